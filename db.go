@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/vulndb"
+)
+
+func runDB(args []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprintln(os.Stderr, "Usage: deplister db update|info [options]")
+		exit(2)
+	}
+
+	switch args[0] {
+	case "update":
+		runDBUpdate(args[1:])
+	case "info":
+		runDBInfo(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown db command %q\n", args[0])
+		fmt.Fprintln(os.Stderr, "Available db commands: update, info")
+		exit(2)
+	}
+}
+
+func runDBUpdate(args []string) {
+	var (
+		path       string
+		ecosystems string
+		opts       vulndb.UpdateOptions
+	)
+
+	flags := flag.NewFlagSet("db update", flag.ExitOnError)
+	flags.StringVar(&path, "path", vulndb.DefaultPath(), "Vulnerability database to create or replace")
+	flags.StringVar(&opts.Source, "source", vulndb.DefaultSourceURL, "URL or directory containing <ecosystem>/all.zip OSV snapshots")
+	flags.StringVar(&ecosystems, "ecosystems", "", "Comma separated OSV ecosystems to download (default: all supported)")
+	network := networkFlags(flags)
+	flags.Parse(args)
+	if offline && (strings.HasPrefix(opts.Source, "http://") || strings.HasPrefix(opts.Source, "https://")) {
+		fmt.Fprintln(os.Stderr, "Downloading OSV snapshots needs network access; in offline mode, use -source with a directory of snapshots")
+		exit(2)
+	}
+	opts.HTTPClient = httpClient(network)
+
+	for _, ecosystem := range strings.Split(ecosystems, ",") {
+		if ecosystem = strings.TrimSpace(ecosystem); ecosystem != "" {
+			opts.Ecosystems = append(opts.Ecosystems, ecosystem)
+		}
+	}
+
+	sources, err := vulndb.Update(context.Background(), path, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error updating vulnerability database: %v\n", err)
+		exit(1)
+	}
+
+	for _, source := range sources {
+		fmt.Fprintf(os.Stderr, "Loaded %d %s advisories\n", source.Advisories, source.Ecosystem)
+	}
+	fmt.Fprintf(os.Stderr, "Vulnerability database written to %s\n", path)
+}
+
+func runDBInfo(args []string) {
+	var path string
+
+	flags := flag.NewFlagSet("db info", flag.ExitOnError)
+	flags.StringVar(&path, "path", vulndb.DefaultPath(), "Vulnerability database to describe")
+	flags.Parse(args)
+
+	db, err := vulndb.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening vulnerability database: %v\n", err)
+		exit(1)
+	}
+	defer db.Close()
+
+	sources, err := db.Sources(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading vulnerability database: %v\n", err)
+		exit(1)
+	}
+
+	fmt.Printf("Vulnerability database %s\n\n", path)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ECOSYSTEM\tADVISORIES\tUPDATED\tSOURCE")
+	for _, source := range sources {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", source.Ecosystem, source.Advisories, source.UpdatedAt.Local().Format(time.DateTime), source.URL)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/output"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func runGraph(args []string) {
+	var (
+		projectPath string
+		repoSpec    string
+		scanFile    string
+		format      string
+		outputFile  string
+		focus       []string
+		graphOpts   output.GraphOptions
+		disabled    string
+		opts        = scanners.DefaultScanOptions()
+	)
+
+	flags := flag.NewFlagSet("graph", flag.ExitOnError)
+	flags.StringVar(&projectPath, "path", ".", "Path to the project directory or archive")
+	flags.StringVar(&repoSpec, "repo", "", "Git repository to clone and scan, as url[@ref]")
+	flags.StringVar(&scanFile, "scan", "", "JSON output of scan -graph to read instead of scanning")
+	flags.StringVar(&format, "format", output.Tree, "Visualization format: "+strings.Join(output.GraphFormats, ", "))
+	flags.StringVar(&outputFile, "out", "", "Output file path (default: stdout)")
+	flags.Var((*listFlag)(&focus), "focus", "Only show the packages on paths from the project to this package, as name or name@version (repeatable)")
+	flags.IntVar(&graphOpts.MaxNodes, "max-nodes", 0, "Show at most this many dependencies, the nearest to the project first (default: all)")
+	flags.BoolVar(&graphOpts.CollapseTransitive, "collapse-transitive", false, "Only show the direct dependencies, each with the number of transitive dependencies below it")
+	flags.StringVar(&disabled, "disable", "", "Comma separated list of scanner types to disable")
+	flags.BoolVar(&opts.IncludeDev, "include-dev", opts.IncludeDev, "Include development dependencies")
+	flags.BoolVar(&opts.Offline, "offline", opts.Offline, "Never access the network while scanning")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: deplister graph [flags]\n\nDraws the dependency graph as Graphviz dot, a Mermaid flowchart or a text tree, pruned to stay readable.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	opts.Offline = opts.Offline || offline
+
+	if !slices.Contains(output.GraphFormats, format) {
+		fmt.Fprintf(os.Stderr, "Invalid -format %q, expected one of %s\n", format, strings.Join(output.GraphFormats, ", "))
+		exit(2)
+	}
+	if graphOpts.MaxNodes < 0 {
+		fmt.Fprintf(os.Stderr, "-max-nodes must not be negative\n")
+		exit(2)
+	}
+
+	setupScanners(disabled)
+	target := engine.Target{Path: projectPath}
+	if repoSpec != "" {
+		target = engine.Target{Repo: repoSpec}
+	}
+
+	report := scanOrLoad(target, opts, scanFile)
+
+	for _, name := range focus {
+		keys := nodeKeys(report.Result.Graph, name)
+		if len(keys) == 0 {
+			fmt.Fprintf(os.Stderr, "%s is not a dependency of %s\n", name, describeTarget(target))
+			exit(1)
+		}
+		// A bare name focuses on every version of the package
+		graphOpts.Focus = append(graphOpts.Focus, keys...)
+	}
+
+	writer := os.Stdout
+	var err error
+	if outputFile != "" {
+		if writer, err = os.Create(outputFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
+			exit(1)
+		}
+	}
+	err = output.WriteGraph(writer, report.Result, format, graphOpts)
+	if outputFile != "" {
+		err = errors.Join(err, writer.Close())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		exit(1)
+	}
+}
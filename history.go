@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/history"
+)
+
+// saveScan records the report in the history database at path
+func saveScan(path, project string, report *engine.Report) error {
+	store, err := history.Open(path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	_, err = store.Save(context.Background(), project, report)
+	return err
+}
+
+// historyFlags holds the flags shared by the history and trend commands
+type historyFlags struct {
+	storePath   string
+	projectPath string
+	repoSpec    string
+	jsonOutput  bool
+}
+
+func (f *historyFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&f.storePath, "store", "", "SQLite database written by scan -store (required)")
+	flags.StringVar(&f.projectPath, "path", ".", "Path of the scanned project")
+	flags.StringVar(&f.repoSpec, "repo", "", "Scanned git repository, as url[@ref]")
+	flags.BoolVar(&f.jsonOutput, "json", false, "Output as JSON")
+}
+
+// open opens the store and returns the project key used by scan -store
+func (f *historyFlags) open() (*history.Store, string) {
+	if f.storePath == "" {
+		fmt.Fprintln(os.Stderr, "-store is required")
+		exit(2)
+	}
+	if _, err := os.Stat(f.storePath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening history: %v\n", err)
+		exit(1)
+	}
+
+	store, err := history.Open(f.storePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening history: %v\n", err)
+		exit(1)
+	}
+
+	target := engine.Target{Path: f.projectPath}
+	if f.repoSpec != "" {
+		target = engine.Target{Repo: f.repoSpec}
+	}
+	return store, describeTarget(target)
+}
+
+func runHistory(args []string) {
+	var (
+		hf    historyFlags
+		limit int
+	)
+
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	hf.register(flags)
+	flags.IntVar(&limit, "limit", 20, "Maximum number of scans to list (0 for all)")
+	flags.Parse(args)
+
+	store, project := hf.open()
+	defer store.Close()
+
+	scans, err := store.History(context.Background(), project, limit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading history: %v\n", err)
+		exit(1)
+	}
+
+	if hf.jsonOutput {
+		err = writeJSONValue(os.Stdout, scans)
+	} else {
+		err = writeHistoryText(os.Stdout, project, scans)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		exit(1)
+	}
+}
+
+func runTrend(args []string) {
+	var hf historyFlags
+
+	flags := flag.NewFlagSet("trend", flag.ExitOnError)
+	hf.register(flags)
+	flags.Parse(args)
+
+	store, project := hf.open()
+	defer store.Close()
+
+	points, err := store.Trend(context.Background(), project)
+	if errors.Is(err, history.ErrNoHistory) {
+		fmt.Fprintf(os.Stderr, "No scans recorded for %s\n", project)
+		exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading history: %v\n", err)
+		exit(1)
+	}
+
+	if hf.jsonOutput {
+		err = writeJSONValue(os.Stdout, points)
+	} else {
+		err = writeTrendText(os.Stdout, project, points)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		exit(1)
+	}
+}
+
+func writeJSONValue(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func writeHistoryText(w io.Writer, project string, scans []history.Scan) error {
+	fmt.Fprintf(w, "Scan history for %s\n\n", project)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSCANNED AT\tTYPE\tDEPENDENCIES\tDIRECT")
+	for _, scan := range scans {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\n", scan.ID, scan.ScannedAt.Local().Format(time.DateTime), scan.ProjectType, scan.DependencyCount, scan.DirectCount)
+	}
+	return tw.Flush()
+}
+
+func writeTrendText(w io.Writer, project string, points []history.TrendPoint) error {
+	fmt.Fprintf(w, "Dependency trend for %s\n", project)
+	for i, point := range points {
+		delta := ""
+		if i > 0 {
+			delta = fmt.Sprintf(" (%+d)", point.DependencyCount-points[i-1].DependencyCount)
+		}
+		fmt.Fprintf(w, "\n%s  %d dependencies%s\n", point.ScannedAt.Local().Format(time.DateTime), point.DependencyCount, delta)
+		for _, dep := range point.Added {
+			fmt.Fprintf(w, "  + %s@%s\n", dep.Name, dep.Version)
+		}
+		for _, dep := range point.Removed {
+			fmt.Fprintf(w, "  - %s@%s\n", dep.Name, dep.Version)
+		}
+		for _, change := range point.Changed {
+			fmt.Fprintf(w, "  ~ %s %s -> %s\n", change.Name, change.From, change.To)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// graphAnalysis is the JSON output of the hubs command
+type graphAnalysis struct {
+	Hubs   []hubEntry   `json:"hubs"`
+	Chains []chainEntry `json:"longestChains"`
+}
+
+type hubEntry struct {
+	Name       string `json:"name"`
+	Version    string `json:"version,omitempty"`
+	Direct     int    `json:"directDependents"`
+	Transitive int    `json:"transitiveDependents"`
+}
+
+type chainEntry struct {
+	Depth int      `json:"depth"`
+	Path  []string `json:"path"`
+}
+
+func runHubs(args []string) {
+	var (
+		projectPath string
+		repoSpec    string
+		scanFile    string
+		jsonOutput  bool
+		top         int
+		disabled    string
+		opts        = scanners.DefaultScanOptions()
+	)
+
+	flags := flag.NewFlagSet("hubs", flag.ExitOnError)
+	flags.StringVar(&projectPath, "path", ".", "Path to the project directory or archive")
+	flags.StringVar(&repoSpec, "repo", "", "Git repository to clone and scan, as url[@ref]")
+	flags.BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	flags.StringVar(&scanFile, "scan", "", "JSON output of scan -graph to read instead of scanning")
+	flags.IntVar(&top, "top", 10, "Number of hubs and chains to report")
+	flags.StringVar(&disabled, "disable", "", "Comma separated list of scanner types to disable")
+	flags.BoolVar(&opts.IncludeDev, "include-dev", opts.IncludeDev, "Include development dependencies")
+	flags.BoolVar(&opts.Offline, "offline", opts.Offline, "Never access the network while scanning")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: deplister hubs [flags]\n\nReports the packages most other packages depend on and the longest dependency chains.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	opts.Offline = opts.Offline || offline
+
+	setupScanners(disabled)
+	target := engine.Target{Path: projectPath}
+	if repoSpec != "" {
+		target = engine.Target{Repo: repoSpec}
+	}
+
+	report := scanOrLoad(target, opts, scanFile)
+
+	graph := report.Result.Graph
+	analysis := graphAnalysis{Hubs: []hubEntry{}, Chains: []chainEntry{}}
+	hubs := graph.Hubs()
+	for _, hub := range hubs[:min(top, len(hubs))] {
+		entry := hubEntry{Name: hub.Key, Direct: hub.Direct, Transitive: hub.Transitive}
+		if node, ok := graph.Nodes[hub.Key]; ok {
+			entry.Name, entry.Version = node.Name, node.Version
+		}
+		analysis.Hubs = append(analysis.Hubs, entry)
+	}
+	for _, chain := range graph.LongestChains(top) {
+		analysis.Chains = append(analysis.Chains, chainEntry{Depth: chain.Depth, Path: report.Result.DisplayPath(chain.Path)})
+	}
+
+	var err error
+	if jsonOutput {
+		err = writeJSONValue(os.Stdout, analysis)
+	} else {
+		err = writeHubsText(os.Stdout, analysis)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		exit(1)
+	}
+}
+
+func writeHubsText(w io.Writer, analysis graphAnalysis) error {
+	fmt.Fprintln(w, "Most depended upon packages:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TRANSITIVE\tDIRECT\tPACKAGE")
+	for _, hub := range analysis.Hubs {
+		name := hub.Name
+		if hub.Version != "" {
+			name += "@" + hub.Version
+		}
+		fmt.Fprintf(tw, "%d\t%d\t%s\n", hub.Transitive, hub.Direct, name)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Longest dependency chains:")
+	for _, chain := range analysis.Chains {
+		if _, err := fmt.Fprintf(w, "%d: %s\n", chain.Depth, strings.Join(chain.Path, " -> ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/impact"
+	"github.com/santoshdahal12/deplister/pkg/npmrc"
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// impactReport is the JSON output of the impact command
+type impactReport struct {
+	Removed   []string         `json:"removed"`
+	Added     []string         `json:"added"`
+	Changed   []impactChange   `json:"changed"`
+	Conflicts []impactConflict `json:"conflicts"`
+}
+
+type impactChange struct {
+	Name string   `json:"name"`
+	From []string `json:"from"`
+	To   []string `json:"to"`
+}
+
+type impactConflict struct {
+	Dependent string `json:"dependent,omitempty"`
+	Name      string `json:"name"`
+	Requires  string `json:"requires,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Message   string `json:"message"`
+}
+
+func runImpact(args []string) {
+	var (
+		projectPath string
+		repoSpec    string
+		jsonOutput  bool
+		remove      string
+		upgrade     string
+		disabled    string
+		opts        = scanners.DefaultScanOptions()
+	)
+
+	flags := flag.NewFlagSet("impact", flag.ExitOnError)
+	flags.StringVar(&projectPath, "path", ".", "Path to the project directory or archive")
+	flags.StringVar(&repoSpec, "repo", "", "Git repository to clone and scan, as url[@ref]")
+	flags.BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	flags.StringVar(&remove, "remove", "", "Package to remove, as name[@version]")
+	flags.StringVar(&upgrade, "upgrade", "", "Package to upgrade, as name@version")
+	flags.StringVar(&disabled, "disable", "", "Comma separated list of scanner types to disable")
+	flags.BoolVar(&opts.IncludeDev, "include-dev", opts.IncludeDev, "Include development dependencies")
+	flags.BoolVar(&opts.Offline, "offline", opts.Offline, "Never access the network while scanning")
+	network := networkFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: deplister impact [flags] -remove <package>[@version] | -upgrade <package>@<version>\n\nReports the dependencies that would disappear, change or conflict if a package were removed or upgraded.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	opts.Offline = opts.Offline || offline
+
+	if (remove == "") == (upgrade == "") || flags.NArg() != 0 {
+		flags.Usage()
+		exit(2)
+	}
+	opts.Env = network.Env()
+	network.Offline = opts.Offline
+	opts.HTTPClient = httpClient(network)
+	var name, version string
+	if upgrade != "" {
+		i := strings.LastIndex(upgrade, "@")
+		if i <= 0 {
+			fmt.Fprintf(os.Stderr, "Invalid -upgrade %q: expected name@version\n", upgrade)
+			exit(2)
+		}
+		name, version = upgrade[:i], upgrade[i+1:]
+		if opts.Offline {
+			fmt.Fprintln(os.Stderr, "Simulating an upgrade needs registry access and cannot be combined with -offline")
+			exit(2)
+		}
+	}
+
+	setupScanners(disabled)
+	target := engine.Target{Path: projectPath}
+	if repoSpec != "" {
+		target = engine.Target{Repo: repoSpec}
+	}
+
+	ctx := context.Background()
+	report, err := engine.Scan(ctx, target, opts)
+	if errors.Is(err, engine.ErrNoProject) {
+		fmt.Fprintf(os.Stderr, "No supported project found at %s\n", describeTarget(target))
+		exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error scanning dependencies: %v\n", err)
+		exit(1)
+	}
+
+	var result impact.Impact
+	if remove != "" {
+		result, err = impact.Remove(report.Result.Graph, remove)
+	} else {
+		client := registry.NewClient()
+		client.HTTPClient = opts.HTTPClient
+		if repoSpec == "" {
+			// Without a readable .npmrc the public registry is used
+			client.NPMRC, _ = npmrc.Load(os.DirFS(projectPath), true)
+		}
+		result, err = impact.Upgrade(ctx, client, report.Result.Graph, name, version)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error simulating impact: %v\n", err)
+		exit(1)
+	}
+
+	output := impactReport{
+		Removed:   append([]string{}, result.Removed...),
+		Added:     append([]string{}, result.Added...),
+		Changed:   []impactChange{},
+		Conflicts: []impactConflict{},
+	}
+	for _, change := range result.Changed {
+		output.Changed = append(output.Changed, impactChange{Name: change.Name, From: change.From, To: change.To})
+	}
+	for _, conflict := range result.Conflicts {
+		output.Conflicts = append(output.Conflicts, impactConflict{
+			Dependent: conflict.Dependent,
+			Name:      conflict.Name,
+			Requires:  conflict.Requires,
+			Version:   conflict.Version,
+			Message:   conflict.String(),
+		})
+	}
+
+	if jsonOutput {
+		err = writeJSONValue(os.Stdout, output)
+	} else {
+		err = writeImpactText(os.Stdout, output)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		exit(1)
+	}
+}
+
+func writeImpactText(w io.Writer, report impactReport) error {
+	if len(report.Removed)+len(report.Added)+len(report.Changed)+len(report.Conflicts) == 0 {
+		_, err := fmt.Fprintln(w, "No dependencies would change")
+		return err
+	}
+
+	var changed, conflicts []string
+	for _, change := range report.Changed {
+		changed = append(changed, fmt.Sprintf("%s %s -> %s", change.Name, strings.Join(change.From, ", "), strings.Join(change.To, ", ")))
+	}
+	for _, conflict := range report.Conflicts {
+		conflicts = append(conflicts, conflict.Message)
+	}
+	sections := []struct {
+		title string
+		lines []string
+	}{
+		{"Removed", report.Removed},
+		{"Added", report.Added},
+		{"Changed", changed},
+		{"Conflicts", conflicts},
+	}
+
+	first := true
+	for _, section := range sections {
+		if len(section.lines) == 0 {
+			continue
+		}
+		if !first {
+			fmt.Fprintln(w)
+		}
+		first = false
+		fmt.Fprintf(w, "%s (%d):\n", section.title, len(section.lines))
+		for _, line := range section.lines {
+			if _, err := fmt.Fprintf(w, "  %s\n", line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/license"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// licenseRollup is the JSON output of the licenses command
+type licenseRollup struct {
+	Licenses []licenseCount   `json:"licenses"`
+	Unknown  []unknownLicense `json:"unknown"`
+	Direct   []directLicenses `json:"directDependencies"`
+}
+
+type licenseCount struct {
+	License  string `json:"license"`
+	Packages int    `json:"packages"`
+}
+
+type unknownLicense struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type directLicenses struct {
+	Name     string   `json:"name"`
+	Version  string   `json:"version,omitempty"`
+	Licenses []string `json:"licenses"`
+	Packages int      `json:"packages"`
+}
+
+func runLicenses(args []string) {
+	var (
+		projectPath string
+		repoSpec    string
+		scanFile    string
+		jsonOutput  bool
+		disabled    string
+		opts        = scanners.DefaultScanOptions()
+	)
+
+	flags := flag.NewFlagSet("licenses", flag.ExitOnError)
+	flags.StringVar(&projectPath, "path", ".", "Path to the project directory or archive")
+	flags.StringVar(&repoSpec, "repo", "", "Git repository to clone and scan, as url[@ref]")
+	flags.BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	flags.StringVar(&scanFile, "scan", "", "JSON output of scan -graph to read instead of scanning")
+	flags.StringVar(&disabled, "disable", "", "Comma separated list of scanner types to disable")
+	flags.BoolVar(&opts.IncludeDev, "include-dev", opts.IncludeDev, "Include development dependencies")
+	flags.BoolVar(&opts.Offline, "offline", opts.Offline, "Never access the network while scanning")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: deplister licenses [flags]\n\nReports the packages under each license, the packages without a known license and the licenses each direct dependency brings in with its own dependencies.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	opts.Offline = opts.Offline || offline
+
+	setupScanners(disabled)
+	target := engine.Target{Path: projectPath}
+	if repoSpec != "" {
+		target = engine.Target{Repo: repoSpec}
+	}
+
+	report := scanOrLoad(target, opts, scanFile)
+
+	summary := license.Summarize(report.Result)
+	rollup := licenseRollup{Licenses: []licenseCount{}, Unknown: []unknownLicense{}, Direct: []directLicenses{}}
+	for _, count := range summary.Counts {
+		rollup.Licenses = append(rollup.Licenses, licenseCount{License: count.License, Packages: count.Packages})
+	}
+	for _, dep := range summary.Unknown {
+		rollup.Unknown = append(rollup.Unknown, unknownLicense{Name: dep.Name, Version: dep.Version})
+	}
+	for _, closure := range summary.Direct {
+		entry := directLicenses{Name: closure.Key, Licenses: closure.Licenses, Packages: closure.Packages}
+		if node, ok := report.Result.Graph.Nodes[closure.Key]; ok {
+			entry.Name, entry.Version = node.Name, node.Version
+		}
+		rollup.Direct = append(rollup.Direct, entry)
+	}
+
+	var err error
+	if jsonOutput {
+		err = writeJSONValue(os.Stdout, rollup)
+	} else {
+		err = writeLicensesText(os.Stdout, rollup)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		exit(1)
+	}
+}
+
+func writeLicensesText(w io.Writer, rollup licenseRollup) error {
+	fmt.Fprintln(w, "Packages per license:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PACKAGES\tLICENSE")
+	for _, count := range rollup.Licenses {
+		fmt.Fprintf(tw, "%d\t%s\n", count.Packages, count.License)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(rollup.Unknown) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Packages without a known license: %d\n", len(rollup.Unknown))
+		for _, dep := range rollup.Unknown {
+			name := dep.Name
+			if dep.Version != "" {
+				name += "@" + dep.Version
+			}
+			fmt.Fprintf(w, "  %s\n", name)
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Licenses brought in by each direct dependency:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DEPENDENCY\tPACKAGES\tLICENSES")
+	for _, direct := range rollup.Direct {
+		name := direct.Name
+		if direct.Version != "" {
+			name += "@" + direct.Version
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", name, direct.Packages, strings.Join(direct.Licenses, ", "))
+	}
+	return tw.Flush()
+}
@@ -2,170 +2,855 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/santoshdahal12/deplister/pkg/abandoned"
+	"github.com/santoshdahal12/deplister/pkg/age"
+	"github.com/santoshdahal12/deplister/pkg/cache"
+	"github.com/santoshdahal12/deplister/pkg/cycles"
+	"github.com/santoshdahal12/deplister/pkg/deprecation"
+	"github.com/santoshdahal12/deplister/pkg/discover"
+	"github.com/santoshdahal12/deplister/pkg/duplicates"
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/enrich"
+	"github.com/santoshdahal12/deplister/pkg/group"
+	"github.com/santoshdahal12/deplister/pkg/ignore"
+	"github.com/santoshdahal12/deplister/pkg/integrity"
+	"github.com/santoshdahal12/deplister/pkg/lifecycle"
+	"github.com/santoshdahal12/deplister/pkg/maintainers"
+	"github.com/santoshdahal12/deplister/pkg/outdated"
+	"github.com/santoshdahal12/deplister/pkg/output"
+	"github.com/santoshdahal12/deplister/pkg/peers"
+	"github.com/santoshdahal12/deplister/pkg/platform"
+	"github.com/santoshdahal12/deplister/pkg/policy"
+	"github.com/santoshdahal12/deplister/pkg/provenance"
+	"github.com/santoshdahal12/deplister/pkg/publish"
+	"github.com/santoshdahal12/deplister/pkg/risk"
 	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/scanners/plugin"
+	"github.com/santoshdahal12/deplister/pkg/scorecard"
+	"github.com/santoshdahal12/deplister/pkg/signing"
+	"github.com/santoshdahal12/deplister/pkg/size"
+	"github.com/santoshdahal12/deplister/pkg/skew"
+	"github.com/santoshdahal12/deplister/pkg/tracing"
+	"github.com/santoshdahal12/deplister/pkg/typosquat"
+	"github.com/santoshdahal12/deplister/pkg/unused"
+	"github.com/santoshdahal12/deplister/pkg/vex"
+	"github.com/santoshdahal12/deplister/pkg/vulns"
+
+	// Built-in scanners register themselves with the scanner registry
 	"github.com/santoshdahal12/deplister/pkg/scanners/golang"
-	"github.com/santoshdahal12/deplister/pkg/scanners/npm"
+	_ "github.com/santoshdahal12/deplister/pkg/scanners/npm"
+	"github.com/santoshdahal12/deplister/pkg/scanners/python"
 )
 
-type OutputFormat struct {
-	ProjectType  string             `json:"projectType"`
-	Dependencies []DependencyOutput `json:"dependencies"`
-}
+// offline is set by -offline before the command, or by DEPLISTER_OFFLINE. Every
+// command then runs offline and fails fast on steps that need the network.
+var offline bool
+
+func main() {
+	args := os.Args[1:]
+	offline, _ = strconv.ParseBool(os.Getenv("DEPLISTER_OFFLINE"))
+	if len(args) > 0 && (args[0] == "-offline" || args[0] == "--offline") {
+		offline, args = true, args[1:]
+	}
+	command := "scan"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
 
-type DependencyOutput struct {
-	Name        string            `json:"name"`
-	Version     string            `json:"version"`
-	Type        string            `json:"type"`
-	IsDirectDep bool              `json:"isDirectDependency"`
-	Parent      string            `json:"parent,omitempty"`
-	Properties  map[string]string `json:"properties,omitempty"`
+	shutdown, err := tracing.Setup(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error setting up tracing: %v\n", err)
+		os.Exit(1)
+	}
+	shutdownTracing = shutdown
+
+	switch command {
+	case "scan":
+		runScan(args)
+	case "serve":
+		runServe(args)
+	case "history":
+		runHistory(args)
+	case "trend":
+		runTrend(args)
+	case "submit":
+		runSubmit(args)
+	case "db":
+		runDB(args)
+	case "vex":
+		runVEX(args)
+	case "merge":
+		runMerge(args)
+	case "verify":
+		runVerify(args)
+	case "rdeps":
+		runRdeps(args)
+	case "hubs":
+		runHubs(args)
+	case "why":
+		runWhy(args)
+	case "graph":
+		runGraph(args)
+	case "impact":
+		runImpact(args)
+	case "skew":
+		runSkew(args)
+	case "licenses":
+		runLicenses(args)
+	case "suggest-updates":
+		runSuggestUpdates(args)
+	case "org":
+		runOrg(args)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n", command)
+		fmt.Fprintf(os.Stderr, "Available commands: scan, serve, history, trend, submit, db, vex, merge, verify, rdeps, hubs, why, graph, impact, skew, licenses, suggest-updates, org\n")
+		exit(2)
+	}
+	exit(0)
 }
 
-// Scanner registry
-var availableScanners = []scanners.Scanner{
-	npm.NewScanner(),
-	golang.NewScanner(),
+// shutdownTracing flushes pending spans before the process exits
+var shutdownTracing = func(context.Context) error { return nil }
+
+// exit flushes traces and exits, as os.Exit skips deferred calls
+func exit(code int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Error flushing traces: %v\n", err)
+	}
+	os.Exit(code)
 }
 
-func main() {
+func runScan(args []string) {
 	var (
 		projectPath  string
+		repoSpec     string
 		textOutput   bool
 		outputFile   string
+		junitFile    string
+		swidDir      string
+		annotate     bool
+		publishTo    listFlag
 		prettyOutput bool
+		withGraph    bool
+		disabled     string
+		storePath    string
+		lookupVulns  bool
+		outdatedDeps bool
+		deprecated   bool
+		typosquats   bool
+		duplicated   bool
+		unusedDeps   bool
+		skewed       bool
+		peerCheck    bool
+		cycleCheck   bool
+		scripts      bool
+		provenances  bool
+		verify       bool
+		scorecards   bool
+		abandon      bool
+		sizes        bool
+		ages         bool
+		maintained   bool
+		riskScores   bool
+		enrichers    string
+		groupBy      string
+		fieldList    string
+		fields       []string
+		concurrency  int
+		recursive    bool
+		discovery    discover.Options
+		noCache      bool
+		previousFile string
+		goEnv        listFlag
+		pythonEnv    listFlag
+		policyFile   string
+		vexFile      string
+		ignoreFile   string
+		signKey      string
+		attest       bool
+		opts         = scanners.DefaultScanOptions()
 	)
 
-	flag.StringVar(&projectPath, "path", ".", "Path to the project directory")
-	flag.StringVar(&outputFile, "out", "", "Output file path (default: stdout)")
-	flag.BoolVar(&textOutput, "text", false, "Output in human-readable text format")
-	flag.BoolVar(&prettyOutput, "pretty", false, "Pretty print JSON output (ignored with -text)")
-	flag.Parse()
+	flags := flag.NewFlagSet("scan", flag.ExitOnError)
+	flags.StringVar(&projectPath, "path", ".", "Path to the project directory or archive")
+	flags.StringVar(&repoSpec, "repo", "", "Git repository to clone and scan, as url[@ref]")
+	flags.StringVar(&outputFile, "out", "", "Output file path (default: stdout)")
+	flags.StringVar(&junitFile, "junit", "", "Also write the findings and vulnerabilities as JUnit XML test results to this file")
+	flags.StringVar(&swidDir, "swid", "", "Also write an ISO/IEC 19770-2 SWID tag for every dependency and the project to this directory")
+	flags.Var(&publishTo, "publish", "Also upload the output file, and its signature, to s3://bucket/key, gs://bucket/key or az://account/container/key, named after its SHA-256 under a key ending in / (repeatable)")
+	flags.BoolVar(&annotate, "github-annotations", false, "Also print the findings and vulnerabilities as GitHub Actions workflow commands on stderr, to annotate pull requests")
+	flags.BoolVar(&textOutput, "text", false, "Output in human-readable text format")
+	flags.BoolVar(&prettyOutput, "pretty", false, "Pretty print JSON output (ignored with -text)")
+	flags.StringVar(&fieldList, "fields", "", "Comma separated dependency fields to keep in JSON output, such as name,version,license,purl: "+strings.Join(output.DependencyFields, ", ")+" (default: all but purl and cpe)")
+	flags.BoolVar(&withGraph, "graph", false, "Include the dependency graph in the JSON output, so that rdeps and hubs can read the scan with -scan")
+	flags.StringVar(&disabled, "disable", "", "Comma separated list of scanner types to disable")
+	flags.BoolVar(&opts.IncludeDev, "include-dev", opts.IncludeDev, "Include development dependencies")
+	flags.BoolVar(&opts.FollowWorkspaces, "workspaces", opts.FollowWorkspaces, "Include workspace packages of monorepos")
+	flags.BoolVar(&opts.Offline, "offline", opts.Offline, "Never access the network while scanning")
+	flags.IntVar(&opts.MaxDepth, "max-depth", opts.MaxDepth, "Maximum dependency depth to report (0 for unlimited)")
+	flags.IntVar(&opts.MaxPaths, "max-paths", opts.MaxPaths, "Paths to record per dependency, shortest first (0 or 1 for only the shortest)")
+	flags.StringVar(&opts.Paths, "paths", scanners.PathsShortest, "Paths to record per dependency: "+strings.Join(scanners.PathModes, ", ")+"; see the why command for every path of one")
+	flags.StringVar(&storePath, "store", "", "SQLite database to record the scan in")
+	flags.BoolVar(&lookupVulns, "vulns", false, "Look up known vulnerabilities of each dependency on OSV.dev")
+	flags.BoolVar(&outdatedDeps, "outdated", false, "Look up the latest version of each dependency in the npm registry and Go module proxy")
+	flags.BoolVar(&deprecated, "deprecated", false, "Flag deprecated npm packages and retracted or deprecated Go modules")
+	flags.BoolVar(&typosquats, "typosquat", false, "Warn about dependency names resembling popular packages")
+	flags.BoolVar(&duplicated, "duplicates", false, "Warn about packages present at several versions and which packages require each")
+	flags.BoolVar(&unusedDeps, "unused", false, "Warn about go.mod and package.json dependencies no source file imports, and npm imports missing from package.json")
+	flags.BoolVar(&skewed, "skew", false, "Warn about dependencies the workspaces of an npm monorepo resolve to different versions")
+	flags.BoolVar(&peerCheck, "peers", false, "Warn about npm peer dependencies that are missing or installed outside their declared range")
+	flags.StringVar(&opts.NodeVersion, "node-version", "", "Warn about npm packages whose engines field excludes this Node.js version")
+	flags.StringVar(&opts.Platform, "platform", "", "Warn about npm packages whose os, cpu or libc fields exclude this os[/cpu[/libc]], e.g. linux/x64/glibc")
+	flags.Var(&pythonEnv, "python-env", "KEY=value PEP 508 marker variable of the environment Python dependencies are installed in, such as python_version=3.12 or sys_platform=linux; dependencies whose markers exclude it are left out (repeatable)")
+	flags.BoolVar(&cycleCheck, "cycles", false, "Report cycles in the dependency graph")
+	flags.BoolVar(&opts.FailOnCycles, "fail-on-cycles", opts.FailOnCycles, "Report cycles in Go module graphs as errors, exiting with status 3")
+	flags.BoolVar(&scripts, "install-scripts", false, "Look up install scripts of npm packages missing from node_modules in the registry")
+	flags.BoolVar(&opts.IncludeScripts, "script-text", false, "Include the commands of install scripts in dependency properties")
+	flags.BoolVar(&provenances, "provenance", false, "Verify npm registry signatures and provenance attestations, and Go modules against the checksum database")
+	flags.BoolVar(&verify, "verify", false, "Cross-check npm lockfile integrity with the registry and go.sum with the checksum database")
+	flags.BoolVar(&scorecards, "scorecard", false, "Look up OpenSSF Scorecard scores, stars and maintenance signals of source repositories on deps.dev")
+	flags.BoolVar(&abandon, "abandoned", false, "Warn about dependencies without a release in -abandoned-days")
+	flags.BoolVar(&sizes, "size", false, "Estimate the size of each dependency from the npm registry and Go module proxy, and report the largest")
+	flags.BoolVar(&ages, "age", false, "Report how long ago each resolved version was released and how many versions it is behind")
+	flags.BoolVar(&maintained, "maintainers", false, "Look up the maintainers, publisher and funding links of npm dependencies, and warn about production dependencies with a single maintainer")
+	flags.BoolVar(&riskScores, "risk", false, "Score the risk of each dependency from its depth and the signals of the other enrichments, riskiest first; the policy file can weigh the signals")
+	flags.StringVar(&enrichers, "enrich", "", "Comma separated list of per-dependency enrichers to run: "+strings.Join(enrich.Names(), ", "))
+	flags.IntVar(&opts.EnrichWorkers, "enrich-workers", 0, "Dependencies -enrich processes in parallel (default: number of CPUs)")
+	flags.Float64Var(&opts.EnrichRate, "enrich-rate", 0, "Calls per second -enrich makes at most (default: unlimited)")
+	flags.StringVar(&groupBy, "group-by", "", "Group dependencies by "+strings.Join(group.Keys, ", ")+" with counts; maintainer looks up npm maintainers in the registry")
+	flags.IntVar(&opts.AbandonedDays, "abandoned-days", abandoned.DefaultDays, "Days without a release before a dependency counts as abandoned")
+	flags.StringVar(&opts.VulnDB, "vulndb", "", "Local vulnerability database from 'deplister db update' to use with -vulns (default with -offline)")
+	flags.StringVar(&vexFile, "vex", "", "OpenVEX or CSAF VEX document; vulnerabilities it declares not_affected or fixed are suppressed")
+	flags.StringVar(&signKey, "sign", "", "Sign the -out file with a PEM or cosign private key, or \""+signing.Keyless+"\" to sign with cosign and a Sigstore identity")
+	flags.BoolVar(&attest, "attest", false, "With -sign, write a signed in-toto attestation of the -out file instead of a plain signature")
+	flags.StringVar(&ignoreFile, "ignore", "", "Ignore file of accepted findings and vulnerabilities (default: "+ignore.File+" in the project directory)")
+	flags.StringVar(&policyFile, "policy", "", "Policy file of allow/deny/require rules; violations exit with status 3")
+	flags.StringVar(&opts.CacheDir, "cache-dir", cache.DefaultDir(), "Directory of cached scan results, reused while manifests and lockfiles are unchanged")
+	flags.BoolVar(&noCache, "no-cache", false, "Always rescan instead of using cached results")
+	flags.StringVar(&previousFile, "incremental", "", "Earlier JSON output of the scan; packages whose lockfile entry is unchanged reuse its licenses and install scripts")
+	flags.Var(&goEnv, "go-env", "KEY=value environment variable of the go commands the Go scanner runs, such as GOPRIVATE or GOPROXY (repeatable)")
+	flags.StringVar(&opts.GoMod, "go-mod", "", "-mod mode of the go commands the Go scanner runs: "+strings.Join(golang.ModModes, ", "))
+	flags.StringVar(&opts.GoScope, "go-scope", golang.ScopeGraph, "Go modules to report: graph for the whole module graph, build for modules providing packages to the build for the GOOS, GOARCH and tags of -go-env, annotate for the graph with a build property")
+	flags.BoolVar(&opts.GoPackages, "go-packages", false, "List the packages of each Go module the build uses and the packages of the project importing them")
+	flags.BoolVar(&opts.GoTests, "go-tests", false, "Classify Go modules only the tests of the project import as test dependencies, left out with -include-dev=false")
+	flags.BoolVar(&opts.GoStdlib, "go-stdlib", false, "Report the Go standard library at the version of the go tool, or the toolchain of go.mod, as a dependency")
+	flags.BoolVar(&recursive, "recursive", false, "Scan every project found in the directories given, skipping node_modules, vendor, hidden directories and directories .gitignore files exclude")
+	flags.Var((*listFlag)(&discovery.Exclude), "exclude-dir", "With -recursive, gitignore pattern of directories to skip, such as build or data/raw (repeatable)")
+	flags.BoolVar(&discovery.NoGitignore, "no-gitignore", false, "With -recursive, also descend into directories .gitignore files exclude")
+	flags.IntVar(&discovery.MaxDepth, "discovery-depth", discover.DefaultMaxDepth, "With -recursive, directory levels to descend at most")
+	flags.BoolVar(&discovery.FollowSymlinks, "follow-symlinks", false, "With -recursive, descend into symlinked directories within the directories given")
+	flags.BoolVar(&discovery.FollowOutside, "follow-outside", false, "With -follow-symlinks, also follow links leading outside the directories given")
+	flags.IntVar(&concurrency, "concurrency", 0, "Projects to scan in parallel when several paths are given (default: number of CPUs)")
+	network := networkFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: deplister scan [flags] [path...]\n\nScans the dependencies of a project, or of every project directory or archive given as an argument.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	opts.Offline = opts.Offline || offline
 
-	// Convert to absolute path
-	absPath, err := filepath.Abs(projectPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error resolving path: %v\n", err)
-		os.Exit(1)
+	if noCache {
+		opts.CacheDir = ""
+	}
+	if opts.GoMod != "" && !slices.Contains(golang.ModModes, opts.GoMod) {
+		fmt.Fprintf(os.Stderr, "Invalid -go-mod %q, expected one of %s\n", opts.GoMod, strings.Join(golang.ModModes, ", "))
+		exit(2)
+	}
+	if !slices.Contains(scanners.PathModes, opts.Paths) {
+		fmt.Fprintf(os.Stderr, "Invalid -paths %q, expected one of %s\n", opts.Paths, strings.Join(scanners.PathModes, ", "))
+		exit(2)
 	}
+	if !slices.Contains(golang.Scopes, opts.GoScope) {
+		fmt.Fprintf(os.Stderr, "Invalid -go-scope %q, expected one of %s\n", opts.GoScope, strings.Join(golang.Scopes, ", "))
+		exit(2)
+	}
+	if fieldList != "" {
+		var err error
+		if fields, err = output.ParseFields(fieldList); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -fields: %v\n", err)
+			exit(2)
+		}
+		if textOutput || withGraph {
+			fmt.Fprintf(os.Stderr, "-fields cannot be combined with -text or -graph\n")
+			exit(2)
+		}
+	}
+	if _, err := platform.ParseTarget(opts.NodeVersion, opts.Platform); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -node-version or -platform: %v\n", err)
+		exit(2)
+	}
+	if len(pythonEnv) > 0 {
+		var err error
+		if opts.PythonEnv, err = python.ParseEnvironment(pythonEnv); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -python-env: %v\n", err)
+			exit(2)
+		}
+	}
+	for _, entry := range goEnv {
+		if !strings.Contains(entry, "=") {
+			fmt.Fprintf(os.Stderr, "Invalid -go-env %q, expected KEY=value\n", entry)
+			exit(2)
+		}
+	}
+	opts.Env = append(network.Env(), goEnv...)
+	network.Offline = opts.Offline
+	opts.HTTPClient = httpClient(network)
 
-	// Detect project type and scan dependencies
-	ctx := context.Background()
-	var projectScanner scanners.Scanner
-	var projectType string
+	targets := []engine.Target{{Path: projectPath}}
+	if repoSpec != "" {
+		targets = []engine.Target{{Repo: repoSpec}}
+	}
+	if flags.NArg() > 0 {
+		if repoSpec != "" {
+			fmt.Fprintf(os.Stderr, "-repo cannot be combined with project paths\n")
+			exit(2)
+		}
+		targets = nil
+		for _, path := range flags.Args() {
+			targets = append(targets, engine.Target{Path: path})
+		}
+	}
 
-	for _, scanner := range availableScanners {
-		if scanner.DetectProject(ctx, absPath) {
-			projectScanner = scanner
-			projectType = scanner.GetType()
-			break
+	setupScanners(disabled)
+	if recursive {
+		if repoSpec != "" {
+			fmt.Fprintf(os.Stderr, "-recursive cannot be combined with -repo\n")
+			exit(2)
 		}
+		targets = discoverTargets(targets, discovery)
 	}
 
-	if projectScanner == nil {
-		fmt.Fprintf(os.Stderr, "No supported project found at %s\n", absPath)
-		fmt.Fprintf(os.Stderr, "Supported project types: npm, go\n")
-		os.Exit(1)
+	if signKey != "" && outputFile == "" {
+		fmt.Fprintf(os.Stderr, "-sign requires -out\n")
+		exit(2)
+	}
+	if attest && (signKey == "" || textOutput) {
+		fmt.Fprintf(os.Stderr, "-attest requires -sign and JSON output\n")
+		exit(2)
+	}
+	var destinations []publish.Destination
+	for _, raw := range publishTo {
+		dest, err := publish.Parse(raw)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -publish: %v\n", err)
+			exit(2)
+		}
+		destinations = append(destinations, dest)
+	}
+	if len(destinations) > 0 && outputFile == "" {
+		fmt.Fprintf(os.Stderr, "-publish requires -out\n")
+		exit(2)
+	}
+	if len(destinations) > 0 && opts.Offline {
+		fmt.Fprintf(os.Stderr, "-publish needs network access and cannot be combined with -offline\n")
+		exit(2)
+	}
+	if signKey == signing.Keyless && opts.Offline {
+		fmt.Fprintf(os.Stderr, "-sign %s needs Sigstore network access and cannot be combined with -offline\n", signing.Keyless)
+		exit(2)
 	}
 
-	result, err := projectScanner.ScanDependencies(ctx, absPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error scanning dependencies: %v\n", err)
-		os.Exit(1)
+	if withGraph && (textOutput || groupBy != "" || len(targets) > 1) {
+		fmt.Fprintf(os.Stderr, "-graph requires JSON output of a single project without -group-by\n")
+		exit(2)
 	}
 
-	if textOutput {
-		outputText(result, projectType, outputFile)
-	} else {
-		outputJSON(result, projectType, outputFile, prettyOutput)
+	if groupBy != "" && !slices.Contains(group.Keys, groupBy) {
+		fmt.Fprintf(os.Stderr, "Invalid -group-by %q, expected one of %s\n", groupBy, strings.Join(group.Keys, ", "))
+		exit(2)
 	}
-}
 
-func outputJSON(result *scanners.ScanResult, projectType, outputFile string, pretty bool) {
-	output := OutputFormat{
-		ProjectType:  projectType,
-		Dependencies: make([]DependencyOutput, len(result.Dependencies)),
+	if previousFile != "" {
+		if len(targets) > 1 {
+			fmt.Fprintf(os.Stderr, "-incremental cannot be combined with several project paths\n")
+			exit(2)
+		}
+		var err error
+		if opts.Previous, _, err = output.LoadFile(previousFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading previous scan: %v\n", err)
+			exit(1)
+		}
+	}
+
+	var rules *policy.Policy
+	if policyFile != "" {
+		var err error
+		if rules, err = policy.Load(policyFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading policy: %v\n", err)
+			exit(1)
+		}
+	}
+
+	var statements *vex.Document
+	if vexFile != "" {
+		var err error
+		if statements, err = vex.Load(vexFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading VEX document: %v\n", err)
+			exit(1)
+		}
+	}
+
+	var accepted *ignore.List
+	if ignoreFile != "" {
+		var err error
+		if accepted, err = ignore.Load(ignoreFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading ignore file: %v\n", err)
+			exit(1)
+		}
+	}
+
+	opts.Enrich = map[string]bool{
+		vulns.Enrichment:                       lookupVulns,
+		outdated.Enrichment:                    outdatedDeps,
+		deprecation.Enrichment:                 deprecated,
+		typosquat.Enrichment:                   typosquats,
+		duplicates.Enrichment:                  duplicated,
+		unused.Enrichment:                      unusedDeps,
+		skew.Enrichment:                        skewed,
+		peers.Enrichment:                       peerCheck,
+		platform.Enrichment:                    opts.NodeVersion != "" || opts.Platform != "",
+		cycles.Enrichment:                      cycleCheck || opts.FailOnCycles,
+		lifecycle.Enrichment:                   scripts,
+		provenance.Enrichment:                  provenances,
+		integrity.Enrichment:                   verify,
+		scorecard.Enrichment:                   scorecards,
+		abandoned.Enrichment:                   abandon,
+		size.Enrichment:                        sizes,
+		age.Enrichment:                         ages,
+		maintainers.Enrichment:                 maintained || groupBy == group.Maintainer,
+		maintainers.SingleMaintainerEnrichment: maintained,
+		risk.Enrichment:                        riskScores,
+	}
+	for _, name := range strings.Split(enrichers, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if !slices.Contains(enrich.Names(), name) {
+			fmt.Fprintf(os.Stderr, "Invalid -enrich %q, expected one of %s\n", name, strings.Join(enrich.Names(), ", "))
+			exit(2)
+		}
+		opts.Enrich[name] = true
+	}
+	if rules != nil {
+		for _, enrichment := range rules.Enrichments() {
+			opts.Enrich[enrichment] = true
+		}
+		opts.RiskWeights = rules.Weights
+	}
+
+	// finish applies VEX statements, policies and accepted findings to a
+	// report and stores it
+	finish := func(target engine.Target, report *engine.Report) error {
+		if statements != nil {
+			if suppressed := vex.Apply(statements, report.Result); suppressed > 0 {
+				fmt.Fprintf(os.Stderr, "Suppressed %d vulnerabilities declared not affected or fixed\n", suppressed)
+				if opts.Enabled(risk.Enrichment) {
+					risk.Enrich(report.Result, opts.RiskWeights, true)
+				}
+			}
+		}
+		if opts.Enabled(risk.Enrichment) {
+			risk.Sort(report.Result.Dependencies)
+		}
+		if rules != nil {
+			report.Result.Findings = append(report.Result.Findings, rules.Evaluate(report.Result)...)
+		}
+		list := accepted
+		if list == nil && target.Path != "" {
+			if _, err := os.Stat(filepath.Join(target.Path, ignore.File)); err == nil {
+				if list, err = ignore.Load(filepath.Join(target.Path, ignore.File)); err != nil {
+					return fmt.Errorf("loading ignore file: %w", err)
+				}
+			}
+		}
+		if list != nil {
+			list.Apply(report.Result)
+		}
+
+		if storePath != "" {
+			if err := saveScan(storePath, describeTarget(target), report); err != nil {
+				return fmt.Errorf("storing scan: %w", err)
+			}
+		}
+		return nil
 	}
 
-	for i, dep := range result.Dependencies {
-		output.Dependencies[i] = DependencyOutput{
-			Name:        dep.Name,
-			Version:     dep.Version,
-			Type:        dep.Type,
-			IsDirectDep: dep.IsDirectDep,
-			Parent:      dep.Parent,
-			Properties:  dep.Properties,
+	outcomes := engine.ScanAll(context.Background(), targets, opts, concurrency)
+	failed := false
+	for i := range outcomes {
+		outcome := &outcomes[i]
+		if errors.Is(outcome.Err, engine.ErrNoProject) && len(outcomes) == 1 {
+			fmt.Fprintf(os.Stderr, "No supported project found at %s\n", describeTarget(outcome.Target))
+			fmt.Fprintf(os.Stderr, "Supported project types: %s\n", strings.Join(scanners.Types(), ", "))
+			exit(1)
+		}
+		if outcome.Err != nil && len(outcomes) == 1 {
+			fmt.Fprintf(os.Stderr, "Error scanning dependencies: %v\n", outcome.Err)
+			exit(1)
+		}
+		if outcome.Err == nil {
+			for _, scanErr := range outcome.Report.Result.Errors {
+				fmt.Fprintf(os.Stderr, "Warning: scan of %s is incomplete: %v\n", describeTarget(outcome.Target), scanErr)
+			}
+			if err := finish(outcome.Target, outcome.Report); err != nil {
+				if len(outcomes) == 1 {
+					fmt.Fprintf(os.Stderr, "Error %v\n", err)
+					exit(1)
+				}
+				outcome.Report, outcome.Err = nil, err
+			}
+		}
+		if outcome.Err != nil {
+			fmt.Fprintf(os.Stderr, "Error scanning %s: %v\n", describeTarget(outcome.Target), outcome.Err)
+			failed = true
 		}
 	}
 
+	var err error
 	var writer io.Writer = os.Stdout
+	var file *os.File
 	if outputFile != "" {
-		file, err := os.Create(outputFile)
-		if err != nil {
+		if file, err = os.Create(outputFile); err != nil {
 			fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
-			os.Exit(1)
+			exit(1)
 		}
-		defer file.Close()
 		writer = file
 	}
 
-	encoder := json.NewEncoder(writer)
-	if pretty {
-		encoder.SetIndent("", "  ")
+	report := outcomes[0].Report
+	switch {
+	case len(outcomes) > 1:
+		err = writeProjects(writer, outcomes, textOutput, prettyOutput, groupBy, fields)
+	case groupBy != "" && textOutput:
+		err = output.WriteGroupedText(writer, report.Result, report.ProjectType, groupBy)
+	case fields != nil:
+		doc := output.NewOutputFormat(report.Result, report.ProjectType)
+		if groupBy != "" {
+			doc, err = output.NewGroupedOutput(report.Result, report.ProjectType, groupBy)
+		}
+		if err == nil {
+			err = output.WriteSelectedJSON(writer, doc, fields, prettyOutput)
+		}
+	case groupBy != "":
+		err = output.WriteGroupedJSON(writer, report.Result, report.ProjectType, groupBy, prettyOutput)
+	case textOutput:
+		err = output.WriteText(writer, report.Result, report.ProjectType)
+	case withGraph:
+		err = output.Save(writer, report.Result, report.ProjectType, prettyOutput)
+	default:
+		err = output.WriteJSON(writer, report.Result, report.ProjectType, prettyOutput)
 	}
-	if err := encoder.Encode(output); err != nil {
-		fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
-		os.Exit(1)
+	if file != nil {
+		err = errors.Join(err, file.Close())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		exit(1)
 	}
-}
 
-func outputText(result *scanners.ScanResult, projectType, outputFile string) {
-	var writer io.Writer = os.Stdout
-	if outputFile != "" {
-		file, err := os.Create(outputFile)
+	if junitFile != "" {
+		if err := writeJUnitFile(junitFile, outcomes); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing JUnit results: %v\n", err)
+			exit(1)
+		}
+	}
+
+	if swidDir != "" {
+		if err := writeSWIDTags(swidDir, outcomes); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing SWID tags: %v\n", err)
+			exit(1)
+		}
+	}
+
+	if annotate {
+		if err := writeAnnotations(os.Stderr, outcomes); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing GitHub annotations: %v\n", err)
+			exit(1)
+		}
+	}
+
+	var signature string
+	if signKey != "" {
+		path, err := signing.SignFile(context.Background(), outputFile, signing.Options{Key: signKey, Attest: attest})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintf(os.Stderr, "Error signing output: %v\n", err)
+			exit(1)
 		}
-		defer file.Close()
-		writer = file
+		fmt.Fprintf(os.Stderr, "Wrote %s\n", path)
+		signature = path
+	}
+
+	for _, dest := range destinations {
+		if err := publishFiles(opts.HTTPClient, dest, outputFile, signature); err != nil {
+			fmt.Fprintf(os.Stderr, "Error publishing output: %v\n", err)
+			exit(1)
+		}
+	}
+
+	if failed {
+		exit(1)
+	}
+	for _, outcome := range outcomes {
+		if policy.HasErrors(outcome.Report.Result.Findings) {
+			fmt.Fprintf(os.Stderr, "Policy violations, integrity mismatches or dependency cycles found\n")
+			exit(3)
+		}
+	}
+}
+
+// writeProjects writes the reports of a multi-project scan, with the error of
+// every project that failed, keeping only the given dependency fields in JSON
+// when there are any
+func writeProjects(writer io.Writer, outcomes []engine.Outcome, textOutput, prettyOutput bool, groupBy string, fields []string) error {
+	if textOutput {
+		for i, outcome := range outcomes {
+			if i > 0 {
+				fmt.Fprintln(writer)
+			}
+			fmt.Fprintf(writer, "==> %s <==\n", describeTarget(outcome.Target))
+			var err error
+			switch {
+			case outcome.Err != nil:
+				_, err = fmt.Fprintf(writer, "Error: %v\n", outcome.Err)
+			case groupBy != "":
+				err = output.WriteGroupedText(writer, outcome.Report.Result, outcome.Report.ProjectType, groupBy)
+			default:
+				err = output.WriteText(writer, outcome.Report.Result, outcome.Report.ProjectType)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	projects := make([]output.ProjectOutput, len(outcomes))
+	for i, outcome := range outcomes {
+		projects[i].Target = describeTarget(outcome.Target)
+		if outcome.Err != nil {
+			projects[i].Error = outcome.Err.Error()
+			continue
+		}
+		doc := output.NewOutputFormat(outcome.Report.Result, outcome.Report.ProjectType)
+		if groupBy != "" {
+			var err error
+			if doc, err = output.NewGroupedOutput(outcome.Report.Result, outcome.Report.ProjectType, groupBy); err != nil {
+				return err
+			}
+		}
+		projects[i].OutputFormat = &doc
 	}
+	if fields != nil {
+		return output.WriteSelectedJSON(writer, projects, fields, prettyOutput)
+	}
+	return output.WriteProjectsJSON(writer, projects, prettyOutput)
+}
+
+// setupScanners registers external plugins and disables the given scanners
+func setupScanners(disabled string) {
+	plugin.RegisterAll()
 
-	fmt.Fprintf(writer, "Project Type: %s\n", projectType)
-	fmt.Fprintln(writer, "Dependencies:")
-	fmt.Fprintln(writer, "-------------")
+	for _, name := range strings.Split(disabled, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if err := scanners.Disable(name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error disabling scanner %q: %v\n", name, err)
+			exit(1)
+		}
+	}
+}
 
-	for _, dep := range result.Dependencies {
-		depType := "Production"
-		if t, ok := dep.Properties["dependencyType"]; ok {
-			depType = t
+// discoverTargets replaces the directories among the targets with the
+// projects found in them, exiting when there are none
+func discoverTargets(targets []engine.Target, opts discover.Options) []engine.Target {
+	var found []engine.Target
+	for _, target := range targets {
+		if info, err := os.Stat(target.Path); err != nil || !info.IsDir() {
+			found = append(found, target)
+			continue
+		}
+		projects, err := engine.Discover(context.Background(), target.Path, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error discovering projects in %s: %v\n", describeTarget(target), err)
+			exit(1)
+		}
+		for _, project := range projects {
+			found = append(found, engine.Target{Path: project})
 		}
+	}
+	if len(found) == 0 {
+		fmt.Fprintf(os.Stderr, "No supported project found\n")
+		fmt.Fprintf(os.Stderr, "Supported project types: %s\n", strings.Join(scanners.Types(), ", "))
+		exit(1)
+	}
+	return found
+}
 
-		directness := "Indirect"
-		if dep.IsDirectDep {
-			directness = "Direct"
+// writeJUnitFile writes the findings and vulnerabilities of the scanned
+// projects as JUnit XML to path
+func writeJUnitFile(path string, outcomes []engine.Outcome) error {
+	projects := make([]output.JUnitProject, len(outcomes))
+	for i, outcome := range outcomes {
+		projects[i] = output.JUnitProject{Name: describeTarget(outcome.Target), Err: outcome.Err}
+		if outcome.Report != nil {
+			projects[i].Result = outcome.Report.Result
 		}
+	}
 
-		fmt.Fprintf(writer, "%s@%s (%s, %s)\n", dep.Name, dep.Version, depType, directness)
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = output.WriteJUnit(file, projects)
+	return errors.Join(err, file.Close())
+}
 
-		if resolved, ok := dep.Properties["resolved"]; ok {
-			fmt.Fprintf(writer, "  Source: %s\n", resolved)
+// writeSWIDTags writes the SWID tags of the scanned projects to a directory,
+// one file per tag. Packages several projects share get a single tag.
+func writeSWIDTags(dir string, outcomes []engine.Outcome) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, outcome := range outcomes {
+		if outcome.Report == nil {
+			continue
 		}
+		for _, tag := range output.SWIDTags(outcome.Report.Result, outcome.Report.ProjectType) {
+			file, err := os.Create(filepath.Join(dir, tag.FileName))
+			if err != nil {
+				return err
+			}
+			if err := errors.Join(output.WriteSWIDTag(file, tag), file.Close()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
 
-		if !dep.IsDirectDep && dep.Parent != "" {
-			fmt.Fprintf(writer, "  Required by: %s\n", dep.Parent)
+// writeAnnotations writes the findings and vulnerabilities of the scanned
+// projects as GitHub Actions workflow commands. Annotations of a project
+// scanned from a directory point at its manifest, the first of the files its
+// scanner caches results on, relative to the workspace.
+func writeAnnotations(writer io.Writer, outcomes []engine.Outcome) error {
+	workspace := os.Getenv("GITHUB_WORKSPACE")
+	if workspace == "" {
+		workspace, _ = os.Getwd()
+	}
+	for _, outcome := range outcomes {
+		if outcome.Report == nil {
+			continue
+		}
+		var manifest output.Manifest
+		scanner, ok := scanners.Get(outcome.Report.ProjectType)
+		cacheable, cached := scanner.(scanners.CacheableScanner)
+		if info, err := os.Stat(outcome.Target.Path); ok && cached && err == nil && info.IsDir() && len(cacheable.CacheFiles()) > 0 {
+			file := filepath.Join(outcome.Target.Path, cacheable.CacheFiles()[0])
+			if abs, err := filepath.Abs(file); err == nil {
+				if rel, err := filepath.Rel(workspace, abs); err == nil && !strings.HasPrefix(rel, "..") {
+					manifest.Path = filepath.ToSlash(rel)
+					manifest.Content, _ = os.ReadFile(file)
+				}
+			}
 		}
+		if err := output.WriteGitHubAnnotations(writer, outcome.Report.Result, manifest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishFiles uploads the output file to the destination, named after its
+// digest, and the signature written next to it, if any, under the same name
+// with the signature's suffix
+func publishFiles(client *http.Client, dest publish.Destination, outputFile, signature string) error {
+	creds := publish.CredentialsFromEnv(os.Getenv)
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		return err
+	}
+	dest = dest.Name(content, filepath.Ext(outputFile))
+	if err := publish.Upload(context.Background(), client, dest, content, publish.ContentType(outputFile), creds); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Published %s\n", dest)
 
-		if replacedBy, ok := dep.Properties["replaced_by"]; ok {
-			fmt.Fprintf(writer, "  Replaced by: %s@%s\n", replacedBy, dep.Properties["replaced_version"])
+	if signature == "" {
+		return nil
+	}
+	if content, err = os.ReadFile(signature); err != nil {
+		return err
+	}
+	dest.Key += strings.TrimPrefix(signature, outputFile)
+	if err := publish.Upload(context.Background(), client, dest, content, publish.ContentType(signature), creds); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Published %s\n", dest)
+	return nil
+}
+
+// listFlag collects the values of a flag given several times
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// scanOrLoad scans the target, or reads the scan saved in scanFile by
+// scan -graph instead when it is set, exiting on failure
+func scanOrLoad(target engine.Target, opts scanners.ScanOptions, scanFile string) *engine.Report {
+	if scanFile != "" {
+		result, projectType, err := output.LoadFile(scanFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading scan: %v\n", err)
+			exit(1)
+		}
+		if result.Graph == nil {
+			fmt.Fprintf(os.Stderr, "%s has no dependency graph, write it with scan -graph\n", scanFile)
+			exit(1)
 		}
+		return &engine.Report{ProjectType: projectType, Result: result}
+	}
+
+	report, err := engine.Scan(context.Background(), target, opts)
+	if errors.Is(err, engine.ErrNoProject) {
+		fmt.Fprintf(os.Stderr, "No supported project found at %s\n", describeTarget(target))
+		exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error scanning dependencies: %v\n", err)
+		exit(1)
+	}
+	return report
+}
 
-		fmt.Fprintln(writer)
+func describeTarget(target engine.Target) string {
+	if target.Repo != "" {
+		return target.Repo
+	}
+	if absPath, err := filepath.Abs(target.Path); err == nil {
+		return absPath
 	}
+	return target.Path
 }
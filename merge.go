@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/output"
+	"github.com/santoshdahal12/deplister/pkg/sbom"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func runMerge(args []string) {
+	var (
+		projectPath  string
+		repoSpec     string
+		textOutput   bool
+		outputFile   string
+		prettyOutput bool
+		disabled     string
+		opts         = scanners.DefaultScanOptions()
+	)
+
+	flags := flag.NewFlagSet("merge", flag.ExitOnError)
+	flags.StringVar(&projectPath, "path", "", "Project directory or archive to scan and merge with the documents")
+	flags.StringVar(&repoSpec, "repo", "", "Git repository to clone, scan and merge with the documents, as url[@ref]")
+	flags.StringVar(&outputFile, "out", "", "Output file path (default: stdout)")
+	flags.BoolVar(&textOutput, "text", false, "Output in human-readable text format")
+	flags.BoolVar(&prettyOutput, "pretty", false, "Pretty print JSON output (ignored with -text)")
+	flags.StringVar(&disabled, "disable", "", "Comma separated list of scanner types to disable")
+	flags.BoolVar(&opts.IncludeDev, "include-dev", opts.IncludeDev, "Include development dependencies")
+	flags.BoolVar(&opts.Offline, "offline", opts.Offline, "Never access the network while scanning")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: deplister merge [flags] <sbom.json>...\n\nMerges CycloneDX and SPDX JSON documents, and optionally a fresh scan, deduplicating by package URL.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	opts.Offline = opts.Offline || offline
+
+	if flags.NArg() == 0 && projectPath == "" && repoSpec == "" {
+		flags.Usage()
+		exit(2)
+	}
+
+	var results []*scanners.ScanResult
+	var types []string
+	if projectPath != "" || repoSpec != "" {
+		setupScanners(disabled)
+		target := engine.Target{Path: projectPath}
+		if repoSpec != "" {
+			target = engine.Target{Repo: repoSpec}
+		}
+
+		report, err := engine.Scan(context.Background(), target, opts)
+		if errors.Is(err, engine.ErrNoProject) {
+			fmt.Fprintf(os.Stderr, "No supported project found at %s\n", describeTarget(target))
+			exit(1)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error scanning dependencies: %v\n", err)
+			exit(1)
+		}
+		results = append(results, report.Result)
+		types = append(types, report.ProjectType)
+	}
+
+	for _, path := range flags.Args() {
+		format, result, err := sbom.Load(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", path, err)
+			exit(1)
+		}
+		results = append(results, result)
+		if !slices.Contains(types, format) {
+			types = append(types, format)
+		}
+	}
+	merged := sbom.Merge(results...)
+
+	var writer io.Writer = os.Stdout
+	if outputFile != "" {
+		file, err := os.Create(outputFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
+			exit(1)
+		}
+		defer file.Close()
+		writer = file
+	}
+
+	projectType := strings.Join(types, ",")
+	var err error
+	if textOutput {
+		err = output.WriteText(writer, merged, projectType)
+	} else {
+		err = output.WriteJSON(writer, merged, projectType, prettyOutput)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		exit(1)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/santoshdahal12/deplister/pkg/httpclient"
+)
+
+// networkFlags adds the flags configuring how a command reaches the network
+func networkFlags(flags *flag.FlagSet) *httpclient.Config {
+	config := &httpclient.Config{}
+	flags.StringVar(&config.Proxy, "proxy", "", "Proxy URL for network requests, with user:password for an authenticated proxy (default: HTTPS_PROXY and HTTP_PROXY)")
+	flags.StringVar(&config.CAFile, "ca-file", "", "PEM bundle of certificate authorities to trust besides the system ones")
+	flags.DurationVar(&config.Timeout, "http-timeout", 0, "Timeout of each network request, e.g. 30s (default: none)")
+	flags.IntVar(&config.Retries, "http-retries", 3, "Times to retry network requests failing with a connection error or a 5xx or 429 status, with exponential backoff")
+	flags.Float64Var(&config.Rate, "http-rate", 0, "Requests per second to send to each registry or API at most (default: unlimited)")
+	return config
+}
+
+// httpClient creates the client of the network flags, which fails every
+// request in offline mode, exiting on failure
+func httpClient(config *httpclient.Config) *http.Client {
+	config.Offline = config.Offline || offline
+	client, err := httpclient.New(*config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error configuring network access: %v\n", err)
+		exit(2)
+	}
+	return client
+}
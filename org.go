@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/github"
+	"github.com/santoshdahal12/deplister/pkg/gitlab"
+	"github.com/santoshdahal12/deplister/pkg/output"
+	"github.com/santoshdahal12/deplister/pkg/remote"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func runOrg(args []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprintln(os.Stderr, "Usage: deplister org scan [options]")
+		exit(2)
+	}
+
+	switch args[0] {
+	case "scan":
+		runOrgScan(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown org command %q\n", args[0])
+		fmt.Fprintln(os.Stderr, "Available org commands: scan")
+		exit(2)
+	}
+}
+
+// orgRepo is a repository listed by the GitHub or GitLab API
+type orgRepo struct {
+	name     string
+	cloneURL string
+	branch   string
+	fsys     fs.FS // Contents read through the API instead of a clone
+}
+
+func runOrgScan(args []string) {
+	var (
+		githubOrg       string
+		gitlabGroup     string
+		apiURL          string
+		token           string
+		includeArchived bool
+		includeForks    bool
+		noClone         bool
+		outputFile      string
+		textOutput      bool
+		prettyOutput    bool
+		fieldList       string
+		fields          []string
+		disabled        string
+		concurrency     int
+		opts            = scanners.DefaultScanOptions()
+	)
+
+	flags := flag.NewFlagSet("org scan", flag.ExitOnError)
+	flags.StringVar(&githubOrg, "github-org", "", "GitHub organization whose repositories to scan")
+	flags.StringVar(&gitlabGroup, "gitlab-group", "", "GitLab group whose projects, including those of its subgroups, to scan")
+	flags.StringVar(&apiURL, "api-url", "", "API URL of GitHub Enterprise or a self-managed GitLab (default: GITHUB_API_URL or CI_API_V4_URL, then the public API)")
+	flags.StringVar(&token, "token", "", "Token listing and cloning the repositories (default: GITHUB_TOKEN or GITLAB_TOKEN)")
+	flags.BoolVar(&includeArchived, "include-archived", false, "Also scan archived repositories")
+	flags.BoolVar(&includeForks, "include-forks", false, "Also scan forks")
+	flags.BoolVar(&noClone, "no-clone", false, "Read the manifests and lockfiles of GitHub repositories through the contents API instead of cloning them; Go modules are scanned from go.mod as with -offline")
+	flags.StringVar(&outputFile, "out", "", "Output file path (default: stdout)")
+	flags.BoolVar(&textOutput, "text", false, "Output in human-readable text format")
+	flags.BoolVar(&prettyOutput, "pretty", false, "Pretty print JSON output (ignored with -text)")
+	flags.StringVar(&fieldList, "fields", "", "Comma separated dependency fields to keep in JSON output, as with scan -fields")
+	flags.StringVar(&disabled, "disable", "", "Comma separated list of scanner types to disable")
+	flags.IntVar(&concurrency, "concurrency", 0, "Number of repositories to clone and scan at once (default: one per CPU)")
+	flags.BoolVar(&opts.IncludeDev, "include-dev", opts.IncludeDev, "Include development dependencies")
+	network := networkFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: deplister org scan -github-org <org> | -gitlab-group <group> [flags]\n\nLists the repositories of a GitHub organization or GitLab group, clones the default branch of each, scans them in parallel and writes one aggregate report.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if (githubOrg == "") == (gitlabGroup == "") {
+		fmt.Fprintln(os.Stderr, "Exactly one of -github-org and -gitlab-group is required")
+		exit(2)
+	}
+	if noClone && githubOrg == "" {
+		fmt.Fprintln(os.Stderr, "-no-clone is only supported with -github-org")
+		exit(2)
+	}
+	if fieldList != "" {
+		var err error
+		if fields, err = output.ParseFields(fieldList); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -fields: %v\n", err)
+			exit(2)
+		}
+	}
+	if offline {
+		fmt.Fprintln(os.Stderr, "org scan lists and clones repositories over the network and cannot run offline")
+		exit(2)
+	}
+	opts.HTTPClient = httpClient(network)
+
+	ctx := context.Background()
+	var (
+		repos []orgRepo
+		err   error
+	)
+	if githubOrg != "" {
+		if apiURL == "" {
+			apiURL = envOr("GITHUB_API_URL", github.DefaultAPIURL)
+		}
+		if token == "" {
+			token = os.Getenv("GITHUB_TOKEN")
+		}
+		repos, err = githubRepos(ctx, opts.HTTPClient, apiURL, token, githubOrg, includeArchived, includeForks, noClone)
+	} else {
+		if apiURL == "" {
+			apiURL = envOr("CI_API_V4_URL", gitlab.DefaultAPIURL)
+		}
+		if token == "" {
+			token = os.Getenv("GITLAB_TOKEN")
+		}
+		repos, err = gitlabRepos(ctx, opts.HTTPClient, apiURL, token, gitlabGroup, includeArchived, includeForks)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing repositories: %v\n", err)
+		exit(1)
+	}
+	if len(repos) == 0 {
+		fmt.Fprintln(os.Stderr, "No repositories to scan")
+		exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Scanning %d repositories\n", len(repos))
+
+	setupScanners(disabled)
+	specs := make([]engine.Target, len(repos))
+	targets := make([]engine.Target, len(repos))
+	for i, repo := range repos {
+		specs[i] = engine.Target{Repo: repo.cloneURL}
+		if repo.branch != "" {
+			specs[i].Repo += "@" + repo.branch
+		}
+		targets[i] = specs[i]
+		if repo.fsys != nil {
+			targets[i] = engine.Target{FS: repo.fsys}
+		}
+	}
+
+	var (
+		scanned  []engine.Outcome
+		skipped  int
+		failed   int
+		deps     int
+		affected int
+	)
+	for i, outcome := range engine.ScanAll(ctx, targets, opts, concurrency) {
+		// Repositories read through the API are reported by their URL too
+		outcome.Target = specs[i]
+		switch {
+		case errors.Is(outcome.Err, engine.ErrNoProject):
+			skipped++
+			continue
+		case outcome.Err != nil:
+			fmt.Fprintf(os.Stderr, "Error scanning %s: %v\n", repos[i].name, outcome.Err)
+			failed++
+		default:
+			deps += len(outcome.Report.Result.Dependencies)
+			for _, dep := range outcome.Report.Result.Dependencies {
+				if len(dep.Vulnerabilities) > 0 {
+					affected++
+				}
+			}
+		}
+		scanned = append(scanned, outcome)
+	}
+	fmt.Fprintf(os.Stderr, "Scanned %d repositories: %d dependencies, %d with known vulnerabilities; %d without a supported project, %d failed\n",
+		len(scanned)-failed, deps, affected, skipped, failed)
+
+	var writer io.Writer = os.Stdout
+	var file *os.File
+	if outputFile != "" {
+		if file, err = os.Create(outputFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
+			exit(1)
+		}
+		writer = file
+	}
+	err = writeProjects(writer, scanned, textOutput, prettyOutput, "", fields)
+	if file != nil {
+		err = errors.Join(err, file.Close())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		exit(1)
+	}
+
+	if failed > 0 {
+		exit(1)
+	}
+}
+
+// githubRepos lists the repositories of a GitHub organization to scan and
+// authenticates their clones with the token, or reads them through the API
+// instead with noClone
+func githubRepos(ctx context.Context, httpClient *http.Client, apiURL, token, org string, archived, forks, noClone bool) ([]orgRepo, error) {
+	client := github.NewClient(token)
+	client.APIURL, client.HTTPClient = apiURL, httpClient
+
+	listed, err := client.OrgRepos(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	var repos []orgRepo
+	for _, repo := range listed {
+		if (repo.Archived && !archived) || (repo.Fork && !forks) {
+			continue
+		}
+		found := orgRepo{name: repo.FullName, cloneURL: repo.CloneURL, branch: repo.DefaultBranch}
+		if noClone {
+			if found.fsys, err = client.RepoFS(ctx, repo.FullName, repo.DefaultBranch); err != nil {
+				return nil, err
+			}
+		}
+		repos = append(repos, found)
+	}
+	authenticate(repos, "x-access-token", token)
+	return repos, nil
+}
+
+// gitlabRepos lists the projects of a GitLab group to scan and authenticates
+// their clones with the token
+func gitlabRepos(ctx context.Context, httpClient *http.Client, apiURL, token, group string, archived, forks bool) ([]orgRepo, error) {
+	client := gitlab.NewClient(token)
+	client.APIURL, client.HTTPClient = apiURL, httpClient
+
+	listed, err := client.GroupProjects(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+	var repos []orgRepo
+	for _, project := range listed {
+		if project.EmptyRepo || (project.Archived && !archived) || (project.ForkedFrom != nil && !forks) {
+			continue
+		}
+		repos = append(repos, orgRepo{name: project.PathWithNamespace, cloneURL: project.CloneURL, branch: project.DefaultBranch})
+	}
+	authenticate(repos, "oauth2", token)
+	return repos, nil
+}
+
+// authenticate sends the token with the clones of every host the
+// repositories are on
+func authenticate(repos []orgRepo, username, token string) {
+	if token == "" {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, repo := range repos {
+		u, err := url.Parse(repo.cloneURL)
+		if err != nil || u.Host == "" {
+			continue
+		}
+		prefix := u.Scheme + "://" + u.Host + "/"
+		if !seen[prefix] {
+			seen[prefix] = true
+			remote.AddCredential(remote.Credential{Prefix: prefix, Username: username, Password: token})
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package abandoned
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Enrichment is the name of the abandoned package check in ScanOptions.Enrich
+const Enrichment = "abandoned"
+
+// Rule is the rule name of abandoned package findings
+const Rule = "abandoned"
+
+// DefaultDays is the number of days without a release after which a package
+// counts as abandoned, about three years
+const DefaultDays = 3 * 365
+
+// now is replaced by tests
+var now = time.Now
+
+// Enrich records the publication date of the latest release of every
+// dependency as the "lastRelease" property, and reports packages without a
+// release in the given number of days as warnings. A non-positive days uses
+// DefaultDays. Packages missing from their registry or without publication
+// times are left untouched.
+func Enrich(ctx context.Context, source registry.Source, result *scanners.ScanResult, days int) error {
+	if days <= 0 {
+		days = DefaultDays
+	}
+	cutoff := now().AddDate(0, 0, -days)
+
+	packages, err := registry.Lookup(ctx, source, result.Dependencies)
+	if err != nil {
+		return err
+	}
+
+	for i := range result.Dependencies {
+		dep := &result.Dependencies[i]
+		pkg, ok := packages[registry.Key{Type: dep.Type, Name: dep.Name}]
+		if !ok {
+			continue
+		}
+		last := pkg.LastRelease()
+		if last.IsZero() {
+			continue
+		}
+
+		if dep.Properties == nil {
+			dep.Properties = make(map[string]string)
+		}
+		dep.Properties["lastRelease"] = last.UTC().Format(time.DateOnly)
+		if last.Before(cutoff) {
+			result.Findings = append(result.Findings, scanners.Finding{
+				Rule:       Rule,
+				Severity:   scanners.SeverityWarning,
+				Dependency: dep.Name,
+				Version:    dep.Version,
+				Message:    fmt.Sprintf("%s has had no release since %s (%s)", dep.Name, last.UTC().Format(time.DateOnly), age(now().Sub(last))),
+			})
+		}
+	}
+	return nil
+}
+
+// age describes a duration in whole years, or months when shorter
+func age(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	switch years := days / 365; {
+	case years == 1:
+		return "1 year"
+	case years > 1:
+		return fmt.Sprintf("%d years", years)
+	}
+	if months := days / 30; months != 1 {
+		return fmt.Sprintf("%d months", months)
+	}
+	return "1 month"
+}
@@ -0,0 +1,79 @@
+package abandoned
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+type fakeSource map[string]*registry.Package
+
+func (s fakeSource) Package(ctx context.Context, depType, name string) (*registry.Package, error) {
+	if pkg, ok := s[name]; ok {
+		return pkg, nil
+	}
+	return nil, registry.ErrNotFound
+}
+
+func TestEnrich(t *testing.T) {
+	now = func() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "left-pad", Version: "1.3.0", Type: "npm"},
+			{Name: "express", Version: "4.18.2", Type: "npm"},
+			{Name: "example.com/old", Version: "v1.0.0", Type: "go"},
+			{Name: "unknown", Version: "1.0.0", Type: "npm"},
+			{Name: "private", Version: "1.0.0", Type: "npm"},
+		},
+	}
+	source := fakeSource{
+		"left-pad": {Published: map[string]time.Time{
+			"1.3.0": time.Date(2018, 4, 9, 0, 0, 0, 0, time.UTC),
+			"1.2.0": time.Date(2017, 11, 1, 0, 0, 0, 0, time.UTC),
+		}},
+		"express":         {Published: map[string]time.Time{"4.19.2": time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC)}},
+		"example.com/old": {Published: map[string]time.Time{"v1.0.0": time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		"unknown":         {},
+	}
+
+	if !assert.NoError(t, Enrich(context.Background(), source, result, 0)) {
+		return
+	}
+
+	assert.Equal(t, "2018-04-09", result.Dependencies[0].Properties["lastRelease"])
+	assert.Equal(t, "2024-03-25", result.Dependencies[1].Properties["lastRelease"])
+	assert.Equal(t, "2022-01-01", result.Dependencies[2].Properties["lastRelease"])
+	assert.Nil(t, result.Dependencies[3].Properties)
+	assert.Nil(t, result.Dependencies[4].Properties)
+
+	assert.Equal(t, []scanners.Finding{{
+		Rule:       Rule,
+		Severity:   scanners.SeverityWarning,
+		Dependency: "left-pad",
+		Version:    "1.3.0",
+		Message:    "left-pad has had no release since 2018-04-09 (6 years)",
+	}}, result.Findings)
+
+	// A shorter threshold also flags the Go module
+	result.Findings = nil
+	assert.NoError(t, Enrich(context.Background(), source, result, 365))
+	if assert.Len(t, result.Findings, 2) {
+		assert.Equal(t, "example.com/old has had no release since 2022-01-01 (2 years)", result.Findings[1].Message)
+	}
+}
+
+func TestAge(t *testing.T) {
+	day := 24 * time.Hour
+	assert.Equal(t, "0 months", age(10*day))
+	assert.Equal(t, "1 month", age(40*day))
+	assert.Equal(t, "11 months", age(340*day))
+	assert.Equal(t, "1 year", age(400*day))
+	assert.Equal(t, "3 years", age(3*366*day))
+}
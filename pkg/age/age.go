@@ -0,0 +1,177 @@
+package age
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/module"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/version"
+)
+
+// Enrichment is the name of the dependency age report in ScanOptions.Enrich
+const Enrichment = "age"
+
+// Dependency properties recorded by Enrich
+const (
+	Released       = "released"
+	DaysSince      = "daysSinceRelease"
+	VersionsBehind = "versionsBehind"
+)
+
+// StaleDays is the age in days after which a dependency counts as stale in
+// the distribution, about two years
+const StaleDays = 2 * 365
+
+// concurrency is the number of Go module publication times looked up in parallel
+const concurrency = 8
+
+// now is replaced by tests
+var now = time.Now
+
+// Source provides registry metadata and Go module publication times
+type Source interface {
+	registry.Source
+	ModuleTime(ctx context.Context, path, version string) (time.Time, error)
+}
+
+// Enrich records when the resolved version of every dependency was published
+// as the "released" property, the days since then as "daysSinceRelease", and
+// the number of stable releases between it and the latest version as
+// "versionsBehind". Dependencies missing from their registry or without
+// publication times are left untouched.
+func Enrich(ctx context.Context, source Source, result *scanners.ScanResult) error {
+	packages, err := registry.Lookup(ctx, source, result.Dependencies)
+	if err != nil {
+		return err
+	}
+
+	released := make([]time.Time, len(result.Dependencies))
+	errs := make([]error, len(result.Dependencies))
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, concurrency)
+	for i, dep := range result.Dependencies {
+		if pkg, ok := packages[registry.Key{Type: dep.Type, Name: dep.Name}]; ok {
+			if published, ok := pkg.Published[dep.Version]; ok {
+				released[i] = published
+				continue
+			}
+		}
+		if dep.Type != "go" || dep.Version == "" {
+			continue
+		}
+		// Pseudo-versions carry the commit time
+		if published, err := module.PseudoVersionTime(dep.Version); err == nil {
+			released[i] = published
+			continue
+		}
+		wg.Add(1)
+		limit <- struct{}{}
+		go func() {
+			defer func() { <-limit; wg.Done() }()
+			released[i], errs[i] = source.ModuleTime(ctx, dep.Name, dep.Version)
+			if errors.Is(errs[i], registry.ErrNotFound) {
+				errs[i] = nil
+			}
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	for i := range result.Dependencies {
+		dep := &result.Dependencies[i]
+		pkg, known := packages[registry.Key{Type: dep.Type, Name: dep.Name}]
+		if released[i].IsZero() && !known {
+			continue
+		}
+
+		if dep.Properties == nil {
+			dep.Properties = make(map[string]string)
+		}
+		if !released[i].IsZero() {
+			dep.Properties[Released] = released[i].UTC().Format(time.DateOnly)
+			dep.Properties[DaysSince] = strconv.Itoa(days(now().Sub(released[i])))
+		}
+		if known && pkg.Latest != "" && dep.Version != "" {
+			dep.Properties[VersionsBehind] = strconv.Itoa(behind(pkg, dep.Version))
+		}
+	}
+	return nil
+}
+
+// behind counts the stable versions published after v up to the latest
+func behind(pkg *registry.Package, v string) int {
+	count := 0
+	for _, published := range pkg.Versions {
+		if !version.Prerelease(published) && version.Compare(published, v) > 0 && version.Compare(published, pkg.Latest) <= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func days(d time.Duration) int {
+	return max(0, int(d.Hours()/24))
+}
+
+// Bucket counts the dependencies released within an age range
+type Bucket struct {
+	Label string
+	Count int
+}
+
+// Distribution summarizes the ages recorded by Enrich
+type Distribution struct {
+	Dated   int      // Number of dependencies with a known release date
+	Stale   int      // Dependencies released more than StaleDays ago
+	Behind  int      // Dependencies with newer stable releases
+	Buckets []Bucket // Dependencies by age, youngest first
+}
+
+// buckets are the upper bounds in days of each age range
+var buckets = []struct {
+	label string
+	days  int
+}{
+	{"< 6 months", 182},
+	{"6-12 months", 365},
+	{"1-2 years", StaleDays},
+	{"> 2 years", -1},
+}
+
+// Summarize counts the dependencies by age. It reports false when no
+// dependency has a release date.
+func Summarize(deps []scanners.Dependency) (Distribution, bool) {
+	distribution := Distribution{Buckets: make([]Bucket, len(buckets))}
+	for i, bucket := range buckets {
+		distribution.Buckets[i].Label = bucket.label
+	}
+
+	for _, dep := range deps {
+		if n, err := strconv.Atoi(dep.Properties[VersionsBehind]); err == nil && n > 0 {
+			distribution.Behind++
+		}
+		age, err := strconv.Atoi(dep.Properties[DaysSince])
+		if err != nil {
+			continue
+		}
+		distribution.Dated++
+		if age > StaleDays {
+			distribution.Stale++
+		}
+		for i, bucket := range buckets {
+			if bucket.days < 0 || age <= bucket.days {
+				distribution.Buckets[i].Count++
+				break
+			}
+		}
+	}
+	return distribution, distribution.Dated > 0
+}
@@ -0,0 +1,94 @@
+package age
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+type fakeSource struct {
+	packages map[string]*registry.Package
+	modules  map[string]time.Time
+}
+
+func (f fakeSource) Package(ctx context.Context, depType, name string) (*registry.Package, error) {
+	if pkg, ok := f.packages[name]; ok {
+		return pkg, nil
+	}
+	return nil, registry.ErrNotFound
+}
+
+func (f fakeSource) ModuleTime(ctx context.Context, path, version string) (time.Time, error) {
+	if published, ok := f.modules[path+"@"+version]; ok {
+		return published, nil
+	}
+	return time.Time{}, registry.ErrNotFound
+}
+
+func TestEnrich(t *testing.T) {
+	now = func() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	source := fakeSource{
+		packages: map[string]*registry.Package{
+			"express": {
+				Name:     "express",
+				Latest:   "4.19.2",
+				Versions: []string{"4.17.1", "4.18.0", "4.18.2", "4.19.0", "4.19.2", "5.0.0-beta.3"},
+				Published: map[string]time.Time{
+					"4.17.1": time.Date(2019, 5, 26, 0, 0, 0, 0, time.UTC),
+					"4.19.2": time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			"github.com/spf13/cobra": {
+				Name:      "github.com/spf13/cobra",
+				Latest:    "v1.8.0",
+				Versions:  []string{"v1.7.0", "v1.8.0"},
+				Published: map[string]time.Time{"v1.8.0": time.Date(2023, 11, 4, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		modules: map[string]time.Time{
+			"github.com/spf13/cobra@v1.7.0": time.Date(2023, 4, 4, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "express", Version: "4.17.1", Type: "npm"},
+			{Name: "express", Version: "4.19.2", Type: "npm"},
+			{Name: "private", Version: "1.0.0", Type: "npm"},
+			{Name: "github.com/spf13/cobra", Version: "v1.7.0", Type: "go"},
+			{Name: "example.com/untagged", Version: "v0.0.0-20210101120000-abcdefabcdef", Type: "go"},
+		},
+	}
+
+	if !assert.NoError(t, Enrich(context.Background(), source, result)) {
+		return
+	}
+	assert.Equal(t, map[string]string{Released: "2019-05-26", DaysSince: "1833", VersionsBehind: "4"}, result.Dependencies[0].Properties)
+	assert.Equal(t, map[string]string{Released: "2024-03-25", DaysSince: "68", VersionsBehind: "0"}, result.Dependencies[1].Properties)
+	assert.Nil(t, result.Dependencies[2].Properties)
+	assert.Equal(t, map[string]string{Released: "2023-04-04", DaysSince: "424", VersionsBehind: "1"}, result.Dependencies[3].Properties)
+	assert.Equal(t, map[string]string{Released: "2021-01-01", DaysSince: "1246"}, result.Dependencies[4].Properties)
+
+	distribution, ok := Summarize(result.Dependencies)
+	assert.True(t, ok)
+	assert.Equal(t, Distribution{
+		Dated:  4,
+		Stale:  2,
+		Behind: 2,
+		Buckets: []Bucket{
+			{Label: "< 6 months", Count: 1},
+			{Label: "6-12 months", Count: 0},
+			{Label: "1-2 years", Count: 1},
+			{Label: "> 2 years", Count: 2},
+		},
+	}, distribution)
+
+	_, ok = Summarize(result.Dependencies[2:3])
+	assert.False(t, ok)
+}
@@ -0,0 +1,985 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: deplister/v1/deplister.proto
+
+package deplisterv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Target identifies what to scan
+type Target struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Source:
+	//
+	//	*Target_Path
+	//	*Target_Repo
+	//	*Target_Archive
+	Source        isTarget_Source `protobuf_oneof:"source"`
+	ArchiveName   string          `protobuf:"bytes,4,opt,name=archive_name,json=archiveName,proto3" json:"archive_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Target) Reset() {
+	*x = Target{}
+	mi := &file_deplister_v1_deplister_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Target) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Target) ProtoMessage() {}
+
+func (x *Target) ProtoReflect() protoreflect.Message {
+	mi := &file_deplister_v1_deplister_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Target.ProtoReflect.Descriptor instead.
+func (*Target) Descriptor() ([]byte, []int) {
+	return file_deplister_v1_deplister_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Target) GetSource() isTarget_Source {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+func (x *Target) GetPath() string {
+	if x != nil {
+		if x, ok := x.Source.(*Target_Path); ok {
+			return x.Path
+		}
+	}
+	return ""
+}
+
+func (x *Target) GetRepo() string {
+	if x != nil {
+		if x, ok := x.Source.(*Target_Repo); ok {
+			return x.Repo
+		}
+	}
+	return ""
+}
+
+func (x *Target) GetArchive() []byte {
+	if x != nil {
+		if x, ok := x.Source.(*Target_Archive); ok {
+			return x.Archive
+		}
+	}
+	return nil
+}
+
+func (x *Target) GetArchiveName() string {
+	if x != nil {
+		return x.ArchiveName
+	}
+	return ""
+}
+
+type isTarget_Source interface {
+	isTarget_Source()
+}
+
+type Target_Path struct {
+	// Path on the server's file system
+	Path string `protobuf:"bytes,1,opt,name=path,proto3,oneof"`
+}
+
+type Target_Repo struct {
+	// Git repository as url[@ref]
+	Repo string `protobuf:"bytes,2,opt,name=repo,proto3,oneof"`
+}
+
+type Target_Archive struct {
+	// Archive contents; archive_name carries the format
+	Archive []byte `protobuf:"bytes,3,opt,name=archive,proto3,oneof"`
+}
+
+func (*Target_Path) isTarget_Source() {}
+
+func (*Target_Repo) isTarget_Source() {}
+
+func (*Target_Archive) isTarget_Source() {}
+
+type ScanOptions struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	IncludeDev       bool                   `protobuf:"varint,1,opt,name=include_dev,json=includeDev,proto3" json:"include_dev,omitempty"`
+	FollowWorkspaces bool                   `protobuf:"varint,2,opt,name=follow_workspaces,json=followWorkspaces,proto3" json:"follow_workspaces,omitempty"`
+	Offline          bool                   `protobuf:"varint,3,opt,name=offline,proto3" json:"offline,omitempty"`
+	MaxDepth         int32                  `protobuf:"varint,4,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`
+	Enrich           map[string]bool        `protobuf:"bytes,5,rep,name=enrich,proto3" json:"enrich,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ScanOptions) Reset() {
+	*x = ScanOptions{}
+	mi := &file_deplister_v1_deplister_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanOptions) ProtoMessage() {}
+
+func (x *ScanOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_deplister_v1_deplister_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanOptions.ProtoReflect.Descriptor instead.
+func (*ScanOptions) Descriptor() ([]byte, []int) {
+	return file_deplister_v1_deplister_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ScanOptions) GetIncludeDev() bool {
+	if x != nil {
+		return x.IncludeDev
+	}
+	return false
+}
+
+func (x *ScanOptions) GetFollowWorkspaces() bool {
+	if x != nil {
+		return x.FollowWorkspaces
+	}
+	return false
+}
+
+func (x *ScanOptions) GetOffline() bool {
+	if x != nil {
+		return x.Offline
+	}
+	return false
+}
+
+func (x *ScanOptions) GetMaxDepth() int32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *ScanOptions) GetEnrich() map[string]bool {
+	if x != nil {
+		return x.Enrich
+	}
+	return nil
+}
+
+type ScanRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Target *Target                `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	// Defaults are used when options are omitted
+	Options       *ScanOptions `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanRequest) Reset() {
+	*x = ScanRequest{}
+	mi := &file_deplister_v1_deplister_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanRequest) ProtoMessage() {}
+
+func (x *ScanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deplister_v1_deplister_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanRequest.ProtoReflect.Descriptor instead.
+func (*ScanRequest) Descriptor() ([]byte, []int) {
+	return file_deplister_v1_deplister_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ScanRequest) GetTarget() *Target {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+func (x *ScanRequest) GetOptions() *ScanOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type Project struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectType   string                 `protobuf:"bytes,1,opt,name=project_type,json=projectType,proto3" json:"project_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Project) Reset() {
+	*x = Project{}
+	mi := &file_deplister_v1_deplister_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Project) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Project) ProtoMessage() {}
+
+func (x *Project) ProtoReflect() protoreflect.Message {
+	mi := &file_deplister_v1_deplister_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Project.ProtoReflect.Descriptor instead.
+func (*Project) Descriptor() ([]byte, []int) {
+	return file_deplister_v1_deplister_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Project) GetProjectType() string {
+	if x != nil {
+		return x.ProjectType
+	}
+	return ""
+}
+
+type DependencyPath struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          []string               `protobuf:"bytes,1,rep,name=path,proto3" json:"path,omitempty"`
+	Depth         int32                  `protobuf:"varint,2,opt,name=depth,proto3" json:"depth,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DependencyPath) Reset() {
+	*x = DependencyPath{}
+	mi := &file_deplister_v1_deplister_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DependencyPath) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DependencyPath) ProtoMessage() {}
+
+func (x *DependencyPath) ProtoReflect() protoreflect.Message {
+	mi := &file_deplister_v1_deplister_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DependencyPath.ProtoReflect.Descriptor instead.
+func (*DependencyPath) Descriptor() ([]byte, []int) {
+	return file_deplister_v1_deplister_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DependencyPath) GetPath() []string {
+	if x != nil {
+		return x.Path
+	}
+	return nil
+}
+
+func (x *DependencyPath) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+type Dependency struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Name               string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version            string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Type               string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	IsDirectDependency bool                   `protobuf:"varint,4,opt,name=is_direct_dependency,json=isDirectDependency,proto3" json:"is_direct_dependency,omitempty"`
+	Parent             string                 `protobuf:"bytes,5,opt,name=parent,proto3" json:"parent,omitempty"`
+	Parents            []string               `protobuf:"bytes,6,rep,name=parents,proto3" json:"parents,omitempty"`
+	Paths              []*DependencyPath      `protobuf:"bytes,7,rep,name=paths,proto3" json:"paths,omitempty"`
+	Properties         map[string]string      `protobuf:"bytes,8,rep,name=properties,proto3" json:"properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Depth              int32                  `protobuf:"varint,9,opt,name=depth,proto3" json:"depth,omitempty"`
+	// Known vulnerabilities, when the "vulns" enrichment is enabled
+	Vulnerabilities []*Vulnerability `protobuf:"bytes,10,rep,name=vulnerabilities,proto3" json:"vulnerabilities,omitempty"`
+	// SPDX license identifier or expression, when known
+	License       string `protobuf:"bytes,11,opt,name=license,proto3" json:"license,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Dependency) Reset() {
+	*x = Dependency{}
+	mi := &file_deplister_v1_deplister_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Dependency) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Dependency) ProtoMessage() {}
+
+func (x *Dependency) ProtoReflect() protoreflect.Message {
+	mi := &file_deplister_v1_deplister_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Dependency.ProtoReflect.Descriptor instead.
+func (*Dependency) Descriptor() ([]byte, []int) {
+	return file_deplister_v1_deplister_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Dependency) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Dependency) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Dependency) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Dependency) GetIsDirectDependency() bool {
+	if x != nil {
+		return x.IsDirectDependency
+	}
+	return false
+}
+
+func (x *Dependency) GetParent() string {
+	if x != nil {
+		return x.Parent
+	}
+	return ""
+}
+
+func (x *Dependency) GetParents() []string {
+	if x != nil {
+		return x.Parents
+	}
+	return nil
+}
+
+func (x *Dependency) GetPaths() []*DependencyPath {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
+func (x *Dependency) GetProperties() map[string]string {
+	if x != nil {
+		return x.Properties
+	}
+	return nil
+}
+
+func (x *Dependency) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *Dependency) GetVulnerabilities() []*Vulnerability {
+	if x != nil {
+		return x.Vulnerabilities
+	}
+	return nil
+}
+
+func (x *Dependency) GetLicense() string {
+	if x != nil {
+		return x.License
+	}
+	return ""
+}
+
+type Vulnerability struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Aliases []string               `protobuf:"bytes,2,rep,name=aliases,proto3" json:"aliases,omitempty"`
+	Summary string                 `protobuf:"bytes,3,opt,name=summary,proto3" json:"summary,omitempty"`
+	// LOW, MEDIUM, HIGH or CRITICAL when known
+	Severity      string   `protobuf:"bytes,4,opt,name=severity,proto3" json:"severity,omitempty"`
+	Cvss          string   `protobuf:"bytes,5,opt,name=cvss,proto3" json:"cvss,omitempty"`
+	FixedVersions []string `protobuf:"bytes,6,rep,name=fixed_versions,json=fixedVersions,proto3" json:"fixed_versions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Vulnerability) Reset() {
+	*x = Vulnerability{}
+	mi := &file_deplister_v1_deplister_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Vulnerability) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Vulnerability) ProtoMessage() {}
+
+func (x *Vulnerability) ProtoReflect() protoreflect.Message {
+	mi := &file_deplister_v1_deplister_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Vulnerability.ProtoReflect.Descriptor instead.
+func (*Vulnerability) Descriptor() ([]byte, []int) {
+	return file_deplister_v1_deplister_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Vulnerability) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetAliases() []string {
+	if x != nil {
+		return x.Aliases
+	}
+	return nil
+}
+
+func (x *Vulnerability) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetCvss() string {
+	if x != nil {
+		return x.Cvss
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetFixedVersions() []string {
+	if x != nil {
+		return x.FixedVersions
+	}
+	return nil
+}
+
+type ScanResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*ScanResponse_Project
+	//	*ScanResponse_Dependency
+	Result        isScanResponse_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanResponse) Reset() {
+	*x = ScanResponse{}
+	mi := &file_deplister_v1_deplister_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanResponse) ProtoMessage() {}
+
+func (x *ScanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deplister_v1_deplister_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanResponse.ProtoReflect.Descriptor instead.
+func (*ScanResponse) Descriptor() ([]byte, []int) {
+	return file_deplister_v1_deplister_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ScanResponse) GetResult() isScanResponse_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *ScanResponse) GetProject() *Project {
+	if x != nil {
+		if x, ok := x.Result.(*ScanResponse_Project); ok {
+			return x.Project
+		}
+	}
+	return nil
+}
+
+func (x *ScanResponse) GetDependency() *Dependency {
+	if x != nil {
+		if x, ok := x.Result.(*ScanResponse_Dependency); ok {
+			return x.Dependency
+		}
+	}
+	return nil
+}
+
+type isScanResponse_Result interface {
+	isScanResponse_Result()
+}
+
+type ScanResponse_Project struct {
+	Project *Project `protobuf:"bytes,1,opt,name=project,proto3,oneof"`
+}
+
+type ScanResponse_Dependency struct {
+	Dependency *Dependency `protobuf:"bytes,2,opt,name=dependency,proto3,oneof"`
+}
+
+func (*ScanResponse_Project) isScanResponse_Result() {}
+
+func (*ScanResponse_Dependency) isScanResponse_Result() {}
+
+type DetectProjectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Target        *Target                `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetectProjectRequest) Reset() {
+	*x = DetectProjectRequest{}
+	mi := &file_deplister_v1_deplister_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetectProjectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectProjectRequest) ProtoMessage() {}
+
+func (x *DetectProjectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deplister_v1_deplister_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectProjectRequest.ProtoReflect.Descriptor instead.
+func (*DetectProjectRequest) Descriptor() ([]byte, []int) {
+	return file_deplister_v1_deplister_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DetectProjectRequest) GetTarget() *Target {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+type DetectProjectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Detected      bool                   `protobuf:"varint,1,opt,name=detected,proto3" json:"detected,omitempty"`
+	ProjectType   string                 `protobuf:"bytes,2,opt,name=project_type,json=projectType,proto3" json:"project_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetectProjectResponse) Reset() {
+	*x = DetectProjectResponse{}
+	mi := &file_deplister_v1_deplister_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetectProjectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectProjectResponse) ProtoMessage() {}
+
+func (x *DetectProjectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deplister_v1_deplister_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectProjectResponse.ProtoReflect.Descriptor instead.
+func (*DetectProjectResponse) Descriptor() ([]byte, []int) {
+	return file_deplister_v1_deplister_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DetectProjectResponse) GetDetected() bool {
+	if x != nil {
+		return x.Detected
+	}
+	return false
+}
+
+func (x *DetectProjectResponse) GetProjectType() string {
+	if x != nil {
+		return x.ProjectType
+	}
+	return ""
+}
+
+type ListScannersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScannersRequest) Reset() {
+	*x = ListScannersRequest{}
+	mi := &file_deplister_v1_deplister_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScannersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScannersRequest) ProtoMessage() {}
+
+func (x *ListScannersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_deplister_v1_deplister_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScannersRequest.ProtoReflect.Descriptor instead.
+func (*ListScannersRequest) Descriptor() ([]byte, []int) {
+	return file_deplister_v1_deplister_proto_rawDescGZIP(), []int{10}
+}
+
+type ListScannersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Types         []string               `protobuf:"bytes,1,rep,name=types,proto3" json:"types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScannersResponse) Reset() {
+	*x = ListScannersResponse{}
+	mi := &file_deplister_v1_deplister_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScannersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScannersResponse) ProtoMessage() {}
+
+func (x *ListScannersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_deplister_v1_deplister_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScannersResponse.ProtoReflect.Descriptor instead.
+func (*ListScannersResponse) Descriptor() ([]byte, []int) {
+	return file_deplister_v1_deplister_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListScannersResponse) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+var File_deplister_v1_deplister_proto protoreflect.FileDescriptor
+
+const file_deplister_v1_deplister_proto_rawDesc = "" +
+	"\n" +
+	"\x1cdeplister/v1/deplister.proto\x12\fdeplister.v1\"}\n" +
+	"\x06Target\x12\x14\n" +
+	"\x04path\x18\x01 \x01(\tH\x00R\x04path\x12\x14\n" +
+	"\x04repo\x18\x02 \x01(\tH\x00R\x04repo\x12\x1a\n" +
+	"\aarchive\x18\x03 \x01(\fH\x00R\aarchive\x12!\n" +
+	"\farchive_name\x18\x04 \x01(\tR\varchiveNameB\b\n" +
+	"\x06source\"\x8c\x02\n" +
+	"\vScanOptions\x12\x1f\n" +
+	"\vinclude_dev\x18\x01 \x01(\bR\n" +
+	"includeDev\x12+\n" +
+	"\x11follow_workspaces\x18\x02 \x01(\bR\x10followWorkspaces\x12\x18\n" +
+	"\aoffline\x18\x03 \x01(\bR\aoffline\x12\x1b\n" +
+	"\tmax_depth\x18\x04 \x01(\x05R\bmaxDepth\x12=\n" +
+	"\x06enrich\x18\x05 \x03(\v2%.deplister.v1.ScanOptions.EnrichEntryR\x06enrich\x1a9\n" +
+	"\vEnrichEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\bR\x05value:\x028\x01\"p\n" +
+	"\vScanRequest\x12,\n" +
+	"\x06target\x18\x01 \x01(\v2\x14.deplister.v1.TargetR\x06target\x123\n" +
+	"\aoptions\x18\x02 \x01(\v2\x19.deplister.v1.ScanOptionsR\aoptions\",\n" +
+	"\aProject\x12!\n" +
+	"\fproject_type\x18\x01 \x01(\tR\vprojectType\":\n" +
+	"\x0eDependencyPath\x12\x12\n" +
+	"\x04path\x18\x01 \x03(\tR\x04path\x12\x14\n" +
+	"\x05depth\x18\x02 \x01(\x05R\x05depth\"\xe6\x03\n" +
+	"\n" +
+	"Dependency\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x120\n" +
+	"\x14is_direct_dependency\x18\x04 \x01(\bR\x12isDirectDependency\x12\x16\n" +
+	"\x06parent\x18\x05 \x01(\tR\x06parent\x12\x18\n" +
+	"\aparents\x18\x06 \x03(\tR\aparents\x122\n" +
+	"\x05paths\x18\a \x03(\v2\x1c.deplister.v1.DependencyPathR\x05paths\x12H\n" +
+	"\n" +
+	"properties\x18\b \x03(\v2(.deplister.v1.Dependency.PropertiesEntryR\n" +
+	"properties\x12\x14\n" +
+	"\x05depth\x18\t \x01(\x05R\x05depth\x12E\n" +
+	"\x0fvulnerabilities\x18\n" +
+	" \x03(\v2\x1b.deplister.v1.VulnerabilityR\x0fvulnerabilities\x12\x18\n" +
+	"\alicense\x18\v \x01(\tR\alicense\x1a=\n" +
+	"\x0fPropertiesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xaa\x01\n" +
+	"\rVulnerability\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aaliases\x18\x02 \x03(\tR\aaliases\x12\x18\n" +
+	"\asummary\x18\x03 \x01(\tR\asummary\x12\x1a\n" +
+	"\bseverity\x18\x04 \x01(\tR\bseverity\x12\x12\n" +
+	"\x04cvss\x18\x05 \x01(\tR\x04cvss\x12%\n" +
+	"\x0efixed_versions\x18\x06 \x03(\tR\rfixedVersions\"\x87\x01\n" +
+	"\fScanResponse\x121\n" +
+	"\aproject\x18\x01 \x01(\v2\x15.deplister.v1.ProjectH\x00R\aproject\x12:\n" +
+	"\n" +
+	"dependency\x18\x02 \x01(\v2\x18.deplister.v1.DependencyH\x00R\n" +
+	"dependencyB\b\n" +
+	"\x06result\"D\n" +
+	"\x14DetectProjectRequest\x12,\n" +
+	"\x06target\x18\x01 \x01(\v2\x14.deplister.v1.TargetR\x06target\"V\n" +
+	"\x15DetectProjectResponse\x12\x1a\n" +
+	"\bdetected\x18\x01 \x01(\bR\bdetected\x12!\n" +
+	"\fproject_type\x18\x02 \x01(\tR\vprojectType\"\x15\n" +
+	"\x13ListScannersRequest\",\n" +
+	"\x14ListScannersResponse\x12\x14\n" +
+	"\x05types\x18\x01 \x03(\tR\x05types2\xfd\x01\n" +
+	"\tDeplister\x12?\n" +
+	"\x04Scan\x12\x19.deplister.v1.ScanRequest\x1a\x1a.deplister.v1.ScanResponse0\x01\x12X\n" +
+	"\rDetectProject\x12\".deplister.v1.DetectProjectRequest\x1a#.deplister.v1.DetectProjectResponse\x12U\n" +
+	"\fListScanners\x12!.deplister.v1.ListScannersRequest\x1a\".deplister.v1.ListScannersResponseB9Z7github.com/santoshdahal12/deplister/pkg/api/deplisterv1b\x06proto3"
+
+var (
+	file_deplister_v1_deplister_proto_rawDescOnce sync.Once
+	file_deplister_v1_deplister_proto_rawDescData []byte
+)
+
+func file_deplister_v1_deplister_proto_rawDescGZIP() []byte {
+	file_deplister_v1_deplister_proto_rawDescOnce.Do(func() {
+		file_deplister_v1_deplister_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_deplister_v1_deplister_proto_rawDesc), len(file_deplister_v1_deplister_proto_rawDesc)))
+	})
+	return file_deplister_v1_deplister_proto_rawDescData
+}
+
+var file_deplister_v1_deplister_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_deplister_v1_deplister_proto_goTypes = []any{
+	(*Target)(nil),                // 0: deplister.v1.Target
+	(*ScanOptions)(nil),           // 1: deplister.v1.ScanOptions
+	(*ScanRequest)(nil),           // 2: deplister.v1.ScanRequest
+	(*Project)(nil),               // 3: deplister.v1.Project
+	(*DependencyPath)(nil),        // 4: deplister.v1.DependencyPath
+	(*Dependency)(nil),            // 5: deplister.v1.Dependency
+	(*Vulnerability)(nil),         // 6: deplister.v1.Vulnerability
+	(*ScanResponse)(nil),          // 7: deplister.v1.ScanResponse
+	(*DetectProjectRequest)(nil),  // 8: deplister.v1.DetectProjectRequest
+	(*DetectProjectResponse)(nil), // 9: deplister.v1.DetectProjectResponse
+	(*ListScannersRequest)(nil),   // 10: deplister.v1.ListScannersRequest
+	(*ListScannersResponse)(nil),  // 11: deplister.v1.ListScannersResponse
+	nil,                           // 12: deplister.v1.ScanOptions.EnrichEntry
+	nil,                           // 13: deplister.v1.Dependency.PropertiesEntry
+}
+var file_deplister_v1_deplister_proto_depIdxs = []int32{
+	12, // 0: deplister.v1.ScanOptions.enrich:type_name -> deplister.v1.ScanOptions.EnrichEntry
+	0,  // 1: deplister.v1.ScanRequest.target:type_name -> deplister.v1.Target
+	1,  // 2: deplister.v1.ScanRequest.options:type_name -> deplister.v1.ScanOptions
+	4,  // 3: deplister.v1.Dependency.paths:type_name -> deplister.v1.DependencyPath
+	13, // 4: deplister.v1.Dependency.properties:type_name -> deplister.v1.Dependency.PropertiesEntry
+	6,  // 5: deplister.v1.Dependency.vulnerabilities:type_name -> deplister.v1.Vulnerability
+	3,  // 6: deplister.v1.ScanResponse.project:type_name -> deplister.v1.Project
+	5,  // 7: deplister.v1.ScanResponse.dependency:type_name -> deplister.v1.Dependency
+	0,  // 8: deplister.v1.DetectProjectRequest.target:type_name -> deplister.v1.Target
+	2,  // 9: deplister.v1.Deplister.Scan:input_type -> deplister.v1.ScanRequest
+	8,  // 10: deplister.v1.Deplister.DetectProject:input_type -> deplister.v1.DetectProjectRequest
+	10, // 11: deplister.v1.Deplister.ListScanners:input_type -> deplister.v1.ListScannersRequest
+	7,  // 12: deplister.v1.Deplister.Scan:output_type -> deplister.v1.ScanResponse
+	9,  // 13: deplister.v1.Deplister.DetectProject:output_type -> deplister.v1.DetectProjectResponse
+	11, // 14: deplister.v1.Deplister.ListScanners:output_type -> deplister.v1.ListScannersResponse
+	12, // [12:15] is the sub-list for method output_type
+	9,  // [9:12] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_deplister_v1_deplister_proto_init() }
+func file_deplister_v1_deplister_proto_init() {
+	if File_deplister_v1_deplister_proto != nil {
+		return
+	}
+	file_deplister_v1_deplister_proto_msgTypes[0].OneofWrappers = []any{
+		(*Target_Path)(nil),
+		(*Target_Repo)(nil),
+		(*Target_Archive)(nil),
+	}
+	file_deplister_v1_deplister_proto_msgTypes[7].OneofWrappers = []any{
+		(*ScanResponse_Project)(nil),
+		(*ScanResponse_Dependency)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_deplister_v1_deplister_proto_rawDesc), len(file_deplister_v1_deplister_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_deplister_v1_deplister_proto_goTypes,
+		DependencyIndexes: file_deplister_v1_deplister_proto_depIdxs,
+		MessageInfos:      file_deplister_v1_deplister_proto_msgTypes,
+	}.Build()
+	File_deplister_v1_deplister_proto = out.File
+	file_deplister_v1_deplister_proto_goTypes = nil
+	file_deplister_v1_deplister_proto_depIdxs = nil
+}
@@ -0,0 +1,211 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: deplister/v1/deplister.proto
+
+package deplisterv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Deplister_Scan_FullMethodName          = "/deplister.v1.Deplister/Scan"
+	Deplister_DetectProject_FullMethodName = "/deplister.v1.Deplister/DetectProject"
+	Deplister_ListScanners_FullMethodName  = "/deplister.v1.Deplister/ListScanners"
+)
+
+// DeplisterClient is the client API for Deplister service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Deplister scans projects for their dependencies
+type DeplisterClient interface {
+	// Scan streams the project information followed by one message per dependency
+	Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ScanResponse], error)
+	// DetectProject reports which scanner supports the target
+	DetectProject(ctx context.Context, in *DetectProjectRequest, opts ...grpc.CallOption) (*DetectProjectResponse, error)
+	// ListScanners returns the enabled scanner types in detection order
+	ListScanners(ctx context.Context, in *ListScannersRequest, opts ...grpc.CallOption) (*ListScannersResponse, error)
+}
+
+type deplisterClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDeplisterClient(cc grpc.ClientConnInterface) DeplisterClient {
+	return &deplisterClient{cc}
+}
+
+func (c *deplisterClient) Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ScanResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Deplister_ServiceDesc.Streams[0], Deplister_Scan_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ScanRequest, ScanResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Deplister_ScanClient = grpc.ServerStreamingClient[ScanResponse]
+
+func (c *deplisterClient) DetectProject(ctx context.Context, in *DetectProjectRequest, opts ...grpc.CallOption) (*DetectProjectResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DetectProjectResponse)
+	err := c.cc.Invoke(ctx, Deplister_DetectProject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deplisterClient) ListScanners(ctx context.Context, in *ListScannersRequest, opts ...grpc.CallOption) (*ListScannersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListScannersResponse)
+	err := c.cc.Invoke(ctx, Deplister_ListScanners_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DeplisterServer is the server API for Deplister service.
+// All implementations must embed UnimplementedDeplisterServer
+// for forward compatibility.
+//
+// Deplister scans projects for their dependencies
+type DeplisterServer interface {
+	// Scan streams the project information followed by one message per dependency
+	Scan(*ScanRequest, grpc.ServerStreamingServer[ScanResponse]) error
+	// DetectProject reports which scanner supports the target
+	DetectProject(context.Context, *DetectProjectRequest) (*DetectProjectResponse, error)
+	// ListScanners returns the enabled scanner types in detection order
+	ListScanners(context.Context, *ListScannersRequest) (*ListScannersResponse, error)
+	mustEmbedUnimplementedDeplisterServer()
+}
+
+// UnimplementedDeplisterServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDeplisterServer struct{}
+
+func (UnimplementedDeplisterServer) Scan(*ScanRequest, grpc.ServerStreamingServer[ScanResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Scan not implemented")
+}
+func (UnimplementedDeplisterServer) DetectProject(context.Context, *DetectProjectRequest) (*DetectProjectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DetectProject not implemented")
+}
+func (UnimplementedDeplisterServer) ListScanners(context.Context, *ListScannersRequest) (*ListScannersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListScanners not implemented")
+}
+func (UnimplementedDeplisterServer) mustEmbedUnimplementedDeplisterServer() {}
+func (UnimplementedDeplisterServer) testEmbeddedByValue()                   {}
+
+// UnsafeDeplisterServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DeplisterServer will
+// result in compilation errors.
+type UnsafeDeplisterServer interface {
+	mustEmbedUnimplementedDeplisterServer()
+}
+
+func RegisterDeplisterServer(s grpc.ServiceRegistrar, srv DeplisterServer) {
+	// If the following call pancis, it indicates UnimplementedDeplisterServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Deplister_ServiceDesc, srv)
+}
+
+func _Deplister_Scan_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ScanRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DeplisterServer).Scan(m, &grpc.GenericServerStream[ScanRequest, ScanResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Deplister_ScanServer = grpc.ServerStreamingServer[ScanResponse]
+
+func _Deplister_DetectProject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DetectProjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeplisterServer).DetectProject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Deplister_DetectProject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeplisterServer).DetectProject(ctx, req.(*DetectProjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Deplister_ListScanners_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListScannersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeplisterServer).ListScanners(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Deplister_ListScanners_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeplisterServer).ListScanners(ctx, req.(*ListScannersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Deplister_ServiceDesc is the grpc.ServiceDesc for Deplister service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Deplister_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "deplister.v1.Deplister",
+	HandlerType: (*DeplisterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DetectProject",
+			Handler:    _Deplister_DetectProject_Handler,
+		},
+		{
+			MethodName: "ListScanners",
+			Handler:    _Deplister_ListScanners_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Scan",
+			Handler:       _Deplister_Scan_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "deplister/v1/deplister.proto",
+}
@@ -0,0 +1,4 @@
+// Package deplisterv1 contains the generated gRPC API of the deplister scan service.
+package deplisterv1
+
+//go:generate protoc -I ../../../api --go_out=../../.. --go_opt=module=github.com/santoshdahal12/deplister --go-grpc_out=../../.. --go-grpc_opt=module=github.com/santoshdahal12/deplister deplister/v1/deplister.proto
@@ -0,0 +1,197 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// Common errors
+var (
+	ErrUnsupportedArchive = errors.New("unsupported archive format")
+	ErrTooLarge           = errors.New("archive too large")
+)
+
+// Limits on the decompressed contents of archives, which are read into
+// memory. Compressed archives can be much smaller than what they hold, so the
+// size of the archive itself says little.
+var (
+	MaxEntrySize int64 = 64 << 20  // Largest file an archive may hold
+	MaxTotalSize int64 = 512 << 20 // Largest size of all files together
+)
+
+// IsArchive reports whether the path names an archive deplister can scan
+func IsArchive(name string) bool {
+	return format(name) != ""
+}
+
+func format(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip"
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	}
+	return ""
+}
+
+// Open reads an archive into memory and returns its contents as a file
+// system. Archives wrapping everything in a single top level directory, like
+// npm package tarballs, are rooted at that directory.
+func Open(name string) (fs.FS, error) {
+	if !IsArchive(name) {
+		return nil, ErrUnsupportedArchive
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return OpenReader(file, info.Size(), name)
+}
+
+// OpenReader is like Open but reads the archive from r. The name is only
+// used to determine the archive format.
+func OpenReader(r io.ReaderAt, size int64, name string) (fs.FS, error) {
+	var fsys fs.FS
+	var err error
+
+	switch format(name) {
+	case "zip":
+		fsys, err = readZip(r, size)
+	case "tar.gz":
+		fsys, err = readTar(io.NewSectionReader(r, 0, size), true)
+	case "tar":
+		fsys, err = readTar(io.NewSectionReader(r, 0, size), false)
+	default:
+		return nil, ErrUnsupportedArchive
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return singleRoot(fsys), nil
+}
+
+func readZip(r io.ReaderAt, size int64) (fs.FS, error) {
+	reader, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	fsys := newMemFS()
+	var total int64
+	for _, file := range reader.File {
+		entryName, ok := cleanName(file.Name)
+		if !ok || file.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := readEntry(rc, entryName, &total)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		fsys.add(entryName, data, file.Modified)
+	}
+
+	return fsys, nil
+}
+
+func readTar(r io.Reader, gzipped bool) (fs.FS, error) {
+	if gzipped {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	fsys := newMemFS()
+	var total int64
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entryName, ok := cleanName(header.Name)
+		if !ok || header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := readEntry(tr, entryName, &total)
+		if err != nil {
+			return nil, err
+		}
+		fsys.add(entryName, data, header.ModTime)
+	}
+
+	return fsys, nil
+}
+
+// readEntry reads the contents of an archive entry, adding its size to total.
+// It fails once the entry exceeds MaxEntrySize or the total MaxTotalSize,
+// whatever size the entry claims to have.
+func readEntry(r io.Reader, name string, total *int64) ([]byte, error) {
+	limit := min(MaxEntrySize, MaxTotalSize-*total)
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		if limit < MaxEntrySize {
+			return nil, fmt.Errorf("%w: contents exceed %d bytes", ErrTooLarge, MaxTotalSize)
+		}
+		return nil, fmt.Errorf("%w: %s exceeds %d bytes", ErrTooLarge, name, MaxEntrySize)
+	}
+	*total += int64(len(data))
+	return data, nil
+}
+
+// cleanName normalizes an archive entry name, rejecting entries that would
+// escape the archive root
+func cleanName(name string) (string, bool) {
+	name = path.Clean(strings.TrimLeft(strings.ReplaceAll(name, "\\", "/"), "/"))
+	if name == "." || !fs.ValidPath(name) {
+		return "", false
+	}
+	return name, true
+}
+
+// singleRoot descends into the only top level directory of fsys, if any
+func singleRoot(fsys fs.FS) fs.FS {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return fsys
+	}
+	sub, err := fs.Sub(fsys, entries[0].Name())
+	if err != nil {
+		return fsys
+	}
+	return sub
+}
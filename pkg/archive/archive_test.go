@@ -0,0 +1,139 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTarGz(t *testing.T, name string, files map[string]string) {
+	t.Helper()
+	file, err := os.Create(name)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	for entry, content := range files {
+		err := tw.WriteHeader(&tar.Header{Name: entry, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		assert.NoError(t, err)
+		_, err = tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+}
+
+func writeZip(t *testing.T, name string, files map[string]string) {
+	t.Helper()
+	file, err := os.Create(name)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	for entry, content := range files {
+		w, err := zw.Create(entry)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+}
+
+func TestIsArchive(t *testing.T) {
+	assert.True(t, IsArchive("dist.tar.gz"))
+	assert.True(t, IsArchive("react-18.2.0.tgz"))
+	assert.True(t, IsArchive("release.ZIP"))
+	assert.True(t, IsArchive("image.tar"))
+	assert.False(t, IsArchive("project"))
+	assert.False(t, IsArchive("package.json"))
+}
+
+func TestOpen_TarGzSingleRoot(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "react-18.2.0.tgz")
+	writeTarGz(t, name, map[string]string{
+		"package/package.json":     `{"name": "react"}`,
+		"package/lib/index.js":     "module.exports = {}",
+		"../escape/package.json":   "{}",
+		"./package/cjs/react.js":   "",
+		"/package/umd/react.js":    "",
+		"package/../../outside.js": "",
+	})
+
+	fsys, err := Open(name)
+	assert.NoError(t, err)
+
+	content, err := fs.ReadFile(fsys, "package.json")
+	assert.NoError(t, err, "archive should be rooted at package/")
+	assert.Equal(t, `{"name": "react"}`, string(content))
+
+	_, err = fs.Stat(fsys, "lib/index.js")
+	assert.NoError(t, err)
+	_, err = fs.Stat(fsys, "cjs/react.js")
+	assert.NoError(t, err)
+
+	var files []string
+	err = fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, p)
+		}
+		return err
+	})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"package.json", "lib/index.js", "cjs/react.js", "umd/react.js"}, files)
+}
+
+func TestOpen_Zip(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "release.zip")
+	writeZip(t, name, map[string]string{
+		"go.mod":      "module example.com/app",
+		"cmd/main.go": "package main",
+	})
+
+	fsys, err := Open(name)
+	assert.NoError(t, err)
+
+	content, err := fs.ReadFile(fsys, "go.mod")
+	assert.NoError(t, err)
+	assert.Equal(t, "module example.com/app", string(content))
+}
+
+func TestOpen_Unsupported(t *testing.T) {
+	_, err := Open("project.rar")
+	assert.ErrorIs(t, err, ErrUnsupportedArchive)
+}
+
+func TestOpen_TooLarge(t *testing.T) {
+	defer func(entry, total int64) { MaxEntrySize, MaxTotalSize = entry, total }(MaxEntrySize, MaxTotalSize)
+	MaxEntrySize, MaxTotalSize = 1024, 1536
+
+	dir := t.TempDir()
+	for name, write := range map[string]func(*testing.T, string, map[string]string){
+		"bomb.tar.gz": writeTarGz,
+		"bomb.zip":    writeZip,
+	} {
+		name = filepath.Join(dir, name)
+		write(t, name, map[string]string{"package.json": "{}", "huge.bin": strings.Repeat("0", 1025)})
+		_, err := Open(name)
+		assert.ErrorIs(t, err, ErrTooLarge, name)
+		assert.ErrorContains(t, err, "huge.bin exceeds 1024 bytes", name)
+
+		name = strings.Replace(name, "bomb", "many", 1)
+		write(t, name, map[string]string{"a.bin": strings.Repeat("0", 1000), "b.bin": strings.Repeat("0", 1000)})
+		_, err = Open(name)
+		assert.ErrorIs(t, err, ErrTooLarge, name)
+		assert.ErrorContains(t, err, "contents exceed 1536 bytes", name)
+
+		name = strings.Replace(name, "many", "fits", 1)
+		write(t, name, map[string]string{"a.bin": strings.Repeat("0", 1024), "b.bin": strings.Repeat("0", 512)})
+		_, err = Open(name)
+		assert.NoError(t, err, name)
+	}
+}
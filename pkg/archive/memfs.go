@@ -0,0 +1,122 @@
+package archive
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+// memFS is a read-only in-memory file system holding the contents of an archive
+type memFS struct {
+	files map[string]*memEntry
+}
+
+// memEntry is a single file or directory of a memFS
+type memEntry struct {
+	name     string
+	data     []byte
+	mode     fs.FileMode
+	modTime  time.Time
+	children map[string]*memEntry
+}
+
+func newMemFS() *memFS {
+	return &memFS{
+		files: map[string]*memEntry{
+			".": {name: ".", mode: fs.ModeDir | 0555, children: make(map[string]*memEntry)},
+		},
+	}
+}
+
+// add stores a regular file, creating its parent directories as needed
+func (m *memFS) add(name string, data []byte, modTime time.Time) {
+	entry := &memEntry{name: path.Base(name), data: data, mode: 0444, modTime: modTime}
+	m.files[name] = entry
+	m.dir(path.Dir(name)).children[entry.name] = entry
+}
+
+func (m *memFS) dir(name string) *memEntry {
+	if entry, ok := m.files[name]; ok {
+		return entry
+	}
+	entry := &memEntry{name: path.Base(name), mode: fs.ModeDir | 0555, children: make(map[string]*memEntry)}
+	m.files[name] = entry
+	m.dir(path.Dir(name)).children[entry.name] = entry
+	return entry
+}
+
+func (m *memFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	entry, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if entry.mode.IsDir() {
+		return &memDir{entry: entry}, nil
+	}
+	return &memFile{entry: entry, reader: bytes.NewReader(entry.data)}, nil
+}
+
+func (e *memEntry) Name() string               { return e.name }
+func (e *memEntry) Size() int64                { return int64(len(e.data)) }
+func (e *memEntry) Mode() fs.FileMode          { return e.mode }
+func (e *memEntry) ModTime() time.Time         { return e.modTime }
+func (e *memEntry) IsDir() bool                { return e.mode.IsDir() }
+func (e *memEntry) Sys() any                   { return nil }
+func (e *memEntry) Type() fs.FileMode          { return e.mode.Type() }
+func (e *memEntry) Info() (fs.FileInfo, error) { return e, nil }
+
+// memFile is an open regular file
+type memFile struct {
+	entry  *memEntry
+	reader *bytes.Reader
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.entry, nil }
+func (f *memFile) Read(b []byte) (int, error) { return f.reader.Read(b) }
+func (f *memFile) Close() error               { return nil }
+
+// memDir is an open directory
+type memDir struct {
+	entry   *memEntry
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.entry, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.entry.name, Err: fs.ErrInvalid}
+}
+
+func (d *memDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	if d.entries == nil {
+		d.entries = make([]fs.DirEntry, 0, len(d.entry.children))
+		for _, child := range d.entry.children {
+			d.entries = append(d.entries, child)
+		}
+		sort.Slice(d.entries, func(i, j int) bool {
+			return d.entries[i].Name() < d.entries[j].Name()
+		})
+	}
+
+	remaining := d.entries[d.offset:]
+	if count <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(remaining) {
+		count = len(remaining)
+	}
+	d.offset += count
+	return remaining[:count], nil
+}
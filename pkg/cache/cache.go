@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// format is the version of the cached result layout, changed whenever
+// scanners.ScanResult changes incompatibly
+const format = 1
+
+// DefaultDir returns the scan cache location in the user's cache directory
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "deplister", "scans")
+}
+
+// Key returns the cache key of a scan by the given scanner: a hash of the
+// scanner type, the deplister build, the options affecting the scanner and
+// the contents of the files in fsys the scanner reads. Missing files are
+// part of the key too.
+func Key(fsys fs.FS, scannerType string, files []string, opts scanners.ScanOptions) (string, error) {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "deplister scan cache %d\n%s\n%s\n", format, build(), scannerType)
+
+	// Enrichments run on every scan, cached or not
+	opts.Enrich = nil
+	options, err := json.Marshal(opts)
+	if err != nil {
+		return "", err
+	}
+	// The environment of the go tool is left out of the JSON options
+	fmt.Fprintf(hash, "%s\n%q\n", options, opts.Env)
+
+	for _, name := range files {
+		content, err := fs.ReadFile(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(hash, "%s missing\n", name)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256(content)
+		fmt.Fprintf(hash, "%s %x\n", name, sum)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// build identifies the running deplister binary, so that upgrades do not
+// reuse results of older scanners
+func build() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	id := info.Main.Version
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" || setting.Key == "vcs.modified" {
+			id += " " + setting.Value
+		}
+	}
+	return id
+}
+
+// Load returns the result cached in dir under key. It reports false when
+// there is none or it cannot be read.
+func Load(dir, key string) (*scanners.ScanResult, bool) {
+	content, err := os.ReadFile(filepath.Join(dir, key+".json"))
+	if err != nil {
+		return nil, false
+	}
+	var result scanners.ScanResult
+	if err := json.Unmarshal(content, &result); err != nil {
+		return nil, false
+	}
+	return &result, true
+}
+
+// Store caches a result in dir under key. Concurrent scans storing the same
+// key do not corrupt the entry, as it is replaced atomically.
+func Store(dir, key string, result *scanners.ScanResult) error {
+	content, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(content)
+	if err = errors.Join(err, file.Close()); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	if err := os.Rename(file.Name(), filepath.Join(dir, key+".json")); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func TestKey(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json":      {Data: []byte(`{"name": "app"}`)},
+		"package-lock.json": {Data: []byte(`{"packages": {}}`)},
+	}
+	files := []string{"package.json", "package-lock.json", "node_modules/.package-lock.json"}
+	opts := scanners.DefaultScanOptions()
+
+	key, err := Key(fsys, "npm", files, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, key, 64)
+
+	// Enrichments do not affect the scanner
+	opts.Enrich = map[string]bool{"vulns": true}
+	same, _ := Key(fsys, "npm", files, opts)
+	assert.Equal(t, key, same)
+
+	opts.IncludeDev = false
+	other, _ := Key(fsys, "npm", files, opts)
+	assert.NotEqual(t, key, other)
+
+	opts = scanners.DefaultScanOptions()
+	opts.Env = []string{"GOFLAGS=-tags=integration"}
+	other, _ = Key(fsys, "npm", files, opts)
+	assert.NotEqual(t, key, other)
+
+	other, _ = Key(fsys, "go", files, scanners.DefaultScanOptions())
+	assert.NotEqual(t, key, other)
+
+	fsys["node_modules/.package-lock.json"] = &fstest.MapFile{Data: []byte(`{}`)}
+	other, _ = Key(fsys, "npm", files, scanners.DefaultScanOptions())
+	assert.NotEqual(t, key, other)
+}
+
+func TestStoreLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "scans")
+
+	_, ok := Load(dir, "abc")
+	assert.False(t, ok)
+
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{{Name: "lodash", Version: "4.17.21", Type: "npm", Depth: 1}},
+		Graph: &scanners.DependencyGraph{
+			Nodes: map[string]*scanners.Dependency{"lodash@4.17.21": {Name: "lodash", Version: "4.17.21", Type: "npm", Depth: 1}},
+			Edges: map[string][]string{"": {"lodash@4.17.21"}},
+		},
+	}
+	if !assert.NoError(t, Store(dir, "abc", result)) {
+		return
+	}
+	loaded, ok := Load(dir, "abc")
+	assert.True(t, ok)
+	assert.Equal(t, result, loaded)
+
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 1)
+
+	os.WriteFile(filepath.Join(dir, "corrupt.json"), []byte("{"), 0o644)
+	_, ok = Load(dir, "corrupt")
+	assert.False(t, ok)
+}
@@ -0,0 +1,105 @@
+package cpe
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// targetSoftware maps dependency types to the target_sw of their CPEs in
+// the NVD dictionary
+var targetSoftware = map[string]string{
+	"npm":   "node.js",
+	"go":    "go",
+	"cargo": "rust",
+	"pypi":  "python",
+	"gem":   "ruby",
+}
+
+// codeHosts are the Go module hosts whose paths name an owner and a repository
+var codeHosts = []string{"github.com", "gitlab.com", "bitbucket.org"}
+
+// majorSuffix matches the major version suffix of Go module paths, such as
+// /v2 or .v3 of gopkg.in
+var majorSuffix = regexp.MustCompile(`[./]v[0-9]+$`)
+
+// New builds a best-effort CPE 2.3 formatted string for a package, such as
+// cpe:2.3:a:lodash:lodash:4.17.21:*:*:*:*:node.js:*:*. Packages have no
+// official vendor, so it is guessed from the name: the scope of npm
+// packages, the owner of Go modules hosted on GitHub and the like, or else
+// the product itself. Names in the NVD dictionary may still differ.
+func New(depType, name, version string) string {
+	vendor, product := vendorProduct(depType, name)
+	if depType == "go" {
+		version = strings.TrimSuffix(strings.TrimPrefix(version, "v"), "+incompatible")
+	}
+	target, ok := targetSoftware[strings.ToLower(depType)]
+	if !ok {
+		target = "*"
+	}
+
+	fields := []string{"cpe", "2.3", "a", escape(vendor), escape(product), "*", "*", "*", "*", "*", target, "*", "*"}
+	if version != "" {
+		fields[5] = escape(version)
+	}
+	return strings.Join(fields, ":")
+}
+
+// For returns the CPE of a scanned dependency
+func For(dep scanners.Dependency) string {
+	return New(dep.Type, dep.Name, dep.Version)
+}
+
+// vendorProduct guesses the vendor and product of a package from its name
+func vendorProduct(depType, name string) (string, string) {
+	switch depType {
+	case "npm":
+		if scope, pkg, ok := strings.Cut(strings.TrimPrefix(name, "@"), "/"); ok && strings.HasPrefix(name, "@") {
+			return scope, pkg
+		}
+		return name, name
+	case "go":
+		path := majorSuffix.ReplaceAllString(name, "")
+		segments := strings.Split(path, "/")
+		switch {
+		case len(segments) >= 3 && slices.Contains(codeHosts, segments[0]):
+			return segments[1], segments[2]
+		case len(segments) == 3 && segments[0] == "golang.org" && segments[1] == "x":
+			return "golang", segments[2]
+		case len(segments) >= 2:
+			return segments[len(segments)-2], segments[len(segments)-1]
+		}
+		return path, path
+	}
+	// Maven style group:artifact and path style names
+	segments := strings.FieldsFunc(name, func(r rune) bool { return r == '/' || r == ':' })
+	if len(segments) >= 2 {
+		group := segments[len(segments)-2]
+		if dot := strings.LastIndex(group, "."); dot >= 0 {
+			group = group[dot+1:]
+		}
+		return group, segments[len(segments)-1]
+	}
+	return name, name
+}
+
+// escape lowercases a CPE component and quotes the characters the
+// formatted string binding reserves, spaces becoming underscores
+func escape(s string) string {
+	s = strings.ReplaceAll(strings.ToLower(s), " ", "_")
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+		case r > 0x7f:
+			// Not allowed in CPEs at all
+			continue
+		default:
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package cpe
+
+import (
+	"testing"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		depType string
+		pkg     string
+		version string
+		want    string
+	}{
+		{name: "npm", depType: "npm", pkg: "lodash", version: "4.17.21", want: "cpe:2.3:a:lodash:lodash:4.17.21:*:*:*:*:node.js:*:*"},
+		{name: "npm_scoped", depType: "npm", pkg: "@babel/core", version: "7.24.0", want: "cpe:2.3:a:babel:core:7.24.0:*:*:*:*:node.js:*:*"},
+		{name: "go_github", depType: "go", pkg: "github.com/gin-gonic/gin", version: "v1.9.1", want: "cpe:2.3:a:gin-gonic:gin:1.9.1:*:*:*:*:go:*:*"},
+		{name: "go_major", depType: "go", pkg: "github.com/go-chi/chi/v5", version: "v5.0.12", want: "cpe:2.3:a:go-chi:chi:5.0.12:*:*:*:*:go:*:*"},
+		{name: "go_incompatible", depType: "go", pkg: "github.com/docker/docker", version: "v20.10.0+incompatible", want: "cpe:2.3:a:docker:docker:20.10.0:*:*:*:*:go:*:*"},
+		{name: "go_x", depType: "go", pkg: "golang.org/x/net", version: "v0.23.0", want: "cpe:2.3:a:golang:net:0.23.0:*:*:*:*:go:*:*"},
+		{name: "gopkg", depType: "go", pkg: "gopkg.in/yaml.v3", version: "v3.0.1", want: "cpe:2.3:a:gopkg.in:yaml:3.0.1:*:*:*:*:go:*:*"},
+		{name: "maven", depType: "maven", pkg: "org.apache.logging.log4j:log4j-core", version: "2.14.1", want: "cpe:2.3:a:log4j:log4j-core:2.14.1:*:*:*:*:*:*:*"},
+		{name: "no_version", depType: "npm", pkg: "lodash", want: "cpe:2.3:a:lodash:lodash:*:*:*:*:*:node.js:*:*"},
+		{name: "escaped", depType: "generic", pkg: "Foo Bar!", version: "1.0+build", want: `cpe:2.3:a:foo_bar\!:foo_bar\!:1.0\+build:*:*:*:*:*:*:*`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, New(tt.depType, tt.pkg, tt.version))
+		})
+	}
+}
+
+func TestFor(t *testing.T) {
+	dep := scanners.Dependency{Name: "github.com/pkg/errors", Version: "v0.9.1", Type: "go"}
+	assert.Equal(t, "cpe:2.3:a:pkg:errors:0.9.1:*:*:*:*:go:*:*", For(dep))
+}
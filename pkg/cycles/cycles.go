@@ -0,0 +1,38 @@
+package cycles
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Enrichment is the name of the cycle detection in ScanOptions.Enrich
+const Enrichment = "cycles"
+
+// Rule is the rule name of dependency cycle findings
+const Rule = "cycle"
+
+// Enrich adds a finding for every cycle of the dependency graph. Cycles are
+// warnings, as npm packages routinely depend on each other, unless fail is
+// set and the graph is a Go module graph, where they make upgrades of the
+// modules involved depend on each other.
+func Enrich(result *scanners.ScanResult, fail bool) {
+	if result.Graph == nil {
+		return
+	}
+	for _, cycle := range result.Graph.Cycles() {
+		finding := scanners.Finding{
+			Rule:     Rule,
+			Severity: scanners.SeverityWarning,
+			Message:  fmt.Sprintf("dependency cycle of %d packages: %s", len(cycle)-1, strings.Join(cycle, " -> ")),
+		}
+		if node, ok := result.Graph.Nodes[cycle[0]]; ok {
+			finding.Dependency, finding.Version = node.Name, node.Version
+			if fail && node.Type == "go" {
+				finding.Severity = scanners.SeverityError
+			}
+		}
+		result.Findings = append(result.Findings, finding)
+	}
+}
@@ -0,0 +1,45 @@
+package cycles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func testResult(depType string) *scanners.ScanResult {
+	a := &scanners.Dependency{Name: "a", Version: "1.0.0", Type: depType}
+	b := &scanners.Dependency{Name: "b", Version: "2.0.0", Type: depType}
+	return &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{*a, *b},
+		Graph: &scanners.DependencyGraph{
+			Nodes: map[string]*scanners.Dependency{"a@1.0.0": a, "b@2.0.0": b},
+			Edges: map[string][]string{"": {"a@1.0.0"}, "a@1.0.0": {"b@2.0.0"}, "b@2.0.0": {"a@1.0.0"}},
+		},
+	}
+}
+
+func TestEnrich(t *testing.T) {
+	result := testResult("npm")
+	Enrich(result, true)
+	assert.Equal(t, []scanners.Finding{{
+		Rule:       Rule,
+		Severity:   scanners.SeverityWarning,
+		Dependency: "a",
+		Version:    "1.0.0",
+		Message:    "dependency cycle of 2 packages: a@1.0.0 -> b@2.0.0 -> a@1.0.0",
+	}}, result.Findings)
+
+	result = testResult("go")
+	Enrich(result, false)
+	assert.Equal(t, scanners.SeverityWarning, result.Findings[0].Severity)
+
+	result = testResult("go")
+	Enrich(result, true)
+	assert.Equal(t, scanners.SeverityError, result.Findings[0].Severity)
+
+	result = &scanners.ScanResult{}
+	Enrich(result, true)
+	assert.Empty(t, result.Findings)
+}
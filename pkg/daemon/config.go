@@ -0,0 +1,124 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/schedule"
+)
+
+// DefaultSchedule is the schedule of targets when the configuration sets
+// none
+const DefaultSchedule = "@daily"
+
+// ErrInvalidConfig is returned for configurations that do not load
+var ErrInvalidConfig = errors.New("invalid daemon configuration")
+
+// Config lists the targets the daemon rescans
+type Config struct {
+	Schedule string   `yaml:"schedule"` // Cron spec of targets without their own, DefaultSchedule when empty
+	Targets  []Target `yaml:"targets"`
+}
+
+// Target is a path or repository the daemon rescans
+type Target struct {
+	Name     string         `yaml:"name"`     // Name in the HTTP API, derived from the path or repository when empty
+	Path     string         `yaml:"path"`     // Local project directory or archive
+	Repo     string         `yaml:"repo"`     // Git repository as url[@ref]
+	Schedule string         `yaml:"schedule"` // Cron spec overriding the configuration's
+	Options  map[string]any `yaml:"options"`  // Scan options, with the JSON names the server accepts
+}
+
+// LoadConfig reads a YAML configuration file
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return Config{}, fmt.Errorf("%w: %s: %w", ErrInvalidConfig, path, err)
+	}
+	return config, nil
+}
+
+// name returns the name of a target: its configured name, the repository
+// without its scheme, or the base name of the path
+func name(t Target) string {
+	switch {
+	case t.Name != "":
+		return t.Name
+	case t.Repo != "":
+		_, rest, found := strings.Cut(t.Repo, "://")
+		if !found {
+			rest = t.Repo
+		}
+		return strings.TrimSuffix(rest, ".git")
+	}
+	if abs, err := filepath.Abs(t.Path); err == nil {
+		return filepath.Base(abs)
+	}
+	return filepath.Base(t.Path)
+}
+
+// target is a checked target of the configuration
+type target struct {
+	name     string
+	spec     string
+	schedule schedule.Schedule
+	target   engine.Target
+	opts     scanners.ScanOptions
+}
+
+// check validates the configuration and resolves its targets
+func (c Config) check() ([]*target, error) {
+	if len(c.Targets) == 0 {
+		return nil, fmt.Errorf("%w: no targets", ErrInvalidConfig)
+	}
+	defaultSpec := c.Schedule
+	if defaultSpec == "" {
+		defaultSpec = DefaultSchedule
+	}
+
+	seen := make(map[string]bool)
+	var targets []*target
+	for i, t := range c.Targets {
+		if (t.Path == "") == (t.Repo == "") {
+			return nil, fmt.Errorf("%w: target %d needs exactly one of path or repo", ErrInvalidConfig, i+1)
+		}
+		resolved := &target{name: name(t), spec: t.Schedule, target: engine.Target{Path: t.Path, Repo: t.Repo}}
+		if seen[resolved.name] {
+			return nil, fmt.Errorf("%w: several targets are named %q, set distinct names", ErrInvalidConfig, resolved.name)
+		}
+		seen[resolved.name] = true
+
+		if resolved.spec == "" {
+			resolved.spec = defaultSpec
+		}
+		var err error
+		if resolved.schedule, err = schedule.Parse(resolved.spec); err != nil {
+			return nil, fmt.Errorf("%w: target %s: %w", ErrInvalidConfig, resolved.name, err)
+		}
+
+		resolved.opts = scanners.DefaultScanOptions()
+		if len(t.Options) > 0 {
+			raw, err := json.Marshal(t.Options)
+			if err == nil {
+				err = json.Unmarshal(raw, &resolved.opts)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: options of target %s: %w", ErrInvalidConfig, resolved.name, err)
+			}
+		}
+		targets = append(targets, resolved)
+	}
+	return targets, nil
+}
@@ -0,0 +1,61 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+schedule: "0 */6 * * *"
+targets:
+  - path: /srv/web
+  - repo: https://github.com/org/api.git@main
+    schedule: "@hourly"
+    options:
+      includeDev: false
+      enrich: {vulns: true}
+  - name: tools
+    path: ./tools
+`), 0644))
+
+	config, err := LoadConfig(path)
+	assert.NoError(t, err)
+	targets, err := config.check()
+	assert.NoError(t, err)
+	assert.Len(t, targets, 3)
+
+	assert.Equal(t, "web", targets[0].name)
+	assert.Equal(t, "0 */6 * * *", targets[0].spec)
+	assert.True(t, targets[0].opts.IncludeDev)
+
+	assert.Equal(t, "github.com/org/api.git@main", targets[1].name)
+	assert.Equal(t, "@hourly", targets[1].spec)
+	assert.Equal(t, "https://github.com/org/api.git@main", targets[1].target.Repo)
+	assert.False(t, targets[1].opts.IncludeDev)
+	assert.True(t, targets[1].opts.Enrich["vulns"])
+
+	assert.Equal(t, "tools", targets[2].name)
+}
+
+func TestConfig_Invalid(t *testing.T) {
+	tests := map[string]Config{
+		"no targets":     {},
+		"path and repo":  {Targets: []Target{{Path: ".", Repo: "https://github.com/org/repo"}}},
+		"neither":        {Targets: []Target{{Name: "x"}}},
+		"duplicate name": {Targets: []Target{{Path: "/a/app"}, {Path: "/b/app"}}},
+		"bad schedule":   {Schedule: "every day", Targets: []Target{{Path: "."}}},
+		"bad options":    {Targets: []Target{{Path: ".", Options: map[string]any{"includeDev": "yes"}}}},
+	}
+	for name, config := range tests {
+		_, err := config.check()
+		assert.ErrorIs(t, err, ErrInvalidConfig, name)
+	}
+
+	_, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,244 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/history"
+	"github.com/santoshdahal12/deplister/pkg/output"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/webhook"
+)
+
+// Options wires the daemon to the rest of the service
+type Options struct {
+	// Scan scans a target, engine.Scan when nil. Callers set the HTTP client
+	// and offline mode of the service here, and record its metrics.
+	Scan func(ctx context.Context, target engine.Target, opts scanners.ScanOptions) (*engine.Report, error)
+
+	Store       *history.Store    // Records every scan when set
+	Webhook     *webhook.Notifier // Receives every scan when set
+	Concurrency int               // Scans running at once, one per CPU when not positive
+
+	// Error, when set, is called with the scans, saves and deliveries that
+	// fail
+	Error func(name string, err error)
+}
+
+// Status is the state of a target in the HTTP API
+type Status struct {
+	Name         string     `json:"name"`
+	Project      string     `json:"project"` // Key of the target's scans in the history store
+	Schedule     string     `json:"schedule"`
+	ProjectType  string     `json:"projectType,omitempty"`
+	Dependencies int        `json:"dependencies"`
+	LastScan     *time.Time `json:"lastScan,omitempty"`
+	NextScan     *time.Time `json:"nextScan,omitempty"` // nil when the schedule never runs again
+	Error        string     `json:"error,omitempty"`    // Why the last scan failed
+}
+
+// TargetResult is a target with the result of its latest successful scan
+type TargetResult struct {
+	Status
+	Result *output.OutputFormat `json:"result,omitempty"`
+}
+
+// Daemon rescans the targets of a configuration on their schedules and
+// keeps their latest results
+type Daemon struct {
+	targets []*target
+	opts    Options
+	now     func() time.Time
+
+	mu       sync.Mutex
+	statuses map[string]*Status
+	reports  map[string]*engine.Report
+}
+
+// New checks the configuration and creates a daemon for it
+func New(config Config, opts Options) (*Daemon, error) {
+	targets, err := config.check()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Scan == nil {
+		opts.Scan = engine.Scan
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = runtime.NumCPU()
+	}
+
+	d := &Daemon{
+		targets:  targets,
+		opts:     opts,
+		now:      time.Now,
+		statuses: make(map[string]*Status),
+		reports:  make(map[string]*engine.Report),
+	}
+	for _, t := range targets {
+		d.statuses[t.name] = &Status{Name: t.name, Project: project(t.target), Schedule: t.spec}
+	}
+	return d, nil
+}
+
+// project returns the key scans of the target are stored under, the same
+// as scan -store uses: the repository, or the absolute path
+func project(target engine.Target) string {
+	if target.Repo != "" {
+		return target.Repo
+	}
+	if abs, err := filepath.Abs(target.Path); err == nil {
+		return abs
+	}
+	return target.Path
+}
+
+// Run scans every target at once, then again on its schedule, until the
+// context is done
+func (d *Daemon) Run(ctx context.Context) {
+	limit := make(chan struct{}, d.opts.Concurrency)
+	var wg sync.WaitGroup
+	for _, t := range d.targets {
+		wg.Add(1)
+		go func(t *target) {
+			defer wg.Done()
+			for {
+				select {
+				case limit <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				d.scan(ctx, t)
+				<-limit
+
+				next := t.schedule.Next(d.now())
+				if next.IsZero() {
+					return
+				}
+				d.mu.Lock()
+				d.statuses[t.name].NextScan = &next
+				d.mu.Unlock()
+				timer := time.NewTimer(time.Until(next))
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				}
+			}
+		}(t)
+	}
+	wg.Wait()
+}
+
+// scan scans a target, records the outcome and hands a successful scan to
+// the store and, in the background, the webhook
+func (d *Daemon) scan(ctx context.Context, t *target) {
+	report, err := d.opts.Scan(ctx, t.target, t.opts)
+	if ctx.Err() != nil {
+		return
+	}
+
+	d.mu.Lock()
+	status := d.statuses[t.name]
+	now := d.now().UTC()
+	status.LastScan, status.NextScan = &now, nil
+	if err != nil {
+		status.Error = err.Error()
+	} else {
+		status.Error = ""
+		status.ProjectType = report.ProjectType
+		status.Dependencies = len(report.Result.Dependencies)
+		d.reports[t.name] = report
+	}
+	key := status.Project
+	d.mu.Unlock()
+
+	if err != nil {
+		d.fail(t.name, err)
+		return
+	}
+	if d.opts.Store != nil {
+		if _, err := d.opts.Store.Save(ctx, key, report); err != nil {
+			d.fail(t.name, err)
+		}
+	}
+	if d.opts.Webhook != nil {
+		// Deliveries do not hold up the next scans
+		go d.deliver(context.WithoutCancel(ctx), t.name, key, report)
+	}
+}
+
+func (d *Daemon) deliver(ctx context.Context, name, key string, report *engine.Report) {
+	ctx, cancel := context.WithTimeout(ctx, webhook.Timeout)
+	defer cancel()
+	if err := d.opts.Webhook.Deliver(ctx, key, report); err != nil {
+		d.fail(name, err)
+	}
+}
+
+func (d *Daemon) fail(name string, err error) {
+	if d.opts.Error != nil {
+		d.opts.Error(name, err)
+	}
+}
+
+// Statuses returns the state of every target, by name
+func (d *Daemon) Statuses() []Status {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	statuses := make([]Status, 0, len(d.statuses))
+	for _, status := range d.statuses {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Latest returns the state of a target and the report of its latest
+// successful scan, nil before the first one
+func (d *Daemon) Latest(name string) (Status, *engine.Report, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	status, ok := d.statuses[name]
+	if !ok {
+		return Status{}, nil, false
+	}
+	return *status, d.reports[name], true
+}
+
+// Handler serves the targets over HTTP: GET /targets lists their states and
+// GET /targets/{name} returns one with the result of its latest scan
+func (d *Daemon) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /targets", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, d.Statuses())
+	})
+	mux.HandleFunc("GET /targets/{name...}", func(w http.ResponseWriter, r *http.Request) {
+		status, report, ok := d.Latest(r.PathValue("name"))
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown target " + r.PathValue("name")})
+			return
+		}
+		result := TargetResult{Status: status}
+		if report != nil {
+			doc := output.NewOutputFormat(report.Result, report.ProjectType)
+			result.Result = &doc
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,136 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/history"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaemon_Run(t *testing.T) {
+	store, err := history.Open(filepath.Join(t.TempDir(), "history.db"))
+	assert.NoError(t, err)
+	defer store.Close()
+
+	var mu sync.Mutex
+	var failures []string
+	config := Config{
+		// Never runs again, so that Run returns after the first scans
+		Schedule: "0 0 31 2 *",
+		Targets:  []Target{{Name: "web", Path: "/srv/web"}, {Name: "broken", Repo: "https://example.com/broken"}},
+	}
+	d, err := New(config, Options{
+		Store: store,
+		Scan: func(ctx context.Context, target engine.Target, opts scanners.ScanOptions) (*engine.Report, error) {
+			if target.Repo != "" {
+				return nil, errors.New("clone failed")
+			}
+			return &engine.Report{ProjectType: "npm", Result: &scanners.ScanResult{Dependencies: []scanners.Dependency{{Name: "lodash", Version: "4.17.21", IsDirectDep: true}}}}, nil
+		},
+		Error: func(name string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failures = append(failures, name+": "+err.Error())
+		},
+	})
+	assert.NoError(t, err)
+	d.Run(context.Background())
+
+	assert.Equal(t, []string{"broken: clone failed"}, failures)
+	statuses := d.Statuses()
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, "broken", statuses[0].Name)
+	assert.Equal(t, "clone failed", statuses[0].Error)
+	assert.NotNil(t, statuses[0].LastScan)
+	assert.Equal(t, "web", statuses[1].Name)
+	assert.Equal(t, "npm", statuses[1].ProjectType)
+	assert.Equal(t, 1, statuses[1].Dependencies)
+	assert.Nil(t, statuses[1].NextScan)
+
+	scans, err := store.History(context.Background(), "/srv/web", 0)
+	assert.NoError(t, err)
+	assert.Len(t, scans, 1)
+	assert.Equal(t, 1, scans[0].DirectCount)
+}
+
+func TestDaemon_Handler(t *testing.T) {
+	config := Config{Targets: []Target{{Name: "org/web", Path: "/srv/web"}, {Name: "api", Path: "/srv/api"}}}
+	d, err := New(config, Options{Scan: func(ctx context.Context, target engine.Target, opts scanners.ScanOptions) (*engine.Report, error) {
+		return &engine.Report{ProjectType: "go", Result: &scanners.ScanResult{Dependencies: []scanners.Dependency{{Name: "golang.org/x/mod", Version: "v0.23.0"}}}}, nil
+	}})
+	assert.NoError(t, err)
+	d.scan(context.Background(), d.targets[0])
+	handler := d.Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/targets", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var statuses []Status
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &statuses))
+	assert.Equal(t, []string{"api", "org/web"}, []string{statuses[0].Name, statuses[1].Name})
+	assert.Equal(t, "@daily", statuses[0].Schedule)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/targets/org/web", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var result TargetResult
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, "org/web", result.Name)
+	assert.Equal(t, "go", result.Result.ProjectType)
+	assert.Len(t, result.Result.Dependencies, 1)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/targets/api", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), `"result"`)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/targets/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestDaemon_Webhook(t *testing.T) {
+	release := make(chan struct{})
+	delivered := make(chan webhook.Payload, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		var payload webhook.Payload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		delivered <- payload
+	}))
+	defer receiver.Close()
+	notifier, err := webhook.New(webhook.Config{URL: receiver.URL})
+	assert.NoError(t, err)
+
+	config := Config{Targets: []Target{{Name: "web", Path: "/srv/web"}}}
+	d, err := New(config, Options{
+		Webhook: notifier,
+		Scan: func(ctx context.Context, target engine.Target, opts scanners.ScanOptions) (*engine.Report, error) {
+			return &engine.Report{ProjectType: "npm", Result: &scanners.ScanResult{}}, nil
+		},
+	})
+	assert.NoError(t, err)
+
+	// The scan completes while the receiver is still busy
+	d.scan(context.Background(), d.targets[0])
+	close(release)
+
+	select {
+	case payload := <-delivered:
+		assert.Equal(t, "/srv/web", payload.Target)
+	case <-time.After(10 * time.Second):
+		t.Fatal("scan was not delivered to the webhook")
+	}
+}
@@ -0,0 +1,56 @@
+package deprecation
+
+import (
+	"context"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Enrichment is the name of the deprecation enrichment in ScanOptions.Enrich
+const Enrichment = "deprecated"
+
+// Enrich flags npm package versions deprecated in the registry and Go modules
+// deprecated by their authors with a "deprecated" property, and retracted Go
+// module versions with a "retracted" property. The properties hold the
+// author's message, or the property name when none was given.
+func Enrich(ctx context.Context, source registry.Source, result *scanners.ScanResult) error {
+	packages, err := registry.Lookup(ctx, source, result.Dependencies)
+	if err != nil {
+		return err
+	}
+
+	for i := range result.Dependencies {
+		dep := &result.Dependencies[i]
+		pkg, ok := packages[registry.Key{Type: dep.Type, Name: dep.Name}]
+		if !ok {
+			continue
+		}
+
+		props := make(map[string]string)
+		if message, ok := pkg.Deprecated[dep.Version]; ok {
+			props["deprecated"] = message
+		}
+		if pkg.ModuleDeprecated != "" {
+			props["deprecated"] = pkg.ModuleDeprecated
+		}
+		if retraction, ok := pkg.Retraction(dep.Version); ok {
+			props["retracted"] = retraction.Rationale
+		}
+		if len(props) == 0 {
+			continue
+		}
+
+		if dep.Properties == nil {
+			dep.Properties = make(map[string]string)
+		}
+		for key, message := range props {
+			if message == "" {
+				message = key
+			}
+			dep.Properties[key] = message
+		}
+	}
+
+	return nil
+}
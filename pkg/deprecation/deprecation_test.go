@@ -0,0 +1,50 @@
+package deprecation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+type fakeSource map[string]*registry.Package
+
+func (s fakeSource) Package(ctx context.Context, depType, name string) (*registry.Package, error) {
+	if pkg, ok := s[name]; ok {
+		return pkg, nil
+	}
+	return nil, registry.ErrNotFound
+}
+
+func TestEnrich(t *testing.T) {
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "request", Version: "2.88.2", Type: "npm"},
+			{Name: "left-pad", Version: "1.3.0", Type: "npm", Properties: map[string]string{"dependencyType": "production"}},
+			{Name: "example.com/retracted", Version: "v1.1.0", Type: "go"},
+			{Name: "example.com/old", Version: "v1.0.0", Type: "go"},
+			{Name: "example.com/fine", Version: "v1.2.0", Type: "go"},
+		},
+	}
+
+	source := fakeSource{
+		"request":  {Deprecated: map[string]string{"2.88.2": "request has been deprecated"}},
+		"left-pad": {Deprecated: map[string]string{"1.3.0": ""}},
+		"example.com/retracted": {Retracted: []registry.Retraction{
+			{Low: "v1.1.0", High: "v1.1.0", Rationale: "Published accidentally"},
+		}},
+		"example.com/old":  {ModuleDeprecated: "use example.com/new", Retracted: []registry.Retraction{{Low: "v1.0.0", High: "v1.0.0"}}},
+		"example.com/fine": {Retracted: []registry.Retraction{{Low: "v1.1.0", High: "v1.1.0"}}},
+	}
+
+	assert.NoError(t, Enrich(context.Background(), source, result))
+
+	assert.Equal(t, map[string]string{"deprecated": "request has been deprecated"}, result.Dependencies[0].Properties)
+	assert.Equal(t, map[string]string{"dependencyType": "production", "deprecated": "deprecated"}, result.Dependencies[1].Properties)
+	assert.Equal(t, map[string]string{"retracted": "Published accidentally"}, result.Dependencies[2].Properties)
+	assert.Equal(t, map[string]string{"deprecated": "use example.com/new", "retracted": "retracted"}, result.Dependencies[3].Properties)
+	assert.Nil(t, result.Dependencies[4].Properties)
+}
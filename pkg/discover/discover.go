@@ -0,0 +1,168 @@
+// Package discover finds the directories of a tree that may hold projects,
+// without descending into installed packages, build output or other
+// directories .gitignore files and the user exclude
+package discover
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Skipped are directory names discovery never descends into: installed and
+// vendored packages. Hidden directories such as .git are skipped as well.
+var Skipped = []string{"node_modules", "vendor", "bower_components"}
+
+// DefaultMaxDepth is the number of directory levels below the root
+// discovery descends by default
+const DefaultMaxDepth = 32
+
+// Options controls which directories discovery descends into
+type Options struct {
+	Exclude        []string // Gitignore patterns of directories to skip, relative to the root, such as "build" or "data/raw"
+	NoGitignore    bool     // Descend into directories .gitignore files exclude
+	MaxDepth       int      // Directory levels below the root to descend, 0 for DefaultMaxDepth
+	FollowSymlinks bool     // Descend into symlinked directories within the root, each real directory once
+	FollowOutside  bool     // With FollowSymlinks, also follow links to directories outside the root
+}
+
+// Dirs returns the slash separated paths, relative to root, of the
+// directories discovery descends into, "." first and parents before their
+// children. A directory reached through a symlink keeps the path of the link.
+// Directories that cannot be read are left out with their contents.
+func Dirs(root string, opts Options) ([]string, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	realRoot, err := filepath.EvalSymlinks(absRoot)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.ReadDir(realRoot); err != nil {
+		return nil, err
+	}
+
+	w := &walker{
+		root:    realRoot,
+		opts:    opts,
+		exclude: &Ignore{Dir: "."},
+		ignores: ignores{},
+		visited: map[string]bool{realRoot: true},
+	}
+	if w.opts.MaxDepth <= 0 {
+		w.opts.MaxDepth = DefaultMaxDepth
+	}
+	for _, pattern := range opts.Exclude {
+		w.exclude.Add(pattern)
+	}
+	if err := w.walk(".", realRoot, 0); err != nil {
+		return nil, err
+	}
+	return w.dirs, nil
+}
+
+// walker holds the state of a walk of Dirs. Directories are tracked by their
+// real path, so that symlink cycles and links to directories already walked
+// are not followed again.
+type walker struct {
+	root    string // Real path of the root
+	opts    Options
+	exclude *Ignore
+	ignores ignores
+	visited map[string]bool
+	dirs    []string
+}
+
+// walk records the directory name, whose real path is dir, and walks its
+// subdirectories
+func (w *walker) walk(name, dir string, depth int) error {
+	if !w.opts.NoGitignore {
+		if ignore, err := loadIgnore(name, dir); err == nil {
+			w.ignores[name] = ignore
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	w.dirs = append(w.dirs, name)
+	if depth >= w.opts.MaxDepth {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		child := path.Join(name, entry.Name())
+		real, ok := w.resolve(filepath.Join(dir, entry.Name()), entry)
+		if !ok || w.visited[real] || skip(child, w.exclude, w.ignores) {
+			continue
+		}
+		w.visited[real] = true
+		if err := w.walk(child, real, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve returns the real path of a directory entry and whether it is a
+// directory to descend into. Symlinks are followed only as the options allow.
+func (w *walker) resolve(file string, entry fs.DirEntry) (string, bool) {
+	if entry.Type()&fs.ModeSymlink == 0 {
+		return file, entry.IsDir()
+	}
+	if !w.opts.FollowSymlinks {
+		return "", false
+	}
+	target, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return "", false
+	}
+	if !w.opts.FollowOutside && !within(w.root, target) {
+		return "", false
+	}
+	info, err := os.Stat(target)
+	if err != nil || !info.IsDir() {
+		return "", false
+	}
+	return target, true
+}
+
+// within reports whether path is dir or below it
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// skip reports whether discovery leaves out a directory
+func skip(name string, exclude *Ignore, found ignores) bool {
+	base := path.Base(name)
+	for _, skipped := range Skipped {
+		if base == skipped {
+			return true
+		}
+	}
+	if strings.HasPrefix(base, ".") {
+		return true
+	}
+	if excluded, _ := exclude.Match(name, true); excluded {
+		return true
+	}
+	return found.match(name, true)
+}
+
+// loadIgnore reads the .gitignore file of the directory name, whose real
+// path is dir
+func loadIgnore(name, dir string) (*Ignore, error) {
+	file, err := os.Open(filepath.Join(dir, IgnoreFile))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseIgnore(name, file)
+}
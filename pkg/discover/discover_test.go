@@ -0,0 +1,121 @@
+package discover
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTree creates the files of a map from slash separated path to content
+// under a new directory
+func writeTree(t *testing.T, files map[string]string) string {
+	root := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(root, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	}
+	return root
+}
+
+func TestDirs(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		".gitignore":                       "# build output\n/dist\n*.tmp/\nlogs/\n",
+		"package.json":                     "{}",
+		"dist/package.json":                "{}",
+		"node_modules/lodash/package.json": "{}",
+		".git/config":                      "",
+		"services/api/go.mod":              "module api",
+		"services/api/vendor/modules.txt":  "",
+		"services/api/dist/main.go":        "package main",
+		"services/cache.tmp/go.mod":        "module tmp",
+		"services/web/.gitignore":          "generated/\n!logs/\n",
+		"services/web/package.json":        "{}",
+		"services/web/generated/index.js":  "",
+		"services/web/logs/app.log":        "",
+		"data/raw/huge.csv":                "",
+		"data/clean/small.csv":             "",
+	})
+
+	dirs, err := Dirs(root, Options{Exclude: []string{"data/raw"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{".", "data", "data/clean", "services", "services/api", "services/api/dist", "services/web", "services/web/logs"}, dirs)
+
+	dirs, err = Dirs(root, Options{NoGitignore: true})
+	assert.NoError(t, err)
+	assert.Contains(t, dirs, "dist")
+	assert.Contains(t, dirs, "data/raw")
+	assert.Contains(t, dirs, "services/web/generated")
+	assert.NotContains(t, dirs, "node_modules")
+	assert.NotContains(t, dirs, ".git")
+}
+
+func TestDirs_Symlinks(t *testing.T) {
+	outside := writeTree(t, map[string]string{"secret/package.json": "{}"})
+	root := writeTree(t, map[string]string{
+		"app/package.json":     "{}",
+		"app/lib/a/b/c/go.mod": "module deep",
+	})
+	assert.NoError(t, os.Symlink(root, filepath.Join(root, "app", "loop")))
+	assert.NoError(t, os.Symlink(filepath.Join(root, "app", "lib"), filepath.Join(root, "shared")))
+	assert.NoError(t, os.Symlink(outside, filepath.Join(root, "outside")))
+
+	dirs, err := Dirs(root, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{".", "app", "app/lib", "app/lib/a", "app/lib/a/b", "app/lib/a/b/c"}, dirs)
+
+	// The cycle back to the root and the link to a directory already walked
+	// are not followed, nor is the link outside the root
+	dirs, err = Dirs(root, Options{FollowSymlinks: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{".", "app", "app/lib", "app/lib/a", "app/lib/a/b", "app/lib/a/b/c"}, dirs)
+
+	dirs, err = Dirs(root, Options{FollowSymlinks: true, FollowOutside: true, MaxDepth: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{".", "app", "app/lib", "outside", "outside/secret"}, dirs)
+
+	_, err = Dirs(filepath.Join(root, "missing"), Options{})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestIgnore_Match(t *testing.T) {
+	ignore, err := ParseIgnore("web", strings.NewReader(strings.Join([]string{
+		"build",
+		"/out/",
+		"docs/**/*.md",
+		"**/cache",
+		"tmp-[0-9]",
+		"*.log",
+		"!keep.log",
+		`\#notes`,
+	}, "\n")))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		isDir   bool
+		ignored bool
+	}{
+		{"web/build", true, true},
+		{"web/src/build", true, true},
+		{"web/out", true, true},
+		{"web/out", false, false},
+		{"web/src/out", true, false},
+		{"web/docs/a/b/intro.md", false, true},
+		{"web/docs/intro.md", false, true},
+		{"web/a/cache", true, true},
+		{"web/tmp-1", true, true},
+		{"web/tmp-x", true, false},
+		{"web/debug.log", false, true},
+		{"web/keep.log", false, false},
+		{"web/#notes", false, true},
+		{"web/src", true, false},
+	}
+	for _, tt := range tests {
+		ignored, _ := ignore.Match(tt.name, tt.isDir)
+		assert.Equal(t, tt.ignored, ignored, tt.name)
+	}
+}
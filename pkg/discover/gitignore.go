@@ -0,0 +1,158 @@
+package discover
+
+import (
+	"bufio"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFile is the name of the files whose patterns discovery honors
+const IgnoreFile = ".gitignore"
+
+// pattern is a line of a .gitignore file
+type pattern struct {
+	re      *regexp.Regexp
+	negate  bool // "!" pattern, including again what earlier patterns excluded
+	dirOnly bool // Pattern ending with "/", matching directories only
+}
+
+// Ignore holds the patterns of a .gitignore file. They apply to the
+// directory of the file and below, with the last matching pattern winning.
+type Ignore struct {
+	Dir      string // Slash separated directory of the file relative to the walk root, "." for the root
+	patterns []pattern
+}
+
+// ParseIgnore reads the patterns of a .gitignore file in dir. Lines that are
+// not valid patterns are skipped, as git does.
+func ParseIgnore(dir string, r io.Reader) (*Ignore, error) {
+	ignore := &Ignore{Dir: dir}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		ignore.Add(scanner.Text())
+	}
+	return ignore, scanner.Err()
+}
+
+// Add appends a gitignore pattern. Patterns containing a slash other than a
+// trailing one are relative to the directory of the file; others match a
+// name at any depth below it.
+func (ig *Ignore) Add(line string) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+
+	var p pattern
+	if strings.HasPrefix(line, "!") {
+		p.negate, line = true, line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly, line = true, strings.TrimRight(line, "/")
+	}
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return
+	}
+
+	expr := "^" + globToRegexp(line) + "$"
+	if !anchored {
+		expr = "^(?:.*/)?" + globToRegexp(line) + "$"
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return
+	}
+	p.re = re
+	ig.patterns = append(ig.patterns, p)
+}
+
+// Match reports whether a path is ignored, and whether any pattern decided
+// it. name is slash separated and relative to the walk root, and must be
+// within Dir.
+func (ig *Ignore) Match(name string, isDir bool) (ignored, matched bool) {
+	rel := name
+	if ig.Dir != "." {
+		rel = strings.TrimPrefix(name, ig.Dir+"/")
+	}
+	for _, p := range ig.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.re.MatchString(rel) {
+			ignored, matched = !p.negate, true
+		}
+	}
+	return ignored, matched
+}
+
+// ignores are the .gitignore files found along a walk, keyed by directory
+type ignores map[string]*Ignore
+
+// match reports whether a path is ignored by the files of its parent
+// directories, files nearer to the path overriding those above them
+func (m ignores) match(name string, isDir bool) bool {
+	var dirs []string
+	for dir := path.Dir(name); ; dir = path.Dir(dir) {
+		dirs = append(dirs, dir)
+		if dir == "." {
+			break
+		}
+	}
+
+	ignored := false
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if ig, ok := m[dirs[i]]; ok {
+			if result, matched := ig.Match(name, isDir); matched {
+				ignored = result
+			}
+		}
+	}
+	return ignored
+}
+
+// globToRegexp translates a gitignore glob to a regular expression: "*" and
+// "?" stop at slashes, "**/" matches any number of directories and "/**"
+// everything inside a directory
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case glob[i:] == "/**":
+			b.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,128 @@
+package duplicates
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/version"
+)
+
+// Enrichment is the name of the duplicate version check in ScanOptions.Enrich
+const Enrichment = "duplicates"
+
+// Rule is the rule name of duplicate version findings
+const Rule = "duplicate"
+
+// Project names the project root among the parents of a version
+const Project = "the project"
+
+// Duplicate is a package present at more than one version
+type Duplicate struct {
+	Name     string
+	Type     string
+	Versions []Version // Ordered from lowest to highest
+}
+
+// Version is one of the versions of a duplicated package
+type Version struct {
+	Version string
+	Parents []string // Node keys of the packages requiring the version, or Project
+}
+
+// Find returns the packages of a scan result present at several versions,
+// sorted by name
+func Find(result *scanners.ScanResult) []Duplicate {
+	type pkg struct{ depType, name string }
+	versions := make(map[pkg][]string)
+	for _, dep := range result.Dependencies {
+		key := pkg{dep.Type, dep.Name}
+		if !slices.Contains(versions[key], dep.Version) {
+			versions[key] = append(versions[key], dep.Version)
+		}
+	}
+
+	var duplicates []Duplicate
+	for key, found := range versions {
+		if len(found) < 2 {
+			continue
+		}
+		slices.SortFunc(found, version.Compare)
+
+		duplicate := Duplicate{Name: key.name, Type: key.depType}
+		for _, v := range found {
+			duplicate.Versions = append(duplicate.Versions, Version{Version: v, Parents: parents(result, key.name, v)})
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Name != duplicates[j].Name {
+			return duplicates[i].Name < duplicates[j].Name
+		}
+		return duplicates[i].Type < duplicates[j].Type
+	})
+	return duplicates
+}
+
+// parents returns the packages requiring a version, preferring the graph
+// edges and falling back to the parent names recorded on the dependency
+func parents(result *scanners.ScanResult, name, v string) []string {
+	var parents []string
+	if result.Graph != nil && len(result.Graph.Edges) > 0 {
+		key := scanners.NodeKey(name, v)
+		for parent, children := range result.Graph.Edges {
+			if !slices.Contains(children, key) {
+				continue
+			}
+			if _, ok := result.Graph.Nodes[parent]; parent == result.Graph.Root || (!ok && len(result.Graph.Nodes) > 0) {
+				// The project root is no node
+				parent = Project
+			}
+			if !slices.Contains(parents, parent) {
+				parents = append(parents, parent)
+			}
+		}
+		slices.Sort(parents)
+		return parents
+	}
+
+	for _, dep := range result.Dependencies {
+		if dep.Name != name || dep.Version != v {
+			continue
+		}
+		if dep.IsDirectDep {
+			parents = append(parents, Project)
+		}
+		for _, parent := range dep.Parents {
+			if !slices.Contains(parents, parent) {
+				parents = append(parents, parent)
+			}
+		}
+	}
+	slices.Sort(parents)
+	return parents
+}
+
+// Enrich adds a warning finding for every package present at several
+// versions, naming the packages requiring each of them
+func Enrich(result *scanners.ScanResult) {
+	for _, duplicate := range Find(result) {
+		var versions []string
+		for _, v := range duplicate.Versions {
+			text := v.Version
+			if len(v.Parents) > 0 {
+				text += " (required by " + strings.Join(v.Parents, ", ") + ")"
+			}
+			versions = append(versions, text)
+		}
+		result.Findings = append(result.Findings, scanners.Finding{
+			Rule:       Rule,
+			Severity:   scanners.SeverityWarning,
+			Dependency: duplicate.Name,
+			Message:    fmt.Sprintf("%s is present at %d versions: %s", duplicate.Name, len(duplicate.Versions), strings.Join(versions, ", ")),
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package duplicates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func TestFind(t *testing.T) {
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "express", Version: "4.18.2", Type: "npm", IsDirectDep: true},
+			{Name: "debug", Version: "4.3.4", Type: "npm", IsDirectDep: true},
+			{Name: "debug", Version: "2.6.9", Type: "npm", Parents: []string{"express", "send"}},
+			{Name: "send", Version: "0.18.0", Type: "npm", Parents: []string{"express"}},
+		},
+		Graph: &scanners.DependencyGraph{
+			Edges: map[string][]string{
+				"":               {"express@4.18.2", "debug@4.3.4"},
+				"express@4.18.2": {"debug@2.6.9", "send@0.18.0"},
+				"send@0.18.0":    {"debug@2.6.9"},
+			},
+		},
+	}
+
+	assert.Equal(t, []Duplicate{{
+		Name: "debug",
+		Type: "npm",
+		Versions: []Version{
+			{Version: "2.6.9", Parents: []string{"express@4.18.2", "send@0.18.0"}},
+			{Version: "4.3.4", Parents: []string{Project}},
+		},
+	}}, Find(result))
+
+	Enrich(result)
+	assert.Equal(t, []scanners.Finding{{
+		Rule:       Rule,
+		Severity:   scanners.SeverityWarning,
+		Dependency: "debug",
+		Message:    "debug is present at 2 versions: 2.6.9 (required by express@4.18.2, send@0.18.0), 4.3.4 (required by the project)",
+	}}, result.Findings)
+}
+
+func TestFindWithoutGraph(t *testing.T) {
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "six", Version: "1.16.0", Type: "pip", IsDirectDep: true},
+			{Name: "six", Version: "1.15.0", Type: "pip", Parents: []string{"python-dateutil"}},
+			{Name: "six", Version: "1.16.0", Type: "npm"},
+		},
+	}
+
+	assert.Equal(t, []Duplicate{{
+		Name: "six",
+		Type: "pip",
+		Versions: []Version{
+			{Version: "1.15.0", Parents: []string{"python-dateutil"}},
+			{Version: "1.16.0", Parents: []string{Project}},
+		},
+	}}, Find(result))
+}
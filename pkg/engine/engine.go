@@ -0,0 +1,585 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/santoshdahal12/deplister/pkg/abandoned"
+	"github.com/santoshdahal12/deplister/pkg/age"
+	"github.com/santoshdahal12/deplister/pkg/archive"
+	"github.com/santoshdahal12/deplister/pkg/cache"
+	"github.com/santoshdahal12/deplister/pkg/cycles"
+	"github.com/santoshdahal12/deplister/pkg/deprecation"
+	"github.com/santoshdahal12/deplister/pkg/discover"
+	"github.com/santoshdahal12/deplister/pkg/duplicates"
+	pipeline "github.com/santoshdahal12/deplister/pkg/enrich"
+	"github.com/santoshdahal12/deplister/pkg/httpclient"
+	"github.com/santoshdahal12/deplister/pkg/integrity"
+	"github.com/santoshdahal12/deplister/pkg/lifecycle"
+	"github.com/santoshdahal12/deplister/pkg/maintainers"
+	"github.com/santoshdahal12/deplister/pkg/npmrc"
+	"github.com/santoshdahal12/deplister/pkg/outdated"
+	"github.com/santoshdahal12/deplister/pkg/peers"
+	"github.com/santoshdahal12/deplister/pkg/platform"
+	"github.com/santoshdahal12/deplister/pkg/provenance"
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/remote"
+	"github.com/santoshdahal12/deplister/pkg/risk"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/scorecard"
+	"github.com/santoshdahal12/deplister/pkg/size"
+	"github.com/santoshdahal12/deplister/pkg/skew"
+	"github.com/santoshdahal12/deplister/pkg/tracing"
+	"github.com/santoshdahal12/deplister/pkg/typosquat"
+	"github.com/santoshdahal12/deplister/pkg/unused"
+	"github.com/santoshdahal12/deplister/pkg/vulndb"
+	"github.com/santoshdahal12/deplister/pkg/vulns"
+)
+
+// Common errors
+var (
+	ErrNoProject     = errors.New("no supported project found")
+	ErrInvalidTarget = errors.New("invalid scan target")
+	ErrOffline       = errors.New("offline, but network access is required")
+)
+
+// Target describes what to scan. Exactly one of Path, Repo or FS must be set.
+type Target struct {
+	Path string // Local project directory or archive
+	Repo string // Git repository as url[@ref]
+	FS   fs.FS  // Project contents, scanned by scanners supporting fs.FS
+}
+
+// Report is the outcome of scanning a target
+type Report struct {
+	ProjectType string
+	Result      *scanners.ScanResult
+}
+
+// ProjectError is the error of a scan that failed after detecting the project
+// type, such as a scan of a malformed lockfile. It wraps the cause, so
+// errors.Is sees through it.
+type ProjectError struct {
+	ProjectType string
+	Err         error
+}
+
+func (e *ProjectError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ProjectError) Unwrap() error {
+	return e.Err
+}
+
+// project is a resolved target, either a directory or a file system
+type project struct {
+	dir  string
+	fsys fs.FS
+}
+
+// files returns the contents of the project
+func (p project) files() fs.FS {
+	if p.fsys != nil {
+		return p.fsys
+	}
+	return os.DirFS(p.dir)
+}
+
+// Scan resolves the target, detects its project type using the registered
+// scanners and scans its dependencies. Failures after the detection are
+// returned as a *ProjectError.
+func Scan(ctx context.Context, target Target, opts scanners.ScanOptions) (_ *Report, err error) {
+	ctx, span := tracing.Start(ctx, "deplister.Scan", targetAttributes(target)...)
+	defer func() { tracing.End(span, err) }()
+
+	proj, cleanup, err := resolve(ctx, target, opts.Offline)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	scanner, err := detect(ctx, proj)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("deplister.scanner", scanner.GetType()))
+	defer func() {
+		if err != nil {
+			err = &ProjectError{ProjectType: scanner.GetType(), Err: err}
+		}
+	}()
+
+	result, err := scanCached(ctx, scanner, proj, opts)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("deplister.dependencies", len(result.Dependencies)))
+
+	if opts.Enabled(unused.Enrichment) {
+		_, span := tracing.Start(ctx, "enrich "+unused.Enrichment)
+		err := unused.Enrich(proj.files(), scanner.GetType(), result)
+		tracing.End(span, err)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Enabled(skew.Enrichment) {
+		_, span := tracing.Start(ctx, "enrich "+skew.Enrichment)
+		err := skew.Enrich(proj.files(), scanner.GetType(), result)
+		tracing.End(span, err)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Enabled(peers.Enrichment) {
+		_, span := tracing.Start(ctx, "enrich "+peers.Enrichment)
+		err := peers.Enrich(proj.files(), scanner.GetType(), result)
+		tracing.End(span, err)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Registry lookups of npm packages follow the .npmrc files npm would use.
+	// Only local projects are trusted with the environment.
+	var config *npmrc.Config
+	if scanner.GetType() == "npm" {
+		if config, err = npmrc.Load(proj.files(), proj.dir != "" && target.Path != ""); err != nil {
+			return nil, err
+		}
+	}
+	if err := enrich(ctx, result, opts, config); err != nil {
+		return nil, err
+	}
+
+	// Risk scores combine the signals of the other enrichments
+	if opts.Enabled(risk.Enrichment) {
+		_, span := tracing.Start(ctx, "enrich "+risk.Enrichment)
+		risk.Enrich(result, opts.RiskWeights, opts.Enabled(vulns.Enrichment) || opts.Enabled(pipeline.OSVName))
+		span.End()
+	}
+
+	// Enrichments update the dependencies, of which graph nodes may be copies
+	result.SyncGraph()
+
+	// Enrichments such as peers read the paths, so they are only dropped now
+	if opts.Paths == scanners.PathsNone {
+		for i := range result.Dependencies {
+			result.Dependencies[i].Paths = nil
+		}
+		if result.Graph != nil {
+			for _, node := range result.Graph.Nodes {
+				node.Paths = nil
+			}
+		}
+	}
+
+	return &Report{ProjectType: scanner.GetType(), Result: result}, nil
+}
+
+// isCacheable reports whether the scanner's CacheFiles are all a scan with
+// opts depends on
+func isCacheable(scanner scanners.Scanner, opts scanners.ScanOptions) bool {
+	partly, ok := scanner.(scanners.PartlyCacheableScanner)
+	return !ok || partly.Cacheable(opts)
+}
+
+// scanCached scans the project, reusing the result cached in opts.CacheDir
+// while the files the scanner depends on are unchanged
+func scanCached(ctx context.Context, scanner scanners.Scanner, proj project, opts scanners.ScanOptions) (result *scanners.ScanResult, err error) {
+	ctx, span := tracing.Start(ctx, "scan "+scanner.GetType())
+	defer func() { tracing.End(span, err) }()
+
+	var key string
+	if cacheable, ok := scanner.(scanners.CacheableScanner); ok && opts.CacheDir != "" && isCacheable(scanner, opts) {
+		// A project that cannot be hashed is scanned without the cache
+		key, _ = cache.Key(proj.files(), scanner.GetType(), cacheable.CacheFiles(), opts)
+	}
+	if key != "" {
+		if result, ok := cache.Load(opts.CacheDir, key); ok {
+			span.SetAttributes(attribute.Bool("deplister.cached", true))
+			return result, nil
+		}
+	}
+
+	if proj.fsys != nil {
+		result, err = scanner.(scanners.FSScanner).ScanDependenciesFS(ctx, proj.fsys, opts)
+	} else {
+		result, err = scanner.ScanDependencies(ctx, proj.dir, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Partial results are scanned again, as their failures may be transient
+	if key != "" && len(result.Errors) == 0 {
+		// Failing to cache only costs the next scan time
+		cache.Store(opts.CacheDir, key, result)
+	}
+	return result, nil
+}
+
+// Outcome is the report or error of one target scanned by ScanAll
+type Outcome struct {
+	Target Target
+	Report *Report
+	Err    error
+}
+
+// ScanAll scans the targets with at most workers scans running at once, or
+// one per CPU when workers is not positive. A failing target does not stop
+// the others; outcomes are returned in the order of the targets.
+func ScanAll(ctx context.Context, targets []Target, opts scanners.ScanOptions, workers int) []Outcome {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	outcomes := make([]Outcome, len(targets))
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, workers)
+	for i, target := range targets {
+		outcomes[i].Target = target
+		wg.Add(1)
+		limit <- struct{}{}
+		go func() {
+			defer func() { <-limit; wg.Done() }()
+			outcomes[i].Report, outcomes[i].Err = scanIsolated(ctx, target, opts)
+		}()
+	}
+	wg.Wait()
+	return outcomes
+}
+
+// scanIsolated scans a target, turning a panicking scanner into an error so
+// that it only fails its own target
+func scanIsolated(ctx context.Context, target Target, opts scanners.ScanOptions) (report *Report, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("scanner panic: %v", r)
+		}
+	}()
+	return Scan(ctx, target, opts)
+}
+
+// enrich runs the enrichment steps requested in the options, looking up npm
+// packages in the registries of config when not nil
+func enrich(ctx context.Context, result *scanners.ScanResult, opts scanners.ScanOptions, config *npmrc.Config) error {
+	if opts.Enabled(vulns.Enrichment) {
+		source, closeSource, err := vulnSource(opts)
+		if err != nil {
+			return err
+		}
+		defer closeSource()
+
+		ctx, span := tracing.Start(ctx, "enrich "+vulns.Enrichment)
+		err = vulns.Enrich(ctx, source, result)
+		tracing.End(span, err)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.Enabled(typosquat.Enrichment) {
+		_, span := tracing.Start(ctx, "enrich "+typosquat.Enrichment)
+		typosquat.Enrich(typosquat.Default(), result)
+		span.End()
+	}
+
+	if opts.Enabled(duplicates.Enrichment) {
+		_, span := tracing.Start(ctx, "enrich "+duplicates.Enrichment)
+		duplicates.Enrich(result)
+		span.End()
+	}
+
+	if opts.Enabled(platform.Enrichment) {
+		target, err := platform.ParseTarget(opts.NodeVersion, opts.Platform)
+		if err != nil {
+			return err
+		}
+		_, span := tracing.Start(ctx, "enrich "+platform.Enrichment)
+		platform.Enrich(result, target)
+		span.End()
+	}
+
+	if opts.Enabled(cycles.Enrichment) {
+		_, span := tracing.Start(ctx, "enrich "+cycles.Enrichment)
+		cycles.Enrich(result, opts.FailOnCycles)
+		span.End()
+	}
+
+	if opts.Enabled(scorecard.Enrichment) {
+		if opts.Offline {
+			return fmt.Errorf("%w: %s", ErrOffline, scorecard.Enrichment)
+		}
+		ctx, span := tracing.Start(ctx, "enrich "+scorecard.Enrichment)
+		client := scorecard.NewClient()
+		client.HTTPClient = httpClient(opts)
+		err := scorecard.Enrich(ctx, client, result)
+		tracing.End(span, err)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Registry enrichments share a client, which caches package metadata
+	packages := registry.NewClient()
+	packages.NPMRC = config
+	packages.HTTPClient = httpClient(opts)
+	for _, step := range registryEnrichments(opts, packages) {
+		if !opts.Enabled(step.name) {
+			continue
+		}
+		if opts.Offline {
+			return fmt.Errorf("%w: %s", ErrOffline, step.name)
+		}
+
+		ctx, span := tracing.Start(ctx, "enrich "+step.name)
+		err := step.enrich(ctx, packages, result)
+		tracing.End(span, err)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Per-dependency enrichers run last, together
+	var enrichers []pipeline.Enricher
+	for _, enricher := range append([]pipeline.Enricher{pipeline.License{Source: packages}, pipeline.Metadata{Source: packages}}, pipeline.Registered()...) {
+		if !opts.Enabled(enricher.Name()) {
+			continue
+		}
+		if opts.Offline {
+			return fmt.Errorf("%w: %s", ErrOffline, enricher.Name())
+		}
+		enrichers = append(enrichers, enricher)
+	}
+	if opts.Enabled(pipeline.OSVName) {
+		source, closeSource, err := vulnSource(opts)
+		if err != nil {
+			return err
+		}
+		defer closeSource()
+		enrichers = append(enrichers, pipeline.OSV{Source: source})
+	}
+	if len(enrichers) == 0 {
+		return nil
+	}
+	ctx, span := tracing.Start(ctx, "enrich dependencies")
+	err := pipeline.Run(ctx, result, enrichers, pipeline.Options{Workers: opts.EnrichWorkers, Rate: opts.EnrichRate})
+	tracing.End(span, err)
+	return err
+}
+
+// registryEnrichment is an enrichment step using npm and Go registry metadata.
+// Unlike a per-dependency enricher, a step sees the whole result: it looks up
+// each package once for all its installed versions, may add findings, and
+// fails the scan when the registry cannot be reached rather than leaving the
+// result partial.
+type registryEnrichment struct {
+	name   string
+	enrich func(context.Context, registry.Source, *scanners.ScanResult) error
+}
+
+// registryEnrichments returns the registry enrichment steps, in order
+func registryEnrichments(opts scanners.ScanOptions, packages *registry.Client) []registryEnrichment {
+	sums := sync.OnceValue(func() *registry.ChecksumDB {
+		client := registry.NewClient()
+		client.HTTPClient = httpClient(opts)
+		return registry.NewChecksumDB(client)
+	})
+	return []registryEnrichment{
+		{outdated.Enrichment, outdated.Enrich},
+		{deprecation.Enrichment, deprecation.Enrich},
+		{lifecycle.Enrichment, func(ctx context.Context, source registry.Source, result *scanners.ScanResult) error {
+			return lifecycle.Enrich(ctx, source, result, opts.IncludeScripts)
+		}},
+		{abandoned.Enrichment, func(ctx context.Context, source registry.Source, result *scanners.ScanResult) error {
+			return abandoned.Enrich(ctx, source, result, opts.AbandonedDays)
+		}},
+		{provenance.Enrichment, func(ctx context.Context, source registry.Source, result *scanners.ScanResult) error {
+			return provenance.Enrich(ctx, packages, sums(), result)
+		}},
+		{integrity.Enrichment, func(ctx context.Context, source registry.Source, result *scanners.ScanResult) error {
+			return integrity.Enrich(ctx, source, sums(), result)
+		}},
+		{size.Enrichment, func(ctx context.Context, source registry.Source, result *scanners.ScanResult) error {
+			return size.Enrich(ctx, packages, result)
+		}},
+		{age.Enrichment, func(ctx context.Context, source registry.Source, result *scanners.ScanResult) error {
+			return age.Enrich(ctx, packages, result)
+		}},
+		{maintainers.Enrichment, func(ctx context.Context, source registry.Source, result *scanners.ScanResult) error {
+			return maintainers.Enrich(ctx, source, result, opts.Enabled(maintainers.SingleMaintainerEnrichment))
+		}},
+	}
+}
+
+// vulnSource returns the configured local vulnerability database, the
+// default one when offline, or the OSV.dev API
+func vulnSource(opts scanners.ScanOptions) (vulns.Source, func(), error) {
+	path := opts.VulnDB
+	if path == "" && opts.Offline {
+		path = vulndb.DefaultPath()
+	}
+	if path == "" {
+		client := vulns.NewClient()
+		client.HTTPClient = httpClient(opts)
+		return client, func() {}, nil
+	}
+
+	db, err := vulndb.Open(path)
+	if errors.Is(err, vulndb.ErrNoDatabase) && opts.VulnDB == "" {
+		return nil, nil, fmt.Errorf("%w: %s (%v)", ErrOffline, vulns.Enrichment, err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, func() { db.Close() }, nil
+}
+
+// httpClient returns the HTTP client of the options, or the default one
+// retrying failed requests. Offline, it returns a client failing every
+// request, so that a step missing its offline check cannot reach the network.
+func httpClient(opts scanners.ScanOptions) *http.Client {
+	if opts.Offline {
+		return httpclient.Offline
+	}
+	if opts.HTTPClient != nil {
+		return opts.HTTPClient
+	}
+	return httpclient.Default
+}
+
+func targetAttributes(target Target) []attribute.KeyValue {
+	switch {
+	case target.FS != nil:
+		return []attribute.KeyValue{attribute.String("deplister.target", "fs")}
+	case target.Repo != "":
+		return []attribute.KeyValue{attribute.String("deplister.target", "repo"), attribute.String("deplister.repo", target.Repo)}
+	}
+	return []attribute.KeyValue{attribute.String("deplister.target", "path"), attribute.String("deplister.path", target.Path)}
+}
+
+// Detect returns the type of the first registered scanner supporting the
+// target. Offline options fail repositories with ErrOffline, as Scan does.
+func Detect(ctx context.Context, target Target, opts scanners.ScanOptions) (string, error) {
+	proj, cleanup, err := resolve(ctx, target, opts.Offline)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	scanner, err := detect(ctx, proj)
+	if err != nil {
+		return "", err
+	}
+	return scanner.GetType(), nil
+}
+
+// Discover returns the directories under root, root included, that a
+// registered scanner supports, parents before their children. Directories
+// the options or .gitignore files exclude are not descended into.
+func Discover(ctx context.Context, root string, opts discover.Options) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "deplister.discover", attribute.String("deplister.path", root))
+	defer span.End()
+
+	dirs, err := discover.Dirs(root, opts)
+	if err != nil {
+		return nil, err
+	}
+	var projects []string
+	for _, dir := range dirs {
+		path := filepath.Join(root, filepath.FromSlash(dir))
+		for _, scanner := range scanners.All() {
+			if scanner.DetectProject(ctx, path) {
+				projects = append(projects, path)
+				break
+			}
+		}
+	}
+	span.SetAttributes(attribute.Int("deplister.projects", len(projects)))
+	return projects, nil
+}
+
+// Files resolves the target and returns its contents. The returned cleanup
+// function releases them. Offline options fail repositories with ErrOffline.
+func Files(ctx context.Context, target Target, opts scanners.ScanOptions) (fs.FS, func(), error) {
+	proj, cleanup, err := resolve(ctx, target, opts.Offline)
+	if err != nil {
+		return nil, nil, err
+	}
+	return proj.files(), cleanup, nil
+}
+
+// resolve turns a target into a project, cloning repositories and opening
+// archives as needed. Offline, repositories fail with ErrOffline instead of
+// being cloned. The returned cleanup function releases the project.
+func resolve(ctx context.Context, target Target, offline bool) (project, func(), error) {
+	switch {
+	case target.FS != nil:
+		return project{fsys: target.FS}, func() {}, nil
+
+	case target.Repo != "":
+		if offline {
+			return project{}, nil, fmt.Errorf("%w: cloning %s", ErrOffline, target.Repo)
+		}
+		repo, err := remote.ParseRepo(target.Repo)
+		if err != nil {
+			return project{}, nil, err
+		}
+		dir, cleanup, err := remote.Clone(ctx, repo)
+		if err != nil {
+			return project{}, nil, err
+		}
+		return project{dir: dir}, cleanup, nil
+
+	case target.Path != "":
+		absPath, err := filepath.Abs(target.Path)
+		if err != nil {
+			return project{}, nil, err
+		}
+		if archive.IsArchive(absPath) {
+			fsys, err := archive.Open(absPath)
+			if err != nil {
+				return project{}, nil, err
+			}
+			return project{fsys: fsys}, func() {}, nil
+		}
+		return project{dir: absPath}, func() {}, nil
+	}
+
+	return project{}, nil, ErrInvalidTarget
+}
+
+func detect(ctx context.Context, proj project) (scanners.Scanner, error) {
+	ctx, span := tracing.Start(ctx, "deplister.detect")
+	defer span.End()
+
+	for _, scanner := range scanners.All() {
+		if proj.fsys != nil {
+			fsScanner, ok := scanner.(scanners.FSScanner)
+			if ok && fsScanner.DetectProjectFS(ctx, proj.fsys) {
+				return scanner, nil
+			}
+			continue
+		}
+		if scanner.DetectProject(ctx, proj.dir) {
+			return scanner, nil
+		}
+	}
+
+	if proj.dir != "" {
+		return nil, fmt.Errorf("%w at %s", ErrNoProject, proj.dir)
+	}
+	return nil, ErrNoProject
+}
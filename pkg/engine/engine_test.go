@@ -0,0 +1,245 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/santoshdahal12/deplister/pkg/discover"
+	pipeline "github.com/santoshdahal12/deplister/pkg/enrich"
+	"github.com/santoshdahal12/deplister/pkg/httpclient"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	_ "github.com/santoshdahal12/deplister/pkg/scanners/npm"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testPackageJSON = `{"name": "test-project", "dependencies": {"lodash": "^4.17.21"}}`
+	testPackageLock = `{
+		"name": "test-project",
+		"packages": {
+			"": {"name": "test-project"},
+			"node_modules/lodash": {"version": "4.17.21"}
+		}
+	}`
+)
+
+func TestScan_Path(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(testPackageJSON), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "package-lock.json"), []byte(testPackageLock), 0644)
+	assert.NoError(t, err)
+
+	report, err := Scan(context.Background(), Target{Path: dir}, scanners.DefaultScanOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, "npm", report.ProjectType)
+	assert.Len(t, report.Result.Dependencies, 1)
+}
+
+func TestScan_FS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json":      {Data: []byte(testPackageJSON)},
+		"package-lock.json": {Data: []byte(testPackageLock)},
+	}
+
+	report, err := Scan(context.Background(), Target{FS: fsys}, scanners.DefaultScanOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, "npm", report.ProjectType)
+	assert.Equal(t, "lodash", report.Result.Dependencies[0].Name)
+}
+
+func TestScan_PathsNone(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json":      {Data: []byte(testPackageJSON)},
+		"package-lock.json": {Data: []byte(testPackageLock)},
+	}
+
+	report, err := Scan(context.Background(), Target{FS: fsys}, scanners.DefaultScanOptions())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, report.Result.Dependencies[0].Paths)
+
+	opts := scanners.DefaultScanOptions()
+	opts.Paths = scanners.PathsNone
+	report, err = Scan(context.Background(), Target{FS: fsys}, opts)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Result.Dependencies[0].Paths)
+	assert.Empty(t, report.Result.Graph.Nodes["lodash@4.17.21"].Paths)
+	assert.Equal(t, 1, report.Result.Dependencies[0].Depth)
+}
+
+// stampEnricher sets a property on every dependency
+type stampEnricher struct{}
+
+func (stampEnricher) Name() string { return "stamp" }
+
+func (stampEnricher) Enrich(ctx context.Context, dep *scanners.Dependency) error {
+	dep.Properties = map[string]string{"stamp": "x"}
+	return nil
+}
+
+func TestScan_SyncGraph(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json":      {Data: []byte(testPackageJSON)},
+		"package-lock.json": {Data: []byte(testPackageLock)},
+	}
+	assert.NoError(t, pipeline.Register(stampEnricher{}))
+
+	opts := scanners.DefaultScanOptions()
+	opts.Enrich = map[string]bool{"stamp": true}
+	report, err := Scan(context.Background(), Target{FS: fsys}, opts)
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]string{"stamp": "x"}, report.Result.Graph.Nodes["lodash@4.17.21"].Properties)
+	}
+}
+
+func TestScan_Errors(t *testing.T) {
+	_, err := Scan(context.Background(), Target{}, scanners.DefaultScanOptions())
+	assert.ErrorIs(t, err, ErrInvalidTarget)
+
+	_, err = Scan(context.Background(), Target{Path: t.TempDir()}, scanners.DefaultScanOptions())
+	assert.ErrorIs(t, err, ErrNoProject)
+
+	_, err = Scan(context.Background(), Target{FS: fstest.MapFS{}}, scanners.DefaultScanOptions())
+	assert.ErrorIs(t, err, ErrNoProject)
+	var projectErr *ProjectError
+	assert.False(t, errors.As(err, &projectErr))
+
+	broken := fstest.MapFS{
+		"package.json":      {Data: []byte(testPackageJSON)},
+		"package-lock.json": {Data: []byte("{")},
+	}
+	_, err = Scan(context.Background(), Target{FS: broken}, scanners.DefaultScanOptions())
+	assert.ErrorIs(t, err, scanners.ErrInvalidProject)
+	if assert.ErrorAs(t, err, &projectErr) {
+		assert.Equal(t, "npm", projectErr.ProjectType)
+	}
+}
+
+func TestScan_OfflineEnrichment(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json":      {Data: []byte(testPackageJSON)},
+		"package-lock.json": {Data: []byte(testPackageLock)},
+	}
+
+	// Without a downloaded vulnerability database in the cache directory
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	opts := scanners.DefaultScanOptions()
+	opts.Offline = true
+	opts.Enrich = map[string]bool{"vulns": true}
+
+	_, err := Scan(context.Background(), Target{FS: fsys}, opts)
+	assert.ErrorIs(t, err, ErrOffline)
+
+	for _, enrichment := range []string{"outdated", "deprecated", "scripts", "provenance", "verify", "scorecard", "abandoned", "licenses", "metadata"} {
+		opts.Enrich = map[string]bool{enrichment: true}
+		_, err = Scan(context.Background(), Target{FS: fsys}, opts)
+		assert.ErrorIs(t, err, ErrOffline, enrichment)
+	}
+
+	opts.Enrich = nil
+	_, err = Scan(context.Background(), Target{Repo: "https://github.com/example/app"}, opts)
+	assert.ErrorIs(t, err, ErrOffline)
+	assert.Same(t, httpclient.Offline, httpClient(opts))
+
+	// Detecting and reading repositories would clone them as well
+	_, err = Detect(context.Background(), Target{Repo: "https://github.com/example/app"}, opts)
+	assert.ErrorIs(t, err, ErrOffline)
+	_, _, err = Files(context.Background(), Target{Repo: "https://github.com/example/app"}, opts)
+	assert.ErrorIs(t, err, ErrOffline)
+}
+
+func TestScanAll(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(testPackageJSON), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "package-lock.json"), []byte(testPackageLock), 0644)
+	assert.NoError(t, err)
+	fsys := fstest.MapFS{
+		"package.json":      {Data: []byte(testPackageJSON)},
+		"package-lock.json": {Data: []byte(testPackageLock)},
+	}
+
+	targets := []Target{{Path: dir}, {Path: t.TempDir()}, {FS: fsys}, {}}
+	outcomes := ScanAll(context.Background(), targets, scanners.DefaultScanOptions(), 2)
+	if !assert.Len(t, outcomes, 4) {
+		return
+	}
+
+	assert.Equal(t, targets[0], outcomes[0].Target)
+	assert.NoError(t, outcomes[0].Err)
+	assert.Equal(t, "npm", outcomes[0].Report.ProjectType)
+	assert.ErrorIs(t, outcomes[1].Err, ErrNoProject)
+	assert.Nil(t, outcomes[1].Report)
+	assert.NoError(t, outcomes[2].Err)
+	assert.Len(t, outcomes[2].Report.Result.Dependencies, 1)
+	assert.ErrorIs(t, outcomes[3].Err, ErrInvalidTarget)
+}
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{".", "apps/web", "apps/web/node_modules/lodash", "build/app", "docs"} {
+		err := os.MkdirAll(filepath.Join(root, dir), 0755)
+		assert.NoError(t, err)
+		if dir != "docs" {
+			err = os.WriteFile(filepath.Join(root, dir, "package.json"), []byte(testPackageJSON), 0644)
+			assert.NoError(t, err)
+		}
+	}
+	err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("build/\n"), 0644)
+	assert.NoError(t, err)
+
+	projects, err := Discover(context.Background(), root, discover.Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{root, filepath.Join(root, "apps/web")}, projects)
+
+	projects, err = Discover(context.Background(), root, discover.Options{Exclude: []string{"apps"}, NoGitignore: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{root, filepath.Join(root, "build/app")}, projects)
+}
+
+func TestScan_Cache(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(testPackageJSON), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "package-lock.json"), []byte(testPackageLock), 0644)
+	assert.NoError(t, err)
+
+	opts := scanners.DefaultScanOptions()
+	opts.CacheDir = t.TempDir()
+	report, err := Scan(context.Background(), Target{Path: dir}, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	entries, _ := os.ReadDir(opts.CacheDir)
+	assert.Len(t, entries, 1)
+
+	// A cached result is returned without reading the lockfile again
+	cached := filepath.Join(opts.CacheDir, entries[0].Name())
+	content, _ := os.ReadFile(cached)
+	os.WriteFile(cached, bytes.Replace(content, []byte(`"lodash"`), []byte(`"cached"`), 1), 0644)
+	again, err := Scan(context.Background(), Target{Path: dir}, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "cached", again.Result.Dependencies[0].Name)
+
+	// Changing the lockfile invalidates it
+	err = os.WriteFile(filepath.Join(dir, "package-lock.json"), []byte(strings.Replace(testPackageLock, "4.17.21", "4.17.20", 1)), 0644)
+	assert.NoError(t, err)
+	changed, err := Scan(context.Background(), Target{Path: dir}, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "lodash", changed.Result.Dependencies[0].Name)
+	assert.Equal(t, "4.17.20", changed.Result.Dependencies[0].Version)
+	assert.Len(t, report.Result.Dependencies, 1)
+}
@@ -0,0 +1,128 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/license"
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/vulns"
+)
+
+// Names of the built-in enrichers in ScanOptions.Enrich
+const (
+	LicenseName  = "licenses"
+	OSVName      = "osv"
+	MetadataName = "metadata"
+)
+
+// Dependency properties recorded by the metadata enricher
+const (
+	Latest     = "latest"     // Latest version in the registry
+	Released   = "released"   // Release date of the installed version
+	Deprecated = "deprecated" // Deprecation message of the installed version
+)
+
+// License fills in the license of dependencies the scanner could not find
+// one for with the license their registry version declares
+type License struct {
+	Source registry.Source
+}
+
+// Name implements Enricher
+func (License) Name() string { return LicenseName }
+
+// Enrich implements Enricher
+func (l License) Enrich(ctx context.Context, dep *scanners.Dependency) error {
+	if dep.License != "" {
+		return nil
+	}
+	pkg, err := lookup(ctx, l.Source, dep)
+	if pkg == nil {
+		return err
+	}
+	dep.License = license.Normalize(pkg.Licenses[dep.Version])
+	return nil
+}
+
+// OSV looks up the advisories affecting one dependency at a time, for
+// enrichers needing them per dependency; the vulns enrichment batches the
+// lookups of a whole scan instead
+type OSV struct {
+	Source vulns.Source
+}
+
+// Name implements Enricher
+func (OSV) Name() string { return OSVName }
+
+// Enrich implements Enricher
+func (o OSV) Enrich(ctx context.Context, dep *scanners.Dependency) error {
+	pkg, ok := vulns.PackageFor(*dep)
+	if !ok {
+		return nil
+	}
+	advisories, err := o.Source.Query(ctx, []vulns.Package{pkg})
+	if err != nil {
+		return err
+	}
+	if len(advisories) > 0 {
+		dep.Vulnerabilities = vulns.Vulnerabilities(advisories[0], pkg)
+	}
+	return nil
+}
+
+// Metadata records the latest version of dependencies in their registry,
+// the release date of the installed version and its deprecation message
+type Metadata struct {
+	Source registry.Source
+}
+
+// Name implements Enricher
+func (Metadata) Name() string { return MetadataName }
+
+// Enrich implements Enricher
+func (m Metadata) Enrich(ctx context.Context, dep *scanners.Dependency) error {
+	pkg, err := lookup(ctx, m.Source, dep)
+	if pkg == nil {
+		return err
+	}
+
+	props := make(map[string]string)
+	if pkg.Latest != "" {
+		props[Latest] = pkg.Latest
+	}
+	if released, ok := pkg.Published[dep.Version]; ok {
+		props[Released] = released.UTC().Format(time.DateOnly)
+	}
+	if message, ok := pkg.Deprecated[dep.Version]; ok {
+		if message == "" {
+			message = Deprecated
+		}
+		props[Deprecated] = message
+	}
+	if len(props) == 0 {
+		return nil
+	}
+	if dep.Properties == nil {
+		dep.Properties = make(map[string]string)
+	}
+	for key, value := range props {
+		dep.Properties[key] = value
+	}
+	return nil
+}
+
+// lookup returns the registry package of a dependency, or nil without an
+// error when the registry does not know it
+func lookup(ctx context.Context, source registry.Source, dep *scanners.Dependency) (*registry.Package, error) {
+	if dep.Version == "" {
+		return nil, nil
+	}
+	pkg, err := source.Package(ctx, dep.Type, dep.Name)
+	if errors.Is(err, registry.ErrNotFound) {
+		return nil, nil
+	}
+	return pkg, err
+}
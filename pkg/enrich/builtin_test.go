@@ -0,0 +1,91 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/vulns"
+)
+
+type fakeSource map[string]*registry.Package
+
+func (s fakeSource) Package(ctx context.Context, depType, name string) (*registry.Package, error) {
+	if name == "broken" {
+		return nil, errors.New("registry unavailable")
+	}
+	if pkg, ok := s[name]; ok {
+		return pkg, nil
+	}
+	return nil, registry.ErrNotFound
+}
+
+type fakeVulns map[string][]vulns.OSV
+
+func (s fakeVulns) Query(ctx context.Context, pkgs []vulns.Package) ([][]vulns.OSV, error) {
+	results := make([][]vulns.OSV, len(pkgs))
+	for i, pkg := range pkgs {
+		results[i] = s[pkg.Name]
+	}
+	return results, nil
+}
+
+var source = fakeSource{
+	"lodash": {
+		Name:       "lodash",
+		Latest:     "4.17.21",
+		Licenses:   map[string]string{"4.17.20": "MIT"},
+		Published:  map[string]time.Time{"4.17.20": time.Date(2020, 8, 13, 16, 0, 0, 0, time.UTC)},
+		Deprecated: map[string]string{"4.17.20": ""},
+	},
+}
+
+func TestLicense(t *testing.T) {
+	dep := &scanners.Dependency{Name: "lodash", Version: "4.17.20", Type: "npm"}
+	if assert.NoError(t, License{Source: source}.Enrich(context.Background(), dep)) {
+		assert.Equal(t, "MIT", dep.License)
+	}
+
+	// Licenses found by the scanner are kept
+	dep = &scanners.Dependency{Name: "lodash", Version: "4.17.20", Type: "npm", License: "ISC"}
+	if assert.NoError(t, License{Source: source}.Enrich(context.Background(), dep)) {
+		assert.Equal(t, "ISC", dep.License)
+	}
+
+	assert.NoError(t, License{Source: source}.Enrich(context.Background(), &scanners.Dependency{Name: "private", Version: "1.0.0", Type: "npm"}))
+	assert.Error(t, License{Source: source}.Enrich(context.Background(), &scanners.Dependency{Name: "broken", Version: "1.0.0", Type: "npm"}))
+}
+
+func TestMetadata(t *testing.T) {
+	dep := &scanners.Dependency{Name: "lodash", Version: "4.17.20", Type: "npm"}
+	if assert.NoError(t, Metadata{Source: source}.Enrich(context.Background(), dep)) {
+		assert.Equal(t, map[string]string{Latest: "4.17.21", Released: "2020-08-13", Deprecated: "deprecated"}, dep.Properties)
+	}
+
+	dep = &scanners.Dependency{Name: "private", Version: "1.0.0", Type: "npm"}
+	if assert.NoError(t, Metadata{Source: source}.Enrich(context.Background(), dep)) {
+		assert.Nil(t, dep.Properties)
+	}
+}
+
+func TestOSV(t *testing.T) {
+	source := fakeVulns{"lodash": {
+		{ID: "GHSA-35jh-r3h4-6jhm", Summary: "Command injection"},
+		{ID: "GHSA-withdrawn", Withdrawn: "2021-01-01T00:00:00Z"},
+	}}
+	dep := &scanners.Dependency{Name: "lodash", Version: "4.17.20", Type: "npm"}
+	if assert.NoError(t, OSV{Source: source}.Enrich(context.Background(), dep)) && assert.Len(t, dep.Vulnerabilities, 1) {
+		assert.Equal(t, "GHSA-35jh-r3h4-6jhm", dep.Vulnerabilities[0].ID)
+	}
+
+	// Dependencies without an OSV ecosystem are skipped
+	dep = &scanners.Dependency{Name: "local", Type: "plugin"}
+	if assert.NoError(t, OSV{Source: source}.Enrich(context.Background(), dep)) {
+		assert.Nil(t, dep.Vulnerabilities)
+	}
+}
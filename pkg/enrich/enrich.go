@@ -0,0 +1,152 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// ErrEnricherExists is returned when registering a name twice
+var ErrEnricherExists = errors.New("enricher already registered")
+
+// Enricher adds data to one dependency at a time, such as its license or
+// advisories. Run calls it for the dependencies of a scan concurrently, but
+// never for the same dependency at once. Enrichments that report findings
+// or must fail the scan, such as outdated or abandoned, need the whole
+// result and are engine steps instead.
+type Enricher interface {
+	// Name enables the enricher in ScanOptions.Enrich
+	Name() string
+
+	// Enrich updates the dependency in place. Errors are recorded in the
+	// scan result without stopping the other calls.
+	Enrich(ctx context.Context, dep *scanners.Dependency) error
+}
+
+var (
+	mu         sync.RWMutex
+	registered = make(map[string]Enricher)
+)
+
+// Register adds an enricher to the ones scans can enable by name
+func Register(enricher Enricher) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := registered[enricher.Name()]; ok {
+		return ErrEnricherExists
+	}
+	registered[enricher.Name()] = enricher
+	return nil
+}
+
+// Registered returns the registered enrichers, sorted by name
+func Registered() []Enricher {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	enrichers := make([]Enricher, 0, len(registered))
+	for _, enricher := range registered {
+		enrichers = append(enrichers, enricher)
+	}
+	sort.Slice(enrichers, func(i, j int) bool { return enrichers[i].Name() < enrichers[j].Name() })
+	return enrichers
+}
+
+// Names returns the names of the built-in and registered enrichers, sorted
+func Names() []string {
+	names := []string{LicenseName, MetadataName, OSVName}
+	for _, enricher := range Registered() {
+		names = append(names, enricher.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Options controls how Run calls enrichers
+type Options struct {
+	Workers int     // Dependencies enriched at once, 0 for the number of CPUs
+	Rate    float64 // Enricher calls started per second at most, 0 for no limit
+}
+
+// Run calls every enricher on every dependency of the result, in the order
+// given for each dependency. A failing call does not stop the others: it is
+// recorded in result.Errors with the step "enrich <name>". Run only fails
+// when ctx is done.
+func Run(ctx context.Context, result *scanners.ScanResult, enrichers []Enricher, opts Options) error {
+	if len(enrichers) == 0 || len(result.Dependencies) == 0 {
+		return nil
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	var tick <-chan time.Time
+	if opts.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var (
+		wg       sync.WaitGroup
+		errorsMu sync.Mutex
+		failures []scanners.ScanError
+		indexes  = make(chan int)
+	)
+	for range min(workers, len(result.Dependencies)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				dep := &result.Dependencies[i]
+				for _, enricher := range enrichers {
+					if tick != nil {
+						select {
+						case <-tick:
+						case <-ctx.Done():
+							return
+						}
+					}
+					if err := enricher.Enrich(ctx, dep); err != nil && ctx.Err() == nil {
+						errorsMu.Lock()
+						failures = append(failures, scanners.ScanError{
+							Step:    "enrich " + enricher.Name(),
+							Message: fmt.Sprintf("%s: %v", scanners.NodeKey(dep.Name, dep.Version), err),
+						})
+						errorsMu.Unlock()
+					}
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range result.Dependencies {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sort.Slice(failures, func(i, j int) bool {
+		if failures[i].Step != failures[j].Step {
+			return failures[i].Step < failures[j].Step
+		}
+		return failures[i].Message < failures[j].Message
+	})
+	result.Errors = append(result.Errors, failures...)
+	return nil
+}
@@ -0,0 +1,90 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// stampEnricher sets a property on every dependency and fails for names in
+// fail
+type stampEnricher struct {
+	name  string
+	fail  map[string]bool
+	calls atomic.Int32
+}
+
+func (s *stampEnricher) Name() string { return s.name }
+
+func (s *stampEnricher) Enrich(ctx context.Context, dep *scanners.Dependency) error {
+	s.calls.Add(1)
+	if s.fail[dep.Name] {
+		return errors.New("unavailable")
+	}
+	if dep.Properties == nil {
+		dep.Properties = make(map[string]string)
+	}
+	dep.Properties[s.name] = dep.Properties[s.name] + "x"
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "lodash", Version: "4.17.21", Type: "npm"},
+			{Name: "left-pad", Version: "1.3.0", Type: "npm"},
+			{Name: "express", Version: "4.17.1", Type: "npm"},
+		},
+	}
+	first := &stampEnricher{name: "first", fail: map[string]bool{"left-pad": true, "express": true}}
+	second := &stampEnricher{name: "second"}
+
+	if !assert.NoError(t, Run(context.Background(), result, []Enricher{first, second}, Options{Workers: 2})) {
+		return
+	}
+	assert.Equal(t, int32(3), first.calls.Load())
+	assert.Equal(t, map[string]string{"first": "x", "second": "x"}, result.Dependencies[0].Properties)
+	assert.Equal(t, map[string]string{"second": "x"}, result.Dependencies[1].Properties)
+	assert.Equal(t, []scanners.ScanError{
+		{Step: "enrich first", Message: "express@4.17.1: unavailable"},
+		{Step: "enrich first", Message: "left-pad@1.3.0: unavailable"},
+	}, result.Errors)
+}
+
+func TestRun_Rate(t *testing.T) {
+	result := &scanners.ScanResult{Dependencies: make([]scanners.Dependency, 4)}
+	start := time.Now()
+	if assert.NoError(t, Run(context.Background(), result, []Enricher{&stampEnricher{name: "stamp"}}, Options{Rate: 100})) {
+		assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+	}
+}
+
+func TestRun_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := &scanners.ScanResult{Dependencies: make([]scanners.Dependency, 4)}
+	err := Run(ctx, result, []Enricher{&stampEnricher{name: "stamp"}}, Options{Rate: 1})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, result.Errors)
+}
+
+func TestRegister(t *testing.T) {
+	enricher := &stampEnricher{name: "test-register"}
+	if !assert.NoError(t, Register(enricher)) {
+		return
+	}
+	t.Cleanup(func() {
+		mu.Lock()
+		delete(registered, enricher.name)
+		mu.Unlock()
+	})
+	assert.ErrorIs(t, Register(&stampEnricher{name: "test-register"}), ErrEnricherExists)
+	assert.Contains(t, Registered(), Enricher(enricher))
+	assert.Equal(t, []string{LicenseName, MetadataName, OSVName, "test-register"}, Names())
+}
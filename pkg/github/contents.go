@@ -0,0 +1,281 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrFetchFailed is returned when the files of a repository cannot be read
+// through the API
+var ErrFetchFailed = errors.New("fetching repository contents failed")
+
+// RepoFS returns the files of a repository at a ref, read through the REST
+// API instead of a clone. The tree of the repository is listed on first use
+// and each file is downloaded when first opened, so scanning a project only
+// fetches the manifests and lockfiles its scanner reads. The context bounds
+// every request of the file system.
+func (c *Client) RepoFS(ctx context.Context, repository, ref string) (fs.FS, error) {
+	owner, name, ok := strings.Cut(repository, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRepository, repository)
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	return &repoFS{
+		client: c,
+		ctx:    ctx,
+		base:   fmt.Sprintf("%s/repos/%s/%s", strings.TrimSuffix(c.APIURL, "/"), url.PathEscape(owner), url.PathEscape(name)),
+		ref:    ref,
+		data:   make(map[string][]byte),
+	}, nil
+}
+
+// treeEntry is a file or directory of the tree of a repository
+type treeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"` // blob, tree or commit for submodules
+	Size int64  `json:"size"`
+}
+
+// repoFS is a read-only file system over the contents API
+type repoFS struct {
+	client *Client
+	ctx    context.Context
+	base   string
+	ref    string
+
+	treeOnce sync.Once
+	treeErr  error
+	entries  map[string]*repoEntry
+
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+// repoEntry is a file or directory of a repoFS
+type repoEntry struct {
+	name     string
+	size     int64
+	dir      bool
+	children []*repoEntry
+}
+
+func (e *repoEntry) Name() string { return e.name }
+func (e *repoEntry) Size() int64  { return e.size }
+func (e *repoEntry) Mode() fs.FileMode {
+	if e.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+func (e *repoEntry) ModTime() time.Time         { return time.Time{} }
+func (e *repoEntry) IsDir() bool                { return e.dir }
+func (e *repoEntry) Sys() any                   { return nil }
+func (e *repoEntry) Type() fs.FileMode          { return e.Mode().Type() }
+func (e *repoEntry) Info() (fs.FileInfo, error) { return e, nil }
+
+// tree lists the repository once, building its directories
+func (r *repoFS) tree() error {
+	r.treeOnce.Do(func() {
+		var tree struct {
+			Tree      []treeEntry `json:"tree"`
+			Truncated bool        `json:"truncated"`
+		}
+		content, err := r.get(r.base+"/git/trees/"+url.PathEscape(r.ref)+"?recursive=1", "application/vnd.github+json")
+		if err == nil {
+			err = json.Unmarshal(content, &tree)
+		}
+		if err != nil {
+			r.treeErr = err
+			return
+		}
+		if tree.Truncated {
+			r.treeErr = fmt.Errorf("%w: the tree of %s is too large to list", ErrFetchFailed, r.base)
+			return
+		}
+
+		r.entries = map[string]*repoEntry{".": {name: ".", dir: true}}
+		var dir func(name string) *repoEntry
+		dir = func(name string) *repoEntry {
+			if entry, ok := r.entries[name]; ok {
+				return entry
+			}
+			entry := &repoEntry{name: path.Base(name), dir: true}
+			r.entries[name] = entry
+			parent := dir(path.Dir(name))
+			parent.children = append(parent.children, entry)
+			return entry
+		}
+		for _, item := range tree.Tree {
+			switch item.Type {
+			case "tree":
+				dir(item.Path)
+			case "blob":
+				entry := &repoEntry{name: path.Base(item.Path), size: item.Size}
+				r.entries[item.Path] = entry
+				parent := dir(path.Dir(item.Path))
+				parent.children = append(parent.children, entry)
+			}
+		}
+		for _, entry := range r.entries {
+			sort.Slice(entry.children, func(i, j int) bool { return entry.children[i].name < entry.children[j].name })
+		}
+	})
+	return r.treeErr
+}
+
+// lookup returns the entry of a path
+func (r *repoFS) lookup(op, name string) (*repoEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if err := r.tree(); err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	entry, ok := r.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return entry, nil
+}
+
+func (r *repoFS) Open(name string) (fs.File, error) {
+	entry, err := r.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.dir {
+		return &repoDir{entry: entry}, nil
+	}
+	data, err := r.file(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &repoFile{entry: entry, reader: bytes.NewReader(data)}, nil
+}
+
+func (r *repoFS) Stat(name string) (fs.FileInfo, error) {
+	return r.lookup("stat", name)
+}
+
+func (r *repoFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entry, err := r.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	entries := make([]fs.DirEntry, len(entry.children))
+	for i, child := range entry.children {
+		entries[i] = child
+	}
+	return entries, nil
+}
+
+// file downloads the contents of a file once
+func (r *repoFS) file(name string) ([]byte, error) {
+	r.mu.Lock()
+	data, ok := r.data[name]
+	r.mu.Unlock()
+	if ok {
+		return data, nil
+	}
+
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	data, err := r.get(r.base+"/contents/"+strings.Join(segments, "/")+"?ref="+url.QueryEscape(r.ref), "application/vnd.github.raw+json")
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.data[name] = data
+	r.mu.Unlock()
+	return data, nil
+}
+
+func (r *repoFS) get(address, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if r.client.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.client.Token)
+	}
+
+	resp, err := r.client.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(content, &apiErr) == nil && apiErr.Message != "" {
+			return nil, fmt.Errorf("%w: %s: %s", ErrFetchFailed, resp.Status, apiErr.Message)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrFetchFailed, resp.Status)
+	}
+	return content, nil
+}
+
+// repoFile is an open file
+type repoFile struct {
+	entry  *repoEntry
+	reader *bytes.Reader
+}
+
+func (f *repoFile) Stat() (fs.FileInfo, error) { return f.entry, nil }
+func (f *repoFile) Read(b []byte) (int, error) { return f.reader.Read(b) }
+func (f *repoFile) Close() error               { return nil }
+
+// repoDir is an open directory
+type repoDir struct {
+	entry  *repoEntry
+	offset int
+}
+
+func (d *repoDir) Stat() (fs.FileInfo, error) { return d.entry, nil }
+func (d *repoDir) Close() error               { return nil }
+
+func (d *repoDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.entry.name, Err: fs.ErrInvalid}
+}
+
+func (d *repoDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	remaining := d.entry.children[d.offset:]
+	if count > 0 && len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if count <= 0 || count > len(remaining) {
+		count = len(remaining)
+	}
+	d.offset += count
+	entries := make([]fs.DirEntry, count)
+	for i, child := range remaining[:count] {
+		entries[i] = child
+	}
+	return entries, nil
+}
@@ -0,0 +1,91 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_RepoFS(t *testing.T) {
+	files := map[string]string{
+		"package.json":             `{"name": "web"}`,
+		"package-lock.json":        `{"lockfileVersion": 3}`,
+		"packages/ui/package.json": `{"name": "ui"}`,
+		"docs/getting started.md":  "# Hello",
+	}
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		switch {
+		case r.URL.Path == "/repos/acme/web/git/trees/main":
+			assert.Equal(t, "1", r.URL.Query().Get("recursive"))
+			fmt.Fprint(w, `{"tree": [
+				{"path": "package.json", "type": "blob", "size": 15},
+				{"path": "package-lock.json", "type": "blob", "size": 21},
+				{"path": "packages", "type": "tree"},
+				{"path": "packages/ui", "type": "tree"},
+				{"path": "packages/ui/package.json", "type": "blob", "size": 14},
+				{"path": "docs/getting started.md", "type": "blob", "size": 7},
+				{"path": "vendor/lib", "type": "commit"}
+			]}`)
+		default:
+			name := r.URL.Path[len("/repos/acme/web/contents/"):]
+			assert.Equal(t, "main", r.URL.Query().Get("ref"))
+			assert.Equal(t, "application/vnd.github.raw+json", r.Header.Get("Accept"))
+			content, ok := files[name]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			fetches.Add(1)
+			fmt.Fprint(w, content)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("secret")
+	client.APIURL = server.URL
+
+	fsys, err := client.RepoFS(context.Background(), "acme/web", "main")
+	assert.NoError(t, err)
+
+	data, err := fs.ReadFile(fsys, "package.json")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name": "web"}`, string(data))
+	_, err = fs.ReadFile(fsys, "package.json")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	_, err = fs.Stat(fsys, "go.mod")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	assert.NoError(t, fstest.TestFS(fsys, "package.json", "package-lock.json", "packages/ui/package.json", "docs/getting started.md"))
+}
+
+func TestClient_RepoFSErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient("")
+	client.APIURL = server.URL
+
+	_, err := client.RepoFS(context.Background(), "acme", "")
+	assert.ErrorIs(t, err, ErrInvalidRepository)
+
+	fsys, err := client.RepoFS(context.Background(), "acme/missing", "")
+	assert.NoError(t, err)
+	_, err = fs.Stat(fsys, "package.json")
+	assert.ErrorIs(t, err, ErrFetchFailed)
+	assert.ErrorContains(t, err, "Not Found")
+}
@@ -0,0 +1,93 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrListFailed is returned when the repositories of an organization cannot
+// be listed
+var ErrListFailed = errors.New("listing repositories failed")
+
+// Repository is a repository of an organization
+type Repository struct {
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	CloneURL      string `json:"clone_url"`
+	DefaultBranch string `json:"default_branch"`
+	Private       bool   `json:"private"`
+	Fork          bool   `json:"fork"`
+	Archived      bool   `json:"archived"`
+}
+
+// OrgRepos lists every repository of an organization the token can see,
+// following the API's pagination
+func (c *Client) OrgRepos(ctx context.Context, org string) ([]Repository, error) {
+	if org == "" || strings.Contains(org, "/") {
+		return nil, fmt.Errorf("%w: invalid organization %q", ErrListFailed, org)
+	}
+
+	next := fmt.Sprintf("%s/orgs/%s/repos?per_page=100", strings.TrimSuffix(c.APIURL, "/"), url.PathEscape(org))
+	var repos []Repository
+	for next != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		if c.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.Token)
+		}
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			var apiErr struct {
+				Message string `json:"message"`
+			}
+			if json.Unmarshal(content, &apiErr) == nil && apiErr.Message != "" {
+				return nil, fmt.Errorf("%w: %s: %s", ErrListFailed, resp.Status, apiErr.Message)
+			}
+			return nil, fmt.Errorf("%w: %s", ErrListFailed, resp.Status)
+		}
+
+		var page []Repository
+		if err := json.Unmarshal(content, &page); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrListFailed, err)
+		}
+		repos = append(repos, page...)
+		next = nextLink(resp.Header.Get("Link"))
+	}
+	return repos, nil
+}
+
+// nextLink returns the URL of the rel="next" entry of a Link header, empty on
+// the last page
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+		if !ok {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_OrgRepos(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/orgs/acme/repos", r.URL.Path)
+		assert.Equal(t, "100", r.URL.Query().Get("per_page"))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"name": "web", "full_name": "acme/web", "clone_url": "https://github.com/acme/web.git", "default_branch": "develop", "fork": true}]`)
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/orgs/acme/repos?per_page=100&page=2>; rel="next", <%s/orgs/acme/repos?per_page=100&page=2>; rel="last"`, server.URL, server.URL))
+		fmt.Fprint(w, `[{"name": "api", "full_name": "acme/api", "clone_url": "https://github.com/acme/api.git", "default_branch": "main", "private": true, "archived": true}]`)
+	}))
+	defer server.Close()
+
+	client := NewClient("secret")
+	client.APIURL = server.URL
+
+	repos, err := client.OrgRepos(context.Background(), "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, []Repository{
+		{Name: "api", FullName: "acme/api", CloneURL: "https://github.com/acme/api.git", DefaultBranch: "main", Private: true, Archived: true},
+		{Name: "web", FullName: "acme/web", CloneURL: "https://github.com/acme/web.git", DefaultBranch: "develop", Fork: true},
+	}, repos)
+}
+
+func TestClient_OrgReposErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient("")
+	client.APIURL = server.URL
+
+	_, err := client.OrgRepos(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrListFailed)
+	assert.ErrorContains(t, err, "Not Found")
+
+	_, err = client.OrgRepos(context.Background(), "acme/api")
+	assert.ErrorIs(t, err, ErrListFailed)
+}
+
+func TestNextLink(t *testing.T) {
+	assert.Equal(t, "https://api.github.com/x?page=3", nextLink(`<https://api.github.com/x?page=1>; rel="prev", <https://api.github.com/x?page=3>; rel="next"`))
+	assert.Equal(t, "", nextLink(`<https://api.github.com/x?page=1>; rel="first"`))
+	assert.Equal(t, "", nextLink(""))
+}
@@ -0,0 +1,237 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/purl"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// DefaultAPIURL is the REST API of github.com
+const DefaultAPIURL = "https://api.github.com"
+
+// Common errors
+var (
+	ErrInvalidRepository = errors.New("repository must be owner/name")
+	ErrSubmissionFailed  = errors.New("dependency submission failed")
+)
+
+// Snapshot is a dependency snapshot in the format of GitHub's Dependency
+// Submission API
+type Snapshot struct {
+	Version   int                 `json:"version"`
+	SHA       string              `json:"sha"`
+	Ref       string              `json:"ref"`
+	Job       Job                 `json:"job"`
+	Detector  Detector            `json:"detector"`
+	Scanned   time.Time           `json:"scanned"`
+	Manifests map[string]Manifest `json:"manifests"`
+}
+
+// Job identifies the run that produced a snapshot. Snapshots with the same
+// correlator replace each other.
+type Job struct {
+	Correlator string `json:"correlator"`
+	ID         string `json:"id"`
+	HTMLURL    string `json:"html_url,omitempty"`
+}
+
+// Detector describes the tool that produced a snapshot
+type Detector struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	URL     string `json:"url"`
+}
+
+// Manifest lists the packages resolved from a single manifest file
+type Manifest struct {
+	Name     string                     `json:"name"`
+	File     *ManifestFile              `json:"file,omitempty"`
+	Resolved map[string]ResolvedPackage `json:"resolved"`
+}
+
+// ManifestFile locates a manifest within the repository
+type ManifestFile struct {
+	SourceLocation string `json:"source_location"`
+}
+
+// ResolvedPackage is a package in a manifest
+type ResolvedPackage struct {
+	PackageURL   string   `json:"package_url"`
+	Relationship string   `json:"relationship"`
+	Scope        string   `json:"scope"`
+	Dependencies []string `json:"dependencies"`
+}
+
+// SnapshotOptions describes the commit and job a snapshot is submitted for
+type SnapshotOptions struct {
+	SHA             string
+	Ref             string
+	Correlator      string
+	JobID           string
+	JobURL          string
+	Manifest        string // Path of the manifest in the repository
+	DetectorVersion string
+	Scanned         time.Time
+}
+
+// ManifestName returns the file GitHub should attribute dependencies of a
+// project type to
+func ManifestName(projectType string) string {
+	switch projectType {
+	case "npm":
+		return "package-lock.json"
+	case "go":
+		return "go.mod"
+	}
+	return projectType
+}
+
+// NewSnapshot converts a scan report into a dependency snapshot. Packages are
+// keyed by package URL and list the package URLs of their dependencies.
+func NewSnapshot(report *engine.Report, opts SnapshotOptions) *Snapshot {
+	result := report.Result
+	purls := make(map[string]string, len(result.Dependencies))
+	for _, dep := range result.Dependencies {
+		purls[scanners.NodeKey(dep.Name, dep.Version)] = purl.For(dep)
+	}
+
+	resolved := make(map[string]ResolvedPackage, len(result.Dependencies))
+	for _, dep := range result.Dependencies {
+		pkg := ResolvedPackage{
+			PackageURL:   purls[scanners.NodeKey(dep.Name, dep.Version)],
+			Relationship: "indirect",
+			Scope:        "runtime",
+			Dependencies: []string{},
+		}
+		if dep.IsDirectDep {
+			pkg.Relationship = "direct"
+		}
+		if dep.Properties["dependencyType"] == "development" {
+			pkg.Scope = "development"
+		}
+		if result.Graph != nil {
+			for _, child := range result.Graph.Edges[scanners.NodeKey(dep.Name, dep.Version)] {
+				if p, ok := purls[child]; ok {
+					pkg.Dependencies = append(pkg.Dependencies, p)
+				}
+			}
+			sort.Strings(pkg.Dependencies)
+		}
+		resolved[pkg.PackageURL] = pkg
+	}
+
+	manifest := opts.Manifest
+	if manifest == "" {
+		manifest = ManifestName(report.ProjectType)
+	}
+
+	return &Snapshot{
+		SHA: opts.SHA,
+		Ref: opts.Ref,
+		Job: Job{
+			Correlator: opts.Correlator,
+			ID:         opts.JobID,
+			HTMLURL:    opts.JobURL,
+		},
+		Detector: Detector{
+			Name:    "deplister",
+			Version: opts.DetectorVersion,
+			URL:     "https://github.com/santoshdahal12/deplister",
+		},
+		Scanned: opts.Scanned.UTC(),
+		Manifests: map[string]Manifest{
+			manifest: {
+				Name:     path.Base(manifest),
+				File:     &ManifestFile{SourceLocation: manifest},
+				Resolved: resolved,
+			},
+		},
+	}
+}
+
+// SubmissionResult is GitHub's response to a snapshot submission
+type SubmissionResult struct {
+	ID        int64  `json:"id"`
+	CreatedAt string `json:"created_at"`
+	Result    string `json:"result"`
+	Message   string `json:"message"`
+}
+
+// Client submits snapshots to the GitHub REST API
+type Client struct {
+	APIURL     string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client for github.com using the given token
+func NewClient(token string) *Client {
+	return &Client{
+		APIURL:     DefaultAPIURL,
+		Token:      token,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Submit posts the snapshot to the repository, given as owner/name
+func (c *Client) Submit(ctx context.Context, repository string, snapshot *Snapshot) (*SubmissionResult, error) {
+	owner, name, ok := strings.Cut(repository, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRepository, repository)
+	}
+
+	body, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/dependency-graph/snapshots", strings.TrimSuffix(c.APIURL, "/"), owner, name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusCreated {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(content, &apiErr) == nil && apiErr.Message != "" {
+			return nil, fmt.Errorf("%w: %s: %s", ErrSubmissionFailed, resp.Status, apiErr.Message)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrSubmissionFailed, resp.Status)
+	}
+
+	var result SubmissionResult
+	if err := json.Unmarshal(content, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,117 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testReport() *engine.Report {
+	deps := []scanners.Dependency{
+		{Name: "express", Version: "4.18.2", Type: "npm", IsDirectDep: true, Properties: map[string]string{"dependencyType": "production"}},
+		{Name: "debug", Version: "2.6.9", Type: "npm", Properties: map[string]string{"dependencyType": "production"}},
+		{Name: "jest", Version: "29.7.0", Type: "npm", IsDirectDep: true, Properties: map[string]string{"dependencyType": "development"}},
+	}
+	return &engine.Report{
+		ProjectType: "npm",
+		Result: &scanners.ScanResult{
+			Dependencies: deps,
+			Graph: &scanners.DependencyGraph{
+				Edges: map[string][]string{
+					"":               {"express@4.18.2", "jest@29.7.0"},
+					"express@4.18.2": {"debug@2.6.9", "filtered-out@1.0.0"},
+				},
+			},
+		},
+	}
+}
+
+func TestNewSnapshot(t *testing.T) {
+	scanned := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	snapshot := NewSnapshot(testReport(), SnapshotOptions{
+		SHA:        "abc123",
+		Ref:        "refs/heads/main",
+		Correlator: "ci_deplister",
+		JobID:      "42",
+		Manifest:   "web/package-lock.json",
+		Scanned:    scanned,
+	})
+
+	assert.Equal(t, "abc123", snapshot.SHA)
+	assert.Equal(t, "refs/heads/main", snapshot.Ref)
+	assert.Equal(t, Job{Correlator: "ci_deplister", ID: "42"}, snapshot.Job)
+	assert.Equal(t, "deplister", snapshot.Detector.Name)
+	assert.Equal(t, scanned, snapshot.Scanned)
+
+	manifest, ok := snapshot.Manifests["web/package-lock.json"]
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, "package-lock.json", manifest.Name)
+	assert.Equal(t, "web/package-lock.json", manifest.File.SourceLocation)
+
+	assert.Equal(t, ResolvedPackage{
+		PackageURL:   "pkg:npm/express@4.18.2",
+		Relationship: "direct",
+		Scope:        "runtime",
+		Dependencies: []string{"pkg:npm/debug@2.6.9"},
+	}, manifest.Resolved["pkg:npm/express@4.18.2"])
+	assert.Equal(t, "indirect", manifest.Resolved["pkg:npm/debug@2.6.9"].Relationship)
+	assert.Equal(t, "development", manifest.Resolved["pkg:npm/jest@29.7.0"].Scope)
+}
+
+func TestNewSnapshot_DefaultManifest(t *testing.T) {
+	report := &engine.Report{ProjectType: "go", Result: &scanners.ScanResult{}}
+	snapshot := NewSnapshot(report, SnapshotOptions{})
+	assert.Contains(t, snapshot.Manifests, "go.mod")
+}
+
+func TestClient_Submit(t *testing.T) {
+	var received Snapshot
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/repos/octo/app/dependency-graph/snapshots", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 7, "created_at": "2024-05-01T12:00:00Z", "result": "SUCCESS", "message": "Dependency results for the repo have been successfully updated."}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("secret")
+	client.APIURL = server.URL
+
+	result, err := client.Submit(context.Background(), "octo/app", NewSnapshot(testReport(), SnapshotOptions{SHA: "abc123"}))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), result.ID)
+	assert.Equal(t, "SUCCESS", result.Result)
+	assert.Equal(t, "abc123", received.SHA)
+	assert.Len(t, received.Manifests["package-lock.json"].Resolved, 3)
+}
+
+func TestClient_SubmitErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("")
+	client.APIURL = server.URL
+
+	_, err := client.Submit(context.Background(), "octo/app", &Snapshot{})
+	assert.ErrorIs(t, err, ErrSubmissionFailed)
+	assert.Contains(t, err.Error(), "Resource not accessible by integration")
+
+	_, err = client.Submit(context.Background(), "octo", &Snapshot{})
+	assert.ErrorIs(t, err, ErrInvalidRepository)
+}
@@ -0,0 +1,98 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultAPIURL is the REST API of gitlab.com
+const DefaultAPIURL = "https://gitlab.com/api/v4"
+
+// ErrListFailed is returned when the projects of a group cannot be listed
+var ErrListFailed = errors.New("listing projects failed")
+
+// Project is a project of a group
+type Project struct {
+	Name              string `json:"name"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	CloneURL          string `json:"http_url_to_repo"`
+	DefaultBranch     string `json:"default_branch"`
+	Visibility        string `json:"visibility"`
+	Archived          bool   `json:"archived"`
+	EmptyRepo         bool   `json:"empty_repo"`
+
+	// ForkedFrom is set for forks
+	ForkedFrom *struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"forked_from_project,omitempty"`
+}
+
+// Client reads from the GitLab REST API
+type Client struct {
+	APIURL     string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client for gitlab.com using the given token
+func NewClient(token string) *Client {
+	return &Client{
+		APIURL:     DefaultAPIURL,
+		Token:      token,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// GroupProjects lists every project of a group and its subgroups the token
+// can see, following the API's pagination
+func (c *Client) GroupProjects(ctx context.Context, group string) ([]Project, error) {
+	if group == "" {
+		return nil, fmt.Errorf("%w: empty group", ErrListFailed)
+	}
+
+	base := fmt.Sprintf("%s/groups/%s/projects", strings.TrimSuffix(c.APIURL, "/"), url.PathEscape(group))
+	var projects []Project
+	for page := "1"; page != ""; {
+		query := url.Values{"include_subgroups": {"true"}, "per_page": {"100"}, "page": {page}}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if c.Token != "" {
+			req.Header.Set("PRIVATE-TOKEN", c.Token)
+		}
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			var apiErr struct {
+				Message string `json:"message"`
+			}
+			if json.Unmarshal(content, &apiErr) == nil && apiErr.Message != "" {
+				return nil, fmt.Errorf("%w: %s: %s", ErrListFailed, resp.Status, apiErr.Message)
+			}
+			return nil, fmt.Errorf("%w: %s", ErrListFailed, resp.Status)
+		}
+
+		var batch []Project
+		if err := json.Unmarshal(content, &batch); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrListFailed, err)
+		}
+		projects = append(projects, batch...)
+		page = resp.Header.Get("X-Next-Page")
+	}
+	return projects, nil
+}
@@ -0,0 +1,61 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_GroupProjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/groups/acme/platform/projects", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("include_subgroups"))
+		assert.Equal(t, "secret", r.Header.Get("PRIVATE-TOKEN"))
+
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"name": "web", "path_with_namespace": "acme/platform/web", "http_url_to_repo": "https://gitlab.com/acme/platform/web.git", "default_branch": "main", "forked_from_project": {"path_with_namespace": "other/web"}}]`)
+			return
+		}
+		w.Header().Set("X-Next-Page", "2")
+		fmt.Fprint(w, `[{"name": "api", "path_with_namespace": "acme/platform/api", "http_url_to_repo": "https://gitlab.com/acme/platform/api.git", "default_branch": "master", "visibility": "private", "archived": true}]`)
+	}))
+	defer server.Close()
+
+	client := NewClient("secret")
+	client.APIURL = server.URL
+
+	projects, err := client.GroupProjects(context.Background(), "acme/platform")
+	assert.NoError(t, err)
+	if assert.Len(t, projects, 2) {
+		assert.Equal(t, "acme/platform/api", projects[0].PathWithNamespace)
+		assert.Equal(t, "https://gitlab.com/acme/platform/api.git", projects[0].CloneURL)
+		assert.Equal(t, "master", projects[0].DefaultBranch)
+		assert.True(t, projects[0].Archived)
+		assert.Nil(t, projects[0].ForkedFrom)
+		if assert.NotNil(t, projects[1].ForkedFrom) {
+			assert.Equal(t, "other/web", projects[1].ForkedFrom.PathWithNamespace)
+		}
+	}
+}
+
+func TestClient_GroupProjectsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "404 Group Not Found"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient("")
+	client.APIURL = server.URL
+
+	_, err := client.GroupProjects(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrListFailed)
+	assert.ErrorContains(t, err, "404 Group Not Found")
+
+	_, err = client.GroupProjects(context.Background(), "")
+	assert.ErrorIs(t, err, ErrListFailed)
+}
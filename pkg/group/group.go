@@ -0,0 +1,139 @@
+package group
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/module"
+
+	"github.com/santoshdahal12/deplister/pkg/maintainers"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Keys dependencies can be grouped by
+const (
+	Scope      = "scope"      // npm scope or Go module path prefix, e.g. @babel or golang.org/x
+	Org        = "org"        // Owning organization, e.g. babel or spf13
+	License    = "license"    // License expression
+	Maintainer = "maintainer" // npm maintainer, from the maintainers enrichment
+)
+
+// Keys lists the supported group keys
+var Keys = []string{Scope, Org, License, Maintainer}
+
+// None is the group of dependencies without a value for the key
+const None = "(none)"
+
+// ErrUnknownKey is returned for group keys not in Keys
+var ErrUnknownKey = errors.New("unknown group key")
+
+// Group is a set of dependencies sharing a value
+type Group struct {
+	Key          string
+	Dependencies []scanners.Dependency
+}
+
+// codeHosts are hosts whose first path element is the owning organization
+var codeHosts = []string{"github.com", "gitlab.com", "bitbucket.org"}
+
+// By groups dependencies by a key, largest groups first and None last among
+// groups of the same size. A dependency with several maintainers is counted
+// in the group of each.
+func By(deps []scanners.Dependency, key string) ([]Group, error) {
+	var valueOf func(scanners.Dependency) []string
+	switch key {
+	case Scope:
+		valueOf = func(dep scanners.Dependency) []string { return []string{scope(dep)} }
+	case Org:
+		valueOf = func(dep scanners.Dependency) []string { return []string{org(dep)} }
+	case License:
+		valueOf = func(dep scanners.Dependency) []string { return []string{dep.License} }
+	case Maintainer:
+		valueOf = func(dep scanners.Dependency) []string {
+			return strings.Split(dep.Properties[maintainers.Property], ",")
+		}
+	default:
+		return nil, fmt.Errorf("%w %q, expected one of %s", ErrUnknownKey, key, strings.Join(Keys, ", "))
+	}
+
+	members := make(map[string][]scanners.Dependency)
+	for _, dep := range deps {
+		for _, value := range valueOf(dep) {
+			if value == "" {
+				value = None
+			}
+			members[value] = append(members[value], dep)
+		}
+	}
+
+	groups := make([]Group, 0, len(members))
+	for value, deps := range members {
+		groups = append(groups, Group{Key: value, Dependencies: deps})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if len(groups[i].Dependencies) != len(groups[j].Dependencies) {
+			return len(groups[i].Dependencies) > len(groups[j].Dependencies)
+		}
+		if (groups[i].Key == None) != (groups[j].Key == None) {
+			return groups[j].Key == None
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups, nil
+}
+
+// scope returns the npm scope of a package, or the prefix of a Go module
+// path shared by related modules: the organization on code hosts, the
+// parent path elsewhere
+func scope(dep scanners.Dependency) string {
+	switch dep.Type {
+	case "npm":
+		if strings.HasPrefix(dep.Name, "@") {
+			name, _, _ := strings.Cut(dep.Name, "/")
+			return name
+		}
+		return ""
+	case "go":
+		path, _, _ := module.SplitPathVersion(dep.Name)
+		elems := strings.Split(path, "/")
+		if len(elems) > 2 && isCodeHost(elems[0]) {
+			return strings.Join(elems[:2], "/")
+		}
+		return strings.Join(elems[:len(elems)-1], "/")
+	}
+	// Other ecosystems separate groups with a colon, as in Maven coordinates
+	if group, _, ok := strings.Cut(dep.Name, ":"); ok {
+		return group
+	}
+	if i := strings.LastIndex(dep.Name, "/"); i > 0 {
+		return dep.Name[:i]
+	}
+	return ""
+}
+
+// org returns the organization publishing a package: the npm scope, the
+// owner on a code host, or the domain of a Go module path
+func org(dep scanners.Dependency) string {
+	switch dep.Type {
+	case "npm":
+		return strings.TrimPrefix(scope(dep), "@")
+	case "go":
+		elems := strings.Split(dep.Name, "/")
+		if len(elems) > 2 && isCodeHost(elems[0]) {
+			return elems[1]
+		}
+		return elems[0]
+	}
+	return scope(dep)
+}
+
+func isCodeHost(host string) bool {
+	for _, codeHost := range codeHosts {
+		if host == codeHost {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package group
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+var deps = []scanners.Dependency{
+	{Name: "@babel/core", Version: "7.24.0", Type: "npm", License: "MIT", Properties: map[string]string{"maintainers": "hzoo,nicolo-ribaudo"}},
+	{Name: "@babel/parser", Version: "7.24.0", Type: "npm", License: "MIT", Properties: map[string]string{"maintainers": "nicolo-ribaudo"}},
+	{Name: "lodash", Version: "4.17.21", Type: "npm", License: "MIT"},
+	{Name: "golang.org/x/net", Version: "v0.24.0", Type: "go", License: "BSD-3-Clause"},
+	{Name: "golang.org/x/text", Version: "v0.14.0", Type: "go", License: "BSD-3-Clause"},
+	{Name: "github.com/spf13/cobra", Version: "v1.8.0", Type: "go", License: "Apache-2.0"},
+	{Name: "github.com/aws/aws-sdk-go-v2/service/s3", Version: "v1.50.0", Type: "go"},
+	{Name: "gopkg.in/yaml.v3", Version: "v3.0.1", Type: "go"},
+	{Name: "org.apache.commons:commons-lang3", Version: "3.14.0", Type: "maven"},
+}
+
+func keysOf(groups []Group) map[string]int {
+	counts := make(map[string]int)
+	for _, group := range groups {
+		counts[group.Key] = len(group.Dependencies)
+	}
+	return counts
+}
+
+func TestBy(t *testing.T) {
+	groups, err := By(deps, Scope)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]int{
+		"@babel":             2,
+		"golang.org/x":       2,
+		None:                 1,
+		"github.com/spf13":   1,
+		"github.com/aws":     1,
+		"gopkg.in":           1,
+		"org.apache.commons": 1,
+	}, keysOf(groups))
+	assert.Equal(t, "@babel", groups[0].Key)
+	assert.Equal(t, "golang.org/x", groups[1].Key)
+
+	groups, err = By(deps, Org)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"babel": 2, "golang.org": 2, None: 1, "spf13": 1, "aws": 1, "gopkg.in": 1, "org.apache.commons": 1}, keysOf(groups))
+
+	groups, err = By(deps, License)
+	assert.NoError(t, err)
+	assert.Equal(t, Group{Key: "MIT", Dependencies: deps[:3]}, groups[0])
+	assert.Equal(t, map[string]int{"MIT": 3, None: 3, "BSD-3-Clause": 2, "Apache-2.0": 1}, keysOf(groups))
+
+	groups, err = By(deps, Maintainer)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{None: 7, "nicolo-ribaudo": 2, "hzoo": 1}, keysOf(groups))
+
+	_, err = By(deps, "color")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
@@ -0,0 +1,323 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	// Registers the pure Go "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+)
+
+// Common errors
+var (
+	ErrNoHistory = errors.New("no scan history")
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS scans (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	project      TEXT    NOT NULL,
+	project_type TEXT    NOT NULL,
+	scanned_at   INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS scans_project ON scans (project, scanned_at);
+CREATE TABLE IF NOT EXISTS dependencies (
+	scan_id INTEGER NOT NULL REFERENCES scans (id) ON DELETE CASCADE,
+	name    TEXT    NOT NULL,
+	version TEXT    NOT NULL,
+	type    TEXT    NOT NULL,
+	direct  INTEGER NOT NULL,
+	depth   INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS dependencies_scan ON dependencies (scan_id);
+`
+
+// Scan is a stored scan of a project
+type Scan struct {
+	ID              int64     `json:"id"`
+	Project         string    `json:"project"`
+	ProjectType     string    `json:"projectType"`
+	ScannedAt       time.Time `json:"scannedAt"`
+	DependencyCount int       `json:"dependencyCount"`
+	DirectCount     int       `json:"directCount"`
+}
+
+// Dependency is a dependency recorded with a scan
+type Dependency struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Type    string `json:"type"`
+	Direct  bool   `json:"isDirectDependency"`
+	Depth   int    `json:"depth"`
+}
+
+// Store persists scans in a SQLite database
+type Store struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// Open opens or creates the database at path
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer; sharing one connection avoids busy errors
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec("PRAGMA foreign_keys = ON;" + schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initializing %s: %w", path, err)
+	}
+
+	return &Store{db: db, now: time.Now}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save records the report as the latest scan of the project
+func (s *Store) Save(ctx context.Context, project string, report *engine.Report) (Scan, error) {
+	scan := Scan{
+		Project:         project,
+		ProjectType:     report.ProjectType,
+		ScannedAt:       s.now().UTC(),
+		DependencyCount: len(report.Result.Dependencies),
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Scan{}, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		"INSERT INTO scans (project, project_type, scanned_at) VALUES (?, ?, ?)",
+		scan.Project, scan.ProjectType, scan.ScannedAt.UnixNano())
+	if err != nil {
+		return Scan{}, err
+	}
+	if scan.ID, err = res.LastInsertId(); err != nil {
+		return Scan{}, err
+	}
+
+	stmt, err := tx.PrepareContext(ctx,
+		"INSERT INTO dependencies (scan_id, name, version, type, direct, depth) VALUES (?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return Scan{}, err
+	}
+	defer stmt.Close()
+
+	for _, dep := range report.Result.Dependencies {
+		if dep.IsDirectDep {
+			scan.DirectCount++
+		}
+		if _, err := stmt.ExecContext(ctx, scan.ID, dep.Name, dep.Version, dep.Type, dep.IsDirectDep, dep.Depth); err != nil {
+			return Scan{}, err
+		}
+	}
+
+	return scan, tx.Commit()
+}
+
+// History returns the scans of the project, newest first. A positive limit
+// caps the number of scans returned.
+func (s *Store) History(ctx context.Context, project string, limit int) ([]Scan, error) {
+	query := `
+		SELECT s.id, s.project, s.project_type, s.scanned_at,
+		       COUNT(d.scan_id), COALESCE(SUM(d.direct), 0)
+		FROM scans s LEFT JOIN dependencies d ON d.scan_id = s.id
+		WHERE s.project = ?
+		GROUP BY s.id
+		ORDER BY s.scanned_at DESC, s.id DESC`
+	args := []any{project}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scans []Scan
+	for rows.Next() {
+		var scan Scan
+		var scannedAt int64
+		if err := rows.Scan(&scan.ID, &scan.Project, &scan.ProjectType, &scannedAt, &scan.DependencyCount, &scan.DirectCount); err != nil {
+			return nil, err
+		}
+		scan.ScannedAt = time.Unix(0, scannedAt).UTC()
+		scans = append(scans, scan)
+	}
+	return scans, rows.Err()
+}
+
+// Projects returns the names of all projects with stored scans
+func (s *Store) Projects(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT DISTINCT project FROM scans ORDER BY project")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []string
+	for rows.Next() {
+		var project string
+		if err := rows.Scan(&project); err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+	return projects, rows.Err()
+}
+
+// Dependencies returns the dependencies recorded with a scan, sorted by name
+func (s *Store) Dependencies(ctx context.Context, scanID int64) ([]Dependency, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT name, version, type, direct, depth FROM dependencies WHERE scan_id = ? ORDER BY name, version",
+		scanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deps []Dependency
+	for rows.Next() {
+		var dep Dependency
+		if err := rows.Scan(&dep.Name, &dep.Version, &dep.Type, &dep.Direct, &dep.Depth); err != nil {
+			return nil, err
+		}
+		deps = append(deps, dep)
+	}
+	return deps, rows.Err()
+}
+
+// VersionChange describes a dependency whose version changed between scans
+type VersionChange struct {
+	Name string `json:"name"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// TrendPoint is a scan together with the changes since the previous scan
+type TrendPoint struct {
+	Scan
+	Added   []Dependency    `json:"added,omitempty"`
+	Removed []Dependency    `json:"removed,omitempty"`
+	Changed []VersionChange `json:"changed,omitempty"`
+}
+
+// Trend returns the project's scans, oldest first, with the dependencies
+// added, removed and changed since the previous scan. The first scan reports
+// no changes.
+func (s *Store) Trend(ctx context.Context, project string) ([]TrendPoint, error) {
+	scans, err := s.History(ctx, project, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(scans) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoHistory, project)
+	}
+
+	points := make([]TrendPoint, len(scans))
+	var previous []Dependency
+	for i := range scans {
+		scan := scans[len(scans)-1-i]
+		deps, err := s.Dependencies(ctx, scan.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		points[i] = TrendPoint{Scan: scan}
+		if i > 0 {
+			points[i].Added, points[i].Removed, points[i].Changed = Diff(previous, deps)
+		}
+		previous = deps
+	}
+
+	return points, nil
+}
+
+// byName returns the distinct versions of the dependencies by name
+func byName(deps []Dependency) map[string][]Dependency {
+	m := make(map[string][]Dependency)
+	for _, dep := range deps {
+		if !slices.ContainsFunc(m[dep.Name], func(d Dependency) bool { return d.Version == dep.Version }) {
+			m[dep.Name] = append(m[dep.Name], dep)
+		}
+	}
+	return m
+}
+
+// Diff compares two dependency sets by name, so that dependencies without an
+// ecosystem, such as SBOM components lacking a package URL, still match. A
+// name present in both with a different single version is a version change;
+// anything else is reported as additions and removals of the individual
+// versions. Each result is sorted by name.
+func Diff(beforeDeps, afterDeps []Dependency) (added, removed []Dependency, changed []VersionChange) {
+	before, after := byName(beforeDeps), byName(afterDeps)
+	for name, deps := range after {
+		old, ok := before[name]
+		switch {
+		case !ok:
+			added = append(added, deps...)
+		case len(old) == 1 && len(deps) == 1:
+			if old[0].Version != deps[0].Version {
+				changed = append(changed, VersionChange{Name: name, From: old[0].Version, To: deps[0].Version})
+			}
+		default:
+			added = append(added, missing(deps, old)...)
+			removed = append(removed, missing(old, deps)...)
+		}
+	}
+	for name, deps := range before {
+		if _, ok := after[name]; !ok {
+			removed = append(removed, deps...)
+		}
+	}
+
+	sortDependencies(added)
+	sortDependencies(removed)
+	sort.Slice(changed, func(i, j int) bool { return changed[i].Name < changed[j].Name })
+	return added, removed, changed
+}
+
+// missing returns the dependencies in deps whose version is not in other
+func missing(deps, other []Dependency) []Dependency {
+	var result []Dependency
+	for _, dep := range deps {
+		found := false
+		for _, o := range other {
+			if o.Version == dep.Version {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, dep)
+		}
+	}
+	return result
+}
+
+func sortDependencies(deps []Dependency) {
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Name != deps[j].Name {
+			return deps[i].Name < deps[j].Name
+		}
+		return deps[i].Version < deps[j].Version
+	})
+}
@@ -0,0 +1,123 @@
+package history
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStore(t *testing.T) *Store {
+	store, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time {
+		now = now.Add(time.Hour)
+		return now
+	}
+	return store
+}
+
+func testReport(deps ...scanners.Dependency) *engine.Report {
+	return &engine.Report{ProjectType: "npm", Result: &scanners.ScanResult{Dependencies: deps}}
+}
+
+func TestStore_History(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	_, err := store.Save(ctx, "/app", testReport(
+		scanners.Dependency{Name: "lodash", Version: "4.17.20", Type: "npm", IsDirectDep: true, Depth: 1},
+	))
+	assert.NoError(t, err)
+	saved, err := store.Save(ctx, "/app", testReport(
+		scanners.Dependency{Name: "lodash", Version: "4.17.21", Type: "npm", IsDirectDep: true, Depth: 1},
+		scanners.Dependency{Name: "ms", Version: "2.1.3", Type: "npm", Depth: 2},
+	))
+	assert.NoError(t, err)
+	_, err = store.Save(ctx, "/other", testReport())
+	assert.NoError(t, err)
+
+	scans, err := store.History(ctx, "/app", 0)
+	assert.NoError(t, err)
+	if assert.Len(t, scans, 2) {
+		assert.Equal(t, saved, scans[0])
+		assert.Equal(t, 2, scans[0].DependencyCount)
+		assert.Equal(t, 1, scans[0].DirectCount)
+		assert.True(t, scans[0].ScannedAt.After(scans[1].ScannedAt))
+	}
+
+	scans, err = store.History(ctx, "/app", 1)
+	assert.NoError(t, err)
+	assert.Len(t, scans, 1)
+
+	scans, err = store.History(ctx, "/other", 0)
+	assert.NoError(t, err)
+	if assert.Len(t, scans, 1) {
+		assert.Equal(t, 0, scans[0].DependencyCount)
+	}
+
+	projects, err := store.Projects(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/app", "/other"}, projects)
+
+	deps, err := store.Dependencies(ctx, saved.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []Dependency{
+		{Name: "lodash", Version: "4.17.21", Type: "npm", Direct: true, Depth: 1},
+		{Name: "ms", Version: "2.1.3", Type: "npm", Depth: 2},
+	}, deps)
+}
+
+func TestStore_Trend(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	_, err := store.Trend(ctx, "/app")
+	assert.ErrorIs(t, err, ErrNoHistory)
+
+	scans := [][]scanners.Dependency{
+		{
+			{Name: "lodash", Version: "4.17.20", Type: "npm"},
+			{Name: "debug", Version: "2.6.9", Type: "npm"},
+		},
+		{
+			{Name: "lodash", Version: "4.17.21", Type: "npm"},
+			{Name: "ms", Version: "2.1.3", Type: "npm"},
+		},
+	}
+	for _, deps := range scans {
+		_, err := store.Save(ctx, "/app", testReport(deps...))
+		assert.NoError(t, err)
+	}
+
+	points, err := store.Trend(ctx, "/app")
+	assert.NoError(t, err)
+	if assert.Len(t, points, 2) {
+		assert.Empty(t, points[0].Added)
+		assert.Empty(t, points[0].Changed)
+
+		assert.Equal(t, []Dependency{{Name: "ms", Version: "2.1.3", Type: "npm"}}, points[1].Added)
+		assert.Equal(t, []Dependency{{Name: "debug", Version: "2.6.9", Type: "npm"}}, points[1].Removed)
+		assert.Equal(t, []VersionChange{{Name: "lodash", From: "4.17.20", To: "4.17.21"}}, points[1].Changed)
+	}
+}
+
+func TestDiff_MultipleVersions(t *testing.T) {
+	before := []Dependency{{Name: "a", Version: "1.0.0"}, {Name: "a", Version: "2.0.0"}}
+	after := []Dependency{{Name: "a", Version: "2.0.0"}, {Name: "a", Version: "3.0.0"}, {Name: "a", Version: "3.0.0"}}
+
+	added, removed, changed := Diff(before, after)
+	assert.Equal(t, []Dependency{{Name: "a", Version: "3.0.0"}}, added)
+	assert.Equal(t, []Dependency{{Name: "a", Version: "1.0.0"}}, removed)
+	assert.Empty(t, changed)
+}
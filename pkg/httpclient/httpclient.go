@@ -0,0 +1,247 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Common errors
+var (
+	ErrInvalidConfig = errors.New("invalid HTTP client configuration")
+	ErrOffline       = errors.New("network access disabled offline")
+)
+
+// Delays between attempts of a request. The delay before a retry is picked at
+// random up to RetryDelay doubled for each earlier retry, and at most
+// MaxRetryDelay, which also bounds the delays servers ask for.
+var (
+	RetryDelay    = 500 * time.Millisecond
+	MaxRetryDelay = 30 * time.Second
+)
+
+// Default is the client of programs that do not configure one: it retries
+// failed requests three times
+var Default = must(New(Config{Retries: 3}))
+
+// Offline is a client failing every request with ErrOffline
+var Offline = must(New(Config{Offline: true}))
+
+// Config describes how deplister reaches the network: registries, OSV.dev,
+// deps.dev, vulnerability database snapshots and the GitHub API
+type Config struct {
+	Proxy   string        // Proxy URL, with user:password for an authenticated proxy; "" for HTTPS_PROXY, HTTP_PROXY and NO_PROXY
+	CAFile  string        // PEM bundle of certificate authorities trusted besides the system ones, such as a proxy's private CA
+	Timeout time.Duration // Limit of each request including reading the response, 0 for none
+	Retries int           // Times a request failing with a connection error or a 5xx or 429 status is sent again
+	Rate    float64       // Requests per second sent to each host at most, 0 for no limit
+	Offline bool          // Fail every request with ErrOffline instead of sending it
+}
+
+// New creates an HTTP client with the configuration
+func New(config Config) (*http.Client, error) {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	if config.Proxy != "" {
+		proxy, err := url.Parse(config.Proxy)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("%w: proxy %q", ErrInvalidConfig, config.Proxy)
+		}
+		base.Proxy = http.ProxyURL(proxy)
+	}
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: no certificates in %s", ErrInvalidConfig, config.CAFile)
+		}
+		base.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	return &http.Client{
+		Transport: &transport{next: base, retries: config.Retries, limiter: newLimiter(config.Rate), offline: config.Offline},
+		Timeout:   config.Timeout,
+	}, nil
+}
+
+func must(client *http.Client, err error) *http.Client {
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// Env returns the environment variables making commands such as the go tool
+// use the proxy and CA bundle of the configuration
+func (c Config) Env() []string {
+	var env []string
+	if c.Proxy != "" {
+		env = append(env, "HTTPS_PROXY="+c.Proxy, "HTTP_PROXY="+c.Proxy)
+	}
+	if c.CAFile != "" {
+		env = append(env, "SSL_CERT_FILE="+c.CAFile)
+	}
+	return env
+}
+
+// transport fails every request offline. Otherwise it waits for the rate limit
+// of a host before each request and sends requests again after connection
+// errors and server errors, with exponential backoff and jitter. Requests whose
+// body cannot be replayed are sent once.
+type transport struct {
+	next    http.RoundTripper
+	retries int
+	limiter *limiter
+	offline bool
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.offline {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		// The client reports the method and URL
+		return nil, ErrOffline
+	}
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		if err := t.limiter.wait(req.Context(), req.URL.Host); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.next.RoundTrip(req)
+		if attempt == t.retries || !retryable(req, resp, err) {
+			return resp, err
+		}
+
+		delay := backoff(attempt)
+		if resp != nil {
+			if after, ok := retryAfter(resp, time.Now()); ok {
+				delay = after
+				// Other requests to the host wait as well
+				t.limiter.pause(req.URL.Host, after)
+			}
+			// Drain the body so that the connection is reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff returns a random delay before retrying after the given attempt, up
+// to RetryDelay doubled for each attempt and at most MaxRetryDelay, so that
+// clients failing together do not retry together
+func backoff(attempt int) time.Duration {
+	limit := RetryDelay << attempt
+	if limit <= 0 || limit > MaxRetryDelay {
+		limit = MaxRetryDelay
+	}
+	return limit/2 + rand.N(limit/2+1)
+}
+
+// retryAfter returns the delay a Retry-After header asks for, in seconds or
+// as a date, at most MaxRetryDelay
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = date.Sub(now)
+	} else {
+		return 0, false
+	}
+	return min(max(delay, 0), MaxRetryDelay), true
+}
+
+// retryable reports whether a failed request can be sent again
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// sleep waits for the delay unless ctx is done first
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// limiter spaces out the requests to each host. A nil limiter does not limit.
+type limiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     map[string]time.Time // Earliest start of the next request, by host
+}
+
+func newLimiter(rate float64) *limiter {
+	if rate <= 0 {
+		return &limiter{next: make(map[string]time.Time)}
+	}
+	return &limiter{interval: time.Duration(float64(time.Second) / rate), next: make(map[string]time.Time)}
+}
+
+// wait reserves the next slot for a request to the host and waits for it
+func (l *limiter) wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	now := time.Now()
+	start := now
+	if next := l.next[host]; next.After(now) {
+		start = next
+	}
+	if l.interval > 0 || start.After(now) {
+		l.next[host] = start.Add(l.interval)
+	}
+	l.mu.Unlock()
+	if !start.After(now) {
+		return nil
+	}
+	return sleep(ctx, start.Sub(now))
+}
+
+// pause delays the requests to the host not yet started
+func (l *limiter) pause(host string, delay time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(delay); until.After(l.next[host]) {
+		l.next[host] = until
+	}
+}
@@ -0,0 +1,214 @@
+package httpclient
+
+import (
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_Retries(t *testing.T) {
+	defer func(delay time.Duration) { RetryDelay = delay }(RetryDelay)
+	RetryDelay = time.Millisecond
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client, err := New(Config{Retries: 2})
+	if !assert.NoError(t, err) {
+		return
+	}
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	if assert.NoError(t, err) {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "payload", string(body))
+	}
+	assert.Equal(t, int32(3), requests.Load())
+
+	// The last failure is returned once retries run out
+	requests.Store(0)
+	client, _ = New(Config{Retries: 1})
+	resp, err = client.Get(server.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	}
+	assert.Equal(t, int32(2), requests.Load())
+
+	// Bodies that cannot be replayed are sent once
+	requests.Store(0)
+	client, _ = New(Config{Retries: 2})
+	resp, err = client.Post(server.URL, "text/plain", io.NopCloser(strings.NewReader("payload")))
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	}
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestNew_Proxy(t *testing.T) {
+	var target, auth string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target, auth = r.URL.String(), r.Header.Get("Proxy-Authorization")
+		io.WriteString(w, "proxied")
+	}))
+	defer proxy.Close()
+
+	client, err := New(Config{Proxy: strings.Replace(proxy.URL, "http://", "http://user:secret@", 1)})
+	if !assert.NoError(t, err) {
+		return
+	}
+	resp, err := client.Get("http://registry.example.com/lodash")
+	if assert.NoError(t, err) {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "proxied", string(body))
+		assert.Equal(t, "http://registry.example.com/lodash", target)
+		assert.Equal(t, "Basic dXNlcjpzZWNyZXQ=", auth)
+	}
+
+	_, err = New(Config{Proxy: "not a url"})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestNew_CAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "trusted")
+	}))
+	defer server.Close()
+
+	// The test server's certificate is not trusted by default
+	client, err := New(Config{Timeout: 5 * time.Second})
+	if assert.NoError(t, err) {
+		_, err = client.Get(server.URL)
+		assert.Error(t, err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if !assert.NoError(t, os.WriteFile(caFile, certificate, 0o600)) {
+		return
+	}
+	client, err = New(Config{CAFile: caFile})
+	if !assert.NoError(t, err) {
+		return
+	}
+	resp, err := client.Get(server.URL)
+	if assert.NoError(t, err) {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "trusted", string(body))
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	os.WriteFile(empty, nil, 0o600)
+	_, err = New(Config{CAFile: empty})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	_, err = New(Config{CAFile: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestConfig_Env(t *testing.T) {
+	assert.Empty(t, Config{}.Env())
+	assert.Equal(t, []string{"HTTPS_PROXY=http://proxy:3128", "HTTP_PROXY=http://proxy:3128", "SSL_CERT_FILE=/etc/ca.pem"}, Config{Proxy: "http://proxy:3128", CAFile: "/etc/ca.pem"}.Env())
+}
+
+func TestNew_RetryAfter(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	client, _ := New(Config{Retries: 1})
+	start := time.Now()
+	resp, err := client.Get(server.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	}
+}
+
+func TestNew_Rate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client, _ := New(Config{Rate: 50})
+	start := time.Now()
+	for range 4 {
+		resp, err := client.Get(server.URL)
+		if !assert.NoError(t, err) {
+			return
+		}
+		resp.Body.Close()
+	}
+	// The first request starts at once and the others 20ms apart
+	assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+}
+
+func TestBackoff(t *testing.T) {
+	defer func(delay, maxDelay time.Duration) { RetryDelay, MaxRetryDelay = delay, maxDelay }(RetryDelay, MaxRetryDelay)
+	RetryDelay, MaxRetryDelay = 100*time.Millisecond, time.Second
+	for attempt := range 8 {
+		delay := backoff(attempt)
+		limit := min(RetryDelay<<attempt, MaxRetryDelay)
+		assert.GreaterOrEqual(t, delay, limit/2)
+		assert.LessOrEqual(t, delay, limit)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	header := func(value string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": {value}}}
+	}
+	delay, ok := retryAfter(header("5"), now)
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, delay)
+	delay, ok = retryAfter(header(now.Add(10*time.Second).Format(http.TimeFormat)), now)
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Second, delay)
+	delay, _ = retryAfter(header("86400"), now)
+	assert.Equal(t, MaxRetryDelay, delay)
+	_, ok = retryAfter(header("soon"), now)
+	assert.False(t, ok)
+	_, ok = retryAfter(&http.Response{Header: http.Header{}}, now)
+	assert.False(t, ok)
+}
+
+func TestNew_Offline(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	_, err := Offline.Get(server.URL)
+	assert.ErrorIs(t, err, ErrOffline)
+	_, err = Offline.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	assert.ErrorIs(t, err, ErrOffline)
+	assert.Zero(t, requests.Load())
+}
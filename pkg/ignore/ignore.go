@@ -0,0 +1,294 @@
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// File is the name of the ignore file looked up in scanned projects
+const File = ".deplister-ignore"
+
+// Rule is the rule name of findings about expired ignore entries
+const Rule = "ignore"
+
+// VulnerabilityRule is the rule name given to ignored vulnerabilities
+const VulnerabilityRule = "vulnerability"
+
+// Common errors
+var (
+	ErrInvalidIgnoreFile = errors.New("invalid ignore file")
+)
+
+// now is replaced by tests
+var now = time.Now
+
+// Entry accepts the findings or vulnerabilities of a package as known risks
+type Entry struct {
+	Package       string    // Package name glob, optionally followed by "@version"
+	Advisory      string    // Vulnerability ID or alias the entry covers, if any
+	Rule          string    // Rule glob of the findings the entry covers, if any
+	Expires       time.Time // Last day the entry applies, zero for never
+	Justification string    // Why the risk was accepted
+	Line          int       // Line number in the ignore file
+
+	name    *regexp.Regexp
+	version *regexp.Regexp
+	rule    *regexp.Regexp
+}
+
+// List is the set of entries of an ignore file
+type List struct {
+	Entries []Entry
+}
+
+// Load reads an ignore file
+func Load(path string) (*List, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse reads ignore entries, one per line. Blank lines and lines starting
+// with "#" are ignored. Entries have the form
+//
+//	<package>[@version] [advisory=<id>] [rule=<rule>] [expires=<YYYY-MM-DD>] [-- justification]
+//
+// Package names, versions and rules may contain "*" wildcards, and values
+// containing spaces may be double quoted. An entry with an advisory covers
+// that vulnerability, one with a rule covers the findings of that rule, and
+// one with neither covers every finding and vulnerability of the package.
+func Parse(r io.Reader) (*List, error) {
+	list := &List{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		entry, err := parseEntry(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidIgnoreFile, line, err)
+		}
+		entry.Line = line
+		list.Entries = append(list.Entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func parseEntry(text string) (Entry, error) {
+	var entry Entry
+	text, justification, _ := strings.Cut(text, " -- ")
+	entry.Justification = strings.TrimSpace(justification)
+
+	fields, err := split(text)
+	if err != nil {
+		return entry, err
+	}
+	if len(fields) == 0 {
+		return entry, fmt.Errorf("missing package")
+	}
+
+	entry.Package = fields[0]
+	name, version := entry.Package, ""
+	if at := strings.LastIndex(name, "@"); at > 0 {
+		name, version = name[:at], name[at+1:]
+	}
+	entry.name = glob(name)
+	if version != "" {
+		entry.version = glob(version)
+	}
+
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return entry, fmt.Errorf("expected key=value, got %q", field)
+		}
+		switch key {
+		case "advisory":
+			entry.Advisory = value
+		case "rule":
+			entry.Rule = value
+			entry.rule = glob(value)
+		case "expires":
+			expires, err := time.Parse(time.DateOnly, value)
+			if err != nil {
+				return entry, fmt.Errorf("invalid expiry date %q", value)
+			}
+			entry.Expires = expires
+		default:
+			return entry, fmt.Errorf("unknown key %q", key)
+		}
+	}
+
+	if entry.Advisory != "" && entry.Rule != "" {
+		return entry, fmt.Errorf("an entry covers either an advisory or a rule, not both")
+	}
+	if name == "*" && entry.Advisory == "" && entry.Rule == "" {
+		return entry, fmt.Errorf("an entry for every package needs an advisory or a rule")
+	}
+	return entry, nil
+}
+
+// split splits a line into whitespace separated fields, keeping double
+// quoted runs together
+func split(text string) ([]string, error) {
+	var fields []string
+	var field strings.Builder
+	quoted, started := false, false
+	for _, r := range text {
+		switch {
+		case r == '"':
+			quoted, started = !quoted, true
+		case !quoted && (r == ' ' || r == '\t'):
+			if started {
+				fields = append(fields, field.String())
+				field.Reset()
+				started = false
+			}
+		default:
+			field.WriteRune(r)
+			started = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if started {
+		fields = append(fields, field.String())
+	}
+	return fields, nil
+}
+
+// glob compiles a pattern in which "*" matches any sequence of characters
+func glob(pattern string) *regexp.Regexp {
+	return regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$")
+}
+
+// expired reports whether the entry no longer applies on the given day
+func (e Entry) expired(today time.Time) bool {
+	return !e.Expires.IsZero() && e.Expires.Before(today)
+}
+
+// covers reports whether the entry applies to a dependency
+func (e Entry) covers(name, version string) bool {
+	return e.name.MatchString(name) && (e.version == nil || e.version.MatchString(version))
+}
+
+// coversFinding reports whether the entry accepts a finding
+func (e Entry) coversFinding(finding scanners.Finding) bool {
+	if e.Advisory != "" || !e.covers(finding.Dependency, finding.Version) {
+		return false
+	}
+	return e.rule == nil || e.rule.MatchString(finding.Rule)
+}
+
+// coversVulnerability reports whether the entry accepts a vulnerability
+func (e Entry) coversVulnerability(dep scanners.Dependency, vuln scanners.Vulnerability) bool {
+	if e.Rule != "" || !e.covers(dep.Name, dep.Version) {
+		return false
+	}
+	if e.Advisory == "" || strings.EqualFold(e.Advisory, vuln.ID) {
+		return true
+	}
+	for _, alias := range vuln.Aliases {
+		if strings.EqualFold(e.Advisory, alias) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply moves the findings and vulnerabilities covered by unexpired entries
+// to the result's Ignored section, and reports expired entries as warnings so
+// they get revisited. It returns the number of findings and vulnerabilities
+// ignored.
+func (l *List) Apply(result *scanners.ScanResult) int {
+	today := now().UTC().Truncate(24 * time.Hour)
+	var active []Entry
+	for _, entry := range l.Entries {
+		if !entry.expired(today) {
+			active = append(active, entry)
+			continue
+		}
+		result.Findings = append(result.Findings, scanners.Finding{
+			Rule:     Rule,
+			Severity: scanners.SeverityWarning,
+			Message:  fmt.Sprintf("ignore entry for %s on line %d expired on %s", entry.Package, entry.Line, entry.Expires.Format(time.DateOnly)),
+		})
+	}
+
+	ignored := 0
+	var findings []scanners.Finding
+	for _, finding := range result.Findings {
+		if entry, ok := find(active, func(e Entry) bool { return e.coversFinding(finding) }); ok {
+			result.Ignored = append(result.Ignored, entry.ignored(finding, ""))
+			ignored++
+			continue
+		}
+		findings = append(findings, finding)
+	}
+	result.Findings = findings
+
+	for i := range result.Dependencies {
+		dep := &result.Dependencies[i]
+		var kept []scanners.Vulnerability
+		for _, vuln := range dep.Vulnerabilities {
+			entry, ok := find(active, func(e Entry) bool { return e.coversVulnerability(*dep, vuln) })
+			if !ok {
+				kept = append(kept, vuln)
+				continue
+			}
+			result.Ignored = append(result.Ignored, entry.ignored(scanners.Finding{
+				Rule:       VulnerabilityRule,
+				Severity:   strings.ToLower(vuln.Severity),
+				Dependency: dep.Name,
+				Version:    dep.Version,
+				Message:    strings.TrimSpace(vuln.ID + " " + vuln.Summary),
+			}, vuln.ID))
+			ignored++
+		}
+		if len(kept) == len(dep.Vulnerabilities) {
+			continue
+		}
+		dep.Vulnerabilities = kept
+	}
+	result.SyncGraph()
+	return ignored
+}
+
+// find returns the first entry matching a predicate
+func find(entries []Entry, match func(Entry) bool) (Entry, bool) {
+	for _, entry := range entries {
+		if match(entry) {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+func (e Entry) ignored(finding scanners.Finding, advisory string) scanners.IgnoredFinding {
+	ignored := scanners.IgnoredFinding{
+		Finding:       finding,
+		Advisory:      advisory,
+		Justification: e.Justification,
+	}
+	if !e.Expires.IsZero() {
+		ignored.Expires = e.Expires.Format(time.DateOnly)
+	}
+	return ignored
+}
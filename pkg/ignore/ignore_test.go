@@ -0,0 +1,110 @@
+package ignore
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func TestParse(t *testing.T) {
+	list, err := Parse(strings.NewReader(`
+# Accepted risks
+lodash advisory=GHSA-p6mc-m468-83gw expires=2024-12-31 -- only used by build scripts
+@acme/* rule=typosquat
+left-pad@1.3.0
+* rule="deny license GPL-*" -- reviewed by legal
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, list.Entries, 4) {
+		return
+	}
+
+	assert.Equal(t, "lodash", list.Entries[0].Package)
+	assert.Equal(t, "GHSA-p6mc-m468-83gw", list.Entries[0].Advisory)
+	assert.Equal(t, time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), list.Entries[0].Expires)
+	assert.Equal(t, "only used by build scripts", list.Entries[0].Justification)
+	assert.Equal(t, 3, list.Entries[0].Line)
+
+	assert.True(t, list.Entries[1].covers("@acme/utils", "1.0.0"))
+	assert.Equal(t, "typosquat", list.Entries[1].Rule)
+	assert.True(t, list.Entries[2].covers("left-pad", "1.3.0"))
+	assert.False(t, list.Entries[2].covers("left-pad", "1.2.0"))
+	assert.Equal(t, "deny license GPL-*", list.Entries[3].Rule)
+	assert.Equal(t, "reviewed by legal", list.Entries[3].Justification)
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, text := range []string{
+		"lodash expires=tomorrow",
+		"lodash severity=high",
+		"lodash advisory",
+		"lodash advisory=GHSA-1 rule=typosquat",
+		"*",
+		`lodash rule="deny`,
+	} {
+		_, err := Parse(strings.NewReader(text))
+		assert.True(t, errors.Is(err, ErrInvalidIgnoreFile), text)
+	}
+}
+
+func TestApply(t *testing.T) {
+	now = func() time.Time { return time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	list, err := Parse(strings.NewReader(`
+lodash advisory=CVE-2021-23337 expires=2024-06-01 -- not reachable
+minimist advisory=GHSA-xvch-5gv4-984h expires=2024-01-01
+expresss rule=typosquat -- internal fork
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	lodash := scanners.Dependency{Name: "lodash", Version: "4.17.20", Vulnerabilities: []scanners.Vulnerability{
+		{ID: "GHSA-35jh-r3h4-6jhm", Aliases: []string{"CVE-2021-23337"}, Summary: "Command Injection", Severity: "HIGH"},
+		{ID: "GHSA-29mw-wpgm-hmr9", Severity: "MEDIUM"},
+	}}
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			lodash,
+			{Name: "minimist", Version: "1.2.5", Vulnerabilities: []scanners.Vulnerability{{ID: "GHSA-xvch-5gv4-984h"}}},
+		},
+		Graph: &scanners.DependencyGraph{Nodes: map[string]*scanners.Dependency{"lodash@4.17.20": &lodash}},
+		Findings: []scanners.Finding{
+			{Rule: "typosquat", Severity: scanners.SeverityWarning, Dependency: "expresss", Version: "1.0.0", Message: "expresss resembles express"},
+			{Rule: "deny accepts", Severity: scanners.SeverityError, Dependency: "accepts", Version: "1.3.7", Message: "accepts@1.3.7 is denied by policy"},
+		},
+	}
+
+	assert.Equal(t, 2, list.Apply(result))
+
+	assert.Equal(t, []scanners.Vulnerability{{ID: "GHSA-29mw-wpgm-hmr9", Severity: "MEDIUM"}}, result.Dependencies[0].Vulnerabilities)
+	assert.Len(t, result.Graph.Nodes["lodash@4.17.20"].Vulnerabilities, 1)
+	// The minimist entry has expired, so its vulnerability stays
+	assert.Len(t, result.Dependencies[1].Vulnerabilities, 1)
+
+	assert.Equal(t, []scanners.Finding{
+		{Rule: "deny accepts", Severity: scanners.SeverityError, Dependency: "accepts", Version: "1.3.7", Message: "accepts@1.3.7 is denied by policy"},
+		{Rule: Rule, Severity: scanners.SeverityWarning, Message: "ignore entry for minimist on line 3 expired on 2024-01-01"},
+	}, result.Findings)
+
+	assert.Equal(t, []scanners.IgnoredFinding{
+		{
+			Finding:       scanners.Finding{Rule: "typosquat", Severity: scanners.SeverityWarning, Dependency: "expresss", Version: "1.0.0", Message: "expresss resembles express"},
+			Justification: "internal fork",
+		},
+		{
+			Finding:       scanners.Finding{Rule: VulnerabilityRule, Severity: "high", Dependency: "lodash", Version: "4.17.20", Message: "GHSA-35jh-r3h4-6jhm Command Injection"},
+			Advisory:      "GHSA-35jh-r3h4-6jhm",
+			Justification: "not reachable",
+			Expires:       "2024-06-01",
+		},
+	}, result.Ignored)
+}
@@ -0,0 +1,469 @@
+package impact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/outdated"
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/version"
+)
+
+// Common errors
+var (
+	ErrNotFound       = errors.New("package not found in the dependency graph")
+	ErrUnknownVersion = errors.New("version not found in the registry")
+	ErrUnsupported    = errors.New("upgrades can only be simulated for npm and Go dependencies")
+)
+
+// Source provides npm registry metadata and Go module requirements
+type Source interface {
+	registry.Source
+	Requirements(ctx context.Context, path, version string) (map[string]string, error)
+}
+
+// Impact is the difference between the current dependency graph and a
+// hypothetical one
+type Impact struct {
+	Removed   []string   // Node keys no longer in the graph
+	Added     []string   // Node keys new to the graph
+	Changed   []Change   // Packages resolved to other versions
+	Conflicts []Conflict // Requirements the hypothetical graph does not satisfy
+}
+
+// Change is a package whose resolved versions change
+type Change struct {
+	Name string
+	From []string
+	To   []string
+}
+
+// Conflict is a requirement left unsatisfied
+type Conflict struct {
+	Dependent string // Node key of the requiring package, or "" for the project
+	Name      string // Required package
+	Requires  string // Required version range, "" when the package is required at all
+	Version   string // Version the package would resolve to, "" when removed or unresolvable
+}
+
+// String describes the conflict
+func (c Conflict) String() string {
+	dependent := c.Dependent
+	if dependent == "" {
+		dependent = "the project"
+	}
+	switch {
+	case c.Version == "" && c.Requires == "":
+		return fmt.Sprintf("%s requires %s, which would be removed", dependent, c.Name)
+	case c.Version == "":
+		return fmt.Sprintf("%s requires %s@%s, which no published version satisfies", dependent, c.Name, c.Requires)
+	}
+	return fmt.Sprintf("%s requires %s@%s, but %s would be installed", dependent, c.Name, c.Requires, c.Version)
+}
+
+// simulation is a copy of a graph being modified
+type simulation struct {
+	graph    *scanners.DependencyGraph
+	roots    []string
+	edges    map[string][]string
+	names    map[string]string   // Package name of every node key
+	versions map[string][]string // Node keys of every package name
+	impact   Impact
+}
+
+func newSimulation(graph *scanners.DependencyGraph) *simulation {
+	s := &simulation{
+		graph:    graph,
+		roots:    graph.Roots(),
+		edges:    make(map[string][]string, len(graph.Edges)),
+		names:    make(map[string]string),
+		versions: make(map[string][]string),
+	}
+	for parent, children := range graph.Edges {
+		s.edges[parent] = slices.Clone(children)
+	}
+	for key, node := range graph.Nodes {
+		s.add(key, node.Name)
+	}
+	return s
+}
+
+// add records a node key of a package
+func (s *simulation) add(key, name string) {
+	s.names[key] = name
+	if !slices.Contains(s.versions[name], key) {
+		s.versions[name] = append(s.versions[name], key)
+	}
+}
+
+// replace points every edge to from at to instead, and drops the edges of from
+func (s *simulation) replace(from, to string) {
+	for parent, children := range s.edges {
+		if !slices.Contains(children, from) {
+			continue
+		}
+		children = slices.DeleteFunc(children, func(child string) bool { return child == from })
+		if !slices.Contains(children, to) {
+			children = append(children, to)
+		}
+		s.edges[parent] = children
+	}
+	delete(s.edges, from)
+	s.versions[s.names[from]] = slices.DeleteFunc(s.versions[s.names[from]], func(key string) bool { return key == from })
+}
+
+// parents returns the keys with an edge to key in the original graph
+func (s *simulation) parents(key string) []string {
+	var parents []string
+	for parent, children := range s.graph.Edges {
+		if slices.Contains(children, key) {
+			parents = append(parents, parent)
+		}
+	}
+	sort.Strings(parents)
+	return parents
+}
+
+// matching returns the node keys an argument refers to, either a node key or
+// a package name matching every version
+func matching(graph *scanners.DependencyGraph, arg string) []string {
+	if _, ok := graph.Nodes[arg]; ok {
+		return []string{arg}
+	}
+	var keys []string
+	for key, node := range graph.Nodes {
+		if node.Name == arg {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Remove simulates removing a package, given as a name or name@version, and
+// every dependency only it requires
+func Remove(graph *scanners.DependencyGraph, arg string) (Impact, error) {
+	keys := matching(graph, arg)
+	if len(keys) == 0 {
+		return Impact{}, fmt.Errorf("%w: %s", ErrNotFound, arg)
+	}
+
+	s := newSimulation(graph)
+	for _, key := range keys {
+		for parent, children := range s.edges {
+			s.edges[parent] = slices.DeleteFunc(children, func(child string) bool { return child == key })
+		}
+		delete(s.edges, key)
+	}
+
+	reachable := s.reachable()
+	for _, key := range keys {
+		for _, parent := range s.parents(key) {
+			if slices.Contains(s.roots, parent) || !reachable[parent] {
+				continue
+			}
+			s.impact.Conflicts = append(s.impact.Conflicts, Conflict{Dependent: parent, Name: s.names[key]})
+		}
+	}
+	return s.finish(), nil
+}
+
+// Upgrade simulates resolving every version of a package to another
+// version, resolving the dependencies of new versions through the source as
+// npm or the go command would
+func Upgrade(ctx context.Context, source Source, graph *scanners.DependencyGraph, name, v string) (Impact, error) {
+	keys := matching(graph, name)
+	if len(keys) == 0 {
+		return Impact{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	depType := graph.Nodes[keys[0]].Type
+	if depType != "npm" && depType != "go" {
+		return Impact{}, ErrUnsupported
+	}
+
+	s := newSimulation(graph)
+	target := scanners.NodeKey(name, v)
+
+	// Dependents whose range excludes the new version keep needing the old
+	// one. Go requirements are minimums, so they never conflict.
+	for _, key := range keys {
+		if depType != "npm" {
+			break
+		}
+		for _, parent := range s.parents(key) {
+			requires, err := s.requiredRange(ctx, source, parent, name)
+			if err != nil {
+				return Impact{}, err
+			}
+			constraint, err := version.ParseConstraint(requires)
+			if requires == "" || err != nil || constraint.Check(v) {
+				continue
+			}
+			dependent := parent
+			if slices.Contains(s.roots, parent) {
+				dependent = ""
+			}
+			s.impact.Conflicts = append(s.impact.Conflicts, Conflict{Dependent: dependent, Name: name, Requires: requires, Version: v})
+		}
+	}
+
+	s.add(target, name)
+	for _, key := range keys {
+		if key != target {
+			s.replace(key, target)
+		}
+	}
+	delete(s.edges, target)
+
+	queue := []string{target}
+	resolved := map[string]bool{target: true}
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+
+		added, err := s.resolve(ctx, source, depType, key, key == target)
+		if err != nil {
+			return Impact{}, err
+		}
+		for _, child := range added {
+			if !resolved[child] {
+				resolved[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	return s.finish(), nil
+}
+
+// requiredRange returns the range in which parent requires an npm package:
+// the package.json specifier for the project, or the registry metadata of
+// the parent
+func (s *simulation) requiredRange(ctx context.Context, source Source, parent, name string) (string, error) {
+	if slices.Contains(s.roots, parent) {
+		for _, key := range s.versions[name] {
+			if node, ok := s.graph.Nodes[key]; ok && node.IsDirectDep {
+				return node.Properties["specifier"], nil
+			}
+		}
+		return "", nil
+	}
+
+	node, ok := s.graph.Nodes[parent]
+	if !ok || node.Type != "npm" {
+		return "", nil
+	}
+	pkg, err := source.Package(ctx, "npm", node.Name)
+	if errors.Is(err, registry.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return pkg.Dependencies[node.Version][name], nil
+}
+
+// resolve sets the edges of a new node from its registry metadata and
+// returns the node keys it adds or changes, whose dependencies need resolving
+// in turn
+func (s *simulation) resolve(ctx context.Context, source Source, depType, key string, target bool) ([]string, error) {
+	name := s.names[key]
+	v := versionOf(key, name)
+
+	var requirements map[string]string
+	switch depType {
+	case "npm":
+		pkg, err := source.Package(ctx, "npm", name)
+		if errors.Is(err, registry.ErrNotFound) && !target {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(pkg.Versions, v) {
+			if target {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownVersion, key)
+			}
+			return nil, nil
+		}
+		requirements = pkg.Dependencies[v]
+	case "go":
+		var err error
+		requirements, err = source.Requirements(ctx, name, v)
+		if errors.Is(err, registry.ErrNotFound) {
+			if target {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownVersion, key)
+			}
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var pending []string
+	for _, dep := range sortedKeys(requirements) {
+		requires := requirements[dep]
+		var child string
+		var err error
+		if depType == "npm" {
+			child, err = s.resolveNPM(ctx, source, key, dep, requires)
+		} else {
+			child = s.resolveGo(dep, requires)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if child == "" {
+			continue
+		}
+		if !slices.Contains(s.edges[key], child) {
+			s.edges[key] = append(s.edges[key], child)
+		}
+		if _, ok := s.graph.Nodes[child]; !ok {
+			pending = append(pending, child)
+		}
+	}
+	return pending, nil
+}
+
+// resolveNPM returns the node key satisfying a range, preferring versions
+// already installed as npm deduplicates them
+func (s *simulation) resolveNPM(ctx context.Context, source Source, dependent, name, requires string) (string, error) {
+	constraint, err := version.ParseConstraint(requires)
+	if err != nil {
+		// Aliases, git and file specifiers cannot be resolved from the registry
+		return "", nil
+	}
+	var best string
+	for _, key := range s.versions[name] {
+		if v := versionOf(key, name); constraint.Check(v) && (best == "" || version.Compare(v, versionOf(best, name)) > 0) {
+			best = key
+		}
+	}
+	if best != "" {
+		return best, nil
+	}
+
+	pkg, err := source.Package(ctx, "npm", name)
+	if errors.Is(err, registry.ErrNotFound) {
+		s.impact.Conflicts = append(s.impact.Conflicts, Conflict{Dependent: dependent, Name: name, Requires: requires})
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	wanted := outdated.Wanted(pkg, requires)
+	if wanted == "" {
+		s.impact.Conflicts = append(s.impact.Conflicts, Conflict{Dependent: dependent, Name: name, Requires: requires})
+		return "", nil
+	}
+	key := scanners.NodeKey(name, wanted)
+	s.add(key, name)
+	return key, nil
+}
+
+// resolveGo applies minimal version selection: the highest required version
+// of a module wins
+func (s *simulation) resolveGo(name, required string) string {
+	key := scanners.NodeKey(name, required)
+	current := s.versions[name]
+	if len(current) == 0 {
+		s.add(key, name)
+		return key
+	}
+	selected := current[0]
+	if version.Compare(required, versionOf(selected, name)) <= 0 {
+		return selected
+	}
+	s.add(key, name)
+	s.replace(selected, key)
+	return key
+}
+
+// reachable returns the keys reachable from the project roots
+func (s *simulation) reachable() map[string]bool {
+	return reachable(s.edges, s.roots)
+}
+
+func reachable(edges map[string][]string, roots []string) map[string]bool {
+	seen := make(map[string]bool)
+	queue := slices.Clone(roots)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range edges[current] {
+			if !seen[child] {
+				seen[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	return seen
+}
+
+// finish compares the reachable nodes before and after the simulation
+func (s *simulation) finish() Impact {
+	before := reachable(s.graph.Edges, s.roots)
+	after := s.reachable()
+
+	from := make(map[string][]string)
+	to := make(map[string][]string)
+	for key := range before {
+		from[s.names[key]] = append(from[s.names[key]], key)
+	}
+	for key := range after {
+		to[s.names[key]] = append(to[s.names[key]], key)
+	}
+
+	for _, name := range sortedKeys(from) {
+		if len(to[name]) == 0 {
+			continue
+		}
+		fromVersions, toVersions := versionsOf(name, from[name]), versionsOf(name, to[name])
+		if !slices.Equal(fromVersions, toVersions) {
+			s.impact.Changed = append(s.impact.Changed, Change{Name: name, From: fromVersions, To: toVersions})
+		}
+	}
+	for key := range before {
+		if !after[key] && len(to[s.names[key]]) == 0 {
+			s.impact.Removed = append(s.impact.Removed, key)
+		}
+	}
+	for key := range after {
+		if !before[key] && len(from[s.names[key]]) == 0 {
+			s.impact.Added = append(s.impact.Added, key)
+		}
+	}
+	sort.Strings(s.impact.Removed)
+	sort.Strings(s.impact.Added)
+	return s.impact
+}
+
+// versionsOf returns the sorted versions of a package's node keys
+func versionsOf(name string, keys []string) []string {
+	var versions []string
+	for _, key := range keys {
+		versions = append(versions, versionOf(key, name))
+	}
+	slices.SortFunc(versions, version.Compare)
+	return versions
+}
+
+// versionOf returns the version part of a package's node key
+func versionOf(key, name string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(key, name), "@")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,163 @@
+package impact
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+type fakeSource struct {
+	packages     map[string]*registry.Package
+	requirements map[string]map[string]string
+}
+
+func (s fakeSource) Package(ctx context.Context, depType, name string) (*registry.Package, error) {
+	if pkg, ok := s.packages[name]; ok {
+		return pkg, nil
+	}
+	return nil, registry.ErrNotFound
+}
+
+func (s fakeSource) Requirements(ctx context.Context, path, version string) (map[string]string, error) {
+	if requirements, ok := s.requirements[path+"@"+version]; ok {
+		return requirements, nil
+	}
+	return nil, registry.ErrNotFound
+}
+
+func npmGraph() *scanners.DependencyGraph {
+	node := func(name, version string, direct bool, specifier string) *scanners.Dependency {
+		dep := &scanners.Dependency{Name: name, Version: version, Type: "npm", IsDirectDep: direct}
+		if specifier != "" {
+			dep.Properties = map[string]string{"specifier": specifier}
+		}
+		return dep
+	}
+	return &scanners.DependencyGraph{
+		Nodes: map[string]*scanners.Dependency{
+			"express@4.18.2": node("express", "4.18.2", true, "^4.18.0"),
+			"debug@2.6.9":    node("debug", "2.6.9", false, ""),
+			"ms@2.0.0":       node("ms", "2.0.0", false, ""),
+			"cookie@0.5.0":   node("cookie", "0.5.0", false, ""),
+			"morgan@1.10.0":  node("morgan", "1.10.0", true, "^1.10.0"),
+		},
+		Edges: map[string][]string{
+			"":               {"express@4.18.2", "morgan@1.10.0"},
+			"express@4.18.2": {"debug@2.6.9", "cookie@0.5.0"},
+			"morgan@1.10.0":  {"debug@2.6.9"},
+			"debug@2.6.9":    {"ms@2.0.0"},
+		},
+	}
+}
+
+func TestRemove(t *testing.T) {
+	impact, err := Remove(npmGraph(), "express")
+	if !assert.NoError(t, err) {
+		return
+	}
+	// debug stays installed for morgan
+	assert.Equal(t, []string{"cookie@0.5.0", "express@4.18.2"}, impact.Removed)
+	assert.Empty(t, impact.Added)
+	assert.Empty(t, impact.Changed)
+	assert.Empty(t, impact.Conflicts)
+
+	impact, err = Remove(npmGraph(), "debug@2.6.9")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"debug@2.6.9", "ms@2.0.0"}, impact.Removed)
+	assert.Equal(t, []Conflict{
+		{Dependent: "express@4.18.2", Name: "debug"},
+		{Dependent: "morgan@1.10.0", Name: "debug"},
+	}, impact.Conflicts)
+	assert.Equal(t, "express@4.18.2 requires debug, which would be removed", impact.Conflicts[0].String())
+
+	_, err = Remove(npmGraph(), "lodash")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUpgrade_NPM(t *testing.T) {
+	source := fakeSource{packages: map[string]*registry.Package{
+		"express": {
+			Versions: []string{"4.18.2", "5.0.0"},
+			Dependencies: map[string]map[string]string{
+				"5.0.0": {"debug": "^4.3.0", "cookie": "^0.5.0", "router": "^2.0.0"},
+			},
+		},
+		"debug": {
+			Versions:     []string{"2.6.9", "4.3.4"},
+			Dependencies: map[string]map[string]string{"4.3.4": {"ms": "2.1.2"}},
+		},
+		"ms":     {Versions: []string{"2.0.0", "2.1.2"}},
+		"router": {Versions: []string{"2.0.0", "2.1.0"}},
+		"morgan": {
+			Versions:     []string{"1.10.0"},
+			Dependencies: map[string]map[string]string{"1.10.0": {"debug": "2.6.9"}},
+		},
+	}}
+
+	impact, err := Upgrade(context.Background(), source, npmGraph(), "express", "5.0.0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"router@2.1.0"}, impact.Added)
+	assert.Empty(t, impact.Removed)
+	assert.Equal(t, []Change{
+		{Name: "debug", From: []string{"2.6.9"}, To: []string{"2.6.9", "4.3.4"}},
+		{Name: "express", From: []string{"4.18.2"}, To: []string{"5.0.0"}},
+		{Name: "ms", From: []string{"2.0.0"}, To: []string{"2.0.0", "2.1.2"}},
+	}, impact.Changed)
+	assert.Equal(t, []Conflict{{Name: "express", Requires: "^4.18.0", Version: "5.0.0"}}, impact.Conflicts)
+	assert.Equal(t, "the project requires express@^4.18.0, but 5.0.0 would be installed", impact.Conflicts[0].String())
+
+	// morgan pins the version of debug it requires
+	impact, err = Upgrade(context.Background(), source, npmGraph(), "debug", "4.3.4")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []Conflict{{Dependent: "morgan@1.10.0", Name: "debug", Requires: "2.6.9", Version: "4.3.4"}}, impact.Conflicts)
+
+	_, err = Upgrade(context.Background(), source, npmGraph(), "express", "6.0.0")
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+}
+
+func TestUpgrade_Go(t *testing.T) {
+	graph := &scanners.DependencyGraph{
+		Nodes: map[string]*scanners.Dependency{
+			"example.com/a@v1.0.0": {Name: "example.com/a", Version: "v1.0.0", Type: "go", IsDirectDep: true},
+			"example.com/b@v1.1.0": {Name: "example.com/b", Version: "v1.1.0", Type: "go", IsDirectDep: true},
+			"example.com/c@v1.0.0": {Name: "example.com/c", Version: "v1.0.0", Type: "go"},
+			"example.com/d@v1.0.0": {Name: "example.com/d", Version: "v1.0.0", Type: "go"},
+		},
+		Edges: map[string][]string{
+			"example.com/app":      {"example.com/a@v1.0.0", "example.com/b@v1.1.0"},
+			"example.com/a@v1.0.0": {"example.com/c@v1.0.0", "example.com/d@v1.0.0"},
+			"example.com/b@v1.1.0": {"example.com/c@v1.0.0"},
+		},
+	}
+	source := fakeSource{requirements: map[string]map[string]string{
+		"example.com/a@v1.2.0": {"example.com/c": "v1.3.0", "example.com/e": "v0.1.0"},
+		"example.com/c@v1.3.0": {"example.com/b": "v1.0.0"},
+		"example.com/e@v0.1.0": {},
+	}}
+
+	impact, err := Upgrade(context.Background(), source, graph, "example.com/a", "v1.2.0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	// c is selected at the highest required version, b keeps its own
+	assert.Equal(t, []string{"example.com/e@v0.1.0"}, impact.Added)
+	assert.Equal(t, []string{"example.com/d@v1.0.0"}, impact.Removed)
+	assert.Equal(t, []Change{
+		{Name: "example.com/a", From: []string{"v1.0.0"}, To: []string{"v1.2.0"}},
+		{Name: "example.com/c", From: []string{"v1.0.0"}, To: []string{"v1.3.0"}},
+	}, impact.Changed)
+	assert.Empty(t, impact.Conflicts)
+
+	_, err = Upgrade(context.Background(), source, graph, "example.com/a", "v9.0.0")
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+}
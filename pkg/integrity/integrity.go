@@ -0,0 +1,192 @@
+package integrity
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Enrichment is the name of the integrity verification in ScanOptions.Enrich
+const Enrichment = "verify"
+
+// Rule is the rule name of integrity mismatch findings
+const Rule = "integrity"
+
+// concurrency is the number of Go modules looked up in parallel
+const concurrency = 8
+
+// ChecksumDB looks up Go module checksums in a checksum database
+type ChecksumDB interface {
+	Checksum(path, version string) (string, error)
+}
+
+// Enrich cross-checks the integrity data recorded by the project against
+// the upstream registries: the "integrity" of npm packages from the lockfile
+// against the registry's published integrity, and the go.sum hashes of Go
+// modules ("checksum" and "checksum.gomod") against the checksum database.
+// Every mismatch is reported as a critical finding. Packages missing from
+// their registry and modules the checksum database does not cover are
+// skipped.
+func Enrich(ctx context.Context, source registry.Source, sums ChecksumDB, result *scanners.ScanResult) error {
+	var npm, golang []scanners.Dependency
+	for _, dep := range result.Dependencies {
+		switch {
+		case dep.Type == "npm" && dep.Properties["integrity"] != "":
+			npm = append(npm, dep)
+		case dep.Type == "go":
+			golang = append(golang, dep)
+		}
+	}
+
+	packages, err := registry.Lookup(ctx, source, npm)
+	if err != nil {
+		return err
+	}
+
+	var findings []scanners.Finding
+	for _, dep := range npm {
+		pkg, ok := packages[registry.Key{Type: dep.Type, Name: dep.Name}]
+		if !ok {
+			continue
+		}
+		dist, ok := pkg.Dist[dep.Version]
+		if !ok {
+			continue
+		}
+		local := dep.Properties["integrity"]
+		if !matches(local, dist) {
+			remote := dist.Integrity
+			if remote == "" {
+				remote = "sha1 " + dist.Shasum
+			}
+			findings = append(findings, finding(dep, fmt.Sprintf("integrity of %s@%s in the lockfile (%s) does not match the registry (%s)", dep.Name, dep.Version, local, remote)))
+		}
+	}
+
+	goFindings, err := verifyModules(golang, sums)
+	if err != nil {
+		return err
+	}
+
+	result.Findings = append(result.Findings, findings...)
+	result.Findings = append(result.Findings, goFindings...)
+	return nil
+}
+
+func finding(dep scanners.Dependency, message string) scanners.Finding {
+	return scanners.Finding{
+		Rule:       Rule,
+		Severity:   scanners.SeverityCritical,
+		Dependency: dep.Name,
+		Version:    dep.Version,
+		Message:    message,
+	}
+}
+
+// matches reports whether a subresource integrity string agrees with the
+// registry's distribution metadata on every hash algorithm both know.
+// Integrity that cannot be compared, for lack of a common algorithm, matches.
+func matches(integrity string, dist registry.Dist) bool {
+	remote := parse(dist.Integrity)
+	if sum, err := hex.DecodeString(dist.Shasum); err == nil && len(sum) > 0 {
+		if _, ok := remote["sha1"]; !ok {
+			remote["sha1"] = string(sum)
+		}
+	}
+
+	for algorithm, digest := range parse(integrity) {
+		if expected, ok := remote[algorithm]; ok && expected != digest {
+			return false
+		}
+	}
+	return true
+}
+
+// parse decodes the hashes of a subresource integrity string by algorithm
+func parse(integrity string) map[string]string {
+	hashes := make(map[string]string)
+	for _, hash := range strings.Fields(integrity) {
+		algorithm, encoded, ok := strings.Cut(hash, "-")
+		if !ok {
+			continue
+		}
+		// Options such as "sha512-...?foo" are not part of the digest
+		encoded, _, _ = strings.Cut(encoded, "?")
+		if digest, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+			hashes[algorithm] = string(digest)
+		}
+	}
+	return hashes
+}
+
+// verifyModules compares the go.sum hashes of Go modules with the checksum
+// database
+func verifyModules(deps []scanners.Dependency, sums ChecksumDB) ([]scanners.Finding, error) {
+	if sums == nil {
+		return nil, nil
+	}
+
+	findings := make([][]scanners.Finding, len(deps))
+	errs := make([]error, len(deps))
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, concurrency)
+	for i, dep := range deps {
+		wg.Add(1)
+		limit <- struct{}{}
+		go func() {
+			defer func() { <-limit; wg.Done() }()
+			findings[i], errs[i] = verifyModule(dep, sums)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	var all []scanners.Finding
+	for _, list := range findings {
+		all = append(all, list...)
+	}
+	return all, nil
+}
+
+func verifyModule(dep scanners.Dependency, sums ChecksumDB) ([]scanners.Finding, error) {
+	// go.sum holds the checksums of the replacement module
+	path, version := dep.Name, dep.Version
+	if replacement := dep.Properties["replaced_by"]; replacement != "" {
+		path, version = replacement, dep.Properties["replaced_version"]
+	}
+	if version == "" {
+		return nil, nil
+	}
+
+	var findings []scanners.Finding
+	for _, entry := range []struct{ property, version string }{
+		{"checksum", version},
+		{"checksum.gomod", version + "/go.mod"},
+	} {
+		local := dep.Properties[entry.property]
+		if local == "" {
+			continue
+		}
+
+		sum, err := sums.Checksum(path, entry.version)
+		switch {
+		case errors.Is(err, registry.ErrNoSumDB), errors.Is(err, registry.ErrNotFound):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		if sum != local {
+			findings = append(findings, finding(dep, fmt.Sprintf("go.sum has %s for %s %s but the checksum database has %s", local, path, entry.version, sum)))
+		}
+	}
+	return findings, nil
+}
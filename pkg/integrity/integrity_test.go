@@ -0,0 +1,95 @@
+package integrity
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+type fakeSource map[string]*registry.Package
+
+func (s fakeSource) Package(ctx context.Context, depType, name string) (*registry.Package, error) {
+	if pkg, ok := s[name]; ok {
+		return pkg, nil
+	}
+	return nil, registry.ErrNotFound
+}
+
+type fakeChecksumDB map[string]string
+
+func (db fakeChecksumDB) Checksum(path, version string) (string, error) {
+	if path == "example.com/private" {
+		return "", registry.ErrNoSumDB
+	}
+	if sum, ok := db[path+"@"+version]; ok {
+		return sum, nil
+	}
+	return "", registry.ErrNotFound
+}
+
+func sri(content string) string {
+	sum := sha512.Sum512([]byte(content))
+	return "sha512-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestMatches(t *testing.T) {
+	sha1Sum := sha1.Sum([]byte("old"))
+	old := registry.Dist{Shasum: hex.EncodeToString(sha1Sum[:])}
+	oldSRI := "sha1-" + base64.StdEncoding.EncodeToString(sha1Sum[:])
+
+	assert.True(t, matches(sri("a"), registry.Dist{Integrity: sri("a")}))
+	assert.False(t, matches(sri("a"), registry.Dist{Integrity: sri("b")}))
+	assert.True(t, matches(oldSRI, old))
+	assert.False(t, matches(oldSRI, registry.Dist{Shasum: "0000000000000000000000000000000000000000"}))
+	assert.True(t, matches(sri("a")+" "+oldSRI, registry.Dist{Integrity: sri("a"), Shasum: old.Shasum}))
+
+	// Nothing to compare
+	assert.True(t, matches(sri("a"), old))
+	assert.True(t, matches("", registry.Dist{Integrity: sri("a")}))
+}
+
+func TestEnrich(t *testing.T) {
+	source := fakeSource{
+		"intact":   {Dist: map[string]registry.Dist{"1.0.0": {Integrity: sri("intact")}}},
+		"tampered": {Dist: map[string]registry.Dist{"1.0.0": {Integrity: sri("original")}}},
+	}
+	sums := fakeChecksumDB{
+		"example.com/intact@v1.0.0":          "h1:zip=",
+		"example.com/intact@v1.0.0/go.mod":   "h1:mod=",
+		"example.com/tampered@v1.0.0":        "h1:zip=",
+		"example.com/tampered@v1.0.0/go.mod": "h1:mod=",
+		"example.com/fork@v1.1.0":            "h1:fork=",
+	}
+
+	result := &scanners.ScanResult{Dependencies: []scanners.Dependency{
+		{Name: "intact", Version: "1.0.0", Type: "npm", Properties: map[string]string{"integrity": sri("intact")}},
+		{Name: "tampered", Version: "1.0.0", Type: "npm", Properties: map[string]string{"integrity": sri("tampered")}},
+		{Name: "private", Version: "1.0.0", Type: "npm", Properties: map[string]string{"integrity": sri("private")}},
+		{Name: "example.com/intact", Version: "v1.0.0", Type: "go", Properties: map[string]string{"checksum": "h1:zip=", "checksum.gomod": "h1:mod="}},
+		{Name: "example.com/tampered", Version: "v1.0.0", Type: "go", Properties: map[string]string{"checksum": "h1:bad=", "checksum.gomod": "h1:mod="}},
+		{Name: "example.com/private", Version: "v1.0.0", Type: "go", Properties: map[string]string{"checksum": "h1:private="}},
+		{Name: "example.com/original", Version: "v1.0.0", Type: "go", Properties: map[string]string{"checksum": "h1:bad=", "replaced_by": "example.com/fork", "replaced_version": "v1.1.0"}},
+		{Name: "example.com/local", Version: "v1.0.0", Type: "go", Properties: map[string]string{"replaced_by": "../local"}},
+	}}
+
+	if !assert.NoError(t, Enrich(context.Background(), source, sums, result)) {
+		return
+	}
+
+	var got []string
+	for _, finding := range result.Findings {
+		assert.Equal(t, scanners.SeverityCritical, finding.Severity)
+		assert.Equal(t, Rule, finding.Rule)
+		got = append(got, finding.Dependency)
+	}
+	assert.Equal(t, []string{"tampered", "example.com/tampered", "example.com/original"}, got)
+	assert.Equal(t, "go.sum has h1:bad= for example.com/fork v1.1.0 but the checksum database has h1:fork=", result.Findings[2].Message)
+}
@@ -0,0 +1,124 @@
+package license
+
+import (
+	"io/fs"
+	"regexp"
+	"strings"
+)
+
+// files are the names license texts are commonly published under, in order
+// of preference
+var files = []string{
+	"LICENSE", "LICENSE.md", "LICENSE.txt", "LICENSE.rst",
+	"LICENCE", "LICENCE.md", "LICENCE.txt",
+	"COPYING", "COPYING.md", "COPYING.txt",
+	"LICENSE-MIT", "LICENSE-APACHE", "MIT-LICENSE",
+	"license", "license.md", "license.txt",
+}
+
+// rule identifies a license by phrases that must all appear in its
+// normalized text
+type rule struct {
+	id      string
+	phrases []string
+}
+
+// rules are checked in order, so more specific licenses come before the
+// licenses whose phrases they contain. Only the license version can be told
+// from its text, not whether later versions are allowed, so GPL family
+// licenses are reported without an -only or -or-later suffix.
+var rules = []rule{
+	{"AGPL-3.0", []string{"gnu affero general public license", "version 3"}},
+	{"LGPL-3.0", []string{"gnu lesser general public license", "version 3"}},
+	{"LGPL-2.1", []string{"gnu lesser general public license", "version 2.1"}},
+	{"GPL-3.0", []string{"gnu general public license", "version 3"}},
+	{"GPL-2.0", []string{"gnu general public license", "version 2"}},
+	{"MPL-2.0", []string{"mozilla public license", "2.0"}},
+	{"EPL-2.0", []string{"eclipse public license", "v 2.0"}},
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+	{"BSL-1.0", []string{"boost software license", "version 1.0"}},
+	{"Unlicense", []string{"this is free and unencumbered software released into the public domain"}},
+	{"CC0-1.0", []string{"cc0 1.0 universal"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted", "this permission notice appear in all copies"}},
+	{"0BSD", []string{"permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "names of its contributors"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+	{"MIT", []string{"permission is hereby granted, free of charge, to any person obtaining a copy"}},
+	{"Zlib", []string{"this software is provided 'as-is', without any express or implied warranty", "altered source versions must be plainly marked"}},
+}
+
+var (
+	space   = regexp.MustCompile(`\s+`)
+	comment = regexp.MustCompile(`(?m)^\s*(//|#|\*|/\*)`)
+)
+
+// Identify returns the SPDX identifier of a license text, or "" when the
+// license is not recognized
+func Identify(text string) string {
+	text = comment.ReplaceAllString(text, " ")
+	text = strings.ToLower(space.ReplaceAllString(text, " "))
+
+	for _, r := range rules {
+		matched := true
+		for _, phrase := range r.phrases {
+			if !strings.Contains(text, phrase) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return r.id
+		}
+	}
+	return ""
+}
+
+// FromFS identifies the license of the package rooted at fsys by its
+// license file. Unrecognized licenses are reported as "NOASSERTION".
+func FromFS(fsys fs.FS) string {
+	for _, name := range files {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			continue
+		}
+		if id := Identify(string(data)); id != "" {
+			return id
+		}
+		return "NOASSERTION"
+	}
+	return ""
+}
+
+// aliases maps license names commonly found in package metadata to their
+// SPDX identifiers
+var aliases = map[string]string{
+	"apache 2.0":     "Apache-2.0",
+	"apache-2":       "Apache-2.0",
+	"apache2":        "Apache-2.0",
+	"apache license": "Apache-2.0",
+	"bsd":            "BSD-3-Clause",
+	"bsd-3":          "BSD-3-Clause",
+	"bsd 3-clause":   "BSD-3-Clause",
+	"bsd-2":          "BSD-2-Clause",
+	"gpl-2.0":        "GPL-2.0",
+	"gpl-3.0":        "GPL-3.0",
+	"gplv2":          "GPL-2.0",
+	"gplv3":          "GPL-3.0",
+	"isc license":    "ISC",
+	"mit license":    "MIT",
+	"mit/x11":        "MIT",
+	"public domain":  "Unlicense",
+	"unlicensed":     "UNLICENSED",
+}
+
+// Normalize cleans up a declared license such as the license field of a
+// package.json. SPDX expressions are kept as they are and common non-SPDX
+// names are mapped to their identifiers.
+func Normalize(declared string) string {
+	declared = strings.TrimSpace(declared)
+	if alias, ok := aliases[strings.ToLower(declared)]; ok {
+		return alias
+	}
+	return declared
+}
@@ -0,0 +1,64 @@
+package license
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const mitText = `MIT License
+
+Copyright (c) 2024 Example
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction.`
+
+const bsd3Text = `Copyright (c) 2009 The Go Authors. All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are
+met:
+   * Neither the name of Google Inc. nor the names of its
+contributors may be used to endorse or promote products derived from
+this software without specific prior written permission.`
+
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "mit", text: mitText, want: "MIT"},
+		{name: "bsd3", text: bsd3Text, want: "BSD-3-Clause"},
+		{name: "bsd2", text: "Redistribution and use in source and binary forms, with or without modification, are permitted", want: "BSD-2-Clause"},
+		{name: "apache", text: "Apache License\n                           Version 2.0, January 2004", want: "Apache-2.0"},
+		{name: "isc", text: "Permission to use, copy, modify, and/or distribute this software for any\npurpose with or without fee is hereby granted, provided that the above\ncopyright notice and this permission notice appear in all copies.", want: "ISC"},
+		{name: "lgpl", text: "GNU LESSER GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007", want: "LGPL-3.0"},
+		{name: "gpl2", text: "GNU GENERAL PUBLIC LICENSE\nVersion 2, June 1991", want: "GPL-2.0"},
+		{name: "mpl", text: "Mozilla Public License Version 2.0", want: "MPL-2.0"},
+		{name: "commented", text: "// Permission is hereby granted, free of charge, to any person\n// obtaining a copy", want: "MIT"},
+		{name: "unknown", text: "All rights reserved. Do not copy.", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Identify(tt.text))
+		})
+	}
+}
+
+func TestFromFS(t *testing.T) {
+	assert.Equal(t, "MIT", FromFS(fstest.MapFS{"LICENSE.md": {Data: []byte(mitText)}}))
+	assert.Equal(t, "BSD-3-Clause", FromFS(fstest.MapFS{"COPYING": {Data: []byte(bsd3Text)}}))
+	assert.Equal(t, "NOASSERTION", FromFS(fstest.MapFS{"LICENSE": {Data: []byte("Proprietary")}}))
+	assert.Equal(t, "", FromFS(fstest.MapFS{"README.md": {Data: []byte(mitText)}}))
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "MIT", Normalize(" MIT "))
+	assert.Equal(t, "Apache-2.0", Normalize("Apache 2.0"))
+	assert.Equal(t, "(MIT OR Apache-2.0)", Normalize("(MIT OR Apache-2.0)"))
+	assert.Equal(t, "", Normalize(""))
+}
@@ -0,0 +1,101 @@
+package license
+
+import (
+	"sort"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Unknown is the SPDX value reported for packages whose license is not known
+const Unknown = "NOASSERTION"
+
+// Count is the number of packages under a license
+type Count struct {
+	License  string // SPDX identifier or expression, Unknown for packages without a known license
+	Packages int
+}
+
+// Closure is the set of licenses a direct dependency brings into the project
+type Closure struct {
+	Key      string   // Node key of the direct dependency
+	Licenses []string // Licenses of the dependency and of every package it depends on, sorted
+	Packages int      // Packages of the closure, the dependency included
+}
+
+// Rollup summarizes the licenses of a scan
+type Rollup struct {
+	Counts  []Count               // Packages per license, most packages first
+	Unknown []scanners.Dependency // Packages without a known license, by name and version
+	Direct  []Closure             // Per direct dependency, by key
+}
+
+// Summarize counts the packages of a scan per license and collects the
+// licenses of the transitive closure of each direct dependency. Packages
+// missing from the graph are counted but belong to no closure.
+func Summarize(result *scanners.ScanResult) Rollup {
+	var rollup Rollup
+	licenses := make(map[string]string, len(result.Dependencies))
+	counts := make(map[string]int)
+	for _, dep := range result.Dependencies {
+		value := dep.License
+		if value == "" || value == Unknown {
+			value = Unknown
+			rollup.Unknown = append(rollup.Unknown, dep)
+		}
+		licenses[scanners.NodeKey(dep.Name, dep.Version)] = value
+		counts[value]++
+	}
+
+	for value, n := range counts {
+		rollup.Counts = append(rollup.Counts, Count{License: value, Packages: n})
+	}
+	sort.Slice(rollup.Counts, func(i, j int) bool {
+		if rollup.Counts[i].Packages != rollup.Counts[j].Packages {
+			return rollup.Counts[i].Packages > rollup.Counts[j].Packages
+		}
+		return rollup.Counts[i].License < rollup.Counts[j].License
+	})
+	sort.Slice(rollup.Unknown, func(i, j int) bool {
+		a, b := rollup.Unknown[i], rollup.Unknown[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+
+	if result.Graph == nil {
+		return rollup
+	}
+	for _, key := range result.Graph.Children(result.Graph.Root) {
+		rollup.Direct = append(rollup.Direct, closure(result.Graph, key, licenses))
+	}
+	sort.Slice(rollup.Direct, func(i, j int) bool { return rollup.Direct[i].Key < rollup.Direct[j].Key })
+	return rollup
+}
+
+// closure collects the licenses of the packages reachable from key
+func closure(g *scanners.DependencyGraph, key string, licenses map[string]string) Closure {
+	seen := map[string]bool{key: true}
+	queue := []string{key}
+	found := make(map[string]bool)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if value, ok := licenses[current]; ok {
+			found[value] = true
+		}
+		for _, child := range g.Children(current) {
+			if !seen[child] {
+				seen[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	c := Closure{Key: key, Packages: len(seen)}
+	for value := range found {
+		c.Licenses = append(c.Licenses, value)
+	}
+	sort.Strings(c.Licenses)
+	return c
+}
@@ -0,0 +1,43 @@
+package license
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func TestSummarize(t *testing.T) {
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "express", Version: "4.19.2", License: "MIT"},
+			{Name: "body-parser", Version: "1.20.2", License: "MIT"},
+			{Name: "qs", Version: "6.11.0", License: "BSD-3-Clause"},
+			{Name: "mystery", Version: "1.0.0"},
+			{Name: "chalk", Version: "5.3.0", License: "MIT"},
+			{Name: "blob", Version: "0.1.0", License: Unknown},
+		},
+		Graph: &scanners.DependencyGraph{
+			Edges: map[string][]string{
+				"":                   {"express@4.19.2", "chalk@5.3.0"},
+				"express@4.19.2":     {"body-parser@1.20.2", "qs@6.11.0"},
+				"body-parser@1.20.2": {"qs@6.11.0", "mystery@1.0.0"},
+			},
+		},
+	}
+
+	rollup := Summarize(result)
+	assert.Equal(t, []Count{{"MIT", 3}, {Unknown, 2}, {"BSD-3-Clause", 1}}, rollup.Counts)
+	if assert.Len(t, rollup.Unknown, 2) {
+		assert.Equal(t, "blob", rollup.Unknown[0].Name)
+		assert.Equal(t, "mystery", rollup.Unknown[1].Name)
+	}
+	assert.Equal(t, []Closure{
+		{Key: "chalk@5.3.0", Licenses: []string{"MIT"}, Packages: 1},
+		{Key: "express@4.19.2", Licenses: []string{"BSD-3-Clause", "MIT", Unknown}, Packages: 4},
+	}, rollup.Direct)
+
+	result.Graph = nil
+	assert.Empty(t, Summarize(result).Direct)
+}
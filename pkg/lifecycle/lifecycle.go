@@ -0,0 +1,89 @@
+package lifecycle
+
+import (
+	"context"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Enrichment is the name of the registry install script lookup in
+// ScanOptions.Enrich
+const Enrichment = "scripts"
+
+// InstallScripts are the lifecycle scripts npm runs when installing a
+// package, in the order it runs them
+var InstallScripts = []string{"preinstall", "install", "postinstall"}
+
+// Filter returns the install scripts among a package's scripts
+func Filter(scripts map[string]string) map[string]string {
+	installs := make(map[string]string)
+	for _, name := range InstallScripts {
+		if script := strings.TrimSpace(scripts[name]); script != "" {
+			installs[name] = script
+		}
+	}
+	return installs
+}
+
+// Annotate records install scripts in a dependency's properties.
+// "installScripts" lists their names and, with includeText, "script.<name>"
+// holds the command of each.
+func Annotate(props map[string]string, scripts map[string]string, includeText bool) {
+	var names []string
+	for _, name := range InstallScripts {
+		script, ok := scripts[name]
+		if !ok {
+			continue
+		}
+		names = append(names, name)
+		if includeText {
+			props["script."+name] = script
+		}
+	}
+	if len(names) > 0 {
+		props["installScripts"] = strings.Join(names, ",")
+	}
+}
+
+// Enrich looks up the install scripts of npm dependencies in the registry
+// when they were not found in node_modules
+func Enrich(ctx context.Context, source registry.Source, result *scanners.ScanResult, includeText bool) error {
+	var pending []scanners.Dependency
+	for _, dep := range result.Dependencies {
+		if dep.Type == "npm" && dep.Properties["installScripts"] == "" {
+			pending = append(pending, dep)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	packages, err := registry.Lookup(ctx, source, pending)
+	if err != nil {
+		return err
+	}
+
+	for i := range result.Dependencies {
+		dep := &result.Dependencies[i]
+		if dep.Type != "npm" || dep.Properties["installScripts"] != "" {
+			continue
+		}
+		pkg, ok := packages[registry.Key{Type: dep.Type, Name: dep.Name}]
+		if !ok {
+			continue
+		}
+		scripts := Filter(pkg.Scripts[dep.Version])
+		if len(scripts) == 0 {
+			continue
+		}
+
+		if dep.Properties == nil {
+			dep.Properties = make(map[string]string)
+		}
+		Annotate(dep.Properties, scripts, includeText)
+	}
+
+	return nil
+}
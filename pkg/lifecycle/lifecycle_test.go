@@ -0,0 +1,75 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func TestFilter(t *testing.T) {
+	assert.Equal(t, map[string]string{"preinstall": "node check.js", "postinstall": "node install.js"}, Filter(map[string]string{
+		"preinstall":  "node check.js",
+		"install":     " ",
+		"postinstall": "node install.js",
+		"test":        "jest",
+	}))
+	assert.Empty(t, Filter(nil))
+}
+
+func TestAnnotate(t *testing.T) {
+	scripts := map[string]string{"postinstall": "node install.js", "preinstall": "node check.js"}
+
+	props := map[string]string{}
+	Annotate(props, scripts, false)
+	assert.Equal(t, map[string]string{"installScripts": "preinstall,postinstall"}, props)
+
+	props = map[string]string{}
+	Annotate(props, scripts, true)
+	assert.Equal(t, map[string]string{
+		"installScripts":     "preinstall,postinstall",
+		"script.preinstall":  "node check.js",
+		"script.postinstall": "node install.js",
+	}, props)
+
+	props = map[string]string{}
+	Annotate(props, nil, true)
+	assert.Empty(t, props)
+}
+
+type fakeSource map[string]*registry.Package
+
+func (s fakeSource) Package(ctx context.Context, depType, name string) (*registry.Package, error) {
+	if pkg, ok := s[name]; ok {
+		return pkg, nil
+	}
+	return nil, registry.ErrNotFound
+}
+
+func TestEnrich(t *testing.T) {
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "esbuild", Version: "0.20.2", Type: "npm", Properties: map[string]string{"hasInstallScript": "true"}},
+			{Name: "installed", Version: "1.0.0", Type: "npm", Properties: map[string]string{"installScripts": "install"}},
+			{Name: "plain", Version: "1.0.0", Type: "npm"},
+			{Name: "golang.org/x/mod", Version: "v0.17.0", Type: "go"},
+		},
+	}
+
+	source := fakeSource{
+		"esbuild":   {Scripts: map[string]map[string]string{"0.20.2": {"postinstall": "node install.js"}}},
+		"installed": {Scripts: map[string]map[string]string{"1.0.0": {"postinstall": "ignored"}}},
+		"plain":     {Scripts: map[string]map[string]string{"1.0.0": {"test": "jest"}}},
+	}
+
+	assert.NoError(t, Enrich(context.Background(), source, result, true))
+
+	expected := map[string]string{"hasInstallScript": "true", "installScripts": "postinstall", "script.postinstall": "node install.js"}
+	assert.Equal(t, expected, result.Dependencies[0].Properties)
+	assert.Equal(t, map[string]string{"installScripts": "install"}, result.Dependencies[1].Properties)
+	assert.Nil(t, result.Dependencies[2].Properties)
+	assert.Nil(t, result.Dependencies[3].Properties)
+}
@@ -0,0 +1,96 @@
+package maintainers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Enrichment is the name of the maintainer lookup in ScanOptions.Enrich
+const Enrichment = "maintainers"
+
+// SingleMaintainerEnrichment is the name of the single maintainer report in
+// ScanOptions.Enrich, which also needs Enrichment
+const SingleMaintainerEnrichment = "single-maintainer"
+
+// Rule is the rule name of single maintainer findings
+const Rule = "single-maintainer"
+
+// Dependency properties recorded by Enrich
+const (
+	Property      = "maintainers"     // Maintainers, comma separated
+	CountProperty = "maintainerCount" // Number of maintainers
+	Publisher     = "publisher"       // npm user who published the installed version
+	Funding       = "funding"         // Funding URLs of the installed version, comma separated
+)
+
+// Enrich records the npm users allowed to publish every npm dependency as the
+// "maintainers" and "maintainerCount" properties, and the user who published
+// the installed version and the funding links it declares as "publisher" and
+// "funding". With report, production dependencies only one user can publish
+// are reported as warnings: a single compromised or departing account
+// controls them. Go modules have no registry accounts and are left
+// untouched, as are packages missing from the registry.
+func Enrich(ctx context.Context, source registry.Source, result *scanners.ScanResult, report bool) error {
+	var npm []scanners.Dependency
+	for _, dep := range result.Dependencies {
+		if dep.Type == "npm" {
+			npm = append(npm, dep)
+		}
+	}
+	packages, err := registry.Lookup(ctx, source, npm)
+	if err != nil {
+		return err
+	}
+
+	for i := range result.Dependencies {
+		dep := &result.Dependencies[i]
+		pkg, ok := packages[registry.Key{Type: dep.Type, Name: dep.Name}]
+		if !ok {
+			continue
+		}
+		props := make(map[string]string)
+		if len(pkg.Maintainers) > 0 {
+			props[Property] = strings.Join(pkg.Maintainers, ",")
+			props[CountProperty] = strconv.Itoa(len(pkg.Maintainers))
+		}
+		if publisher := pkg.Publishers[dep.Version]; publisher != "" {
+			props[Publisher] = publisher
+		}
+		if links := pkg.Funding[dep.Version]; len(links) > 0 {
+			props[Funding] = strings.Join(links, ",")
+		}
+		if len(props) == 0 {
+			continue
+		}
+
+		if dep.Properties == nil {
+			dep.Properties = make(map[string]string)
+		}
+		for key, value := range props {
+			dep.Properties[key] = value
+		}
+
+		if report && len(pkg.Maintainers) == 1 && !development(dep) {
+			result.Findings = append(result.Findings, scanners.Finding{
+				Rule:       Rule,
+				Severity:   scanners.SeverityWarning,
+				Dependency: dep.Name,
+				Version:    dep.Version,
+				Message:    fmt.Sprintf("%s can only be published by %s", dep.Name, pkg.Maintainers[0]),
+			})
+		}
+	}
+	return nil
+}
+
+// development reports whether a dependency is only needed to develop the
+// project
+func development(dep *scanners.Dependency) bool {
+	depType := dep.Properties["dependencyType"]
+	return depType == "development" || depType == "test"
+}
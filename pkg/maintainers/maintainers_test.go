@@ -0,0 +1,80 @@
+package maintainers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+type fakeSource map[string]*registry.Package
+
+func (s fakeSource) Package(ctx context.Context, depType, name string) (*registry.Package, error) {
+	if pkg, ok := s[name]; ok {
+		return pkg, nil
+	}
+	return nil, registry.ErrNotFound
+}
+
+func TestEnrich(t *testing.T) {
+	source := fakeSource{
+		"lodash":   {Name: "lodash", Maintainers: []string{"mathias", "jdalton"}},
+		"left-pad": {Name: "left-pad"},
+	}
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "lodash", Version: "4.17.21", Type: "npm"},
+			{Name: "left-pad", Version: "1.3.0", Type: "npm"},
+			{Name: "private", Version: "1.0.0", Type: "npm"},
+			{Name: "github.com/spf13/cobra", Version: "v1.8.0", Type: "go"},
+		},
+	}
+
+	if !assert.NoError(t, Enrich(context.Background(), source, result, false)) {
+		return
+	}
+	assert.Equal(t, map[string]string{Property: "mathias,jdalton", CountProperty: "2"}, result.Dependencies[0].Properties)
+	assert.Nil(t, result.Dependencies[1].Properties)
+	assert.Nil(t, result.Dependencies[2].Properties)
+	assert.Nil(t, result.Dependencies[3].Properties)
+	assert.Empty(t, result.Findings)
+}
+
+func TestEnrich_SingleMaintainer(t *testing.T) {
+	source := fakeSource{
+		"left-pad": {
+			Name:        "left-pad",
+			Maintainers: []string{"azer"},
+			Publishers:  map[string]string{"1.3.0": "azer"},
+			Funding:     map[string][]string{"1.3.0": {"https://github.com/sponsors/azer", "https://opencollective.com/left-pad"}},
+		},
+		"eslint-plugin-x": {Name: "eslint-plugin-x", Maintainers: []string{"solo"}},
+		"lodash":          {Name: "lodash", Maintainers: []string{"mathias", "jdalton"}},
+	}
+	result := &scanners.ScanResult{Dependencies: []scanners.Dependency{
+		{Name: "left-pad", Version: "1.3.0", Type: "npm", Properties: map[string]string{"dependencyType": "production"}},
+		{Name: "eslint-plugin-x", Version: "1.0.0", Type: "npm", Properties: map[string]string{"dependencyType": "development"}},
+		{Name: "lodash", Version: "4.17.21", Type: "npm"},
+	}}
+
+	if !assert.NoError(t, Enrich(context.Background(), source, result, true)) {
+		return
+	}
+	assert.Equal(t, map[string]string{
+		"dependencyType": "production",
+		Property:         "azer",
+		CountProperty:    "1",
+		Publisher:        "azer",
+		Funding:          "https://github.com/sponsors/azer,https://opencollective.com/left-pad",
+	}, result.Dependencies[0].Properties)
+	assert.Equal(t, []scanners.Finding{{
+		Rule:       Rule,
+		Severity:   scanners.SeverityWarning,
+		Dependency: "left-pad",
+		Version:    "1.3.0",
+		Message:    "left-pad can only be published by azer",
+	}}, result.Findings)
+}
@@ -0,0 +1,193 @@
+package npmrc
+
+import (
+	"encoding/base64"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// File is the name of the npm configuration file of projects and users
+const File = ".npmrc"
+
+// Config is the registry configuration of an .npmrc file, or of several
+// merged in the order npm reads them
+type Config struct {
+	Registry string            // Default registry, "" when not configured
+	Scopes   map[string]string // Registry by scope, such as @myorg
+
+	// auth holds the credentials of each registry by nerf dart, the URL
+	// without its scheme such as //npm.pkg.github.com/
+	auth map[string]credentials
+}
+
+type credentials struct {
+	token    string // _authToken
+	basic    string // _auth, base64 of user:password
+	username string
+	password string // _password, base64
+}
+
+// reference matches the environment variable references npm expands,
+// ${NAME} or ${NAME?} for an optional one
+var reference = regexp.MustCompile(`\$\{([^${}?]+)\??\}`)
+
+// Parse reads an .npmrc file. Environment variable references in values
+// are expanded with expand, or kept as written when expand is nil, which
+// leaves credentials given by reference unusable.
+func Parse(content []byte, expand func(string) string) *Config {
+	config := &Config{Scopes: make(map[string]string), auth: make(map[string]credentials)}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.Trim(strings.TrimSpace(value), `"'`)
+		if expand != nil {
+			value = reference.ReplaceAllStringFunc(value, func(ref string) string {
+				return expand(reference.FindStringSubmatch(ref)[1])
+			})
+		}
+
+		switch {
+		case key == "registry":
+			config.Registry = value
+		case strings.HasPrefix(key, "@") && strings.HasSuffix(key, ":registry"):
+			config.Scopes[strings.TrimSuffix(key, ":registry")] = value
+		case strings.HasPrefix(key, "//"):
+			// Credentials are scoped to a registry, as in //host/path/:_authToken
+			i := strings.LastIndex(key, ":")
+			if i < 0 {
+				continue
+			}
+			dart := key[:i]
+			if !strings.HasSuffix(dart, "/") {
+				dart += "/"
+			}
+			creds := config.auth[dart]
+			switch key[i+1:] {
+			case "_authToken":
+				creds.token = value
+			case "_auth":
+				creds.basic = value
+			case "username":
+				creds.username = value
+			case "_password":
+				creds.password = value
+			default:
+				continue
+			}
+			config.auth[dart] = creds
+		}
+	}
+	return config
+}
+
+// merge overrides the settings of c with those of other
+func (c *Config) merge(other *Config) {
+	if other.Registry != "" {
+		c.Registry = other.Registry
+	}
+	for scope, registry := range other.Scopes {
+		c.Scopes[scope] = registry
+	}
+	for dart, creds := range other.auth {
+		c.auth[dart] = creds
+	}
+}
+
+// UserFile returns the path of the user's .npmrc, from NPM_CONFIG_USERCONFIG
+// or in the home directory, or "" when there is no home directory
+func UserFile() string {
+	if path := os.Getenv("NPM_CONFIG_USERCONFIG"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, File)
+}
+
+// Load reads the user's .npmrc and then the project's in fsys, whose
+// settings take precedence, as npm does. References to environment variables
+// are expanded in the user's file, but only in the project's when
+// expandProject is set: a project scanned from an untrusted source could
+// otherwise send the environment to a registry of its choosing. Missing
+// files are skipped.
+func Load(fsys fs.FS, expandProject bool) (*Config, error) {
+	config := Parse(nil, nil)
+	if path := UserFile(); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		config.merge(Parse(content, os.Getenv))
+	}
+
+	content, err := fs.ReadFile(fsys, File)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	var expand func(string) string
+	if expandProject {
+		expand = os.Getenv
+	}
+	config.merge(Parse(content, expand))
+	return config, nil
+}
+
+// RegistryFor returns the registry a package is installed from: the
+// registry of its scope, or else the default registry, or "" when neither is
+// configured
+func (c *Config) RegistryFor(name string) string {
+	if scope, _, ok := strings.Cut(name, "/"); ok && strings.HasPrefix(scope, "@") {
+		if registry := c.Scopes[scope]; registry != "" {
+			return registry
+		}
+	}
+	return c.Registry
+}
+
+// Authorization returns the Authorization header to send with a request to
+// rawURL, from the credentials of the registry with the longest URL the
+// request falls under, or "" for none
+func (c *Config) Authorization(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	target := "//" + u.Host + u.Path
+	var match string
+	for dart := range c.auth {
+		if strings.HasPrefix(target, dart) && len(dart) > len(match) {
+			match = dart
+		}
+	}
+	if match == "" {
+		return ""
+	}
+
+	creds := c.auth[match]
+	switch {
+	case creds.token != "" && !reference.MatchString(creds.token):
+		return "Bearer " + creds.token
+	case creds.basic != "" && !reference.MatchString(creds.basic):
+		return "Basic " + creds.basic
+	case creds.username != "" && creds.password != "" && !reference.MatchString(creds.username):
+		password, err := base64.StdEncoding.DecodeString(creds.password)
+		if err != nil {
+			return ""
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(creds.username+":"+string(password)))
+	}
+	return ""
+}
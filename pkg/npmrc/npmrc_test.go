@@ -0,0 +1,75 @@
+package npmrc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	config := Parse([]byte(`
+# Company registries
+registry=https://npm.example.com/
+@myorg:registry = https://npm.pkg.github.com
+; Credentials
+//npm.pkg.github.com/:_authToken=${GITHUB_TOKEN}
+//npm.example.com/:_auth="dXNlcjpwYXNz"
+//npm.example.com/private/:username=deploy
+//npm.example.com/private/:_password=c2VjcmV0
+//npm.example.com/:always-auth=true
+`), func(name string) string { return map[string]string{"GITHUB_TOKEN": "ghp_123"}[name] })
+
+	assert.Equal(t, "https://npm.example.com/", config.Registry)
+	assert.Equal(t, map[string]string{"@myorg": "https://npm.pkg.github.com"}, config.Scopes)
+	assert.Equal(t, "https://npm.pkg.github.com", config.RegistryFor("@myorg/ui"))
+	assert.Equal(t, "https://npm.example.com/", config.RegistryFor("@types/node"))
+	assert.Equal(t, "https://npm.example.com/", config.RegistryFor("lodash"))
+
+	assert.Equal(t, "Bearer ghp_123", config.Authorization("https://npm.pkg.github.com/@myorg%2Fui"))
+	assert.Equal(t, "Basic dXNlcjpwYXNz", config.Authorization("https://npm.example.com/lodash"))
+	assert.Equal(t, "Basic ZGVwbG95OnNlY3JldA==", config.Authorization("https://npm.example.com/private/lodash"))
+	assert.Empty(t, config.Authorization("https://registry.npmjs.org/lodash"))
+	assert.Empty(t, config.Authorization("https://npm.example.com.evil.test/lodash"))
+}
+
+func TestParse_Unexpanded(t *testing.T) {
+	config := Parse([]byte("//npm.pkg.github.com/:_authToken=${GITHUB_TOKEN}\n//npm.example.com/:_authToken=literal\n"), nil)
+	assert.Empty(t, config.Authorization("https://npm.pkg.github.com/@myorg%2Fui"))
+	assert.Equal(t, "Bearer literal", config.Authorization("https://npm.example.com/lodash"))
+	assert.Empty(t, config.RegistryFor("lodash"))
+}
+
+func TestLoad(t *testing.T) {
+	user := filepath.Join(t.TempDir(), "npmrc")
+	if !assert.NoError(t, os.WriteFile(user, []byte("registry=https://npm.example.com/\n//npm.example.com/:_authToken=${NPMRC_TEST_TOKEN}\n"), 0o600)) {
+		return
+	}
+	t.Setenv("NPM_CONFIG_USERCONFIG", user)
+	t.Setenv("NPMRC_TEST_TOKEN", "user-token")
+	t.Setenv("NPMRC_TEST_PROJECT", "project-token")
+
+	fsys := fstest.MapFS{File: {Data: []byte("@myorg:registry=https://npm.pkg.github.com/\n//npm.pkg.github.com/:_authToken=${NPMRC_TEST_PROJECT}\n")}}
+	config, err := Load(fsys, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "https://npm.pkg.github.com/", config.RegistryFor("@myorg/ui"))
+	assert.Equal(t, "https://npm.example.com/", config.RegistryFor("lodash"))
+	assert.Equal(t, "Bearer user-token", config.Authorization("https://npm.example.com/lodash"))
+	assert.Empty(t, config.Authorization("https://npm.pkg.github.com/@myorg%2Fui"))
+
+	config, err = Load(fsys, true)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Bearer project-token", config.Authorization("https://npm.pkg.github.com/@myorg%2Fui"))
+	}
+
+	// Missing files are skipped
+	t.Setenv("NPM_CONFIG_USERCONFIG", filepath.Join(t.TempDir(), "missing"))
+	config, err = Load(fstest.MapFS{}, true)
+	if assert.NoError(t, err) {
+		assert.Empty(t, config.RegistryFor("lodash"))
+	}
+}
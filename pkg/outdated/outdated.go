@@ -0,0 +1,93 @@
+package outdated
+
+import (
+	"context"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/version"
+)
+
+// Enrichment is the name of the outdated enrichment in ScanOptions.Enrich
+const Enrichment = "outdated"
+
+// Update classifications
+const (
+	Major = "major"
+	Minor = "minor"
+	Patch = "patch"
+)
+
+// Enrich looks up the latest version of every dependency and annotates it
+// with the "latest", "wanted" and "update" properties. "wanted" is the
+// highest version allowed by the dependency's specifier, if it has one, and
+// "update" classifies the upgrade to the latest version as major, minor or
+// patch. Dependencies missing from their registry are left untouched.
+func Enrich(ctx context.Context, source registry.Source, result *scanners.ScanResult) error {
+	packages, err := registry.Lookup(ctx, source, result.Dependencies)
+	if err != nil {
+		return err
+	}
+
+	for i := range result.Dependencies {
+		dep := &result.Dependencies[i]
+		pkg, ok := packages[registry.Key{Type: dep.Type, Name: dep.Name}]
+		if !ok || pkg.Latest == "" {
+			continue
+		}
+
+		if dep.Properties == nil {
+			dep.Properties = make(map[string]string)
+		}
+		dep.Properties["latest"] = pkg.Latest
+
+		// A Go requirement is a minimum version and the module path pins
+		// the major version, so any later version is wanted
+		specifier := dep.Properties["specifier"]
+		if specifier == "" && dep.Type == "go" && dep.Version != "" {
+			specifier = ">=" + dep.Version
+		}
+		if wanted := Wanted(pkg, specifier); wanted != "" {
+			dep.Properties["wanted"] = wanted
+		}
+		if update := Classify(dep.Version, pkg.Latest); update != "" {
+			dep.Properties["update"] = update
+		}
+	}
+
+	return nil
+}
+
+// Wanted returns the highest published version satisfying the specifier, or
+// "" when the specifier is not a version range or nothing satisfies it
+func Wanted(pkg *registry.Package, specifier string) string {
+	if specifier == "" {
+		return ""
+	}
+	constraint, err := version.ParseConstraint(specifier)
+	if err != nil {
+		return ""
+	}
+	for i := len(pkg.Versions) - 1; i >= 0; i-- {
+		if constraint.Check(pkg.Versions[i]) {
+			return pkg.Versions[i]
+		}
+	}
+	return ""
+}
+
+// Classify returns the kind of update from current to latest, or "" when
+// current is up to date
+func Classify(current, latest string) string {
+	if current == "" || version.Compare(current, latest) >= 0 {
+		return ""
+	}
+	from, to := version.Parts(current), version.Parts(latest)
+	switch {
+	case from[0] != to[0]:
+		return Major
+	case from[1] != to[1]:
+		return Minor
+	}
+	return Patch
+}
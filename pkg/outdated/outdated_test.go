@@ -0,0 +1,63 @@
+package outdated
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+type fakeSource map[string]*registry.Package
+
+func (s fakeSource) Package(ctx context.Context, depType, name string) (*registry.Package, error) {
+	if pkg, ok := s[name]; ok {
+		return pkg, nil
+	}
+	return nil, registry.ErrNotFound
+}
+
+func TestEnrich(t *testing.T) {
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "react", Version: "17.0.1", Type: "npm", Properties: map[string]string{"specifier": "^17.0.0"}},
+			{Name: "golang.org/x/mod", Version: "v0.17.0", Type: "go"},
+			{Name: "current", Version: "1.0.0", Type: "npm"},
+			{Name: "private", Version: "1.0.0", Type: "npm"},
+		},
+	}
+
+	source := fakeSource{
+		"react":            {Latest: "18.2.0", Versions: []string{"17.0.1", "17.0.2", "18.0.0-rc.0", "18.2.0"}},
+		"golang.org/x/mod": {Latest: "v0.21.0", Versions: []string{"v0.17.0", "v0.20.0", "v0.21.0"}},
+		"current":          {Latest: "1.0.0", Versions: []string{"1.0.0"}},
+	}
+
+	assert.NoError(t, Enrich(context.Background(), source, result))
+
+	expected := map[string]string{"specifier": "^17.0.0", "latest": "18.2.0", "wanted": "17.0.2", "update": "major"}
+	assert.Equal(t, expected, result.Dependencies[0].Properties)
+	assert.Equal(t, map[string]string{"latest": "v0.21.0", "wanted": "v0.21.0", "update": "minor"}, result.Dependencies[1].Properties)
+	assert.Equal(t, map[string]string{"latest": "1.0.0"}, result.Dependencies[2].Properties)
+	assert.Nil(t, result.Dependencies[3].Properties)
+}
+
+func TestWanted(t *testing.T) {
+	pkg := &registry.Package{Versions: []string{"1.0.0", "1.2.0", "1.3.0-beta", "2.0.0"}}
+	assert.Equal(t, "1.2.0", Wanted(pkg, "^1.0.0"))
+	assert.Equal(t, "2.0.0", Wanted(pkg, "*"))
+	assert.Equal(t, "", Wanted(pkg, "^3.0.0"))
+	assert.Equal(t, "", Wanted(pkg, "github:user/repo"))
+	assert.Equal(t, "", Wanted(pkg, ""))
+}
+
+func TestClassify(t *testing.T) {
+	assert.Equal(t, Major, Classify("1.2.3", "2.0.0"))
+	assert.Equal(t, Minor, Classify("v1.2.3", "v1.3.0"))
+	assert.Equal(t, Patch, Classify("1.2.3", "1.2.4"))
+	assert.Equal(t, Patch, Classify("1.2.3-rc.1", "1.2.3"))
+	assert.Equal(t, "", Classify("1.2.3", "1.2.3"))
+	assert.Equal(t, "", Classify("2.0.0", "1.9.0"))
+}
@@ -0,0 +1,89 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Manifest is the file GitHub annotations of a project point at
+type Manifest struct {
+	Path    string // Slash separated path relative to the repository root, "" for none
+	Content []byte // Contents, used to find the line declaring a dependency
+}
+
+// WriteGitHubAnnotations writes the vulnerabilities and findings of a scan as
+// GitHub Actions ::error and ::warning workflow commands, which show inline
+// on pull requests. Vulnerabilities and findings of error or critical
+// severity are errors. Annotations of a dependency the manifest declares
+// point at its line; others at the manifest as a whole.
+func WriteGitHubAnnotations(writer io.Writer, result *scanners.ScanResult, manifest Manifest) error {
+	lines := strings.Split(string(manifest.Content), "\n")
+	write := func(level, dependency, title, message string) error {
+		var props []string
+		if manifest.Path != "" {
+			props = append(props, "file="+escapeProperty(manifest.Path))
+			if line := declaringLine(lines, dependency); line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", line))
+			}
+		}
+		props = append(props, "title="+escapeProperty(title))
+		_, err := fmt.Fprintf(writer, "::%s %s::%s\n", level, strings.Join(props, ","), escapeData(message))
+		return err
+	}
+
+	for _, dep := range result.Dependencies {
+		for _, vuln := range dep.Vulnerabilities {
+			message := vulnerabilityMessage(dep, vuln)
+			if len(vuln.FixedVersions) > 0 {
+				message += " (fixed in " + strings.Join(vuln.FixedVersions, ", ") + ")"
+			}
+			title := fmt.Sprintf("%s in %s", vuln.ID, scanners.NodeKey(dep.Name, dep.Version))
+			if err := write("error", dep.Name, title, message); err != nil {
+				return err
+			}
+		}
+	}
+	for _, finding := range result.Findings {
+		level := "error"
+		if finding.Severity == scanners.SeverityWarning {
+			level = "warning"
+		}
+		if err := write(level, finding.Dependency, finding.Rule, finding.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// declaringLine returns the 1-based number of the first line with the
+// dependency name as a token, such as a package.json key or a go.mod
+// requirement, or 0 when there is none
+func declaringLine(lines []string, name string) int {
+	if name == "" {
+		return 0
+	}
+	for i, line := range lines {
+		tokens := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == '"' || r == '\'' || r == ':' || r == ','
+		})
+		for _, token := range tokens {
+			if token == name {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// escapeData escapes the message of a workflow command
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
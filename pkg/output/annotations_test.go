@@ -0,0 +1,42 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteGitHubAnnotations(t *testing.T) {
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "lodash", Version: "4.17.20", Vulnerabilities: []scanners.Vulnerability{
+				{ID: "GHSA-35jh-r3h4-6jhm", Summary: "Command Injection", FixedVersions: []string{"4.17.21"}},
+			}},
+			{Name: "minimist", Version: "1.2.5", Vulnerabilities: []scanners.Vulnerability{{ID: "GHSA-xvch-5gv4-984h"}}},
+		},
+		Findings: []scanners.Finding{
+			{Rule: "deny license GPL-*", Severity: scanners.SeverityError, Dependency: "gpl-lib", Version: "1.0.0", Message: "gpl-lib@1.0.0 uses denied license GPL-3.0\n100% sure"},
+			{Rule: "single-maintainer", Severity: scanners.SeverityWarning, Dependency: "tiny", Version: "1.0.0", Message: "tiny has a single maintainer"},
+		},
+	}
+	manifest := Manifest{
+		Path:    "web/package.json",
+		Content: []byte("{\n  \"name\": \"web\",\n  \"dependencies\": {\n    \"lodash\": \"4.17.20\",\n    \"gpl-lib\": \"^1.0.0\"\n  }\n}\n"),
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteGitHubAnnotations(&buf, result, manifest))
+	assert.Equal(t, ""+
+		"::error file=web/package.json,line=4,title=GHSA-35jh-r3h4-6jhm in lodash@4.17.20::lodash@4.17.20 is affected by GHSA-35jh-r3h4-6jhm: Command Injection (fixed in 4.17.21)\n"+
+		"::error file=web/package.json,title=GHSA-xvch-5gv4-984h in minimist@1.2.5::minimist@1.2.5 is affected by GHSA-xvch-5gv4-984h\n"+
+		"::error file=web/package.json,line=5,title=deny license GPL-*::gpl-lib@1.0.0 uses denied license GPL-3.0%0A100%25 sure\n"+
+		"::warning file=web/package.json,title=single-maintainer::tiny has a single maintainer\n",
+		buf.String())
+
+	buf.Reset()
+	assert.NoError(t, WriteGitHubAnnotations(&buf, &scanners.ScanResult{Findings: result.Findings[1:]}, Manifest{}))
+	assert.Equal(t, "::warning title=single-maintainer::tiny has a single maintainer\n", buf.String())
+}
@@ -0,0 +1,108 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/cpe"
+	"github.com/santoshdahal12/deplister/pkg/purl"
+)
+
+// DependencyFields are the fields of dependencies SelectFields can keep, by
+// their JSON names. purl and cpe are computed from the type, name and
+// version, and are only written when selected.
+var DependencyFields = []string{"name", "version", "type", "purl", "cpe", "license", "isDirectDependency", "parent", "properties", "vulnerabilities"}
+
+// ParseFields splits a comma separated list of DependencyFields
+func ParseFields(list string) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !slices.Contains(DependencyFields, field) {
+			return nil, fmt.Errorf("unknown field %q, expected some of %s", field, strings.Join(DependencyFields, ", "))
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields given, expected some of %s", strings.Join(DependencyFields, ", "))
+	}
+	return fields, nil
+}
+
+// SelectFields returns the JSON form of an output document, or of a list of
+// ProjectOutput, keeping only the given fields of every dependency. The rest
+// of the document is unchanged.
+func SelectFields(doc any, fields []string) (any, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	// Numbers are kept as written, not converted to float64
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	selectDocument := func(doc any) {
+		object, ok := doc.(map[string]any)
+		if !ok {
+			return
+		}
+		deps, _ := object["dependencies"].([]any)
+		for _, dep := range deps {
+			if dep, ok := dep.(map[string]any); ok {
+				selectDependency(dep, fields)
+			}
+		}
+	}
+	if list, ok := value.([]any); ok {
+		for _, doc := range list {
+			selectDocument(doc)
+		}
+	} else {
+		selectDocument(value)
+	}
+	return value, nil
+}
+
+// selectDependency removes the fields of a dependency that are not selected,
+// adding its package URL and CPE when they are
+func selectDependency(dep map[string]any, fields []string) {
+	depType, _ := dep["type"].(string)
+	name, _ := dep["name"].(string)
+	version, _ := dep["version"].(string)
+	if slices.Contains(fields, "purl") {
+		dep["purl"] = purl.New(depType, name, version)
+	}
+	if slices.Contains(fields, "cpe") {
+		dep["cpe"] = cpe.New(depType, name, version)
+	}
+	for field := range dep {
+		if !slices.Contains(fields, field) {
+			delete(dep, field)
+		}
+	}
+}
+
+// WriteSelectedJSON writes an output document, or a list of ProjectOutput,
+// with only the given fields of every dependency
+func WriteSelectedJSON(writer io.Writer, doc any, fields []string, pretty bool) error {
+	selected, err := SelectFields(doc, fields)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(writer)
+	if pretty {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(selected)
+}
@@ -0,0 +1,43 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields("name, version,purl,")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "version", "purl"}, fields)
+
+	_, err = ParseFields("name,paths")
+	assert.ErrorContains(t, err, `unknown field "paths"`)
+
+	_, err = ParseFields(" , ")
+	assert.Error(t, err)
+}
+
+func TestWriteSelectedJSON(t *testing.T) {
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "@babel/core", Version: "7.24.0", Type: "npm", License: "MIT", IsDirectDep: true, Properties: map[string]string{"dependencyType": "production"}},
+			// A size no float64 holds exactly
+			{Name: "golang.org/x/net", Version: "v0.30.0", Type: "go", Properties: map[string]string{"size": "9007199254740993"}},
+		},
+		Findings: []scanners.Finding{{Rule: "deny", Severity: "error", Dependency: "@babel/core", Message: "denied"}},
+	}
+	doc := NewOutputFormat(result, "npm")
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteSelectedJSON(&buf, doc, []string{"name", "version", "license", "purl", "cpe"}, false))
+	assert.Equal(t, `{"dependencies":[{"cpe":"cpe:2.3:a:babel:core:7.24.0:*:*:*:*:node.js:*:*","license":"MIT","name":"@babel/core","purl":"pkg:npm/%40babel/core@7.24.0","version":"7.24.0"},{"cpe":"cpe:2.3:a:golang:net:0.30.0:*:*:*:*:go:*:*","name":"golang.org/x/net","purl":"pkg:golang/golang.org/x/net@v0.30.0","version":"v0.30.0"}],"findings":[{"dependency":"@babel/core","message":"denied","rule":"deny","severity":"error"}],"footprint":{"dependencies":1,"largest":[{"bytes":9007199254740993,"name":"golang.org/x/net","version":"v0.30.0"}],"totalBytes":9007199254740993},"projectType":"npm"}`+"\n", buf.String())
+
+	buf.Reset()
+	projects := []ProjectOutput{{Target: "/app", OutputFormat: &doc}, {Target: "/broken", Error: "no supported project found"}}
+	assert.NoError(t, WriteSelectedJSON(&buf, projects, []string{"name", "properties"}, false))
+	assert.Equal(t, `[{"dependencies":[{"name":"@babel/core","properties":{"dependencyType":"production"}},{"name":"golang.org/x/net","properties":{"size":"9007199254740993"}}],"findings":[{"dependency":"@babel/core","message":"denied","rule":"deny","severity":"error"}],"footprint":{"dependencies":1,"largest":[{"bytes":9007199254740993,"name":"golang.org/x/net","version":"v0.30.0"}],"totalBytes":9007199254740993},"projectType":"npm","target":"/app"},{"error":"no supported project found","target":"/broken"}]`+"\n", buf.String())
+}
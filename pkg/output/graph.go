@@ -0,0 +1,108 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// GraphOutput is the dependency graph of a scan. Nodes are keyed by
+// scanners.NodeKey, name@version, and are the dependencies of the document
+// except for the roots, the project itself.
+type GraphOutput struct {
+	Root    string              `json:"root"` // Key of the project, "" for npm
+	Roots   []string            `json:"roots"`
+	Edges   map[string][]string `json:"edges"`
+	Parents map[string][]string `json:"parents"` // Reverse of Edges, sorted
+}
+
+// NewGraphOutput converts a dependency graph into its JSON form, or returns
+// nil for none
+func NewGraphOutput(graph *scanners.DependencyGraph) *GraphOutput {
+	if graph == nil {
+		return nil
+	}
+	output := &GraphOutput{Root: graph.Root, Roots: graph.Roots(), Edges: make(map[string][]string), Parents: make(map[string][]string)}
+	if output.Roots == nil {
+		output.Roots = []string{}
+	}
+	for parent, children := range graph.Edges {
+		output.Edges[parent] = slices.Clone(children)
+		for _, child := range children {
+			if _, ok := output.Parents[child]; !ok {
+				output.Parents[child] = graph.Parents(child)
+			}
+		}
+	}
+	return output
+}
+
+// restore rebuilds the graph of deps, whose paths, depths and parents it
+// recomputes from the edges. Paths are the shortest from the nearest root.
+func (g *GraphOutput) restore(deps []scanners.Dependency) *scanners.DependencyGraph {
+	graph := &scanners.DependencyGraph{Nodes: make(map[string]*scanners.Dependency, len(deps)), Edges: make(map[string][]string, len(g.Edges)), Root: g.Root}
+	for parent, children := range g.Edges {
+		graph.Edges[parent] = slices.Clone(children)
+	}
+	for i := range deps {
+		graph.Nodes[scanners.NodeKey(deps[i].Name, deps[i].Version)] = &deps[i]
+	}
+
+	shortest := make(map[string]scanners.DependencyPath)
+	for _, root := range g.Roots {
+		for key, path := range graph.ShortestPaths(root) {
+			if current, ok := shortest[key]; !ok || path.Depth < current.Depth {
+				shortest[key] = path
+			}
+		}
+	}
+	for key, dep := range graph.Nodes {
+		if path, ok := shortest[key]; ok {
+			dep.Paths = []scanners.DependencyPath{path}
+			dep.Depth = path.Depth
+		}
+		dep.Parents = nil
+		for _, parent := range graph.Parents(key) {
+			if node, ok := graph.Nodes[parent]; ok && !slices.Contains(dep.Parents, node.Name) {
+				dep.Parents = append(dep.Parents, node.Name)
+			}
+		}
+	}
+	return graph
+}
+
+// Save writes the scan result as JSON like WriteJSON, with its dependency
+// graph so that ReadJSON restores it
+func Save(writer io.Writer, result *scanners.ScanResult, projectType string, pretty bool) error {
+	output := NewOutputFormat(result, projectType)
+	output.Graph = NewGraphOutput(result.Graph)
+	encoder := json.NewEncoder(writer)
+	if pretty {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(output)
+}
+
+// SaveFile writes the scan result with its dependency graph to a file
+func SaveFile(path string, result *scanners.ScanResult, projectType string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	return errors.Join(Save(file, result, projectType, false), file.Close())
+}
+
+// LoadFile reads a scan result from a file written by Save or WriteJSON,
+// with its project type
+func LoadFile(path string) (*scanners.ScanResult, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+	return ReadJSON(file)
+}
@@ -0,0 +1,76 @@
+package output
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSave(t *testing.T) {
+	result := testResult()
+	result.Graph = &scanners.DependencyGraph{
+		Nodes: map[string]*scanners.Dependency{
+			"express@4.17.1": &result.Dependencies[0],
+			"accepts@1.3.7":  &result.Dependencies[1],
+		},
+		Edges: map[string][]string{
+			"":               {"express@4.17.1"},
+			"express@4.17.1": {"accepts@1.3.7"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, Save(&buf, result, "npm", false)) {
+		return
+	}
+	assert.Contains(t, buf.String(), `"graph":{"root":"","roots":[""],"edges":{"":["express@4.17.1"],"express@4.17.1":["accepts@1.3.7"]},"parents":{"accepts@1.3.7":["express@4.17.1"],"express@4.17.1":[""]}}`)
+
+	read, projectType, err := ReadJSON(&buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "npm", projectType)
+	if !assert.NotNil(t, read.Graph) {
+		return
+	}
+	assert.Equal(t, result.Graph.Edges, read.Graph.Edges)
+	assert.Equal(t, []string{""}, read.Graph.Roots())
+	accepts, ok := read.Graph.Node("accepts", "1.3.7")
+	if assert.True(t, ok) {
+		assert.Same(t, &read.Dependencies[1], accepts)
+		assert.Equal(t, 2, accepts.Depth)
+		assert.Equal(t, []scanners.DependencyPath{{Path: []string{"", "express@4.17.1", "accepts@1.3.7"}, Depth: 2}}, accepts.Paths)
+		assert.Equal(t, []string{"express"}, accepts.Parents)
+	}
+	assert.Equal(t, []scanners.Dependent{{Key: "express@4.17.1", Depth: 1, Via: "accepts@1.3.7"}}, read.Graph.Dependents("accepts@1.3.7"))
+
+	// WriteJSON leaves the graph out
+	buf.Reset()
+	assert.NoError(t, WriteJSON(&buf, result, "npm", false))
+	read, _, err = ReadJSON(&buf)
+	if assert.NoError(t, err) {
+		assert.Nil(t, read.Graph)
+	}
+}
+
+func TestSaveFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.json")
+	result := testResult()
+	result.Graph = &scanners.DependencyGraph{Edges: map[string][]string{"example.com/app": {"express@4.17.1"}}}
+	if !assert.NoError(t, SaveFile(path, result, "npm")) {
+		return
+	}
+	read, projectType, err := LoadFile(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "npm", projectType)
+		assert.Equal(t, 1, read.Dependencies[0].Depth)
+		assert.Len(t, read.Graph.Nodes, 2)
+	}
+
+	_, _, err = LoadFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,225 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// JUnitProject is a scanned project to report as JUnit test suites
+type JUnitProject struct {
+	Name   string               // Target the project was scanned from, prefixing its suite names
+	Result *scanners.ScanResult // nil when the scan failed
+	Err    error                // Why the scan failed
+}
+
+// VulnerabilitySuite names the suite of the vulnerabilities of a project
+const VulnerabilitySuite = "vulnerabilities"
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the findings and vulnerabilities of the projects as JUnit
+// XML, for CI systems to render as test results. Each vulnerability is a
+// failed test case in the project's vulnerabilities suite and each finding a
+// test case in the suite of its rule, failed for errors and passed with the
+// message as output for warnings. Ignored findings are skipped test cases,
+// and a failed scan or a failed step of a scan is an error. A project
+// without any of these passes a single test case, so that it still shows.
+func WriteJUnit(writer io.Writer, projects []JUnitProject) error {
+	doc := junitSuites{Name: "deplister"}
+	for _, project := range projects {
+		for _, suite := range junitProject(project) {
+			for _, c := range suite.Cases {
+				suite.Tests++
+				switch {
+				case c.Failure != nil:
+					suite.Failures++
+				case c.Error != nil:
+					suite.Errors++
+				case c.Skipped != nil:
+					suite.Skipped++
+				}
+			}
+			doc.Tests += suite.Tests
+			doc.Failures += suite.Failures
+			doc.Errors += suite.Errors
+			doc.Skipped += suite.Skipped
+			doc.Suites = append(doc.Suites, suite)
+		}
+	}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(writer)
+	return err
+}
+
+// junitProject returns the suites of a project, its scan first, then its
+// vulnerabilities and then its findings by rule
+func junitProject(project JUnitProject) []junitSuite {
+	scan := junitSuite{Name: project.Name}
+	if project.Err != nil {
+		scan.Cases = append(scan.Cases, junitCase{Name: "scan", ClassName: project.Name, Error: &junitMessage{Message: project.Err.Error()}})
+		return []junitSuite{scan}
+	}
+	result := project.Result
+	for _, scanErr := range result.Errors {
+		scan.Cases = append(scan.Cases, junitCase{Name: scanErr.Step, ClassName: project.Name, Error: &junitMessage{Message: scanErr.Message}})
+	}
+
+	var suites []junitSuite
+	if len(scan.Cases) > 0 {
+		suites = append(suites, scan)
+	}
+	vulns := junitSuite{Name: suiteName(project.Name, VulnerabilitySuite)}
+	for _, dep := range result.Dependencies {
+		for _, vuln := range dep.Vulnerabilities {
+			vulns.Cases = append(vulns.Cases, junitCase{
+				Name:      vuln.ID,
+				ClassName: scanners.NodeKey(dep.Name, dep.Version),
+				Failure:   &junitMessage{Message: vulnerabilityMessage(dep, vuln), Type: vuln.Severity, Text: vulnerabilityDetails(vuln)},
+			})
+		}
+	}
+	if len(vulns.Cases) > 0 {
+		suites = append(suites, vulns)
+	}
+
+	// Findings and ignored findings are grouped by rule, in order of first
+	// appearance
+	byRule := make(map[string]*junitSuite)
+	var rules []string
+	suiteOf := func(rule string) *junitSuite {
+		if _, ok := byRule[rule]; !ok {
+			byRule[rule] = &junitSuite{Name: suiteName(project.Name, rule)}
+			rules = append(rules, rule)
+		}
+		return byRule[rule]
+	}
+	for _, finding := range result.Findings {
+		c := junitCase{Name: findingName(finding), ClassName: findingClass(project.Name, finding)}
+		if finding.Severity == scanners.SeverityWarning {
+			c.SystemOut = finding.Message
+		} else {
+			c.Failure = &junitMessage{Message: finding.Message, Type: finding.Severity}
+		}
+		suite := suiteOf(finding.Rule)
+		suite.Cases = append(suite.Cases, c)
+	}
+	for _, ignored := range result.Ignored {
+		c := junitCase{Name: findingName(ignored.Finding), ClassName: findingClass(project.Name, ignored.Finding)}
+		if ignored.Advisory != "" {
+			c.Name = ignored.Advisory
+		}
+		message := "accepted risk"
+		if ignored.Justification != "" {
+			message += ": " + ignored.Justification
+		}
+		if ignored.Expires != "" {
+			message += " (until " + ignored.Expires + ")"
+		}
+		c.Skipped = &junitMessage{Message: message, Text: ignored.Message}
+		suite := suiteOf(ignored.Rule)
+		suite.Cases = append(suite.Cases, c)
+	}
+	for _, rule := range rules {
+		suites = append(suites, *byRule[rule])
+	}
+
+	if len(suites) == 0 {
+		scan.Cases = []junitCase{{Name: "scan", ClassName: project.Name}}
+		suites = append(suites, scan)
+	}
+	return suites
+}
+
+func suiteName(project, name string) string {
+	if project == "" {
+		return name
+	}
+	return project + ": " + name
+}
+
+// findingName names the test case of a finding after its dependency, or
+// its rule for findings about the project as a whole
+func findingName(finding scanners.Finding) string {
+	if finding.Dependency == "" {
+		return finding.Rule
+	}
+	return scanners.NodeKey(finding.Dependency, finding.Version)
+}
+
+func findingClass(project string, finding scanners.Finding) string {
+	if finding.Dependency == "" {
+		return project
+	}
+	return finding.Dependency
+}
+
+func vulnerabilityMessage(dep scanners.Dependency, vuln scanners.Vulnerability) string {
+	message := fmt.Sprintf("%s@%s is affected by %s", dep.Name, dep.Version, vuln.ID)
+	if vuln.Summary != "" {
+		message += ": " + vuln.Summary
+	}
+	return message
+}
+
+// vulnerabilityDetails lists the aliases, CVSS vector and fixed versions of
+// a vulnerability, one per line
+func vulnerabilityDetails(vuln scanners.Vulnerability) string {
+	var lines []string
+	if len(vuln.Aliases) > 0 {
+		aliases := append([]string(nil), vuln.Aliases...)
+		sort.Strings(aliases)
+		lines = append(lines, "Aliases: "+strings.Join(aliases, ", "))
+	}
+	if vuln.CVSS != "" {
+		lines = append(lines, "CVSS: "+vuln.CVSS)
+	}
+	if len(vuln.FixedVersions) > 0 {
+		lines = append(lines, "Fixed in: "+strings.Join(vuln.FixedVersions, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,74 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "lodash", Version: "4.17.20", Vulnerabilities: []scanners.Vulnerability{
+				{ID: "GHSA-35jh-r3h4-6jhm", Aliases: []string{"CVE-2021-23337"}, Summary: "Command Injection", Severity: "HIGH", FixedVersions: []string{"4.17.21"}},
+			}},
+			{Name: "express", Version: "4.17.1"},
+		},
+		Findings: []scanners.Finding{
+			{Rule: "deny left-pad", Severity: scanners.SeverityError, Dependency: "left-pad", Version: "1.3.0", Message: "left-pad@1.3.0 is denied by policy"},
+			{Rule: "single-maintainer", Severity: scanners.SeverityWarning, Dependency: "tiny", Version: "1.0.0", Message: "tiny has a single maintainer"},
+		},
+		Ignored: []scanners.IgnoredFinding{{
+			Finding:       scanners.Finding{Rule: "vulnerability", Severity: scanners.SeverityError, Dependency: "minimist", Version: "1.2.5", Message: "minimist@1.2.5 is affected"},
+			Advisory:      "GHSA-xvch-5gv4-984h",
+			Justification: "not reachable",
+		}},
+	}
+
+	var buf bytes.Buffer
+	err := WriteJUnit(&buf, []JUnitProject{
+		{Name: "app", Result: result},
+		{Name: "clean", Result: &scanners.ScanResult{}},
+		{Name: "broken", Err: errors.New("no supported project found")},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var doc junitSuites
+	if !assert.NoError(t, xml.Unmarshal(buf.Bytes(), &doc)) {
+		return
+	}
+	assert.Equal(t, 6, doc.Tests)
+	assert.Equal(t, 2, doc.Failures)
+	assert.Equal(t, 1, doc.Errors)
+	assert.Equal(t, 1, doc.Skipped)
+
+	var names []string
+	for _, suite := range doc.Suites {
+		names = append(names, suite.Name)
+	}
+	assert.Equal(t, []string{"app: vulnerabilities", "app: deny left-pad", "app: single-maintainer", "app: vulnerability", "clean", "broken"}, names)
+
+	vuln := doc.Suites[0].Cases[0]
+	assert.Equal(t, "GHSA-35jh-r3h4-6jhm", vuln.Name)
+	assert.Equal(t, "lodash@4.17.20", vuln.ClassName)
+	assert.Equal(t, "lodash@4.17.20 is affected by GHSA-35jh-r3h4-6jhm: Command Injection", vuln.Failure.Message)
+	assert.Equal(t, "Aliases: CVE-2021-23337\nFixed in: 4.17.21", vuln.Failure.Text)
+
+	warning := doc.Suites[2].Cases[0]
+	assert.Nil(t, warning.Failure)
+	assert.Equal(t, "tiny has a single maintainer", warning.SystemOut)
+
+	ignored := doc.Suites[3].Cases[0]
+	assert.Equal(t, "GHSA-xvch-5gv4-984h", ignored.Name)
+	assert.Equal(t, "accepted risk: not reachable", ignored.Skipped.Message)
+
+	assert.Equal(t, []junitCase{{Name: "scan", ClassName: "clean"}}, doc.Suites[4].Cases)
+	assert.Equal(t, "no supported project found", doc.Suites[5].Cases[0].Error.Message)
+}
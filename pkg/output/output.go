@@ -0,0 +1,551 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/age"
+	"github.com/santoshdahal12/deplister/pkg/group"
+	"github.com/santoshdahal12/deplister/pkg/risk"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/size"
+)
+
+type OutputFormat struct {
+	ProjectType  string             `json:"projectType"`
+	Project      *SubjectOutput     `json:"project,omitempty"`
+	Metadata     map[string]string  `json:"metadata,omitempty"`
+	Dependencies []DependencyOutput `json:"dependencies"`
+	Findings     []FindingOutput    `json:"findings,omitempty"`
+	Ignored      []IgnoredOutput    `json:"ignored,omitempty"`
+	Footprint    *FootprintOutput   `json:"footprint,omitempty"`
+	Age          *AgeOutput         `json:"age,omitempty"`
+	Statistics   *StatisticsOutput  `json:"statistics,omitempty"`
+	GroupBy      string             `json:"groupBy,omitempty"`
+	Groups       []GroupOutput      `json:"groups,omitempty"`
+	Errors       []ErrorOutput      `json:"errors,omitempty"`
+	Graph        *GraphOutput       `json:"graph,omitempty"` // Only written by Save
+}
+
+// SubjectOutput is the scanned project itself, the subject of the report
+type SubjectOutput struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// ErrorOutput is a step of the scan that failed, leaving the report partial
+type ErrorOutput struct {
+	Step    string `json:"step"`
+	Message string `json:"message"`
+}
+
+type DependencyOutput struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+	Type        string            `json:"type"`
+	License     string            `json:"license,omitempty"`
+	IsDirectDep bool              `json:"isDirectDependency"`
+	Parent      string            `json:"parent,omitempty"`
+	Properties  map[string]string `json:"properties,omitempty"`
+
+	Vulnerabilities []VulnerabilityOutput `json:"vulnerabilities,omitempty"`
+}
+
+type VulnerabilityOutput struct {
+	ID            string   `json:"id"`
+	Aliases       []string `json:"aliases,omitempty"`
+	Summary       string   `json:"summary,omitempty"`
+	Severity      string   `json:"severity,omitempty"`
+	CVSS          string   `json:"cvss,omitempty"`
+	FixedVersions []string `json:"fixedVersions,omitempty"`
+}
+
+type FindingOutput struct {
+	Rule       string `json:"rule"`
+	Severity   string `json:"severity"`
+	Dependency string `json:"dependency,omitempty"`
+	Version    string `json:"version,omitempty"`
+	Message    string `json:"message"`
+}
+
+type FootprintOutput struct {
+	TotalBytes   int64        `json:"totalBytes"`
+	Dependencies int          `json:"dependencies"`
+	Largest      []SizeOutput `json:"largest"`
+}
+
+type SizeOutput struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Bytes   int64  `json:"bytes"`
+}
+
+type AgeOutput struct {
+	Dependencies int               `json:"dependencies"`
+	Stale        int               `json:"stale"`
+	Behind       int               `json:"behind"`
+	Buckets      []AgeBucketOutput `json:"buckets"`
+}
+
+type AgeBucketOutput struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// StatisticsOutput describes the shape of the dependency graph
+type StatisticsOutput struct {
+	Dependencies int           `json:"dependencies"` // Packages reachable from the project roots
+	Edges        int           `json:"edges"`
+	MaxDepth     int           `json:"maxDepth"`
+	Depths       []DepthOutput `json:"depths"`
+}
+
+type DepthOutput struct {
+	Depth int `json:"depth"`
+	Count int `json:"count"`
+}
+
+type GroupOutput struct {
+	Key          string   `json:"key"`
+	Count        int      `json:"count"`
+	Dependencies []string `json:"dependencies"`
+}
+
+type IgnoredOutput struct {
+	FindingOutput
+	Advisory      string `json:"advisory,omitempty"`
+	Justification string `json:"justification,omitempty"`
+	Expires       string `json:"expires,omitempty"`
+}
+
+// NewOutputFormat converts a scan result into the JSON output document
+func NewOutputFormat(result *scanners.ScanResult, projectType string) OutputFormat {
+	output := OutputFormat{
+		ProjectType:  projectType,
+		Project:      (*SubjectOutput)(result.Project),
+		Metadata:     result.Metadata,
+		Dependencies: make([]DependencyOutput, len(result.Dependencies)),
+	}
+
+	for i, dep := range result.Dependencies {
+		output.Dependencies[i] = DependencyOutput{
+			Name:        dep.Name,
+			Version:     dep.Version,
+			Type:        dep.Type,
+			License:     dep.License,
+			IsDirectDep: dep.IsDirectDep,
+			Parent:      dep.Parent,
+			Properties:  dep.Properties,
+		}
+		for _, vuln := range dep.Vulnerabilities {
+			output.Dependencies[i].Vulnerabilities = append(output.Dependencies[i].Vulnerabilities, VulnerabilityOutput(vuln))
+		}
+	}
+
+	for _, finding := range result.Findings {
+		output.Findings = append(output.Findings, FindingOutput(finding))
+	}
+	for _, ignored := range result.Ignored {
+		output.Ignored = append(output.Ignored, IgnoredOutput{
+			FindingOutput: FindingOutput(ignored.Finding),
+			Advisory:      ignored.Advisory,
+			Justification: ignored.Justification,
+			Expires:       ignored.Expires,
+		})
+	}
+
+	if footprint, ok := size.Summarize(result.Dependencies, size.Top); ok {
+		output.Footprint = &FootprintOutput{TotalBytes: footprint.Total, Dependencies: footprint.Sized}
+		for _, dep := range footprint.Largest {
+			output.Footprint.Largest = append(output.Footprint.Largest, SizeOutput(dep))
+		}
+	}
+	if distribution, ok := age.Summarize(result.Dependencies); ok {
+		output.Age = &AgeOutput{Dependencies: distribution.Dated, Stale: distribution.Stale, Behind: distribution.Behind}
+		for _, bucket := range distribution.Buckets {
+			output.Age.Buckets = append(output.Age.Buckets, AgeBucketOutput(bucket))
+		}
+	}
+	output.Statistics = statistics(result.Graph)
+	for _, scanErr := range result.Errors {
+		output.Errors = append(output.Errors, ErrorOutput(scanErr))
+	}
+
+	return output
+}
+
+// statistics summarizes the graph of a scan, or returns nil for results
+// without one, such as those read back by ReadJSON
+func statistics(graph *scanners.DependencyGraph) *StatisticsOutput {
+	if graph == nil || len(graph.Edges) == 0 {
+		return nil
+	}
+	stats := &StatisticsOutput{MaxDepth: graph.MaxDepth(), Depths: make([]DepthOutput, 0)}
+	for _, children := range graph.Edges {
+		stats.Edges += len(children)
+	}
+	counts := graph.CountByDepth()
+	for depth := 1; depth <= stats.MaxDepth; depth++ {
+		stats.Dependencies += counts[depth]
+		stats.Depths = append(stats.Depths, DepthOutput{Depth: depth, Count: counts[depth]})
+	}
+	return stats
+}
+
+// ReadJSON reads a scan result written by WriteJSON or Save and returns it
+// with its project type. Only Save records the dependency graph, from which
+// paths and depths are recomputed; results written by WriteJSON have none.
+func ReadJSON(reader io.Reader) (*scanners.ScanResult, string, error) {
+	var output OutputFormat
+	if err := json.NewDecoder(reader).Decode(&output); err != nil {
+		return nil, "", err
+	}
+
+	result := &scanners.ScanResult{
+		Project:      (*scanners.Project)(output.Project),
+		Dependencies: make([]scanners.Dependency, len(output.Dependencies)),
+		Metadata:     output.Metadata,
+	}
+	for i, dep := range output.Dependencies {
+		result.Dependencies[i] = scanners.Dependency{
+			Name:        dep.Name,
+			Version:     dep.Version,
+			Type:        dep.Type,
+			License:     dep.License,
+			IsDirectDep: dep.IsDirectDep,
+			Parent:      dep.Parent,
+			Properties:  dep.Properties,
+		}
+		for _, vuln := range dep.Vulnerabilities {
+			result.Dependencies[i].Vulnerabilities = append(result.Dependencies[i].Vulnerabilities, scanners.Vulnerability(vuln))
+		}
+	}
+	for _, finding := range output.Findings {
+		result.Findings = append(result.Findings, scanners.Finding(finding))
+	}
+	for _, scanErr := range output.Errors {
+		result.Errors = append(result.Errors, scanners.ScanError(scanErr))
+	}
+	if output.Graph != nil {
+		result.Graph = output.Graph.restore(result.Dependencies)
+	}
+	return result, output.ProjectType, nil
+}
+
+// WriteJSON writes the scan result as JSON
+func WriteJSON(writer io.Writer, result *scanners.ScanResult, projectType string, pretty bool) error {
+	encoder := json.NewEncoder(writer)
+	if pretty {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(NewOutputFormat(result, projectType))
+}
+
+// NewGroupedOutput converts a scan result into the JSON output document with
+// the dependencies also grouped by one of group.Keys
+func NewGroupedOutput(result *scanners.ScanResult, projectType, by string) (OutputFormat, error) {
+	groups, err := group.By(result.Dependencies, by)
+	if err != nil {
+		return OutputFormat{}, err
+	}
+	output := NewOutputFormat(result, projectType)
+	output.GroupBy = by
+	for _, g := range groups {
+		entry := GroupOutput{Key: g.Key, Count: len(g.Dependencies)}
+		for _, dep := range g.Dependencies {
+			entry.Dependencies = append(entry.Dependencies, dep.Name+"@"+dep.Version)
+		}
+		output.Groups = append(output.Groups, entry)
+	}
+	return output, nil
+}
+
+// WriteGroupedJSON writes the scan result as JSON with grouped dependencies
+func WriteGroupedJSON(writer io.Writer, result *scanners.ScanResult, projectType, by string, pretty bool) error {
+	output, err := NewGroupedOutput(result, projectType, by)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(writer)
+	if pretty {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(output)
+}
+
+// ProjectOutput is the output document of one project of a multi-project
+// scan. Projects that failed to scan only have an error.
+type ProjectOutput struct {
+	Target string `json:"target"`
+	Error  string `json:"error,omitempty"`
+	*OutputFormat
+}
+
+// WriteProjectsJSON writes the output documents of several projects as a JSON array
+func WriteProjectsJSON(writer io.Writer, projects []ProjectOutput, pretty bool) error {
+	encoder := json.NewEncoder(writer)
+	if pretty {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(projects)
+}
+
+// WriteGroupedText writes the scan result in a human-readable format, listing
+// the dependencies by group instead of one by one
+func WriteGroupedText(writer io.Writer, result *scanners.ScanResult, projectType, by string) error {
+	groups, err := group.By(result.Dependencies, by)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(writer, "Project Type: %s\n", projectType)
+	if result.Project != nil {
+		fmt.Fprintf(writer, "Project: %s\n", scanners.NodeKey(result.Project.Name, result.Project.Version))
+	}
+	writeErrors(writer, result)
+	title := fmt.Sprintf("Dependencies by %s:", by)
+	fmt.Fprintln(writer, title)
+	fmt.Fprintln(writer, strings.Repeat("-", len(title)))
+	for _, g := range groups {
+		fmt.Fprintf(writer, "%s (%d)\n", g.Key, len(g.Dependencies))
+		for _, dep := range g.Dependencies {
+			fmt.Fprintf(writer, "  %s@%s\n", dep.Name, dep.Version)
+		}
+	}
+	fmt.Fprintln(writer)
+	return writeSummary(writer, result)
+}
+
+// WriteText writes the scan result in a human-readable format
+func WriteText(writer io.Writer, result *scanners.ScanResult, projectType string) error {
+	fmt.Fprintf(writer, "Project Type: %s\n", projectType)
+	if result.Project != nil {
+		fmt.Fprintf(writer, "Project: %s\n", scanners.NodeKey(result.Project.Name, result.Project.Version))
+	}
+	keys := make([]string, 0, len(result.Metadata))
+	for key := range result.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(writer, "%s: %s\n", key, result.Metadata[key])
+	}
+	writeErrors(writer, result)
+	fmt.Fprintln(writer, "Dependencies:")
+	fmt.Fprintln(writer, "-------------")
+
+	for _, dep := range result.Dependencies {
+		depType := "Production"
+		if t, ok := dep.Properties["dependencyType"]; ok {
+			depType = t
+		}
+
+		directness := "Indirect"
+		if dep.IsDirectDep {
+			directness = "Direct"
+		}
+
+		fmt.Fprintf(writer, "%s@%s (%s, %s)\n", dep.Name, dep.Version, depType, directness)
+
+		if dep.License != "" {
+			fmt.Fprintf(writer, "  License: %s\n", dep.License)
+		}
+
+		if latest, ok := dep.Properties["latest"]; ok {
+			fmt.Fprintf(writer, "  Latest: %s", latest)
+			if update, ok := dep.Properties["update"]; ok {
+				fmt.Fprintf(writer, " (%s update", update)
+				if wanted, ok := dep.Properties["wanted"]; ok && wanted != latest && wanted != dep.Version {
+					fmt.Fprintf(writer, ", wanted %s", wanted)
+				}
+				fmt.Fprint(writer, ")")
+			}
+			fmt.Fprintln(writer)
+		}
+
+		if names, ok := dep.Properties["installScripts"]; ok {
+			fmt.Fprintf(writer, "  Install scripts: %s\n", strings.ReplaceAll(names, ",", ", "))
+			for _, name := range strings.Split(names, ",") {
+				if script, ok := dep.Properties["script."+name]; ok {
+					fmt.Fprintf(writer, "    %s: %s\n", name, script)
+				}
+			}
+		} else if dep.Properties["hasInstallScript"] == "true" {
+			fmt.Fprintln(writer, "  Install scripts: yes")
+		}
+
+		if deprecated, ok := dep.Properties["deprecated"]; ok {
+			fmt.Fprintf(writer, "  Deprecated: %s\n", deprecated)
+		}
+		if retracted, ok := dep.Properties["retracted"]; ok {
+			fmt.Fprintf(writer, "  Retracted: %s\n", retracted)
+		}
+		if bytes, err := strconv.ParseInt(dep.Properties[size.Property], 10, 64); err == nil {
+			fmt.Fprintf(writer, "  Size: %s\n", size.Format(bytes))
+		}
+		if released, ok := dep.Properties[age.Released]; ok {
+			fmt.Fprintf(writer, "  Released: %s (%s days ago", released, dep.Properties[age.DaysSince])
+			if behind, ok := dep.Properties[age.VersionsBehind]; ok && behind != "0" {
+				fmt.Fprintf(writer, ", %s versions behind", behind)
+			}
+			fmt.Fprintln(writer, ")")
+		}
+		if lastRelease, ok := dep.Properties["lastRelease"]; ok {
+			fmt.Fprintf(writer, "  Last release: %s\n", lastRelease)
+		}
+		if repo, ok := dep.Properties["sourceRepo"]; ok {
+			fmt.Fprintf(writer, "  Repository: %s (%s stars, %s open issues)\n", repo, dep.Properties["stars"], dep.Properties["openIssues"])
+		}
+		if score, ok := dep.Properties["scorecard"]; ok {
+			fmt.Fprintf(writer, "  Scorecard: %s/10", score)
+			if maintained, ok := dep.Properties["scorecard.maintained"]; ok {
+				fmt.Fprintf(writer, " (maintained %s/10)", maintained)
+			}
+			fmt.Fprintln(writer)
+		}
+		if status, ok := dep.Properties["provenance"]; ok {
+			fmt.Fprintf(writer, "  Provenance: %s", status)
+			if source, ok := dep.Properties["provenance.source"]; ok {
+				fmt.Fprintf(writer, " (%s)", source)
+			}
+			fmt.Fprintln(writer)
+		}
+		if score, ok := dep.Properties[risk.Score]; ok {
+			fmt.Fprintf(writer, "  Risk: %s/100", score)
+			if factors, ok := dep.Properties[risk.Factors]; ok {
+				fmt.Fprintf(writer, " (%s)", strings.ReplaceAll(factors, ",", ", "))
+			}
+			fmt.Fprintln(writer)
+		}
+
+		if resolved, ok := dep.Properties["resolved"]; ok {
+			fmt.Fprintf(writer, "  Source: %s\n", resolved)
+		}
+
+		if !dep.IsDirectDep && dep.Parent != "" {
+			fmt.Fprintf(writer, "  Required by: %s\n", dep.Parent)
+		}
+		if packages, ok := dep.Properties["packages"]; ok {
+			fmt.Fprintf(writer, "  Packages: %s\n", strings.ReplaceAll(packages, ",", ", "))
+		}
+		if importers, ok := dep.Properties["importedBy"]; ok {
+			fmt.Fprintf(writer, "  Imported by: %s\n", strings.ReplaceAll(importers, ",", ", "))
+		}
+
+		if replacedBy, ok := dep.Properties["replaced_by"]; ok {
+			fmt.Fprintf(writer, "  Replaced by: %s@%s\n", replacedBy, dep.Properties["replaced_version"])
+		}
+
+		for _, vuln := range dep.Vulnerabilities {
+			fmt.Fprintf(writer, "  Vulnerability: %s", vuln.ID)
+			if vuln.Severity != "" {
+				fmt.Fprintf(writer, " [%s]", vuln.Severity)
+			}
+			if vuln.Summary != "" {
+				fmt.Fprintf(writer, " %s", vuln.Summary)
+			}
+			fmt.Fprintln(writer)
+			if len(vuln.FixedVersions) > 0 {
+				fmt.Fprintf(writer, "    Fixed in: %s\n", strings.Join(vuln.FixedVersions, ", "))
+			}
+		}
+		if suppressed, ok := dep.Properties["vex.suppressed"]; ok {
+			fmt.Fprintf(writer, "  Suppressed by VEX: %s\n", strings.ReplaceAll(suppressed, ",", ", "))
+		}
+
+		if _, err := fmt.Fprintln(writer); err != nil {
+			return err
+		}
+	}
+	return writeSummary(writer, result)
+}
+
+// writeSummary writes the findings, ignored findings, footprint and age
+// sections that follow the dependencies
+// writeErrors notes the steps of the scan that failed, so that a partial
+// result is not mistaken for a complete one
+func writeErrors(writer io.Writer, result *scanners.ScanResult) {
+	for _, scanErr := range result.Errors {
+		fmt.Fprintf(writer, "Incomplete: %s\n", scanErr)
+	}
+}
+
+func writeSummary(writer io.Writer, result *scanners.ScanResult) error {
+	if len(result.Findings) > 0 {
+		fmt.Fprintln(writer, "Findings:")
+		fmt.Fprintln(writer, "---------")
+		for _, finding := range result.Findings {
+			if _, err := fmt.Fprintf(writer, "[%s] %s (%s)\n", finding.Severity, finding.Message, finding.Rule); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(result.Ignored) > 0 {
+		if len(result.Findings) > 0 {
+			fmt.Fprintln(writer)
+		}
+		fmt.Fprintln(writer, "Ignored:")
+		fmt.Fprintln(writer, "--------")
+		for _, ignored := range result.Ignored {
+			fmt.Fprintf(writer, "[%s] %s (%s)", ignored.Severity, ignored.Message, ignored.Rule)
+			if ignored.Expires != "" {
+				fmt.Fprintf(writer, " until %s", ignored.Expires)
+			}
+			fmt.Fprintln(writer)
+			if ignored.Justification != "" {
+				if _, err := fmt.Fprintf(writer, "  Justification: %s\n", ignored.Justification); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	footprint, sized := size.Summarize(result.Dependencies, size.Top)
+	if sized {
+		if len(result.Findings) > 0 || len(result.Ignored) > 0 {
+			fmt.Fprintln(writer)
+		}
+		fmt.Fprintln(writer, "Footprint:")
+		fmt.Fprintln(writer, "----------")
+		fmt.Fprintf(writer, "Total: %s across %d dependencies\n", size.Format(footprint.Total), footprint.Sized)
+		for _, dep := range footprint.Largest {
+			if _, err := fmt.Fprintf(writer, "  %-10s %s@%s\n", size.Format(dep.Bytes), dep.Name, dep.Version); err != nil {
+				return err
+			}
+		}
+	}
+
+	distribution, aged := age.Summarize(result.Dependencies)
+	if aged {
+		if len(result.Findings) > 0 || len(result.Ignored) > 0 || sized {
+			fmt.Fprintln(writer)
+		}
+		fmt.Fprintln(writer, "Age:")
+		fmt.Fprintln(writer, "----")
+		for _, bucket := range distribution.Buckets {
+			fmt.Fprintf(writer, "  %-12s %d\n", bucket.Label, bucket.Count)
+		}
+		fmt.Fprintf(writer, "%d of %d dependencies were released more than 2 years ago, %d have newer releases\n", distribution.Stale, distribution.Dated, distribution.Behind)
+	}
+
+	if stats := statistics(result.Graph); stats != nil {
+		if len(result.Findings) > 0 || len(result.Ignored) > 0 || sized || aged {
+			fmt.Fprintln(writer)
+		}
+		fmt.Fprintln(writer, "Graph:")
+		fmt.Fprintln(writer, "------")
+		fmt.Fprintf(writer, "%d dependencies, %d edges, up to %d levels deep\n", stats.Dependencies, stats.Edges, stats.MaxDepth)
+		for _, depth := range stats.Depths {
+			if _, err := fmt.Fprintf(writer, "  depth %-6d %d\n", depth.Depth, depth.Count); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
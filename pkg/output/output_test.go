@@ -0,0 +1,241 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testResult() *scanners.ScanResult {
+	return &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{
+				Name:        "express",
+				Version:     "4.17.1",
+				Type:        "npm",
+				License:     "MIT",
+				IsDirectDep: true,
+				Properties:  map[string]string{"dependencyType": "production", "resolved": "https://registry.npmjs.org/express/-/express-4.17.1.tgz", "installScripts": "preinstall,postinstall", "script.postinstall": "node setup.js", "provenance": "attested", "provenance.source": "https://github.com/expressjs/express"},
+				Vulnerabilities: []scanners.Vulnerability{
+					{ID: "GHSA-rv95-896h-c2vc", Summary: "Express.js Open Redirect in malformed URLs", Severity: "MEDIUM", FixedVersions: []string{"4.19.2"}},
+				},
+			},
+			{
+				Name:       "accepts",
+				Version:    "1.3.7",
+				Type:       "npm",
+				Parent:     "express",
+				Properties: map[string]string{"latest": "2.0.0", "wanted": "1.3.8", "update": "major", "deprecated": "use accepts@2", "sourceRepo": "github.com/jshttp/accepts", "stars": "250", "openIssues": "3", "scorecard": "4.5", "scorecard.maintained": "0", "lastRelease": "2019-03-01", "vex.suppressed": "GHSA-a,GHSA-b"},
+			},
+		},
+		Findings: []scanners.Finding{
+			{Rule: "deny accepts <1.3.8", Severity: scanners.SeverityError, Dependency: "accepts", Version: "1.3.7", Message: "accepts@1.3.7 is denied by policy"},
+		},
+		Ignored: []scanners.IgnoredFinding{
+			{
+				Finding:       scanners.Finding{Rule: "vulnerability", Severity: "high", Dependency: "express", Version: "4.17.1", Message: "GHSA-qw6h-vgh9-j6wx express vulnerable to XSS"},
+				Advisory:      "GHSA-qw6h-vgh9-j6wx",
+				Justification: "redirects are not user controlled",
+				Expires:       "2025-01-31",
+			},
+		},
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteJSON(&buf, testResult(), "npm", false))
+
+	var out OutputFormat
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	assert.Equal(t, "npm", out.ProjectType)
+	assert.Nil(t, out.Project)
+	assert.Len(t, out.Dependencies, 2)
+	assert.Equal(t, "express", out.Dependencies[1].Parent)
+	assert.Equal(t, "MIT", out.Dependencies[0].License)
+	assert.Equal(t, []VulnerabilityOutput{
+		{ID: "GHSA-rv95-896h-c2vc", Summary: "Express.js Open Redirect in malformed URLs", Severity: "MEDIUM", FixedVersions: []string{"4.19.2"}},
+	}, out.Dependencies[0].Vulnerabilities)
+	assert.Empty(t, out.Dependencies[1].Vulnerabilities)
+	assert.Equal(t, []FindingOutput{
+		{Rule: "deny accepts <1.3.8", Severity: "error", Dependency: "accepts", Version: "1.3.7", Message: "accepts@1.3.7 is denied by policy"},
+	}, out.Findings)
+	assert.Equal(t, []IgnoredOutput{{
+		FindingOutput: FindingOutput{Rule: "vulnerability", Severity: "high", Dependency: "express", Version: "4.17.1", Message: "GHSA-qw6h-vgh9-j6wx express vulnerable to XSS"},
+		Advisory:      "GHSA-qw6h-vgh9-j6wx",
+		Justification: "redirects are not user controlled",
+		Expires:       "2025-01-31",
+	}}, out.Ignored)
+}
+
+func TestWriteText(t *testing.T) {
+	result := testResult()
+	result.Project = &scanners.Project{Name: "my-app", Version: "1.2.0"}
+	result.Errors = []scanners.ScanError{{Step: "go mod graph", Message: "exit status 1"}}
+	var buf bytes.Buffer
+	assert.NoError(t, WriteText(&buf, result, "npm"))
+
+	text := buf.String()
+	assert.Contains(t, text, "Project Type: npm\nProject: my-app@1.2.0\nIncomplete: go mod graph: exit status 1\n")
+	assert.Contains(t, text, "express@4.17.1 (production, Direct)")
+	assert.Contains(t, text, "  License: MIT")
+	assert.Contains(t, text, "  Source: https://registry.npmjs.org/express/-/express-4.17.1.tgz")
+	assert.Contains(t, text, "accepts@1.3.7 (Production, Indirect)")
+	assert.Contains(t, text, "  Required by: express")
+	assert.Contains(t, text, "  Latest: 2.0.0 (major update, wanted 1.3.8)")
+	assert.Contains(t, text, "  Deprecated: use accepts@2")
+	assert.Contains(t, text, "  Suppressed by VEX: GHSA-a, GHSA-b\n")
+	assert.Contains(t, text, "  Last release: 2019-03-01\n")
+	assert.Contains(t, text, "  Repository: github.com/jshttp/accepts (250 stars, 3 open issues)\n  Scorecard: 4.5/10 (maintained 0/10)\n")
+	assert.Contains(t, text, "  Provenance: attested (https://github.com/expressjs/express)")
+	assert.Contains(t, text, "  Install scripts: preinstall, postinstall\n    postinstall: node setup.js\n")
+	assert.Contains(t, text, "  Vulnerability: GHSA-rv95-896h-c2vc [MEDIUM] Express.js Open Redirect in malformed URLs\n    Fixed in: 4.19.2")
+	assert.Contains(t, text, "Findings:\n---------\n[error] accepts@1.3.7 is denied by policy (deny accepts <1.3.8)\n")
+	assert.Contains(t, text, "Ignored:\n--------\n[high] GHSA-qw6h-vgh9-j6wx express vulnerable to XSS (vulnerability) until 2025-01-31\n  Justification: redirects are not user controlled\n")
+}
+
+func TestFootprint(t *testing.T) {
+	result := testResult()
+	result.Dependencies[0].Properties["size"] = "2097152"
+
+	out := NewOutputFormat(result, "npm")
+	assert.Equal(t, &FootprintOutput{
+		TotalBytes:   2097152,
+		Dependencies: 1,
+		Largest:      []SizeOutput{{Name: "express", Version: "4.17.1", Bytes: 2097152}},
+	}, out.Footprint)
+	assert.Nil(t, NewOutputFormat(testResult(), "npm").Footprint)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteText(&buf, result, "npm"))
+	text := buf.String()
+	assert.Contains(t, text, "  Size: 2.0 MiB\n")
+	assert.Contains(t, text, "\nFootprint:\n----------\nTotal: 2.0 MiB across 1 dependencies\n  2.0 MiB    express@4.17.1\n")
+}
+
+func TestAge(t *testing.T) {
+	result := testResult()
+	result.Dependencies[0].Properties["released"] = "2019-05-26"
+	result.Dependencies[0].Properties["daysSinceRelease"] = "1833"
+	result.Dependencies[0].Properties["versionsBehind"] = "4"
+
+	out := NewOutputFormat(result, "npm")
+	assert.Equal(t, &AgeOutput{
+		Dependencies: 1,
+		Stale:        1,
+		Behind:       1,
+		Buckets: []AgeBucketOutput{
+			{Label: "< 6 months"}, {Label: "6-12 months"}, {Label: "1-2 years"}, {Label: "> 2 years", Count: 1},
+		},
+	}, out.Age)
+	assert.Nil(t, NewOutputFormat(testResult(), "npm").Age)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteText(&buf, result, "npm"))
+	text := buf.String()
+	assert.Contains(t, text, "  Released: 2019-05-26 (1833 days ago, 4 versions behind)\n")
+	assert.Contains(t, text, "\nAge:\n----\n  < 6 months   0\n  6-12 months  0\n  1-2 years    0\n  > 2 years    1\n1 of 1 dependencies were released more than 2 years ago, 1 have newer releases\n")
+}
+
+func TestStatistics(t *testing.T) {
+	result := testResult()
+	result.Graph = &scanners.DependencyGraph{Edges: map[string][]string{
+		"":               {"express@4.17.1"},
+		"express@4.17.1": {"accepts@1.3.7"},
+	}}
+	out := NewOutputFormat(result, "npm")
+	assert.Equal(t, &StatisticsOutput{
+		Dependencies: 2,
+		Edges:        2,
+		MaxDepth:     2,
+		Depths:       []DepthOutput{{Depth: 1, Count: 1}, {Depth: 2, Count: 1}},
+	}, out.Statistics)
+	assert.Nil(t, NewOutputFormat(testResult(), "npm").Statistics)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteText(&buf, result, "npm"))
+	assert.Contains(t, buf.String(), "\nGraph:\n------\n2 dependencies, 2 edges, up to 2 levels deep\n  depth 1      1\n  depth 2      1\n")
+}
+
+func TestGroupedOutput(t *testing.T) {
+	result := testResult()
+
+	out, err := NewGroupedOutput(result, "npm", "license")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "license", out.GroupBy)
+	assert.Equal(t, []GroupOutput{
+		{Key: "MIT", Count: 1, Dependencies: []string{"express@4.17.1"}},
+		{Key: "(none)", Count: 1, Dependencies: []string{"accepts@1.3.7"}},
+	}, out.Groups)
+	assert.Len(t, out.Dependencies, 2)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteGroupedText(&buf, result, "npm", "license"))
+	text := buf.String()
+	assert.Contains(t, text, "Dependencies by license:\n------------------------\nMIT (1)\n  express@4.17.1\n(none) (1)\n  accepts@1.3.7\n\nFindings:\n")
+
+	_, err = NewGroupedOutput(result, "npm", "color")
+	assert.Error(t, err)
+}
+
+func TestWriteProjectsJSON(t *testing.T) {
+	out := NewOutputFormat(testResult(), "npm")
+	projects := []ProjectOutput{
+		{Target: "/src/web", OutputFormat: &out},
+		{Target: "/src/empty", Error: "no supported project found"},
+	}
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, WriteProjectsJSON(&buf, projects, false)) {
+		return
+	}
+	var decoded []map[string]any
+	if !assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded)) {
+		return
+	}
+	assert.Len(t, decoded, 2)
+	assert.Equal(t, "/src/web", decoded[0]["target"])
+	assert.Equal(t, "npm", decoded[0]["projectType"])
+	assert.NotContains(t, decoded[0], "error")
+	assert.Equal(t, map[string]any{"target": "/src/empty", "error": "no supported project found"}, decoded[1])
+}
+
+func TestReadJSON(t *testing.T) {
+	result := testResult()
+	result.Project = &scanners.Project{Name: "my-app", Version: "1.2.0"}
+	result.Metadata = map[string]string{"lockfileVersion": "3"}
+	result.Errors = []scanners.ScanError{{Step: "go mod graph", Message: "exit status 1"}}
+
+	var buf bytes.Buffer
+	if !assert.NoError(t, WriteJSON(&buf, result, "npm", false)) {
+		return
+	}
+	read, projectType, err := ReadJSON(&buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "npm", projectType)
+	assert.Equal(t, result.Project, read.Project)
+	assert.Equal(t, result.Metadata, read.Metadata)
+	assert.Equal(t, result.Errors, read.Errors)
+	assert.Equal(t, result.Findings, read.Findings)
+	if !assert.Len(t, read.Dependencies, len(result.Dependencies)) {
+		return
+	}
+	for i, dep := range result.Dependencies {
+		assert.Equal(t, dep.Name, read.Dependencies[i].Name)
+		assert.Equal(t, dep.Version, read.Dependencies[i].Version)
+		assert.Equal(t, dep.License, read.Dependencies[i].License)
+		assert.Equal(t, dep.Properties, read.Dependencies[i].Properties)
+		assert.Equal(t, dep.Vulnerabilities, read.Dependencies[i].Vulnerabilities)
+	}
+
+	_, _, err = ReadJSON(bytes.NewReader([]byte("[")))
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/purl"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// SWIDNamespace is the XML namespace of ISO/IEC 19770-2:2015 SWID tags
+const SWIDNamespace = "http://standards.iso.org/iso/19770/-2/2015/schema.xsd"
+
+// swidUnknownRegID is the registration ID NIST IR 8060 reserves for entities
+// without a domain of their own, such as a tag creator run by anyone
+const swidUnknownRegID = "invalid.unavailable"
+
+// SWIDTag is the SWID tag of a package or of the scanned project, and the
+// name of the file it is written to
+type SWIDTag struct {
+	FileName string
+	Identity SoftwareIdentity
+}
+
+// SoftwareIdentity is the root element of a SWID tag
+type SoftwareIdentity struct {
+	XMLName       xml.Name     `xml:"SoftwareIdentity"`
+	Namespace     string       `xml:"xmlns,attr"`
+	Name          string       `xml:"name,attr"`
+	TagID         string       `xml:"tagId,attr"`
+	TagVersion    int          `xml:"tagVersion,attr"`
+	Version       string       `xml:"version,attr,omitempty"`
+	VersionScheme string       `xml:"versionScheme,attr,omitempty"`
+	Entities      []SWIDEntity `xml:"Entity"`
+	Links         []SWIDLink   `xml:"Link"`
+}
+
+// SWIDEntity is an organization or tool with a role in the tag
+type SWIDEntity struct {
+	Name  string `xml:"name,attr"`
+	RegID string `xml:"regid,attr"`
+	Role  string `xml:"role,attr"`
+}
+
+// SWIDLink points at a related tag or resource
+type SWIDLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+// SWIDTags returns a tag for every dependency of a scan, identified by its
+// package URL. When the project is named, its primary tag links to the
+// dependency tags as components. Packages have no known software creator, so
+// the tags only name deplister as their creator.
+func SWIDTags(result *scanners.ScanResult, projectType string) []SWIDTag {
+	var tags []SWIDTag
+	seen := make(map[string]bool)
+	var links []SWIDLink
+	for _, dep := range result.Dependencies {
+		id := purl.For(dep)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		tags = append(tags, SWIDTag{FileName: swidFileName(id), Identity: newSoftwareIdentity(dep.Name, dep.Version, id, dep.Type)})
+		links = append(links, SWIDLink{Rel: "component", Href: "swid:" + id})
+	}
+
+	if result.Project != nil && result.Project.Name != "" {
+		id := purl.New(projectType, result.Project.Name, result.Project.Version)
+		identity := newSoftwareIdentity(result.Project.Name, result.Project.Version, id, projectType)
+		identity.Links = links
+		tags = append([]SWIDTag{{FileName: swidFileName(id), Identity: identity}}, tags...)
+	}
+	return tags
+}
+
+func newSoftwareIdentity(name, version, tagID, depType string) SoftwareIdentity {
+	identity := SoftwareIdentity{
+		Namespace:  SWIDNamespace,
+		Name:       name,
+		TagID:      tagID,
+		TagVersion: 1,
+		Version:    version,
+		Entities:   []SWIDEntity{{Name: "deplister", RegID: swidUnknownRegID, Role: "tagCreator"}},
+	}
+	if version != "" {
+		// npm versions are semantic versions; Go ones add a v prefix
+		identity.VersionScheme = "unknown"
+		if depType == "npm" {
+			identity.VersionScheme = "semver"
+		}
+	}
+	return identity
+}
+
+// swidFileName names the file of a tag after its ID, replacing the
+// characters file systems may not accept
+func swidFileName(tagID string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, tagID)
+	return name + ".swidtag"
+}
+
+// WriteSWIDTag writes a SWID tag as an XML document
+func WriteSWIDTag(writer io.Writer, tag SWIDTag) error {
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(tag.Identity); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(writer)
+	return err
+}
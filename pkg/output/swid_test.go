@@ -0,0 +1,45 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSWIDTags(t *testing.T) {
+	result := &scanners.ScanResult{
+		Project: &scanners.Project{Name: "app", Version: "1.0.0"},
+		Dependencies: []scanners.Dependency{
+			{Name: "@babel/core", Version: "7.24.0", Type: "npm"},
+			{Name: "golang.org/x/net", Version: "v0.30.0", Type: "go"},
+			{Name: "@babel/core", Version: "7.24.0", Type: "npm"},
+		},
+	}
+
+	tags := SWIDTags(result, "npm")
+	if !assert.Len(t, tags, 3) {
+		return
+	}
+	assert.Equal(t, "pkg_npm_app_1.0.0.swidtag", tags[0].FileName)
+	assert.Equal(t, []SWIDLink{
+		{Rel: "component", Href: "swid:pkg:npm/%40babel/core@7.24.0"},
+		{Rel: "component", Href: "swid:pkg:golang/golang.org/x/net@v0.30.0"},
+	}, tags[0].Identity.Links)
+	assert.Equal(t, "pkg_npm__40babel_core_7.24.0.swidtag", tags[1].FileName)
+	assert.Equal(t, "unknown", tags[2].Identity.VersionScheme)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteSWIDTag(&buf, tags[1]))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<SoftwareIdentity xmlns="http://standards.iso.org/iso/19770/-2/2015/schema.xsd" name="@babel/core" tagId="pkg:npm/%40babel/core@7.24.0" tagVersion="1" version="7.24.0" versionScheme="semver">
+  <Entity name="deplister" regid="invalid.unavailable" role="tagCreator"></Entity>
+</SoftwareIdentity>
+`, buf.String())
+
+	// Without a project name there is no primary tag
+	result.Project = nil
+	assert.Len(t, SWIDTags(result, "npm"), 2)
+}
@@ -0,0 +1,308 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Visualization formats of WriteGraph
+const (
+	Dot     = "dot"     // Graphviz
+	Mermaid = "mermaid" // Mermaid flowchart
+	Tree    = "tree"    // Indented text, like npm ls
+)
+
+// GraphFormats lists the visualization formats
+var GraphFormats = []string{Dot, Mermaid, Tree}
+
+// GraphOptions cuts a dependency graph down to what a visualization should
+// show. Focus applies first, then CollapseTransitive, then MaxNodes.
+type GraphOptions struct {
+	Focus              []string // Node keys; only the packages on paths from the roots to them are kept
+	MaxNodes           int      // Dependencies to keep at most, nearest to the roots first; 0 for all
+	CollapseTransitive bool     // Keep only the direct dependencies, counting the transitive ones below each
+}
+
+// PrunedGraph is the part of a dependency graph a visualization shows
+type PrunedGraph struct {
+	Roots   []string
+	Edges   map[string][]string // Sorted children of every kept node with any
+	Hidden  map[string]int      // Transitive dependencies collapsed below a direct dependency
+	Omitted int                 // Dependencies left out by MaxNodes
+}
+
+// Prune returns the part of the graph reachable from its roots that the
+// options keep
+func Prune(graph *scanners.DependencyGraph, opts GraphOptions) *PrunedGraph {
+	roots := graph.Roots()
+	// Edges may lead to packages the scan left out, such as development ones
+	known := make(map[string]bool, len(graph.Nodes)+len(roots))
+	for key := range graph.Nodes {
+		known[key] = true
+	}
+	for _, root := range roots {
+		known[root] = true
+	}
+	edges := restrict(graph.Edges, known)
+	keep := reachable(edges, roots, nil)
+
+	if len(opts.Focus) > 0 {
+		reaching := make(map[string]bool)
+		queue := slices.Clone(opts.Focus)
+		for _, key := range queue {
+			reaching[key] = true
+		}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, parent := range graph.Parents(current) {
+				if !reaching[parent] {
+					reaching[parent] = true
+					queue = append(queue, parent)
+				}
+			}
+		}
+		for key := range keep {
+			if !reaching[key] {
+				delete(keep, key)
+			}
+		}
+		roots = slices.DeleteFunc(roots, func(root string) bool { return !keep[root] })
+	}
+
+	pruned := &PrunedGraph{Roots: roots, Hidden: make(map[string]int)}
+	edges = restrict(edges, keep)
+
+	if opts.CollapseTransitive {
+		shown := make(map[string]bool)
+		for _, root := range roots {
+			shown[root] = true
+			for _, child := range edges[root] {
+				shown[child] = true
+			}
+		}
+		for key := range shown {
+			if slices.Contains(roots, key) {
+				continue
+			}
+			below := reachable(edges, []string{key}, shown)
+			if len(below) > 1 {
+				pruned.Hidden[key] = len(below) - 1
+			}
+		}
+		keep = shown
+		edges = restrict(edges, keep)
+	}
+
+	if opts.MaxNodes > 0 {
+		order := breadthFirst(edges, roots)
+		if dependencies := len(order) - len(roots); dependencies > opts.MaxNodes {
+			pruned.Omitted = dependencies - opts.MaxNodes
+			keep = make(map[string]bool)
+			for _, key := range order[:len(roots)+opts.MaxNodes] {
+				keep[key] = true
+			}
+			edges = restrict(edges, keep)
+			for key := range pruned.Hidden {
+				if !keep[key] {
+					delete(pruned.Hidden, key)
+				}
+			}
+		}
+	}
+
+	pruned.Edges = edges
+	return pruned
+}
+
+// reachable returns the keys reachable from the start keys, including them,
+// without walking past the keys in stop other than the start keys
+func reachable(edges map[string][]string, start []string, stop map[string]bool) map[string]bool {
+	seen := make(map[string]bool)
+	queue := slices.Clone(start)
+	for _, key := range queue {
+		seen[key] = true
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range edges[current] {
+			if !seen[child] && !stop[child] {
+				seen[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	return seen
+}
+
+// restrict returns the sorted edges between the kept keys
+func restrict(edges map[string][]string, keep map[string]bool) map[string][]string {
+	restricted := make(map[string][]string)
+	for parent, children := range edges {
+		if !keep[parent] {
+			continue
+		}
+		for _, child := range children {
+			if keep[child] {
+				restricted[parent] = append(restricted[parent], child)
+			}
+		}
+		if list := restricted[parent]; list != nil {
+			slices.Sort(list)
+			restricted[parent] = slices.Compact(list)
+		}
+	}
+	return restricted
+}
+
+// breadthFirst returns the keys reachable from the roots, the roots first
+// and then by depth, visiting children by key
+func breadthFirst(edges map[string][]string, roots []string) []string {
+	order := slices.Clone(roots)
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		seen[root] = true
+	}
+	for i := 0; i < len(order); i++ {
+		for _, child := range edges[order[i]] {
+			if !seen[child] {
+				seen[child] = true
+				order = append(order, child)
+			}
+		}
+	}
+	return order
+}
+
+// WriteGraph prunes the dependency graph of a scan and writes it in one of
+// the GraphFormats
+func WriteGraph(writer io.Writer, result *scanners.ScanResult, format string, opts GraphOptions) error {
+	if result.Graph == nil {
+		return fmt.Errorf("the scan has no dependency graph")
+	}
+	pruned := Prune(result.Graph, opts)
+	label := func(key string) string {
+		if slices.Contains(pruned.Roots, key) {
+			return result.DisplayPath([]string{key})[0]
+		}
+		return key
+	}
+
+	w := bufio.NewWriter(writer)
+	switch format {
+	case Dot:
+		writeDot(w, pruned, label)
+	case Mermaid:
+		writeMermaid(w, pruned, label)
+	case Tree:
+		writeTree(w, pruned, label)
+	default:
+		return fmt.Errorf("unknown graph format %q, expected one of %s", format, strings.Join(GraphFormats, ", "))
+	}
+	return w.Flush()
+}
+
+// nodes returns every key of the pruned graph, roots first and then by key
+func (p *PrunedGraph) nodes() []string {
+	var keys []string
+	for parent, children := range p.Edges {
+		keys = append(keys, parent)
+		keys = append(keys, children...)
+	}
+	keys = slices.DeleteFunc(keys, func(key string) bool { return slices.Contains(p.Roots, key) })
+	sort.Strings(keys)
+	return append(slices.Clone(p.Roots), slices.Compact(keys)...)
+}
+
+func writeDot(w io.Writer, pruned *PrunedGraph, label func(string) string) {
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+	}
+	fmt.Fprintln(w, "digraph dependencies {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box];")
+	for _, key := range pruned.nodes() {
+		text := label(key)
+		if hidden := pruned.Hidden[key]; hidden > 0 {
+			text += fmt.Sprintf("\n+%d transitive", hidden)
+		}
+		attrs := "label=" + quote(text)
+		if slices.Contains(pruned.Roots, key) {
+			attrs += ", style=bold"
+		}
+		fmt.Fprintf(w, "  %s [%s];\n", quote(key), attrs)
+	}
+	for _, parent := range pruned.nodes() {
+		for _, child := range pruned.Edges[parent] {
+			fmt.Fprintf(w, "  %s -> %s;\n", quote(parent), quote(child))
+		}
+	}
+	if pruned.Omitted > 0 {
+		fmt.Fprintf(w, "  omitted [label=%s, shape=plaintext];\n", quote(fmt.Sprintf("%d more dependencies", pruned.Omitted)))
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func writeMermaid(w io.Writer, pruned *PrunedGraph, label func(string) string) {
+	escape := strings.NewReplacer(`"`, "#quot;")
+	ids := make(map[string]string)
+	fmt.Fprintln(w, "graph LR")
+	for i, key := range pruned.nodes() {
+		ids[key] = fmt.Sprintf("n%d", i)
+		text := escape.Replace(label(key))
+		if hidden := pruned.Hidden[key]; hidden > 0 {
+			text += fmt.Sprintf("<br/>+%d transitive", hidden)
+		}
+		fmt.Fprintf(w, "  %s[\"%s\"]\n", ids[key], text)
+	}
+	for _, parent := range pruned.nodes() {
+		for _, child := range pruned.Edges[parent] {
+			fmt.Fprintf(w, "  %s --> %s\n", ids[parent], ids[child])
+		}
+	}
+	if pruned.Omitted > 0 {
+		fmt.Fprintf(w, "  omitted[/\"%d more dependencies\"/]\n", pruned.Omitted)
+	}
+}
+
+// writeTree prints every root with its dependencies below it, expanding
+// each package once and marking its later occurrences as deduped
+func writeTree(w io.Writer, pruned *PrunedGraph, label func(string) string) {
+	expanded := make(map[string]bool)
+	var visit func(key, prefix string)
+	visit = func(key, prefix string) {
+		children := pruned.Edges[key]
+		for i, child := range children {
+			branch, indent := "├── ", "│   "
+			if i == len(children)-1 {
+				branch, indent = "└── ", "    "
+			}
+			line := label(child)
+			if hidden := pruned.Hidden[child]; hidden > 0 {
+				line += fmt.Sprintf(" (+%d transitive)", hidden)
+			}
+			if expanded[child] && len(pruned.Edges[child]) > 0 {
+				fmt.Fprintf(w, "%s%s%s (deduped)\n", prefix, branch, line)
+				continue
+			}
+			fmt.Fprintf(w, "%s%s%s\n", prefix, branch, line)
+			expanded[child] = true
+			visit(child, prefix+indent)
+		}
+	}
+	for _, root := range pruned.Roots {
+		fmt.Fprintln(w, label(root))
+		expanded[root] = true
+		visit(root, "")
+	}
+	if pruned.Omitted > 0 {
+		fmt.Fprintf(w, "(%d more dependencies omitted)\n", pruned.Omitted)
+	}
+}
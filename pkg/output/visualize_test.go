@@ -0,0 +1,132 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// visualizeResult is app -> express -> {accepts -> mime, debug -> ms}, app ->
+// lodash, and a development dependency jest the scan left out
+func visualizeResult() *scanners.ScanResult {
+	deps := []scanners.Dependency{
+		{Name: "express", Version: "4.17.1"},
+		{Name: "accepts", Version: "1.3.7"},
+		{Name: "mime", Version: "1.6.0"},
+		{Name: "debug", Version: "2.6.9"},
+		{Name: "ms", Version: "2.0.0"},
+		{Name: "lodash", Version: "4.17.21"},
+	}
+	nodes := make(map[string]*scanners.Dependency)
+	for i := range deps {
+		nodes[scanners.NodeKey(deps[i].Name, deps[i].Version)] = &deps[i]
+	}
+	return &scanners.ScanResult{
+		Project:      &scanners.Project{Name: "app"},
+		Dependencies: deps,
+		Graph: &scanners.DependencyGraph{
+			Nodes: nodes,
+			Edges: map[string][]string{
+				"":               {"express@4.17.1", "lodash@4.17.21", "jest@29.7.0"},
+				"express@4.17.1": {"debug@2.6.9", "accepts@1.3.7"},
+				"accepts@1.3.7":  {"mime@1.6.0"},
+				"debug@2.6.9":    {"ms@2.0.0"},
+				"jest@29.7.0":    {"ms@2.0.0"},
+			},
+		},
+	}
+}
+
+func TestPrune(t *testing.T) {
+	graph := visualizeResult().Graph
+
+	all := Prune(graph, GraphOptions{})
+	assert.Equal(t, []string{""}, all.Roots)
+	assert.Equal(t, map[string][]string{
+		"":               {"express@4.17.1", "lodash@4.17.21"},
+		"express@4.17.1": {"accepts@1.3.7", "debug@2.6.9"},
+		"accepts@1.3.7":  {"mime@1.6.0"},
+		"debug@2.6.9":    {"ms@2.0.0"},
+	}, all.Edges)
+
+	focused := Prune(graph, GraphOptions{Focus: []string{"ms@2.0.0"}})
+	assert.Equal(t, map[string][]string{
+		"":               {"express@4.17.1"},
+		"express@4.17.1": {"debug@2.6.9"},
+		"debug@2.6.9":    {"ms@2.0.0"},
+	}, focused.Edges)
+
+	collapsed := Prune(graph, GraphOptions{CollapseTransitive: true})
+	assert.Equal(t, map[string][]string{"": {"express@4.17.1", "lodash@4.17.21"}}, collapsed.Edges)
+	assert.Equal(t, map[string]int{"express@4.17.1": 4}, collapsed.Hidden)
+
+	capped := Prune(graph, GraphOptions{MaxNodes: 3})
+	assert.Equal(t, map[string][]string{
+		"":               {"express@4.17.1", "lodash@4.17.21"},
+		"express@4.17.1": {"accepts@1.3.7"},
+	}, capped.Edges)
+	assert.Equal(t, 3, capped.Omitted)
+}
+
+func TestWriteGraph(t *testing.T) {
+	result := visualizeResult()
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteGraph(&buf, result, Tree, GraphOptions{}))
+	assert.Equal(t, `app
+├── express@4.17.1
+│   ├── accepts@1.3.7
+│   │   └── mime@1.6.0
+│   └── debug@2.6.9
+│       └── ms@2.0.0
+└── lodash@4.17.21
+`, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, WriteGraph(&buf, result, Tree, GraphOptions{CollapseTransitive: true, MaxNodes: 1}))
+	assert.Equal(t, "app\n└── express@4.17.1 (+4 transitive)\n(1 more dependencies omitted)\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, WriteGraph(&buf, result, Dot, GraphOptions{CollapseTransitive: true}))
+	assert.Equal(t, `digraph dependencies {
+  rankdir=LR;
+  node [shape=box];
+  "" [label="app", style=bold];
+  "express@4.17.1" [label="express@4.17.1\n+4 transitive"];
+  "lodash@4.17.21" [label="lodash@4.17.21"];
+  "" -> "express@4.17.1";
+  "" -> "lodash@4.17.21";
+}
+`, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, WriteGraph(&buf, result, Mermaid, GraphOptions{Focus: []string{"mime@1.6.0"}}))
+	assert.Equal(t, `graph LR
+  n0["app"]
+  n1["accepts@1.3.7"]
+  n2["express@4.17.1"]
+  n3["mime@1.6.0"]
+  n0 --> n2
+  n1 --> n3
+  n2 --> n1
+`, buf.String())
+
+	assert.Error(t, WriteGraph(&buf, result, "svg", GraphOptions{}))
+}
+
+func TestWriteGraph_Cycle(t *testing.T) {
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{{Name: "a", Version: "1.0.0"}, {Name: "b", Version: "1.0.0"}},
+	}
+	result.Graph = &scanners.DependencyGraph{
+		Nodes: map[string]*scanners.Dependency{"a@1.0.0": &result.Dependencies[0], "b@1.0.0": &result.Dependencies[1]},
+		Edges: map[string][]string{"": {"a@1.0.0"}, "a@1.0.0": {"b@1.0.0"}, "b@1.0.0": {"a@1.0.0"}},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteGraph(&buf, result, Tree, GraphOptions{}))
+	assert.Equal(t, "(project)\n└── a@1.0.0\n    └── b@1.0.0\n        └── a@1.0.0 (deduped)\n", buf.String())
+}
@@ -0,0 +1,139 @@
+package peers
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/scanners/npm"
+	"github.com/santoshdahal12/deplister/pkg/version"
+)
+
+// Enrichment is the name of the peer dependency check in ScanOptions.Enrich
+const Enrichment = "peers"
+
+// Rule is the rule name of peer dependency findings
+const Rule = "peer-dependency"
+
+// Problem is a peer dependency an installed package declares that the
+// installation does not satisfy
+type Problem struct {
+	Dependent string   // Node key of the package declaring the peer dependency
+	Name      string   // Peer package
+	Requires  string   // Declared range
+	Installed string   // Version the dependent loads, "" when the peer is missing
+	Chain     []string // Node keys from the project to the dependent
+}
+
+// Find checks the peer dependencies recorded in the npm v2 or v3 lockfile in
+// fsys against the versions each package loads. Only packages in the graph
+// are checked, so dependencies the scan left out are not reported. Legacy
+// lockfiles do not record peer dependencies and have no problems.
+func Find(fsys fs.FS, graph *scanners.DependencyGraph) ([]Problem, error) {
+	lock, err := npm.ReadLockfile(fsys)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(lock.Packages))
+	for pkgPath := range lock.Packages {
+		paths = append(paths, pkgPath)
+	}
+	sort.Strings(paths)
+
+	var problems []Problem
+	type peer struct{ dependent, name, installed string }
+	seen := make(map[peer]bool)
+	for _, pkgPath := range paths {
+		pkg := lock.Packages[pkgPath]
+		if pkgPath == "" || pkg.Link || len(pkg.PeerDependencies) == 0 {
+			continue
+		}
+		key := scanners.NodeKey(npm.PackageName(pkgPath), pkg.Version)
+		node, ok := graph.Nodes[key]
+		if !ok {
+			continue
+		}
+
+		names := make([]string, 0, len(pkg.PeerDependencies))
+		for name := range pkg.PeerDependencies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			problem := Problem{Dependent: key, Name: name, Requires: pkg.PeerDependencies[name]}
+			installed, ok := npm.ResolvePackage(lock.Packages, pkgPath, name)
+			switch {
+			case !ok && pkg.PeerDependenciesMeta[name].Optional:
+				continue
+			case ok:
+				problem.Installed = lock.Packages[installed].Version
+				constraint, err := version.ParseConstraint(problem.Requires)
+				if err != nil || problem.Installed == "" || constraint.Check(problem.Installed) {
+					continue
+				}
+			}
+			// Copies installed in several places may each miss the peer
+			if seen[peer{key, name, problem.Installed}] {
+				continue
+			}
+			seen[peer{key, name, problem.Installed}] = true
+			problem.Chain = chain(node)
+			problems = append(problems, problem)
+		}
+	}
+	return problems, nil
+}
+
+// chain returns the node keys of the shortest path to a dependency, without
+// the project root
+func chain(dep *scanners.Dependency) []string {
+	var shortest []string
+	for _, p := range dep.Paths {
+		if shortest == nil || len(p.Path) < len(shortest) {
+			shortest = p.Path
+		}
+	}
+	if len(shortest) > 0 && shortest[0] == "" {
+		shortest = shortest[1:]
+	}
+	return shortest
+}
+
+// Enrich adds a warning finding for every peer dependency of an npm project
+// in fsys that is missing or installed at a version outside the declared
+// range. Other project types are left untouched.
+func Enrich(fsys fs.FS, projectType string, result *scanners.ScanResult) error {
+	if projectType != "npm" || result.Graph == nil {
+		return nil
+	}
+	problems, err := Find(fsys, result.Graph)
+	if err != nil {
+		return err
+	}
+
+	for _, problem := range problems {
+		node := result.Graph.Nodes[problem.Dependent]
+		message := fmt.Sprintf("%s requires peer %s@%s, which is not installed", problem.Dependent, problem.Name, problem.Requires)
+		if problem.Installed != "" {
+			message = fmt.Sprintf("%s requires peer %s@%s, but %s is installed", problem.Dependent, problem.Name, problem.Requires, problem.Installed)
+		}
+		if len(problem.Chain) > 1 {
+			message += fmt.Sprintf(" (required through %s)", strings.Join(problem.Chain, " > "))
+		}
+		result.Findings = append(result.Findings, scanners.Finding{
+			Rule:       Rule,
+			Severity:   scanners.SeverityWarning,
+			Dependency: node.Name,
+			Version:    node.Version,
+			Message:    message,
+		})
+	}
+	return nil
+}
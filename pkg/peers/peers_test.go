@@ -0,0 +1,74 @@
+package peers
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+const lock = `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"dependencies": {"next": "^13.0.0", "react": "^17.0.2", "eslint-plugin-react": "^7.0.0"}},
+    "node_modules/next": {"version": "13.0.0", "dependencies": {"react-dom": "^18.2.0"}, "peerDependencies": {"react": "^18.2.0", "sass": "^1.3.0"}, "peerDependenciesMeta": {"sass": {"optional": true}}},
+    "node_modules/react-dom": {"version": "18.2.0", "peerDependencies": {"react": "^18.2.0", "scheduler": "*"}},
+    "node_modules/react": {"version": "17.0.2"},
+    "node_modules/eslint-plugin-react": {"version": "7.33.2", "dev": true, "peerDependencies": {"eslint": "^8"}}
+  }
+}`
+
+func testGraph() *scanners.DependencyGraph {
+	return &scanners.DependencyGraph{
+		Nodes: map[string]*scanners.Dependency{
+			"next@13.0.0": {Name: "next", Version: "13.0.0", Paths: []scanners.DependencyPath{{Path: []string{"", "next@13.0.0"}, Depth: 1}}},
+			"react-dom@18.2.0": {Name: "react-dom", Version: "18.2.0", Paths: []scanners.DependencyPath{
+				{Path: []string{"", "next@13.0.0", "react-dom@18.2.0"}, Depth: 2},
+			}},
+			"react@17.0.2": {Name: "react", Version: "17.0.2", Paths: []scanners.DependencyPath{{Path: []string{"", "react@17.0.2"}, Depth: 1}}},
+		},
+	}
+}
+
+func TestFind(t *testing.T) {
+	problems, err := Find(fstest.MapFS{"package-lock.json": {Data: []byte(lock)}}, testGraph())
+	if !assert.NoError(t, err) {
+		return
+	}
+	// Optional peers may be missing, and development packages were not scanned
+	assert.Equal(t, []Problem{
+		{Dependent: "next@13.0.0", Name: "react", Requires: "^18.2.0", Installed: "17.0.2", Chain: []string{"next@13.0.0"}},
+		{Dependent: "react-dom@18.2.0", Name: "react", Requires: "^18.2.0", Installed: "17.0.2", Chain: []string{"next@13.0.0", "react-dom@18.2.0"}},
+		{Dependent: "react-dom@18.2.0", Name: "scheduler", Requires: "*", Chain: []string{"next@13.0.0", "react-dom@18.2.0"}},
+	}, problems)
+
+	problems, err = Find(fstest.MapFS{}, testGraph())
+	assert.NoError(t, err)
+	assert.Empty(t, problems)
+}
+
+func TestEnrich(t *testing.T) {
+	fsys := fstest.MapFS{"package-lock.json": {Data: []byte(lock)}}
+
+	result := &scanners.ScanResult{Graph: testGraph()}
+	if !assert.NoError(t, Enrich(fsys, "npm", result)) {
+		return
+	}
+	if assert.Len(t, result.Findings, 3) {
+		assert.Equal(t, scanners.Finding{
+			Rule:       Rule,
+			Severity:   scanners.SeverityWarning,
+			Dependency: "next",
+			Version:    "13.0.0",
+			Message:    "next@13.0.0 requires peer react@^18.2.0, but 17.0.2 is installed",
+		}, result.Findings[0])
+		assert.Equal(t, "react-dom@18.2.0 requires peer react@^18.2.0, but 17.0.2 is installed (required through next@13.0.0 > react-dom@18.2.0)", result.Findings[1].Message)
+		assert.Equal(t, "react-dom@18.2.0 requires peer scheduler@*, which is not installed (required through next@13.0.0 > react-dom@18.2.0)", result.Findings[2].Message)
+	}
+
+	result = &scanners.ScanResult{Graph: testGraph()}
+	assert.NoError(t, Enrich(fsys, "go", result))
+	assert.Empty(t, result.Findings)
+}
@@ -0,0 +1,125 @@
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/version"
+)
+
+// Enrichment is the name of the platform compatibility check in ScanOptions.Enrich
+const Enrichment = "platform"
+
+// Rule is the rule name of platform compatibility findings
+const Rule = "platform"
+
+// ErrInvalidTarget is returned for malformed Node.js versions and platforms
+var ErrInvalidTarget = errors.New("invalid target platform")
+
+// Target is the Node.js version and platform a project is installed on. Empty
+// fields are not checked.
+type Target struct {
+	Node string // Node.js version, e.g. 18.17.0
+	OS   string // process.platform, e.g. linux, darwin or win32
+	CPU  string // process.arch, e.g. x64 or arm64
+	Libc string // glibc or musl, on Linux
+}
+
+// ParseTarget reads a Node.js version such as 18, v18.17 or 18.17.0 and a
+// platform given as os[/cpu[/libc]], e.g. linux/x64/musl. Either may be empty.
+func ParseTarget(node, platform string) (Target, error) {
+	var target Target
+	if node != "" {
+		node = strings.TrimPrefix(node, "v")
+		if strings.Count(node, ".") > 2 || strings.ContainsFunc(node, func(r rune) bool { return (r < '0' || r > '9') && r != '.' }) {
+			return Target{}, fmt.Errorf("%w: node version %q", ErrInvalidTarget, node)
+		}
+		for strings.Count(node, ".") < 2 {
+			node += ".0"
+		}
+		target.Node = node
+	}
+	if platform != "" {
+		parts := strings.Split(platform, "/")
+		if len(parts) > 3 || slices.Contains(parts, "") {
+			return Target{}, fmt.Errorf("%w: %q, expected os[/cpu[/libc]]", ErrInvalidTarget, platform)
+		}
+		parts = append(parts, "", "")
+		target.OS, target.CPU, target.Libc = parts[0], parts[1], parts[2]
+	}
+	return target, nil
+}
+
+// Problem is a requirement of a package the target does not meet
+type Problem struct {
+	Field    string // engines.node, os, cpu or libc
+	Requires string // Declared value of the field
+	Target   string // Value of the target
+}
+
+// Check returns the requirements of a dependency, as recorded by the npm
+// scanner in its engines.node, os, cpu and libc properties, that the target
+// does not meet. npm skips optional packages built for other platforms, so
+// only their engines are checked.
+func Check(dep scanners.Dependency, target Target) []Problem {
+	var problems []Problem
+	if requires := dep.Properties["engines.node"]; requires != "" && target.Node != "" {
+		// Ranges npm cannot parse either are not enforced
+		constraint, err := version.ParseConstraint(requires)
+		if err == nil && !constraint.Check(target.Node) {
+			problems = append(problems, Problem{Field: "engines.node", Requires: requires, Target: target.Node})
+		}
+	}
+	if dep.Properties["optional"] == "true" {
+		return problems
+	}
+	for _, field := range []struct{ name, target string }{{"os", target.OS}, {"cpu", target.CPU}, {"libc", target.Libc}} {
+		requires := dep.Properties[field.name]
+		if requires != "" && field.target != "" && !allowed(strings.Split(requires, ","), field.target) {
+			problems = append(problems, Problem{Field: field.name, Requires: requires, Target: field.target})
+		}
+	}
+	return problems
+}
+
+// allowed reports whether a value satisfies a list of os, cpu or libc
+// entries, which either name the values supported or, prefixed with !, those
+// that are not
+func allowed(entries []string, value string) bool {
+	listed := false
+	for _, entry := range entries {
+		if excluded, ok := strings.CutPrefix(entry, "!"); ok {
+			if excluded == value {
+				return false
+			}
+			continue
+		}
+		listed = true
+		if entry == value {
+			return true
+		}
+	}
+	return !listed
+}
+
+// Enrich adds a warning finding for every npm dependency whose engines, os,
+// cpu or libc fields exclude the target
+func Enrich(result *scanners.ScanResult, target Target) {
+	for _, dep := range result.Dependencies {
+		if dep.Type != "npm" {
+			continue
+		}
+		for _, problem := range Check(dep, target) {
+			result.Findings = append(result.Findings, scanners.Finding{
+				Rule:       Rule,
+				Severity:   scanners.SeverityWarning,
+				Dependency: dep.Name,
+				Version:    dep.Version,
+				Message:    fmt.Sprintf("%s@%s requires %s %s, not %s", dep.Name, dep.Version, problem.Field, problem.Requires, problem.Target),
+			})
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package platform
+
+import (
+	"testing"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTarget(t *testing.T) {
+	target, err := ParseTarget("v18", "linux/x64/musl")
+	if assert.NoError(t, err) {
+		assert.Equal(t, Target{Node: "18.0.0", OS: "linux", CPU: "x64", Libc: "musl"}, target)
+	}
+	target, err = ParseTarget("20.11.1", "darwin")
+	if assert.NoError(t, err) {
+		assert.Equal(t, Target{Node: "20.11.1", OS: "darwin"}, target)
+	}
+	target, err = ParseTarget("", "")
+	if assert.NoError(t, err) {
+		assert.Equal(t, Target{}, target)
+	}
+
+	_, err = ParseTarget("lts", "")
+	assert.ErrorIs(t, err, ErrInvalidTarget)
+	_, err = ParseTarget("", "linux//glibc")
+	assert.ErrorIs(t, err, ErrInvalidTarget)
+	_, err = ParseTarget("", "linux/x64/glibc/extra")
+	assert.ErrorIs(t, err, ErrInvalidTarget)
+}
+
+func TestCheck(t *testing.T) {
+	target := Target{Node: "16.20.0", OS: "linux", CPU: "x64", Libc: "glibc"}
+
+	dep := scanners.Dependency{Name: "vite", Version: "5.0.0", Properties: map[string]string{"engines.node": "^18.0.0 || >=20.0.0", "os": "!win32"}}
+	assert.Equal(t, []Problem{{Field: "engines.node", Requires: "^18.0.0 || >=20.0.0", Target: "16.20.0"}}, Check(dep, target))
+
+	dep = scanners.Dependency{Name: "fsevents", Version: "2.3.3", Properties: map[string]string{"os": "darwin"}}
+	assert.Equal(t, []Problem{{Field: "os", Requires: "darwin", Target: "linux"}}, Check(dep, target))
+
+	dep = scanners.Dependency{Name: "@esbuild/linux-x64", Version: "0.19.0", Properties: map[string]string{"os": "linux", "cpu": "x64", "libc": "musl"}}
+	assert.Equal(t, []Problem{{Field: "libc", Requires: "musl", Target: "glibc"}}, Check(dep, target))
+
+	// Optional packages for other platforms are skipped by npm
+	dep.Properties["optional"] = "true"
+	assert.Empty(t, Check(dep, target))
+
+	dep = scanners.Dependency{Name: "lodash", Version: "4.17.21", Properties: map[string]string{"os": "!win32", "cpu": "x64,arm64"}}
+	assert.Empty(t, Check(dep, target))
+	assert.Empty(t, Check(dep, Target{}))
+	assert.Equal(t, []Problem{{Field: "os", Requires: "!win32", Target: "win32"}, {Field: "cpu", Requires: "x64,arm64", Target: "ia32"}}, Check(dep, Target{OS: "win32", CPU: "ia32"}))
+}
+
+func TestEnrich(t *testing.T) {
+	result := &scanners.ScanResult{Dependencies: []scanners.Dependency{
+		{Name: "fsevents", Version: "2.3.3", Type: "npm", Properties: map[string]string{"os": "darwin"}},
+		{Name: "golang.org/x/sys", Version: "v0.15.0", Type: "go", Properties: map[string]string{"os": "darwin"}},
+	}}
+	Enrich(result, Target{OS: "linux"})
+	assert.Equal(t, []scanners.Finding{{
+		Rule:       Rule,
+		Severity:   scanners.SeverityWarning,
+		Dependency: "fsevents",
+		Version:    "2.3.3",
+		Message:    "fsevents@2.3.3 requires os darwin, not linux",
+	}}, result.Findings)
+}
@@ -0,0 +1,291 @@
+package policy
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/provenance"
+	"github.com/santoshdahal12/deplister/pkg/risk"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/version"
+)
+
+// Common errors
+var (
+	ErrInvalidPolicy = errors.New("invalid policy")
+)
+
+// Rule actions
+const (
+	Deny    = "deny"
+	Allow   = "allow"
+	Require = "require"
+)
+
+// Rule is a single policy statement, such as "deny lodash <4.17.21"
+type Rule struct {
+	Action     string              // Deny, Allow or Require
+	Pattern    string              // Package name glob, or license glob for license rules
+	License    bool                // Whether Pattern matches licenses instead of names
+	Pinned     bool                // "require pinned": direct dependencies must use exact versions
+	Provenance bool                // "require provenance": packages must be attested or verified
+	Constraint *version.Constraint // Versions the rule applies to, nil for all
+	Severity   string              // Severity of findings produced by the rule
+	Line       int                 // Line number in the policy file
+	Text       string              // Rule as written
+
+	match *regexp.Regexp
+}
+
+// Policy is an ordered list of rules evaluated against scan results, and the
+// weights of risk scores
+type Policy struct {
+	Rules   []Rule
+	Weights risk.Weights // Weights of "risk" lines, nil when there are none
+}
+
+// Load reads a policy file
+func Load(path string) (*Policy, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse reads policy rules, one per line. Blank lines and lines starting
+// with "#" are ignored. A rule prefixed with "warn" produces warnings instead
+// of errors. Supported rules are:
+//
+//	deny <name> [constraint]      the package must not be used
+//	deny license <license>        packages must not use the license
+//	allow <name> [constraint]     exempts packages from deny rules
+//	require <name> <constraint>   the package version must satisfy constraint
+//	require pinned                direct dependencies must use exact versions
+//	require provenance            packages must have attested or verified provenance
+//
+// Names and licenses may contain "*" wildcards and constraints use npm range
+// syntax, e.g. "<4.17.21" or ">=1.2.0 <2.0.0". Lines of the form
+// "risk <signal> <weight>" weigh a signal of risk scores instead of adding a
+// rule, e.g. "risk vulnerabilities 10" or "risk depth 0".
+func Parse(r io.Reader) (*Policy, error) {
+	policy := &Policy{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if fields := strings.Fields(text); fields[0] == risk.Enrichment {
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("%w: line %d: expected \"risk <signal> <weight>\"", ErrInvalidPolicy, line)
+			}
+			weight, err := risk.ParseSignal(fields[1], fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidPolicy, line, err)
+			}
+			if policy.Weights == nil {
+				policy.Weights = risk.Weights{}
+			}
+			policy.Weights[fields[1]] = weight
+			continue
+		}
+
+		rule, err := parseRule(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidPolicy, line, err)
+		}
+		rule.Line = line
+		policy.Rules = append(policy.Rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func parseRule(text string) (Rule, error) {
+	rule := Rule{Text: text, Severity: scanners.SeverityError}
+
+	fields := strings.Fields(text)
+	if fields[0] == "warn" {
+		rule.Severity = scanners.SeverityWarning
+		fields = fields[1:]
+		rule.Text = strings.Join(fields, " ")
+	}
+	if len(fields) < 2 {
+		return rule, fmt.Errorf("incomplete rule %q", text)
+	}
+
+	rule.Action = fields[0]
+	switch rule.Action {
+	case Deny, Allow, Require:
+	default:
+		return rule, fmt.Errorf("unknown action %q", rule.Action)
+	}
+
+	args := fields[1:]
+	switch {
+	case rule.Action == Require && len(args) == 1 && args[0] == "pinned":
+		rule.Pinned = true
+		return rule, nil
+	case rule.Action == Require && len(args) == 1 && args[0] == "provenance":
+		rule.Provenance = true
+		return rule, nil
+	case args[0] == "license":
+		if rule.Action == Require || len(args) != 2 {
+			return rule, fmt.Errorf("expected \"%s license <license>\"", rule.Action)
+		}
+		rule.License = true
+		rule.Pattern = args[1]
+		rule.match = glob(args[1], true)
+		return rule, nil
+	}
+
+	rule.Pattern = args[0]
+	rule.match = glob(args[0], false)
+	if len(args) > 1 {
+		constraint, err := version.ParseConstraint(strings.Join(args[1:], " "))
+		if err != nil {
+			return rule, err
+		}
+		rule.Constraint = &constraint
+	} else if rule.Action == Require {
+		return rule, fmt.Errorf("require rule for %q needs a version constraint", rule.Pattern)
+	}
+	return rule, nil
+}
+
+// glob compiles a pattern in which "*" matches any sequence of characters
+func glob(pattern string, ignoreCase bool) *regexp.Regexp {
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
+	return regexp.MustCompile(expr)
+}
+
+// applies reports whether a name or version rule covers the dependency
+func (r Rule) applies(dep scanners.Dependency) bool {
+	if !r.match.MatchString(dep.Name) {
+		return false
+	}
+	return r.Constraint == nil || r.Action == Require || r.Constraint.Check(dep.Version)
+}
+
+// Evaluate checks every dependency of the result against the policy and
+// returns the violations found
+func (p *Policy) Evaluate(result *scanners.ScanResult) []scanners.Finding {
+	var findings []scanners.Finding
+	for _, dep := range result.Dependencies {
+		for _, rule := range p.Rules {
+			if message, violated := p.violates(rule, dep); violated {
+				findings = append(findings, scanners.Finding{
+					Rule:       rule.Text,
+					Severity:   rule.Severity,
+					Dependency: dep.Name,
+					Version:    dep.Version,
+					Message:    message,
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func (p *Policy) violates(rule Rule, dep scanners.Dependency) (string, bool) {
+	switch {
+	case rule.Action == Allow:
+		return "", false
+	case rule.Pinned:
+		specifier, ok := dep.Properties["specifier"]
+		if !dep.IsDirectDep || !ok || pinned(specifier) {
+			return "", false
+		}
+		return fmt.Sprintf("%s is not pinned to an exact version (%s)", dep.Name, specifier), true
+	case rule.Provenance:
+		// Packages that could not be checked, such as private ones, pass
+		status, ok := dep.Properties["provenance"]
+		if !ok || status == provenance.Attested || status == provenance.Verified {
+			return "", false
+		}
+		return fmt.Sprintf("%s@%s has no verified provenance (%s)", dep.Name, dep.Version, status), true
+	case rule.License:
+		if !matchesLicense(rule.match, dep.License) || p.allowed(dep) {
+			return "", false
+		}
+		return fmt.Sprintf("%s@%s uses denied license %s", dep.Name, dep.Version, dep.License), true
+	case !rule.applies(dep):
+		return "", false
+	case rule.Action == Require:
+		if rule.Constraint.Check(dep.Version) {
+			return "", false
+		}
+		return fmt.Sprintf("%s@%s does not satisfy %s", dep.Name, dep.Version, rule.Constraint), true
+	case p.allowed(dep):
+		return "", false
+	}
+	return fmt.Sprintf("%s@%s is denied by policy", dep.Name, dep.Version), true
+}
+
+// Enrichments returns the enrichments the policy's rules depend on
+func (p *Policy) Enrichments() []string {
+	var enrichments []string
+	for _, rule := range p.Rules {
+		if rule.Provenance {
+			enrichments = append(enrichments, provenance.Enrichment)
+			break
+		}
+	}
+	if p.Weights != nil {
+		enrichments = append(enrichments, risk.Enrichment)
+	}
+	return enrichments
+}
+
+// allowed reports whether an allow rule exempts the dependency
+func (p *Policy) allowed(dep scanners.Dependency) bool {
+	for _, rule := range p.Rules {
+		if rule.Action == Allow && rule.applies(dep) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	exactVersion    = regexp.MustCompile(`^=?v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+	licenseOperator = regexp.MustCompile(`[()]|\s+(?i:OR|AND|WITH)\s+`)
+)
+
+// pinned reports whether a version specifier names a single exact version
+func pinned(specifier string) bool {
+	return exactVersion.MatchString(strings.TrimSpace(specifier))
+}
+
+// matchesLicense reports whether any license of an SPDX expression matches
+func matchesLicense(match *regexp.Regexp, expression string) bool {
+	for _, id := range licenseOperator.Split(expression, -1) {
+		if id = strings.TrimSpace(id); id != "" && match.MatchString(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasErrors reports whether any finding has error or critical severity
+func HasErrors(findings []scanners.Finding) bool {
+	for _, finding := range findings {
+		if finding.Severity == scanners.SeverityError || finding.Severity == scanners.SeverityCritical {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/risk"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func TestParse(t *testing.T) {
+	policy, err := Parse(strings.NewReader(`
+# Known bad packages
+deny left-pad
+deny lodash <4.17.21
+warn deny license GPL-*
+allow @internal/*
+require react >=18.0.0
+require pinned
+require provenance
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, policy.Rules, 7) {
+		return
+	}
+
+	assert.Equal(t, Deny, policy.Rules[0].Action)
+	assert.Equal(t, "left-pad", policy.Rules[0].Pattern)
+	assert.Nil(t, policy.Rules[0].Constraint)
+	assert.Equal(t, 3, policy.Rules[0].Line)
+
+	assert.Equal(t, "<4.17.21", policy.Rules[1].Constraint.String())
+
+	assert.True(t, policy.Rules[2].License)
+	assert.Equal(t, scanners.SeverityWarning, policy.Rules[2].Severity)
+	assert.Equal(t, "deny license GPL-*", policy.Rules[2].Text)
+
+	assert.True(t, policy.Rules[5].Pinned)
+	assert.True(t, policy.Rules[6].Provenance)
+	assert.Equal(t, []string{"provenance"}, policy.Enrichments())
+	assert.Nil(t, policy.Weights)
+}
+
+func TestParse_RiskWeights(t *testing.T) {
+	policy, err := Parse(strings.NewReader("deny left-pad\nrisk vulnerabilities 10\nrisk depth 0\n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, policy.Rules, 1)
+	assert.Equal(t, risk.Weights{risk.Vulnerabilities: 10, risk.Depth: 0}, policy.Weights)
+	assert.Equal(t, []string{risk.Enrichment}, policy.Enrichments())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, text := range []string{
+		"deny",
+		"block left-pad",
+		"require react",
+		"require license MIT",
+		"deny lodash <abc",
+		"risk vulnerabilities",
+		"risk popularity 2",
+		"risk depth -1",
+	} {
+		_, err := Parse(strings.NewReader(text))
+		assert.ErrorIs(t, err, ErrInvalidPolicy, text)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	policy, err := Parse(strings.NewReader(`
+deny left-pad
+deny lodash <4.17.21
+deny @internal/*
+allow @internal/ok
+warn deny license GPL-*
+require react >=18.0.0
+require pinned
+warn require provenance
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	result := &scanners.ScanResult{Dependencies: []scanners.Dependency{
+		{Name: "left-pad", Version: "1.3.0"},
+		{Name: "lodash", Version: "4.17.15"},
+		{Name: "lodash-es", Version: "4.17.15"},
+		{Name: "@internal/bad", Version: "1.0.0"},
+		{Name: "@internal/ok", Version: "1.0.0", License: "(MIT OR GPL-3.0)"},
+		{Name: "readline", Version: "2.0.0", License: "(MIT OR GPL-3.0)"},
+		{Name: "react", Version: "17.0.2", IsDirectDep: true, Properties: map[string]string{"specifier": "^17.0.0"}},
+		{Name: "react-dom", Version: "18.2.0", IsDirectDep: true, Properties: map[string]string{"specifier": "18.2.0", "provenance": "attested"}},
+		{Name: "example.com/mod", Version: "v1.0.0", Properties: map[string]string{"provenance": "unlisted"}},
+	}}
+
+	var got []string
+	for _, finding := range policy.Evaluate(result) {
+		got = append(got, finding.Severity+" "+finding.Message)
+	}
+	assert.Equal(t, []string{
+		"error left-pad@1.3.0 is denied by policy",
+		"error lodash@4.17.15 is denied by policy",
+		"error @internal/bad@1.0.0 is denied by policy",
+		"warning readline@2.0.0 uses denied license (MIT OR GPL-3.0)",
+		"error react@17.0.2 does not satisfy >=18.0.0",
+		"error react is not pinned to an exact version (^17.0.0)",
+		"warning example.com/mod@v1.0.0 has no verified provenance (unlisted)",
+	}, got)
+
+	result = &scanners.ScanResult{Dependencies: []scanners.Dependency{
+		{Name: "lodash", Version: "4.17.21"},
+	}}
+	assert.Empty(t, policy.Evaluate(result))
+
+	policy, err = Parse(strings.NewReader("deny left-pad"))
+	if assert.NoError(t, err) {
+		assert.Empty(t, policy.Enrichments())
+	}
+}
+
+func TestHasErrors(t *testing.T) {
+	assert.False(t, HasErrors(nil))
+	assert.False(t, HasErrors([]scanners.Finding{{Severity: scanners.SeverityWarning}}))
+	assert.True(t, HasErrors([]scanners.Finding{{Severity: scanners.SeverityWarning}, {Severity: scanners.SeverityError}}))
+	assert.True(t, HasErrors([]scanners.Finding{{Severity: scanners.SeverityCritical}}))
+}
@@ -0,0 +1,292 @@
+package provenance
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Enrichment is the name of the provenance enrichment in ScanOptions.Enrich
+const Enrichment = "provenance"
+
+// Provenance statuses
+const (
+	Attested = "attested" // npm: signed, with a SLSA provenance attestation of the tarball
+	Signed   = "signed"   // npm: signed by the registry, without provenance
+	Unsigned = "unsigned" // npm: published without registry signatures
+	Verified = "verified" // Go: logged in the checksum database and matching go.sum
+	Logged   = "logged"   // Go: logged in the checksum database, no go.sum entry to compare
+	Unlisted = "unlisted" // Go: missing from the checksum database
+	Invalid  = "invalid"  // Signature, attestation or checksum does not match
+)
+
+// SLSAProvenance is the predicate type of npm provenance attestations
+const SLSAProvenance = "https://slsa.dev/provenance/v1"
+
+// concurrency is the number of dependencies verified in parallel
+const concurrency = 8
+
+// Source provides npm registry metadata, signing keys and attestations
+type Source interface {
+	registry.Source
+	SigningKeys(ctx context.Context) ([]registry.SigningKey, error)
+	Attestations(ctx context.Context, name, version string) ([]registry.Attestation, error)
+}
+
+// ChecksumDB looks up Go module checksums in a checksum database
+type ChecksumDB interface {
+	Checksum(path, version string) (string, error)
+}
+
+// verifier checks the provenance of npm packages and Go modules
+type verifier struct {
+	source Source
+	sums   ChecksumDB
+	keys   map[string]*ecdsa.PublicKey
+}
+
+// Enrich annotates every dependency with a "provenance" status. npm
+// packages are checked against the registry's signing keys and their SLSA
+// provenance attestations, with the attested source repository recorded as
+// "provenance.source". Go modules are looked up in the checksum database
+// and compared with the "checksum" property from go.sum. Dependencies that
+// cannot be checked, such as private packages, are left untouched.
+//
+// Attestation bundles are matched against the package integrity, but their
+// Sigstore certificate chains are not verified.
+func Enrich(ctx context.Context, source Source, sums ChecksumDB, result *scanners.ScanResult) error {
+	packages, err := registry.Lookup(ctx, source, npmDependencies(result.Dependencies))
+	if err != nil {
+		return err
+	}
+
+	v := &verifier{source: source, sums: sums}
+	if len(packages) > 0 {
+		if v.keys, err = signingKeys(ctx, source); err != nil {
+			return err
+		}
+	}
+
+	props := make([]map[string]string, len(result.Dependencies))
+	errs := make([]error, len(result.Dependencies))
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, concurrency)
+	for i, dep := range result.Dependencies {
+		wg.Add(1)
+		limit <- struct{}{}
+		go func() {
+			defer func() { <-limit; wg.Done() }()
+			switch dep.Type {
+			case "npm":
+				if pkg, ok := packages[registry.Key{Type: dep.Type, Name: dep.Name}]; ok {
+					props[i], errs[i] = v.npm(ctx, dep, pkg)
+				}
+			case "go":
+				props[i], errs[i] = v.golang(dep)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	for i := range result.Dependencies {
+		if len(props[i]) == 0 {
+			continue
+		}
+		dep := &result.Dependencies[i]
+		if dep.Properties == nil {
+			dep.Properties = make(map[string]string)
+		}
+		for key, value := range props[i] {
+			dep.Properties[key] = value
+		}
+	}
+	return nil
+}
+
+func npmDependencies(deps []scanners.Dependency) []scanners.Dependency {
+	var npm []scanners.Dependency
+	for _, dep := range deps {
+		if dep.Type == "npm" {
+			npm = append(npm, dep)
+		}
+	}
+	return npm
+}
+
+// signingKeys returns the registry's ECDSA signing keys by key ID
+func signingKeys(ctx context.Context, source Source) (map[string]*ecdsa.PublicKey, error) {
+	list, err := source.SigningKeys(ctx)
+	if err != nil && !errors.Is(err, registry.ErrNotFound) {
+		return nil, err
+	}
+
+	keys := make(map[string]*ecdsa.PublicKey)
+	for _, key := range list {
+		der, err := base64.StdEncoding.DecodeString(key.Key)
+		if err != nil {
+			continue
+		}
+		if pub, err := x509.ParsePKIXPublicKey(der); err == nil {
+			if ecdsaKey, ok := pub.(*ecdsa.PublicKey); ok {
+				keys[key.KeyID] = ecdsaKey
+			}
+		}
+	}
+	return keys, nil
+}
+
+// npm checks the registry signature and provenance attestation of an npm
+// package version
+func (v *verifier) npm(ctx context.Context, dep scanners.Dependency, pkg *registry.Package) (map[string]string, error) {
+	dist, ok := pkg.Dist[dep.Version]
+	if !ok {
+		return nil, nil
+	}
+	integrity := dep.Properties["integrity"]
+	if integrity == "" {
+		integrity = dist.Integrity
+	}
+
+	if len(dist.Signatures) == 0 {
+		return map[string]string{"provenance": Unsigned}, nil
+	}
+	if !v.signed(dep.Name+"@"+dep.Version+":"+integrity, dist.Signatures) {
+		return map[string]string{"provenance": Invalid}, nil
+	}
+	if dist.Attestations == nil || dist.Attestations.Provenance.PredicateType == "" {
+		return map[string]string{"provenance": Signed}, nil
+	}
+
+	attestations, err := v.source.Attestations(ctx, dep.Name, dep.Version)
+	if errors.Is(err, registry.ErrNotFound) {
+		return map[string]string{"provenance": Invalid}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, attestation := range attestations {
+		if attestation.PredicateType != SLSAProvenance {
+			continue
+		}
+		source, ok := attests(attestation, integrity)
+		if !ok {
+			return map[string]string{"provenance": Invalid}, nil
+		}
+		props := map[string]string{"provenance": Attested}
+		if source != "" {
+			props["provenance.source"] = source
+		}
+		return props, nil
+	}
+	return map[string]string{"provenance": Invalid}, nil
+}
+
+// signed reports whether any signature of the message verifies with a known
+// registry key
+func (v *verifier) signed(message string, signatures []registry.Signature) bool {
+	digest := sha256.Sum256([]byte(message))
+	for _, signature := range signatures {
+		key, ok := v.keys[signature.KeyID]
+		if !ok {
+			continue
+		}
+		sig, err := base64.StdEncoding.DecodeString(signature.Sig)
+		if err == nil && ecdsa.VerifyASN1(key, digest[:], sig) {
+			return true
+		}
+	}
+	return false
+}
+
+// statement is an in-toto statement carrying a SLSA provenance predicate
+type statement struct {
+	Subject []struct {
+		Name   string            `json:"name"`
+		Digest map[string]string `json:"digest"`
+	} `json:"subject"`
+	Predicate struct {
+		BuildDefinition struct {
+			ExternalParameters struct {
+				Workflow struct {
+					Repository string `json:"repository"`
+				} `json:"workflow"`
+			} `json:"externalParameters"`
+		} `json:"buildDefinition"`
+	} `json:"predicate"`
+}
+
+// attests reports whether the attestation's subject is the tarball with the
+// given integrity, and returns the attested source repository
+func attests(attestation registry.Attestation, integrity string) (string, bool) {
+	digest, ok := sha512Hex(integrity)
+	if !ok {
+		return "", false
+	}
+	payload, err := base64.StdEncoding.DecodeString(attestation.Bundle.DSSEEnvelope.Payload)
+	if err != nil {
+		return "", false
+	}
+	var stmt statement
+	if err := json.Unmarshal(payload, &stmt); err != nil {
+		return "", false
+	}
+	for _, subject := range stmt.Subject {
+		if strings.EqualFold(subject.Digest["sha512"], digest) {
+			return stmt.Predicate.BuildDefinition.ExternalParameters.Workflow.Repository, true
+		}
+	}
+	return "", false
+}
+
+// sha512Hex converts a "sha512-<base64>" subresource integrity to hex
+func sha512Hex(integrity string) (string, bool) {
+	for _, hash := range strings.Fields(integrity) {
+		if encoded, ok := strings.CutPrefix(hash, "sha512-"); ok {
+			sum, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return "", false
+			}
+			return hex.EncodeToString(sum), true
+		}
+	}
+	return "", false
+}
+
+// golang checks a Go module version against the checksum database
+func (v *verifier) golang(dep scanners.Dependency) (map[string]string, error) {
+	// Replaced modules are not downloaded from their own path
+	if v.sums == nil || dep.Version == "" || dep.Properties["replaced_by"] != "" {
+		return nil, nil
+	}
+
+	sum, err := v.sums.Checksum(dep.Name, dep.Version)
+	switch {
+	case errors.Is(err, registry.ErrNoSumDB):
+		return nil, nil
+	case errors.Is(err, registry.ErrNotFound):
+		return map[string]string{"provenance": Unlisted}, nil
+	case err != nil:
+		return nil, err
+	}
+
+	switch local := dep.Properties["checksum"]; {
+	case local == "":
+		return map[string]string{"provenance": Logged}, nil
+	case local != sum:
+		return map[string]string{"provenance": Invalid}, nil
+	}
+	return map[string]string{"provenance": Verified}, nil
+}
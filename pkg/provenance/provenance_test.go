@@ -0,0 +1,164 @@
+package provenance
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+type fakeSource struct {
+	packages     map[string]*registry.Package
+	keys         []registry.SigningKey
+	attestations map[string][]registry.Attestation
+}
+
+func (s *fakeSource) Package(ctx context.Context, depType, name string) (*registry.Package, error) {
+	if pkg, ok := s.packages[name]; ok {
+		return pkg, nil
+	}
+	return nil, registry.ErrNotFound
+}
+
+func (s *fakeSource) SigningKeys(ctx context.Context) ([]registry.SigningKey, error) {
+	return s.keys, nil
+}
+
+func (s *fakeSource) Attestations(ctx context.Context, name, version string) ([]registry.Attestation, error) {
+	if attestations, ok := s.attestations[name+"@"+version]; ok {
+		return attestations, nil
+	}
+	return nil, registry.ErrNotFound
+}
+
+type fakeChecksumDB map[string]string
+
+func (db fakeChecksumDB) Checksum(path, version string) (string, error) {
+	if path == "example.com/private" {
+		return "", registry.ErrNoSumDB
+	}
+	if sum, ok := db[path+"@"+version]; ok {
+		return sum, nil
+	}
+	return "", registry.ErrNotFound
+}
+
+func integrity(content string) string {
+	sum := sha512.Sum512([]byte(content))
+	return "sha512-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func attestation(content, repository string) registry.Attestation {
+	sum := sha512.Sum512([]byte(content))
+	var att registry.Attestation
+	att.PredicateType = SLSAProvenance
+	att.Bundle.DSSEEnvelope.Payload = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf(`{
+		"subject": [{"name": "pkg:npm/pkg", "digest": {"sha512": %q}}],
+		"predicate": {"buildDefinition": {"externalParameters": {"workflow": {"repository": %q}}}}
+	}`, hex.EncodeToString(sum[:]), repository)))
+	return att
+}
+
+func TestEnrich(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.NoError(t, err) {
+		return
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if !assert.NoError(t, err) {
+		return
+	}
+	sign := func(name, version, integrity string) []registry.Signature {
+		digest := sha256.Sum256([]byte(name + "@" + version + ":" + integrity))
+		sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		assert.NoError(t, err)
+		return []registry.Signature{{KeyID: "SHA256:test", Sig: base64.StdEncoding.EncodeToString(sig)}}
+	}
+	provenance := func(dist registry.Dist) registry.Dist {
+		dist.Attestations = &registry.DistAttestations{}
+		dist.Attestations.Provenance.PredicateType = SLSAProvenance
+		return dist
+	}
+
+	source := &fakeSource{
+		keys: []registry.SigningKey{{KeyID: "SHA256:test", Key: base64.StdEncoding.EncodeToString(der)}},
+		packages: map[string]*registry.Package{
+			"attested": {Dist: map[string]registry.Dist{"1.0.0": provenance(registry.Dist{
+				Integrity: integrity("attested"), Signatures: sign("attested", "1.0.0", integrity("attested")),
+			})}},
+			"signed": {Dist: map[string]registry.Dist{"1.0.0": {
+				Integrity: integrity("signed"), Signatures: sign("signed", "1.0.0", integrity("signed")),
+			}}},
+			"unsigned": {Dist: map[string]registry.Dist{"1.0.0": {Integrity: integrity("unsigned")}}},
+			"tampered": {Dist: map[string]registry.Dist{"1.0.0": {
+				Integrity: integrity("tampered"), Signatures: sign("tampered", "1.0.0", integrity("original")),
+			}}},
+			"misattested": {Dist: map[string]registry.Dist{"1.0.0": provenance(registry.Dist{
+				Integrity: integrity("misattested"), Signatures: sign("misattested", "1.0.0", integrity("misattested")),
+			})}},
+		},
+		attestations: map[string][]registry.Attestation{
+			"attested@1.0.0":    {attestation("attested", "https://github.com/example/attested")},
+			"misattested@1.0.0": {attestation("other", "https://github.com/example/other")},
+		},
+	}
+	sums := fakeChecksumDB{
+		"example.com/verified@v1.0.0": "h1:good=",
+		"example.com/changed@v1.0.0":  "h1:good=",
+		"example.com/logged@v1.0.0":   "h1:good=",
+	}
+
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "attested", Version: "1.0.0", Type: "npm"},
+			{Name: "signed", Version: "1.0.0", Type: "npm"},
+			{Name: "unsigned", Version: "1.0.0", Type: "npm"},
+			{Name: "tampered", Version: "1.0.0", Type: "npm"},
+			{Name: "misattested", Version: "1.0.0", Type: "npm"},
+			{Name: "@private/pkg", Version: "1.0.0", Type: "npm"},
+			{Name: "example.com/verified", Version: "v1.0.0", Type: "go", Properties: map[string]string{"checksum": "h1:good="}},
+			{Name: "example.com/changed", Version: "v1.0.0", Type: "go", Properties: map[string]string{"checksum": "h1:bad="}},
+			{Name: "example.com/logged", Version: "v1.0.0", Type: "go"},
+			{Name: "example.com/unlisted", Version: "v1.0.0", Type: "go"},
+			{Name: "example.com/private", Version: "v1.0.0", Type: "go"},
+			{Name: "example.com/replaced", Version: "v1.0.0", Type: "go", Properties: map[string]string{"replaced_by": "../replaced"}},
+		},
+	}
+
+	if !assert.NoError(t, Enrich(context.Background(), source, sums, result)) {
+		return
+	}
+
+	got := make(map[string]string)
+	for _, dep := range result.Dependencies {
+		got[dep.Name] = dep.Properties["provenance"]
+	}
+	assert.Equal(t, map[string]string{
+		"attested":             Attested,
+		"signed":               Signed,
+		"unsigned":             Unsigned,
+		"tampered":             Invalid,
+		"misattested":          Invalid,
+		"@private/pkg":         "",
+		"example.com/verified": Verified,
+		"example.com/changed":  Invalid,
+		"example.com/logged":   Logged,
+		"example.com/unlisted": Unlisted,
+		"example.com/private":  "",
+		"example.com/replaced": "",
+	}, got)
+
+	assert.Equal(t, "https://github.com/example/attested", result.Dependencies[0].Properties["provenance.source"])
+}
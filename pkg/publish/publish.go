@@ -0,0 +1,242 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// Storage URL schemes
+const (
+	S3    = "s3" // s3://bucket/key
+	GCS   = "gs" // gs://bucket/key
+	Azure = "az" // az://account/container/blob
+)
+
+// Digest is replaced by the hex encoded SHA-256 of the content in keys
+const Digest = "{sha256}"
+
+// Common errors
+var (
+	ErrInvalidDestination = errors.New("invalid publish destination")
+	ErrMissingCredentials = errors.New("missing storage credentials")
+	ErrUpload             = errors.New("upload failed")
+)
+
+// Destination is an object to upload to
+type Destination struct {
+	Scheme  string // S3, GCS or Azure
+	Account string // Storage account, Azure only
+	Bucket  string // Bucket, or container on Azure
+	Key     string // Object key without a leading slash
+}
+
+// String returns the destination as a storage URL
+func (d Destination) String() string {
+	if d.Scheme == Azure {
+		return fmt.Sprintf("%s://%s/%s/%s", d.Scheme, d.Account, d.Bucket, d.Key)
+	}
+	return fmt.Sprintf("%s://%s/%s", d.Scheme, d.Bucket, d.Key)
+}
+
+// Parse parses a storage URL. The key is a prefix, "" or ending in a
+// slash, or a name that may hold Digest.
+func Parse(raw string) (Destination, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Destination{}, fmt.Errorf("%w: %w", ErrInvalidDestination, err)
+	}
+	d := Destination{Scheme: u.Scheme, Bucket: u.Host, Key: strings.TrimPrefix(u.Path, "/")}
+	switch d.Scheme {
+	case S3, GCS:
+	case Azure:
+		d.Account = u.Host
+		d.Bucket, d.Key, _ = strings.Cut(d.Key, "/")
+	default:
+		return Destination{}, fmt.Errorf("%w: %s, expected s3://, gs:// or az://", ErrInvalidDestination, raw)
+	}
+	if d.Bucket == "" || (d.Scheme == Azure && d.Account == "") {
+		return Destination{}, fmt.Errorf("%w: %s has no bucket", ErrInvalidDestination, raw)
+	}
+	return d, nil
+}
+
+// Name returns the destination of content, named after its digest: the key
+// with Digest replaced, or the digest followed by ext under a key prefix
+func (d Destination) Name(content []byte, ext string) Destination {
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+	switch {
+	case strings.Contains(d.Key, Digest):
+		d.Key = strings.ReplaceAll(d.Key, Digest, digest)
+	case d.Key == "" || strings.HasSuffix(d.Key, "/"):
+		d.Key += digest + ext
+	}
+	return d
+}
+
+// Credentials authenticate uploads. Endpoints override the public ones of
+// each service, for S3 compatible stores and emulators.
+type Credentials struct {
+	AWSAccessKeyID     string
+	AWSSecretAccessKey string
+	AWSSessionToken    string
+	AWSRegion          string // us-east-1 when empty
+	S3Endpoint         string // Path style endpoint, such as http://localhost:9000 for MinIO
+
+	GCSToken    string // OAuth 2 access token
+	GCSEndpoint string
+
+	AzureSAS      string // Shared access signature query string
+	AzureEndpoint string // Replaces https://<account>.blob.core.windows.net
+}
+
+// CredentialsFromEnv reads the credentials from the environment variables
+// the cloud tools use: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+// AWS_SESSION_TOKEN, AWS_REGION or AWS_DEFAULT_REGION, AWS_ENDPOINT_URL_S3
+// or AWS_ENDPOINT_URL, GOOGLE_OAUTH_ACCESS_TOKEN, STORAGE_EMULATOR_HOST,
+// AZURE_STORAGE_SAS_TOKEN and AZURE_STORAGE_BLOB_ENDPOINT
+func CredentialsFromEnv(getenv func(string) string) Credentials {
+	first := func(names ...string) string {
+		for _, name := range names {
+			if value := getenv(name); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+	return Credentials{
+		AWSAccessKeyID:     getenv("AWS_ACCESS_KEY_ID"),
+		AWSSecretAccessKey: getenv("AWS_SECRET_ACCESS_KEY"),
+		AWSSessionToken:    getenv("AWS_SESSION_TOKEN"),
+		AWSRegion:          first("AWS_REGION", "AWS_DEFAULT_REGION"),
+		S3Endpoint:         first("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"),
+		GCSToken:           getenv("GOOGLE_OAUTH_ACCESS_TOKEN"),
+		GCSEndpoint:        getenv("STORAGE_EMULATOR_HOST"),
+		AzureSAS:           getenv("AZURE_STORAGE_SAS_TOKEN"),
+		AzureEndpoint:      getenv("AZURE_STORAGE_BLOB_ENDPOINT"),
+	}
+}
+
+// Upload stores content at the destination with a single PUT
+func Upload(ctx context.Context, client *http.Client, dest Destination, content []byte, contentType string, creds Credentials) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	var (
+		req *http.Request
+		err error
+	)
+	switch dest.Scheme {
+	case S3:
+		req, err = s3Request(ctx, dest, content, creds)
+	case GCS:
+		req, err = gcsRequest(ctx, dest, content, creds)
+	case Azure:
+		req, err = azureRequest(ctx, dest, content, creds)
+	default:
+		err = fmt.Errorf("%w: unknown scheme %q", ErrInvalidDestination, dest.Scheme)
+	}
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if dest.Scheme == S3 {
+		signV4(req, creds, time.Now())
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%w: %s responded %s: %s", ErrUpload, dest, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func gcsRequest(ctx context.Context, dest Destination, content []byte, creds Credentials) (*http.Request, error) {
+	endpoint := "https://storage.googleapis.com"
+	if creds.GCSEndpoint != "" {
+		endpoint = withScheme(creds.GCSEndpoint)
+	} else if creds.GCSToken == "" {
+		return nil, fmt.Errorf("%w: set GOOGLE_OAUTH_ACCESS_TOKEN, e.g. to the output of gcloud auth print-access-token", ErrMissingCredentials)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint+"/"+dest.Bucket+"/"+escapePath(dest.Key), bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	if creds.GCSToken != "" {
+		req.Header.Set("Authorization", "Bearer "+creds.GCSToken)
+	}
+	return req, nil
+}
+
+func azureRequest(ctx context.Context, dest Destination, content []byte, creds Credentials) (*http.Request, error) {
+	if creds.AzureSAS == "" {
+		return nil, fmt.Errorf("%w: set AZURE_STORAGE_SAS_TOKEN to a shared access signature allowing writes", ErrMissingCredentials)
+	}
+	endpoint := "https://" + dest.Account + ".blob.core.windows.net"
+	if creds.AzureEndpoint != "" {
+		endpoint = strings.TrimSuffix(creds.AzureEndpoint, "/")
+	}
+	target := endpoint + "/" + dest.Bucket + "/" + escapePath(dest.Key) + "?" + strings.TrimPrefix(creds.AzureSAS, "?")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-version", "2021-08-06")
+	return req, nil
+}
+
+// withScheme prefixes a host without a scheme with http://, as emulator
+// hosts are given
+func withScheme(endpoint string) string {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	return strings.TrimSuffix(endpoint, "/")
+}
+
+// escapePath percent-encodes every byte of a key but unreserved characters
+// and slashes, as S3 signatures require
+func escapePath(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' ||
+			'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// ContentType returns the media type of an output file by extension
+func ContentType(name string) string {
+	switch path.Ext(name) {
+	case ".json":
+		return "application/json"
+	case ".xml":
+		return "application/xml"
+	case ".txt":
+		return "text/plain; charset=utf-8"
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,106 @@
+package publish
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Destination
+	}{
+		{"s3://artifacts/sboms/", Destination{Scheme: S3, Bucket: "artifacts", Key: "sboms/"}},
+		{"gs://artifacts", Destination{Scheme: GCS, Bucket: "artifacts"}},
+		{"az://acct/artifacts/app-{sha256}.json", Destination{Scheme: Azure, Account: "acct", Bucket: "artifacts", Key: "app-{sha256}.json"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.raw)
+		assert.NoError(t, err, tt.raw)
+		assert.Equal(t, tt.want, got, tt.raw)
+	}
+
+	for _, raw := range []string{"http://example.com/x", "s3:///key", "az://acct", "sboms/"} {
+		_, err := Parse(raw)
+		assert.ErrorIs(t, err, ErrInvalidDestination, raw)
+	}
+}
+
+func TestDestination_Name(t *testing.T) {
+	content := []byte("{}")
+	digest := "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+
+	d := Destination{Scheme: S3, Bucket: "b", Key: "sboms/"}
+	assert.Equal(t, "s3://b/sboms/"+digest+".json", d.Name(content, ".json").String())
+	d.Key = ""
+	assert.Equal(t, "s3://b/"+digest+".json", d.Name(content, ".json").String())
+	d = Destination{Scheme: Azure, Account: "acct", Bucket: "c", Key: "app-{sha256}.cdx.json"}
+	assert.Equal(t, "az://acct/c/app-"+digest+".cdx.json", d.Name(content, ".json").String())
+	d = Destination{Scheme: GCS, Bucket: "b", Key: "latest.json"}
+	assert.Equal(t, "gs://b/latest.json", d.Name(content, ".json").String())
+}
+
+func TestCredentialsFromEnv(t *testing.T) {
+	env := map[string]string{"AWS_DEFAULT_REGION": "eu-west-1", "AWS_ENDPOINT_URL": "http://minio:9000", "AZURE_STORAGE_SAS_TOKEN": "sv=1"}
+	creds := CredentialsFromEnv(func(name string) string { return env[name] })
+	assert.Equal(t, Credentials{AWSRegion: "eu-west-1", S3Endpoint: "http://minio:9000", AzureSAS: "sv=1"}, creds)
+}
+
+// upload records the last request a fake store received
+type upload struct {
+	method, path, query string
+	header              http.Header
+	body                string
+}
+
+func fakeStore(t *testing.T, status int) (*httptest.Server, *upload) {
+	var got upload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = upload{method: r.Method, path: r.URL.EscapedPath(), query: r.URL.RawQuery, header: r.Header, body: string(body)}
+		w.WriteHeader(status)
+		io.WriteString(w, "denied")
+	}))
+	t.Cleanup(server.Close)
+	return server, &got
+}
+
+func TestUpload(t *testing.T) {
+	ctx := context.Background()
+	server, got := fakeStore(t, http.StatusOK)
+
+	creds := Credentials{AWSAccessKeyID: "AK", AWSSecretAccessKey: "SK", AWSSessionToken: "ST", S3Endpoint: server.URL}
+	err := Upload(ctx, nil, Destination{Scheme: S3, Bucket: "b", Key: "sboms/x.json"}, []byte("{}"), "application/json", creds)
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodPut, got.method)
+	assert.Equal(t, "/b/sboms/x.json", got.path)
+	assert.Equal(t, "{}", got.body)
+	assert.Equal(t, "ST", got.header.Get("X-Amz-Security-Token"))
+	assert.True(t, strings.HasPrefix(got.header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AK/"))
+	assert.Contains(t, got.header.Get("Authorization"), "SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date;x-amz-security-token,")
+
+	creds = Credentials{GCSToken: "ya29.token", GCSEndpoint: strings.TrimPrefix(server.URL, "http://")}
+	assert.NoError(t, Upload(ctx, nil, Destination{Scheme: GCS, Bucket: "b", Key: "x.json"}, []byte("{}"), "application/json", creds))
+	assert.Equal(t, "/b/x.json", got.path)
+	assert.Equal(t, "Bearer ya29.token", got.header.Get("Authorization"))
+
+	creds = Credentials{AzureSAS: "?sv=2022-11-02&sig=abc", AzureEndpoint: server.URL}
+	assert.NoError(t, Upload(ctx, nil, Destination{Scheme: Azure, Account: "acct", Bucket: "c", Key: "x.json"}, []byte("{}"), "", creds))
+	assert.Equal(t, "/c/x.json", got.path)
+	assert.Equal(t, "sv=2022-11-02&sig=abc", got.query)
+	assert.Equal(t, "BlockBlob", got.header.Get("X-Ms-Blob-Type"))
+
+	assert.ErrorIs(t, Upload(ctx, nil, Destination{Scheme: GCS, Bucket: "b", Key: "x"}, nil, "", Credentials{}), ErrMissingCredentials)
+	assert.ErrorIs(t, Upload(ctx, nil, Destination{Scheme: Azure, Account: "a", Bucket: "c", Key: "x"}, nil, "", Credentials{}), ErrMissingCredentials)
+
+	server, _ = fakeStore(t, http.StatusForbidden)
+	err = Upload(ctx, nil, Destination{Scheme: GCS, Bucket: "b", Key: "x"}, nil, "", Credentials{GCSEndpoint: server.URL})
+	assert.ErrorIs(t, err, ErrUpload)
+	assert.Contains(t, err.Error(), "403 Forbidden: denied")
+}
@@ -0,0 +1,99 @@
+package purl
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// types maps dependency types to package URL types where they differ
+var types = map[string]string{
+	"go": "golang",
+}
+
+// Common errors
+var (
+	ErrInvalidPURL = errors.New("invalid package URL")
+)
+
+// Type returns the package URL type for a dependency type
+func Type(depType string) string {
+	if t, ok := types[depType]; ok {
+		return t
+	}
+	return strings.ToLower(depType)
+}
+
+// New builds a package URL such as pkg:npm/%40babel/core@7.24.0. Each path
+// segment of the name is escaped, so Go module paths become namespaces.
+func New(depType, name, version string) string {
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = escape(segment)
+	}
+
+	var b strings.Builder
+	b.WriteString("pkg:")
+	b.WriteString(Type(depType))
+	b.WriteByte('/')
+	b.WriteString(strings.Join(segments, "/"))
+	if version != "" {
+		b.WriteByte('@')
+		b.WriteString(escape(version))
+	}
+	return b.String()
+}
+
+// For returns the package URL of a scanned dependency
+func For(dep scanners.Dependency) string {
+	return New(dep.Type, dep.Name, dep.Version)
+}
+
+// escape percent-encodes a purl component. url.PathEscape leaves "@" and
+// "+" alone, which the purl spec requires to be encoded.
+func escape(s string) string {
+	s = url.PathEscape(s)
+	s = strings.ReplaceAll(s, "@", "%40")
+	return strings.ReplaceAll(s, "+", "%2B")
+}
+
+// Parse splits a package URL into a dependency type, name and version,
+// reversing New. The namespace becomes part of the name, and qualifiers
+// and subpaths are dropped.
+func Parse(s string) (depType, name, version string, err error) {
+	rest, ok := strings.CutPrefix(s, "pkg:")
+	if !ok {
+		return "", "", "", fmt.Errorf("%w: %q", ErrInvalidPURL, s)
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, _, _ = strings.Cut(rest, "?")
+	rest = strings.TrimLeft(rest, "/")
+
+	t, path, ok := strings.Cut(rest, "/")
+	if !ok || t == "" || path == "" {
+		return "", "", "", fmt.Errorf("%w: %q", ErrInvalidPURL, s)
+	}
+	depType = strings.ToLower(t)
+	for dep, purlType := range types {
+		if purlType == depType {
+			depType = dep
+		}
+	}
+
+	if at := strings.LastIndex(path, "@"); at >= 0 {
+		if version, err = url.PathUnescape(path[at+1:]); err != nil {
+			return "", "", "", fmt.Errorf("%w: %q", ErrInvalidPURL, s)
+		}
+		path = path[:at]
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if segments[i], err = url.PathUnescape(segment); err != nil {
+			return "", "", "", fmt.Errorf("%w: %q", ErrInvalidPURL, s)
+		}
+	}
+	return depType, strings.Join(segments, "/"), version, nil
+}
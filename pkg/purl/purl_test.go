@@ -0,0 +1,64 @@
+package purl
+
+import (
+	"testing"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		depType string
+		pkg     string
+		version string
+		want    string
+	}{
+		{name: "npm", depType: "npm", pkg: "lodash", version: "4.17.21", want: "pkg:npm/lodash@4.17.21"},
+		{name: "npm_scoped", depType: "npm", pkg: "@babel/core", version: "7.24.0", want: "pkg:npm/%40babel/core@7.24.0"},
+		{name: "go", depType: "go", pkg: "github.com/pkg/errors", version: "v0.9.1", want: "pkg:golang/github.com/pkg/errors@v0.9.1"},
+		{name: "go_incompatible", depType: "go", pkg: "github.com/docker/docker", version: "v20.10.0+incompatible", want: "pkg:golang/github.com/docker/docker@v20.10.0%2Bincompatible"},
+		{name: "no_version", depType: "npm", pkg: "lodash", want: "pkg:npm/lodash"},
+		{name: "plugin_type", depType: "Cargo", pkg: "serde", version: "1.0.0", want: "pkg:cargo/serde@1.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, New(tt.depType, tt.pkg, tt.version))
+		})
+	}
+}
+
+func TestFor(t *testing.T) {
+	dep := scanners.Dependency{Name: "golang.org/x/mod", Version: "v0.17.0", Type: "go"}
+	assert.Equal(t, "pkg:golang/golang.org/x/mod@v0.17.0", For(dep))
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		purl    string
+		depType string
+		name    string
+		version string
+	}{
+		{purl: "pkg:npm/%40babel/core@7.24.0", depType: "npm", name: "@babel/core", version: "7.24.0"},
+		{purl: "pkg:golang/github.com/docker/docker@v20.10.0%2Bincompatible", depType: "go", name: "github.com/docker/docker", version: "v20.10.0+incompatible"},
+		{purl: "pkg:deb/debian/curl@7.88.1-10?arch=amd64&distro=debian-12", depType: "deb", name: "debian/curl", version: "7.88.1-10"},
+		{purl: "pkg:npm/lodash", depType: "npm", name: "lodash"},
+		{purl: "pkg:NPM/lodash@4.17.21#lib", depType: "npm", name: "lodash", version: "4.17.21"},
+	}
+
+	for _, tt := range tests {
+		depType, name, version, err := Parse(tt.purl)
+		if assert.NoError(t, err, tt.purl) {
+			assert.Equal(t, []string{tt.depType, tt.name, tt.version}, []string{depType, name, version}, tt.purl)
+		}
+	}
+
+	for _, invalid := range []string{"npm/lodash", "pkg:npm", "pkg:/lodash", "pkg:npm/%zz"} {
+		_, _, _, err := Parse(invalid)
+		assert.ErrorIs(t, err, ErrInvalidPURL, invalid)
+	}
+}
@@ -0,0 +1,651 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+
+	"github.com/santoshdahal12/deplister/pkg/httpclient"
+	"github.com/santoshdahal12/deplister/pkg/npmrc"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/version"
+)
+
+// Default registries
+const (
+	DefaultNPMURL     = "https://registry.npmjs.org"
+	DefaultGoProxyURL = "https://proxy.golang.org"
+)
+
+// concurrency is the number of parallel lookups in Lookup
+const concurrency = 8
+
+// Common errors
+var (
+	ErrNotFound      = errors.New("package not found in registry")
+	ErrRequestFailed = errors.New("registry request failed")
+)
+
+// Package is the registry metadata of a package
+type Package struct {
+	Name       string
+	Type       string               // Dependency type, "npm" or "go"
+	Latest     string               // Version installed by default, e.g. npm's "latest" dist-tag
+	Versions   []string             // Published versions, oldest first
+	Deprecated map[string]string    // Deprecation messages by version
+	Published  map[string]time.Time // Publication times by version, when known
+	Retracted  []Retraction         // Retracted Go versions
+
+	// Scripts are the package.json scripts of each npm version
+	Scripts map[string]map[string]string
+
+	// Dist is the distribution metadata of each npm version
+	Dist map[string]Dist
+
+	// Dependencies are the dependency ranges of each npm version, by name
+	Dependencies map[string]map[string]string
+
+	// Maintainers are the npm users allowed to publish the package
+	Maintainers []string
+
+	// Publishers are the npm users who published each npm version
+	Publishers map[string]string
+
+	// Funding are the funding URLs each npm version declares
+	Funding map[string][]string
+
+	// Licenses are the license each npm version declares, as written
+	Licenses map[string]string
+
+	// ModuleDeprecated is the deprecation message of a whole Go module
+	ModuleDeprecated string
+}
+
+// Dist describes the published tarball of an npm version
+type Dist struct {
+	Integrity    string            `json:"integrity"`
+	Shasum       string            `json:"shasum"`       // Hex SHA-1, published before integrity
+	UnpackedSize int64             `json:"unpackedSize"` // Bytes of the extracted tarball, when published
+	Signatures   []Signature       `json:"signatures"`
+	Attestations *DistAttestations `json:"attestations"`
+}
+
+// DistAttestations locates the attestations published for an npm version
+type DistAttestations struct {
+	URL        string `json:"url"`
+	Provenance struct {
+		PredicateType string `json:"predicateType"`
+	} `json:"provenance"`
+}
+
+// Signature is a registry signature of "<name>@<version>:<integrity>"
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// Retraction is a range of Go module versions withdrawn by their author
+type Retraction struct {
+	Low       string
+	High      string
+	Rationale string
+}
+
+// Retraction returns the retraction covering the version, if any
+func (p *Package) Retraction(v string) (Retraction, bool) {
+	for _, r := range p.Retracted {
+		if version.Compare(v, r.Low) >= 0 && version.Compare(v, r.High) <= 0 {
+			return r, true
+		}
+	}
+	return Retraction{}, false
+}
+
+// Source looks up packages in their registries
+type Source interface {
+	Package(ctx context.Context, depType, name string) (*Package, error)
+}
+
+// Key identifies a package across registries
+type Key struct {
+	Type string
+	Name string
+}
+
+// Client reads package metadata from the npm registry and a Go module proxy
+type Client struct {
+	NPMURL     string
+	GoProxyURL string // Empty disables Go lookups
+	HTTPClient *http.Client
+	NPMRC      *npmrc.Config // Scoped registries and credentials of npm packages, nil for NPMURL alone
+
+	mu    sync.Mutex
+	cache map[Key]*Package
+}
+
+// NewClient creates a client for the public npm registry and the first
+// proxy in GOPROXY
+func NewClient() *Client {
+	return &Client{
+		NPMURL:     DefaultNPMURL,
+		GoProxyURL: goProxy(os.Getenv("GOPROXY")),
+		HTTPClient: httpclient.Default,
+	}
+}
+
+// goProxy returns the first proxy URL of a GOPROXY list
+func goProxy(env string) string {
+	if env == "" {
+		return DefaultGoProxyURL
+	}
+	for _, entry := range strings.FieldsFunc(env, func(r rune) bool { return r == ',' || r == '|' }) {
+		switch entry = strings.TrimSpace(entry); entry {
+		case "direct", "":
+			continue
+		case "off":
+			return ""
+		}
+		return entry
+	}
+	return ""
+}
+
+// Package looks up a package by dependency type. Packages are cached for
+// the lifetime of the client.
+func (c *Client) Package(ctx context.Context, depType, name string) (*Package, error) {
+	key := Key{Type: depType, Name: name}
+	c.mu.Lock()
+	pkg, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok {
+		return pkg, nil
+	}
+
+	var err error
+	switch {
+	case depType == "npm":
+		pkg, err = c.npmPackage(ctx, name)
+	case depType == "go" && c.GoProxyURL != "":
+		pkg, err = c.goModule(ctx, name)
+	default:
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.cache == nil {
+		c.cache = make(map[Key]*Package)
+	}
+	c.cache[key] = pkg
+	c.mu.Unlock()
+	return pkg, nil
+}
+
+type packument struct {
+	DistTags map[string]string `json:"dist-tags"`
+	Versions map[string]struct {
+		Deprecated   deprecation       `json:"deprecated"`
+		Scripts      map[string]string `json:"scripts"`
+		Dist         Dist              `json:"dist"`
+		Dependencies map[string]string `json:"dependencies"`
+		Funding      funding           `json:"funding"`
+		License      licenseField      `json:"license"`
+		NPMUser      struct {
+			Name string `json:"name"`
+		} `json:"_npmUser"`
+	} `json:"versions"`
+	Time        map[string]string `json:"time"`
+	Maintainers []struct {
+		Name string `json:"name"`
+	} `json:"maintainers"`
+}
+
+// deprecation is a version's deprecation message. Some packages were
+// deprecated with a boolean instead of a message.
+type deprecation string
+
+func (d *deprecation) UnmarshalJSON(data []byte) error {
+	var message string
+	if json.Unmarshal(data, &message) == nil {
+		*d = deprecation(message)
+		return nil
+	}
+	var deprecated bool
+	if json.Unmarshal(data, &deprecated) == nil && deprecated {
+		*d = "deprecated"
+	}
+	return nil
+}
+
+// funding is the funding field of a package.json: a URL, an object with a
+// url, or an array of either
+type funding []string
+
+func (f *funding) UnmarshalJSON(data []byte) error {
+	var entries []json.RawMessage
+	if json.Unmarshal(data, &entries) != nil {
+		entries = []json.RawMessage{data}
+	}
+	for _, entry := range entries {
+		var link string
+		if json.Unmarshal(entry, &link) != nil {
+			var object struct {
+				URL string `json:"url"`
+			}
+			// Malformed funding should not fail the lookup
+			_ = json.Unmarshal(entry, &object)
+			link = object.URL
+		}
+		if link != "" {
+			*f = append(*f, link)
+		}
+	}
+	return nil
+}
+
+// licenseField is the license field of a package.json: an SPDX expression,
+// or an object with a type in old packages
+type licenseField string
+
+func (l *licenseField) UnmarshalJSON(data []byte) error {
+	var expression string
+	if json.Unmarshal(data, &expression) == nil {
+		*l = licenseField(expression)
+		return nil
+	}
+	var object struct {
+		Type string `json:"type"`
+	}
+	// Malformed licenses should not fail the lookup
+	_ = json.Unmarshal(data, &object)
+	*l = licenseField(object.Type)
+	return nil
+}
+
+func (c *Client) npmPackage(ctx context.Context, name string) (*Package, error) {
+	base := c.NPMURL
+	if c.NPMRC != nil {
+		if registry := c.NPMRC.RegistryFor(name); registry != "" {
+			base = registry
+		}
+	}
+
+	// Scoped names keep their "@" but escape the "/"
+	var doc packument
+	if err := c.get(ctx, base, "/"+strings.Replace(url.PathEscape(name), "%40", "@", 1), &doc); err != nil {
+		return nil, err
+	}
+
+	pkg := &Package{
+		Name:         name,
+		Type:         "npm",
+		Latest:       doc.DistTags["latest"],
+		Deprecated:   make(map[string]string),
+		Published:    make(map[string]time.Time),
+		Scripts:      make(map[string]map[string]string),
+		Dist:         make(map[string]Dist),
+		Dependencies: make(map[string]map[string]string),
+		Publishers:   make(map[string]string),
+		Funding:      make(map[string][]string),
+		Licenses:     make(map[string]string),
+	}
+	for _, maintainer := range doc.Maintainers {
+		if maintainer.Name != "" {
+			pkg.Maintainers = append(pkg.Maintainers, maintainer.Name)
+		}
+	}
+	for v, meta := range doc.Versions {
+		pkg.Versions = append(pkg.Versions, v)
+		if len(meta.Scripts) > 0 {
+			pkg.Scripts[v] = meta.Scripts
+		}
+		if len(meta.Dependencies) > 0 {
+			pkg.Dependencies[v] = meta.Dependencies
+		}
+		pkg.Dist[v] = meta.Dist
+		if meta.NPMUser.Name != "" {
+			pkg.Publishers[v] = meta.NPMUser.Name
+		}
+		if len(meta.Funding) > 0 {
+			pkg.Funding[v] = meta.Funding
+		}
+		if meta.License != "" {
+			pkg.Licenses[v] = string(meta.License)
+		}
+		if meta.Deprecated != "" {
+			pkg.Deprecated[v] = string(meta.Deprecated)
+		}
+		if published, err := time.Parse(time.RFC3339, doc.Time[v]); err == nil {
+			pkg.Published[v] = published
+		}
+	}
+	sort.Slice(pkg.Versions, func(i, j int) bool { return version.Compare(pkg.Versions[i], pkg.Versions[j]) < 0 })
+	return pkg, nil
+}
+
+func (c *Client) goModule(ctx context.Context, name string) (*Package, error) {
+	escaped, err := module.EscapePath(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+
+	var list string
+	if err := c.get(ctx, c.GoProxyURL, "/"+escaped+"/@v/list", &list); err != nil {
+		return nil, err
+	}
+
+	pkg := &Package{Name: name, Type: "go"}
+	for _, v := range strings.Fields(list) {
+		// Versions of the next major version without a go.mod are not
+		// upgrades available under this module path
+		if !strings.HasSuffix(v, "+incompatible") {
+			pkg.Versions = append(pkg.Versions, v)
+		}
+	}
+	sort.Slice(pkg.Versions, func(i, j int) bool { return version.Compare(pkg.Versions[i], pkg.Versions[j]) < 0 })
+
+	// Like the go command, prefer the highest release over prereleases
+	for i := len(pkg.Versions) - 1; i >= 0; i-- {
+		if !version.Prerelease(pkg.Versions[i]) {
+			pkg.Latest = pkg.Versions[i]
+			break
+		}
+	}
+	if pkg.Latest == "" && len(pkg.Versions) > 0 {
+		pkg.Latest = pkg.Versions[len(pkg.Versions)-1]
+	}
+
+	// Modules without tagged versions only have a pseudo-version
+	if pkg.Latest == "" {
+		var info struct {
+			Version string
+			Time    time.Time
+		}
+		if err := c.get(ctx, c.GoProxyURL, "/"+escaped+"/@latest", &info); err != nil {
+			return nil, err
+		}
+		pkg.Latest = info.Version
+		pkg.Versions = []string{info.Version}
+		pkg.Published = map[string]time.Time{info.Version: info.Time}
+	}
+
+	escapedVersion, err := module.EscapeVersion(pkg.Latest)
+	if err != nil {
+		return pkg, nil
+	}
+	if pkg.Published == nil {
+		var info struct {
+			Time time.Time
+		}
+		switch err := c.get(ctx, c.GoProxyURL, "/"+escaped+"/@v/"+escapedVersion+".info", &info); {
+		case err == nil:
+			pkg.Published = map[string]time.Time{pkg.Latest: info.Time}
+		case !errors.Is(err, ErrNotFound):
+			return nil, err
+		}
+	}
+
+	// Retractions and deprecations are published in the latest go.mod
+	var goMod string
+	if err := c.get(ctx, c.GoProxyURL, "/"+escaped+"/@v/"+escapedVersion+".mod", &goMod); err != nil {
+		return nil, err
+	}
+	file, err := modfile.ParseLax("go.mod", []byte(goMod), nil)
+	if err != nil {
+		return pkg, nil
+	}
+	if file.Module != nil {
+		pkg.ModuleDeprecated = file.Module.Deprecated
+	}
+	for _, r := range file.Retract {
+		pkg.Retracted = append(pkg.Retracted, Retraction{Low: r.Low, High: r.High, Rationale: r.Rationale})
+	}
+	return pkg, nil
+}
+
+// SigningKey is a public key the npm registry signs packages with
+type SigningKey struct {
+	KeyID   string `json:"keyid"`
+	Key     string `json:"key"` // Base64 DER encoded public key
+	Expires string `json:"expires"`
+}
+
+// SigningKeys returns the npm registry's package signing keys
+func (c *Client) SigningKeys(ctx context.Context) ([]SigningKey, error) {
+	var doc struct {
+		Keys []SigningKey `json:"keys"`
+	}
+	if err := c.get(ctx, c.NPMURL, "/-/npm/v1/keys", &doc); err != nil {
+		return nil, err
+	}
+	return doc.Keys, nil
+}
+
+// Attestation is a Sigstore bundle attesting an npm version
+type Attestation struct {
+	PredicateType string `json:"predicateType"`
+	Bundle        struct {
+		DSSEEnvelope struct {
+			Payload     string `json:"payload"` // Base64 encoded in-toto statement
+			PayloadType string `json:"payloadType"`
+		} `json:"dsseEnvelope"`
+	} `json:"bundle"`
+}
+
+// Attestations returns the attestations published for an npm version
+func (c *Client) Attestations(ctx context.Context, name, v string) ([]Attestation, error) {
+	var doc struct {
+		Attestations []Attestation `json:"attestations"`
+	}
+	path := "/-/npm/v1/attestations/" + strings.Replace(url.PathEscape(name), "%40", "@", 1) + "@" + url.PathEscape(v)
+	if err := c.get(ctx, c.NPMURL, path, &doc); err != nil {
+		return nil, err
+	}
+	return doc.Attestations, nil
+}
+
+// Requirements returns the modules a Go module version requires in its
+// go.mod, mapped to the required versions
+func (c *Client) Requirements(ctx context.Context, path, v string) (map[string]string, error) {
+	if c.GoProxyURL == "" {
+		return nil, ErrNotFound
+	}
+	escaped, err := module.EscapePath(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+	escapedVersion, err := module.EscapeVersion(v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+
+	var goMod string
+	if err := c.get(ctx, c.GoProxyURL, "/"+escaped+"/@v/"+escapedVersion+".mod", &goMod); err != nil {
+		return nil, err
+	}
+	file, err := modfile.ParseLax("go.mod", []byte(goMod), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s@%s: %v", ErrRequestFailed, path, v, err)
+	}
+	requirements := make(map[string]string)
+	for _, req := range file.Require {
+		requirements[req.Mod.Path] = req.Mod.Version
+	}
+	return requirements, nil
+}
+
+// ModuleTime returns the time a Go module version was published, as the
+// proxy records it
+func (c *Client) ModuleTime(ctx context.Context, path, v string) (time.Time, error) {
+	if c.GoProxyURL == "" {
+		return time.Time{}, ErrNotFound
+	}
+	escaped, err := module.EscapePath(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+	escapedVersion, err := module.EscapeVersion(v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+
+	var info struct {
+		Time time.Time
+	}
+	if err := c.get(ctx, c.GoProxyURL, "/"+escaped+"/@v/"+escapedVersion+".info", &info); err != nil {
+		return time.Time{}, err
+	}
+	return info.Time, nil
+}
+
+// ModuleSize returns the size in bytes of a Go module version's zip file on
+// the proxy, as the go command downloads it
+func (c *Client) ModuleSize(ctx context.Context, path, v string) (int64, error) {
+	if c.GoProxyURL == "" {
+		return 0, ErrNotFound
+	}
+	escaped, err := module.EscapePath(path)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+	escapedVersion, err := module.EscapeVersion(v)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+
+	zipPath := "/" + escaped + "/@v/" + escapedVersion + ".zip"
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, strings.TrimSuffix(c.GoProxyURL, "/")+zipPath, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrRequestFailed, err)
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return 0, fmt.Errorf("%w: %s", ErrNotFound, zipPath)
+	case resp.StatusCode != http.StatusOK:
+		return 0, fmt.Errorf("%w: HEAD %s: %s", ErrRequestFailed, zipPath, resp.Status)
+	case resp.ContentLength < 0:
+		return 0, fmt.Errorf("%w: %s", ErrNotFound, zipPath)
+	}
+	return resp.ContentLength, nil
+}
+
+// get fetches a registry document. JSON responses are decoded into out,
+// others are read into a *string. Rate limited and failed requests are
+// retried by the HTTP client.
+func (c *Client) get(ctx context.Context, base, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(base, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.NPMRC != nil {
+		// Credentials are keyed by registry URL, so they only go to their own
+		if auth := c.NPMRC.Authorization(req.URL.String()); auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRequestFailed, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return fmt.Errorf("%w: %s", ErrNotFound, path)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%w: GET %s: %s", ErrRequestFailed, path, resp.Status)
+	}
+	if text, ok := out.(*string); ok {
+		data, err := io.ReadAll(resp.Body)
+		*text = string(data)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// LastRelease returns the publication time of the most recently published
+// version, or the zero time when unknown
+func (p *Package) LastRelease() time.Time {
+	var last time.Time
+	for _, published := range p.Published {
+		if published.After(last) {
+			last = published
+		}
+	}
+	return last
+}
+
+// Lookup fetches every distinct package of the dependencies from the source
+// with limited concurrency. Packages missing from their registry, such as
+// private packages, are left out of the result.
+func Lookup(ctx context.Context, source Source, deps []scanners.Dependency) (map[Key]*Package, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		packages = make(map[Key]*Package)
+		queue    = make(chan Key)
+	)
+
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range queue {
+				pkg, err := source.Package(ctx, key.Type, key.Name)
+
+				mu.Lock()
+				switch {
+				case errors.Is(err, ErrNotFound):
+				case err != nil && firstErr == nil:
+					firstErr = err
+					cancel()
+				case err == nil:
+					packages[key] = pkg
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	seen := make(map[Key]bool)
+	for _, dep := range deps {
+		key := Key{Type: dep.Type, Name: dep.Name}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		select {
+		case queue <- key:
+		case <-ctx.Done():
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	return packages, firstErr
+}
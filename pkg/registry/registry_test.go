@@ -0,0 +1,301 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/npmrc"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func newTestClient(url string) *Client {
+	client := NewClient()
+	client.NPMURL = url
+	client.GoProxyURL = url
+	return client
+}
+
+func TestClient_Package(t *testing.T) {
+	var rateLimited atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/lodash":
+			if rateLimited.Add(1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			fmt.Fprint(w, `{
+				"dist-tags": {"latest": "4.17.21", "next": "5.0.0-beta"},
+				"versions": {"4.17.21": {"scripts": {"test": "jest"}, "dist": {"unpackedSize": 1412415}, "dependencies": {"tslib": "^2.0.0"}, "_npmUser": {"name": "bnjmnt4n"}, "license": "MIT", "funding": [{"type": "github", "url": "https://github.com/sponsors/jdalton"}, "https://opencollective.com/lodash"]}, "4.2.0": {"deprecated": "use 4.17", "license": {"type": "BSD"}, "funding": {"type": "patreon"}}, "5.0.0-beta": {"funding": "https://example.com/fund"}, "3.0.0": {"deprecated": true}},
+				"time": {"4.17.21": "2021-02-20T15:42:16.891Z"},
+				"maintainers": [{"name": "mathias", "email": "mathias@qiwi.be"}, {"name": "jdalton"}]
+			}`)
+		case "/@types%2Fnode":
+			fmt.Fprint(w, `{"dist-tags": {"latest": "20.0.0"}, "versions": {"20.0.0": {}}}`)
+		case "/github.com/!burnt!sushi/toml/@v/list":
+			fmt.Fprint(w, "v1.2.0\nv1.10.0\nv1.11.0-rc.1\nv2.0.0+incompatible\n")
+		case "/github.com/!burnt!sushi/toml/@v/v1.10.0.info":
+			fmt.Fprint(w, `{"Version": "v1.10.0", "Time": "2023-05-22T10:00:00Z"}`)
+		case "/github.com/!burnt!sushi/toml/@v/v1.10.0.mod":
+			fmt.Fprint(w, "// Deprecated: use example.com/toml\nmodule github.com/BurntSushi/toml\n\nretract (\n\tv1.1.0 // Published accidentally\n\t[v1.3.0, v1.4.1]\n)\n")
+		case "/example.com/untagged/@v/v0.0.0-20240101000000-abcdefabcdef.mod":
+			fmt.Fprint(w, "module example.com/untagged\n")
+		case "/example.com/untagged/@v/list":
+		case "/example.com/untagged/@latest":
+			fmt.Fprint(w, `{"Version": "v0.0.0-20240101000000-abcdefabcdef", "Time": "2024-01-01T00:00:00Z"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	ctx := context.Background()
+
+	pkg, err := client.Package(ctx, "npm", "lodash")
+	assert.NoError(t, err)
+	assert.Equal(t, "4.17.21", pkg.Latest)
+	assert.Equal(t, []string{"3.0.0", "4.2.0", "4.17.21", "5.0.0-beta"}, pkg.Versions)
+	assert.Equal(t, map[string]string{"4.2.0": "use 4.17", "3.0.0": "deprecated"}, pkg.Deprecated)
+	assert.Equal(t, map[string]map[string]string{"4.17.21": {"test": "jest"}}, pkg.Scripts)
+	assert.Equal(t, int64(1412415), pkg.Dist["4.17.21"].UnpackedSize)
+	assert.Equal(t, map[string]map[string]string{"4.17.21": {"tslib": "^2.0.0"}}, pkg.Dependencies)
+	assert.Equal(t, map[string]string{"4.17.21": "bnjmnt4n"}, pkg.Publishers)
+	assert.Equal(t, map[string][]string{"4.17.21": {"https://github.com/sponsors/jdalton", "https://opencollective.com/lodash"}, "5.0.0-beta": {"https://example.com/fund"}}, pkg.Funding)
+	assert.Equal(t, map[string]string{"4.17.21": "MIT", "4.2.0": "BSD"}, pkg.Licenses)
+	assert.Equal(t, []string{"mathias", "jdalton"}, pkg.Maintainers)
+	assert.Equal(t, time.Date(2021, 2, 20, 15, 42, 16, 891000000, time.UTC), pkg.Published["4.17.21"])
+
+	pkg, err = client.Package(ctx, "npm", "@types/node")
+	assert.NoError(t, err)
+	assert.Equal(t, "20.0.0", pkg.Latest)
+
+	pkg, err = client.Package(ctx, "go", "github.com/BurntSushi/toml")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.10.0", pkg.Latest)
+	assert.Equal(t, []string{"v1.2.0", "v1.10.0", "v1.11.0-rc.1"}, pkg.Versions)
+	assert.Equal(t, "use example.com/toml", pkg.ModuleDeprecated)
+	assert.Equal(t, time.Date(2023, 5, 22, 10, 0, 0, 0, time.UTC), pkg.LastRelease())
+	assert.Equal(t, []Retraction{
+		{Low: "v1.1.0", High: "v1.1.0", Rationale: "Published accidentally"},
+		{Low: "v1.3.0", High: "v1.4.1"},
+	}, pkg.Retracted)
+
+	cached, err := client.Package(ctx, "go", "github.com/BurntSushi/toml")
+	assert.NoError(t, err)
+	assert.Same(t, pkg, cached)
+
+	pkg, err = client.Package(ctx, "go", "example.com/untagged")
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.0.0-20240101000000-abcdefabcdef", pkg.Latest)
+
+	_, err = client.Package(ctx, "npm", "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = client.Package(ctx, "maven", "junit")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestClient_NPMRC(t *testing.T) {
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"dist-tags": {"latest": "4.17.21"}, "versions": {"4.17.21": {}}}`)
+	}))
+	defer public.Close()
+	private := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.URL.EscapedPath() != "/npm/@myorg%2Fui" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"dist-tags": {"latest": "1.0.0"}, "versions": {"1.0.0": {}}}`)
+	}))
+	defer private.Close()
+
+	client := newTestClient(public.URL)
+	client.NPMRC = npmrc.Parse([]byte("@myorg:registry="+private.URL+"/npm/\n"+strings.TrimPrefix(private.URL, "http:")+"/npm/:_authToken=secret\n"), nil)
+	ctx := context.Background()
+
+	pkg, err := client.Package(ctx, "npm", "@myorg/ui")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1.0.0", pkg.Latest)
+	}
+	pkg, err = client.Package(ctx, "npm", "lodash")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "4.17.21", pkg.Latest)
+	}
+}
+
+func TestClient_Signatures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/@scope%2Fpkg":
+			fmt.Fprint(w, `{"dist-tags": {"latest": "1.0.0"}, "versions": {"1.0.0": {"dist": {
+				"integrity": "sha512-abc==",
+				"signatures": [{"keyid": "SHA256:key", "sig": "MEUC"}],
+				"attestations": {"url": "https://registry.npmjs.org/-/npm/v1/attestations/@scope%2fpkg@1.0.0", "provenance": {"predicateType": "https://slsa.dev/provenance/v1"}}
+			}}}}`)
+		case "/-/npm/v1/keys":
+			fmt.Fprint(w, `{"keys": [{"keyid": "SHA256:key", "key": "MFkw", "expires": null}]}`)
+		case "/-/npm/v1/attestations/@scope%2Fpkg@1.0.0":
+			fmt.Fprint(w, `{"attestations": [{"predicateType": "https://slsa.dev/provenance/v1", "bundle": {"dsseEnvelope": {"payload": "e30=", "payloadType": "application/vnd.in-toto+json"}}}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	ctx := context.Background()
+
+	pkg, err := client.Package(ctx, "npm", "@scope/pkg")
+	if !assert.NoError(t, err) {
+		return
+	}
+	dist := pkg.Dist["1.0.0"]
+	assert.Equal(t, "sha512-abc==", dist.Integrity)
+	assert.Equal(t, []Signature{{KeyID: "SHA256:key", Sig: "MEUC"}}, dist.Signatures)
+	if assert.NotNil(t, dist.Attestations) {
+		assert.Equal(t, "https://slsa.dev/provenance/v1", dist.Attestations.Provenance.PredicateType)
+	}
+
+	keys, err := client.SigningKeys(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []SigningKey{{KeyID: "SHA256:key", Key: "MFkw"}}, keys)
+
+	attestations, err := client.Attestations(ctx, "@scope/pkg", "1.0.0")
+	if assert.NoError(t, err) && assert.Len(t, attestations, 1) {
+		assert.Equal(t, "https://slsa.dev/provenance/v1", attestations[0].PredicateType)
+		assert.Equal(t, "e30=", attestations[0].Bundle.DSSEEnvelope.Payload)
+	}
+
+	_, err = client.Attestations(ctx, "@scope/pkg", "2.0.0")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestClient_ModuleSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead || r.URL.EscapedPath() != "/github.com/!burnt!sushi/toml/@v/v1.3.2.zip" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Length", "123456")
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	size, err := client.ModuleSize(context.Background(), "github.com/BurntSushi/toml", "v1.3.2")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(123456), size)
+
+	_, err = client.ModuleSize(context.Background(), "github.com/BurntSushi/toml", "v9.9.9")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestClient_ModuleTime(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/github.com/!burnt!sushi/toml/@v/v1.3.2.info" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"Version": "v1.3.2", "Time": "2023-06-08T06:13:02Z"}`)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	published, err := client.ModuleTime(context.Background(), "github.com/BurntSushi/toml", "v1.3.2")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 6, 8, 6, 13, 2, 0, time.UTC), published)
+
+	_, err = client.ModuleTime(context.Background(), "github.com/BurntSushi/toml", "v9.9.9")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestClient_Requirements(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/github.com/!burnt!sushi/toml/@v/v1.3.2.mod" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "module github.com/BurntSushi/toml\n\ngo 1.16\n\nrequire (\n\tgolang.org/x/text v0.3.0\n\tgithub.com/pkg/errors v0.9.1 // indirect\n)\n")
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	requirements, err := client.Requirements(context.Background(), "github.com/BurntSushi/toml", "v1.3.2")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"golang.org/x/text": "v0.3.0", "github.com/pkg/errors": "v0.9.1"}, requirements)
+
+	_, err = client.Requirements(context.Background(), "github.com/BurntSushi/toml", "v9.9.9")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPackage_Retraction(t *testing.T) {
+	pkg := &Package{Retracted: []Retraction{{Low: "v1.1.0", High: "v1.1.0", Rationale: "broken"}, {Low: "v1.3.0", High: "v1.4.1"}}}
+
+	r, ok := pkg.Retraction("v1.1.0")
+	assert.True(t, ok)
+	assert.Equal(t, "broken", r.Rationale)
+
+	_, ok = pkg.Retraction("v1.4.0")
+	assert.True(t, ok)
+	_, ok = pkg.Retraction("v1.2.0")
+	assert.False(t, ok)
+	_, ok = pkg.Retraction("v1.4.2")
+	assert.False(t, ok)
+}
+
+func TestPackage_LastRelease(t *testing.T) {
+	assert.True(t, (&Package{}).LastRelease().IsZero())
+
+	pkg := &Package{Published: map[string]time.Time{
+		"2.0.0": time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		"1.0.1": time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), // Backported fix
+		"1.0.0": time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+	}}
+	assert.Equal(t, time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), pkg.LastRelease())
+}
+
+func TestGoProxy(t *testing.T) {
+	assert.Equal(t, DefaultGoProxyURL, goProxy(""))
+	assert.Equal(t, "https://goproxy.example.com", goProxy("https://goproxy.example.com,direct"))
+	assert.Equal(t, "https://b.example.com", goProxy("direct|https://b.example.com"))
+	assert.Equal(t, "", goProxy("off"))
+	assert.Equal(t, "", goProxy("direct"))
+}
+
+type fakeSource map[Key]*Package
+
+func (s fakeSource) Package(ctx context.Context, depType, name string) (*Package, error) {
+	if pkg, ok := s[Key{Type: depType, Name: name}]; ok {
+		return pkg, nil
+	}
+	if name == "broken" {
+		return nil, ErrRequestFailed
+	}
+	return nil, ErrNotFound
+}
+
+func TestLookup(t *testing.T) {
+	lodash := &Package{Name: "lodash", Latest: "4.17.21"}
+	source := fakeSource{{Type: "npm", Name: "lodash"}: lodash}
+
+	packages, err := Lookup(context.Background(), source, []scanners.Dependency{
+		{Name: "lodash", Type: "npm", Version: "4.17.15"},
+		{Name: "lodash", Type: "npm", Version: "4.17.21"},
+		{Name: "private", Type: "npm"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[Key]*Package{{Type: "npm", Name: "lodash"}: lodash}, packages)
+
+	_, err = Lookup(context.Background(), source, []scanners.Dependency{{Name: "broken", Type: "npm"}})
+	assert.ErrorIs(t, err, ErrRequestFailed)
+}
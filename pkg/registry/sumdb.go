@@ -0,0 +1,195 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb"
+)
+
+// DefaultSumDB is the verifier key of the public Go checksum database
+const DefaultSumDB = "sum.golang.org+033de0ae+Ac4zctda0e5eza+HJyk9SxEdh+s3Ux18htTTAD8OuAn8"
+
+// ErrNoSumDB is returned for modules the checksum database does not cover,
+// because it is disabled or the module is private
+var ErrNoSumDB = errors.New("module not covered by the checksum database")
+
+// ChecksumDB looks up module checksums in a Go checksum database, verifying
+// every record against the database's signed transparency log
+type ChecksumDB struct {
+	client   *sumdb.Client
+	ops      *sumdbOps
+	disabled bool
+}
+
+// NewChecksumDB creates a checksum database client configured like the go
+// command, from GOSUMDB, GONOSUMDB and GOPRIVATE, which reaches the database
+// with client
+func NewChecksumDB(client *Client) *ChecksumDB {
+	env := os.Getenv("GOSUMDB")
+	if env == "off" {
+		return &ChecksumDB{disabled: true}
+	}
+
+	key, url := DefaultSumDB, "https://sum.golang.org"
+	switch fields := strings.Fields(env); {
+	case len(fields) == 0, fields[0] == "sum.golang.org":
+	case fields[0] == "sum.golang.google.cn":
+		url = "https://sum.golang.google.cn"
+	default:
+		key = fields[0]
+		url = "https://" + strings.SplitN(key, "+", 2)[0]
+		if len(fields) > 1 {
+			url = fields[1]
+		}
+	}
+
+	db := OpenChecksumDB(key, url, client)
+	private := os.Getenv("GONOSUMDB")
+	if private == "" {
+		private = os.Getenv("GOPRIVATE")
+	}
+	db.client.SetGONOSUMDB(private)
+	return db
+}
+
+// OpenChecksumDB creates a client for the checksum database with the given
+// verifier key served at url. Like the go command, it reaches the database
+// through the client's Go module proxy when the proxy supports it.
+func OpenChecksumDB(key, url string, client *Client) *ChecksumDB {
+	ops := &sumdbOps{
+		key:    key,
+		name:   strings.SplitN(key, "+", 2)[0],
+		url:    url,
+		client: client,
+		config: make(map[string][]byte),
+		cache:  make(map[string][]byte),
+		gone:   make(map[string]bool),
+	}
+	return &ChecksumDB{client: sumdb.NewClient(ops), ops: ops}
+}
+
+// Checksum returns the "h1:" hash of a module version's zip file as logged
+// in the checksum database, or of its go.mod file for a "<version>/go.mod"
+// version
+func (db *ChecksumDB) Checksum(path, version string) (string, error) {
+	if db.disabled {
+		return "", ErrNoSumDB
+	}
+
+	lines, err := db.client.Lookup(path, version)
+	switch {
+	case errors.Is(err, sumdb.ErrGONOSUMDB):
+		return "", ErrNoSumDB
+	case err != nil && db.ops.missing(path, version):
+		return "", ErrNotFound
+	case err != nil:
+		return "", err
+	}
+	for _, line := range lines {
+		if fields := strings.Fields(line); len(fields) == 3 && fields[1] == version {
+			return fields[2], nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// sumdbOps implements sumdb.ClientOps in memory, so the checksum database
+// is verified from scratch on every run
+type sumdbOps struct {
+	key    string
+	name   string
+	url    string
+	client *Client
+	once   sync.Once
+
+	mu     sync.Mutex
+	config map[string][]byte
+	cache  map[string][]byte
+	gone   map[string]bool // Remote paths answered with 404 or 410
+}
+
+func (o *sumdbOps) ReadRemote(path string) ([]byte, error) {
+	o.once.Do(o.useProxy)
+
+	var data string
+	err := o.client.get(context.Background(), o.url, path, &data)
+	if errors.Is(err, ErrNotFound) {
+		o.mu.Lock()
+		o.gone[path] = true
+		o.mu.Unlock()
+	}
+	return []byte(data), err
+}
+
+// useProxy switches to the Go module proxy if it serves the database
+func (o *sumdbOps) useProxy() {
+	if o.client.GoProxyURL == "" {
+		return
+	}
+	proxy := strings.TrimSuffix(o.client.GoProxyURL, "/") + "/sumdb/" + o.name
+	var supported string
+	if err := o.client.get(context.Background(), proxy, "/supported", &supported); err == nil {
+		o.url = proxy
+	}
+}
+
+// missing reports whether the database has no record of the module version
+func (o *sumdbOps) missing(path, version string) bool {
+	escapedPath, err := module.EscapePath(path)
+	if err != nil {
+		return false
+	}
+	escapedVersion, err := module.EscapeVersion(strings.TrimSuffix(version, "/go.mod"))
+	if err != nil {
+		return false
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.gone["/lookup/"+escapedPath+"@"+escapedVersion]
+}
+
+func (o *sumdbOps) ReadConfig(file string) ([]byte, error) {
+	if file == "key" {
+		return []byte(o.key), nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.config[file], nil
+}
+
+func (o *sumdbOps) WriteConfig(file string, old, new []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if string(o.config[file]) != string(old) {
+		return sumdb.ErrWriteConflict
+	}
+	o.config[file] = new
+	return nil
+}
+
+func (o *sumdbOps) ReadCache(file string) ([]byte, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	data, ok := o.cache[file]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+func (o *sumdbOps) WriteCache(file string, data []byte) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.cache[file] = data
+}
+
+func (o *sumdbOps) Log(msg string) {}
+
+// SecurityError is reported through the error of the failed lookup
+func (o *sumdbOps) SecurityError(msg string) {}
@@ -0,0 +1,90 @@
+package registry
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+)
+
+func TestChecksumDB(t *testing.T) {
+	skey, vkey, err := note.GenerateKey(rand.Reader, "sum.example.com")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	server := httptest.NewServer(sumdb.NewServer(sumdb.NewTestServer(skey, func(path, vers string) ([]byte, error) {
+		if path != "example.com/mod" {
+			return nil, os.ErrNotExist
+		}
+		return []byte(fmt.Sprintf("%s %s h1:zip=\n%s %s/go.mod h1:mod=\n", path, vers, path, vers)), nil
+	})))
+	defer server.Close()
+
+	// The server does not proxy the database, so it is used directly
+	db := OpenChecksumDB(vkey, server.URL, newTestClient(server.URL))
+
+	sum, err := db.Checksum("example.com/mod", "v1.2.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "h1:zip=", sum)
+
+	sum, err = db.Checksum("example.com/mod", "v1.2.0/go.mod")
+	assert.NoError(t, err)
+	assert.Equal(t, "h1:mod=", sum)
+
+	// Later lookups verify against the tree seen so far
+	sum, err = db.Checksum("example.com/mod", "v1.3.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "h1:zip=", sum)
+
+	_, err = db.Checksum("example.com/missing", "v1.0.0")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = db.Checksum("example.com/missing", "v1.0.0/go.mod")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	private := OpenChecksumDB(vkey, server.URL, newTestClient(server.URL))
+	private.client.SetGONOSUMDB("example.com/private")
+	_, err = private.Checksum("example.com/private/mod", "v1.0.0")
+	assert.ErrorIs(t, err, ErrNoSumDB)
+
+	// Records signed with another key are rejected
+	_, otherKey, err := note.GenerateKey(rand.Reader, "sum.example.com")
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = OpenChecksumDB(otherKey, server.URL, newTestClient(server.URL)).Checksum("example.com/mod", "v1.2.0")
+	assert.Error(t, err)
+}
+
+func TestChecksumDB_Proxy(t *testing.T) {
+	skey, vkey, err := note.GenerateKey(rand.Reader, "sum.example.com")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sumdb/sum.example.com/supported", func(w http.ResponseWriter, r *http.Request) {})
+	mux.Handle("/sumdb/sum.example.com/", http.StripPrefix("/sumdb/sum.example.com", sumdb.NewServer(sumdb.NewTestServer(skey, func(path, vers string) ([]byte, error) {
+		return []byte(fmt.Sprintf("%s %s h1:zip=\n", path, vers)), nil
+	}))))
+	proxy := httptest.NewServer(mux)
+	defer proxy.Close()
+
+	db := OpenChecksumDB(vkey, "https://sum.example.invalid", newTestClient(proxy.URL))
+	sum, err := db.Checksum("example.com/mod", "v1.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "h1:zip=", sum)
+}
+
+func TestNewChecksumDB_Off(t *testing.T) {
+	t.Setenv("GOSUMDB", "off")
+
+	_, err := NewChecksumDB(NewClient()).Checksum("example.com/mod", "v1.0.0")
+	assert.ErrorIs(t, err, ErrNoSumDB)
+}
@@ -0,0 +1,220 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/santoshdahal12/deplister/pkg/tracing"
+)
+
+// Common errors
+var (
+	ErrInvalidRepo = errors.New("invalid repository")
+	ErrCloneFailed = errors.New("clone failed")
+)
+
+// Repo identifies a git repository and the ref to check out
+type Repo struct {
+	URL string // Clone URL of the repository
+	Ref string // Branch, tag or commit; empty for the default branch
+}
+
+// ParseRepo splits a repository spec of the form url[@ref]. An "@" only
+// separates the ref when it appears in the path, so URLs such as
+// git@github.com:org/repo and ssh://git@github.com/org/repo keep their user
+// name.
+func ParseRepo(spec string) (Repo, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return Repo{}, ErrInvalidRepo
+	}
+
+	path := strings.Index(spec, "/")
+	if scheme := strings.Index(spec, "://"); scheme >= 0 {
+		if path = strings.Index(spec[scheme+3:], "/"); path >= 0 {
+			path += scheme + 3
+		}
+	}
+	repo := Repo{URL: spec}
+	if i := strings.LastIndex(spec, "@"); path >= 0 && i > path {
+		repo = Repo{URL: spec[:i], Ref: spec[i+1:]}
+		if repo.Ref == "" {
+			return Repo{}, ErrInvalidRepo
+		}
+	}
+	if err := repo.Validate(); err != nil {
+		return Repo{}, err
+	}
+	return repo, nil
+}
+
+var allowLocal atomic.Bool
+
+// AllowLocal lets later clones read local paths and file:// URLs, which are
+// rejected by default because they expose the host's file system to whoever
+// names the repository
+func AllowLocal(allow bool) {
+	allowLocal.Store(allow)
+}
+
+// Validate checks that the repository can be passed to git safely. URLs
+// must use https, ssh or git, or the scp-like user@host:path syntax, and
+// neither the URL nor the ref may start with "-", which git would read as an
+// option such as --upload-pack. Transport helpers such as ext:: are never
+// accepted.
+func (r Repo) Validate() error {
+	if strings.HasPrefix(r.Ref, "-") {
+		return fmt.Errorf("%w: ref %q starts with -", ErrInvalidRepo, r.Ref)
+	}
+	if strings.HasPrefix(r.URL, "-") {
+		return fmt.Errorf("%w: url %q starts with -", ErrInvalidRepo, r.URL)
+	}
+
+	local := allowLocal.Load()
+	if scheme, rest, ok := strings.Cut(r.URL, "://"); ok {
+		switch strings.ToLower(scheme) {
+		case "https", "ssh", "git":
+			host, _, _ := strings.Cut(rest, "/")
+			return validHost(host, r.URL)
+		case "file":
+			if local {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: unsupported scheme %s://, expected https, ssh or git", ErrInvalidRepo, scheme)
+	}
+	if strings.Contains(r.URL, "::") {
+		return fmt.Errorf("%w: transport helpers are not supported: %q", ErrInvalidRepo, r.URL)
+	}
+
+	// Like git, a colon before the first slash makes an scp-like ssh URL
+	if i := strings.Index(r.URL, ":"); i > 0 && !strings.Contains(r.URL[:i], "/") {
+		return validHost(r.URL[:i], r.URL)
+	}
+	if local {
+		return nil
+	}
+	return fmt.Errorf("%w: local repositories are not allowed: %q", ErrInvalidRepo, r.URL)
+}
+
+// validHost checks the [user@]host[:port] part of a URL, whose host ssh would
+// read as an option if it started with "-"
+func validHost(authority, url string) error {
+	host := strings.TrimPrefix(authority[strings.LastIndex(authority, "@")+1:], "[")
+	if host == "" || strings.HasPrefix(host, "-") {
+		return fmt.Errorf("%w: invalid host in %q", ErrInvalidRepo, url)
+	}
+	return nil
+}
+
+// Clone performs a shallow clone of the repository into a temporary
+// directory. The returned cleanup function removes the checkout.
+func Clone(ctx context.Context, repo Repo) (_ string, _ func(), err error) {
+	ctx, span := tracing.Start(ctx, "git.clone", attribute.String("vcs.repository.url", repo.URL), attribute.String("vcs.ref", repo.Ref))
+	defer func() { tracing.End(span, err) }()
+
+	if err := repo.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "deplister-repo")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	ref := repo.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	// Fetching a single ref works for branches, tags and commit hashes alike.
+	// --end-of-options keeps the URL and ref from being read as options.
+	steps := [][]string{
+		{"init", "--quiet"},
+		{"remote", "add", "--end-of-options", "origin", repo.URL},
+		{"fetch", "--quiet", "--depth", "1", "--end-of-options", "origin", ref},
+		{"checkout", "--quiet", "FETCH_HEAD"},
+	}
+	for _, args := range steps {
+		if err := runGit(ctx, dir, args...); err != nil {
+			cleanup()
+			return "", nil, err
+		}
+	}
+
+	return dir, cleanup, nil
+}
+
+// Credential is the HTTP basic authentication sent to repositories whose URL
+// starts with Prefix, such as a token for the API a list of repositories came
+// from
+type Credential struct {
+	Prefix   string
+	Username string
+	Password string
+}
+
+var (
+	credentialsMu sync.Mutex
+	credentials   []Credential
+)
+
+// AddCredential authenticates later clones of the repositories under the
+// credential's prefix. It is passed to git in its environment, so it never
+// appears in the clone URL or in error messages.
+func AddCredential(credential Credential) {
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+	credentials = append(credentials, credential)
+}
+
+// gitEnv returns the environment of git commands: the process environment
+// without terminal prompts, limited to the protocols Validate accepts, and an
+// http.<prefix>.extraHeader setting for every credential
+func gitEnv() []string {
+	protocols := "https:ssh:git"
+	if allowLocal.Load() {
+		protocols += ":file"
+	}
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ALLOW_PROTOCOL="+protocols)
+
+	credentialsMu.Lock()
+	defer credentialsMu.Unlock()
+	if len(credentials) == 0 {
+		return env
+	}
+	count, _ := strconv.Atoi(os.Getenv("GIT_CONFIG_COUNT"))
+	for _, credential := range credentials {
+		auth := base64.StdEncoding.EncodeToString([]byte(credential.Username + ":" + credential.Password))
+		env = append(env,
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=http.%s.extraHeader", count, credential.Prefix),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=Authorization: Basic %s", count, auth),
+		)
+		count++
+	}
+	return append(env, "GIT_CONFIG_COUNT="+strconv.Itoa(count))
+}
+
+func runGit(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = gitEnv()
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := tracing.Run(ctx, cmd); err != nil {
+		return fmt.Errorf("%w: git %s: %s", ErrCloneFailed, args[0], strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package risk
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/age"
+	"github.com/santoshdahal12/deplister/pkg/maintainers"
+	"github.com/santoshdahal12/deplister/pkg/provenance"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Enrichment is the name of the risk scoring step in ScanOptions.Enrich
+const Enrichment = "risk"
+
+// Properties set on scored dependencies
+const (
+	Score   = "riskScore"   // Composite risk from 0 to 100
+	Factors = "riskFactors" // Signals raising the score, largest contribution first, comma separated
+)
+
+// Signals combined into the score. Each is rated from 0 to 1 per dependency.
+const (
+	Depth           = "depth"           // Minimum depth, rising to 1 at MaxDepth levels
+	Maintainers     = "maintainers"     // 1 over the number of maintainers
+	Age             = "age"             // Days since the release of the version, rising to 1 at age.StaleDays
+	Vulnerabilities = "vulnerabilities" // Highest severity of the known vulnerabilities
+	Scripts         = "scripts"         // 1 for packages with install scripts
+	Provenance      = "provenance"      // 0 for attested or verified packages up to 1 for invalid ones
+)
+
+// Signals lists the signals in the order factors are reported in on ties
+var Signals = []string{Vulnerabilities, Scripts, Provenance, Maintainers, Age, Depth}
+
+// MaxDepth is the depth from which the depth signal is 1
+const MaxDepth = 10
+
+// Weights weighs the signals of the score, keyed by signal. A weight of 0
+// leaves a signal out of the score.
+type Weights map[string]float64
+
+// DefaultWeights are used for signals a policy does not weigh
+var DefaultWeights = Weights{
+	Vulnerabilities: 5,
+	Scripts:         2,
+	Provenance:      2,
+	Maintainers:     2,
+	Age:             1,
+	Depth:           1,
+}
+
+// WithDefaults returns the weights with DefaultWeights filled in for the
+// signals they do not weigh
+func (w Weights) WithDefaults() Weights {
+	merged := make(Weights, len(DefaultWeights))
+	for signal, weight := range DefaultWeights {
+		merged[signal] = weight
+	}
+	for signal, weight := range w {
+		merged[signal] = weight
+	}
+	return merged
+}
+
+// ParseSignal checks a signal name and weight, as written in a policy
+func ParseSignal(signal, weight string) (float64, error) {
+	if !slices.Contains(Signals, signal) {
+		return 0, fmt.Errorf("unknown risk signal %q, expected one of %s", signal, strings.Join(Signals, ", "))
+	}
+	value, err := strconv.ParseFloat(weight, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid weight %q of risk signal %s", weight, signal)
+	}
+	return value, nil
+}
+
+// severities rates the severity of a vulnerability; unknown severities rate
+// as low
+var severities = map[string]float64{"CRITICAL": 1, "HIGH": 0.75, "MEDIUM": 0.5, "LOW": 0.25}
+
+// provenances rates the provenance statuses
+var provenances = map[string]float64{
+	provenance.Attested: 0,
+	provenance.Verified: 0,
+	provenance.Signed:   0.5,
+	provenance.Logged:   0.5,
+	provenance.Unsigned: 0.75,
+	provenance.Unlisted: 0.75,
+	provenance.Invalid:  1,
+}
+
+// Enrich scores every dependency of the result as the weighted mean of its
+// signals, scaled to 100. Signals unknown for a dependency, such as its
+// maintainers when they were not looked up, are left out of its mean.
+// vulnsChecked tells whether vulnerabilities were looked up, so that no
+// vulnerabilities counts as a known 0.
+func Enrich(result *scanners.ScanResult, weights Weights, vulnsChecked bool) {
+	weights = weights.WithDefaults()
+	for i := range result.Dependencies {
+		dep := &result.Dependencies[i]
+		score, factors := score(*dep, weights, vulnsChecked)
+		if dep.Properties == nil {
+			dep.Properties = make(map[string]string)
+		}
+		dep.Properties[Score] = strconv.Itoa(score)
+		if len(factors) > 0 {
+			dep.Properties[Factors] = strings.Join(factors, ",")
+		} else {
+			delete(dep.Properties, Factors)
+		}
+	}
+}
+
+// score returns the score of a dependency and the signals raising it
+func score(dep scanners.Dependency, weights Weights, vulnsChecked bool) (int, []string) {
+	signals := rate(dep, vulnsChecked)
+
+	var total, sum float64
+	contributions := make(map[string]float64)
+	for signal, rating := range signals {
+		weight := weights[signal]
+		if weight <= 0 {
+			continue
+		}
+		total += weight
+		sum += weight * rating
+		if rating > 0 {
+			contributions[signal] = weight * rating
+		}
+	}
+	if total == 0 {
+		return 0, nil
+	}
+
+	var factors []string
+	for _, signal := range Signals {
+		if contributions[signal] > 0 {
+			factors = append(factors, signal)
+		}
+	}
+	sort.SliceStable(factors, func(i, j int) bool { return contributions[factors[i]] > contributions[factors[j]] })
+	return int(100*sum/total + 0.5), factors
+}
+
+// rate returns the rating of each signal known for the dependency
+func rate(dep scanners.Dependency, vulnsChecked bool) map[string]float64 {
+	signals := map[string]float64{
+		Depth:   float64(min(max(dep.Depth, 0), MaxDepth)) / MaxDepth,
+		Scripts: 0,
+	}
+	if dep.Properties["installScripts"] != "" || dep.Properties["hasInstallScript"] == "true" {
+		signals[Scripts] = 1
+	}
+	if n, err := strconv.Atoi(dep.Properties[maintainers.CountProperty]); err == nil && n > 0 {
+		signals[Maintainers] = 1 / float64(n)
+	}
+	if days, err := strconv.Atoi(dep.Properties[age.DaysSince]); err == nil {
+		signals[Age] = min(float64(max(days, 0))/age.StaleDays, 1)
+	}
+	if status, ok := dep.Properties["provenance"]; ok {
+		if rating, known := provenances[status]; known {
+			signals[Provenance] = rating
+		}
+	}
+	if vulnsChecked || len(dep.Vulnerabilities) > 0 {
+		signals[Vulnerabilities] = 0
+		for _, vuln := range dep.Vulnerabilities {
+			rating, ok := severities[strings.ToUpper(vuln.Severity)]
+			if !ok {
+				rating = severities["LOW"]
+			}
+			signals[Vulnerabilities] = max(signals[Vulnerabilities], rating)
+		}
+	}
+	return signals
+}
+
+// Sort orders dependencies by descending risk score, keeping the order of
+// dependencies with the same score. Unscored dependencies come last.
+func Sort(deps []scanners.Dependency) {
+	scoreOf := func(dep scanners.Dependency) int {
+		if n, err := strconv.Atoi(dep.Properties[Score]); err == nil {
+			return n
+		}
+		return -1
+	}
+	sort.SliceStable(deps, func(i, j int) bool { return scoreOf(deps[i]) > scoreOf(deps[j]) })
+}
@@ -0,0 +1,47 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func TestEnrich(t *testing.T) {
+	result := &scanners.ScanResult{Dependencies: []scanners.Dependency{
+		{Name: "safe", Depth: 1, Properties: map[string]string{"maintainerCount": "4", "daysSinceRelease": "30", "provenance": "attested"}},
+		{Name: "risky", Depth: 5, Properties: map[string]string{"maintainerCount": "1", "installScripts": "postinstall", "provenance": "unsigned"},
+			Vulnerabilities: []scanners.Vulnerability{{ID: "GHSA-1", Severity: "MEDIUM"}, {ID: "GHSA-2", Severity: "CRITICAL"}}},
+		{Name: "unknown", Depth: 20},
+	}}
+
+	Enrich(result, nil, true)
+	// (1*0.1 + 2*0.25 + 1*30/730) / 13, with vulnerabilities, scripts and provenance at 0
+	assert.Equal(t, "5", result.Dependencies[0].Properties[Score])
+	assert.Equal(t, "maintainers,depth,age", result.Dependencies[0].Properties[Factors])
+	// (5*1 + 2*1 + 2*0.75 + 2*1 + 1*0.5) / 12
+	assert.Equal(t, "92", result.Dependencies[1].Properties[Score])
+	assert.Equal(t, "vulnerabilities,scripts,maintainers,provenance,depth", result.Dependencies[1].Properties[Factors])
+	// Only depth, scripts and vulnerabilities are known: 1 / 8
+	assert.Equal(t, "13", result.Dependencies[2].Properties[Score])
+
+	Enrich(result, Weights{Vulnerabilities: 0, Depth: 0}, false)
+	assert.Equal(t, "0", result.Dependencies[2].Properties[Score])
+	assert.NotContains(t, result.Dependencies[2].Properties, Factors)
+	assert.NotContains(t, result.Dependencies[1].Properties[Factors], Vulnerabilities)
+
+	Sort(result.Dependencies)
+	assert.Equal(t, []string{"risky", "safe", "unknown"}, []string{result.Dependencies[0].Name, result.Dependencies[1].Name, result.Dependencies[2].Name})
+}
+
+func TestParseSignal(t *testing.T) {
+	weight, err := ParseSignal(Age, "2.5")
+	assert.NoError(t, err)
+	assert.Equal(t, 2.5, weight)
+
+	_, err = ParseSignal("popularity", "1")
+	assert.Error(t, err)
+	_, err = ParseSignal(Age, "-1")
+	assert.Error(t, err)
+}
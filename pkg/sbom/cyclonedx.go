@@ -0,0 +1,114 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+type cdxComponent struct {
+	BOMRef   string `json:"bom-ref"`
+	Name     string `json:"name"`
+	Group    string `json:"group"`
+	Version  string `json:"version"`
+	PURL     string `json:"purl"`
+	Licenses []struct {
+		License struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"license"`
+		Expression string `json:"expression"`
+	} `json:"licenses"`
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxDocument struct {
+	Metadata struct {
+		Component *cdxComponent `json:"component"`
+	} `json:"metadata"`
+	Components   []cdxComponent `json:"components"`
+	Dependencies []struct {
+		Ref       string   `json:"ref"`
+		DependsOn []string `json:"dependsOn"`
+	} `json:"dependencies"`
+}
+
+// license returns the SPDX expression of the component's licenses
+func (c cdxComponent) license() string {
+	var ids []string
+	for _, license := range c.Licenses {
+		switch {
+		case license.Expression != "":
+			return license.Expression
+		case license.License.ID != "":
+			ids = append(ids, license.License.ID)
+		case license.License.Name != "":
+			ids = append(ids, license.License.Name)
+		}
+	}
+	return strings.Join(ids, " OR ")
+}
+
+// parseCycloneDX reads the components of a CycloneDX JSON document, nested
+// ones included. The metadata component is the project, and its dependencies
+// are the direct dependencies; without a dependency graph every component is
+// direct.
+func parseCycloneDX(data []byte) (*scanners.ScanResult, error) {
+	var doc cdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+
+	var components []component
+	var walk func([]cdxComponent)
+	walk = func(list []cdxComponent) {
+		for _, c := range list {
+			name := c.Name
+			if c.Group != "" {
+				name = c.Group + "/" + c.Name
+			}
+			// Components without a bom-ref cannot be referenced
+			id := c.BOMRef
+			if id == "" {
+				id = fmt.Sprintf("#%d", len(components))
+			}
+			components = append(components, component{
+				id:      id,
+				purl:    c.PURL,
+				name:    name,
+				version: c.Version,
+				license: c.license(),
+			})
+			walk(c.Components)
+		}
+	}
+	walk(doc.Components)
+
+	root := ""
+	if doc.Metadata.Component != nil {
+		root = doc.Metadata.Component.BOMRef
+	}
+	edges := make(map[string][]string)
+	for _, dep := range doc.Dependencies {
+		from := dep.Ref
+		if from == root {
+			from = ""
+		}
+		edges[from] = append(edges[from], dep.DependsOn...)
+	}
+	if len(edges[""]) == 0 {
+		for _, c := range components {
+			edges[""] = append(edges[""], c.id)
+		}
+	}
+	result := build(CycloneDX, components, edges)
+	if subject := doc.Metadata.Component; subject != nil && subject.Name != "" {
+		result.Project = &scanners.Project{Name: subject.Name, Version: subject.Version}
+		if subject.Group != "" {
+			result.Project.Name = subject.Group + "/" + subject.Name
+		}
+	}
+	return result, nil
+}
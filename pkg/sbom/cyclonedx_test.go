@@ -0,0 +1,85 @@
+package sbom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+const cycloneDX = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "metadata": {"component": {"bom-ref": "app", "name": "app", "version": "1.0.0"}},
+  "components": [
+    {
+      "bom-ref": "pkg:npm/express@4.18.2",
+      "name": "express",
+      "version": "4.18.2",
+      "purl": "pkg:npm/express@4.18.2",
+      "licenses": [{"license": {"id": "MIT"}}]
+    },
+    {
+      "bom-ref": "core",
+      "group": "@babel",
+      "name": "core",
+      "version": "7.24.0",
+      "purl": "pkg:npm/%40babel/core@7.24.0",
+      "licenses": [{"expression": "MIT OR Apache-2.0"}],
+      "components": [{"bom-ref": "vendored", "name": "vendored-lib", "version": "0.1.0"}]
+    }
+  ],
+  "dependencies": [
+    {"ref": "app", "dependsOn": ["pkg:npm/express@4.18.2"]},
+    {"ref": "pkg:npm/express@4.18.2", "dependsOn": ["core"]},
+    {"ref": "core", "dependsOn": ["vendored"]}
+  ]
+}`
+
+func TestParseCycloneDX(t *testing.T) {
+	format, result, err := Parse([]byte(cycloneDX))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, CycloneDX, format)
+	assert.Equal(t, &scanners.Project{Name: "app", Version: "1.0.0"}, result.Project)
+	if !assert.Len(t, result.Dependencies, 3) {
+		return
+	}
+
+	express := result.Dependencies[0]
+	assert.Equal(t, "express", express.Name)
+	assert.Equal(t, "npm", express.Type)
+	assert.Equal(t, "MIT", express.License)
+	assert.True(t, express.IsDirectDep)
+	assert.Equal(t, 1, express.Depth)
+	assert.Equal(t, map[string]string{"sbom.format": CycloneDX, "purl": "pkg:npm/express@4.18.2"}, express.Properties)
+
+	core := result.Dependencies[1]
+	assert.Equal(t, "@babel/core", core.Name)
+	assert.Equal(t, "MIT OR Apache-2.0", core.License)
+	assert.Equal(t, "express", core.Parent)
+	assert.False(t, core.IsDirectDep)
+	assert.Equal(t, 2, core.Depth)
+
+	vendored := result.Dependencies[2]
+	assert.Equal(t, "vendored-lib", vendored.Name)
+	assert.Equal(t, "generic", vendored.Type)
+	assert.Equal(t, 3, vendored.Depth)
+
+	assert.Equal(t, []string{"express@4.18.2"}, result.Graph.Edges[""])
+	assert.Equal(t, []string{"@babel/core@7.24.0"}, result.Graph.Edges["express@4.18.2"])
+	assert.Same(t, &result.Dependencies[1], result.Graph.Nodes["@babel/core@7.24.0"])
+}
+
+func TestParseCycloneDXWithoutDependencies(t *testing.T) {
+	_, result, err := Parse([]byte(`{"bomFormat": "CycloneDX", "components": [{"name": "a", "version": "1"}, {"name": "b", "version": "2"}]}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Nil(t, result.Project)
+	for _, dep := range result.Dependencies {
+		assert.True(t, dep.IsDirectDep, dep.Name)
+	}
+}
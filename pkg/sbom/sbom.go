@@ -0,0 +1,259 @@
+package sbom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/santoshdahal12/deplister/pkg/purl"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Document formats
+const (
+	CycloneDX = "cyclonedx"
+	SPDX      = "spdx"
+)
+
+// Common errors
+var (
+	ErrInvalidDocument = errors.New("invalid SBOM document")
+)
+
+// Load reads a CycloneDX or SPDX JSON document and returns its format and
+// components as a scan result
+func Load(path string) (string, *scanners.ScanResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return Parse(data)
+}
+
+// Parse reads a CycloneDX or SPDX JSON document, telling them apart by their
+// top-level fields
+func Parse(data []byte) (string, *scanners.ScanResult, error) {
+	var probe struct {
+		BOMFormat   string `json:"bomFormat"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+
+	switch {
+	case probe.BOMFormat == "CycloneDX":
+		result, err := parseCycloneDX(data)
+		return CycloneDX, result, err
+	case probe.SPDXVersion != "":
+		result, err := parseSPDX(data)
+		return SPDX, result, err
+	}
+	return "", nil, fmt.Errorf("%w: neither CycloneDX nor SPDX", ErrInvalidDocument)
+}
+
+// component is a package read from a document, before its place in the
+// dependency graph is known
+type component struct {
+	id      string // Identifier of the component within the document
+	purl    string
+	name    string
+	version string
+	license string
+}
+
+// dependency converts a component, taking its type, name and version from
+// its package URL when it has one
+func (c component) dependency(format string) scanners.Dependency {
+	dep := scanners.Dependency{
+		Name:       c.name,
+		Version:    c.version,
+		Type:       "generic",
+		License:    c.license,
+		Properties: map[string]string{"sbom.format": format},
+	}
+	if c.purl != "" {
+		if depType, name, version, err := purl.Parse(c.purl); err == nil {
+			dep.Type, dep.Name = depType, name
+			if version != "" {
+				dep.Version = version
+			}
+		}
+		dep.Properties["purl"] = c.purl
+	}
+	return dep
+}
+
+// build turns components and the edges between their IDs into a scan
+// result. Edges from the empty ID start at the project root.
+func build(format string, components []component, edges map[string][]string) *scanners.ScanResult {
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{},
+		Graph: &scanners.DependencyGraph{
+			Nodes: make(map[string]*scanners.Dependency),
+			Edges: make(map[string][]string),
+		},
+	}
+
+	keys := make(map[string]string)
+	names := make(map[string]string)
+	for _, c := range components {
+		dep := c.dependency(format)
+		if dep.Name == "" {
+			continue
+		}
+		key := scanners.NodeKey(dep.Name, dep.Version)
+		keys[c.id] = key
+		names[key] = dep.Name
+		result.Dependencies = append(result.Dependencies, dep)
+	}
+
+	for from, targets := range edges {
+		parent, ok := keys[from]
+		if !ok && from != "" {
+			continue
+		}
+		for _, to := range targets {
+			if child, ok := keys[to]; ok && !slices.Contains(result.Graph.Edges[parent], child) {
+				result.Graph.Edges[parent] = append(result.Graph.Edges[parent], child)
+			}
+		}
+	}
+
+	depths := depths(result.Graph.Edges)
+	for i := range result.Dependencies {
+		dep := &result.Dependencies[i]
+		key := scanners.NodeKey(dep.Name, dep.Version)
+		for parent, children := range result.Graph.Edges {
+			if !slices.Contains(children, key) {
+				continue
+			}
+			if parent == "" {
+				dep.IsDirectDep = true
+			} else if !slices.Contains(dep.Parents, names[parent]) {
+				dep.Parents = append(dep.Parents, names[parent])
+			}
+		}
+		slices.Sort(dep.Parents)
+		if len(dep.Parents) > 0 {
+			dep.Parent = dep.Parents[0]
+		}
+		dep.Depth = -1
+		if depth, ok := depths[key]; ok {
+			dep.Depth = depth
+		}
+		if _, ok := result.Graph.Nodes[key]; !ok {
+			result.Graph.Nodes[key] = dep
+		}
+	}
+	return result
+}
+
+// depths returns the minimum depth of every package reachable from the root
+func depths(edges map[string][]string) map[string]int {
+	depths := map[string]int{"": 0}
+	queue := []string{""}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range edges[current] {
+			if _, ok := depths[child]; !ok {
+				depths[child] = depths[current] + 1
+				queue = append(queue, child)
+			}
+		}
+	}
+	return depths
+}
+
+// Merge combines scan results into one, keeping a single dependency per
+// package URL. Later results fill in licenses and properties missing from
+// earlier ones, and their graph edges and findings are added.
+func Merge(results ...*scanners.ScanResult) *scanners.ScanResult {
+	merged := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{},
+		Graph: &scanners.DependencyGraph{
+			Nodes: make(map[string]*scanners.Dependency),
+			Edges: make(map[string][]string),
+		},
+	}
+
+	index := make(map[string]int)
+	for _, result := range results {
+		for _, dep := range result.Dependencies {
+			key := purl.For(dep)
+			i, ok := index[key]
+			if !ok {
+				index[key] = len(merged.Dependencies)
+				dep.Parents = slices.Clone(dep.Parents)
+				dep.Paths = slices.Clone(dep.Paths)
+				dep.Properties = mergeProperties(nil, dep.Properties)
+				merged.Dependencies = append(merged.Dependencies, dep)
+				continue
+			}
+
+			existing := &merged.Dependencies[i]
+			existing.IsDirectDep = existing.IsDirectDep || dep.IsDirectDep
+			if existing.License == "" {
+				existing.License = dep.License
+			}
+			if existing.Parent == "" {
+				existing.Parent = dep.Parent
+			}
+			for _, parent := range dep.Parents {
+				if !slices.Contains(existing.Parents, parent) {
+					existing.Parents = append(existing.Parents, parent)
+				}
+			}
+			existing.Paths = append(existing.Paths, dep.Paths...)
+			if dep.Depth >= 0 && (existing.Depth < 0 || dep.Depth < existing.Depth) {
+				existing.Depth = dep.Depth
+			}
+			existing.Properties = mergeProperties(existing.Properties, dep.Properties)
+			for _, vuln := range dep.Vulnerabilities {
+				if !slices.ContainsFunc(existing.Vulnerabilities, func(v scanners.Vulnerability) bool { return v.ID == vuln.ID }) {
+					existing.Vulnerabilities = append(existing.Vulnerabilities, vuln)
+				}
+			}
+		}
+
+		if result.Graph != nil {
+			for parent, children := range result.Graph.Edges {
+				for _, child := range children {
+					if !slices.Contains(merged.Graph.Edges[parent], child) {
+						merged.Graph.Edges[parent] = append(merged.Graph.Edges[parent], child)
+					}
+				}
+			}
+		}
+		merged.Findings = append(merged.Findings, result.Findings...)
+		merged.Ignored = append(merged.Ignored, result.Ignored...)
+	}
+
+	for i := range merged.Dependencies {
+		dep := &merged.Dependencies[i]
+		key := scanners.NodeKey(dep.Name, dep.Version)
+		if _, ok := merged.Graph.Nodes[key]; !ok {
+			merged.Graph.Nodes[key] = dep
+		}
+	}
+	return merged
+}
+
+// mergeProperties copies the properties of from missing in into
+func mergeProperties(into, from map[string]string) map[string]string {
+	if len(from) == 0 {
+		return into
+	}
+	if into == nil {
+		into = make(map[string]string, len(from))
+	}
+	for key, value := range from {
+		if _, ok := into[key]; !ok {
+			into[key] = value
+		}
+	}
+	return into
+}
@@ -0,0 +1,54 @@
+package sbom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func TestParse(t *testing.T) {
+	_, _, err := Parse([]byte(`{"bomFormat": "other"}`))
+	assert.ErrorIs(t, err, ErrInvalidDocument)
+	_, _, err = Parse([]byte(`not json`))
+	assert.ErrorIs(t, err, ErrInvalidDocument)
+}
+
+func TestMerge(t *testing.T) {
+	scanned := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "express", Version: "4.18.2", Type: "npm", IsDirectDep: true, Depth: 1, Properties: map[string]string{"manager": "npm"}},
+			{Name: "accepts", Version: "1.3.8", Type: "npm", Parent: "express", Parents: []string{"express"}, Depth: 2},
+		},
+		Graph: &scanners.DependencyGraph{Edges: map[string][]string{"": {"express@4.18.2"}, "express@4.18.2": {"accepts@1.3.8"}}},
+		Findings: []scanners.Finding{
+			{Rule: "typosquat", Severity: scanners.SeverityWarning, Message: "warning"},
+		},
+	}
+	imported := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "express", Version: "4.18.2", Type: "npm", License: "MIT", Depth: -1, Properties: map[string]string{"manager": "sbom", "purl": "pkg:npm/express@4.18.2"}},
+			{Name: "debian/curl", Version: "7.88.1", Type: "deb", IsDirectDep: true, Depth: 1},
+		},
+		Graph: &scanners.DependencyGraph{Edges: map[string][]string{"": {"debian/curl@7.88.1"}}},
+	}
+
+	merged := Merge(scanned, imported)
+	if !assert.Len(t, merged.Dependencies, 3) {
+		return
+	}
+
+	express := merged.Dependencies[0]
+	assert.Equal(t, "MIT", express.License)
+	assert.True(t, express.IsDirectDep)
+	assert.Equal(t, 1, express.Depth)
+	assert.Equal(t, map[string]string{"manager": "npm", "purl": "pkg:npm/express@4.18.2"}, express.Properties)
+	// The inputs are left untouched
+	assert.Equal(t, map[string]string{"manager": "npm"}, scanned.Dependencies[0].Properties)
+
+	assert.Equal(t, "debian/curl", merged.Dependencies[2].Name)
+	assert.ElementsMatch(t, []string{"express@4.18.2", "debian/curl@7.88.1"}, merged.Graph.Edges[""])
+	assert.Same(t, &merged.Dependencies[1], merged.Graph.Nodes["accepts@1.3.8"])
+	assert.Len(t, merged.Findings, 1)
+}
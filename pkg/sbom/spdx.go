@@ -0,0 +1,107 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+type spdxDocument struct {
+	DocumentDescribes []string `json:"documentDescribes"`
+	Packages          []struct {
+		SPDXID           string `json:"SPDXID"`
+		Name             string `json:"name"`
+		VersionInfo      string `json:"versionInfo"`
+		LicenseConcluded string `json:"licenseConcluded"`
+		LicenseDeclared  string `json:"licenseDeclared"`
+		ExternalRefs     []struct {
+			ReferenceType    string `json:"referenceType"`
+			ReferenceLocator string `json:"referenceLocator"`
+		} `json:"externalRefs"`
+	} `json:"packages"`
+	Relationships []struct {
+		Element string `json:"spdxElementId"`
+		Type    string `json:"relationshipType"`
+		Related string `json:"relatedSpdxElement"`
+	} `json:"relationships"`
+}
+
+// spdxLicense returns a license expression unless it is NOASSERTION or NONE
+func spdxLicense(expression string) string {
+	if expression == "NOASSERTION" || expression == "NONE" {
+		return ""
+	}
+	return expression
+}
+
+// parseSPDX reads the packages of an SPDX 2 JSON document. The packages the
+// document describes are the project; the packages they depend on or, as in
+// container scans, contain are the direct dependencies.
+func parseSPDX(data []byte) (*scanners.ScanResult, error) {
+	var doc spdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+
+	roots := doc.DocumentDescribes
+	for _, rel := range doc.Relationships {
+		switch {
+		case rel.Type == "DESCRIBES" && rel.Element == "SPDXRef-DOCUMENT":
+			roots = append(roots, rel.Related)
+		case rel.Type == "DESCRIBED_BY" && rel.Related == "SPDXRef-DOCUMENT":
+			roots = append(roots, rel.Element)
+		}
+	}
+	node := func(id string) string {
+		if slices.Contains(roots, id) {
+			return ""
+		}
+		return id
+	}
+
+	var project *scanners.Project
+	var components []component
+	for _, pkg := range doc.Packages {
+		if slices.Contains(roots, pkg.SPDXID) {
+			if project == nil && pkg.Name != "" {
+				project = &scanners.Project{Name: pkg.Name, Version: pkg.VersionInfo}
+			}
+			continue
+		}
+		c := component{id: pkg.SPDXID, name: pkg.Name, version: pkg.VersionInfo, license: spdxLicense(pkg.LicenseConcluded)}
+		if c.license == "" {
+			c.license = spdxLicense(pkg.LicenseDeclared)
+		}
+		for _, ref := range pkg.ExternalRefs {
+			if ref.ReferenceType == "purl" {
+				c.purl = ref.ReferenceLocator
+				break
+			}
+		}
+		components = append(components, c)
+	}
+
+	edges := make(map[string][]string)
+	for _, rel := range doc.Relationships {
+		switch rel.Type {
+		case "DEPENDS_ON":
+			edges[node(rel.Element)] = append(edges[node(rel.Element)], rel.Related)
+		case "DEPENDENCY_OF":
+			edges[node(rel.Related)] = append(edges[node(rel.Related)], rel.Element)
+		case "CONTAINS":
+			if node(rel.Element) == "" {
+				edges[""] = append(edges[""], rel.Related)
+			}
+		}
+	}
+	if len(edges[""]) == 0 {
+		for _, c := range components {
+			edges[""] = append(edges[""], c.id)
+		}
+	}
+	result := build(SPDX, components, edges)
+	result.Project = project
+	return result, nil
+}
@@ -0,0 +1,66 @@
+package sbom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+const spdxDocumentJSON = `{
+  "spdxVersion": "SPDX-2.3",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "packages": [
+    {"SPDXID": "SPDXRef-image", "name": "registry.example.com/app", "versionInfo": "sha256:abc"},
+    {
+      "SPDXID": "SPDXRef-curl",
+      "name": "curl",
+      "versionInfo": "7.88.1-10",
+      "licenseConcluded": "NOASSERTION",
+      "licenseDeclared": "curl",
+      "externalRefs": [
+        {"referenceCategory": "SECURITY", "referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:curl:curl:7.88.1:*:*:*:*:*:*:*"},
+        {"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:deb/debian/curl@7.88.1-10?arch=amd64"}
+      ]
+    },
+    {
+      "SPDXID": "SPDXRef-libcurl",
+      "name": "libcurl4",
+      "versionInfo": "7.88.1-10",
+      "licenseConcluded": "curl",
+      "externalRefs": [{"referenceType": "purl", "referenceLocator": "pkg:deb/debian/libcurl4@7.88.1-10"}]
+    }
+  ],
+  "relationships": [
+    {"spdxElementId": "SPDXRef-DOCUMENT", "relationshipType": "DESCRIBES", "relatedSpdxElement": "SPDXRef-image"},
+    {"spdxElementId": "SPDXRef-image", "relationshipType": "CONTAINS", "relatedSpdxElement": "SPDXRef-curl"},
+    {"spdxElementId": "SPDXRef-libcurl", "relationshipType": "DEPENDENCY_OF", "relatedSpdxElement": "SPDXRef-curl"}
+  ]
+}`
+
+func TestParseSPDX(t *testing.T) {
+	format, result, err := Parse([]byte(spdxDocumentJSON))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, SPDX, format)
+	assert.Equal(t, &scanners.Project{Name: "registry.example.com/app", Version: "sha256:abc"}, result.Project)
+	if !assert.Len(t, result.Dependencies, 2) {
+		return
+	}
+
+	curl := result.Dependencies[0]
+	assert.Equal(t, "debian/curl", curl.Name)
+	assert.Equal(t, "7.88.1-10", curl.Version)
+	assert.Equal(t, "deb", curl.Type)
+	assert.Equal(t, "curl", curl.License)
+	assert.True(t, curl.IsDirectDep)
+	assert.Equal(t, "pkg:deb/debian/curl@7.88.1-10?arch=amd64", curl.Properties["purl"])
+
+	libcurl := result.Dependencies[1]
+	assert.Equal(t, "debian/libcurl4", libcurl.Name)
+	assert.Equal(t, "debian/curl", libcurl.Parent)
+	assert.False(t, libcurl.IsDirectDep)
+	assert.Equal(t, 2, libcurl.Depth)
+}
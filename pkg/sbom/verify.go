@@ -0,0 +1,50 @@
+package sbom
+
+import (
+	"github.com/santoshdahal12/deplister/pkg/history"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Package is a package version in a Drift
+type Package struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Type    string `json:"type,omitempty"`
+}
+
+// Drift lists how a scan differs from an approved baseline SBOM
+type Drift struct {
+	Added   []Package               `json:"added,omitempty"`
+	Changed []history.VersionChange `json:"changed,omitempty"`
+	Removed []Package               `json:"removed,omitempty"`
+}
+
+// Unexpected reports whether the scan adds packages or changes versions.
+// Removals only count when failOnRemoved is set.
+func (d *Drift) Unexpected(failOnRemoved bool) bool {
+	return len(d.Added) > 0 || len(d.Changed) > 0 || (failOnRemoved && len(d.Removed) > 0)
+}
+
+// Verify compares the dependencies of a scan with those of a baseline with
+// history.Diff, which matches them by name since SBOM components without a
+// package URL have no ecosystem
+func Verify(baseline, current *scanners.ScanResult) *Drift {
+	added, removed, changed := history.Diff(dependencies(baseline), dependencies(current))
+	return &Drift{Added: packages(added), Changed: changed, Removed: packages(removed)}
+}
+
+func dependencies(result *scanners.ScanResult) []history.Dependency {
+	deps := make([]history.Dependency, 0, len(result.Dependencies))
+	for _, dep := range result.Dependencies {
+		deps = append(deps, history.Dependency{Name: dep.Name, Version: dep.Version, Type: dep.Type})
+	}
+	return deps
+}
+
+func packages(deps []history.Dependency) []Package {
+	var result []Package
+	for _, dep := range deps {
+		result = append(result, Package{Name: dep.Name, Version: dep.Version, Type: dep.Type})
+	}
+	return result
+}
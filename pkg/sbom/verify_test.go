@@ -0,0 +1,53 @@
+package sbom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/history"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func TestVerify(t *testing.T) {
+	baseline := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "express", Version: "4.18.2", Type: "npm"},
+			{Name: "accepts", Version: "1.3.8", Type: "npm"},
+			{Name: "debug", Version: "2.6.9", Type: "npm"},
+			{Name: "debug", Version: "4.3.4", Type: "npm"},
+			{Name: "left-pad", Version: "1.3.0", Type: "generic"},
+		},
+	}
+	current := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "express", Version: "4.18.2", Type: "npm"},
+			{Name: "accepts", Version: "1.3.9", Type: "npm"},
+			{Name: "debug", Version: "2.6.9", Type: "npm"},
+			{Name: "debug", Version: "2.6.9", Type: "npm"},
+			{Name: "debug", Version: "4.3.5", Type: "npm"},
+			{Name: "ms", Version: "2.1.3", Type: "npm"},
+		},
+	}
+
+	drift := Verify(baseline, current)
+	assert.Equal(t, []Package{
+		{Name: "debug", Version: "4.3.5", Type: "npm"},
+		{Name: "ms", Version: "2.1.3", Type: "npm"},
+	}, drift.Added)
+	assert.Equal(t, []history.VersionChange{{Name: "accepts", From: "1.3.8", To: "1.3.9"}}, drift.Changed)
+	assert.Equal(t, []Package{
+		{Name: "debug", Version: "4.3.4", Type: "npm"},
+		{Name: "left-pad", Version: "1.3.0", Type: "generic"},
+	}, drift.Removed)
+	assert.True(t, drift.Unexpected(false))
+
+	removedOnly := Verify(baseline, &scanners.ScanResult{Dependencies: baseline.Dependencies[:2]})
+	assert.Empty(t, removedOnly.Added)
+	assert.Empty(t, removedOnly.Changed)
+	assert.Len(t, removedOnly.Removed, 3)
+	assert.False(t, removedOnly.Unexpected(false))
+	assert.True(t, removedOnly.Unexpected(true))
+
+	assert.False(t, Verify(baseline, baseline).Unexpected(true))
+}
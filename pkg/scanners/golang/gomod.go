@@ -0,0 +1,148 @@
+package golang
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// GoMod holds the directives of a go.mod file
+type GoMod struct {
+	Module    string
+	Go        string                // Language version of the go directive
+	Toolchain string                // Name of the toolchain directive, e.g. go1.22.3
+	Requires  []ModuleInfo          // Requirements, with Indirect set from "// indirect" comments
+	Replaces  map[string]ModuleInfo // Replacements keyed by the replaced module path
+	Excludes  []module.Version      // Module versions excluded from the build list
+	Retracts  []Retraction          // Versions of the module itself retracted by its authors
+}
+
+// Retraction is a range of versions retracted by a retract directive. Low
+// and High are equal for a single version.
+type Retraction struct {
+	Low       string
+	High      string
+	Rationale string
+}
+
+// ReadGoMod reads and parses go.mod from the root of fsys
+func ReadGoMod(fsys fs.FS) (*GoMod, error) {
+	content, err := fs.ReadFile(fsys, "go.mod")
+	if err != nil {
+		return nil, err
+	}
+	return ParseGoMod(content)
+}
+
+// ParseGoMod parses the contents of a go.mod file. Directives newer than the
+// parser are skipped, along with the replace, exclude and toolchain
+// directives of such a file, rather than failing the scan.
+func ParseGoMod(content []byte) (*GoMod, error) {
+	file, err := modfile.Parse("go.mod", content, nil)
+	if err != nil {
+		var lax error
+		if file, lax = modfile.ParseLax("go.mod", content, nil); lax != nil {
+			return nil, fmt.Errorf("%w: %w", scanners.ErrInvalidProject, err)
+		}
+	}
+
+	goMod := &GoMod{Replaces: make(map[string]ModuleInfo)}
+	if file.Module != nil {
+		goMod.Module = file.Module.Mod.Path
+	}
+	if file.Go != nil {
+		goMod.Go = file.Go.Version
+	}
+	if file.Toolchain != nil {
+		goMod.Toolchain = file.Toolchain.Name
+	}
+	for _, req := range file.Require {
+		goMod.Requires = append(goMod.Requires, ModuleInfo{
+			Path:     req.Mod.Path,
+			Version:  req.Mod.Version,
+			Indirect: req.Indirect,
+		})
+	}
+	for _, replace := range file.Replace {
+		goMod.Replaces[replace.Old.Path] = ModuleInfo{Path: replace.New.Path, Version: replace.New.Version}
+	}
+	for _, exclude := range file.Exclude {
+		goMod.Excludes = append(goMod.Excludes, exclude.Mod)
+	}
+	for _, retract := range file.Retract {
+		goMod.Retracts = append(goMod.Retracts, Retraction{Low: retract.Low, High: retract.High, Rationale: retract.Rationale})
+	}
+	return goMod, nil
+}
+
+// stdlib returns the Go standard library as a dependency named "stdlib", as
+// the Go vulnerability database and package URLs name it. Its version is
+// that of the go tool that scanned the project or, without one, the
+// toolchain or go directive of go.mod, which is the minimum that builds it.
+// The directives are recorded as properties.
+func stdlib(goMod *GoMod, goVersion string) scanners.Dependency {
+	props := map[string]string{
+		"manager":        "go",
+		"dependencyType": "stdlib",
+	}
+	if goMod.Go != "" {
+		props["goDirective"] = goMod.Go
+	}
+	if goMod.Toolchain != "" {
+		props["toolchainDirective"] = goMod.Toolchain
+	}
+
+	effective := goVersion
+	switch {
+	case effective != "":
+	case goMod.Toolchain != "" && goMod.Toolchain != "default":
+		effective = goMod.Toolchain
+	case goMod.Go != "":
+		effective = "go" + goMod.Go
+	}
+	if effective != "" {
+		props["goVersion"] = effective
+	}
+
+	return scanners.Dependency{
+		Name:        "stdlib",
+		Version:     semver(effective),
+		Type:        "go",
+		License:     "BSD-3-Clause",
+		IsDirectDep: true,
+		Properties:  props,
+		Depth:       1,
+	}
+}
+
+// semver converts a Go release name such as go1.22.3 or go1.21rc2 to the
+// semantic version the Go vulnerability database uses, v1.22.3 or
+// v1.21.0-rc.2. Anything after the version, as in development builds, is
+// dropped.
+func semver(release string) string {
+	release, _, _ = strings.Cut(release, " ")
+	version, ok := strings.CutPrefix(release, "go")
+	if !ok || version == "" {
+		return ""
+	}
+
+	prerelease := ""
+	for _, tag := range []string{"rc", "beta"} {
+		if i := strings.Index(version, tag); i > 0 {
+			version, prerelease = version[:i], "-"+tag+"."+version[i+len(tag):]
+			break
+		}
+	}
+	if strings.Count(version, ".") == 1 {
+		version += ".0"
+	}
+	if strings.Count(version, ".") == 0 {
+		version += ".0.0"
+	}
+	return "v" + version + prerelease
+}
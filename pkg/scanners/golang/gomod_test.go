@@ -0,0 +1,114 @@
+package golang
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/mod/module"
+)
+
+func TestParseGoMod(t *testing.T) {
+	goMod, err := ParseGoMod([]byte(`// Service
+module "example.com/service"
+
+go 1.22.0
+
+toolchain go1.22.3
+
+require (
+	github.com/spf13/cobra v1.8.0
+	"golang.org/x/mod" v0.17.0 // indirect
+)
+
+require github.com/google/uuid v1.6.0 // pinned
+
+replace (
+	github.com/spf13/cobra v1.8.0 => ../cobra
+	golang.org/x/mod => golang.org/x/mod v0.18.0
+)
+
+exclude (
+	github.com/spf13/cobra v1.7.0
+)
+
+retract (
+	v1.0.1 // Published by mistake
+	[v1.1.0, v1.1.5]
+)
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "example.com/service", goMod.Module)
+	assert.Equal(t, "1.22.0", goMod.Go)
+	assert.Equal(t, "go1.22.3", goMod.Toolchain)
+	assert.Equal(t, []ModuleInfo{
+		{Path: "github.com/spf13/cobra", Version: "v1.8.0"},
+		{Path: "golang.org/x/mod", Version: "v0.17.0", Indirect: true},
+		{Path: "github.com/google/uuid", Version: "v1.6.0"},
+	}, goMod.Requires)
+	assert.Equal(t, map[string]ModuleInfo{
+		"github.com/spf13/cobra": {Path: "../cobra"},
+		"golang.org/x/mod":       {Path: "golang.org/x/mod", Version: "v0.18.0"},
+	}, goMod.Replaces)
+	assert.Equal(t, []module.Version{{Path: "github.com/spf13/cobra", Version: "v1.7.0"}}, goMod.Excludes)
+	assert.Equal(t, []Retraction{
+		{Low: "v1.0.1", High: "v1.0.1", Rationale: "Published by mistake"},
+		{Low: "v1.1.0", High: "v1.1.5"},
+	}, goMod.Retracts)
+}
+
+func TestParseGoMod_UnknownDirectives(t *testing.T) {
+	goMod, err := ParseGoMod([]byte("module example.com/app\n\ngo 1.30\n\nfuture ./x\n\nrequire github.com/google/uuid v1.6.0\n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "example.com/app", goMod.Module)
+	assert.Len(t, goMod.Requires, 1)
+
+	_, err = ParseGoMod([]byte("module example.com/app\nrequire (\n"))
+	assert.Error(t, err)
+}
+
+func TestSemver(t *testing.T) {
+	for release, want := range map[string]string{
+		"go1.22.3":                      "v1.22.3",
+		"go1.21":                        "v1.21.0",
+		"go1.21rc2":                     "v1.21.0-rc.2",
+		"go1.20beta1":                   "v1.20.0-beta.1",
+		"go1.23.0 X:nocoverageredesign": "v1.23.0",
+		"go2":                           "v2.0.0",
+		"devel":                         "",
+		"":                              "",
+	} {
+		assert.Equal(t, want, semver(release), release)
+	}
+}
+
+func TestStdlib(t *testing.T) {
+	goMod, err := ParseGoMod([]byte("module example.com/app\n\ngo 1.21\n\ntoolchain go1.22.3\n"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	dep := stdlib(goMod, "")
+	assert.Equal(t, "stdlib", dep.Name)
+	assert.Equal(t, "v1.22.3", dep.Version)
+	assert.True(t, dep.IsDirectDep)
+	assert.Equal(t, map[string]string{
+		"manager":            "go",
+		"dependencyType":     "stdlib",
+		"goDirective":        "1.21",
+		"toolchainDirective": "go1.22.3",
+		"goVersion":          "go1.22.3",
+	}, dep.Properties)
+
+	// The go tool that scanned the project wins over go.mod
+	dep = stdlib(goMod, "go1.23.1")
+	assert.Equal(t, "v1.23.1", dep.Version)
+	assert.Equal(t, "go1.23.1", dep.Properties["goVersion"])
+
+	goMod.Toolchain = ""
+	assert.Equal(t, "v1.21.0", stdlib(goMod, "").Version)
+}
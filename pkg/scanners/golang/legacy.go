@@ -0,0 +1,344 @@
+package golang
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/santoshdahal12/deplister/pkg/license"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// legacyLockfiles are the lockfiles of the dependency managers predating
+// modules, by manager, in the order they are looked for
+var legacyLockfiles = []struct{ manager, file string }{
+	{"dep", "Gopkg.lock"},
+	{"glide", "glide.lock"},
+	{"govendor", "vendor/vendor.json"},
+}
+
+// legacyPackage is a repository pinned by the lockfile of dep, glide or
+// govendor
+type legacyPackage struct {
+	Name     string // Import path of the repository root
+	Version  string // Tag, "" when only a revision is pinned
+	Revision string // Commit
+	Source   string // Repository or import path fetched instead of Name
+	Direct   bool   // Imported by the project itself
+	Test     bool   // Only imported by the tests of the project
+}
+
+// legacyManager returns the dependency manager predating modules whose
+// lockfile fsys holds, and the lockfile, or "" for neither
+func legacyManager(fsys fs.FS) (string, string) {
+	for _, lockfile := range legacyLockfiles {
+		if _, err := fs.Stat(fsys, lockfile.file); err == nil {
+			return lockfile.manager, lockfile.file
+		}
+	}
+	return "", ""
+}
+
+// readLegacy reads the root import path of the project and the repositories
+// its lockfile pins. The root is "" when the lockfile does not record it.
+func readLegacy(fsys fs.FS) (string, string, []legacyPackage, error) {
+	manager, file := legacyManager(fsys)
+	if manager == "" {
+		return "", "", nil, scanners.ErrProjectNotFound
+	}
+	content, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	var root string
+	var packages []legacyPackage
+	switch manager {
+	case "dep":
+		packages, err = parseGopkgLock(content)
+	case "glide":
+		manifest, readErr := fs.ReadFile(fsys, "glide.yaml")
+		if readErr != nil && !errors.Is(readErr, fs.ErrNotExist) {
+			return "", "", nil, readErr
+		}
+		root, packages, err = parseGlideLock(content, manifest)
+	case "govendor":
+		root, packages, err = parseVendorJSON(content)
+	}
+	if err != nil {
+		return "", "", nil, fmt.Errorf("%w: %s: %w", scanners.ErrInvalidProject, file, err)
+	}
+	return manager, root, packages, nil
+}
+
+// gopkgString matches a TOML basic string
+var gopkgString = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+
+// parseGopkgLock reads the projects of a dep lockfile. The lockfile is TOML,
+// but dep only writes tables of string and string array keys, which is all
+// this understands. Projects imported by the project itself are listed in
+// the input-imports of dep 0.5; with older lockfiles every project counts as
+// direct.
+func parseGopkgLock(content []byte) ([]legacyPackage, error) {
+	var projects []map[string][]string
+	var meta map[string][]string
+	var table map[string][]string
+	var key, value string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if key != "" {
+			// Continuation of a multi-line array
+			value += line
+		} else {
+			switch {
+			case line == "" || strings.HasPrefix(line, "#"):
+				continue
+			case line == "[[projects]]":
+				table = make(map[string][]string)
+				projects = append(projects, table)
+				continue
+			case line == "[solve-meta]":
+				meta = make(map[string][]string)
+				table = meta
+				continue
+			case strings.HasPrefix(line, "["):
+				table = nil
+				continue
+			}
+			name, rest, ok := strings.Cut(line, "=")
+			if !ok {
+				return nil, errors.New("invalid Gopkg.lock line: " + line)
+			}
+			key, value = strings.TrimSpace(name), strings.TrimSpace(rest)
+		}
+		if strings.HasPrefix(value, "[") && !strings.HasSuffix(value, "]") {
+			continue
+		}
+
+		var values []string
+		for _, quoted := range gopkgString.FindAllString(value, -1) {
+			s, err := strconv.Unquote(quoted)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, s)
+		}
+		if table != nil {
+			table[key] = values
+		}
+		key, value = "", ""
+	}
+
+	first := func(table map[string][]string, key string) string {
+		if values := table[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	imports, recorded := meta["input-imports"]
+	var packages []legacyPackage
+	for _, project := range projects {
+		pkg := legacyPackage{
+			Name:     first(project, "name"),
+			Version:  first(project, "version"),
+			Revision: first(project, "revision"),
+			Source:   first(project, "source"),
+			Direct:   !recorded,
+		}
+		if pkg.Name == "" {
+			return nil, errors.New("Gopkg.lock project without name")
+		}
+		for _, imported := range imports {
+			if imported == pkg.Name || strings.HasPrefix(imported, pkg.Name+"/") {
+				pkg.Direct = true
+			}
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// glideLock is the part of glide.lock read by parseGlideLock
+type glideLock struct {
+	Imports     []glideImport `yaml:"imports"`
+	TestImports []glideImport `yaml:"testImports"`
+}
+
+type glideImport struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+	Repo    string `yaml:"repo"`
+}
+
+// glideManifest is the part of glide.yaml read by parseGlideLock
+type glideManifest struct {
+	Package string `yaml:"package"`
+	Import  []struct {
+		Package string `yaml:"package"`
+	} `yaml:"import"`
+	TestImport []struct {
+		Package string `yaml:"package"`
+	} `yaml:"testImport"`
+}
+
+// parseGlideLock reads the imports of a glide lockfile, which pins commits.
+// The project's own imports and its root come from glide.yaml; without it
+// every import counts as direct.
+func parseGlideLock(content, manifestContent []byte) (string, []legacyPackage, error) {
+	var lock glideLock
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return "", nil, err
+	}
+	var manifest glideManifest
+	if err := yaml.Unmarshal(manifestContent, &manifest); err != nil {
+		return "", nil, err
+	}
+	direct := make(map[string]bool)
+	for _, imported := range manifest.Import {
+		direct[imported.Package] = true
+	}
+	for _, imported := range manifest.TestImport {
+		direct[imported.Package] = true
+	}
+
+	var packages []legacyPackage
+	for i, imports := range [][]glideImport{lock.Imports, lock.TestImports} {
+		for _, imported := range imports {
+			if imported.Name == "" {
+				return "", nil, errors.New("glide.lock import without name")
+			}
+			packages = append(packages, legacyPackage{
+				Name:     imported.Name,
+				Revision: imported.Version,
+				Source:   imported.Repo,
+				Direct:   manifestContent == nil || direct[imported.Name],
+				Test:     i == 1,
+			})
+		}
+	}
+	return manifest.Package, packages, nil
+}
+
+// vendorFile is vendor/vendor.json as written by govendor
+type vendorFile struct {
+	RootPath string `json:"rootPath"`
+	Package  []struct {
+		Path         string `json:"path"`
+		Revision     string `json:"revision"`
+		Version      string `json:"version"`
+		VersionExact string `json:"versionExact"`
+		Origin       string `json:"origin"`
+	} `json:"package"`
+}
+
+// parseVendorJSON reads the packages vendored by govendor. govendor pins
+// packages rather than repositories, so packages below another one at the
+// same revision are folded into it. It does not tell direct imports apart,
+// so every package counts as direct.
+func parseVendorJSON(content []byte) (string, []legacyPackage, error) {
+	var file vendorFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return "", nil, err
+	}
+	sort.Slice(file.Package, func(i, j int) bool { return file.Package[i].Path < file.Package[j].Path })
+
+	var packages []legacyPackage
+	for _, vendored := range file.Package {
+		if vendored.Path == "" {
+			return "", nil, errors.New("vendor.json package without path")
+		}
+		if n := len(packages); n > 0 && strings.HasPrefix(vendored.Path, packages[n-1].Name+"/") && packages[n-1].Revision == vendored.Revision {
+			continue
+		}
+		pkg := legacyPackage{
+			Name:     vendored.Path,
+			Version:  vendored.VersionExact,
+			Revision: vendored.Revision,
+			Source:   vendored.Origin,
+			Direct:   true,
+		}
+		if pkg.Version == "" {
+			pkg.Version = vendored.Version
+		}
+		packages = append(packages, pkg)
+	}
+	return file.RootPath, packages, nil
+}
+
+// resolveLegacy converts the repositories pinned by the lockfile of dep,
+// glide or govendor into dependencies, passing each to emit with its node
+// key, and returns the graph without nodes. Lockfiles are flat, so like
+// requirements read from go.mod alone every dependency is attached to the
+// project. Licenses are read from the vendor directory.
+func (s *GoScanner) resolveLegacy(fsys fs.FS, opts scanners.ScanOptions, emit func(string, scanners.Dependency) error) (*scanners.DependencyGraph, error) {
+	manager, root, packages, err := readLegacy(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	rootKey := scanners.NodeKey(root, "")
+	resolved := &scanners.DependencyGraph{Edges: make(map[string][]string), Root: rootKey}
+	emitted := 0
+	for _, pkg := range packages {
+		// Test dependencies are left out like npm dev dependencies
+		if pkg.Test && !opts.IncludeDev {
+			continue
+		}
+
+		props := map[string]string{
+			"manager":        manager,
+			"dependencyType": "indirect",
+		}
+		switch {
+		case pkg.Test:
+			props["dependencyType"] = "test"
+		case pkg.Direct:
+			props["dependencyType"] = "direct"
+		}
+		if pkg.Revision != "" {
+			props["origin.hash"] = pkg.Revision
+		}
+		if pkg.Source != "" {
+			props["source"] = pkg.Source
+		}
+
+		version := pkg.Version
+		if version == "" {
+			version = pkg.Revision
+		}
+		key := scanners.NodeKey(pkg.Name, version)
+		var depLicense string
+		if vendored, err := fs.Sub(fsys, path.Join("vendor", pkg.Name)); err == nil {
+			depLicense = license.FromFS(vendored)
+		}
+
+		resolved.AddEdge(rootKey, key)
+		dependency := scanners.Dependency{
+			Name:        pkg.Name,
+			Version:     version,
+			Type:        "go",
+			License:     depLicense,
+			IsDirectDep: pkg.Direct && !pkg.Test,
+			Paths:       []scanners.DependencyPath{{Path: []string{rootKey, key}, Depth: 1}},
+			Properties:  props,
+			Depth:       1,
+		}
+		if err := emit(key, dependency); err != nil {
+			return nil, err
+		}
+		emitted++
+	}
+
+	if emitted == 0 {
+		return nil, fmt.Errorf("%w: %s lockfile pins no packages", scanners.ErrInvalidProject, manager)
+	}
+	return resolved, nil
+}
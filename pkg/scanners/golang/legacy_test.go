@@ -0,0 +1,177 @@
+package golang
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func TestParseGopkgLock(t *testing.T) {
+	content := `# This file is autogenerated, do not edit; changes may be undone by the next 'dep ensure'.
+
+
+[[projects]]
+  digest = "1:cf31692c14422fa27c83a05292eb5cbe0fb2775972e8f1f8446a71549bd8980b"
+  name = "github.com/pkg/errors"
+  packages = ["."]
+  pruneopts = "UT"
+  revision = "ba968bfe8b2f7e042a574c888954fccecfa385b4"
+  version = "v0.8.1"
+
+[[projects]]
+  branch = "master"
+  digest = "1:2a1bd4e4d6a7e47b2c4a0c3b13b87c3b0e5f5e0f26b6c0c4df4f3a6d8d9d1b3e"
+  name = "golang.org/x/sys"
+  packages = [
+    "unix",
+    "windows",
+  ]
+  pruneopts = "UT"
+  revision = "d0b11bdaac8adb652bff00e49bcacf992835621a"
+  source = "https://github.com/golang/sys"
+
+[solve-meta]
+  analyzer-name = "dep"
+  analyzer-version = 1
+  input-imports = [
+    "github.com/pkg/errors",
+  ]
+  solver-name = "gps-cdcl"
+  solver-version = 1
+`
+	packages, err := parseGopkgLock([]byte(content))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []legacyPackage{
+		{Name: "github.com/pkg/errors", Version: "v0.8.1", Revision: "ba968bfe8b2f7e042a574c888954fccecfa385b4", Direct: true},
+		{Name: "golang.org/x/sys", Revision: "d0b11bdaac8adb652bff00e49bcacf992835621a", Source: "https://github.com/golang/sys"},
+	}, packages)
+
+	// Lockfiles of dep before 0.5 do not record the project's imports
+	packages, err = parseGopkgLock([]byte("[[projects]]\n  name = \"github.com/pkg/errors\"\n  revision = \"ba968bf\"\n"))
+	if assert.NoError(t, err) && assert.Len(t, packages, 1) {
+		assert.True(t, packages[0].Direct)
+	}
+
+	_, err = parseGopkgLock([]byte("[[projects]]\n  not toml\n"))
+	assert.Error(t, err)
+}
+
+func TestParseGlideLock(t *testing.T) {
+	lock := `hash: 0d3c5d5bc0ac2a2b5b0c1e0d9b2f1c3a
+updated: 2017-06-12T10:21:31.000000000Z
+imports:
+- name: github.com/sirupsen/logrus
+  version: 202f25545ea4cf9b191ff7f846df5d87c9382c2b
+- name: golang.org/x/sys
+  version: 9a7256cb28ed514b4e1e5f68959914c4c28a92e0
+  repo: https://github.com/golang/sys
+  subpackages:
+  - unix
+testImports:
+- name: github.com/stretchr/testify
+  version: 69483b4bd14f5845b5a1e55bca19e954e827f1d0
+  subpackages:
+  - assert
+`
+	manifest := `package: github.com/acme/legacy
+import:
+- package: github.com/sirupsen/logrus
+  version: ^1.0.0
+testImport:
+- package: github.com/stretchr/testify
+`
+	root, packages, err := parseGlideLock([]byte(lock), []byte(manifest))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "github.com/acme/legacy", root)
+	assert.Equal(t, []legacyPackage{
+		{Name: "github.com/sirupsen/logrus", Revision: "202f25545ea4cf9b191ff7f846df5d87c9382c2b", Direct: true},
+		{Name: "golang.org/x/sys", Revision: "9a7256cb28ed514b4e1e5f68959914c4c28a92e0", Source: "https://github.com/golang/sys"},
+		{Name: "github.com/stretchr/testify", Revision: "69483b4bd14f5845b5a1e55bca19e954e827f1d0", Direct: true, Test: true},
+	}, packages)
+}
+
+func TestParseVendorJSON(t *testing.T) {
+	content := `{
+	"comment": "",
+	"ignore": "test",
+	"package": [
+		{"checksumSHA1": "a=", "path": "github.com/pkg/errors", "revision": "645ef00459ed84a119197bfb8d8205042c6df63d", "revisionTime": "2016-09-29T01:48:01Z", "version": "v0.8", "versionExact": "v0.8.0"},
+		{"checksumSHA1": "b=", "path": "golang.org/x/net/context", "revision": "a6577fac2d73be281a500b310739095313165611", "revisionTime": "2017-03-08T20:54:49Z"},
+		{"checksumSHA1": "c=", "path": "golang.org/x/net/context/ctxhttp", "revision": "a6577fac2d73be281a500b310739095313165611", "revisionTime": "2017-03-08T20:54:49Z"},
+		{"checksumSHA1": "d=", "path": "github.com/acme/fork", "origin": "github.com/acme/app/vendor/github.com/acme/fork", "revision": "0123456789abcdef0123456789abcdef01234567"}
+	],
+	"rootPath": "github.com/acme/app"
+}`
+	root, packages, err := parseVendorJSON([]byte(content))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "github.com/acme/app", root)
+	assert.Equal(t, []legacyPackage{
+		{Name: "github.com/acme/fork", Revision: "0123456789abcdef0123456789abcdef01234567", Source: "github.com/acme/app/vendor/github.com/acme/fork", Direct: true},
+		{Name: "github.com/pkg/errors", Version: "v0.8.0", Revision: "645ef00459ed84a119197bfb8d8205042c6df63d", Direct: true},
+		{Name: "golang.org/x/net/context", Revision: "a6577fac2d73be281a500b310739095313165611", Direct: true},
+	}, packages)
+}
+
+func TestGoScanner_Legacy(t *testing.T) {
+	fsys := fstest.MapFS{
+		"glide.lock": {Data: []byte(`imports:
+- name: github.com/sirupsen/logrus
+  version: 202f25545ea4cf9b191ff7f846df5d87c9382c2b
+testImports:
+- name: github.com/stretchr/testify
+  version: 69483b4bd14f5845b5a1e55bca19e954e827f1d0
+`)},
+		"glide.yaml": {Data: []byte("package: github.com/acme/legacy\nimport:\n- package: github.com/sirupsen/logrus\n")},
+		"vendor/github.com/sirupsen/logrus/LICENSE": {Data: []byte("Permission is hereby granted, free of charge, to any person obtaining a copy")},
+	}
+
+	scanner := NewScanner()
+	assert.True(t, scanner.DetectProjectFS(context.Background(), fsys))
+	assert.False(t, scanner.DetectProjectFS(context.Background(), fstest.MapFS{"vendor/modules.txt": {}}))
+
+	result, err := scanner.ScanDependenciesFS(context.Background(), fsys, scanners.DefaultScanOptions())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, &scanners.Project{Name: "github.com/acme/legacy"}, result.Project)
+	assert.Equal(t, "github.com/acme/legacy", result.Graph.Root)
+	if !assert.Len(t, result.Dependencies, 2) {
+		return
+	}
+	logrus := result.Dependencies[0]
+	assert.Equal(t, "github.com/sirupsen/logrus", logrus.Name)
+	assert.Equal(t, "202f25545ea4cf9b191ff7f846df5d87c9382c2b", logrus.Version)
+	assert.Equal(t, "MIT", logrus.License)
+	assert.True(t, logrus.IsDirectDep)
+	assert.Equal(t, map[string]string{
+		"manager":        "glide",
+		"dependencyType": "direct",
+		"origin.hash":    "202f25545ea4cf9b191ff7f846df5d87c9382c2b",
+	}, logrus.Properties)
+	assert.Equal(t, "test", result.Dependencies[1].Properties["dependencyType"])
+	assert.Equal(t, []string{"github.com/acme/legacy"}, result.Graph.Parents(scanners.NodeKey(logrus.Name, logrus.Version)))
+
+	opts := scanners.DefaultScanOptions()
+	opts.IncludeDev = false
+	result, err = scanner.ScanDependenciesFS(context.Background(), fsys, opts)
+	if assert.NoError(t, err) {
+		assert.Len(t, result.Dependencies, 1)
+	}
+}
+
+func TestGoScanner_LegacyInvalid(t *testing.T) {
+	fsys := fstest.MapFS{"glide.lock": {Data: []byte("imports: [\n")}}
+	_, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, scanners.DefaultScanOptions())
+	assert.ErrorIs(t, err, scanners.ErrInvalidProject)
+	assert.ErrorContains(t, err, "glide.lock")
+}
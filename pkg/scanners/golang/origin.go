@@ -0,0 +1,77 @@
+package golang
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/mod/module"
+)
+
+// Origin is where the go tool fetched a module version from, as recorded in
+// go list output and the .info files of the module download cache
+type Origin struct {
+	VCS    string `json:"VCS,omitempty"`
+	URL    string `json:"URL,omitempty"`
+	Subdir string `json:"Subdir,omitempty"`
+	Hash   string `json:"Hash,omitempty"`
+	Ref    string `json:"Ref,omitempty"`
+}
+
+// moduleOrigin returns the origin of a module, or of its replacement, from
+// go list or the module download cache. Versions downloaded through a proxy
+// that does not report origins, and local replacements, have none.
+func moduleOrigin(info *ModuleInfo) *Origin {
+	mod := info
+	if info.Replace != nil {
+		mod = info.Replace
+	}
+	if mod.Origin != nil {
+		return mod.Origin
+	}
+	cache := moduleCache()
+	if cache == "" || mod.Version == "" {
+		return nil
+	}
+
+	file := filepath.Join(cache, "cache", "download", filepath.FromSlash(escapeModulePath(mod.Path)), "@v", escapeModulePath(mod.Version)+".info")
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	var versionInfo struct {
+		Origin *Origin
+	}
+	if err := json.Unmarshal(content, &versionInfo); err != nil {
+		return nil
+	}
+	return versionInfo.Origin
+}
+
+// originProperties records the origin of a module as the "origin.vcs",
+// "origin.url", "origin.subdir", "origin.hash" and "origin.ref" properties.
+// Pseudo-versions also get the abbreviated commit they name as
+// "origin.revision", which needs no module cache.
+func originProperties(props map[string]string, info *ModuleInfo) {
+	if origin := moduleOrigin(info); origin != nil {
+		for name, value := range map[string]string{
+			"origin.vcs":    origin.VCS,
+			"origin.url":    origin.URL,
+			"origin.subdir": origin.Subdir,
+			"origin.hash":   origin.Hash,
+			"origin.ref":    origin.Ref,
+		} {
+			if value != "" {
+				props[name] = value
+			}
+		}
+	}
+
+	version := info.Version
+	if info.Replace != nil {
+		version = info.Replace.Version
+	}
+	if rev, err := module.PseudoVersionRev(version); err == nil {
+		props["origin.revision"] = rev
+	}
+}
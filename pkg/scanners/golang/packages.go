@@ -0,0 +1,161 @@
+package golang
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/tracing"
+)
+
+// goPackage is a package of the build as reported by go list -deps
+type goPackage struct {
+	ImportPath string
+	Module     *ModuleInfo
+	Imports    []string
+}
+
+// listPackages lists the packages of the main module and every package they
+// import, for the GOOS, GOARCH and build tags of the environment, and with
+// tests the test packages of the main module and their imports too.
+// Standard library packages have no module.
+func (s *GoScanner) listPackages(ctx context.Context, dir string, opts scanners.ScanOptions, tests bool) ([]goPackage, error) {
+	args := []string{"list", "-e", "-deps", "-json=ImportPath,Module,Imports"}
+	if tests {
+		args = append(args, "-test")
+	}
+	cmd := s.goCommand(ctx, dir, opts, append(args, "./...")...)
+	out, err := tracing.Output(ctx, cmd)
+	if err != nil {
+		return nil, commandError(cmd, err)
+	}
+
+	var packages []goPackage
+	decoder := json.NewDecoder(strings.NewReader(string(out)))
+	for {
+		var pkg goPackage
+		err := decoder.Decode(&pkg)
+		if errors.Is(err, io.EOF) {
+			return packages, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: decoding %s: %w", scanners.ErrInvalidProject, strings.Join(cmd.Args, " "), err)
+		}
+		packages = append(packages, pkg)
+	}
+}
+
+// markBuild keeps only the modules providing packages to the build with
+// ScopeBuild, or gives every module a "build" property telling whether it
+// does with ScopeAnnotate
+func markBuild(graph *dependencyGraph, packages []goPackage, scope string) {
+	needed := make(map[string]bool)
+	for _, pkg := range packages {
+		if pkg.Module != nil {
+			needed[pkg.Module.Path] = true
+		}
+	}
+
+	for modPath, info := range graph.nodes {
+		switch {
+		case info.Main:
+		case scope == ScopeAnnotate:
+			graph.metadata[modPath]["build"] = strconv.FormatBool(needed[modPath])
+		case !needed[modPath]:
+			delete(graph.nodes, modPath)
+		}
+	}
+	if scope == ScopeBuild {
+		for from, to := range graph.edges {
+			if _, ok := graph.nodes[from]; !ok {
+				delete(graph.edges, from)
+				continue
+			}
+			graph.edges[from] = slices.DeleteFunc(to, func(modPath string) bool {
+				_, ok := graph.nodes[modPath]
+				return !ok
+			})
+		}
+	}
+}
+
+// testOnly returns the modules that provide packages to the tests of the main
+// module but not to its build
+func testOnly(build, tests []goPackage) map[string]bool {
+	needed := make(map[string]bool)
+	for _, pkg := range build {
+		if pkg.Module != nil {
+			needed[pkg.Module.Path] = true
+		}
+	}
+
+	found := make(map[string]bool)
+	for _, pkg := range tests {
+		if pkg.Module != nil && !pkg.Module.Main && !needed[pkg.Module.Path] {
+			found[pkg.Module.Path] = true
+		}
+	}
+	return found
+}
+
+// annotatePackages records the packages of each module the build uses as its
+// "packages" property, and the main module packages importing them, directly
+// or through other modules, as "importedBy". Both are comma separated.
+func annotatePackages(graph *dependencyGraph, packages []goPackage) {
+	byPath := make(map[string]goPackage, len(packages))
+	used := make(map[string][]string)
+	for _, pkg := range packages {
+		byPath[pkg.ImportPath] = pkg
+		if pkg.Module != nil && !pkg.Module.Main {
+			used[pkg.Module.Path] = append(used[pkg.Module.Path], pkg.ImportPath)
+		}
+	}
+
+	// The modules each package pulls in, memoized as Go imports are acyclic
+	reach := make(map[string]map[string]bool)
+	var modules func(importPath string) map[string]bool
+	modules = func(importPath string) map[string]bool {
+		if found, ok := reach[importPath]; ok {
+			return found
+		}
+		found := make(map[string]bool)
+		reach[importPath] = found
+		pkg := byPath[importPath]
+		if pkg.Module != nil && !pkg.Module.Main {
+			found[pkg.Module.Path] = true
+		}
+		for _, imported := range pkg.Imports {
+			for modPath := range modules(imported) {
+				found[modPath] = true
+			}
+		}
+		return found
+	}
+
+	importers := make(map[string][]string)
+	for _, pkg := range packages {
+		if pkg.Module == nil || !pkg.Module.Main {
+			continue
+		}
+		for modPath := range modules(pkg.ImportPath) {
+			importers[modPath] = append(importers[modPath], pkg.ImportPath)
+		}
+	}
+
+	for modPath := range graph.nodes {
+		if list, ok := used[modPath]; ok {
+			slices.Sort(list)
+			graph.metadata[modPath]["packages"] = strings.Join(list, ",")
+		}
+		if list, ok := importers[modPath]; ok {
+			slices.Sort(list)
+			graph.metadata[modPath]["importedBy"] = strings.Join(list, ",")
+		}
+	}
+}
@@ -2,16 +2,38 @@ package golang
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/santoshdahal12/deplister/pkg/license"
 	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/tracing"
+)
+
+// ModModes are the accepted values of ScanOptions.GoMod
+var ModModes = []string{"mod", "vendor", "readonly"}
+
+// Values of ScanOptions.GoScope, selecting the modules reported
+const (
+	ScopeGraph    = "graph"    // Every module of the module graph
+	ScopeBuild    = "build"    // Only modules providing packages to the build
+	ScopeAnnotate = "annotate" // Every module, with a "build" property telling whether the build needs it
 )
 
+// Scopes are the accepted values of ScanOptions.GoScope
+var Scopes = []string{ScopeGraph, ScopeBuild, ScopeAnnotate}
+
 type GoScanner struct {
 	scanners.BaseScanner
 }
@@ -21,15 +43,20 @@ type ModuleInfo struct {
 	Version  string       `json:"Version"`
 	Main     bool         `json:"Main"`
 	Indirect bool         `json:"Indirect"`
+	Dir      string       `json:"Dir,omitempty"`
 	Replace  *ModuleInfo  `json:"Replace,omitempty"`
 	Requires []ModuleInfo `json:"Require,omitempty"`
+	Origin   *Origin      `json:"Origin,omitempty"`
 }
 
 type dependencyGraph struct {
-	nodes    map[string]*ModuleInfo
-	edges    map[string][]string
-	versions map[string]string
-	metadata map[string]map[string]string
+	nodes     map[string]*ModuleInfo
+	edges     map[string][]string
+	versions  map[string]string
+	metadata  map[string]map[string]string
+	goVersion string          // Version of the go tool that built the graph, e.g. go1.22.3
+	testOnly  map[string]bool // Modules only the tests of the main module import
+	errors    []scanners.ScanError
 }
 
 func newDependencyGraph() *dependencyGraph {
@@ -41,6 +68,12 @@ func newDependencyGraph() *dependencyGraph {
 	}
 }
 
+func init() {
+	if err := scanners.Register(NewScanner(), 20); err != nil {
+		panic(err)
+	}
+}
+
 func NewScanner() *GoScanner {
 	return &GoScanner{
 		BaseScanner: scanners.NewBaseScanner("go"),
@@ -48,60 +81,217 @@ func NewScanner() *GoScanner {
 }
 
 func (s *GoScanner) DetectProject(ctx context.Context, dir string) bool {
-	_, err := os.Stat(filepath.Join(dir, "go.mod"))
+	return s.DetectProjectFS(ctx, os.DirFS(dir))
+}
+
+// DetectProjectFS reports whether fsys holds a Go module, or a project
+// managed by dep, glide or govendor
+func (s *GoScanner) DetectProjectFS(ctx context.Context, fsys fs.FS) bool {
+	if hasGoMod(fsys) {
+		return true
+	}
+	manager, _ := legacyManager(fsys)
+	return manager != ""
+}
+
+// hasGoMod reports whether fsys holds a Go module rather than a project of
+// a dependency manager predating modules
+func hasGoMod(fsys fs.FS) bool {
+	_, err := fs.Stat(fsys, "go.mod")
 	return err == nil
 }
 
-func (s *GoScanner) ScanDependencies(ctx context.Context, dir string) (*scanners.ScanResult, error) {
+// CacheFiles returns go.mod and go.sum, which fix the build list, and the
+// files of the dependency managers predating modules. Licenses are read from
+// the module cache, which does not change for a version.
+func (s *GoScanner) CacheFiles() []string {
+	return []string{"go.mod", "go.sum", "Gopkg.lock", "glide.lock", "glide.yaml", "vendor/vendor.json"}
+}
+
+// Cacheable reports false when the scan lists the packages of the build,
+// which depend on the imports of every Go source file
+func (s *GoScanner) Cacheable(opts scanners.ScanOptions) bool {
+	return !listsPackages(opts)
+}
+
+// listsPackages reports whether the options need the packages of the build,
+// rather than only the module graph
+func listsPackages(opts scanners.ScanOptions) bool {
+	return opts.GoScope == ScopeBuild || opts.GoScope == ScopeAnnotate || opts.GoPackages || opts.GoTests
+}
+
+func (s *GoScanner) ScanDependencies(ctx context.Context, dir string, opts scanners.ScanOptions) (*scanners.ScanResult, error) {
+	if !s.DetectProject(ctx, dir) {
+		return nil, scanners.ErrProjectNotFound
+	}
+	// The go tool knows nothing of dependency managers predating modules
+	if !hasGoMod(os.DirFS(dir)) {
+		return s.ScanDependenciesFS(ctx, os.DirFS(dir), opts)
+	}
+
+	ctx, span := tracing.Start(ctx, "go.buildDependencyGraph")
+	graph, err := s.buildDependencyGraph(ctx, dir, opts)
+	tracing.End(span, err)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.buildResult(ctx, graph, os.DirFS(dir), opts)
+	if err != nil {
+		return nil, err
+	}
+	result.Project = project(os.DirFS(dir))
+	result.Errors = graph.errors
+	return result, nil
+}
+
+// ScanDependenciesStream passes each module of the build list to fn once
+// resolved. Only the module graph is held in memory, not the dependencies
+// already passed on.
+func (s *GoScanner) ScanDependenciesStream(ctx context.Context, dir string, opts scanners.ScanOptions, fn func(scanners.Dependency) error) error {
 	if !s.DetectProject(ctx, dir) {
+		return scanners.ErrProjectNotFound
+	}
+	if !hasGoMod(os.DirFS(dir)) {
+		_, err := s.resolveLegacy(os.DirFS(dir), opts, func(_ string, dep scanners.Dependency) error {
+			return fn(dep)
+		})
+		return err
+	}
+
+	ctx, span := tracing.Start(ctx, "go.buildDependencyGraph")
+	graph, err := s.buildDependencyGraph(ctx, dir, opts)
+	tracing.End(span, err)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.resolve(ctx, graph, os.DirFS(dir), opts, func(_ string, dep scanners.Dependency) error {
+		return fn(dep)
+	})
+	return err
+}
+
+// ScanDependenciesFS scans a project without the go tool, so the result only
+// contains the requirements listed in go.mod, or the lockfile of dep, glide
+// or govendor for projects predating modules.
+func (s *GoScanner) ScanDependenciesFS(ctx context.Context, fsys fs.FS, opts scanners.ScanOptions) (*scanners.ScanResult, error) {
+	if !s.DetectProjectFS(ctx, fsys) {
 		return nil, scanners.ErrProjectNotFound
 	}
+	var result *scanners.ScanResult
+	if hasGoMod(fsys) {
+		graph, err := s.buildModFileGraph(fsys)
+		if err != nil {
+			return nil, err
+		}
+		if result, err = s.buildResult(ctx, graph, fsys, opts); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		result, err = collect(func(emit func(string, scanners.Dependency) error) (*scanners.DependencyGraph, error) {
+			return s.resolveLegacy(fsys, opts, emit)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	result.Project = project(fsys)
+	return result, nil
+}
+
+// project returns the main module of the project in fsys or, for projects
+// predating modules, the root import path their lockfile records. Modules
+// have no version of their own.
+func project(fsys fs.FS) *scanners.Project {
+	if goMod, err := ReadGoMod(fsys); err == nil {
+		if goMod.Module == "" {
+			return nil
+		}
+		return &scanners.Project{Name: goMod.Module}
+	}
+	if _, root, _, err := readLegacy(fsys); err == nil && root != "" {
+		return &scanners.Project{Name: root}
+	}
+	return nil
+}
+
+func (s *GoScanner) buildResult(ctx context.Context, graph *dependencyGraph, fsys fs.FS, opts scanners.ScanOptions) (*scanners.ScanResult, error) {
+	return collect(func(emit func(string, scanners.Dependency) error) (*scanners.DependencyGraph, error) {
+		return s.resolve(ctx, graph, fsys, opts, emit)
+	})
+}
 
-	graph, err := s.buildDependencyGraph(ctx, dir)
+// collect gathers the dependencies a resolve function emits into a result
+// with their graph
+func collect(resolve func(emit func(string, scanners.Dependency) error) (*scanners.DependencyGraph, error)) (*scanners.ScanResult, error) {
+	result := &scanners.ScanResult{Dependencies: make([]scanners.Dependency, 0)}
+	nodes := make(map[string]*scanners.Dependency)
+	resolved, err := resolve(func(key string, dep scanners.Dependency) error {
+		result.Dependencies = append(result.Dependencies, dep)
+		nodes[key] = &dep
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	resolved.Nodes = nodes
+	result.Graph = resolved
+	return result, nil
+}
+
+// resolve converts the modules of the go tool's graph into dependencies,
+// passing each to emit with its node key, and returns the graph without nodes
+func (s *GoScanner) resolve(ctx context.Context, graph *dependencyGraph, fsys fs.FS, opts scanners.ScanOptions, emit func(string, scanners.Dependency) error) (resolved *scanners.DependencyGraph, err error) {
+	_, span := tracing.Start(ctx, "go.buildResult", attribute.Int("deplister.modules", len(graph.nodes)))
+	defer func() { tracing.End(span, err) }()
 
 	mainModule := s.findMainModule(graph)
 	if mainModule == "" {
-		return nil, scanners.ErrInvalidProject
+		return nil, fmt.Errorf("%w: the go tool reported no main module", scanners.ErrInvalidProject)
 	}
 
-	result := &scanners.ScanResult{
-		Dependencies: make([]scanners.Dependency, 0),
-		Graph: &scanners.DependencyGraph{
-			Nodes: make(map[string]*scanners.Dependency),
-			Edges: graph.edges,
-		},
+	// Minimal version selection keeps one version of each module, so the go
+	// tool's graph is keyed by module path. Re-key it by node key.
+	key := func(modPath string) string {
+		return scanners.NodeKey(modPath, graph.versions[modPath])
+	}
+	resolved = &scanners.DependencyGraph{Edges: make(map[string][]string, len(graph.edges)), Root: key(mainModule)}
+	emitted := 0
+	modPaths := make(map[string]string, len(graph.nodes))
+	for parent, children := range graph.edges {
+		modPaths[key(parent)] = parent
+		for _, child := range children {
+			modPaths[key(child)] = child
+			resolved.AddEdge(key(parent), key(child))
+		}
 	}
 
 	// Get direct dependencies from go.mod
-	directDeps, err := s.getDirectDependencies(dir)
+	directDeps, err := s.getDirectDependencies(fsys)
 	if err != nil {
 		return nil, err
 	}
+	sums := readGoSum(fsys)
 
+	previous := scanners.Reusable(opts.Previous)
+	shortest := resolved.ShortestPaths(key(mainModule))
 	for modPath, info := range graph.nodes {
 		if modPath == mainModule {
 			continue
 		}
 
-		// Calculate all possible paths to this dependency
-		paths := result.Graph.FindAllPaths(mainModule, modPath)
-		minDepth := -1
-		for _, path := range paths {
-			if minDepth == -1 || path.Depth < minDepth {
-				minDepth = path.Depth
-			}
+		paths, minDepth := scanners.Paths(resolved, shortest, key(mainModule), key(modPath), opts)
+		if !opts.WithinDepth(minDepth) {
+			continue
 		}
 
 		// Get all immediate parents
 		var parents []string
-		for parent, children := range graph.edges {
-			for _, child := range children {
-				if child == modPath && parent != mainModule {
-					parents = append(parents, parent)
-				}
+		for _, parent := range resolved.Parents(key(modPath)) {
+			if parent != key(mainModule) {
+				parents = append(parents, modPaths[parent])
 			}
 		}
 
@@ -112,9 +302,16 @@ func (s *GoScanner) ScanDependencies(ctx context.Context, dir string) (*scanners
 		props["manager"] = "go"
 
 		// Set dependency type
-		if !info.Indirect {
+		switch {
+		case graph.testOnly[modPath]:
+			// Test dependencies are left out like npm dev dependencies
+			if !opts.IncludeDev {
+				continue
+			}
+			props["dependencyType"] = "test"
+		case !info.Indirect:
 			props["dependencyType"] = "direct"
-		} else {
+		default:
 			props["dependencyType"] = "indirect"
 		}
 
@@ -123,10 +320,32 @@ func (s *GoScanner) ScanDependencies(ctx context.Context, dir string) (*scanners
 			props["replaced_version"] = info.Replace.Version
 		}
 
+		// Replacements by local directories have no checksum
+		sumKey := info.Path + "@" + info.Version
+		if info.Replace != nil {
+			sumKey = info.Replace.Path + "@" + info.Replace.Version
+		}
+		if sum, ok := sums[sumKey]; ok {
+			props["checksum"] = sum
+		}
+		if sum, ok := sums[sumKey+"/go.mod"]; ok {
+			props["checksum.gomod"] = sum
+		}
+		originProperties(props, info)
+
+		// Module versions are immutable, unlike replacements
+		var license string
+		if prev, ok := previous[key(modPath)]; ok && info.Replace == nil && prev.Properties["checksum"] == props["checksum"] {
+			license = prev.License
+		} else {
+			license = moduleLicense(info)
+		}
+
 		dependency := scanners.Dependency{
 			Name:        info.Path,
 			Version:     info.Version,
 			Type:        "go",
+			License:     license,
 			IsDirectDep: !info.Indirect && directDeps[modPath], // Use both Indirect flag and direct deps check
 			Parent:      "",
 			Parents:     parents,
@@ -139,88 +358,196 @@ func (s *GoScanner) ScanDependencies(ctx context.Context, dir string) (*scanners
 			dependency.Parent = parents[0]
 		}
 
-		result.Dependencies = append(result.Dependencies, dependency)
-		result.Graph.Nodes[modPath] = &dependency
+		if err := emit(key(modPath), dependency); err != nil {
+			return nil, err
+		}
+		emitted++
 	}
 
-	if len(result.Dependencies) == 0 {
-		return nil, scanners.ErrInvalidProject
+	if opts.GoStdlib {
+		goMod, err := ReadGoMod(fsys)
+		if err != nil {
+			return nil, err
+		}
+		dependency := stdlib(goMod, graph.goVersion)
+		stdKey := scanners.NodeKey(dependency.Name, dependency.Version)
+		dependency.Paths = []scanners.DependencyPath{{Path: []string{key(mainModule), stdKey}, Depth: 1}}
+		resolved.AddEdge(key(mainModule), stdKey)
+		if err := emit(stdKey, dependency); err != nil {
+			return nil, err
+		}
+		emitted++
 	}
 
-	return result, nil
+	if emitted == 0 {
+		return nil, fmt.Errorf("%w: no modules in the build list", scanners.ErrInvalidProject)
+	}
+
+	return resolved, nil
 }
 
-// getDirectDependencies reads go.mod file and returns a map of direct dependencies
-func (s *GoScanner) getDirectDependencies(dir string) (map[string]bool, error) {
-	goModPath := filepath.Join(dir, "go.mod")
-	content, err := os.ReadFile(goModPath)
+// readGoSum returns the checksums recorded in go.sum, keyed by path@version
+// for module zips and path@version/go.mod for go.mod files
+func readGoSum(fsys fs.FS) map[string]string {
+	sums := make(map[string]string)
+	file, err := fsys.Open("go.sum")
 	if err != nil {
-		return nil, err
+		return sums
 	}
+	defer file.Close()
 
-	directDeps := make(map[string]bool)
-	lines := strings.Split(string(content), "\n")
-	inRequireBlock := false
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "//") {
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
 			continue
 		}
+		sums[fields[0]+"@"+fields[1]] = fields[2]
+	}
+	return sums
+}
+
+// moduleLicense identifies the license of a module, or of its replacement,
+// from the module's directory in the module cache
+func moduleLicense(info *ModuleInfo) string {
+	mod := info
+	if info.Replace != nil {
+		mod = info.Replace
+	}
 
-		// Handle single-line requires
-		if strings.HasPrefix(line, "require ") && !strings.HasSuffix(line, "(") {
-			fields := strings.Fields(line)[1:]
-			if len(fields) > 0 {
-				dep := fields[0]
-				if !strings.Contains(line, "// indirect") {
-					directDeps[dep] = true
-				}
+	dir := mod.Dir
+	if dir == "" && mod.Version != "" {
+		dir = moduleCacheDir(mod.Path, mod.Version)
+	}
+	if dir == "" {
+		return ""
+	}
+	return license.FromFS(os.DirFS(dir))
+}
+
+// moduleCacheDir returns where the go tool extracts a module version. go list
+// only reports the directory when it is known, so this also covers scans
+// that only read go.mod.
+func moduleCacheDir(path, version string) string {
+	cache := moduleCache()
+	if cache == "" {
+		return ""
+	}
+	return filepath.Join(cache, filepath.FromSlash(escapeModulePath(path))+"@"+escapeModulePath(version))
+}
+
+// moduleCache returns the root of the module cache, "" when it is unknown
+func moduleCache() string {
+	cache := os.Getenv("GOMODCACHE")
+	if cache == "" {
+		gopath := os.Getenv("GOPATH")
+		if list := filepath.SplitList(gopath); len(list) > 0 {
+			gopath = list[0]
+		}
+		if gopath == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return ""
 			}
-			continue
+			gopath = filepath.Join(home, "go")
 		}
+		cache = filepath.Join(gopath, "pkg", "mod")
+	}
+	return cache
+}
 
-		// Handle require blocks
-		if line == "require (" {
-			inRequireBlock = true
-			continue
-		}
-		if line == ")" {
-			inRequireBlock = false
-			continue
+// escapeModulePath applies the module cache's case encoding, which replaces
+// upper case letters by "!" followed by the lower case letter
+func escapeModulePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if 'A' <= r && r <= 'Z' {
+			b.WriteByte('!')
+			r += 'a' - 'A'
 		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
 
-		if inRequireBlock {
-			fields := strings.Fields(line)
-			if len(fields) > 0 {
-				dep := fields[0]
-				if !strings.Contains(line, "// indirect") {
-					directDeps[dep] = true
-				}
-			}
+// getDirectDependencies reads go.mod file and returns a map of direct dependencies
+func (s *GoScanner) getDirectDependencies(fsys fs.FS) (map[string]bool, error) {
+	goMod, err := ReadGoMod(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	directDeps := make(map[string]bool)
+	for _, req := range goMod.Requires {
+		if !req.Indirect {
+			directDeps[req.Path] = true
 		}
 	}
 
 	return directDeps, nil
 }
 
-func (s *GoScanner) buildDependencyGraph(ctx context.Context, dir string) (*dependencyGraph, error) {
+// buildModFileGraph builds a graph from go.mod alone, for file systems the go
+// tool cannot run against. Every requirement is attached to the main module.
+func (s *GoScanner) buildModFileGraph(fsys fs.FS) (*dependencyGraph, error) {
+	goMod, err := ReadGoMod(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if goMod.Module == "" {
+		return nil, fmt.Errorf("%w: go.mod has no module directive", scanners.ErrInvalidProject)
+	}
+
+	graph := newDependencyGraph()
+	graph.nodes[goMod.Module] = &ModuleInfo{Path: goMod.Module, Main: true}
+
+	for i := range goMod.Requires {
+		info := goMod.Requires[i]
+		if replace, ok := goMod.Replaces[info.Path]; ok {
+			info.Replace = &replace
+		}
+
+		graph.nodes[info.Path] = &info
+		graph.versions[info.Path] = info.Version
+		graph.edges[goMod.Module] = append(graph.edges[goMod.Module], info.Path)
+
+		metadata := make(map[string]string)
+		if info.Indirect {
+			metadata["dependencyType"] = "indirect"
+		} else {
+			metadata["dependencyType"] = "direct"
+		}
+		if info.Replace != nil {
+			metadata["replaced"] = "true"
+		}
+		graph.metadata[info.Path] = metadata
+	}
+
+	return graph, nil
+}
+
+func (s *GoScanner) buildDependencyGraph(ctx context.Context, dir string, opts scanners.ScanOptions) (*dependencyGraph, error) {
+	// The mode ends up in GOFLAGS, where anything else could add flags
+	if opts.GoMod != "" && !slices.Contains(ModModes, opts.GoMod) {
+		return nil, fmt.Errorf("%w: invalid -mod mode %q", scanners.ErrScanFailed, opts.GoMod)
+	}
+	if opts.GoScope != "" && !slices.Contains(Scopes, opts.GoScope) {
+		return nil, fmt.Errorf("%w: invalid module scope %q", scanners.ErrScanFailed, opts.GoScope)
+	}
+
 	graph := newDependencyGraph()
 
-	listCmd := exec.CommandContext(ctx, "go", "list", "-m", "-json", "all")
-	listCmd.Dir = dir
-	listOutput, err := listCmd.Output()
+	listCmd := s.goCommand(ctx, dir, opts, "list", "-m", "-json", "all")
+	listOutput, err := tracing.Output(ctx, listCmd)
 	if err != nil {
-		return nil, scanners.ErrScanFailed
+		return nil, commandError(listCmd, err)
 	}
 
 	decoder := json.NewDecoder(strings.NewReader(string(listOutput)))
 	for decoder.More() {
 		var info ModuleInfo
 		if err := decoder.Decode(&info); err != nil {
-			return nil, scanners.ErrInvalidProject
+			return nil, fmt.Errorf("%w: decoding %s: %w", scanners.ErrInvalidProject, strings.Join(listCmd.Args, " "), err)
 		}
 		graph.nodes[info.Path] = &info
 		graph.versions[info.Path] = info.Version
@@ -238,11 +565,13 @@ func (s *GoScanner) buildDependencyGraph(ctx context.Context, dir string) (*depe
 		graph.metadata[info.Path] = metadata
 	}
 
-	graphCmd := exec.CommandContext(ctx, "go", "mod", "graph")
-	graphCmd.Dir = dir
-	graphOutput, err := graphCmd.Output()
+	// The build list alone makes a result, so the steps after it only add to
+	// it and their failures are recorded rather than failing the scan
+	graphCmd := s.goCommand(ctx, dir, opts, "mod", "graph")
+	graphOutput, err := tracing.Output(ctx, graphCmd)
 	if err != nil {
-		return nil, scanners.ErrScanFailed
+		graph.fail(graphCmd, err)
+		graph.flatten()
 	}
 
 	scanner := bufio.NewScanner(strings.NewReader(string(graphOutput)))
@@ -261,9 +590,122 @@ func (s *GoScanner) buildDependencyGraph(ctx context.Context, dir string) (*depe
 		graph.edges[fromPath] = append(graph.edges[fromPath], toPath)
 	}
 
+	if opts.GoStdlib {
+		cmd := s.goCommand(ctx, dir, opts, "env", "GOVERSION")
+		out, err := tracing.Output(ctx, cmd)
+		if err != nil {
+			graph.fail(cmd, err)
+		}
+		graph.goVersion = strings.TrimSpace(string(out))
+	}
+
+	if listsPackages(opts) {
+		packages, err := s.listPackages(ctx, dir, opts, false)
+		if err != nil {
+			graph.errors = append(graph.errors, scanners.ScanError{Step: "go list -deps", Message: err.Error()})
+			return graph, nil
+		}
+		if opts.GoTests {
+			tests, err := s.listPackages(ctx, dir, opts, true)
+			if err != nil {
+				graph.errors = append(graph.errors, scanners.ScanError{Step: "go list -deps -test", Message: err.Error()})
+			} else {
+				graph.testOnly = testOnly(packages, tests)
+			}
+		}
+		if opts.GoPackages {
+			annotatePackages(graph, packages)
+		}
+		if opts.GoScope == ScopeBuild || opts.GoScope == ScopeAnnotate {
+			markBuild(graph, packages, opts.GoScope)
+		}
+	}
+
 	return graph, nil
 }
 
+// fail records a go command that failed without failing the scan
+func (g *dependencyGraph) fail(cmd *exec.Cmd, err error) {
+	g.errors = append(g.errors, scanners.ScanError{
+		Step:    strings.Join(cmd.Args, " "),
+		Message: commandCause(err).Error(),
+	})
+}
+
+// flatten attaches every module to the main module, as go.mod alone would,
+// for a build list whose module graph is unknown
+func (g *dependencyGraph) flatten() {
+	var mainModule string
+	for path, info := range g.nodes {
+		if info.Main {
+			mainModule = path
+		}
+	}
+	if mainModule == "" {
+		return
+	}
+	paths := make([]string, 0, len(g.nodes))
+	for path := range g.nodes {
+		if path != mainModule {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+	g.edges[mainModule] = paths
+}
+
+// goCommand prepares a go tool invocation in dir honoring the scan options
+func (s *GoScanner) goCommand(ctx context.Context, dir string, opts scanners.ScanOptions, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	cmd.Env = goEnv(os.Environ(), opts)
+	return cmd
+}
+
+// goEnv returns the environment of go commands: environ with the extra
+// variables of the options, the -mod mode added to GOFLAGS and, offline, the
+// module proxy, checksum database and toolchain downloads disabled so that
+// the go tool fails instead of reaching the network. Offline scans default to
+// -mod=readonly, so that go.mod and go.sum needing updates or naming modules
+// missing from the module cache are errors rather than rewritten. Later
+// entries override earlier ones.
+func goEnv(environ []string, opts scanners.ScanOptions) []string {
+	env := append(slices.Clip(environ), opts.Env...)
+
+	mode := opts.GoMod
+	if mode == "" && opts.Offline {
+		mode = "readonly"
+	}
+	if mode != "" {
+		var goflags string
+		for _, entry := range env {
+			if value, ok := strings.CutPrefix(entry, "GOFLAGS="); ok {
+				goflags = value
+			}
+		}
+		env = append(env, "GOFLAGS="+strings.TrimSpace(goflags+" -mod="+mode))
+	}
+	if opts.Offline {
+		env = append(env, "GOPROXY=off", "GOSUMDB=off", "GOTOOLCHAIN=local")
+	}
+	return env
+}
+
+// commandError describes a failed go command with what it wrote to stderr,
+// such as authentication failures fetching private modules
+func commandError(cmd *exec.Cmd, err error) error {
+	return fmt.Errorf("%w: %s: %w", scanners.ErrScanFailed, strings.Join(cmd.Args, " "), commandCause(err))
+}
+
+// commandCause adds what a failed command wrote to stderr to its error
+func commandCause(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(bytes.TrimSpace(exitErr.Stderr)) > 0 {
+		return fmt.Errorf("%w: %s", err, bytes.TrimSpace(exitErr.Stderr))
+	}
+	return err
+}
+
 func (s *GoScanner) findMainModule(graph *dependencyGraph) string {
 	for path, info := range graph.nodes {
 		if info.Main {
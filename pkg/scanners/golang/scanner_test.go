@@ -2,10 +2,13 @@ package golang
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"testing/fstest"
 
 	"github.com/santoshdahal12/deplister/pkg/scanners"
 
@@ -97,8 +100,8 @@ require (
 	assert.NoError(t, err, "failed to write go.mod")
 
 	scanner := NewScanner()
-	result, err := scanner.ScanDependencies(context.Background(), dir)
-	if err == scanners.ErrScanFailed {
+	result, err := scanner.ScanDependencies(context.Background(), dir, scanners.DefaultScanOptions())
+	if errors.Is(err, scanners.ErrScanFailed) {
 		t.Skip("skipping integration test: go tools not available")
 	}
 	assert.NoError(t, err, "scan failed")
@@ -139,8 +142,8 @@ replace github.com/original/pkg => github.com/fork/pkg v1.1.0
 	assert.NoError(t, err, "failed to write go.mod")
 
 	scanner := NewScanner()
-	result, err := scanner.ScanDependencies(context.Background(), dir)
-	if err == scanners.ErrScanFailed {
+	result, err := scanner.ScanDependencies(context.Background(), dir, scanners.DefaultScanOptions())
+	if errors.Is(err, scanners.ErrScanFailed) {
 		t.Skip("skipping integration test: go tools not available")
 	}
 	assert.NoError(t, err, "scan failed")
@@ -172,8 +175,8 @@ require (
 	assert.NoError(t, err, "failed to write go.mod")
 
 	scanner := NewScanner()
-	result, err := scanner.ScanDependencies(context.Background(), dir)
-	if err == scanners.ErrScanFailed {
+	result, err := scanner.ScanDependencies(context.Background(), dir, scanners.DefaultScanOptions())
+	if errors.Is(err, scanners.ErrScanFailed) {
 		t.Skip("skipping integration test: go tools not available")
 	}
 	assert.NoError(t, err, "scan failed")
@@ -229,7 +232,7 @@ replace (
 	}
 
 	scanner := NewScanner()
-	result, err := scanner.ScanDependencies(context.Background(), dir)
+	result, err := scanner.ScanDependencies(context.Background(), dir, scanners.DefaultScanOptions())
 	if err != nil {
 		t.Fatalf("scan failed: %v", err)
 	}
@@ -258,3 +261,471 @@ replace (
 		assert.True(t, found, "replacement for %s not found", pkg)
 	}
 }
+
+func TestGoScanner_ScanDependenciesFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod": {Data: []byte(`module example.com/test
+
+go 1.20
+
+require github.com/original/pkg v1.0.0
+
+require (
+	github.com/stretchr/testify v1.8.1
+	golang.org/x/sync v0.1.0 // indirect
+)
+
+replace github.com/original/pkg => github.com/fork/pkg v1.1.0
+`)},
+		"go.sum": {Data: []byte(`github.com/fork/pkg v1.1.0 h1:fork=
+github.com/fork/pkg v1.1.0/go.mod h1:forkmod=
+github.com/stretchr/testify v1.8.1 h1:testify=
+github.com/stretchr/testify v1.8.1/go.mod h1:testifymod=
+`)},
+	}
+
+	var scanner scanners.FSScanner = NewScanner()
+	assert.True(t, scanner.DetectProjectFS(context.Background(), fsys))
+
+	result, err := scanner.ScanDependenciesFS(context.Background(), fsys, scanners.DefaultScanOptions())
+	if !assert.NoError(t, err, "scan failed") {
+		return
+	}
+	assert.Equal(t, &scanners.Project{Name: "example.com/test"}, result.Project)
+	assert.Equal(t, "example.com/test", result.Graph.Root)
+
+	deps := make(map[string]scanners.Dependency)
+	for _, dep := range result.Dependencies {
+		deps[dep.Name] = dep
+	}
+	assert.Len(t, deps, 3)
+
+	assert.True(t, deps["github.com/stretchr/testify"].IsDirectDep)
+	assert.Equal(t, "v1.8.1", deps["github.com/stretchr/testify"].Version)
+	assert.Equal(t, 1, deps["github.com/stretchr/testify"].Depth)
+
+	assert.False(t, deps["golang.org/x/sync"].IsDirectDep)
+	assert.Equal(t, "indirect", deps["golang.org/x/sync"].Properties["dependencyType"])
+
+	assert.Equal(t, "github.com/fork/pkg", deps["github.com/original/pkg"].Properties["replaced_by"])
+	assert.Equal(t, "v1.1.0", deps["github.com/original/pkg"].Properties["replaced_version"])
+
+	assert.Equal(t, "h1:testify=", deps["github.com/stretchr/testify"].Properties["checksum"])
+	assert.Equal(t, "h1:fork=", deps["github.com/original/pkg"].Properties["checksum"])
+	assert.Equal(t, "h1:forkmod=", deps["github.com/original/pkg"].Properties["checksum.gomod"])
+	assert.NotContains(t, deps["golang.org/x/sync"].Properties, "checksum")
+}
+
+func TestGoScanner_Stdlib(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod": {Data: []byte("module example.com/test\n\ngo 1.21\n\ntoolchain go1.22.3\n\nrequire github.com/stretchr/testify v1.8.1\n")},
+	}
+
+	opts := scanners.DefaultScanOptions()
+	opts.GoStdlib = true
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, result.Dependencies, 2)
+
+	stdlib, ok := result.Graph.Node("stdlib", "v1.22.3")
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, "1.21", stdlib.Properties["goDirective"])
+	assert.Equal(t, "go1.22.3", stdlib.Properties["toolchainDirective"])
+	assert.Equal(t, []string{"example.com/test"}, result.Graph.Parents(scanners.NodeKey("stdlib", "v1.22.3")))
+}
+
+func TestModuleLicense(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("GOMODCACHE", cache)
+
+	dir := filepath.Join(cache, "github.com", "!burnt!sushi", "toml@v1.3.2")
+	assert.NoError(t, os.MkdirAll(dir, 0o755))
+	err := os.WriteFile(filepath.Join(dir, "COPYING"), []byte("Permission is hereby granted, free of charge, to any person obtaining a copy"), 0o644)
+	assert.NoError(t, err)
+
+	assert.Equal(t, dir, moduleCacheDir("github.com/BurntSushi/toml", "v1.3.2"))
+	assert.Equal(t, "MIT", moduleLicense(&ModuleInfo{Path: "github.com/BurntSushi/toml", Version: "v1.3.2"}))
+
+	// Replacements are licensed by the module replacing them
+	replaced := &ModuleInfo{
+		Path:    "github.com/original/toml",
+		Version: "v1.0.0",
+		Replace: &ModuleInfo{Path: "github.com/fork/toml", Dir: dir},
+	}
+	assert.Equal(t, "MIT", moduleLicense(replaced))
+
+	assert.Equal(t, "", moduleLicense(&ModuleInfo{Path: "example.com/missing", Version: "v1.0.0"}))
+}
+
+func TestGoEnv(t *testing.T) {
+	environ := []string{"HOME=/home/dev", "GOFLAGS=-tags=integration"}
+
+	assert.Equal(t, environ, goEnv(environ, scanners.DefaultScanOptions()))
+
+	opts := scanners.DefaultScanOptions()
+	opts.Env = []string{"GOPRIVATE=example.com/*"}
+	opts.GoMod = "vendor"
+	assert.Equal(t, []string{"HOME=/home/dev", "GOFLAGS=-tags=integration", "GOPRIVATE=example.com/*", "GOFLAGS=-tags=integration -mod=vendor"}, goEnv(environ, opts))
+
+	opts = scanners.DefaultScanOptions()
+	opts.Offline = true
+	assert.Equal(t, []string{"GOFLAGS=-mod=readonly", "GOPROXY=off", "GOSUMDB=off", "GOTOOLCHAIN=local"}, goEnv(nil, opts))
+}
+
+func TestGoScanner_Cacheable(t *testing.T) {
+	scanner := NewScanner()
+	opts := scanners.DefaultScanOptions()
+	assert.True(t, scanner.Cacheable(opts))
+	opts.GoScope = ScopeGraph
+	assert.True(t, scanner.Cacheable(opts))
+
+	for _, set := range []func(*scanners.ScanOptions){
+		func(o *scanners.ScanOptions) { o.GoScope = ScopeBuild },
+		func(o *scanners.ScanOptions) { o.GoScope = ScopeAnnotate },
+		func(o *scanners.ScanOptions) { o.GoPackages = true },
+		func(o *scanners.ScanOptions) { o.GoTests = true },
+	} {
+		opts := scanners.DefaultScanOptions()
+		set(&opts)
+		assert.False(t, scanner.Cacheable(opts), "results depending on the sources must not be cached")
+	}
+}
+
+func TestGoScanner_CommandErrors(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go tool not available")
+	}
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.21\n"), 0644)
+	assert.NoError(t, err)
+
+	opts := scanners.DefaultScanOptions()
+	opts.Env = []string{"GOFLAGS=-nonexistent"}
+	_, err = NewScanner().ScanDependencies(context.Background(), dir, opts)
+	assert.ErrorIs(t, err, scanners.ErrScanFailed)
+	assert.ErrorContains(t, err, "unknown flag -nonexistent")
+
+	opts = scanners.DefaultScanOptions()
+	opts.GoMod = "mod -toolexec=/bin/false"
+	_, err = NewScanner().ScanDependencies(context.Background(), dir, opts)
+	assert.ErrorIs(t, err, scanners.ErrScanFailed)
+	assert.ErrorContains(t, err, "invalid -mod mode")
+}
+
+func TestGoScanner_Scope(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go tool not available")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.21
+
+require (
+	example.com/linux v0.0.0
+	example.com/unused v0.0.0
+)
+
+replace (
+	example.com/linux => ./linux
+	example.com/unused => ./unused
+)
+`,
+		"main.go":          "package main\n\nfunc main() {}\n",
+		"main_linux.go":    "//go:build linux\n\npackage main\n\nimport _ \"example.com/linux\"\n",
+		"linux/go.mod":     "module example.com/linux\n\ngo 1.21\n",
+		"linux/linux.go":   "package linux\n",
+		"unused/go.mod":    "module example.com/unused\n\ngo 1.21\n",
+		"unused/unused.go": "package unused\n",
+	}
+	for name, content := range files {
+		os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		assert.NoError(t, err)
+	}
+
+	scan := func(scope, goos string) map[string]*scanners.Dependency {
+		opts := scanners.DefaultScanOptions()
+		opts.Offline = true
+		opts.GoScope = scope
+		opts.Env = []string{"GOOS=" + goos, "GOFLAGS=", "GOWORK=off"}
+		result, err := NewScanner().ScanDependencies(context.Background(), dir, opts)
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		deps := make(map[string]*scanners.Dependency)
+		for i := range result.Dependencies {
+			deps[result.Dependencies[i].Name] = &result.Dependencies[i]
+		}
+		return deps
+	}
+
+	graph := scan(ScopeGraph, "linux")
+	assert.Len(t, graph, 2)
+	assert.NotContains(t, graph["example.com/unused"].Properties, "build")
+
+	annotated := scan(ScopeAnnotate, "linux")
+	assert.Len(t, annotated, 2)
+	assert.Equal(t, "true", annotated["example.com/linux"].Properties["build"])
+	assert.Equal(t, "false", annotated["example.com/unused"].Properties["build"])
+
+	build := scan(ScopeBuild, "linux")
+	assert.Len(t, build, 1)
+	assert.Contains(t, build, "example.com/linux")
+
+	// Only the main module is left when building for Windows
+	opts := scanners.DefaultScanOptions()
+	opts.Offline = true
+	opts.GoScope = ScopeBuild
+	opts.Env = []string{"GOOS=windows", "GOFLAGS=", "GOWORK=off"}
+	_, err := NewScanner().ScanDependencies(context.Background(), dir, opts)
+	assert.ErrorIs(t, err, scanners.ErrInvalidProject)
+}
+
+func TestGoScanner_Packages(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go tool not available")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.21
+
+require (
+	example.com/lib v0.0.0
+	example.com/util v0.0.0
+)
+
+replace (
+	example.com/lib => ./lib
+	example.com/util => ./util
+)
+`,
+		"cmd/server/main.go": "package main\n\nimport _ \"example.com/lib/http\"\n\nfunc main() {}\n",
+		"internal/db/db.go":  "package db\n\nimport _ \"example.com/lib/sql\"\n",
+		"lib/go.mod":         "module example.com/lib\n\ngo 1.21\n\nrequire example.com/util v0.0.0\n",
+		"lib/http/http.go":   "package http\n\nimport _ \"example.com/util\"\n",
+		"lib/sql/sql.go":     "package sql\n\nimport _ \"strings\"\n",
+		"util/go.mod":        "module example.com/util\n\ngo 1.21\n",
+		"util/util.go":       "package util\n",
+	}
+	for name, content := range files {
+		os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		assert.NoError(t, err)
+	}
+
+	opts := scanners.DefaultScanOptions()
+	opts.Offline = true
+	opts.GoPackages = true
+	opts.Env = []string{"GOFLAGS=", "GOWORK=off"}
+	result, err := NewScanner().ScanDependencies(context.Background(), dir, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	lib, ok := result.Graph.Node("example.com/lib", "v0.0.0")
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, "example.com/lib/http,example.com/lib/sql", lib.Properties["packages"])
+	assert.Equal(t, "example.com/app/cmd/server,example.com/app/internal/db", lib.Properties["importedBy"])
+
+	util, _ := result.Graph.Node("example.com/util", "v0.0.0")
+	assert.Equal(t, "example.com/util", util.Properties["packages"])
+	assert.Equal(t, "example.com/app/cmd/server", util.Properties["importedBy"])
+}
+
+func TestGoScanner_OfflineReadOnly(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go tool not available")
+	}
+	dir := t.TempDir()
+	// main.go imports example.com/util, which go.mod only requires through
+	// example.com/lib, so listing packages with -mod=mod would add it
+	files := map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.21
+
+require example.com/lib v0.0.0
+
+replace (
+	example.com/lib => ./lib
+	example.com/util => ./util
+)
+`,
+		"go.sum":       "",
+		"main.go":      "package main\n\nimport (\n\t_ \"example.com/lib\"\n\t_ \"example.com/util\"\n)\n\nfunc main() {}\n",
+		"lib/go.mod":   "module example.com/lib\n\ngo 1.21\n\nrequire example.com/util v0.0.0\n",
+		"lib/lib.go":   "package lib\n\nimport _ \"example.com/util\"\n",
+		"util/go.mod":  "module example.com/util\n\ngo 1.21\n",
+		"util/util.go": "package util\n",
+	}
+	for name, content := range files {
+		os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		assert.NoError(t, err)
+	}
+
+	opts := scanners.DefaultScanOptions()
+	opts.Offline = true
+	opts.GoPackages = true
+	opts.Env = []string{"GOFLAGS=", "GOWORK=off"}
+	result, err := NewScanner().ScanDependencies(context.Background(), dir, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "go list -deps", result.Errors[0].Step)
+		assert.Contains(t, result.Errors[0].Message, "updates to go.mod needed")
+	}
+
+	for _, name := range []string{"go.mod", "go.sum"} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		assert.NoError(t, err)
+		assert.Equal(t, files[name], string(content), "offline scans must not rewrite %s", name)
+	}
+}
+
+func TestGoScanner_Tests(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go tool not available")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": `module example.com/app
+
+go 1.21
+
+require (
+	example.com/assert v0.0.0
+	example.com/lib v0.0.0
+)
+
+replace (
+	example.com/assert => ./assert
+	example.com/lib => ./lib
+)
+`,
+		"app.go":           "package app\n\nimport _ \"example.com/lib\"\n",
+		"app_test.go":      "package app\n\nimport _ \"example.com/assert\"\n",
+		"assert/go.mod":    "module example.com/assert\n\ngo 1.21\n",
+		"assert/assert.go": "package assert\n",
+		"lib/go.mod":       "module example.com/lib\n\ngo 1.21\n",
+		"lib/lib.go":       "package lib\n",
+	}
+	for name, content := range files {
+		os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		assert.NoError(t, err)
+	}
+
+	opts := scanners.DefaultScanOptions()
+	opts.Offline = true
+	opts.GoTests = true
+	opts.Env = []string{"GOFLAGS=", "GOWORK=off"}
+	result, err := NewScanner().ScanDependencies(context.Background(), dir, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	types := make(map[string]string)
+	for _, dep := range result.Dependencies {
+		types[dep.Name] = dep.Properties["dependencyType"]
+	}
+	assert.Equal(t, map[string]string{"example.com/assert": "test", "example.com/lib": "direct"}, types)
+
+	opts.IncludeDev = false
+	result, err = NewScanner().ScanDependencies(context.Background(), dir, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, result.Dependencies, 1) {
+		assert.Equal(t, "example.com/lib", result.Dependencies[0].Name)
+	}
+}
+
+func TestModuleOrigin(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("GOMODCACHE", cache)
+
+	dir := filepath.Join(cache, "cache", "download", "github.com", "!burnt!sushi", "toml", "@v")
+	assert.NoError(t, os.MkdirAll(dir, 0o755))
+	info := `{"Version":"v1.3.2","Time":"2023-06-08T06:01:07Z","Origin":{"VCS":"git","URL":"https://github.com/BurntSushi/toml","Hash":"a1a6b7a8d2d5a2a4b8e5a5d0b3c8b6b6d7a5f3e1","Ref":"refs/tags/v1.3.2"}}`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "v1.3.2.info"), []byte(info), 0o644))
+
+	props := make(map[string]string)
+	originProperties(props, &ModuleInfo{Path: "github.com/BurntSushi/toml", Version: "v1.3.2"})
+	assert.Equal(t, map[string]string{
+		"origin.vcs":  "git",
+		"origin.url":  "https://github.com/BurntSushi/toml",
+		"origin.hash": "a1a6b7a8d2d5a2a4b8e5a5d0b3c8b6b6d7a5f3e1",
+		"origin.ref":  "refs/tags/v1.3.2",
+	}, props)
+
+	// Replacements come from the module replacing them, and pseudo-versions
+	// name their commit even when the cache knows nothing about them
+	props = make(map[string]string)
+	originProperties(props, &ModuleInfo{
+		Path:    "github.com/original/toml",
+		Version: "v1.0.0",
+		Replace: &ModuleInfo{Path: "github.com/fork/toml", Version: "v0.0.0-20240101120000-abcdefabcdef"},
+	})
+	assert.Equal(t, map[string]string{"origin.revision": "abcdefabcdef"}, props)
+
+	// go list output wins over the cache
+	origin := &Origin{VCS: "git", URL: "https://example.com/repo", Hash: "0123"}
+	assert.Equal(t, origin, moduleOrigin(&ModuleInfo{Path: "github.com/BurntSushi/toml", Version: "v1.3.2", Origin: origin}))
+	assert.Nil(t, moduleOrigin(&ModuleInfo{Path: "example.com/local", Replace: &ModuleInfo{Path: "../local"}}))
+}
+
+func TestGoScanner_PartialResult(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not supported on windows")
+	}
+	// A go tool whose module graph fails after listing the build list
+	bin := t.TempDir()
+	script := `#!/bin/sh
+case "$1" in
+list)
+	echo '{"Path": "example.com/app", "Main": true}'
+	echo '{"Path": "github.com/pkg/errors", "Version": "v0.9.1"}'
+	;;
+mod)
+	echo 'go: github.com/pkg/errors@v0.9.1: missing go.sum entry' >&2
+	exit 1
+	;;
+esac
+`
+	if !assert.NoError(t, os.WriteFile(filepath.Join(bin, "go"), []byte(script), 0755)) {
+		return
+	}
+	t.Setenv("PATH", bin)
+	t.Setenv("GOMODCACHE", t.TempDir())
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.21\n\nrequire github.com/pkg/errors v0.9.1\n"), 0644)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	result, err := NewScanner().ScanDependencies(context.Background(), dir, scanners.DefaultScanOptions())
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, result.Dependencies, 1) {
+		assert.Equal(t, "github.com/pkg/errors", result.Dependencies[0].Name)
+		assert.True(t, result.Dependencies[0].IsDirectDep)
+	}
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, "go mod graph", result.Errors[0].Step)
+		assert.Contains(t, result.Errors[0].Message, "missing go.sum entry")
+	}
+}
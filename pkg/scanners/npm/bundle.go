@@ -0,0 +1,50 @@
+package npm
+
+import "encoding/json"
+
+// bundleField is the bundledDependencies field of package.json, a list of
+// dependency names or true to bundle every dependency
+type bundleField struct {
+	all   bool
+	names []string
+}
+
+func (b *bundleField) UnmarshalJSON(data []byte) error {
+	if json.Unmarshal(data, &b.all) == nil {
+		return nil
+	}
+	// Malformed bundle lists should not fail the scan
+	_ = json.Unmarshal(data, &b.names)
+	return nil
+}
+
+// PeerMeta is an entry of the peerDependenciesMeta field of package.json and
+// of lockfile packages
+type PeerMeta struct {
+	Optional bool `json:"optional"`
+}
+
+// bundles reports whether the project bundles a direct dependency into its
+// published tarball. npm reads both spellings of the field.
+func bundles(pkg *PackageJSON, name string) bool {
+	for _, field := range []bundleField{pkg.BundledDependencies, pkg.BundleDependencies} {
+		if field.all {
+			if _, ok := pkg.Dependencies[name]; ok {
+				return true
+			}
+		}
+		for _, bundled := range field.names {
+			if bundled == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// optionalPeer reports whether a peer dependency of the project is marked
+// optional, so npm does not install it unless something else requires it
+func optionalPeer(pkg *PackageJSON, name string) bool {
+	_, ok := pkg.PeerDependencies[name]
+	return ok && pkg.PeerDependenciesMeta[name].Optional
+}
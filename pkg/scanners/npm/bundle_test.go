@@ -0,0 +1,117 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNPMScanner_Bundled(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json": {Data: []byte(`{
+			"name": "test-project",
+			"dependencies": {"tar": "^6.0.0", "debug": "^4.0.0"},
+			"devDependencies": {"react": "^18.0.0"},
+			"peerDependencies": {"react": "^17 || ^18", "react-dom": "^18.0.0"},
+			"peerDependenciesMeta": {"react": {"optional": true}, "react-dom": {"optional": true}},
+			"bundleDependencies": ["tar"]
+		}`)},
+		"package-lock.json": {Data: []byte(`{
+			"name": "test-project",
+			"lockfileVersion": 3,
+			"packages": {
+				"": {"name": "test-project"},
+				"node_modules/tar": {"version": "6.2.0", "dependencies": {"minipass": "^5.0.0"}},
+				"node_modules/tar/node_modules/minipass": {"version": "5.0.0", "inBundle": true},
+				"node_modules/debug": {"version": "4.3.4"},
+				"node_modules/react": {"version": "18.2.0", "dev": true},
+				"node_modules/react-dom": {"version": "18.2.0", "peer": true}
+			}
+		}`)},
+	}
+
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, scanners.DefaultScanOptions())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tar, _ := result.Graph.Node("tar", "6.2.0")
+	assert.Equal(t, "true", tar.Properties["bundled"])
+	minipass, _ := result.Graph.Node("minipass", "5.0.0")
+	assert.Equal(t, "true", minipass.Properties["bundled"])
+	debug, _ := result.Graph.Node("debug", "4.3.4")
+	assert.NotContains(t, debug.Properties, "bundled")
+
+	// An optional peer the project installs for development is not shipped
+	react, ok := result.Graph.Node("react", "18.2.0")
+	if assert.True(t, ok) {
+		assert.Equal(t, "development", react.Properties["dependencyType"])
+	}
+
+	reactDOM, ok := result.Graph.Node("react-dom", "18.2.0")
+	if assert.True(t, ok) {
+		assert.Equal(t, "peer", reactDOM.Properties["dependencyType"])
+		assert.Equal(t, "true", reactDOM.Properties["optional"])
+	}
+
+	opts := scanners.DefaultScanOptions()
+	opts.IncludeDev = false
+	result, err = NewScanner().ScanDependenciesFS(context.Background(), fsys, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, ok = result.Graph.Node("react", "18.2.0")
+	assert.False(t, ok)
+}
+
+func TestNPMScanner_BundledV1(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json": {Data: []byte(`{"name": "test-project", "dependencies": {"tar": "^6.0.0"}}`)},
+		"package-lock.json": {Data: []byte(`{
+			"name": "test-project",
+			"lockfileVersion": 1,
+			"dependencies": {
+				"tar": {"version": "6.2.0", "requires": {"minipass": "^5.0.0"}, "dependencies": {
+					"minipass": {"version": "5.0.0", "bundled": true}
+				}}
+			}
+		}`)},
+	}
+
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, scanners.DefaultScanOptions())
+	if !assert.NoError(t, err) {
+		return
+	}
+	minipass, ok := result.Graph.Node("minipass", "5.0.0")
+	if assert.True(t, ok) {
+		assert.Equal(t, "true", minipass.Properties["bundled"])
+	}
+}
+
+func TestBundles(t *testing.T) {
+	var pkg PackageJSON
+	if !assert.NoError(t, json.Unmarshal([]byte(`{"dependencies": {"a": "1", "b": "1"}, "bundledDependencies": true}`), &pkg)) {
+		return
+	}
+	assert.True(t, bundles(&pkg, "a"))
+	assert.True(t, bundles(&pkg, "b"))
+	assert.False(t, bundles(&pkg, "c"))
+
+	pkg = PackageJSON{}
+	if !assert.NoError(t, json.Unmarshal([]byte(`{"bundleDependencies": ["a"], "peerDependencies": {"b": "1"}}`), &pkg)) {
+		return
+	}
+	assert.True(t, bundles(&pkg, "a"))
+	assert.False(t, bundles(&pkg, "b"))
+	assert.False(t, optionalPeer(&pkg, "b"))
+
+	// Malformed fields are ignored
+	pkg = PackageJSON{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"bundledDependencies": "a"}`), &pkg))
+	assert.False(t, bundles(&pkg, "a"))
+}
@@ -0,0 +1,61 @@
+package npm
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// lockfilePackages returns the packages of a lockfile keyed by install path,
+// as in the packages map of lockfileVersion 2 and 3. Version 1 lockfiles,
+// and version 2 ones written without the map, nest their dependencies
+// instead, which are flattened to the same form. Lockfiles without a
+// version are read by their content.
+func lockfilePackages(lock *PackageLock) (map[string]PackageDep, error) {
+	switch lock.LockfileVersion {
+	case 0, 2, 3:
+		if len(lock.Packages) > 0 {
+			return lock.Packages, nil
+		}
+	case 1:
+	default:
+		return nil, fmt.Errorf("%w: unsupported lockfileVersion %d", scanners.ErrInvalidProject, lock.LockfileVersion)
+	}
+
+	packages := map[string]PackageDep{"": {Name: lock.Name}}
+	flattenDependencies(lock.Dependencies, "", packages)
+	return packages, nil
+}
+
+// flattenDependencies adds the nested dependencies of a version 1 lockfile,
+// installed in the node_modules of dir, to packages. Their version field
+// holds the specifier of aliases, such as npm:lodash@4.17.21, and of git and
+// file dependencies, which are turned into the name and resolved fields of
+// newer lockfiles.
+func flattenDependencies(dependencies map[string]LockDep, dir string, packages map[string]PackageDep) {
+	for name, dep := range dependencies {
+		pkgPath := path.Join(dir, "node_modules", name)
+		entry := PackageDep{
+			Version:      dep.Version,
+			License:      dep.License,
+			Resolved:     dep.Resolved,
+			Integrity:    dep.Integrity,
+			Dependencies: dep.Requires,
+			Dev:          dep.Dev,
+			Optional:     dep.Optional,
+			Peer:         dep.Peer,
+			InBundle:     dep.Bundled,
+		}
+		if alias, ok := strings.CutPrefix(dep.Version, "npm:"); ok {
+			if i := strings.LastIndex(alias, "@"); i > 0 {
+				entry.Name, entry.Version = alias[:i], alias[i+1:]
+			}
+		} else if strings.Contains(dep.Version, ":") {
+			entry.Resolved, entry.Version = dep.Version, ""
+		}
+		packages[pkgPath] = entry
+		flattenDependencies(dep.Dependencies, pkgPath, packages)
+	}
+}
@@ -0,0 +1,87 @@
+package npm
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNPMScanner_LockfileVersion1(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json": {Data: []byte(`{"name": "test-project", "dependencies": {"express": "^4.16.0", "debug": "^4.1.0", "my-ms": "npm:ms@^2.1.0", "left-pad": "github:stevemao/left-pad"}}`)},
+		"package-lock.json": {Data: []byte(`{
+			"name": "test-project",
+			"lockfileVersion": 1,
+			"requires": true,
+			"dependencies": {
+				"express": {
+					"version": "4.16.4",
+					"resolved": "https://registry.npmjs.org/express/-/express-4.16.4.tgz",
+					"integrity": "sha512-express",
+					"requires": {"debug": "2.6.9"},
+					"dependencies": {
+						"debug": {"version": "2.6.9", "requires": {"ms": "2.0.0"}},
+						"ms": {"version": "2.0.0"}
+					}
+				},
+				"debug": {"version": "4.1.1", "requires": {"ms": "^2.1.1"}},
+				"ms": {"version": "2.1.1"},
+				"my-ms": {"version": "npm:ms@2.1.3"},
+				"left-pad": {"version": "git+https://github.com/stevemao/left-pad.git#5f7f6a2c7b0e4d2a0e8f3f1a5a2b1c3d4e5f6a7b"}
+			}
+		}`)},
+	}
+
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, scanners.DefaultScanOptions())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]string{"lockfileVersion": "1"}, result.Metadata)
+	assert.Len(t, result.Dependencies, 7)
+
+	// Nested copies are resolved from the package requiring them
+	assert.Equal(t, []string{"debug@2.6.9"}, result.Graph.Edges["express@4.16.4"])
+	assert.Equal(t, []string{"ms@2.0.0"}, result.Graph.Edges["debug@2.6.9"])
+	assert.Equal(t, []string{"ms@2.1.1"}, result.Graph.Edges["debug@4.1.1"])
+
+	express, _ := result.Graph.Node("express", "4.16.4")
+	assert.Equal(t, "sha512-express", express.Properties["integrity"])
+	assert.True(t, express.IsDirectDep)
+
+	aliased, ok := result.Graph.Node("ms", "2.1.3")
+	if assert.True(t, ok) {
+		assert.Equal(t, "my-ms", aliased.Properties["alias"])
+	}
+	leftPad, ok := result.Graph.Node("left-pad", "")
+	if assert.True(t, ok) {
+		assert.Equal(t, "git", leftPad.Properties["source"])
+		assert.Equal(t, "5f7f6a2c7b0e4d2a0e8f3f1a5a2b1c3d4e5f6a7b", leftPad.Properties["origin.hash"])
+	}
+}
+
+func TestLockfilePackages(t *testing.T) {
+	// Version 2 lockfiles keep the tree of version 1 for older npm releases
+	lock := &PackageLock{
+		LockfileVersion: 2,
+		Packages:        map[string]PackageDep{"": {}, "node_modules/a": {Version: "2.0.0"}},
+		Dependencies:    map[string]LockDep{"a": {Version: "1.0.0"}},
+	}
+	packages, err := lockfilePackages(lock)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "2.0.0", packages["node_modules/a"].Version)
+	}
+
+	lock.Packages = nil
+	packages, err = lockfilePackages(lock)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1.0.0", packages["node_modules/a"].Version)
+	}
+
+	_, err = lockfilePackages(&PackageLock{LockfileVersion: 4})
+	assert.ErrorIs(t, err, scanners.ErrInvalidProject)
+	assert.ErrorContains(t, err, "unsupported lockfileVersion 4")
+}
@@ -0,0 +1,125 @@
+package npm
+
+import (
+	"encoding/json"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// readNodeModules reconstructs a lockfile from the package.json of every
+// package installed in node_modules, for projects installed without one or
+// with Yarn. Packages only the devDependencies of the project lead to are
+// marked dev, as npm would. It fails with fs.ErrNotExist when nothing is
+// installed.
+func readNodeModules(fsys fs.FS, root *PackageJSON) (*PackageLock, error) {
+	if _, err := fs.Stat(fsys, "node_modules"); err != nil {
+		return nil, err
+	}
+
+	lock := &PackageLock{
+		Name:     root.Name,
+		Packages: map[string]PackageDep{"": {Name: root.Name}},
+	}
+	var walk func(dir string)
+	walk = func(dir string) {
+		modules := path.Join(dir, "node_modules")
+		entries, err := fs.ReadDir(fsys, modules)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			// .bin, .package-lock.json and the like
+			if strings.HasPrefix(name, ".") {
+				continue
+			}
+			dirs := []fs.DirEntry{entry}
+			if strings.HasPrefix(name, "@") {
+				if dirs, err = fs.ReadDir(fsys, path.Join(modules, name)); err != nil {
+					continue
+				}
+				for i := range dirs {
+					dirs[i] = scopedEntry{dirs[i], name}
+				}
+			}
+
+			for _, pkgEntry := range dirs {
+				pkgPath := path.Join(modules, pkgEntry.Name())
+				content, err := fs.ReadFile(fsys, path.Join(pkgPath, "package.json"))
+				if err != nil {
+					continue
+				}
+				var installed installedPackage
+				if json.Unmarshal(content, &installed) != nil {
+					continue
+				}
+
+				dependencies := make(map[string]string, len(installed.Dependencies)+len(installed.OptionalDependencies))
+				for _, deps := range []map[string]string{installed.Dependencies, installed.OptionalDependencies} {
+					for depName, spec := range deps {
+						dependencies[depName] = spec
+					}
+				}
+				lock.Packages[pkgPath] = PackageDep{
+					Name:         installed.Name,
+					Version:      installed.Version,
+					License:      licenseField(installed.license()),
+					Dependencies: dependencies,
+					Engines:      installed.Engines,
+					OS:           installed.OS,
+					CPU:          installed.CPU,
+					Libc:         installed.Libc,
+				}
+				// Links, as to workspace members, may lead back up the tree
+				if pkgEntry.Type()&fs.ModeSymlink == 0 {
+					walk(pkgPath)
+				}
+			}
+		}
+	}
+	walk("")
+
+	// Everything the project's other dependencies lead to is needed at runtime
+	type require struct{ from, name string }
+	var queue []require
+	for i, deps := range []map[string]string{root.Dependencies, root.OptionalDependencies, root.PeerDependencies} {
+		for depName := range deps {
+			// Optional peers are left to the project's dependents to install
+			if i == 2 && optionalPeer(root, depName) {
+				continue
+			}
+			queue = append(queue, require{"", depName})
+		}
+	}
+	needed := make(map[string]bool)
+	for len(queue) > 0 {
+		req := queue[0]
+		queue = queue[1:]
+		pkgPath, ok := ResolvePackage(lock.Packages, req.from, req.name)
+		if !ok || needed[pkgPath] {
+			continue
+		}
+		needed[pkgPath] = true
+		for depName := range lock.Packages[pkgPath].Dependencies {
+			queue = append(queue, require{pkgPath, depName})
+		}
+	}
+	for pkgPath, dep := range lock.Packages {
+		if pkgPath != "" && !needed[pkgPath] {
+			dep.Dev = true
+			lock.Packages[pkgPath] = dep
+		}
+	}
+	return lock, nil
+}
+
+// scopedEntry names a package of a scope directory by its full name
+type scopedEntry struct {
+	fs.DirEntry
+	scope string
+}
+
+func (e scopedEntry) Name() string {
+	return e.scope + "/" + e.DirEntry.Name()
+}
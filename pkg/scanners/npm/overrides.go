@@ -0,0 +1,198 @@
+package npm
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/version"
+)
+
+// selector matches a package by name and, optionally, version range, as in
+// the keys of npm overrides such as "lodash" or "react@^17"
+type selector struct {
+	name       string
+	constraint string
+}
+
+// override forces the version of the packages matching its last selector
+// that are installed below packages matching the others, in order
+type override struct {
+	selectors []selector
+	spec      string // Version or range forced, or $name for the root's own
+}
+
+// parseSelector splits an override key into package name and range
+func parseSelector(key string) selector {
+	// Scoped names start with @
+	if i := strings.LastIndex(key, "@"); i > 0 {
+		return selector{name: key[:i], constraint: key[i+1:]}
+	}
+	return selector{name: key}
+}
+
+// parseOverrides flattens the npm overrides field, whose objects override
+// the dependencies of a package, with "." overriding the package itself
+func parseOverrides(raw json.RawMessage, parents []selector) []override {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(raw, &fields) != nil {
+		return nil
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var overrides []override
+	for _, key := range keys {
+		var spec string
+		if key == "." {
+			if json.Unmarshal(fields[key], &spec) == nil && len(parents) > 0 {
+				overrides = append(overrides, override{selectors: parents, spec: spec})
+			}
+			continue
+		}
+		selectors := append(slices.Clone(parents), parseSelector(key))
+		if json.Unmarshal(fields[key], &spec) == nil {
+			overrides = append(overrides, override{selectors: selectors, spec: spec})
+			continue
+		}
+		overrides = append(overrides, parseOverrides(fields[key], selectors)...)
+	}
+	return overrides
+}
+
+// parseResolutions reads the Yarn resolutions field, whose keys are package
+// paths such as "lodash", "**/lodash" or "webpack/**/lodash". Globs are
+// dropped, as selectors already match at any depth.
+func parseResolutions(resolutions map[string]string) []override {
+	keys := make([]string, 0, len(resolutions))
+	for key := range resolutions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var overrides []override
+	for _, key := range keys {
+		var selectors []selector
+		segments := strings.Split(key, "/")
+		for i := 0; i < len(segments); i++ {
+			segment := segments[i]
+			if segment == "**" || segment == "*" || segment == "" {
+				continue
+			}
+			if strings.HasPrefix(segment, "@") && i+1 < len(segments) {
+				i++
+				segment += "/" + segments[i]
+			}
+			selectors = append(selectors, parseSelector(segment))
+		}
+		if len(selectors) > 0 {
+			overrides = append(overrides, override{selectors: selectors, spec: resolutions[key]})
+		}
+	}
+	return overrides
+}
+
+// readOverrides returns the overrides and resolutions package.json declares
+func readOverrides(pkg *PackageJSON) []override {
+	overrides := parseOverrides(pkg.Overrides, nil)
+	return append(overrides, parseResolutions(pkg.Resolutions)...)
+}
+
+// matches reports whether a package satisfies the selector
+func (sel selector) matches(name, v string) bool {
+	if sel.name != name {
+		return false
+	}
+	if sel.constraint == "" {
+		return true
+	}
+	constraint, err := version.ParseConstraint(sel.constraint)
+	return err == nil && constraint.Check(v)
+}
+
+// findOverride returns the most specific override in effect for a package,
+// that is the one with the most selectors among those whose forced version
+// was installed and whose other selectors match ancestors of the package, in
+// order. It also returns the node key of the parent the package was
+// installed for, below those ancestors.
+func (s *NPMScanner) findOverride(overrides []override, pkg *PackageJSON, graph *dependencyGraph, resolved *scanners.DependencyGraph, key string, paths []scanners.DependencyPath) (*override, string) {
+	name, v := graph.names[key], graph.versions[key]
+	var found *override
+	var parent string
+	for i := range overrides {
+		o := &overrides[i]
+		if !o.selectors[len(o.selectors)-1].matches(name, v) || (found != nil && len(found.selectors) >= len(o.selectors)) {
+			continue
+		}
+		spec := o.spec
+		if ref, ok := strings.CutPrefix(spec, "$"); ok {
+			spec = s.getSpecifier(pkg, ref)
+		}
+		if constraint, err := version.ParseConstraint(spec); err != nil || !constraint.Check(v) {
+			continue
+		}
+
+		ancestors := o.selectors[:len(o.selectors)-1]
+		if len(ancestors) == 0 && len(paths) > 0 && len(paths[0].Path) > 1 {
+			found, parent = o, paths[0].Path[len(paths[0].Path)-2]
+			continue
+		}
+		for _, candidate := range resolved.Parents(key) {
+			if hasAncestors(ancestors, graph, resolved, candidate) {
+				found, parent = o, candidate
+				break
+			}
+		}
+	}
+	return found, parent
+}
+
+// hasAncestors reports whether packages matching the selectors lead to the
+// package at key, the last selector matching it or one of its ancestors
+func hasAncestors(selectors []selector, graph *dependencyGraph, resolved *scanners.DependencyGraph, key string) bool {
+	type state struct {
+		key       string
+		remaining int
+	}
+	visited := make(map[state]bool)
+	queue := []state{{key, len(selectors)}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current.remaining == 0 {
+			return true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+
+		if selectors[current.remaining-1].matches(graph.names[current.key], graph.versions[current.key]) {
+			current.remaining--
+			if current.remaining == 0 {
+				return true
+			}
+		}
+		for _, parent := range resolved.Parents(current.key) {
+			queue = append(queue, state{parent, current.remaining})
+		}
+	}
+	return false
+}
+
+// declaredRange returns the range the package at parent declares for a
+// dependency, from package.json for the project itself
+func (s *NPMScanner) declaredRange(pkg *PackageJSON, graph *dependencyGraph, parent, name string) string {
+	if parent == "" {
+		return s.getSpecifier(pkg, name)
+	}
+	if node, ok := graph.nodes[parent]; ok {
+		return node.Dependencies[name]
+	}
+	return ""
+}
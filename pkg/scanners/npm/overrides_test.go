@@ -0,0 +1,79 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOverrides(t *testing.T) {
+	raw := json.RawMessage(`{
+		"lodash": "4.17.21",
+		"@babel/core@^7": {".": "7.24.0", "semver": "6.3.1"},
+		"express": {"body-parser": {"qs": "$qs"}}
+	}`)
+	assert.Equal(t, []override{
+		{selectors: []selector{{name: "@babel/core", constraint: "^7"}}, spec: "7.24.0"},
+		{selectors: []selector{{name: "@babel/core", constraint: "^7"}, {name: "semver"}}, spec: "6.3.1"},
+		{selectors: []selector{{name: "express"}, {name: "body-parser"}, {name: "qs"}}, spec: "$qs"},
+		{selectors: []selector{{name: "lodash"}}, spec: "4.17.21"},
+	}, parseOverrides(raw, nil))
+	assert.Nil(t, parseOverrides(nil, nil))
+
+	assert.Equal(t, []override{
+		{selectors: []selector{{name: "lodash"}}, spec: "4.17.21"},
+		{selectors: []selector{{name: "@types/react"}}, spec: "18.2.0"},
+		{selectors: []selector{{name: "webpack"}, {name: "@scope/pkg", constraint: "1"}}, spec: "1.2.0"},
+	}, parseResolutions(map[string]string{
+		"**/lodash":               "4.17.21",
+		"@types/react":            "18.2.0",
+		"webpack/**/@scope/pkg@1": "1.2.0",
+	}))
+}
+
+func TestNPMScanner_Overrides(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json": {Data: []byte(`{
+			"name": "test-project",
+			"dependencies": {"express": "^4.18.0", "qs": "^6.12.0", "lodash": "^4.17.0"},
+			"overrides": {"express": {"qs": "$qs"}, "semver": "7.6.0"},
+			"resolutions": {"**/lodash": "4.17.21"}
+		}`)},
+		"package-lock.json": {Data: []byte(`{
+			"name": "test-project",
+			"lockfileVersion": 3,
+			"packages": {
+				"": {"name": "test-project"},
+				"node_modules/express": {"version": "4.18.2", "dependencies": {"qs": "6.11.0", "semver": "^5.0.0"}},
+				"node_modules/qs": {"version": "6.12.1"},
+				"node_modules/semver": {"version": "5.7.2"},
+				"node_modules/lodash": {"version": "4.17.21"}
+			}
+		}`)},
+	}
+
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, scanners.DefaultScanOptions())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	qs, _ := result.Graph.Node("qs", "6.12.1")
+	assert.Equal(t, "$qs", qs.Properties["overridden_by"])
+	assert.Equal(t, "6.11.0", qs.Properties["overridden_from"])
+
+	lodash, _ := result.Graph.Node("lodash", "4.17.21")
+	assert.Equal(t, "4.17.21", lodash.Properties["overridden_by"])
+	assert.Equal(t, "^4.17.0", lodash.Properties["overridden_from"])
+
+	// The lockfile predates the override, so it is not in effect
+	semver, _ := result.Graph.Node("semver", "5.7.2")
+	assert.NotContains(t, semver.Properties, "overridden_by")
+
+	express, _ := result.Graph.Node("express", "4.18.2")
+	assert.NotContains(t, express.Properties, "overridden_by")
+}
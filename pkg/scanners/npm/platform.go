@@ -0,0 +1,66 @@
+package npm
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// enginesField is the engines field of a package, the versions of Node.js
+// and npm it supports by engine name. Old packages give it as an array of
+// strings such as "node >=0.6".
+type enginesField map[string]string
+
+func (e *enginesField) UnmarshalJSON(data []byte) error {
+	var engines map[string]string
+	if json.Unmarshal(data, &engines) == nil {
+		*e = engines
+		return nil
+	}
+	var list []string
+	if json.Unmarshal(data, &list) != nil {
+		// Malformed engines should not fail the scan
+		return nil
+	}
+	engines = make(map[string]string)
+	for _, entry := range list {
+		name, constraint, _ := strings.Cut(strings.TrimSpace(entry), " ")
+		if name != "" {
+			engines[name] = strings.TrimSpace(constraint)
+		}
+	}
+	*e = engines
+	return nil
+}
+
+// stringList is a package field listing strings, such as os or cpu, which
+// some packages give as a single string
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var list []string
+	if json.Unmarshal(data, &list) == nil {
+		*l = list
+		return nil
+	}
+	var value string
+	if json.Unmarshal(data, &value) == nil && value != "" {
+		*l = stringList{value}
+	}
+	return nil
+}
+
+// platformProperties records the engines, operating systems, CPU
+// architectures and C libraries a package supports, as engines.<name>, os,
+// cpu and libc properties
+func platformProperties(props map[string]string, dep PackageDep) {
+	for name, constraint := range dep.Engines {
+		if constraint != "" {
+			props["engines."+name] = constraint
+		}
+	}
+	for key, values := range map[string]stringList{"os": dep.OS, "cpu": dep.CPU, "libc": dep.Libc} {
+		if len(values) > 0 {
+			props[key] = strings.Join(values, ",")
+		}
+	}
+}
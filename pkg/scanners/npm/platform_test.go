@@ -0,0 +1,58 @@
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNPMScanner_Platform(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json": {Data: []byte(`{"name": "test-project", "dependencies": {"vite": "^5.0.0"}}`)},
+		"package-lock.json": {Data: []byte(`{
+			"name": "test-project",
+			"lockfileVersion": 3,
+			"packages": {
+				"": {"name": "test-project"},
+				"node_modules/vite": {"version": "5.0.0", "engines": {"node": "^18.0.0 || >=20.0.0"}, "optionalDependencies": {"fsevents": "~2.3.3"}},
+				"node_modules/fsevents": {"version": "2.3.3", "optional": true, "os": ["darwin"], "engines": {"node": "^8.16.0 || ^10.6.0 || >=11.0.0"}},
+				"node_modules/@esbuild/linux-x64": {"version": "0.19.0", "optional": true, "os": ["linux"], "cpu": ["x64"], "libc": ["glibc"]}
+			}
+		}`)},
+	}
+
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, scanners.DefaultScanOptions())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	vite, _ := result.Graph.Node("vite", "5.0.0")
+	assert.Equal(t, "^18.0.0 || >=20.0.0", vite.Properties["engines.node"])
+	assert.NotContains(t, vite.Properties, "os")
+
+	esbuild, ok := result.Graph.Node("@esbuild/linux-x64", "0.19.0")
+	if assert.True(t, ok) {
+		assert.Equal(t, "linux", esbuild.Properties["os"])
+		assert.Equal(t, "x64", esbuild.Properties["cpu"])
+		assert.Equal(t, "glibc", esbuild.Properties["libc"])
+	}
+}
+
+func TestPlatformFields(t *testing.T) {
+	var pkg installedPackage
+	if !assert.NoError(t, json.Unmarshal([]byte(`{"engines": ["node >=0.6", "npm"], "os": "linux", "cpu": 1}`), &pkg)) {
+		return
+	}
+	assert.Equal(t, enginesField{"node": ">=0.6", "npm": ""}, pkg.Engines)
+	assert.Equal(t, stringList{"linux"}, pkg.OS)
+	assert.Empty(t, pkg.CPU)
+
+	props := make(map[string]string)
+	platformProperties(props, PackageDep{Engines: pkg.Engines, OS: stringList{"darwin", "!win32"}})
+	assert.Equal(t, map[string]string{"engines.node": ">=0.6", "os": "darwin,!win32"}, props)
+}
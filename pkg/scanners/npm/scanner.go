@@ -3,11 +3,23 @@ package npm
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/santoshdahal12/deplister/pkg/license"
+	"github.com/santoshdahal12/deplister/pkg/lifecycle"
+	"github.com/santoshdahal12/deplister/pkg/npmrc"
 	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/tracing"
 )
 
 type NPMScanner struct {
@@ -15,53 +27,93 @@ type NPMScanner struct {
 }
 
 type PackageJSON struct {
-	Name                 string            `json:"name"`
-	Dependencies         map[string]string `json:"dependencies"`
-	DevDependencies      map[string]string `json:"devDependencies"`
-	PeerDependencies     map[string]string `json:"peerDependencies"`
-	OptionalDependencies map[string]string `json:"optionalDependencies"`
-	Workspaces           []string          `json:"workspaces"`
+	Name                 string              `json:"name"`
+	Version              string              `json:"version"`
+	Dependencies         map[string]string   `json:"dependencies"`
+	DevDependencies      map[string]string   `json:"devDependencies"`
+	PeerDependencies     map[string]string   `json:"peerDependencies"`
+	PeerDependenciesMeta map[string]PeerMeta `json:"peerDependenciesMeta"`
+	OptionalDependencies map[string]string   `json:"optionalDependencies"`
+	BundledDependencies  bundleField         `json:"bundledDependencies"`
+	BundleDependencies   bundleField         `json:"bundleDependencies"`
+	Workspaces           []string            `json:"workspaces"`
+	Overrides            json.RawMessage     `json:"overrides"`
+	Resolutions          map[string]string   `json:"resolutions"`
 }
 
 type PackageLock struct {
-	Name         string                `json:"name"`
-	Dependencies map[string]LockDep    `json:"dependencies"`
-	Packages     map[string]PackageDep `json:"packages"`
+	Name            string                `json:"name"`
+	LockfileVersion int                   `json:"lockfileVersion"`
+	Dependencies    map[string]LockDep    `json:"dependencies"`
+	Packages        map[string]PackageDep `json:"packages"`
 }
 
 type LockDep struct {
 	Version   string            `json:"version"`
+	License   licenseField      `json:"license"`
 	Resolved  string            `json:"resolved"`
 	Integrity string            `json:"integrity"`
 	Requires  map[string]string `json:"requires"`
 	Dev       bool              `json:"dev"`
 	Optional  bool              `json:"optional"`
 	Peer      bool              `json:"peer"`
+	Bundled   bool              `json:"bundled"`
+
+	Dependencies map[string]LockDep `json:"dependencies"` // Copies nested in this package's node_modules
 }
 
 type PackageDep struct {
+	Name         string            `json:"name"` // Real name of aliased packages and workspace members
 	Version      string            `json:"version"`
+	License      licenseField      `json:"license"`
 	Resolved     string            `json:"resolved"`
 	Integrity    string            `json:"integrity"`
 	Dependencies map[string]string `json:"dependencies"`
-	Dev          bool              `json:"dev"`
-	Optional     bool              `json:"optional"`
-	Peer         bool              `json:"peer"`
+
+	DevDependencies      map[string]string   `json:"devDependencies"` // Declared by workspace members
+	OptionalDependencies map[string]string   `json:"optionalDependencies"`
+	PeerDependencies     map[string]string   `json:"peerDependencies"`
+	PeerDependenciesMeta map[string]PeerMeta `json:"peerDependenciesMeta"`
+
+	Dev      bool         `json:"dev"`
+	Optional bool         `json:"optional"`
+	Peer     bool         `json:"peer"`
+	Link     bool         `json:"link"`
+	InBundle bool         `json:"inBundle"` // Shipped inside the tarball of the package above it
+	Engines  enginesField `json:"engines"`
+	OS       stringList   `json:"os"`
+	CPU      stringList   `json:"cpu"`
+	Libc     stringList   `json:"libc"`
+
+	HasInstallScript bool `json:"hasInstallScript"`
 }
 
+// dependencyGraph holds the packages of a lockfile keyed by scanners.NodeKey
 type dependencyGraph struct {
 	nodes    map[string]*PackageDep
 	edges    map[string][]string
+	names    map[string]string
 	versions map[string]string
+	licenses map[string]string
 	metadata map[string]map[string]string
+	dirs     map[string]string // Install directory, e.g. node_modules/send/node_modules/debug
 }
 
 func newDependencyGraph() *dependencyGraph {
 	return &dependencyGraph{
 		nodes:    make(map[string]*PackageDep),
 		edges:    make(map[string][]string),
+		names:    make(map[string]string),
 		versions: make(map[string]string),
+		licenses: make(map[string]string),
 		metadata: make(map[string]map[string]string),
+		dirs:     make(map[string]string),
+	}
+}
+
+func init() {
+	if err := scanners.Register(NewScanner(), 10); err != nil {
+		panic(err)
 	}
 }
 
@@ -72,78 +124,182 @@ func NewScanner() *NPMScanner {
 }
 
 func (s *NPMScanner) DetectProject(ctx context.Context, dir string) bool {
-	_, err := os.Stat(filepath.Join(dir, "package.json"))
+	return s.DetectProjectFS(ctx, os.DirFS(dir))
+}
+
+func (s *NPMScanner) DetectProjectFS(ctx context.Context, fsys fs.FS) bool {
+	_, err := fs.Stat(fsys, "package.json")
 	return err == nil
 }
 
-func (s *NPMScanner) ScanDependencies(ctx context.Context, dir string) (*scanners.ScanResult, error) {
-	if !s.DetectProject(ctx, dir) {
+// CacheFiles returns the manifest, the lockfile, the files npm and Yarn
+// update whenever node_modules, where licenses and install scripts are read
+// and which stands in for a missing lockfile, changes, and the .npmrc naming
+// the registries packages come from
+func (s *NPMScanner) CacheFiles() []string {
+	return []string{"package.json", "package-lock.json", "node_modules/.package-lock.json", "node_modules/.yarn-integrity", npmrc.File}
+}
+
+func (s *NPMScanner) ScanDependencies(ctx context.Context, dir string, opts scanners.ScanOptions) (*scanners.ScanResult, error) {
+	return s.ScanDependenciesFS(ctx, os.DirFS(dir), opts)
+}
+
+func (s *NPMScanner) ScanDependenciesFS(ctx context.Context, fsys fs.FS, opts scanners.ScanOptions) (*scanners.ScanResult, error) {
+	dependencies := make([]scanners.Dependency, 0)
+	nodes := make(map[string]*scanners.Dependency)
+	result, err := s.scan(ctx, fsys, opts, func(key string, dep scanners.Dependency) error {
+		dependencies = append(dependencies, dep)
+		nodes[key] = &dep
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Dependencies = dependencies
+	result.Graph.Nodes = nodes
+	return result, nil
+}
+
+// ScanDependenciesStream passes each dependency to fn once resolved. Only the
+// lockfile graph is held in memory, not the dependencies already passed on.
+func (s *NPMScanner) ScanDependenciesStream(ctx context.Context, dir string, opts scanners.ScanOptions, fn func(scanners.Dependency) error) error {
+	_, err := s.scan(ctx, os.DirFS(dir), opts, func(_ string, dep scanners.Dependency) error {
+		return fn(dep)
+	})
+	return err
+}
+
+// scan resolves the dependencies of the project in fsys, passing each to emit
+// with its node key, and returns a result with the project, its metadata and
+// the graph without nodes, but without the dependencies
+func (s *NPMScanner) scan(ctx context.Context, fsys fs.FS, opts scanners.ScanOptions, emit func(string, scanners.Dependency) error) (*scanners.ScanResult, error) {
+	if !s.DetectProjectFS(ctx, fsys) {
 		return nil, scanners.ErrProjectNotFound
 	}
 
-	pkg, err := s.readPackageJSON(dir)
+	_, span := tracing.Start(ctx, "npm.readManifests")
+	pkg, err := s.readPackageJSON(fsys)
 	if err != nil {
+		tracing.End(span, err)
 		return nil, err
 	}
 
-	lockFile, err := s.readPackageLock(dir)
+	lockFile, err := ReadLockfile(fsys)
+	// Without a lockfile, the installed tree is the best record there is
+	derived := false
+	if errors.Is(err, fs.ErrNotExist) {
+		if installed, walkErr := readNodeModules(fsys, pkg); walkErr == nil {
+			lockFile, err, derived = installed, nil, true
+		}
+	}
+	var packages map[string]PackageDep
+	if err == nil {
+		packages, err = lockfilePackages(lockFile)
+	}
+	tracing.End(span, err)
 	if err != nil {
 		return nil, err
 	}
-
-	graph := s.buildDependencyGraph(pkg, lockFile)
-	if graph == nil {
-		return nil, scanners.ErrInvalidProject
+	// Registries are only recorded, so a broken .npmrc does not fail the scan
+	config, _ := npmrc.Load(fsys, false)
+	metadata := make(map[string]string)
+	if lockFile.LockfileVersion != 0 {
+		metadata["lockfileVersion"] = strconv.Itoa(lockFile.LockfileVersion)
 	}
 
-	result := &scanners.ScanResult{
-		Dependencies: make([]scanners.Dependency, 0),
-		Graph: &scanners.DependencyGraph{
-			Nodes: make(map[string]*scanners.Dependency),
-			Edges: graph.edges,
-		},
-	}
+	_, span = tracing.Start(ctx, "npm.buildDependencyGraph")
+	graph := s.buildDependencyGraph(pkg, packages, opts)
+	span.End()
 
-	directDeps := s.getDirectDependencies(pkg)
+	_, span = tracing.Start(ctx, "npm.buildResult", attribute.Int("deplister.packages", len(graph.nodes)))
+	defer span.End()
+
+	resolved := &scanners.DependencyGraph{Edges: graph.edges}
+	emitted := 0
 
 	// Convert graph to result
-	for name := range graph.nodes {
-		if name == "" {
+	previous := scanners.Reusable(opts.Previous)
+	overrides := readOverrides(pkg)
+	shortest := resolved.ShortestPaths("")
+	for key := range graph.nodes {
+		if key == "" {
 			continue
 		}
+		name := graph.names[key]
 
-		// Calculate all possible paths to this dependency
-		paths := result.Graph.FindAllPaths("", name)
-		minDepth := -1
-		for _, path := range paths {
-			if minDepth == -1 || path.Depth < minDepth {
-				minDepth = path.Depth
-			}
+		paths, minDepth := scanners.Paths(resolved, shortest, "", key, opts)
+		if !opts.WithinDepth(minDepth) {
+			continue
 		}
 
 		// Get all immediate parents
 		var parents []string
-		for parent, children := range graph.edges {
-			for _, child := range children {
-				if child == name && parent != "" {
-					parents = append(parents, parent)
-				}
+		for _, parent := range resolved.Parents(key) {
+			if parent != "" && !slices.Contains(parents, graph.names[parent]) {
+				parents = append(parents, graph.names[parent])
 			}
 		}
 
-		props := graph.metadata[name]
+		props := graph.metadata[key]
 		if props == nil {
 			props = make(map[string]string)
 		}
 		props["manager"] = "npm"
+		if derived {
+			props["derivedFrom"] = "node_modules"
+		}
+		if config != nil {
+			if registry := config.RegistryFor(name); registry != "" {
+				props["registry"] = registry
+			}
+		}
 
-		// Determine if it's a direct dependency
-		_, isDirect := directDeps[name]
+		// Determine if it's a direct dependency, declared by its alias if any
+		isDirect := slices.Contains(graph.edges[""], key)
+		declaredName := name
+		if alias := props["alias"]; alias != "" {
+			declaredName = alias
+		}
+		if specifier := s.getSpecifier(pkg, declaredName); isDirect && specifier != "" {
+			props["specifier"] = specifier
+		}
+
+		// Versions forced by overrides or resolutions, like Go replacements
+		if forced, parent := s.findOverride(overrides, pkg, graph, resolved, key, paths); forced != nil {
+			props["overridden_by"] = forced.spec
+			if declared := s.declaredRange(pkg, graph, parent, name); declared != "" {
+				props["overridden_from"] = declared
+			}
+		}
+
+		license := graph.licenses[key]
+		var installed *installedPackage
+		if prev, ok := previous[key]; ok && reuse(prev, props, opts.IncludeScripts) {
+			if license == "" {
+				license = prev.License
+			}
+		} else {
+			installed = s.readInstalledPackage(fsys, graph.dirs[key])
+		}
+		if license == "" && installed != nil {
+			license = installed.license()
+		}
+		if installed != nil {
+			scripts := lifecycle.Filter(installed.Scripts)
+			if _, ok := scripts["install"]; !ok && installed.hasBindingGyp {
+				if _, ok := scripts["preinstall"]; !ok {
+					// npm builds native addons without install scripts itself
+					scripts["install"] = "node-gyp rebuild"
+				}
+			}
+			lifecycle.Annotate(props, scripts, opts.IncludeScripts)
+		}
 
 		dependency := scanners.Dependency{
 			Name:        name,
-			Version:     graph.versions[name],
+			Version:     graph.versions[key],
 			Type:        "npm",
+			License:     license,
 			IsDirectDep: isDirect,
 			Parent:      "",
 			Parents:     parents,
@@ -156,139 +312,212 @@ func (s *NPMScanner) ScanDependencies(ctx context.Context, dir string) (*scanner
 			dependency.Parent = parents[0]
 		}
 
-		result.Dependencies = append(result.Dependencies, dependency)
-		result.Graph.Nodes[name] = &dependency
+		if err := emit(key, dependency); err != nil {
+			return nil, err
+		}
+		emitted++
 	}
 
-	if len(result.Dependencies) == 0 {
-		return nil, scanners.ErrInvalidProject
+	if emitted == 0 {
+		return nil, fmt.Errorf("%w: no dependencies in the lockfile or node_modules", scanners.ErrInvalidProject)
 	}
 
+	result := &scanners.ScanResult{Graph: resolved, Metadata: metadata}
+	if pkg.Name != "" {
+		result.Project = &scanners.Project{Name: pkg.Name, Version: pkg.Version}
+	}
 	return result, nil
 }
 
-func (s *NPMScanner) buildDependencyGraph(pkg *PackageJSON, lockFile *PackageLock) *dependencyGraph {
+// buildDependencyGraph builds the graph of the packages of a lockfile, keyed
+// by install path
+func (s *NPMScanner) buildDependencyGraph(pkg *PackageJSON, packages map[string]PackageDep, opts scanners.ScanOptions) *dependencyGraph {
 	graph := newDependencyGraph()
 	directDeps := s.getDirectDependencies(pkg)
 
-	// Handle new package-lock format (v3)
-	if len(lockFile.Packages) > 0 {
-		for pkgPath, dep := range lockFile.Packages {
-			// Skip the root package
-			if pkgPath == "" {
-				continue
-			}
-
-			name := pkgPath
-			if filepath.Base(pkgPath) == "node_modules" {
-				continue
-			}
-			name = strings.TrimPrefix(name, "node_modules/")
+	for _, pkgPath := range installOrder(packages) {
+		dep := packages[pkgPath]
+		// Skip the root package
+		if pkgPath == "" {
+			continue
+		}
 
-			graph.nodes[name] = &dep
-			graph.versions[name] = dep.Version
+		if filepath.Base(pkgPath) == "node_modules" {
+			continue
+		}
 
-			// Store metadata
-			metadata := make(map[string]string)
-			if depType, ok := directDeps[name]; ok {
-				metadata["dependencyType"] = depType
-			} else if dep.Dev {
-				metadata["dependencyType"] = "development"
-			} else {
-				metadata["dependencyType"] = "production"
-			}
+		// Workspace members live outside node_modules and are linked into it
+		target := pkgPath
+		if dep.Link {
+			target = dep.Resolved
+		}
+		if !opts.FollowWorkspaces && isWorkspace(pkg, target) {
+			continue
+		}
 
-			if dep.Optional {
-				metadata["optional"] = "true"
-			}
-			if dep.Peer {
-				metadata["peer"] = "true"
-			}
-			if dep.Resolved != "" {
-				metadata["resolved"] = dep.Resolved
-			}
-			if dep.Integrity != "" {
-				metadata["integrity"] = dep.Integrity
-			}
-			graph.metadata[name] = metadata
+		// Only packages installed at the top level are the ones package.json
+		// names, by the alias they are installed as
+		installName := PackageName(pkgPath)
+		depType, isDirect := directDeps[installName]
+		isDirect = isDirect && pkgPath == "node_modules/"+installName
 
-			// Add edges from dependencies
-			for depName := range dep.Dependencies {
-				graph.edges[name] = append(graph.edges[name], depName)
-			}
+		if !opts.IncludeDev && isDevelopment(isDirect, depType, dep.Dev) {
+			continue
+		}
 
-			// Add edges for direct dependencies from root
-			if _, isDirect := directDeps[name]; isDirect {
-				graph.edges[""] = append(graph.edges[""], name)
+		// Copies of the same version installed in several places share a
+		// node, and so do links and the directories they point at
+		name, version := identify(packages, pkgPath)
+		key := scanners.NodeKey(name, version)
+		if _, ok := graph.nodes[key]; !ok {
+			entry := dep
+			if linked, ok := packages[target]; ok && dep.Link {
+				entry = linked
 			}
-		}
-	} else {
-		// Handle legacy package-lock format
-		for name, lockDep := range lockFile.Dependencies {
-			graph.versions[name] = lockDep.Version
+			graph.nodes[key] = &entry
+			graph.names[key] = name
+			graph.versions[key] = version
+			graph.licenses[key] = string(entry.License)
+			graph.dirs[key] = pkgPath
 
 			// Store metadata
 			metadata := make(map[string]string)
-			if depType, ok := directDeps[name]; ok {
+			if isDirect {
 				metadata["dependencyType"] = depType
-			} else if lockDep.Dev {
+			} else if entry.Dev {
 				metadata["dependencyType"] = "development"
 			} else {
 				metadata["dependencyType"] = "production"
 			}
 
-			if lockDep.Optional {
+			if entry.Optional {
 				metadata["optional"] = "true"
 			}
-			if lockDep.Peer {
+			if entry.Peer {
 				metadata["peer"] = "true"
 			}
-			if lockDep.Resolved != "" {
-				metadata["resolved"] = lockDep.Resolved
+			if entry.InBundle {
+				metadata["bundled"] = "true"
 			}
-			if lockDep.Integrity != "" {
-				metadata["integrity"] = lockDep.Integrity
+			if entry.Resolved != "" {
+				metadata["resolved"] = entry.Resolved
 			}
-			graph.metadata[name] = metadata
-
-			// Add edges from requires
-			for reqName := range lockDep.Requires {
-				graph.edges[name] = append(graph.edges[name], reqName)
+			if entry.Integrity != "" {
+				metadata["integrity"] = entry.Integrity
 			}
+			if entry.HasInstallScript {
+				metadata["hasInstallScript"] = "true"
+			}
+			platformProperties(metadata, entry)
+			graph.metadata[key] = metadata
+		} else if isDirect {
+			// The directory a link points at comes first
+			graph.metadata[key]["dependencyType"] = depType
+		}
+		if isDirect && bundles(pkg, installName) {
+			graph.metadata[key]["bundled"] = "true"
+		}
+		if isDirect && depType == "peer" && optionalPeer(pkg, installName) {
+			graph.metadata[key]["optional"] = "true"
+		}
+		sourceProperties(graph.metadata[key], pkgPath, name, dep)
 
-			// Add edges for direct dependencies from root
-			if _, isDirect := directDeps[name]; isDirect {
-				graph.edges[""] = append(graph.edges[""], name)
+		// Add edges to the copies of dependencies this package resolves
+		for depName := range dep.Dependencies {
+			resolved, ok := ResolvePackage(packages, pkgPath, depName)
+			if !ok {
+				continue
 			}
+			graph.addEdge(key, scanners.NodeKey(identify(packages, resolved)))
+		}
+
+		// Add edges for direct dependencies from root
+		if isDirect {
+			graph.addEdge("", key)
 		}
 	}
 
 	return graph
 }
 
-func (s *NPMScanner) readPackageJSON(dir string) (*PackageJSON, error) {
-	content, err := os.ReadFile(filepath.Join(dir, "package.json"))
+// addEdge adds an edge between two nodes unless it already exists
+func (g *dependencyGraph) addEdge(from, to string) {
+	if !slices.Contains(g.edges[from], to) {
+		g.edges[from] = append(g.edges[from], to)
+	}
+}
+
+// PackageName returns the name of the package installed at a lockfile path,
+// e.g. "debug" for node_modules/send/node_modules/debug
+func PackageName(pkgPath string) string {
+	if i := strings.LastIndex(pkgPath, "node_modules/"); i >= 0 {
+		return pkgPath[i+len("node_modules/"):]
+	}
+	return pkgPath
+}
+
+// ResolvePackage finds the lockfile path of the copy of a dependency that a
+// package installed at from loads, looking in its own node_modules and then
+// in those of the directories above it, as Node.js does
+func ResolvePackage(packages map[string]PackageDep, from, name string) (string, bool) {
+	dir := from
+	for {
+		candidate := path.Join(dir, "node_modules", name)
+		if _, ok := packages[candidate]; ok {
+			return candidate, true
+		}
+		if dir == "" {
+			return "", false
+		}
+		i := strings.LastIndex(dir, "node_modules/")
+		if i < 0 {
+			i = 0
+		}
+		dir = strings.TrimSuffix(dir[:i], "/")
+	}
+}
+
+// installOrder returns the lockfile paths shallowest first, so the hoisted
+// copy of a package describes a version installed in several places
+func installOrder(packages map[string]PackageDep) []string {
+	paths := make([]string, 0, len(packages))
+	for pkgPath := range packages {
+		paths = append(paths, pkgPath)
+	}
+	slices.SortFunc(paths, func(a, b string) int {
+		if depth := strings.Count(a, "node_modules/") - strings.Count(b, "node_modules/"); depth != 0 {
+			return depth
+		}
+		return strings.Compare(a, b)
+	})
+	return paths
+}
+
+func (s *NPMScanner) readPackageJSON(fsys fs.FS) (*PackageJSON, error) {
+	content, err := fs.ReadFile(fsys, "package.json")
 	if err != nil {
 		return nil, err
 	}
 
 	var pkg PackageJSON
 	if err := json.Unmarshal(content, &pkg); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: package.json: %w", scanners.ErrInvalidProject, err)
 	}
 
 	return &pkg, nil
 }
 
-func (s *NPMScanner) readPackageLock(dir string) (*PackageLock, error) {
-	content, err := os.ReadFile(filepath.Join(dir, "package-lock.json"))
+// ReadLockfile reads the package-lock.json at the root of fsys. The error
+// wraps fs.ErrNotExist when the project has no lockfile.
+func ReadLockfile(fsys fs.FS) (*PackageLock, error) {
+	content, err := fs.ReadFile(fsys, "package-lock.json")
 	if err != nil {
 		return nil, err
 	}
 
 	var lock PackageLock
 	if err := json.Unmarshal(content, &lock); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: package-lock.json: %w", scanners.ErrInvalidProject, err)
 	}
 
 	return &lock, nil
@@ -303,6 +532,10 @@ func (s *NPMScanner) getDirectDependencies(pkg *PackageJSON) map[string]string {
 		directDeps[name] = "development"
 	}
 	for name := range pkg.PeerDependencies {
+		// Optional peers installed for the project's own use keep that type
+		if _, ok := directDeps[name]; ok && optionalPeer(pkg, name) {
+			continue
+		}
 		directDeps[name] = "peer"
 	}
 	for name := range pkg.OptionalDependencies {
@@ -310,3 +543,124 @@ func (s *NPMScanner) getDirectDependencies(pkg *PackageJSON) map[string]string {
 	}
 	return directDeps
 }
+
+// getSpecifier returns the version range package.json declares for a direct
+// dependency
+func (s *NPMScanner) getSpecifier(pkg *PackageJSON, name string) string {
+	for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.PeerDependencies, pkg.OptionalDependencies} {
+		if specifier, ok := deps[name]; ok {
+			return specifier
+		}
+	}
+	return ""
+}
+
+// installedPackage is the package.json of a package installed in
+// node_modules
+type installedPackage struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	License              licenseField      `json:"license"`
+	Licenses             []licenseField    `json:"licenses"`
+	Scripts              map[string]string `json:"scripts"`
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	Engines              enginesField      `json:"engines"`
+	OS                   stringList        `json:"os"`
+	CPU                  stringList        `json:"cpu"`
+	Libc                 stringList        `json:"libc"`
+
+	hasBindingGyp bool
+}
+
+// readInstalledPackage reads the package.json of the package installed in
+// dir, or returns nil when the package is not installed
+func (s *NPMScanner) readInstalledPackage(fsys fs.FS, dir string) *installedPackage {
+	content, err := fs.ReadFile(fsys, path.Join(dir, "package.json"))
+	if err != nil {
+		return nil
+	}
+
+	var pkg installedPackage
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil
+	}
+	_, err = fs.Stat(fsys, path.Join(dir, "binding.gyp"))
+	pkg.hasBindingGyp = err == nil
+	return &pkg
+}
+
+// reuse copies the install scripts read from node_modules in an earlier scan
+// into props. It reports false, copying nothing, when the package's lockfile
+// entry changed since or the earlier scan lacks the script commands requested.
+func reuse(prev *scanners.Dependency, props map[string]string, includeScripts bool) bool {
+	if props["integrity"] == "" || prev.Properties["integrity"] != props["integrity"] || prev.Properties["resolved"] != props["resolved"] {
+		return false
+	}
+	names := strings.Split(prev.Properties["installScripts"], ",")
+	if prev.Properties["installScripts"] == "" {
+		names = nil
+	}
+	for _, name := range names {
+		if _, ok := prev.Properties["script."+name]; includeScripts && !ok {
+			return false
+		}
+	}
+
+	if len(names) > 0 {
+		props["installScripts"] = prev.Properties["installScripts"]
+	}
+	if includeScripts {
+		for _, name := range names {
+			props["script."+name] = prev.Properties["script."+name]
+		}
+	}
+	return true
+}
+
+// license returns the declared license, for lockfiles without license fields
+func (p *installedPackage) license() string {
+	if p.License != "" {
+		return string(p.License)
+	}
+
+	// The deprecated "licenses" array lists alternatives
+	var ids []string
+	for _, l := range p.Licenses {
+		if l != "" {
+			ids = append(ids, string(l))
+		}
+	}
+	if len(ids) > 1 {
+		return "(" + strings.Join(ids, " OR ") + ")"
+	}
+	return strings.Join(ids, "")
+}
+
+// licenseField is a package's declared license, which older packages give
+// as a {"type": ...} object instead of an SPDX expression
+type licenseField string
+
+func (l *licenseField) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		var object struct {
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(data, &object) != nil {
+			// Malformed license metadata should not fail the scan
+			return nil
+		}
+		value = object.Type
+	}
+	*l = licenseField(license.Normalize(value))
+	return nil
+}
+
+// isDevelopment reports whether a package is only needed during development
+func isDevelopment(isDirect bool, depType string, dev bool) bool {
+	if isDirect {
+		return depType == "development"
+	}
+	return dev
+}
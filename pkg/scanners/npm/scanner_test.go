@@ -2,9 +2,13 @@ package npm
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/santoshdahal12/deplister/pkg/scanners"
 
@@ -101,7 +105,7 @@ func TestNPMScanner_ScanDependencies(t *testing.T) {
 	assert.NoError(t, err)
 
 	scanner := NewScanner()
-	result, err := scanner.ScanDependencies(context.Background(), dir)
+	result, err := scanner.ScanDependencies(context.Background(), dir, scanners.DefaultScanOptions())
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 
@@ -127,6 +131,7 @@ func TestNPMScanner_ScanDependencies(t *testing.T) {
 	assert.Equal(t, "production", reactDep.Properties["dependencyType"])
 	assert.Equal(t, "https://registry.npmjs.org/react/-/react-18.2.0.tgz", reactDep.Properties["resolved"])
 	assert.Equal(t, "sha512-abcd1234", reactDep.Properties["integrity"])
+	assert.Equal(t, "^18.2.0", reactDep.Properties["specifier"])
 	assert.Equal(t, 1, reactDep.Depth) // Direct dependency has depth 1
 
 	prettierDep := findDep("prettier")
@@ -154,18 +159,25 @@ func TestNPMScanner_ScanDependencies(t *testing.T) {
 	// Check dependency paths
 	reactPaths := reactDep.Paths
 	assert.Len(t, reactPaths, 1)
-	assert.Equal(t, []string{"", "react"}, reactPaths[0].Path)
+	assert.Equal(t, []string{"", "react@18.2.0"}, reactPaths[0].Path)
 
-	jsTokensPaths := jsTokensDep.Paths
-	assert.Greater(t, len(jsTokensPaths), 0)
+	// Only the shortest path is recorded by default
+	assert.Len(t, jsTokensDep.Paths, 1)
+	assert.Len(t, jsTokensDep.Paths[0].Path, 4)
+
+	opts := scanners.DefaultScanOptions()
+	opts.MaxPaths = 10
+	result, err = scanner.ScanDependencies(context.Background(), dir, opts)
+	assert.NoError(t, err)
+	jsTokensPaths := findDep("js-tokens").Paths
 	// Should have multiple paths through react and react-dom
 	foundReactPath := false
 	foundReactDomPath := false
 	for _, path := range jsTokensPaths {
-		if len(path.Path) == 4 && path.Path[1] == "react" {
+		if len(path.Path) == 4 && path.Path[1] == "react@18.2.0" {
 			foundReactPath = true
 		}
-		if len(path.Path) == 4 && path.Path[1] == "react-dom" {
+		if len(path.Path) == 4 && path.Path[1] == "react-dom@18.2.0" {
 			foundReactDomPath = true
 		}
 	}
@@ -174,6 +186,388 @@ func TestNPMScanner_ScanDependencies(t *testing.T) {
 
 	// Verify graph structure
 	assert.NotNil(t, result.Graph)
-	assert.Contains(t, result.Graph.Edges["react"], "loose-envify")
-	assert.Contains(t, result.Graph.Edges["loose-envify"], "js-tokens")
+	assert.Contains(t, result.Graph.Edges["react@18.2.0"], "loose-envify@1.4.0")
+	assert.Contains(t, result.Graph.Edges["loose-envify@1.4.0"], "js-tokens@4.0.0")
+	assert.Contains(t, result.Graph.Nodes, "js-tokens@4.0.0")
+}
+
+func TestNPMScanner_NestedVersions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json": {Data: []byte(`{"name": "test-project", "dependencies": {"express": "^4.18.0", "debug": "^4.3.0"}}`)},
+		"package-lock.json": {Data: []byte(`{
+			"name": "test-project",
+			"packages": {
+				"": {"name": "test-project"},
+				"node_modules/express": {"version": "4.18.2", "dependencies": {"debug": "2.6.9", "send": "0.18.0"}},
+				"node_modules/express/node_modules/debug": {"version": "2.6.9", "license": "MIT"},
+				"node_modules/send": {"version": "0.18.0", "dependencies": {"debug": "2.6.9"}},
+				"node_modules/send/node_modules/debug": {"version": "2.6.9"},
+				"node_modules/debug": {"version": "4.3.4"}
+			}
+		}`)},
+		"node_modules/express/node_modules/debug/package.json": {Data: []byte(`{"name": "debug", "scripts": {"postinstall": "node x.js"}}`)},
+	}
+
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, scanners.DefaultScanOptions())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Both nested copies of debug 2.6.9 share a node
+	assert.Len(t, result.Dependencies, 4)
+	old, ok := result.Graph.Node("debug", "2.6.9")
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.False(t, old.IsDirectDep)
+	assert.Equal(t, "production", old.Properties["dependencyType"])
+	assert.Equal(t, "postinstall", old.Properties["installScripts"])
+	assert.ElementsMatch(t, []string{"express", "send"}, old.Parents)
+	assert.Equal(t, 2, old.Depth)
+
+	current, ok := result.Graph.Node("debug", "4.3.4")
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.True(t, current.IsDirectDep)
+	assert.Equal(t, "^4.3.0", current.Properties["specifier"])
+	assert.Empty(t, current.Parents)
+
+	assert.ElementsMatch(t, []string{"debug@2.6.9", "send@0.18.0"}, result.Graph.Edges["express@4.18.2"])
+	assert.Equal(t, []string{"debug@2.6.9"}, result.Graph.Edges["send@0.18.0"])
+	assert.ElementsMatch(t, []string{"express@4.18.2", "debug@4.3.4"}, result.Graph.Edges[""])
+}
+
+func TestNPMScanner_ScanDependenciesStream(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "test-project", "dependencies": {"express": "^4.18.0"}}`), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "package-lock.json"), []byte(`{
+		"name": "test-project",
+		"packages": {
+			"": {"name": "test-project"},
+			"node_modules/express": {"version": "4.18.2", "dependencies": {"debug": "2.6.9"}},
+			"node_modules/debug": {"version": "2.6.9"}
+		}
+	}`), 0644)
+	assert.NoError(t, err)
+
+	scanner := NewScanner()
+	var streamed []scanners.Dependency
+	err = scanner.ScanDependenciesStream(context.Background(), dir, scanners.DefaultScanOptions(), func(dep scanners.Dependency) error {
+		streamed = append(streamed, dep)
+		return nil
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	result, err := scanner.ScanDependencies(context.Background(), dir, scanners.DefaultScanOptions())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.ElementsMatch(t, result.Dependencies, streamed)
+
+	// The callback's error stops the scan
+	stop := errors.New("stop")
+	calls := 0
+	err = scanner.ScanDependenciesStream(context.Background(), dir, scanners.DefaultScanOptions(), func(dep scanners.Dependency) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+
+	err = scanner.ScanDependenciesStream(context.Background(), t.TempDir(), scanners.DefaultScanOptions(), func(dep scanners.Dependency) error { return nil })
+	assert.ErrorIs(t, err, scanners.ErrProjectNotFound)
+}
+
+func TestNPMScanner_ScanOptions(t *testing.T) {
+	dir := t.TempDir()
+
+	packageJSON := `{
+		"name": "test-project",
+		"dependencies": {"react": "^18.2.0"},
+		"devDependencies": {"prettier": "^1.19.1"}
+	}`
+
+	packageLockJSON := `{
+		"name": "test-project",
+		"packages": {
+			"": {"name": "test-project"},
+			"node_modules/react": {
+				"version": "18.2.0",
+				"dependencies": {"loose-envify": "^1.1.0"}
+			},
+			"node_modules/loose-envify": {"version": "1.4.0"},
+			"node_modules/prettier": {"version": "1.19.1", "dev": true}
+		}
+	}`
+
+	err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(packageJSON), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "package-lock.json"), []byte(packageLockJSON), 0644)
+	assert.NoError(t, err)
+
+	names := func(result *scanners.ScanResult) []string {
+		var names []string
+		for _, dep := range result.Dependencies {
+			names = append(names, dep.Name)
+		}
+		return names
+	}
+
+	scanner := NewScanner()
+
+	opts := scanners.DefaultScanOptions()
+	opts.IncludeDev = false
+	result, err := scanner.ScanDependencies(context.Background(), dir, opts)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"react", "loose-envify"}, names(result))
+
+	opts = scanners.DefaultScanOptions()
+	opts.MaxDepth = 1
+	result, err = scanner.ScanDependencies(context.Background(), dir, opts)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"react", "prettier"}, names(result))
+}
+
+func TestNPMScanner_ScanDependenciesFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json": {Data: []byte(`{"name": "test-project", "version": "1.0.0", "dependencies": {"lodash": "^4.17.21"}}`)},
+		"package-lock.json": {Data: []byte(`{
+			"name": "test-project",
+			"packages": {
+				"": {"name": "test-project"},
+				"node_modules/lodash": {"version": "4.17.21"}
+			}
+		}`)},
+	}
+
+	var scanner scanners.FSScanner = NewScanner()
+	assert.True(t, scanner.DetectProjectFS(context.Background(), fsys))
+	assert.False(t, scanner.DetectProjectFS(context.Background(), fstest.MapFS{}))
+
+	result, err := scanner.ScanDependenciesFS(context.Background(), fsys, scanners.DefaultScanOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, &scanners.Project{Name: "test-project", Version: "1.0.0"}, result.Project)
+	assert.Len(t, result.Dependencies, 1)
+	assert.Equal(t, "lodash", result.Dependencies[0].Name)
+	assert.Equal(t, "4.17.21", result.Dependencies[0].Version)
+	assert.True(t, result.Dependencies[0].IsDirectDep)
+}
+
+func TestNPMScanner_InvalidFiles(t *testing.T) {
+	scanner := NewScanner()
+	_, err := scanner.ScanDependenciesFS(context.Background(), fstest.MapFS{
+		"package.json": {Data: []byte(`{"name": "test-project",}`)},
+	}, scanners.DefaultScanOptions())
+	assert.ErrorIs(t, err, scanners.ErrInvalidProject)
+	var syntaxErr *json.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr)
+	assert.ErrorContains(t, err, "package.json")
+
+	_, err = scanner.ScanDependenciesFS(context.Background(), fstest.MapFS{
+		"package.json":      {Data: []byte(`{"name": "test-project"}`)},
+		"package-lock.json": {Data: []byte(`{"packages": []}`)},
+	}, scanners.DefaultScanOptions())
+	assert.ErrorIs(t, err, scanners.ErrInvalidProject)
+	assert.ErrorContains(t, err, "package-lock.json")
+}
+
+func TestNPMScanner_Registry(t *testing.T) {
+	t.Setenv("NPM_CONFIG_USERCONFIG", filepath.Join(t.TempDir(), "npmrc"))
+	fsys := fstest.MapFS{
+		".npmrc":       {Data: []byte("@myorg:registry=https://npm.pkg.github.com/\n")},
+		"package.json": {Data: []byte(`{"name": "test-project", "dependencies": {"lodash": "^4.17.21", "@myorg/ui": "^1.0.0"}}`)},
+		"package-lock.json": {Data: []byte(`{
+			"name": "test-project",
+			"lockfileVersion": 3,
+			"packages": {
+				"": {"name": "test-project"},
+				"node_modules/lodash": {"version": "4.17.21"},
+				"node_modules/@myorg/ui": {"version": "1.0.0"}
+			}
+		}`)},
+	}
+
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, scanners.DefaultScanOptions())
+	if !assert.NoError(t, err) {
+		return
+	}
+	ui, _ := result.Graph.Node("@myorg/ui", "1.0.0")
+	assert.Equal(t, "https://npm.pkg.github.com/", ui.Properties["registry"])
+	lodash, _ := result.Graph.Node("lodash", "4.17.21")
+	assert.NotContains(t, lodash.Properties, "registry")
+}
+
+func TestNPMScanner_Licenses(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json": {Data: []byte(`{"name": "test-project", "dependencies": {"lodash": "^4.17.21", "legacy": "^1.0.0", "dual": "^1.0.0", "unknown": "^1.0.0"}}`)},
+		"package-lock.json": {Data: []byte(`{
+			"name": "test-project",
+			"packages": {
+				"": {"name": "test-project"},
+				"node_modules/lodash": {"version": "4.17.21", "license": "MIT"},
+				"node_modules/legacy": {"version": "1.0.0"},
+				"node_modules/dual": {"version": "1.0.0"},
+				"node_modules/unknown": {"version": "1.0.0"}
+			}
+		}`)},
+		"node_modules/legacy/package.json": {Data: []byte(`{"name": "legacy", "license": {"type": "Apache 2.0", "url": "https://www.apache.org/licenses/LICENSE-2.0"}}`)},
+		"node_modules/dual/package.json":   {Data: []byte(`{"name": "dual", "licenses": [{"type": "MIT"}, {"type": "GPL-2.0"}]}`)},
+	}
+
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, scanners.DefaultScanOptions())
+	assert.NoError(t, err)
+
+	licenses := make(map[string]string)
+	for _, dep := range result.Dependencies {
+		licenses[dep.Name] = dep.License
+	}
+	assert.Equal(t, map[string]string{
+		"lodash":  "MIT",
+		"legacy":  "Apache-2.0",
+		"dual":    "(MIT OR GPL-2.0)",
+		"unknown": "",
+	}, licenses)
+}
+
+func TestNPMScanner_InstallScripts(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json": {Data: []byte(`{"name": "test-project", "dependencies": {"esbuild": "^0.20.0", "bcrypt": "^5.0.0", "uninstalled": "^1.0.0", "plain": "^1.0.0"}}`)},
+		"package-lock.json": {Data: []byte(`{
+			"name": "test-project",
+			"packages": {
+				"": {"name": "test-project"},
+				"node_modules/esbuild": {"version": "0.20.2", "hasInstallScript": true},
+				"node_modules/bcrypt": {"version": "5.1.1", "hasInstallScript": true},
+				"node_modules/uninstalled": {"version": "1.0.0", "hasInstallScript": true},
+				"node_modules/plain": {"version": "1.0.0"}
+			}
+		}`)},
+		"node_modules/esbuild/package.json": {Data: []byte(`{"name": "esbuild", "scripts": {"postinstall": "node install.js", "test": "make test"}}`)},
+		"node_modules/bcrypt/package.json":  {Data: []byte(`{"name": "bcrypt"}`)},
+		"node_modules/bcrypt/binding.gyp":   {Data: []byte(`{}`)},
+		"node_modules/plain/package.json":   {Data: []byte(`{"name": "plain", "scripts": {"test": "jest"}}`)},
+	}
+
+	opts := scanners.DefaultScanOptions()
+	opts.IncludeScripts = true
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, opts)
+	assert.NoError(t, err)
+
+	props := make(map[string]map[string]string)
+	for _, dep := range result.Dependencies {
+		props[dep.Name] = dep.Properties
+	}
+	assert.Equal(t, "postinstall", props["esbuild"]["installScripts"])
+	assert.Equal(t, "node install.js", props["esbuild"]["script.postinstall"])
+	assert.Equal(t, "install", props["bcrypt"]["installScripts"])
+	assert.Equal(t, "node-gyp rebuild", props["bcrypt"]["script.install"])
+	assert.Equal(t, "true", props["uninstalled"]["hasInstallScript"])
+	assert.NotContains(t, props["uninstalled"], "installScripts")
+	assert.NotContains(t, props["plain"], "installScripts")
+	assert.NotContains(t, props["plain"], "script.test")
+}
+
+func TestNPMScanner_Previous(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json": {Data: []byte(`{"name": "test-project", "dependencies": {"esbuild": "^0.20.0", "left-pad": "^1.3.0"}}`)},
+		"package-lock.json": {Data: []byte(`{
+			"name": "test-project",
+			"packages": {
+				"": {"name": "test-project"},
+				"node_modules/esbuild": {"version": "0.20.2", "integrity": "sha512-esbuild", "resolved": "https://registry.npmjs.org/esbuild/-/esbuild-0.20.2.tgz"},
+				"node_modules/left-pad": {"version": "1.3.0", "integrity": "sha512-leftpad", "resolved": "https://registry.npmjs.org/left-pad/-/left-pad-1.3.0.tgz"}
+			}
+		}`)},
+	}
+	previous := &scanners.ScanResult{Dependencies: []scanners.Dependency{
+		{Name: "esbuild", Version: "0.20.2", License: "MIT", Properties: map[string]string{
+			"integrity":          "sha512-esbuild",
+			"resolved":           "https://registry.npmjs.org/esbuild/-/esbuild-0.20.2.tgz",
+			"installScripts":     "postinstall",
+			"script.postinstall": "node install.js",
+		}},
+		{Name: "left-pad", Version: "1.3.0", License: "WTFPL", Properties: map[string]string{"integrity": "sha512-tampered"}},
+	}}
+
+	opts := scanners.DefaultScanOptions()
+	opts.Previous = previous
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Nothing is installed, so the license and scripts come from the earlier scan
+	esbuild, _ := result.Graph.Node("esbuild", "0.20.2")
+	assert.Equal(t, "MIT", esbuild.License)
+	assert.Equal(t, "postinstall", esbuild.Properties["installScripts"])
+	assert.NotContains(t, esbuild.Properties, "script.postinstall")
+
+	// The lockfile entry changed since
+	leftPad, _ := result.Graph.Node("left-pad", "1.3.0")
+	assert.Empty(t, leftPad.License)
+
+	// Script commands missing from the earlier scan are read again
+	delete(previous.Dependencies[0].Properties, "script.postinstall")
+	opts.IncludeScripts = true
+	result, err = NewScanner().ScanDependenciesFS(context.Background(), fsys, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	esbuild, _ = result.Graph.Node("esbuild", "0.20.2")
+	assert.Empty(t, esbuild.License)
+	assert.NotContains(t, esbuild.Properties, "installScripts")
+}
+
+func TestNPMScanner_NodeModules(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json":                      {Data: []byte(`{"name": "test-project", "dependencies": {"express": "^4.18.0", "my-ms": "npm:ms@^2.1.0"}, "devDependencies": {"@types/node": "^20.0.0"}}`)},
+		"yarn.lock":                         {Data: []byte("# yarn lockfile v1\n")},
+		"node_modules/.yarn-integrity":      {Data: []byte("{}")},
+		"node_modules/express/package.json": {Data: []byte(`{"name": "express", "version": "4.18.2", "license": "MIT", "dependencies": {"debug": "2.6.9"}}`)},
+		"node_modules/express/node_modules/debug/package.json": {Data: []byte(`{"name": "debug", "version": "2.6.9", "dependencies": {"ms": "2.0.0"}}`)},
+		"node_modules/ms/package.json":                         {Data: []byte(`{"name": "ms", "version": "2.0.0"}`)},
+		"node_modules/my-ms/package.json":                      {Data: []byte(`{"name": "ms", "version": "2.1.3"}`)},
+		"node_modules/@types/node/package.json":                {Data: []byte(`{"name": "@types/node", "version": "20.11.0", "licenses": [{"type": "MIT"}]}`)},
+	}
+
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, scanners.DefaultScanOptions())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, result.Dependencies, 5)
+	for _, dep := range result.Dependencies {
+		assert.Equal(t, "node_modules", dep.Properties["derivedFrom"], dep.Name)
+	}
+
+	debug, ok := result.Graph.Node("debug", "2.6.9")
+	if assert.True(t, ok) {
+		assert.Equal(t, []string{"express"}, debug.Parents)
+		assert.Equal(t, "production", debug.Properties["dependencyType"])
+	}
+	assert.Equal(t, []string{"ms@2.0.0"}, result.Graph.Edges["debug@2.6.9"])
+
+	express, _ := result.Graph.Node("express", "4.18.2")
+	assert.Equal(t, "MIT", express.License)
+	assert.True(t, express.IsDirectDep)
+
+	aliased, _ := result.Graph.Node("ms", "2.1.3")
+	assert.Equal(t, "my-ms", aliased.Properties["alias"])
+
+	types, _ := result.Graph.Node("@types/node", "20.11.0")
+	assert.Equal(t, "development", types.Properties["dependencyType"])
+	assert.Equal(t, "MIT", types.License)
+
+	opts := scanners.DefaultScanOptions()
+	opts.IncludeDev = false
+	result, err = NewScanner().ScanDependenciesFS(context.Background(), fsys, opts)
+	if assert.NoError(t, err) {
+		assert.Len(t, result.Dependencies, 4)
+	}
+
+	// Nothing installed and no lockfile is still an error
+	_, err = NewScanner().ScanDependenciesFS(context.Background(), fstest.MapFS{"package.json": fsys["package.json"]}, scanners.DefaultScanOptions())
+	assert.ErrorIs(t, err, fs.ErrNotExist)
 }
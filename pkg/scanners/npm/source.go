@@ -0,0 +1,82 @@
+package npm
+
+import (
+	"path"
+	"strings"
+)
+
+// identify returns the name and version of the package installed at a
+// lockfile path. Packages installed under an alias, as with "npm:lodash@4",
+// and workspace members record their real name, and links take both from
+// the directory they point at.
+func identify(packages map[string]PackageDep, pkgPath string) (string, string) {
+	dep := packages[pkgPath]
+	name := PackageName(pkgPath)
+	if dep.Link {
+		target, ok := packages[dep.Resolved]
+		if !ok {
+			return name, ""
+		}
+		if target.Name != "" {
+			name = target.Name
+		}
+		return name, target.Version
+	}
+	if dep.Name != "" {
+		return dep.Name, dep.Version
+	}
+
+	// Directories outside node_modules are named by the links to them
+	if !strings.Contains(pkgPath, "node_modules/") {
+		for linkPath, link := range packages {
+			if link.Link && link.Resolved == pkgPath {
+				return PackageName(linkPath), dep.Version
+			}
+		}
+	}
+	return name, dep.Version
+}
+
+// isWorkspace reports whether a lockfile path is a workspace member of the
+// project or installed for one
+func isWorkspace(pkg *PackageJSON, pkgPath string) bool {
+	pkgPath, _, _ = strings.Cut(pkgPath, "/node_modules/")
+	for _, pattern := range pkg.Workspaces {
+		if matched, _ := path.Match(path.Clean(pattern), pkgPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceProperties records where a package not downloaded from the registry
+// came from: "source" is git, file, link or tarball, with the repository and
+// commit of git dependencies as "origin.url" and "origin.hash", like Go
+// modules, and the directory or file of local ones as "source.path".
+// Packages installed under an alias get it as the "alias" property.
+func sourceProperties(props map[string]string, pkgPath, name string, dep PackageDep) {
+	if installName := PackageName(pkgPath); installName != name && strings.Contains(pkgPath, "node_modules/") {
+		props["alias"] = installName
+	}
+
+	resolved := dep.Resolved
+	switch {
+	case dep.Link:
+		props["source"] = "link"
+		props["source.path"] = resolved
+	case strings.HasPrefix(resolved, "git+") || strings.HasPrefix(resolved, "git://"):
+		url, hash, _ := strings.Cut(strings.TrimPrefix(resolved, "git+"), "#")
+		props["source"] = "git"
+		props["origin.vcs"] = "git"
+		props["origin.url"] = url
+		if hash != "" {
+			props["origin.hash"] = hash
+		}
+	case strings.HasPrefix(resolved, "file:"):
+		props["source"] = "file"
+		props["source.path"] = strings.TrimPrefix(resolved, "file:")
+	case (strings.HasPrefix(resolved, "https://") || strings.HasPrefix(resolved, "http://")) && !strings.Contains(resolved, "/-/"):
+		// Registry tarballs are published under /<name>/-/
+		props["source"] = "tarball"
+	}
+}
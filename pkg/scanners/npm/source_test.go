@@ -0,0 +1,82 @@
+package npm
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNPMScanner_Sources(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json": {Data: []byte(`{
+			"name": "test-project",
+			"dependencies": {
+				"my-lodash": "npm:lodash@^4.17.0",
+				"left-pad": "git+https://github.com/stevemao/left-pad.git#v1.3.0",
+				"local": "file:../local",
+				"vendored": "file:vendor/vendored-1.0.0.tgz",
+				"remote": "https://example.com/remote-2.0.0.tgz"
+			}
+		}`)},
+		"package-lock.json": {Data: []byte(`{
+			"name": "test-project",
+			"lockfileVersion": 3,
+			"packages": {
+				"": {"name": "test-project"},
+				"node_modules/my-lodash": {"name": "lodash", "version": "4.17.21", "resolved": "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz"},
+				"node_modules/left-pad": {"version": "1.3.0", "resolved": "git+ssh://git@github.com/stevemao/left-pad.git#5f7f6a2c7b0e4d2a0e8f3f1a5a2b1c3d4e5f6a7b"},
+				"node_modules/local": {"resolved": "../local", "link": true},
+				"../local": {"version": "0.1.0", "dependencies": {"my-lodash": "npm:lodash@^4.17.0"}},
+				"node_modules/vendored": {"version": "1.0.0", "resolved": "file:vendor/vendored-1.0.0.tgz"},
+				"node_modules/remote": {"version": "2.0.0", "resolved": "https://example.com/remote-2.0.0.tgz"}
+			}
+		}`)},
+	}
+
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, scanners.DefaultScanOptions())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, result.Dependencies, 5)
+
+	lodash, ok := result.Graph.Node("lodash", "4.17.21")
+	if assert.True(t, ok) {
+		assert.True(t, lodash.IsDirectDep)
+		assert.Equal(t, "my-lodash", lodash.Properties["alias"])
+		assert.Equal(t, "npm:lodash@^4.17.0", lodash.Properties["specifier"])
+		assert.NotContains(t, lodash.Properties, "source")
+	}
+
+	leftPad, _ := result.Graph.Node("left-pad", "1.3.0")
+	assert.Equal(t, "git", leftPad.Properties["source"])
+	assert.Equal(t, "ssh://git@github.com/stevemao/left-pad.git", leftPad.Properties["origin.url"])
+	assert.Equal(t, "5f7f6a2c7b0e4d2a0e8f3f1a5a2b1c3d4e5f6a7b", leftPad.Properties["origin.hash"])
+
+	local, ok := result.Graph.Node("local", "0.1.0")
+	if assert.True(t, ok) {
+		assert.True(t, local.IsDirectDep)
+		assert.Equal(t, "link", local.Properties["source"])
+		assert.Equal(t, "../local", local.Properties["source.path"])
+		assert.Equal(t, []string{"lodash@4.17.21"}, result.Graph.Edges["local@0.1.0"])
+	}
+
+	vendored, _ := result.Graph.Node("vendored", "1.0.0")
+	assert.Equal(t, "file", vendored.Properties["source"])
+	assert.Equal(t, "vendor/vendored-1.0.0.tgz", vendored.Properties["source.path"])
+
+	remote, _ := result.Graph.Node("remote", "2.0.0")
+	assert.Equal(t, "tarball", remote.Properties["source"])
+}
+
+func TestIsWorkspace(t *testing.T) {
+	pkg := &PackageJSON{Workspaces: []string{"packages/*", "./tools/cli"}}
+	assert.True(t, isWorkspace(pkg, "packages/a"))
+	assert.True(t, isWorkspace(pkg, "packages/a/node_modules/lodash"))
+	assert.True(t, isWorkspace(pkg, "tools/cli"))
+	assert.False(t, isWorkspace(pkg, "../local"))
+	assert.False(t, isWorkspace(pkg, "node_modules/lodash"))
+}
@@ -0,0 +1,66 @@
+package scanners
+
+import "net/http"
+
+// ScanOptions controls how a scanner resolves dependencies
+type ScanOptions struct {
+	IncludeDev       bool               `json:"includeDev"`            // Include development dependencies
+	FollowWorkspaces bool               `json:"followWorkspaces"`      // Include workspace packages of monorepos
+	Offline          bool               `json:"offline"`               // Never access the network while scanning
+	MaxDepth         int                `json:"maxDepth"`              // Maximum dependency depth to report, 0 for unlimited
+	MaxPaths         int                `json:"maxPaths"`              // Paths recorded per dependency, shortest first; 0 or 1 for only the shortest
+	Paths            string             `json:"paths,omitempty"`       // Paths recorded per dependency: none, shortest or all; "" for shortest
+	IncludeScripts   bool               `json:"includeScripts"`        // Include the text of install scripts in dependency properties
+	Enrich           map[string]bool    `json:"enrich,omitempty"`      // Enrichment steps to run after scanning, keyed by name
+	EnrichWorkers    int                `json:"enrichWorkers"`         // Dependencies per-dependency enrichers process at once, 0 for the number of CPUs
+	EnrichRate       float64            `json:"enrichRate"`            // Per-dependency enricher calls started per second at most, 0 for no limit
+	AbandonedDays    int                `json:"abandonedDays"`         // Days without a release before a package counts as abandoned, 0 for the default
+	FailOnCycles     bool               `json:"failOnCycles"`          // Report cycles of Go module graphs as errors
+	RiskWeights      map[string]float64 `json:"riskWeights,omitempty"` // Weights of the risk score signals, keyed by signal; defaults for signals missing
+	VulnDB           string             `json:"-"`                     // Local vulnerability database to use instead of OSV.dev
+	CacheDir         string             `json:"-"`                     // Directory of cached scan results, "" to always scan
+	Previous         *ScanResult        `json:"-"`                     // Earlier result of the project, whose data of unchanged packages is reused
+	HTTPClient       *http.Client       `json:"-"`                     // Client of enrichments reaching the network, nil for httpclient.Default
+	Env              []string           `json:"-"`                     // Extra KEY=value environment of the commands scanners run, e.g. GOPRIVATE; never taken from requests
+	GoMod            string             `json:"goMod,omitempty"`       // -mod mode of the go commands: mod, vendor or readonly; "" for the go tool's choice
+	GoScope          string             `json:"goScope,omitempty"`     // Go modules reported: graph, build or annotate; "" for graph
+	GoPackages       bool               `json:"goPackages"`            // Record the packages of each Go module the build uses and the main module packages importing them
+	GoStdlib         bool               `json:"goStdlib"`              // Report the Go standard library at the effective Go version as a dependency
+	GoTests          bool               `json:"goTests"`               // Classify Go modules only the tests of the main module import as "test" dependencies
+	NodeVersion      string             `json:"nodeVersion,omitempty"` // Node.js version npm packages are checked against, "" for none
+	Platform         string             `json:"platform,omitempty"`    // os[/cpu[/libc]] npm packages are checked against, "" for none
+	PythonEnv        map[string]string  `json:"pythonEnv,omitempty"`   // PEP 508 marker variables of the environment Python dependencies are installed in; dependencies whose markers exclude it are left out
+}
+
+// DefaultScanOptions returns the options matching deplister's default behavior
+func DefaultScanOptions() ScanOptions {
+	return ScanOptions{
+		IncludeDev:       true,
+		FollowWorkspaces: true,
+	}
+}
+
+// WithinDepth reports whether a dependency at the given depth should be reported
+func (o ScanOptions) WithinDepth(depth int) bool {
+	return o.MaxDepth <= 0 || depth <= o.MaxDepth
+}
+
+// Enabled reports whether the named enrichment step was requested
+func (o ScanOptions) Enabled(enrichment string) bool {
+	return o.Enrich[enrichment]
+}
+
+// Reusable indexes the dependencies of an earlier result by node key, so that
+// scanners can reuse what they read for packages whose lockfile entry did not
+// change. It returns nil without an earlier result.
+func Reusable(previous *ScanResult) map[string]*Dependency {
+	if previous == nil {
+		return nil
+	}
+	index := make(map[string]*Dependency, len(previous.Dependencies))
+	for i := range previous.Dependencies {
+		dep := &previous.Dependencies[i]
+		index[NodeKey(dep.Name, dep.Version)] = dep
+	}
+	return index
+}
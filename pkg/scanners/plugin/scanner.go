@@ -0,0 +1,192 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/tracing"
+)
+
+// Prefix is the file name prefix of external scanner binaries
+const Prefix = "deplister-scanner-"
+
+// OptionsEnv is the environment variable holding the JSON encoded scan options
+const OptionsEnv = "DEPLISTER_SCAN_OPTIONS"
+
+// DefaultPriority places plugins after the built-in scanners
+const DefaultPriority = 100
+
+// PluginScanner runs an external scanner binary.
+//
+// The protocol is intentionally small:
+//
+//	deplister-scanner-<type> detect <dir>   exit status 0 when the project is supported
+//	deplister-scanner-<type> scan <dir>     writes a PluginResult as JSON to stdout
+//
+// Scan options are passed to the plugin as JSON in the OptionsEnv environment variable.
+type PluginScanner struct {
+	scanners.BaseScanner
+	binary string
+}
+
+// PluginResult is the JSON document a plugin writes on scan
+type PluginResult struct {
+	Dependencies []PluginDependency  `json:"dependencies"`
+	Edges        map[string][]string `json:"edges,omitempty"`
+}
+
+// PluginDependency is a single dependency reported by a plugin
+type PluginDependency struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+	License     string            `json:"license,omitempty"`
+	IsDirectDep bool              `json:"isDirectDependency"`
+	Parent      string            `json:"parent,omitempty"`
+	Parents     []string          `json:"parents,omitempty"`
+	Properties  map[string]string `json:"properties,omitempty"`
+	Depth       int               `json:"depth,omitempty"`
+}
+
+// NewScanner creates a scanner backed by the given plugin binary
+func NewScanner(binary string) *PluginScanner {
+	name := strings.TrimPrefix(filepath.Base(binary), Prefix)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	return &PluginScanner{
+		BaseScanner: scanners.NewBaseScanner(name),
+		binary:      binary,
+	}
+}
+
+// Discover finds plugin binaries in the directories of the given PATH list.
+// The first binary found for a scanner type wins, mirroring PATH lookup.
+func Discover(pathList string) []*PluginScanner {
+	var plugins []*PluginScanner
+	seen := make(map[string]bool)
+
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(dir, Prefix+"*"))
+		if err != nil {
+			continue
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+				continue
+			}
+			plugin := NewScanner(match)
+			if plugin.GetType() == "" || seen[plugin.GetType()] {
+				continue
+			}
+			seen[plugin.GetType()] = true
+			plugins = append(plugins, plugin)
+		}
+	}
+
+	return plugins
+}
+
+// RegisterAll discovers plugins on PATH and adds them to the scanner registry.
+// Plugins never replace an already registered scanner of the same type.
+func RegisterAll() {
+	for _, plugin := range Discover(os.Getenv("PATH")) {
+		_ = scanners.Register(plugin, DefaultPriority)
+	}
+}
+
+func (s *PluginScanner) DetectProject(ctx context.Context, dir string) bool {
+	cmd := exec.CommandContext(ctx, s.binary, "detect", dir)
+	return tracing.Run(ctx, cmd) == nil
+}
+
+func (s *PluginScanner) ScanDependencies(ctx context.Context, dir string, opts scanners.ScanOptions) (*scanners.ScanResult, error) {
+	encoded, err := json.Marshal(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, s.binary, "scan", dir)
+	cmd.Env = append(os.Environ(), OptionsEnv+"="+string(encoded))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := tracing.Run(ctx, cmd); err != nil {
+		if detail := bytes.TrimSpace(stderr.Bytes()); len(detail) > 0 {
+			return nil, fmt.Errorf("%w: %s scan: %w: %s", scanners.ErrScanFailed, filepath.Base(s.binary), err, detail)
+		}
+		return nil, fmt.Errorf("%w: %s scan: %w", scanners.ErrScanFailed, filepath.Base(s.binary), err)
+	}
+
+	var output PluginResult
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return nil, fmt.Errorf("%w: decoding %s output: %w", scanners.ErrInvalidProject, filepath.Base(s.binary), err)
+	}
+
+	// Plugins report edges between package names, so key them by the
+	// version each plugin dependency was resolved to
+	versions := make(map[string]string, len(output.Dependencies))
+	for _, dep := range output.Dependencies {
+		versions[dep.Name] = dep.Version
+	}
+	key := func(name string) string {
+		if name == "" {
+			return ""
+		}
+		return scanners.NodeKey(name, versions[name])
+	}
+	edges := make(map[string][]string, len(output.Edges))
+	for parent, children := range output.Edges {
+		for _, child := range children {
+			edges[key(parent)] = append(edges[key(parent)], key(child))
+		}
+	}
+
+	result := &scanners.ScanResult{
+		Dependencies: make([]scanners.Dependency, 0, len(output.Dependencies)),
+		Graph: &scanners.DependencyGraph{
+			Nodes: make(map[string]*scanners.Dependency),
+			Edges: edges,
+		},
+	}
+
+	for _, dep := range output.Dependencies {
+		if !opts.WithinDepth(dep.Depth) {
+			continue
+		}
+
+		props := dep.Properties
+		if props == nil {
+			props = make(map[string]string)
+		}
+		props["manager"] = s.GetType()
+
+		dependency := scanners.Dependency{
+			Name:        dep.Name,
+			Version:     dep.Version,
+			License:     dep.License,
+			Type:        s.GetType(),
+			IsDirectDep: dep.IsDirectDep,
+			Parent:      dep.Parent,
+			Parents:     dep.Parents,
+			Properties:  props,
+			Depth:       dep.Depth,
+		}
+		if dependency.Parent == "" && len(dependency.Parents) > 0 {
+			dependency.Parent = dependency.Parents[0]
+		}
+
+		result.Dependencies = append(result.Dependencies, dependency)
+		result.Graph.Nodes[key(dep.Name)] = &dependency
+	}
+
+	return result, nil
+}
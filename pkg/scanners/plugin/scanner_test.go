@@ -0,0 +1,121 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPlugin = `#!/bin/sh
+case "$1" in
+detect)
+	test -f "$2/custom.lock"
+	;;
+scan)
+	cat <<'JSON'
+{
+	"dependencies": [
+		{"name": "core", "version": "1.0.0", "isDirectDependency": true, "depth": 1},
+		{"name": "util", "version": "0.2.0", "parents": ["core"], "depth": 2}
+	],
+	"edges": {"": ["core"], "core": ["util"]}
+}
+JSON
+	;;
+esac
+`
+
+func writePlugin(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	err := os.WriteFile(path, []byte(testPlugin), 0755)
+	assert.NoError(t, err, "failed to write plugin")
+	return path
+}
+
+func TestDiscover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell plugins are not supported on windows")
+	}
+
+	first := t.TempDir()
+	second := t.TempDir()
+
+	writePlugin(t, first, Prefix+"custom")
+	writePlugin(t, second, Prefix+"custom")
+	writePlugin(t, second, Prefix+"other")
+	err := os.WriteFile(filepath.Join(second, Prefix+"noexec"), []byte(testPlugin), 0644)
+	assert.NoError(t, err)
+
+	plugins := Discover(first + string(os.PathListSeparator) + second)
+
+	types := make(map[string]string)
+	for _, p := range plugins {
+		types[p.GetType()] = p.binary
+	}
+	assert.Len(t, types, 2)
+	assert.Equal(t, filepath.Join(first, Prefix+"custom"), types["custom"], "first PATH entry should win")
+	assert.Contains(t, types, "other")
+	assert.NotContains(t, types, "noexec")
+}
+
+func TestPluginScanner_Scan(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell plugins are not supported on windows")
+	}
+
+	scanner := NewScanner(writePlugin(t, t.TempDir(), Prefix+"custom"))
+	assert.Equal(t, "custom", scanner.GetType())
+
+	project := t.TempDir()
+	ctx := context.Background()
+	assert.False(t, scanner.DetectProject(ctx, project))
+
+	err := os.WriteFile(filepath.Join(project, "custom.lock"), []byte{}, 0644)
+	assert.NoError(t, err)
+	assert.True(t, scanner.DetectProject(ctx, project))
+
+	result, err := scanner.ScanDependencies(ctx, project, scanners.DefaultScanOptions())
+	assert.NoError(t, err)
+	assert.Len(t, result.Dependencies, 2)
+
+	util := result.Graph.Nodes["util@0.2.0"]
+	assert.NotNil(t, util)
+	assert.Equal(t, "custom", util.Type)
+	assert.Equal(t, "core", util.Parent)
+	assert.Equal(t, "custom", util.Properties["manager"])
+	assert.Equal(t, []string{"util@0.2.0"}, result.Graph.Edges["core@1.0.0"])
+}
+
+func TestPluginScanner_Errors(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell plugins are not supported on windows")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, Prefix+"broken")
+	script := "#!/bin/sh\nif [ -f \"$2/garbage\" ]; then echo not json; exit 0; fi\necho 'custom.lock: unexpected token' >&2\nexit 3\n"
+	if !assert.NoError(t, os.WriteFile(path, []byte(script), 0755)) {
+		return
+	}
+	scanner := NewScanner(path)
+	project := t.TempDir()
+
+	_, err := scanner.ScanDependencies(context.Background(), project, scanners.DefaultScanOptions())
+	assert.ErrorIs(t, err, scanners.ErrScanFailed)
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, err, &exitErr)
+	assert.ErrorContains(t, err, "custom.lock: unexpected token")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(project, "garbage"), nil, 0644))
+	_, err = scanner.ScanDependencies(context.Background(), project, scanners.DefaultScanOptions())
+	assert.ErrorIs(t, err, scanners.ErrInvalidProject)
+	assert.ErrorContains(t, err, Prefix+"broken")
+}
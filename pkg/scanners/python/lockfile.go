@@ -0,0 +1,513 @@
+package python
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/tracing"
+)
+
+// lockfileNames are the lockfiles the scanner reads, the first found winning
+var lockfileNames = []string{"uv.lock", "pdm.lock"}
+
+// lockfile is what the scanner reads of a uv or PDM lockfile
+type lockfile struct {
+	Name     string // File name
+	Manager  string
+	Version  string // Version of the lockfile format
+	Project  *scanners.Project
+	Packages []*lockedPackage
+	Direct   []lockedDependency // Dependencies of the project itself
+}
+
+// lockedPackage is a package pinned by a lockfile
+type lockedPackage struct {
+	Name    string
+	Version string
+	// Source is where the package comes from, keyed by kind as uv records
+	// it: registry, git, url, path, directory, editable or virtual
+	Source       map[string]string
+	Dependencies []lockedDependency
+}
+
+// lockedDependency is a dependency of a locked package or of the project
+type lockedDependency struct {
+	Name    string
+	Version string // Locked version, given only when several are locked
+	Marker  string // Environment marker, if any
+	Type    string // production, optional or development, for the project's dependencies
+	Group   string // Extra or dependency group declaring the project's dependency
+}
+
+// readLockfile reads the first of lockfileNames found in fsys, with the
+// pyproject.toml next to it when the lockfile does not name the project's
+// dependencies itself. It returns nil without a lockfile.
+func readLockfile(fsys fs.FS) (*lockfile, error) {
+	for _, name := range lockfileNames {
+		data, err := fs.ReadFile(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var lock *lockfile
+		switch name {
+		case "uv.lock":
+			lock, err = parseUVLock(string(data))
+		case "pdm.lock":
+			var pyproject map[string]any
+			if pyproject, err = readPyproject(fsys); err == nil {
+				lock, err = parsePDMLock(string(data), pyproject)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", scanners.ErrInvalidProject, name, err)
+		}
+		lock.Name = name
+		return lock, nil
+	}
+	return nil, nil
+}
+
+// readPyproject decodes the pyproject.toml of the project, returning nil
+// without one
+func readPyproject(fsys fs.FS) (map[string]any, error) {
+	data, err := fs.ReadFile(fsys, "pyproject.toml")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	pyproject, err := parseTOML(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("pyproject.toml: %w", err)
+	}
+	return pyproject, nil
+}
+
+// parseUVLock reads a uv.lock. The project is the package at ".", whose
+// dependencies, extras and development dependency groups the lockfile
+// lists. A virtual workspace root is no package, so its members stand in
+// for the project.
+func parseUVLock(data string) (*lockfile, error) {
+	doc, err := parseTOML(data)
+	if err != nil {
+		return nil, err
+	}
+	lock := &lockfile{Manager: "uv", Version: tomlString(doc["version"])}
+	for _, entry := range tomlArray(doc["package"]) {
+		table := tomlTable(entry)
+		pkg := &lockedPackage{
+			Name:         tomlString(table["name"]),
+			Version:      tomlString(table["version"]),
+			Source:       make(map[string]string),
+			Dependencies: uvDependencies(table["dependencies"], "production", ""),
+		}
+		for kind, location := range tomlTable(table["source"]) {
+			pkg.Source[kind] = tomlString(location)
+		}
+		extras := tomlTable(table["optional-dependencies"])
+
+		if pkg.Source["editable"] == "." || pkg.Source["virtual"] == "." {
+			lock.Project = &scanners.Project{Name: pkg.Name, Version: pkg.Version}
+			lock.Direct = append(lock.Direct, pkg.Dependencies...)
+			for _, extra := range sortedKeys(extras) {
+				lock.Direct = append(lock.Direct, uvDependencies(extras[extra], "optional", extra)...)
+			}
+			groups := tomlTable(table["dev-dependencies"])
+			for _, group := range sortedKeys(groups) {
+				lock.Direct = append(lock.Direct, uvDependencies(groups[group], "development", group)...)
+			}
+			continue
+		}
+		// The extras of other packages are only locked when requested
+		for _, extra := range sortedKeys(extras) {
+			pkg.Dependencies = append(pkg.Dependencies, uvDependencies(extras[extra], "", "")...)
+		}
+		lock.Packages = append(lock.Packages, pkg)
+	}
+
+	if lock.Project == nil {
+		manifest := tomlTable(doc["manifest"])
+		for _, member := range tomlArray(manifest["members"]) {
+			lock.Direct = append(lock.Direct, lockedDependency{Name: tomlString(member), Type: "production"})
+		}
+		groups := tomlTable(manifest["dependency-groups"])
+		for _, group := range sortedKeys(groups) {
+			lock.Direct = append(lock.Direct, uvDependencies(groups[group], "development", group)...)
+		}
+	}
+	return lock, nil
+}
+
+// uvDependencies reads a uv.lock array of { name = ..., version = ...,
+// marker = ... } dependency tables
+func uvDependencies(value any, depType, group string) []lockedDependency {
+	var deps []lockedDependency
+	for _, entry := range tomlArray(value) {
+		table := tomlTable(entry)
+		if name := tomlString(table["name"]); name != "" {
+			deps = append(deps, lockedDependency{
+				Name:    name,
+				Version: tomlString(table["version"]),
+				Marker:  tomlString(table["marker"]),
+				Type:    depType,
+				Group:   group,
+			})
+		}
+	}
+	return deps
+}
+
+// parsePDMLock reads a pdm.lock, whose packages list their dependencies as
+// requirement strings. The lockfile does not list the project's own
+// dependencies, which come from its pyproject.toml.
+func parsePDMLock(data string, pyproject map[string]any) (*lockfile, error) {
+	doc, err := parseTOML(data)
+	if err != nil {
+		return nil, err
+	}
+	lock := &lockfile{Manager: "pdm", Version: tomlString(tomlTable(doc["metadata"])["lock_version"])}
+	for _, entry := range tomlArray(doc["package"]) {
+		table := tomlTable(entry)
+		pkg := &lockedPackage{
+			Name:    tomlString(table["name"]),
+			Version: tomlString(table["version"]),
+			Source:  make(map[string]string),
+		}
+		switch {
+		case tomlString(table["git"]) != "":
+			pkg.Source["git"] = tomlString(table["git"])
+			if revision := tomlString(table["revision"]); revision != "" {
+				pkg.Source["git"] += "#" + revision
+			}
+		case tomlString(table["path"]) != "" && table["editable"] == true:
+			pkg.Source["editable"] = tomlString(table["path"])
+		case tomlString(table["path"]) != "":
+			pkg.Source["path"] = tomlString(table["path"])
+		case tomlString(table["url"]) != "":
+			pkg.Source["url"] = tomlString(table["url"])
+		}
+		pkg.Dependencies = requirements(table["dependencies"], "", "")
+		lock.Packages = append(lock.Packages, pkg)
+	}
+	lock.Project, lock.Direct = projectDependencies(pyproject)
+	return lock, nil
+}
+
+// projectDependencies reads the project and the dependencies it declares in
+// pyproject.toml: its dependencies and extras, its PEP 735 dependency
+// groups and the development dependencies of tool.pdm, which predate them
+func projectDependencies(pyproject map[string]any) (*scanners.Project, []lockedDependency) {
+	project := tomlTable(pyproject["project"])
+	var info *scanners.Project
+	if name := tomlString(project["name"]); name != "" {
+		info = &scanners.Project{Name: name, Version: tomlString(project["version"])}
+	}
+
+	direct := requirements(project["dependencies"], "production", "")
+	extras := tomlTable(project["optional-dependencies"])
+	for _, extra := range sortedKeys(extras) {
+		direct = append(direct, requirements(extras[extra], "optional", extra)...)
+	}
+	groups := tomlTable(pyproject["dependency-groups"])
+	for _, group := range sortedKeys(groups) {
+		direct = append(direct, requirements(dependencyGroup(groups, group, nil), "development", group)...)
+	}
+	pdmGroups := tomlTable(tomlTable(tomlTable(pyproject["tool"])["pdm"])["dev-dependencies"])
+	for _, group := range sortedKeys(pdmGroups) {
+		direct = append(direct, requirements(pdmGroups[group], "development", group)...)
+	}
+	return info, direct
+}
+
+// dependencyGroup returns the requirements of a PEP 735 dependency group,
+// with those of the groups it includes by { include-group = "name" }.
+// Group names compare like project names; include cycles are broken.
+func dependencyGroup(groups map[string]any, name string, seen []string) []any {
+	var entries []any
+	for group, value := range groups {
+		if normalize(group) != normalize(name) || slices.Contains(seen, normalize(group)) {
+			continue
+		}
+		for _, entry := range tomlArray(value) {
+			if include := tomlString(tomlTable(entry)["include-group"]); include != "" {
+				entries = append(entries, dependencyGroup(groups, include, append(seen, normalize(group)))...)
+			} else {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries
+}
+
+// requirements reads an array of requirement strings. Local requirements
+// without a name, such as "-e ./lib", are skipped.
+func requirements(value any, depType, group string) []lockedDependency {
+	var deps []lockedDependency
+	for _, entry := range tomlArray(value) {
+		if req, ok := parseRequirement(tomlString(entry)); ok {
+			deps = append(deps, lockedDependency{Name: req.Name, Marker: req.Marker, Type: depType, Group: group})
+		}
+	}
+	return deps
+}
+
+func sortedKeys(table map[string]any) []string {
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// scanLockfile builds the dependency graph of a lockfile. Packages only the
+// development dependency groups lead to are development dependencies, and
+// the extras and groups leading to a package are its "groups" property.
+// Dependencies whose markers exclude the target environment of
+// opts.PythonEnv are left out. Lockfiles record no licenses, so they are
+// read from the .venv of the project when it has one.
+func (s *PythonScanner) scanLockfile(ctx context.Context, fsys fs.FS, lock *lockfile, opts scanners.ScanOptions) (*scanners.ScanResult, error) {
+	_, span := tracing.Start(ctx, "pypi.buildDependencyGraph")
+	packages := make(map[string]*lockedPackage)
+	byName := make(map[string][]string)
+	var keys []string
+	for _, pkg := range lock.Packages {
+		if pkg.Name == "" {
+			continue
+		}
+		key := scanners.NodeKey(pkg.Name, pkg.Version)
+		// PDM locks a package again for each set of extras requested
+		if existing, ok := packages[key]; ok {
+			existing.Dependencies = append(existing.Dependencies, pkg.Dependencies...)
+			continue
+		}
+		packages[key] = pkg
+		keys = append(keys, key)
+		byName[normalize(pkg.Name)] = append(byName[normalize(pkg.Name)], key)
+	}
+	slices.Sort(keys)
+
+	// Packages locked at several versions, for different environments, are
+	// all dependencies unless the version is given
+	resolve := func(dep lockedDependency) []string {
+		var matches []string
+		for _, key := range byName[normalize(dep.Name)] {
+			if dep.Version == "" || packages[key].Version == dep.Version {
+				matches = append(matches, key)
+			}
+		}
+		return matches
+	}
+
+	// As for environments, dependencies whose markers the target environment
+	// does not meet are left out of the graph but not of the one roots are
+	// guessed from
+	all := &scanners.DependencyGraph{Edges: make(map[string][]string)}
+	resolved := &scanners.DependencyGraph{Nodes: make(map[string]*scanners.Dependency), Edges: make(map[string][]string)}
+	for _, key := range keys {
+		for _, dep := range packages[key].Dependencies {
+			for _, child := range resolve(dep) {
+				if child == key {
+					continue
+				}
+				all.AddEdge(key, child)
+				if applies(dep.Marker, opts.PythonEnv) {
+					resolved.AddEdge(key, child)
+				}
+			}
+		}
+	}
+
+	directType := make(map[string]string)
+	groups := make(map[string][]string)
+	var production []string
+	for _, dep := range lock.Direct {
+		if dep.Type == "development" && !opts.IncludeDev || !applies(dep.Marker, opts.PythonEnv) {
+			continue
+		}
+		for _, key := range resolve(dep) {
+			resolved.AddEdge("", key)
+			// Dependencies of the project come before extras and groups
+			if _, ok := directType[key]; !ok {
+				directType[key] = dep.Type
+			}
+			if dep.Type != "development" {
+				production = append(production, key)
+			}
+			if dep.Group != "" && !slices.Contains(groups[dep.Group], key) {
+				groups[dep.Group] = append(groups[dep.Group], key)
+			}
+		}
+	}
+	if len(lock.Direct) == 0 {
+		// Without the project's dependencies, as with a pdm.lock missing its
+		// pyproject.toml, those of environments are guessed the same way
+		for _, key := range keys {
+			if len(all.Parents(key)) == 0 {
+				all.AddEdge("", key)
+			}
+		}
+		attachUnreached(all, keys)
+		for _, key := range all.Edges[""] {
+			resolved.AddEdge("", key)
+		}
+		production = resolved.Edges[""]
+	}
+	shortest := resolved.ShortestPaths("")
+	inProduction := reachable(resolved, production)
+	names := make([]string, 0, len(groups))
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+	groupsOf := make(map[string][]string)
+	for _, group := range names {
+		for key := range reachable(resolved, groups[group]) {
+			groupsOf[key] = append(groupsOf[key], group)
+		}
+	}
+	span.End()
+
+	licenses := s.environmentLicenses(fsys)
+	result := &scanners.ScanResult{
+		Dependencies: make([]scanners.Dependency, 0, len(shortest)),
+		Graph:        resolved,
+		Metadata:     map[string]string{"lockfile": lock.Name},
+		Project:      lock.Project,
+	}
+	if lock.Version != "" {
+		result.Metadata["lockfileVersion"] = lock.Version
+	}
+	for _, key := range keys {
+		if _, ok := shortest[key]; !ok {
+			continue
+		}
+		pkg := packages[key]
+		paths, depth := scanners.Paths(resolved, shortest, "", key, opts)
+		if !opts.WithinDepth(depth) {
+			continue
+		}
+
+		var parents []string
+		for _, parent := range resolved.Parents(key) {
+			if parent != "" && !slices.Contains(parents, packages[parent].Name) {
+				parents = append(parents, packages[parent].Name)
+			}
+		}
+		slices.Sort(parents)
+
+		props := map[string]string{"manager": lock.Manager, "dependencyType": directType[key]}
+		if props["dependencyType"] == "" {
+			props["dependencyType"] = "development"
+			if inProduction[key] {
+				props["dependencyType"] = "production"
+			}
+		}
+		if len(groupsOf[key]) > 0 {
+			props["groups"] = strings.Join(groupsOf[key], ",")
+		}
+		sourceProperties(props, pkg.Source)
+
+		dependency := scanners.Dependency{
+			Name:        pkg.Name,
+			Version:     pkg.Version,
+			Type:        "pypi",
+			License:     licenses[scanners.NodeKey(normalize(pkg.Name), pkg.Version)],
+			IsDirectDep: slices.Contains(resolved.Edges[""], key),
+			Parents:     parents,
+			Paths:       paths,
+			Properties:  props,
+			Depth:       depth,
+		}
+		if len(parents) > 0 {
+			dependency.Parent = parents[0]
+		}
+		result.Dependencies = append(result.Dependencies, dependency)
+	}
+	if len(result.Dependencies) == 0 {
+		return nil, fmt.Errorf("%w: no dependencies in %s", scanners.ErrInvalidProject, lock.Name)
+	}
+	for i := range result.Dependencies {
+		dep := &result.Dependencies[i]
+		resolved.Nodes[scanners.NodeKey(dep.Name, dep.Version)] = dep
+	}
+	return result, nil
+}
+
+// reachable returns the nodes the given ones lead to, themselves included
+func reachable(graph *scanners.DependencyGraph, from []string) map[string]bool {
+	seen := make(map[string]bool)
+	queue := slices.Clone(from)
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		queue = append(queue, graph.Edges[key]...)
+	}
+	return seen
+}
+
+// sourceProperties records where a package not downloaded from a registry
+// came from, as the npm scanner does: "source" is git, tarball, file or
+// link, with the repository and commit of git dependencies as "origin.url"
+// and "origin.hash" and the directory of local ones as "source.path".
+// Registry packages get the index they come from as "registry".
+func sourceProperties(props map[string]string, source map[string]string) {
+	switch {
+	case source["registry"] != "":
+		props["registry"] = source["registry"]
+	case source["git"] != "":
+		// uv records the requested ref as a query, and both the commit
+		url, hash, _ := strings.Cut(source["git"], "#")
+		url, _, _ = strings.Cut(url, "?")
+		props["source"] = "git"
+		props["origin.vcs"] = "git"
+		props["origin.url"] = url
+		if hash != "" {
+			props["origin.hash"] = hash
+		}
+	case source["url"] != "":
+		props["source"] = "tarball"
+		props["resolved"] = source["url"]
+	case source["editable"] != "" || source["virtual"] != "":
+		props["source"] = "link"
+		props["source.path"] = source["editable"] + source["virtual"]
+	case source["path"] != "" || source["directory"] != "":
+		props["source"] = "file"
+		props["source.path"] = source["path"] + source["directory"]
+	}
+}
+
+// environmentLicenses identifies the licenses of the packages installed in
+// the .venv of a project, keyed by normalized name and version
+func (s *PythonScanner) environmentLicenses(fsys fs.FS) map[string]string {
+	licenses := make(map[string]string)
+	venv, err := fs.Sub(fsys, ".venv")
+	if err != nil {
+		return licenses
+	}
+	for _, site := range siteDirs(venv) {
+		for _, pkg := range readSite(venv, site) {
+			lic := pkg.meta.license()
+			if lic == "" {
+				lic = s.licenseFile(venv, pkg.dir)
+			}
+			licenses[scanners.NodeKey(normalize(pkg.meta.Name), pkg.meta.Version)] = lic
+		}
+	}
+	return licenses
+}
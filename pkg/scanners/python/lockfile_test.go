@@ -0,0 +1,360 @@
+package python
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// uvLock locks a project depending on httpx, with a socks extra pulling in
+// socksio, pytest in its dev group and mypy in a lint group. Two versions of
+// numpy are locked for different Python versions.
+const uvLock = `version = 1
+requires-python = ">=3.9"
+resolution-markers = [
+    "python_full_version >= '3.10'",
+    "python_full_version < '3.10'",
+]
+
+[[package]]
+name = "app"
+version = "0.1.0"
+source = { editable = "." }
+dependencies = [
+    { name = "httpx" },
+    { name = "numpy", version = "2.0.2", source = { registry = "https://pypi.org/simple" }, marker = "python_full_version < '3.10'" },
+    { name = "numpy", version = "2.2.1", source = { registry = "https://pypi.org/simple" }, marker = "python_full_version >= '3.10'" },
+]
+
+[package.optional-dependencies]
+socks = [
+    { name = "httpx", extra = ["socks"] },
+]
+
+[package.dev-dependencies]
+dev = [
+    { name = "pytest" },
+]
+lint = [
+    { name = "mypy" },
+]
+
+[package.metadata]
+requires-dist = [{ name = "httpx", specifier = ">=0.27" }]
+
+[[package]]
+name = "httpx"
+version = "0.28.1"
+source = { registry = "https://pypi.org/simple" }
+dependencies = [
+    { name = "idna" },
+]
+sdist = { url = "https://files.pythonhosted.org/packages/httpx-0.28.1.tar.gz", hash = "sha256:75e98c5f16b0f35b567856f597f06ff2270a374470a5c2392242528e3e3e42fc", size = 141406 }
+
+[package.optional-dependencies]
+socks = [
+    { name = "socksio" },
+]
+
+[[package]]
+name = "idna"
+version = "3.10"
+source = { registry = "https://pypi.org/simple" }
+
+[[package]]
+name = "mypy"
+version = "1.14.1"
+source = { git = "https://github.com/python/mypy?rev=master#0123abcd" }
+
+[[package]]
+name = "numpy"
+version = "2.0.2"
+source = { registry = "https://pypi.org/simple" }
+resolution-markers = [
+    "python_full_version < '3.10'",
+]
+
+[[package]]
+name = "numpy"
+version = "2.2.1"
+source = { registry = "https://pypi.org/simple" }
+
+[[package]]
+name = "pytest"
+version = "8.3.4"
+source = { registry = "https://pypi.org/simple" }
+dependencies = [
+    { name = "colorama", marker = "sys_platform == 'win32'" },
+    { name = "idna" },
+]
+
+[[package]]
+name = "colorama"
+version = "0.4.6"
+source = { registry = "https://pypi.org/simple" }
+
+[[package]]
+name = "socksio"
+version = "1.0.0"
+source = { registry = "https://pypi.org/simple" }
+`
+
+func scanDeps(t *testing.T, fsys fstest.MapFS, opts scanners.ScanOptions) (*scanners.ScanResult, map[string]scanners.Dependency) {
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, opts)
+	if !assert.NoError(t, err) {
+		return nil, nil
+	}
+	deps := make(map[string]scanners.Dependency)
+	for _, dep := range result.Dependencies {
+		deps[scanners.NodeKey(dep.Name, dep.Version)] = dep
+	}
+	return result, deps
+}
+
+func TestPythonScanner_UVLock(t *testing.T) {
+	fsys := fstest.MapFS{
+		"uv.lock":        {Data: []byte(uvLock)},
+		"pyproject.toml": {Data: []byte("[project]\nname = \"app\"\n")},
+		".venv/lib/python3.12/site-packages/idna-3.10.dist-info/METADATA": metadataFile("Name: idna\nVersion: 3.10\nClassifier: License :: OSI Approved :: BSD License"),
+	}
+	assert.True(t, NewScanner().DetectProjectFS(context.Background(), fsys))
+
+	result, deps := scanDeps(t, fsys, scanners.DefaultScanOptions())
+	if result == nil {
+		return
+	}
+	assert.Equal(t, &scanners.Project{Name: "app", Version: "0.1.0"}, result.Project)
+	assert.Equal(t, map[string]string{"lockfile": "uv.lock", "lockfileVersion": "1"}, result.Metadata)
+	assert.Len(t, deps, 8)
+
+	httpx := deps["httpx@0.28.1"]
+	assert.True(t, httpx.IsDirectDep)
+	assert.Equal(t, map[string]string{"manager": "uv", "dependencyType": "production", "groups": "socks", "registry": "https://pypi.org/simple"}, httpx.Properties)
+
+	// Each locked numpy is a dependency, named by its version
+	assert.True(t, deps["numpy@2.0.2"].IsDirectDep)
+	assert.True(t, deps["numpy@2.2.1"].IsDirectDep)
+
+	// Extras of dependencies are followed when locked
+	socksio := deps["socksio@1.0.0"]
+	assert.Equal(t, "httpx", socksio.Parent)
+	assert.Equal(t, "production", socksio.Properties["dependencyType"])
+	assert.Equal(t, "socks", socksio.Properties["groups"])
+
+	// idna is needed in production as well as by pytest
+	idna := deps["idna@3.10"]
+	assert.Equal(t, "production", idna.Properties["dependencyType"])
+	assert.Equal(t, "dev,socks", idna.Properties["groups"])
+	assert.Equal(t, []string{"httpx", "pytest"}, idna.Parents)
+	assert.Equal(t, "BSD-3-Clause", idna.License)
+
+	assert.Equal(t, "development", deps["pytest@8.3.4"].Properties["dependencyType"])
+	assert.Equal(t, map[string]string{"manager": "uv", "dependencyType": "development", "groups": "dev", "registry": "https://pypi.org/simple"}, deps["colorama@0.4.6"].Properties)
+	assert.Equal(t, map[string]string{
+		"manager":        "uv",
+		"dependencyType": "development",
+		"groups":         "lint",
+		"source":         "git",
+		"origin.vcs":     "git",
+		"origin.url":     "https://github.com/python/mypy",
+		"origin.hash":    "0123abcd",
+	}, deps["mypy@1.14.1"].Properties)
+
+	opts := scanners.DefaultScanOptions()
+	opts.IncludeDev = false
+	_, deps = scanDeps(t, fsys, opts)
+	assert.Len(t, deps, 5)
+	assert.NotContains(t, deps, "pytest@8.3.4")
+	assert.NotContains(t, deps, "colorama@0.4.6")
+	assert.Equal(t, "socks", deps["idna@3.10"].Properties["groups"])
+}
+
+func TestPythonScanner_UVLockPythonEnv(t *testing.T) {
+	opts := scanners.DefaultScanOptions()
+	opts.PythonEnv = map[string]string{"python_version": "3.12", "python_full_version": "3.12.1", "sys_platform": "linux"}
+	_, deps := scanDeps(t, fstest.MapFS{"uv.lock": {Data: []byte(uvLock)}}, opts)
+	// Only the numpy of Python 3.10 and later applies, and colorama is for Windows
+	assert.Len(t, deps, 6)
+	assert.Contains(t, deps, "numpy@2.2.1")
+	assert.NotContains(t, deps, "numpy@2.0.2")
+	assert.NotContains(t, deps, "colorama@0.4.6")
+
+	opts.PythonEnv = map[string]string{"sys_platform": "win32"}
+	_, deps = scanDeps(t, fstest.MapFS{"uv.lock": {Data: []byte(uvLock)}}, opts)
+	assert.Len(t, deps, 8)
+}
+
+func TestPythonScanner_UVWorkspace(t *testing.T) {
+	lock := `version = 1
+
+[manifest]
+members = ["api", "core"]
+
+[manifest.dependency-groups]
+dev = [{ name = "pytest", specifier = ">=8" }]
+
+[[package]]
+name = "api"
+version = "0.1.0"
+source = { editable = "packages/api" }
+dependencies = [{ name = "core" }]
+
+[[package]]
+name = "core"
+version = "0.2.0"
+source = { virtual = "packages/core" }
+
+[[package]]
+name = "pytest"
+version = "8.3.4"
+source = { registry = "https://pypi.org/simple" }
+`
+	result, deps := scanDeps(t, fstest.MapFS{"uv.lock": {Data: []byte(lock)}}, scanners.DefaultScanOptions())
+	if result == nil {
+		return
+	}
+	assert.Nil(t, result.Project)
+	assert.ElementsMatch(t, []string{"api@0.1.0", "core@0.2.0", "pytest@8.3.4"}, result.Graph.Edges[""])
+	assert.Equal(t, map[string]string{"manager": "uv", "dependencyType": "production", "source": "link", "source.path": "packages/core"}, deps["core@0.2.0"].Properties)
+	assert.Equal(t, "development", deps["pytest@8.3.4"].Properties["dependencyType"])
+}
+
+// pdmLock locks requests with its socks extra, locked as a second entry,
+// and pytest for the test group
+const pdmLock = `# This file is @generated by PDM.
+# It is not intended for manual editing.
+
+[metadata]
+groups = ["default", "socks", "test"]
+strategy = ["inherit_metadata"]
+lock_version = "4.5.0"
+content_hash = "sha256:00"
+
+[[package]]
+name = "requests"
+version = "2.32.3"
+requires_python = ">=3.8"
+summary = "Python HTTP for Humans."
+groups = ["default"]
+dependencies = [
+    "certifi>=2017.4.17",
+    "urllib3<3,>=1.21.1",
+]
+files = [
+    {file = "requests-2.32.3-py3-none-any.whl", hash = "sha256:70761cfe03c773ceb22aa2f671b4757976145175cdfca038c02654d061d6dcc6"},
+]
+
+[[package]]
+name = "requests"
+version = "2.32.3"
+extras = ["socks"]
+groups = ["socks"]
+dependencies = [
+    "PySocks!=1.5.7,>=1.5.6",
+    "requests==2.32.3",
+]
+
+[[package]]
+name = "certifi"
+version = "2024.12.14"
+groups = ["default"]
+
+[[package]]
+name = "urllib3"
+version = "2.3.0"
+groups = ["default"]
+
+[[package]]
+name = "pysocks"
+version = "1.7.1"
+groups = ["socks"]
+
+[[package]]
+name = "pytest"
+version = "8.3.4"
+groups = ["test"]
+dependencies = [
+    "colorama; sys_platform == \"win32\"",
+]
+
+[[package]]
+name = "colorama"
+version = "0.4.6"
+groups = ["test"]
+
+[[package]]
+name = "lib"
+version = "0.3.0"
+path = "./lib"
+editable = true
+groups = ["default"]
+`
+
+const pdmPyproject = `[project]
+name = "service"
+version = "1.2.0"
+dependencies = ["requests>=2.32", "lib @ file:///${PROJECT_ROOT}/lib"]
+
+[project.optional-dependencies]
+socks = ["requests[socks]"]
+
+[dependency-groups]
+lint = ["colorama"]
+test = ["pytest>=8", {include-group = "lint"}]
+`
+
+func TestPythonScanner_PDMLock(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pdm.lock":       {Data: []byte(pdmLock)},
+		"pyproject.toml": {Data: []byte(pdmPyproject)},
+	}
+	result, deps := scanDeps(t, fsys, scanners.DefaultScanOptions())
+	if result == nil {
+		return
+	}
+	assert.Equal(t, &scanners.Project{Name: "service", Version: "1.2.0"}, result.Project)
+	assert.Equal(t, map[string]string{"lockfile": "pdm.lock", "lockfileVersion": "4.5.0"}, result.Metadata)
+	assert.Len(t, deps, 7)
+	assert.ElementsMatch(t, []string{"requests@2.32.3", "lib@0.3.0", "pytest@8.3.4", "colorama@0.4.6"}, result.Graph.Edges[""])
+
+	requests := deps["requests@2.32.3"]
+	assert.Equal(t, "production", requests.Properties["dependencyType"])
+	assert.Equal(t, "socks", requests.Properties["groups"])
+	// The extra's entry adds its dependencies to the package
+	assert.Equal(t, "requests", deps["pysocks@1.7.1"].Parent)
+	assert.Equal(t, "production", deps["urllib3@2.3.0"].Properties["dependencyType"])
+
+	assert.Equal(t, map[string]string{"manager": "pdm", "dependencyType": "production", "source": "link", "source.path": "./lib"}, deps["lib@0.3.0"].Properties)
+
+	// The test group includes the lint group
+	colorama := deps["colorama@0.4.6"]
+	assert.True(t, colorama.IsDirectDep)
+	assert.Equal(t, "development", colorama.Properties["dependencyType"])
+	assert.Equal(t, "lint,test", colorama.Properties["groups"])
+}
+
+func TestPythonScanner_PDMLockWithoutPyproject(t *testing.T) {
+	result, _ := scanDeps(t, fstest.MapFS{"pdm.lock": {Data: []byte(pdmLock)}}, scanners.DefaultScanOptions())
+	if result == nil {
+		return
+	}
+	// Packages nothing requires stand in for the project's dependencies
+	assert.ElementsMatch(t, []string{"requests@2.32.3", "pytest@8.3.4", "lib@0.3.0"}, result.Graph.Edges[""])
+}
+
+func TestPythonScanner_InvalidLockfile(t *testing.T) {
+	_, err := NewScanner().ScanDependenciesFS(context.Background(), fstest.MapFS{"uv.lock": {Data: []byte("version = \n")}}, scanners.DefaultScanOptions())
+	assert.ErrorIs(t, err, scanners.ErrInvalidProject)
+}
+
+func TestDependencyGroup(t *testing.T) {
+	groups := map[string]any{
+		"Test":   []any{"pytest", map[string]any{"include-group": "typing"}},
+		"typing": []any{"mypy", map[string]any{"include-group": "test"}},
+	}
+	assert.Equal(t, []any{"pytest", "mypy"}, dependencyGroup(groups, "test", nil))
+}
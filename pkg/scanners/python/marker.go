@@ -0,0 +1,330 @@
+package python
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MarkerVariables are the PEP 508 environment marker variables a target
+// environment can set
+var MarkerVariables = []string{
+	"os_name",
+	"sys_platform",
+	"platform_machine",
+	"platform_python_implementation",
+	"platform_release",
+	"platform_system",
+	"platform_version",
+	"python_version",
+	"python_full_version",
+	"implementation_name",
+	"implementation_version",
+}
+
+// ErrInvalidEnvironment is returned for malformed target environments
+var ErrInvalidEnvironment = errors.New("invalid Python environment")
+
+// ParseEnvironment reads the marker variables of a target environment given
+// as KEY=value entries, such as python_version=3.12 or sys_platform=linux.
+// python_version and python_full_version are derived from each other when
+// only one is given.
+func ParseEnvironment(entries []string) (map[string]string, error) {
+	env := make(map[string]string)
+	for _, entry := range entries {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: %q, expected KEY=value", ErrInvalidEnvironment, entry)
+		}
+		if !slices.Contains(MarkerVariables, key) {
+			return nil, fmt.Errorf("%w: unknown marker variable %s, expected one of %s", ErrInvalidEnvironment, key, strings.Join(MarkerVariables, ", "))
+		}
+		env[key] = value
+	}
+	if full, ok := env["python_full_version"]; ok && env["python_version"] == "" {
+		if release := strings.Split(full, "."); len(release) >= 2 {
+			env["python_version"] = release[0] + "." + release[1]
+		}
+	}
+	if version, ok := env["python_version"]; ok && env["python_full_version"] == "" {
+		env["python_full_version"] = version
+	}
+	return env, nil
+}
+
+// applies reports whether a dependency with an environment marker may be
+// installed in env. Markers using variables env does not set, such as extra,
+// or that cannot be parsed are assumed to hold, so only the dependencies the
+// target certainly excludes are left out.
+func applies(marker string, env map[string]string) bool {
+	if marker == "" || len(env) == 0 {
+		return true
+	}
+	expr, err := parseMarker(marker)
+	if err != nil {
+		return true
+	}
+	return expr(env) != markerFalse
+}
+
+// markerResult is the value of a marker, which is unknown when it depends
+// on variables the environment does not set
+type markerResult int8
+
+const (
+	markerUnknown markerResult = iota
+	markerFalse
+	markerTrue
+)
+
+type markerExpr func(env map[string]string) markerResult
+
+// markerToken matches the parentheses, strings, operators and names markers
+// are made of
+var markerToken = regexp.MustCompile(`^\s*(\(|\)|'[^']*'|"[^"]*"|===|==|!=|<=|>=|~=|<|>|[A-Za-z_][A-Za-z0-9_.]*)`)
+
+// parseMarker parses a PEP 508 marker such as
+// python_version < "3.11" and (sys_platform == "win32" or extra == "cli")
+func parseMarker(marker string) (markerExpr, error) {
+	var tokens []string
+	for rest := marker; strings.TrimSpace(rest) != ""; {
+		match := markerToken.FindStringSubmatch(rest)
+		if match == nil {
+			return nil, fmt.Errorf("invalid marker %q", marker)
+		}
+		tokens = append(tokens, match[1])
+		rest = rest[len(match[0]):]
+	}
+	p := &markerParser{tokens: tokens}
+	expr, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %s in marker %q", p.tokens[p.pos], marker)
+	}
+	return expr, err
+}
+
+// markerName matches the names of variables
+var markerName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+type markerParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *markerParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *markerParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *markerParser) or() (markerExpr, error) {
+	left, err := p.and()
+	for err == nil && p.peek() == "or" {
+		p.pos++
+		var right markerExpr
+		if right, err = p.and(); err == nil {
+			a, b := left, right
+			left = func(env map[string]string) markerResult {
+				x, y := a(env), b(env)
+				switch {
+				case x == markerTrue || y == markerTrue:
+					return markerTrue
+				case x == markerFalse && y == markerFalse:
+					return markerFalse
+				}
+				return markerUnknown
+			}
+		}
+	}
+	return left, err
+}
+
+func (p *markerParser) and() (markerExpr, error) {
+	left, err := p.comparison()
+	for err == nil && p.peek() == "and" {
+		p.pos++
+		var right markerExpr
+		if right, err = p.comparison(); err == nil {
+			a, b := left, right
+			left = func(env map[string]string) markerResult {
+				x, y := a(env), b(env)
+				switch {
+				case x == markerFalse || y == markerFalse:
+					return markerFalse
+				case x == markerTrue && y == markerTrue:
+					return markerTrue
+				}
+				return markerUnknown
+			}
+		}
+	}
+	return left, err
+}
+
+func (p *markerParser) comparison() (markerExpr, error) {
+	if p.peek() == "(" {
+		p.pos++
+		expr, err := p.or()
+		if err == nil && p.next() != ")" {
+			err = errors.New("missing ) in marker")
+		}
+		return expr, err
+	}
+
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	op := p.next()
+	if op == "not" {
+		if p.next() != "in" {
+			return nil, errors.New("expected in after not in marker")
+		}
+		op = "not in"
+	}
+	if !slices.Contains([]string{"===", "==", "!=", "<=", ">=", "~=", "<", ">", "in", "not in"}, op) {
+		return nil, fmt.Errorf("invalid marker operator %q", op)
+	}
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	return func(env map[string]string) markerResult {
+		l, lok := left(env)
+		r, rok := right(env)
+		if !lok || !rok {
+			return markerUnknown
+		}
+		if compareMarker(op, l, r) {
+			return markerTrue
+		}
+		return markerFalse
+	}, nil
+}
+
+// operand reads a string or a variable, whose value is looked up in the
+// environment. The legacy dotted names such as os.name are accepted.
+func (p *markerParser) operand() (func(env map[string]string) (string, bool), error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return nil, errors.New("unexpected end of marker")
+	case token[0] == '"' || token[0] == '\'':
+		value := token[1 : len(token)-1]
+		return func(map[string]string) (string, bool) { return value, true }, nil
+	case markerName.MatchString(token) && !slices.Contains([]string{"and", "or", "in", "not"}, token):
+		name := strings.ReplaceAll(token, ".", "_")
+		return func(env map[string]string) (string, bool) {
+			value, ok := env[name]
+			return value, ok
+		}, nil
+	}
+	return nil, fmt.Errorf("unexpected %s in marker", token)
+}
+
+// compareMarker applies a marker operator. Versions compare as versions and
+// other values as strings, like Python does; in and not in test substrings.
+func compareMarker(op, l, r string) bool {
+	switch op {
+	case "in":
+		return strings.Contains(r, l)
+	case "not in":
+		return !strings.Contains(r, l)
+	case "===":
+		return l == r
+	}
+	if prefix, ok := strings.CutSuffix(r, ".*"); ok && (op == "==" || op == "!=") {
+		matches := l == prefix || strings.HasPrefix(l, prefix+".")
+		return matches == (op == "==")
+	}
+
+	cmp, ok := compareVersions(l, r)
+	if !ok {
+		if op == "~=" {
+			return false
+		}
+		cmp = strings.Compare(l, r)
+	}
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "~=":
+		// Compatible release: at least r, with all but the last of its
+		// release numbers
+		release := strings.Split(r, ".")
+		if len(release) < 2 {
+			return false
+		}
+		prefix := strings.Join(release[:len(release)-1], ".")
+		return cmp >= 0 && (l == prefix || strings.HasPrefix(l, prefix+"."))
+	}
+	return false
+}
+
+// pythonVersion matches the release numbers of a version and what follows
+var pythonVersion = regexp.MustCompile(`^v?([0-9]+(?:\.[0-9]+)*)([-_.+]?[A-Za-z0-9.+]*)$`)
+
+// compareVersions compares two versions by their release numbers, missing
+// numbers counting as zero. Pre-releases and development releases come
+// before the release and post-releases after it; other suffixes, such as
+// local versions, are ignored. It reports false unless both are versions.
+func compareVersions(a, b string) (int, bool) {
+	ma := pythonVersion.FindStringSubmatch(strings.ToLower(a))
+	mb := pythonVersion.FindStringSubmatch(strings.ToLower(b))
+	if ma == nil || mb == nil {
+		return 0, false
+	}
+	ra, rb := strings.Split(ma[1], "."), strings.Split(mb[1], ".")
+	for i := 0; i < len(ra) || i < len(rb); i++ {
+		var x, y int
+		if i < len(ra) {
+			x, _ = strconv.Atoi(ra[i])
+		}
+		if i < len(rb) {
+			y, _ = strconv.Atoi(rb[i])
+		}
+		if x != y {
+			if x < y {
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return suffixRank(ma[2]) - suffixRank(mb[2]), true
+}
+
+func suffixRank(suffix string) int {
+	suffix = strings.TrimLeft(suffix, "-_.")
+	switch {
+	case strings.HasPrefix(suffix, "rc"):
+		return -1
+	case strings.HasPrefix(suffix, "post"), strings.HasPrefix(suffix, "r"):
+		return 1
+	case strings.HasPrefix(suffix, "dev"):
+		return -2
+	case suffix != "" && suffix[0] >= 'a' && suffix[0] <= 'z':
+		// a, b, rc, alpha, beta, c, pre, preview
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,82 @@
+package python
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEnvironment(t *testing.T) {
+	env, err := ParseEnvironment([]string{"python_full_version=3.11.4", "sys_platform=linux"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]string{"python_version": "3.11", "python_full_version": "3.11.4", "sys_platform": "linux"}, env)
+	}
+	env, err = ParseEnvironment([]string{"python_version=3.12"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]string{"python_version": "3.12", "python_full_version": "3.12"}, env)
+	}
+
+	_, err = ParseEnvironment([]string{"python_version"})
+	assert.ErrorIs(t, err, ErrInvalidEnvironment)
+	_, err = ParseEnvironment([]string{"extra=socks"})
+	assert.ErrorIs(t, err, ErrInvalidEnvironment)
+}
+
+func TestApplies(t *testing.T) {
+	env := map[string]string{
+		"python_version":      "3.11",
+		"python_full_version": "3.11.4",
+		"sys_platform":        "linux",
+		"platform_machine":    "x86_64",
+		"os_name":             "posix",
+	}
+	for marker, expected := range map[string]bool{
+		``:                                                    true,
+		`python_version >= "3.8"`:                             true,
+		`python_version < "3.10"`:                             false,
+		`python_version == "3.1"`:                             false,
+		`python_full_version >= '3.11.4'`:                     true,
+		`python_full_version < "3.11.0rc1"`:                   false,
+		`python_version ~= "3.9"`:                             true,
+		`python_version == "3.*"`:                             true,
+		`python_version != "3.11.*"`:                          false,
+		`sys_platform == "win32"`:                             false,
+		`"linux" in sys_platform`:                             true,
+		`platform_machine not in "arm64 aarch64"`:             true,
+		`os.name == "nt"`:                                     false,
+		`sys_platform == "darwin" or python_version < "3.12"`: true,
+		`python_version < "3.12" and (sys_platform == "win32" or os_name == "nt")`: false,
+		// Variables the target does not set may hold
+		`extra == "socks"`: true,
+		`extra == "socks" and sys_platform == "win32"`: false,
+		`extra == "socks" or sys_platform == "win32"`:  true,
+		`implementation_name == "pypy"`:                true,
+		// Markers that cannot be parsed are kept
+		`python_version <`:         true,
+		`(python_version < "3.10"`: true,
+	} {
+		assert.Equal(t, expected, applies(marker, env), marker)
+	}
+	assert.True(t, applies(`sys_platform == "win32"`, nil))
+}
+
+func TestCompareVersions(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		cmp  int
+	}{
+		{"3.10", "3.9", 1},
+		{"3.9", "3.9.0", 0},
+		{"3.12.0a1", "3.12.0", -1},
+		{"3.12.0rc1", "3.12.0b2", 0},
+		{"3.12.0.dev1", "3.12.0a1", -1},
+		{"1.0.post1", "1.0", 1},
+		{"1.0+local", "1.0", 0},
+	} {
+		cmp, ok := compareVersions(tc.a, tc.b)
+		assert.True(t, ok)
+		assert.Equal(t, tc.cmp, max(-1, min(1, cmp)), tc.a+" vs "+tc.b)
+	}
+	_, ok := compareVersions("linux", "3.9")
+	assert.False(t, ok)
+}
@@ -0,0 +1,145 @@
+package python
+
+import (
+	"bufio"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/license"
+)
+
+// metadata holds the fields of a METADATA or PKG-INFO file the scanner uses
+type metadata struct {
+	Name              string
+	Version           string
+	License           string
+	LicenseExpression string
+	Classifiers       []string
+	RequiresDist      []string
+}
+
+// parseMetadata reads the headers of a core metadata file, which uses the
+// email header format. Headers end at the first empty line, after which the
+// description may follow, and continuation lines start with whitespace.
+func parseMetadata(data string) metadata {
+	var meta metadata
+	var key, value string
+	flush := func() {
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(key) {
+		case "name":
+			meta.Name = value
+		case "version":
+			meta.Version = value
+		case "license":
+			meta.License = value
+		case "license-expression":
+			meta.LicenseExpression = value
+		case "classifier":
+			meta.Classifiers = append(meta.Classifiers, value)
+		case "requires-dist":
+			meta.RequiresDist = append(meta.RequiresDist, value)
+		}
+		key, value = "", ""
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			value += "\n" + line
+			continue
+		}
+		flush()
+		if k, v, ok := strings.Cut(line, ":"); ok {
+			key, value = k, v
+		}
+	}
+	flush()
+	return meta
+}
+
+// classifierLicenses maps the trove classifiers of common licenses to their
+// SPDX identifiers
+var classifierLicenses = map[string]string{
+	"MIT License":                                             "MIT",
+	"MIT No Attribution License (MIT-0)":                      "MIT-0",
+	"Apache Software License":                                 "Apache-2.0",
+	"BSD License":                                             "BSD-3-Clause",
+	"ISC License (ISCL)":                                      "ISC",
+	"Mozilla Public License 2.0 (MPL 2.0)":                    "MPL-2.0",
+	"Python Software Foundation License":                      "PSF-2.0",
+	"The Unlicense (Unlicense)":                               "Unlicense",
+	"GNU General Public License v2 (GPLv2)":                   "GPL-2.0",
+	"GNU General Public License v3 (GPLv3)":                   "GPL-3.0",
+	"GNU Lesser General Public License v2 or later (LGPLv2+)": "LGPL-2.0-or-later",
+	"GNU Lesser General Public License v3 (LGPLv3)":           "LGPL-3.0",
+	"GNU Affero General Public License v3":                    "AGPL-3.0",
+}
+
+// license returns the SPDX expression of the package's license: its
+// License-Expression, its License field unless that holds a whole license
+// text, which is identified instead, or else its license classifiers
+func (m metadata) license() string {
+	if m.LicenseExpression != "" {
+		return m.LicenseExpression
+	}
+	if m.License != "" && !strings.Contains(m.License, "\n") && len(m.License) <= 80 && !strings.EqualFold(m.License, "UNKNOWN") {
+		return license.Normalize(m.License)
+	}
+	if m.License != "" {
+		if id := license.Identify(m.License); id != "" {
+			return id
+		}
+	}
+
+	var ids []string
+	for _, classifier := range m.Classifiers {
+		name, ok := strings.CutPrefix(classifier, "License :: ")
+		if !ok {
+			continue
+		}
+		// License :: OSI Approved :: MIT License names it last
+		if i := strings.LastIndex(name, " :: "); i >= 0 {
+			name = name[i+len(" :: "):]
+		}
+		if id, ok := classifierLicenses[name]; ok && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return strings.Join(ids, " OR ")
+}
+
+// requirementName matches the project name a Requires-Dist entry starts with
+var requirementName = regexp.MustCompile(`^\s*([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)`)
+
+// requirement is a Requires-Dist entry
+type requirement struct {
+	Name   string
+	Marker string // Environment marker after the semicolon, if any
+}
+
+// parseRequirement splits a Requires-Dist entry such as
+// "urllib3<3,>=1.21.1; python_version >= '3.8'" into its name and marker
+func parseRequirement(entry string) (requirement, bool) {
+	spec, marker, _ := strings.Cut(entry, ";")
+	match := requirementName.FindStringSubmatch(spec)
+	if match == nil {
+		return requirement{}, false
+	}
+	return requirement{Name: match[1], Marker: strings.TrimSpace(marker)}, true
+}
+
+// separators matches the runs of characters PEP 503 folds into one dash
+var separators = regexp.MustCompile(`[-_.]+`)
+
+// normalize returns the PEP 503 normalized form of a project name, under
+// which pip considers names equal
+func normalize(name string) string {
+	return strings.ToLower(separators.ReplaceAllString(name, "-"))
+}
@@ -0,0 +1,68 @@
+package python
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMetadata(t *testing.T) {
+	meta := parseMetadata(`Metadata-Version: 2.1
+Name: requests
+Version: 2.31.0
+Summary: Python HTTP for Humans.
+License: Apache 2.0
+Classifier: License :: OSI Approved :: Apache Software License
+Requires-Dist: charset-normalizer (<4,>=2)
+Requires-Dist: urllib3<3,>=1.21.1
+Requires-Dist: PySocks!=1.5.7,>=1.5.6 ; extra == 'socks'
+Description-Content-Type: text/markdown
+
+Requires-Dist: not-a-header
+`)
+	assert.Equal(t, "requests", meta.Name)
+	assert.Equal(t, "2.31.0", meta.Version)
+	assert.Equal(t, "Apache-2.0", meta.license())
+	assert.Equal(t, []string{"charset-normalizer (<4,>=2)", "urllib3<3,>=1.21.1", "PySocks!=1.5.7,>=1.5.6 ; extra == 'socks'"}, meta.RequiresDist)
+}
+
+func TestMetadata_License(t *testing.T) {
+	tests := []struct {
+		name string
+		meta metadata
+		want string
+	}{
+		{name: "expression", meta: metadata{LicenseExpression: "MIT OR Apache-2.0", License: "MIT"}, want: "MIT OR Apache-2.0"},
+		{name: "field", meta: metadata{License: "BSD-3-Clause"}, want: "BSD-3-Clause"},
+		{name: "text", meta: metadata{License: "Copyright (c) 2024\n\nPermission is hereby granted, free of charge, to any person obtaining a copy of this software"}, want: "MIT"},
+		{name: "unknown", meta: metadata{License: "UNKNOWN", Classifiers: []string{"License :: OSI Approved :: MIT License"}}, want: "MIT"},
+		{name: "classifiers", meta: metadata{Classifiers: []string{
+			"Programming Language :: Python :: 3",
+			"License :: OSI Approved :: BSD License",
+			"License :: OSI Approved :: Apache Software License",
+		}}, want: "BSD-3-Clause OR Apache-2.0"},
+		{name: "none", meta: metadata{}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.meta.license())
+		})
+	}
+}
+
+func TestParseRequirement(t *testing.T) {
+	req, ok := parseRequirement("urllib3<3,>=1.21.1; python_version >= '3.8'")
+	assert.True(t, ok)
+	assert.Equal(t, requirement{Name: "urllib3", Marker: "python_version >= '3.8'"}, req)
+
+	req, ok = parseRequirement("requests[socks] (>=2.0)")
+	assert.True(t, ok)
+	assert.Equal(t, requirement{Name: "requests"}, req)
+
+	_, ok = parseRequirement("; extra == 'x'")
+	assert.False(t, ok)
+
+	assert.Equal(t, "zope-interface", normalize("Zope.Interface"))
+	assert.Equal(t, "typing-extensions", normalize("typing_extensions"))
+}
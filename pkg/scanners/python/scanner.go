@@ -0,0 +1,317 @@
+package python
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/license"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/tracing"
+)
+
+// sitePatterns are where installed packages live, relative to a
+// site-packages directory itself, a virtualenv or the root of a container
+// image or layer. The lib64 of a virtualenv links to its lib, but Red Hat
+// systems install compiled packages in a separate usr/lib64.
+var sitePatterns = []string{
+	".",
+	"lib/python*/site-packages",
+	"Lib/site-packages",
+	"usr/lib/python*/site-packages",
+	"usr/lib64/python*/site-packages",
+	"usr/lib/python*/dist-packages",
+	"usr/local/lib/python*/site-packages",
+	"usr/local/lib/python*/dist-packages",
+}
+
+// PythonScanner reads the uv.lock or pdm.lock of a Python project, or else
+// inventories the packages installed in a Python environment: a
+// site-packages directory, a virtualenv, or a container image or layer with
+// a system Python. Environments are read from the .dist-info and .egg-info
+// metadata pip and other installers write, so no manifest is needed.
+type PythonScanner struct {
+	scanners.BaseScanner
+}
+
+func init() {
+	if err := scanners.Register(NewScanner(), 30); err != nil {
+		panic(err)
+	}
+}
+
+func NewScanner() *PythonScanner {
+	return &PythonScanner{
+		BaseScanner: scanners.NewBaseScanner("pypi"),
+	}
+}
+
+func (s *PythonScanner) DetectProject(ctx context.Context, dir string) bool {
+	return s.DetectProjectFS(ctx, os.DirFS(dir))
+}
+
+func (s *PythonScanner) DetectProjectFS(ctx context.Context, fsys fs.FS) bool {
+	for _, name := range lockfileNames {
+		if _, err := fs.Stat(fsys, name); err == nil {
+			return true
+		}
+	}
+	return len(siteDirs(fsys)) > 0
+}
+
+func (s *PythonScanner) ScanDependencies(ctx context.Context, dir string, opts scanners.ScanOptions) (*scanners.ScanResult, error) {
+	return s.ScanDependenciesFS(ctx, os.DirFS(dir), opts)
+}
+
+// installed is a package found in a site-packages directory
+type installed struct {
+	meta      metadata
+	key       string
+	dir       string // .dist-info or .egg-info directory
+	site      string
+	installer string
+	requested bool // Installed at the user's request rather than as a dependency
+}
+
+func (s *PythonScanner) ScanDependenciesFS(ctx context.Context, fsys fs.FS, opts scanners.ScanOptions) (*scanners.ScanResult, error) {
+	// A lockfile describes the project rather than what happens to be installed
+	lock, err := readLockfile(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if lock != nil {
+		return s.scanLockfile(ctx, fsys, lock, opts)
+	}
+	return s.scanEnvironment(ctx, fsys, opts)
+}
+
+// scanEnvironment builds the dependency graph of the installed packages,
+// following the requirements whose markers may hold in opts.PythonEnv
+func (s *PythonScanner) scanEnvironment(ctx context.Context, fsys fs.FS, opts scanners.ScanOptions) (*scanners.ScanResult, error) {
+	sites := siteDirs(fsys)
+	if len(sites) == 0 {
+		return nil, scanners.ErrProjectNotFound
+	}
+
+	_, span := tracing.Start(ctx, "pypi.readMetadata")
+	packages := make(map[string]*installed)
+	byName := make(map[string]string)
+	var keys []string
+	for _, site := range sites {
+		for _, pkg := range readSite(fsys, site) {
+			// The same version installed in several environments shares a node
+			if _, ok := packages[pkg.key]; ok {
+				continue
+			}
+			packages[pkg.key] = pkg
+			keys = append(keys, pkg.key)
+			// The first environment listed shadows the others
+			if _, ok := byName[normalize(pkg.meta.Name)]; !ok {
+				byName[normalize(pkg.meta.Name)] = pkg.key
+			}
+		}
+	}
+	span.End()
+	if len(packages) == 0 {
+		return nil, fmt.Errorf("%w: no installed packages in %s", scanners.ErrInvalidProject, strings.Join(sites, ", "))
+	}
+	slices.Sort(keys)
+
+	// The roots are found in the graph of every requirement, while the
+	// dependencies reported only follow those whose markers the target
+	// environment, if any, may meet
+	all := &scanners.DependencyGraph{Edges: make(map[string][]string)}
+	resolved := &scanners.DependencyGraph{Nodes: make(map[string]*scanners.Dependency), Edges: make(map[string][]string)}
+	for _, key := range keys {
+		for _, entry := range packages[key].meta.RequiresDist {
+			req, ok := parseRequirement(entry)
+			if !ok {
+				continue
+			}
+			// Only what is installed is known; optional extras often are not
+			if child, ok := byName[normalize(req.Name)]; ok && child != key {
+				all.AddEdge(key, child)
+				if applies(req.Marker, opts.PythonEnv) {
+					resolved.AddEdge(key, child)
+				}
+			}
+		}
+	}
+
+	// Environments have no manifest naming the direct dependencies. The
+	// packages installed on request, which pip marks with a REQUESTED file,
+	// and those no other installed package requires stand in for them.
+	for _, key := range keys {
+		if packages[key].requested || len(all.Parents(key)) == 0 {
+			all.AddEdge("", key)
+		}
+	}
+	attachUnreached(all, keys)
+	for _, key := range all.Edges[""] {
+		resolved.AddEdge("", key)
+	}
+	shortest := resolved.ShortestPaths("")
+
+	result := &scanners.ScanResult{
+		Dependencies: make([]scanners.Dependency, 0, len(keys)),
+		Graph:        resolved,
+		Metadata:     map[string]string{"environments": strings.Join(sites, ",")},
+	}
+	for _, key := range keys {
+		if _, ok := shortest[key]; !ok {
+			continue
+		}
+		pkg := packages[key]
+		paths, depth := scanners.Paths(resolved, shortest, "", key, opts)
+		if !opts.WithinDepth(depth) {
+			continue
+		}
+
+		var parents []string
+		for _, parent := range resolved.Parents(key) {
+			if parent != "" && !slices.Contains(parents, packages[parent].meta.Name) {
+				parents = append(parents, packages[parent].meta.Name)
+			}
+		}
+		slices.Sort(parents)
+
+		props := map[string]string{"dependencyType": "production", "location": pkg.site}
+		if pkg.installer != "" {
+			props["installer"] = pkg.installer
+		}
+
+		lic := pkg.meta.license()
+		if lic == "" {
+			lic = s.licenseFile(fsys, pkg.dir)
+		}
+
+		dependency := scanners.Dependency{
+			Name:        pkg.meta.Name,
+			Version:     pkg.meta.Version,
+			Type:        "pypi",
+			License:     lic,
+			IsDirectDep: slices.Contains(resolved.Edges[""], key),
+			Parents:     parents,
+			Paths:       paths,
+			Properties:  props,
+			Depth:       depth,
+		}
+		if len(parents) > 0 {
+			dependency.Parent = parents[0]
+		}
+		result.Dependencies = append(result.Dependencies, dependency)
+	}
+	for i := range result.Dependencies {
+		dep := &result.Dependencies[i]
+		resolved.Nodes[scanners.NodeKey(dep.Name, dep.Version)] = dep
+	}
+	return result, nil
+}
+
+// attachUnreached makes the packages no direct dependency leads to, such as
+// cycles nothing else requires, direct dependencies too, since they are
+// still installed, and returns the shortest paths from the root
+func attachUnreached(resolved *scanners.DependencyGraph, keys []string) map[string]scanners.DependencyPath {
+	shortest := resolved.ShortestPaths("")
+	for _, key := range keys {
+		if _, ok := shortest[key]; !ok {
+			resolved.AddEdge("", key)
+			shortest = resolved.ShortestPaths("")
+		}
+	}
+	return shortest
+}
+
+// siteDirs returns the site-packages directories of fsys that hold
+// installed packages, in the order of sitePatterns. The scanned directory
+// itself only counts with a .dist-info directory, since source checkouts
+// hold the .egg-info of the project after an editable install.
+func siteDirs(fsys fs.FS) []string {
+	var sites []string
+	for _, pattern := range sitePatterns {
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			continue
+		}
+		slices.Sort(matches)
+		for _, dir := range matches {
+			dirs := metadataDirs(fsys, dir)
+			if dir == "." {
+				dirs = slices.DeleteFunc(dirs, func(d string) bool { return !strings.HasSuffix(d, ".dist-info") })
+			}
+			if !slices.Contains(sites, dir) && len(dirs) > 0 {
+				sites = append(sites, dir)
+			}
+		}
+	}
+	return sites
+}
+
+// metadataDirs returns the .dist-info and .egg-info directories of a
+// site-packages directory
+func metadataDirs(fsys fs.FS, site string) []string {
+	entries, err := fs.ReadDir(fsys, site)
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() && (strings.HasSuffix(name, ".dist-info") || strings.HasSuffix(name, ".egg-info")) {
+			dirs = append(dirs, path.Join(site, name))
+		}
+	}
+	return dirs
+}
+
+// readSite reads the metadata of every package installed in a
+// site-packages directory, skipping the ones without a name and version
+func readSite(fsys fs.FS, site string) []*installed {
+	var packages []*installed
+	for _, dir := range metadataDirs(fsys, site) {
+		file := "METADATA"
+		if strings.HasSuffix(dir, ".egg-info") {
+			file = "PKG-INFO"
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, file))
+		if err != nil {
+			continue
+		}
+		meta := parseMetadata(string(data))
+		if meta.Name == "" || meta.Version == "" {
+			continue
+		}
+		pkg := &installed{meta: meta, key: scanners.NodeKey(meta.Name, meta.Version), dir: dir, site: site}
+		if data, err := fs.ReadFile(fsys, path.Join(dir, "INSTALLER")); err == nil {
+			pkg.installer = firstLine(string(data))
+		}
+		_, err = fs.Stat(fsys, path.Join(dir, "REQUESTED"))
+		pkg.requested = err == nil
+		packages = append(packages, pkg)
+	}
+	return packages
+}
+
+// licenseFile identifies the license of a package by the license file
+// wheels ship in their .dist-info directory, under licenses/ since PEP 639
+func (s *PythonScanner) licenseFile(fsys fs.FS, dir string) string {
+	for _, sub := range []string{path.Join(dir, "licenses"), dir} {
+		if root, err := fs.Sub(fsys, sub); err == nil {
+			if id := license.FromFS(root); id != "" {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+func firstLine(s string) string {
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	scanner.Scan()
+	return strings.TrimSpace(scanner.Text())
+}
@@ -0,0 +1,133 @@
+package python
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func metadataFile(lines string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte("Metadata-Version: 2.1\n" + lines + "\n")}
+}
+
+// venv is a virtualenv where requests was installed on request, pulling in
+// urllib3 and certifi, and pip came with the environment
+func venv() fstest.MapFS {
+	site := "lib/python3.12/site-packages/"
+	return fstest.MapFS{
+		"pyvenv.cfg": {Data: []byte("version = 3.12.1\n")},
+		site + "requests-2.31.0.dist-info/METADATA": metadataFile("Name: requests\nVersion: 2.31.0\nLicense: Apache 2.0\n" +
+			"Requires-Dist: urllib3<3,>=1.21.1\nRequires-Dist: Certifi>=2017.4.17\nRequires-Dist: PySocks!=1.5.7 ; extra == 'socks'"),
+		site + "requests-2.31.0.dist-info/INSTALLER":           {Data: []byte("pip\n")},
+		site + "requests-2.31.0.dist-info/REQUESTED":           {},
+		site + "urllib3-2.1.0.dist-info/METADATA":              metadataFile("Name: urllib3\nVersion: 2.1.0\nLicense-Expression: MIT"),
+		site + "certifi-2023.11.17.dist-info/METADATA":         metadataFile("Name: certifi\nVersion: 2023.11.17"),
+		site + "certifi-2023.11.17.dist-info/licenses/LICENSE": {Data: []byte("This package contains a modified version of ca-bundle.crt.\n\nThis Source Code Form is subject to the terms of the Mozilla Public License, v. 2.0.")},
+		site + "pip-23.3.1.dist-info/METADATA":                 metadataFile("Name: pip\nVersion: 23.3.1\nClassifier: License :: OSI Approved :: MIT License"),
+		site + "requests/__init__.py":                          {Data: []byte("")},
+	}
+}
+
+func TestPythonScanner_Detect(t *testing.T) {
+	s := NewScanner()
+	ctx := context.Background()
+	assert.True(t, s.DetectProjectFS(ctx, venv()))
+	assert.True(t, s.DetectProjectFS(ctx, fstest.MapFS{"six-1.16.0.dist-info/METADATA": metadataFile("Name: six\nVersion: 1.16.0")}))
+	assert.True(t, s.DetectProjectFS(ctx, fstest.MapFS{"usr/lib/python3/dist-packages/six-1.16.0.egg-info/PKG-INFO": metadataFile("Name: six\nVersion: 1.16.0")}))
+	// The egg-info of an editable install does not make a checkout an environment
+	assert.False(t, s.DetectProjectFS(ctx, fstest.MapFS{"app.egg-info/PKG-INFO": metadataFile("Name: app\nVersion: 0.1.0"), "setup.py": {}}))
+	assert.False(t, s.DetectProjectFS(ctx, fstest.MapFS{"package.json": {Data: []byte("{}")}}))
+}
+
+func TestPythonScanner_Scan(t *testing.T) {
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), venv(), scanners.DefaultScanOptions())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]string{"environments": "lib/python3.12/site-packages"}, result.Metadata)
+
+	deps := make(map[string]scanners.Dependency)
+	for _, dep := range result.Dependencies {
+		deps[dep.Name] = dep
+	}
+	if !assert.Len(t, deps, 4) {
+		return
+	}
+
+	requests := deps["requests"]
+	assert.True(t, requests.IsDirectDep)
+	assert.Equal(t, "pypi", requests.Type)
+	assert.Equal(t, "Apache-2.0", requests.License)
+	assert.Equal(t, map[string]string{"dependencyType": "production", "location": "lib/python3.12/site-packages", "installer": "pip"}, requests.Properties)
+	assert.Equal(t, 1, requests.Depth)
+
+	certifi := deps["certifi"]
+	assert.False(t, certifi.IsDirectDep)
+	assert.Equal(t, "requests", certifi.Parent)
+	assert.Equal(t, 2, certifi.Depth)
+	assert.Equal(t, "MPL-2.0", certifi.License)
+	assert.Equal(t, "MIT", deps["urllib3"].License)
+
+	// Nothing requires pip, which came with the environment
+	assert.True(t, deps["pip"].IsDirectDep)
+	assert.Equal(t, 1, deps["pip"].Depth)
+	assert.Equal(t, "MIT", deps["pip"].License)
+
+	assert.ElementsMatch(t, []string{"requests@2.31.0", "pip@23.3.1"}, result.Graph.Edges[""])
+	assert.Equal(t, &result.Dependencies[0], result.Graph.Nodes[scanners.NodeKey(result.Dependencies[0].Name, result.Dependencies[0].Version)])
+}
+
+func TestPythonScanner_ScanWithoutRequested(t *testing.T) {
+	fsys := fstest.MapFS{
+		"usr/lib/python3/dist-packages/six-1.16.0.egg-info/PKG-INFO":            metadataFile("Name: six\nVersion: 1.16.0"),
+		"usr/lib/python3/dist-packages/python_dateutil-2.8.2.egg-info/PKG-INFO": metadataFile("Name: python-dateutil\nVersion: 2.8.2\nRequires-Dist: six>=1.5"),
+		"usr/local/lib/python3.11/site-packages/six-1.17.0.dist-info/METADATA":  metadataFile("Name: six\nVersion: 1.17.0"),
+	}
+
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, scanners.DefaultScanOptions())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "usr/lib/python3/dist-packages,usr/local/lib/python3.11/site-packages", result.Metadata["environments"])
+	assert.Len(t, result.Dependencies, 3)
+	// The packages nothing requires are the roots
+	assert.ElementsMatch(t, []string{"python-dateutil@2.8.2", "six@1.17.0"}, result.Graph.Edges[""])
+	assert.Equal(t, []string{"six@1.16.0"}, result.Graph.Edges["python-dateutil@2.8.2"])
+}
+
+func TestPythonScanner_ScanPythonEnv(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pytest-8.3.4.dist-info/METADATA":    metadataFile("Name: pytest\nVersion: 8.3.4\nRequires-Dist: colorama; sys_platform == \"win32\"\nRequires-Dist: tomli>=1; python_version < \"3.11\"\nRequires-Dist: iniconfig"),
+		"colorama-0.4.6.dist-info/METADATA":  metadataFile("Name: colorama\nVersion: 0.4.6"),
+		"tomli-2.2.1.dist-info/METADATA":     metadataFile("Name: tomli\nVersion: 2.2.1"),
+		"tomli-2.2.1.dist-info/REQUESTED":    {},
+		"iniconfig-2.0.0.dist-info/METADATA": metadataFile("Name: iniconfig\nVersion: 2.0.0"),
+	}
+	opts := scanners.DefaultScanOptions()
+	opts.PythonEnv = map[string]string{"python_version": "3.12", "sys_platform": "linux"}
+	result, err := NewScanner().ScanDependenciesFS(context.Background(), fsys, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// colorama is only required on Windows, while tomli was also installed on request
+	var names []string
+	for _, dep := range result.Dependencies {
+		names = append(names, dep.Name)
+	}
+	assert.ElementsMatch(t, []string{"pytest", "tomli", "iniconfig"}, names)
+	assert.Equal(t, []string{"iniconfig@2.0.0"}, result.Graph.Edges["pytest@8.3.4"])
+	assert.ElementsMatch(t, []string{"pytest@8.3.4", "tomli@2.2.1"}, result.Graph.Edges[""])
+}
+
+func TestPythonScanner_ScanErrors(t *testing.T) {
+	s := NewScanner()
+	_, err := s.ScanDependenciesFS(context.Background(), fstest.MapFS{}, scanners.DefaultScanOptions())
+	assert.ErrorIs(t, err, scanners.ErrProjectNotFound)
+
+	_, err = s.ScanDependenciesFS(context.Background(), fstest.MapFS{"broken-1.0.dist-info/METADATA": metadataFile("Summary: no name")}, scanners.DefaultScanOptions())
+	assert.ErrorIs(t, err, scanners.ErrInvalidProject)
+}
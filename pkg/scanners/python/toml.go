@@ -0,0 +1,433 @@
+package python
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseTOML reads the subset of TOML that lockfiles and pyproject.toml are
+// written in: tables, arrays of tables, dotted keys, strings, arrays and
+// inline tables. Tables decode to map[string]any and arrays to []any;
+// booleans decode to bool, while numbers and dates are kept as the text they
+// are written as. Dates separated from times by a space are not supported.
+func parseTOML(data string) (map[string]any, error) {
+	p := &tomlParser{data: data, line: 1}
+	root := make(map[string]any)
+	table := root
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return root, nil
+		}
+		var err error
+		if p.peek() == '[' {
+			table, err = p.header(root)
+		} else {
+			err = p.keyValue(table)
+		}
+		if err == nil {
+			p.skipSpace(false)
+			if !p.eof() && p.peek() != '\n' && p.peek() != '\r' {
+				err = fmt.Errorf("unexpected %q", p.peek())
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", p.line, err)
+		}
+	}
+}
+
+type tomlParser struct {
+	data string
+	pos  int
+	line int
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.data)
+}
+
+// peek returns the next byte, or 0 at the end of the data
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.data[p.pos]
+}
+
+func (p *tomlParser) rest() string {
+	return p.data[p.pos:]
+}
+
+// skipSpace skips whitespace and comments, and newlines too if asked
+func (p *tomlParser) skipSpace(newlines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t':
+			p.pos++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		case newlines && c == '\r':
+			p.pos++
+		case newlines && c == '\n':
+			p.pos++
+			p.line++
+		default:
+			return
+		}
+	}
+}
+
+// header reads a [table] or [[array.of.tables]] header, returning the table
+// the key/value pairs after it go to
+func (p *tomlParser) header(root map[string]any) (map[string]any, error) {
+	array := strings.HasPrefix(p.rest(), "[[")
+	closing := "]"
+	if array {
+		closing = "]]"
+	}
+	p.pos += len(closing)
+	keys, err := p.key()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace(false)
+	if !strings.HasPrefix(p.rest(), closing) {
+		return nil, errors.New("unterminated table header")
+	}
+	p.pos += len(closing)
+
+	if !array {
+		return descend(root, keys)
+	}
+	parent, err := descend(root, keys[:len(keys)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := keys[len(keys)-1]
+	list, ok := parent[last].([]any)
+	if !ok && parent[last] != nil {
+		return nil, fmt.Errorf("%s is not an array of tables", strings.Join(keys, "."))
+	}
+	table := make(map[string]any)
+	parent[last] = append(list, table)
+	return table, nil
+}
+
+// descend returns the table a dotted key names, creating missing tables. In
+// arrays of tables, keys name the last table added.
+func descend(table map[string]any, keys []string) (map[string]any, error) {
+	for _, key := range keys {
+		switch value := table[key].(type) {
+		case nil:
+			child := make(map[string]any)
+			table[key] = child
+			table = child
+		case map[string]any:
+			table = value
+		case []any:
+			child, ok := value[len(value)-1].(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%s is not a table", key)
+			}
+			table = child
+		default:
+			return nil, fmt.Errorf("%s is not a table", key)
+		}
+	}
+	return table, nil
+}
+
+// key reads a bare, quoted or dotted key
+func (p *tomlParser) key() ([]string, error) {
+	var keys []string
+	for {
+		p.skipSpace(false)
+		var key string
+		switch p.peek() {
+		case '"', '\'':
+			value, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			key = value.(string)
+		default:
+			start := p.pos
+			for !p.eof() && isBareKey(p.peek()) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, errors.New("missing key")
+			}
+			key = p.data[start:p.pos]
+		}
+		keys = append(keys, key)
+		p.skipSpace(false)
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+func isBareKey(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// keyValue reads a key = value pair into table
+func (p *tomlParser) keyValue(table map[string]any) error {
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	if p.peek() != '=' {
+		return fmt.Errorf("missing = after %s", strings.Join(keys, "."))
+	}
+	p.pos++
+	p.skipSpace(false)
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+	parent, err := descend(table, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	parent[keys[len(keys)-1]] = value
+	return nil
+}
+
+func (p *tomlParser) value() (any, error) {
+	switch rest := p.rest(); {
+	case strings.HasPrefix(rest, `"""`):
+		return p.multilineString(`"""`)
+	case strings.HasPrefix(rest, "'''"):
+		return p.multilineString("'''")
+	case strings.HasPrefix(rest, `"`):
+		return p.basicString()
+	case strings.HasPrefix(rest, "'"):
+		end := strings.IndexAny(rest[1:], "'\n")
+		if end < 0 || rest[1+end] != '\'' {
+			return nil, errors.New("unterminated string")
+		}
+		p.pos += end + 2
+		return rest[1 : 1+end], nil
+	case strings.HasPrefix(rest, "["):
+		return p.array()
+	case strings.HasPrefix(rest, "{"):
+		return p.inlineTable()
+	}
+
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(",]}# \t\r\n", rune(p.peek())) {
+		p.pos++
+	}
+	switch scalar := p.data[start:p.pos]; scalar {
+	case "":
+		return nil, errors.New("missing value")
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	default:
+		return scalar, nil
+	}
+}
+
+func (p *tomlParser) basicString() (string, error) {
+	for end := p.pos + 1; end < len(p.data); end++ {
+		switch p.data[end] {
+		case '\\':
+			end++
+		case '\n':
+			return "", errors.New("unterminated string")
+		case '"':
+			value, err := unescape(p.data[p.pos+1 : end])
+			p.pos = end + 1
+			return value, err
+		}
+	}
+	return "", errors.New("unterminated string")
+}
+
+// multilineString reads a string delimited by three double or single
+// quotes, of which the first newline is trimmed. Only the basic strings
+// delimited by double quotes have escapes.
+func (p *tomlParser) multilineString(delimiter string) (string, error) {
+	start := p.pos + len(delimiter)
+	end := start
+	for {
+		i := strings.Index(p.data[end:], delimiter)
+		if i < 0 {
+			return "", errors.New("unterminated string")
+		}
+		end += i
+		// Quotes right before the delimiter belong to the string
+		for strings.HasPrefix(p.data[end+1:], delimiter) {
+			end++
+		}
+		if delimiter == "'''" || !escaped(p.data[start:end]) {
+			break
+		}
+		end++
+	}
+	content := p.data[start:end]
+	p.pos = end + len(delimiter)
+	p.line += strings.Count(content, "\n")
+
+	content = strings.TrimPrefix(strings.TrimPrefix(content, "\r"), "\n")
+	if delimiter == "'''" {
+		return content, nil
+	}
+	return unescape(content)
+}
+
+// escaped reports whether s ends with an odd number of backslashes, which
+// escape what follows
+func escaped(s string) bool {
+	n := 0
+	for n < len(s) && s[len(s)-1-n] == '\\' {
+		n++
+	}
+	return n%2 == 1
+}
+
+// unescape replaces the escape sequences of a basic string. A backslash
+// ending a line of a multi-line string trims the whitespace after it.
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i == len(s) {
+			return "", errors.New("invalid escape at end of string")
+		}
+		switch c := s[i]; c {
+		case 'b':
+			b.WriteByte('\b')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case 'e':
+			b.WriteByte(0x1b)
+		case '"', '\\':
+			b.WriteByte(c)
+		case 'u', 'U':
+			size := 4
+			if c == 'U' {
+				size = 8
+			}
+			if i+size >= len(s) {
+				return "", fmt.Errorf("invalid escape \\%c", c)
+			}
+			code, err := strconv.ParseUint(s[i+1:i+1+size], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(code)) {
+				return "", fmt.Errorf("invalid escape \\%s", s[i:i+1+size])
+			}
+			b.WriteRune(rune(code))
+			i += size
+		case ' ', '\t', '\r', '\n':
+			j := i
+			for j < len(s) && strings.ContainsRune(" \t\r", rune(s[j])) {
+				j++
+			}
+			if j == len(s) || s[j] != '\n' {
+				return "", errors.New("invalid escape \\ before whitespace")
+			}
+			for j < len(s) && strings.ContainsRune(" \t\r\n", rune(s[j])) {
+				j++
+			}
+			i = j - 1
+		default:
+			return "", fmt.Errorf("invalid escape \\%c", c)
+		}
+	}
+	return b.String(), nil
+}
+
+func (p *tomlParser) array() ([]any, error) {
+	p.pos++
+	list := []any{}
+	for {
+		p.skipSpace(true)
+		if p.peek() == ']' {
+			p.pos++
+			return list, nil
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+		p.skipSpace(true)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return list, nil
+		default:
+			return nil, errors.New("unterminated array")
+		}
+	}
+}
+
+// inlineTable reads a { key = value, ... } table, which TOML 1.1 lets span
+// lines
+func (p *tomlParser) inlineTable() (map[string]any, error) {
+	p.pos++
+	table := make(map[string]any)
+	for {
+		p.skipSpace(true)
+		if p.peek() == '}' {
+			p.pos++
+			return table, nil
+		}
+		if err := p.keyValue(table); err != nil {
+			return nil, err
+		}
+		p.skipSpace(true)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return table, nil
+		default:
+			return nil, errors.New("unterminated inline table")
+		}
+	}
+}
+
+// tomlTable, tomlArray and tomlString return a decoded value as a table,
+// array or string, or the zero value if it is something else
+func tomlTable(value any) map[string]any {
+	table, _ := value.(map[string]any)
+	return table
+}
+
+func tomlArray(value any) []any {
+	list, _ := value.([]any)
+	return list
+}
+
+func tomlString(value any) string {
+	s, _ := value.(string)
+	return s
+}
@@ -0,0 +1,80 @@
+package python
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTOML(t *testing.T) {
+	doc, err := parseTOML(`# Comment
+version = 1
+requires-python = ">=3.12" # trailing comment
+enabled = true
+
+[project]
+name = 'literal'
+"quoted key" = "tab\there \u00e9"
+urls.homepage = "https://example.com"
+description = """
+Multi-line \
+  with a continuation and "quotes\""""
+raw = '''C:\path'''
+
+[[package]]
+name = "anyio"
+source = { registry = "https://pypi.org/simple" }
+dependencies = [
+    { name = "idna" },
+    { name = "sniffio", marker = "python_version < '3.13'" }, # comment
+]
+
+[package.optional-dependencies]
+trio = [{ name = "trio" }]
+
+[[package]]
+name = "idna"
+wheels = []
+`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "1", doc["version"])
+	assert.Equal(t, ">=3.12", doc["requires-python"])
+	assert.Equal(t, true, doc["enabled"])
+
+	project := tomlTable(doc["project"])
+	assert.Equal(t, "literal", project["name"])
+	assert.Equal(t, "tab\there é", project["quoted key"])
+	assert.Equal(t, map[string]any{"homepage": "https://example.com"}, project["urls"])
+	assert.Equal(t, `Multi-line with a continuation and "quotes"`, project["description"])
+	assert.Equal(t, `C:\path`, project["raw"])
+
+	packages := tomlArray(doc["package"])
+	if assert.Len(t, packages, 2) {
+		anyio := tomlTable(packages[0])
+		assert.Equal(t, map[string]any{"registry": "https://pypi.org/simple"}, anyio["source"])
+		assert.Equal(t, []any{
+			map[string]any{"name": "idna"},
+			map[string]any{"name": "sniffio", "marker": "python_version < '3.13'"},
+		}, anyio["dependencies"])
+		assert.Equal(t, map[string]any{"trio": []any{map[string]any{"name": "trio"}}}, anyio["optional-dependencies"])
+		assert.Equal(t, []any{}, tomlTable(packages[1])["wheels"])
+	}
+}
+
+func TestParseTOML_Errors(t *testing.T) {
+	for _, data := range []string{
+		"name",
+		"name = ",
+		"name = \"unterminated\n",
+		"[table\n",
+		"list = [1, 2\n",
+		"a = 1\n[[a]]\n",
+		"a = \"\\q\"",
+		"a = 1 b = 2",
+	} {
+		_, err := parseTOML(data)
+		assert.Error(t, err, data)
+	}
+}
@@ -0,0 +1,162 @@
+package scanners
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// Registry errors
+var (
+	ErrScannerExists   = errors.New("scanner already registered")
+	ErrScannerNotFound = errors.New("scanner not registered")
+)
+
+// registration holds a scanner together with its registry settings
+type registration struct {
+	scanner  Scanner
+	priority int
+	order    int
+	enabled  bool
+}
+
+// Registry keeps track of the scanners available to deplister
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]*registration
+	next    int
+}
+
+// NewRegistry creates an empty scanner registry
+func NewRegistry() *Registry {
+	return &Registry{
+		entries: make(map[string]*registration),
+	}
+}
+
+// defaultRegistry is used by the package level helpers
+var defaultRegistry = NewRegistry()
+
+// Register adds a scanner to the registry. Scanners with a lower priority
+// are tried first; scanners with equal priority keep their registration order.
+func (r *Registry) Register(scanner Scanner, priority int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := scanner.GetType()
+	if _, ok := r.entries[name]; ok {
+		return ErrScannerExists
+	}
+
+	r.entries[name] = &registration{
+		scanner:  scanner,
+		priority: priority,
+		order:    r.next,
+		enabled:  true,
+	}
+	r.next++
+	return nil
+}
+
+// Enable marks a registered scanner as enabled
+func (r *Registry) Enable(name string) error {
+	return r.setEnabled(name, true)
+}
+
+// Disable marks a registered scanner as disabled so All skips it
+func (r *Registry) Disable(name string) error {
+	return r.setEnabled(name, false)
+}
+
+func (r *Registry) setEnabled(name string, enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[name]
+	if !ok {
+		return ErrScannerNotFound
+	}
+	entry.enabled = enabled
+	return nil
+}
+
+// Get returns the scanner registered under the given type
+func (r *Registry) Get(name string) (Scanner, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.entries[name]
+	if !ok {
+		return nil, false
+	}
+	return entry.scanner, true
+}
+
+// All returns the enabled scanners in detection order
+func (r *Registry) All() []Scanner {
+	var result []Scanner
+	for _, entry := range r.sorted() {
+		if entry.enabled {
+			result = append(result, entry.scanner)
+		}
+	}
+	return result
+}
+
+// Types returns the types of all enabled scanners in detection order
+func (r *Registry) Types() []string {
+	var types []string
+	for _, scanner := range r.All() {
+		types = append(types, scanner.GetType())
+	}
+	return types
+}
+
+// sorted returns copies of the registrations in detection order, taken
+// under the lock so that Enable and Disable may run concurrently
+func (r *Registry) sorted() []registration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]registration, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].priority != entries[j].priority {
+			return entries[i].priority < entries[j].priority
+		}
+		return entries[i].order < entries[j].order
+	})
+	return entries
+}
+
+// Register adds a scanner to the default registry
+func Register(scanner Scanner, priority int) error {
+	return defaultRegistry.Register(scanner, priority)
+}
+
+// Enable enables a scanner in the default registry
+func Enable(name string) error {
+	return defaultRegistry.Enable(name)
+}
+
+// Disable disables a scanner in the default registry
+func Disable(name string) error {
+	return defaultRegistry.Disable(name)
+}
+
+// Get returns a scanner from the default registry
+func Get(name string) (Scanner, bool) {
+	return defaultRegistry.Get(name)
+}
+
+// All returns the enabled scanners of the default registry in detection order
+func All() []Scanner {
+	return defaultRegistry.All()
+}
+
+// Types returns the enabled scanner types of the default registry
+func Types() []string {
+	return defaultRegistry.Types()
+}
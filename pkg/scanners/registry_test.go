@@ -0,0 +1,69 @@
+package scanners
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Ordering(t *testing.T) {
+	registry := NewRegistry()
+
+	assert.NoError(t, registry.Register(NewMockScanner("go"), 20))
+	assert.NoError(t, registry.Register(NewMockScanner("npm"), 10))
+	assert.NoError(t, registry.Register(NewMockScanner("custom"), 20))
+
+	assert.Equal(t, []string{"npm", "go", "custom"}, registry.Types())
+}
+
+func TestRegistry_Duplicate(t *testing.T) {
+	registry := NewRegistry()
+
+	assert.NoError(t, registry.Register(NewMockScanner("npm"), 10))
+	assert.ErrorIs(t, registry.Register(NewMockScanner("npm"), 5), ErrScannerExists)
+	assert.Len(t, registry.All(), 1)
+}
+
+func TestRegistry_EnableDisable(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register(NewMockScanner("npm"), 10))
+	assert.NoError(t, registry.Register(NewMockScanner("go"), 20))
+
+	assert.NoError(t, registry.Disable("npm"))
+	assert.Equal(t, []string{"go"}, registry.Types())
+
+	_, ok := registry.Get("npm")
+	assert.True(t, ok, "disabled scanner should still be retrievable")
+
+	assert.NoError(t, registry.Enable("npm"))
+	assert.Equal(t, []string{"npm", "go"}, registry.Types())
+
+	assert.ErrorIs(t, registry.Disable("maven"), ErrScannerNotFound)
+	assert.ErrorIs(t, registry.Enable("maven"), ErrScannerNotFound)
+}
+
+func TestRegistry_Concurrent(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Register(NewMockScanner("npm"), 10))
+	assert.NoError(t, registry.Register(NewMockScanner("go"), 20))
+
+	// Run with -race: All reads what Disable and Enable write
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			registry.Disable("npm")
+			registry.Enable("npm")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			assert.Contains(t, registry.Types(), "go")
+		}
+	}()
+	wg.Wait()
+	assert.Equal(t, []string{"npm", "go"}, registry.Types())
+}
@@ -3,6 +3,9 @@ package scanners
 import (
 	"context"
 	"errors"
+	"io/fs"
+	"slices"
+	"sort"
 )
 
 // Common errors
@@ -23,33 +26,243 @@ type Dependency struct {
 	Name        string            // Name of the dependency
 	Version     string            // Version of the dependency
 	Type        string            // Type of dependency (npm, go, etc.)
+	License     string            // SPDX license identifier or expression, when known
 	IsDirectDep bool              // Whether this is a direct dependency
 	Parent      string            // Immediate parent dependency
 	Parents     []string          // All direct parent dependencies
 	Paths       []DependencyPath  // All possible paths to this dependency
 	Properties  map[string]string // Additional properties specific to the dependency type
 	Depth       int               // Minimum depth in the dependency tree
+
+	Vulnerabilities []Vulnerability // Known vulnerabilities, filled in by the "vulns" enrichment
+}
+
+// Vulnerability is a security advisory affecting a dependency
+type Vulnerability struct {
+	ID            string   // Advisory ID, e.g. GHSA-xxxx-xxxx-xxxx
+	Aliases       []string // Other IDs of the same advisory, e.g. CVE IDs
+	Summary       string   // Short description
+	Severity      string   // LOW, MEDIUM, HIGH or CRITICAL when known
+	CVSS          string   // CVSS vector, when published
+	FixedVersions []string // Versions fixing the vulnerability
+}
+
+// Finding severities
+const (
+	SeverityCritical = "critical"
+	SeverityError    = "error"
+	SeverityWarning  = "warning"
+)
+
+// Finding is an issue found in a scan, such as a policy violation
+type Finding struct {
+	Rule       string // Rule or check that produced the finding
+	Severity   string // SeverityCritical, SeverityError or SeverityWarning
+	Dependency string // Name of the affected dependency, if any
+	Version    string // Version of the affected dependency, if any
+	Message    string // Human readable description
+}
+
+// IgnoredFinding is a finding or vulnerability accepted as a known risk,
+// reported without failing the scan
+type IgnoredFinding struct {
+	Finding
+	Advisory      string // ID of the ignored vulnerability, if any
+	Justification string // Why the risk was accepted
+	Expires       string // Date the acceptance ends as YYYY-MM-DD, if any
 }
 
 // ScanResult contains the results of a dependency scan
 type ScanResult struct {
+	Project      *Project // The scanned project itself, nil when its manifest does not name it
 	Dependencies []Dependency
 	Graph        *DependencyGraph
+	Findings     []Finding
+	Ignored      []IgnoredFinding
+	Metadata     map[string]string // Facts about the project as a whole, such as its lockfile version
+	Errors       []ScanError       // Steps that failed without failing the scan, leaving the result partial
+}
+
+// ScanError is a step of a scan that failed without failing the scan as a
+// whole. The result lacks what the step would have added.
+type ScanError struct {
+	Step    string // What failed, such as a command
+	Message string // Why it failed
 }
 
-// DependencyGraph represents the complete dependency structure
+func (e ScanError) Error() string {
+	return e.Step + ": " + e.Message
+}
+
+// Project identifies the scanned project, the subject of its dependencies
+type Project struct {
+	Name    string // Package name or Go module path
+	Version string // Version the manifest declares, "" for none
+}
+
+// DependencyGraph represents the complete dependency structure. Nodes and
+// edges are keyed by NodeKey, so a package present at several versions has a
+// node per version.
 type DependencyGraph struct {
 	Nodes map[string]*Dependency
 	Edges map[string][]string
+	Root  string // Key of the project, where every path starts: "" for npm, the main module for Go
+
+	parents map[string][]string // Reverse of Edges, built on first use
+}
+
+// RootLabel names the project in path output when it has no name
+const RootLabel = "(project)"
+
+// DisplayPath returns the node keys along a path for output. Scanners key
+// the project differently, so a path starting at the graph's root starts
+// with the project's name instead, or RootLabel when it has none.
+func (r *ScanResult) DisplayPath(path []string) []string {
+	root := ""
+	if r.Graph != nil {
+		root = r.Graph.Root
+	}
+	if len(path) == 0 || path[0] != root {
+		return path
+	}
+	label := RootLabel
+	if r.Project != nil && r.Project.Name != "" {
+		label = r.Project.Name
+	}
+	return append([]string{label}, path[1:]...)
+}
+
+// SyncGraph copies what enrichment sets on the dependencies, their license,
+// vulnerabilities and properties, to the graph nodes of the same package
+// versions when the graph holds copies of them
+func (r *ScanResult) SyncGraph() {
+	if r.Graph == nil {
+		return
+	}
+	for i := range r.Dependencies {
+		dep := &r.Dependencies[i]
+		if node, ok := r.Graph.Node(dep.Name, dep.Version); ok && node != dep {
+			node.License = dep.License
+			node.Vulnerabilities = dep.Vulnerabilities
+			node.Properties = dep.Properties
+		}
+	}
+}
+
+// NodeKey returns the graph key of a package version, name@version. Project
+// roots, which have no version, are keyed by name alone.
+func NodeKey(name, version string) string {
+	if version == "" {
+		return name
+	}
+	return name + "@" + version
+}
+
+// Node returns the graph node of a package version
+func (g *DependencyGraph) Node(name, version string) (*Dependency, bool) {
+	node, ok := g.Nodes[NodeKey(name, version)]
+	return node, ok
+}
+
+// AddEdge records that the node parent depends on the node child, keeping
+// the reverse index used by Parents up to date. Existing edges are ignored.
+func (g *DependencyGraph) AddEdge(parent, child string) {
+	if g.Edges == nil {
+		g.Edges = make(map[string][]string)
+	}
+	if slices.Contains(g.Edges[parent], child) {
+		return
+	}
+	g.Edges[parent] = append(g.Edges[parent], child)
+	if g.parents != nil {
+		list := g.parents[child]
+		i, _ := slices.BinarySearch(list, parent)
+		g.parents[child] = slices.Insert(list, i, parent)
+	}
+}
+
+// Children returns the keys of the nodes the node with the given key depends on
+func (g *DependencyGraph) Children(key string) []string {
+	return g.Edges[key]
+}
+
+// Parents returns the keys of the nodes depending directly on the node with
+// the given key, sorted. The reverse index is built from Edges on the first
+// call and maintained by AddEdge; edges added to Edges directly afterwards
+// are not indexed. The first call must not race with other graph access.
+func (g *DependencyGraph) Parents(key string) []string {
+	if g.parents == nil {
+		g.parents = make(map[string][]string)
+		for parent, children := range g.Edges {
+			for _, child := range children {
+				g.parents[child] = append(g.parents[child], parent)
+			}
+		}
+		for _, list := range g.parents {
+			slices.Sort(list)
+		}
+	}
+	return g.parents[key]
 }
 
 // Scanner interface defines the methods required for a dependency scanner
 type Scanner interface {
 	DetectProject(ctx context.Context, dir string) bool
-	ScanDependencies(ctx context.Context, dir string) (*ScanResult, error)
+	ScanDependencies(ctx context.Context, dir string, opts ScanOptions) (*ScanResult, error)
 	GetType() string
 }
 
+// FSScanner is implemented by scanners that can read a project from any fs.FS,
+// such as archives, git trees or in-memory file systems
+type FSScanner interface {
+	Scanner
+	DetectProjectFS(ctx context.Context, fsys fs.FS) bool
+	ScanDependenciesFS(ctx context.Context, fsys fs.FS, opts ScanOptions) (*ScanResult, error)
+}
+
+// CacheableScanner is implemented by scanners whose result only depends on
+// the contents of a few project files, so that it can be reused while they
+// are unchanged
+type CacheableScanner interface {
+	Scanner
+	CacheFiles() []string // Paths of the files the result depends on, relative to the project
+}
+
+// PartlyCacheableScanner is implemented by cacheable scanners some of whose
+// options make the result depend on more files than CacheFiles names, such as
+// the sources of the project. Results scanned with those options are never
+// cached.
+type PartlyCacheableScanner interface {
+	CacheableScanner
+	Cacheable(opts ScanOptions) bool // Whether CacheFiles is all a scan with opts depends on
+}
+
+// StreamScanner is implemented by scanners that can pass dependencies to a
+// callback as they are resolved, without collecting them in a ScanResult.
+// Scanning stops at the first error the callback returns, which is returned.
+type StreamScanner interface {
+	Scanner
+	ScanDependenciesStream(ctx context.Context, dir string, opts ScanOptions, fn func(Dependency) error) error
+}
+
+// Stream passes the dependencies of the project in dir to fn, as they are
+// resolved when the scanner supports streaming and after a full scan otherwise
+func Stream(ctx context.Context, scanner Scanner, dir string, opts ScanOptions, fn func(Dependency) error) error {
+	if streamer, ok := scanner.(StreamScanner); ok {
+		return streamer.ScanDependenciesStream(ctx, dir, opts, fn)
+	}
+	result, err := scanner.ScanDependencies(ctx, dir, opts)
+	if err != nil {
+		return err
+	}
+	for _, dep := range result.Dependencies {
+		if err := fn(dep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // BaseScanner provides common functionality for scanners
 type BaseScanner struct {
 	scannerType string
@@ -67,64 +280,460 @@ func (s BaseScanner) GetType() string {
 	return s.scannerType
 }
 
-// Helper functions for graph operations
-func (g *DependencyGraph) FindAllPaths(from, to string) []DependencyPath {
-	visited := make(map[string]bool)
+// ShortestPaths returns a shortest path from a node to every node reachable
+// from it, found by a single breadth-first search. Where several paths are
+// equally short, the one through the earliest edges wins.
+func (g *DependencyGraph) ShortestPaths(from string) map[string]DependencyPath {
+	parent := map[string]string{from: ""}
+	order := []string{from}
+	for i := 0; i < len(order); i++ {
+		for _, child := range g.Edges[order[i]] {
+			if _, seen := parent[child]; !seen {
+				parent[child] = order[i]
+				order = append(order, child)
+			}
+		}
+	}
+
+	paths := make(map[string]DependencyPath, len(order))
+	paths[from] = DependencyPath{Path: []string{from}}
+	// Parents are visited before their children, so their paths exist
+	for _, key := range order[1:] {
+		prefix := paths[parent[key]].Path
+		path := make([]string, len(prefix), len(prefix)+1)
+		copy(path, prefix)
+		paths[key] = DependencyPath{Path: append(path, key), Depth: len(prefix)}
+	}
+	return paths
+}
+
+// FindPaths returns up to limit paths between two nodes that visit no node
+// twice, shortest first. A non-positive limit returns every path, whose
+// number grows exponentially with the size of real npm graphs.
+func (g *DependencyGraph) FindPaths(from, to string, limit int) []DependencyPath {
+	// Nodes that cannot reach the target within the remaining length are
+	// pruned using the distances to it along reversed edges
+	distance := map[string]int{to: 0}
+	queue := []string{to}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, parent := range g.Parents(current) {
+			if _, seen := distance[parent]; !seen {
+				distance[parent] = distance[current] + 1
+				queue = append(queue, parent)
+			}
+		}
+	}
+	shortest, ok := distance[from]
+	if !ok {
+		return nil
+	}
+
 	var paths []DependencyPath
-	g.findPaths(from, to, []string{}, visited, &paths)
+	visited := make(map[string]bool)
+	longer := false // Whether a longer path may exist
+	var walk func(current string, path []string, length int)
+	walk = func(current string, path []string, length int) {
+		path = append(path, current)
+		if current == to {
+			if len(path)-1 == length {
+				paths = append(paths, DependencyPath{Path: append([]string{}, path...), Depth: length})
+			}
+			return
+		}
+		visited[current] = true
+		for _, next := range g.Edges[current] {
+			if limit > 0 && len(paths) >= limit {
+				break
+			}
+			d, ok := distance[next]
+			if !ok || visited[next] {
+				continue
+			}
+			if len(path)+d > length {
+				longer = true
+				continue
+			}
+			walk(next, path, length)
+		}
+		visited[current] = false
+	}
+	for length := shortest; limit <= 0 || len(paths) < limit; length++ {
+		longer = false
+		walk(from, nil, length)
+		if !longer {
+			break
+		}
+	}
 	return paths
 }
 
-func (g *DependencyGraph) findPaths(current, target string, path []string, visited map[string]bool, results *[]DependencyPath) {
-	if visited[current] {
-		return
+// Path modes of ScanOptions.Paths
+const (
+	PathsNone     = "none"     // No paths, dropped once enrichments needing them ran
+	PathsShortest = "shortest" // The shortest path, or the MaxPaths shortest
+	PathsAll      = "all"      // Every path visiting no package twice, at most MaxPaths when above 1
+)
+
+// PathModes lists the path modes
+var PathModes = []string{PathsNone, PathsShortest, PathsAll}
+
+// Paths returns the paths from root to a dependency to record in its Paths
+// field, and the depth of the shortest one or -1 when it is unreachable.
+// shortest holds the ShortestPaths from root; further paths are only
+// enumerated when opts.MaxPaths asks for more than one, or opts.Paths for all.
+func Paths(g *DependencyGraph, shortest map[string]DependencyPath, root, key string, opts ScanOptions) ([]DependencyPath, int) {
+	path, ok := shortest[key]
+	if !ok {
+		return nil, -1
+	}
+	if opts.MaxPaths > 1 || opts.Paths == PathsAll {
+		limit := opts.MaxPaths
+		if limit <= 1 {
+			limit = 0
+		}
+		return g.FindPaths(root, key, limit), path.Depth
 	}
+	return []DependencyPath{path}, path.Depth
+}
 
-	newPath := append(path, current)
+// FindAllPaths returns every path between two nodes that visits no node
+// twice, shortest first. Use FindPaths to cap their number on large graphs.
+func (g *DependencyGraph) FindAllPaths(from, to string) []DependencyPath {
+	return g.FindPaths(from, to, 0)
+}
 
-	if current == target {
-		*results = append(*results, DependencyPath{
-			Path:  append([]string{}, newPath...),
-			Depth: len(newPath) - 1,
-		})
-		return
+// Dependent is a package depending on another one, directly or transitively
+type Dependent struct {
+	Key   string // Node key of the dependent package
+	Depth int    // Number of edges to the depended on package, 1 for direct dependents
+	Via   string // Node key of the dependency through which it depends on the package
+}
+
+// Dependents returns every package that depends on the node with the given
+// key, directly or transitively, nearest first and by key within a depth.
+// The project root is left out.
+func (g *DependencyGraph) Dependents(key string) []Dependent {
+	var dependents []Dependent
+	seen := map[string]bool{key: true}
+	level := []string{key}
+	for depth := 1; len(level) > 0; depth++ {
+		var next []Dependent
+		for _, current := range level {
+			for _, parent := range g.Parents(current) {
+				if parent == g.Root || seen[parent] {
+					continue
+				}
+				seen[parent] = true
+				next = append(next, Dependent{Key: parent, Depth: depth, Via: current})
+			}
+		}
+		sort.Slice(next, func(i, j int) bool { return next[i].Key < next[j].Key })
+
+		level = level[:0]
+		for _, dependent := range next {
+			level = append(level, dependent.Key)
+		}
+		dependents = append(dependents, next...)
+	}
+	return dependents
+}
+
+// Cycles returns one cycle per group of packages that depend on each other,
+// as the node keys along the shortest cycle through the group's smallest key,
+// which starts and ends the cycle. Cycles are sorted by their first key.
+func (g *DependencyGraph) Cycles() [][]string {
+	var keys []string
+	for parent, children := range g.Edges {
+		keys = append(keys, parent)
+		keys = append(keys, children...)
+	}
+	sort.Strings(keys)
+	keys = slices.Compact(keys)
+
+	// Tarjan's algorithm finds the strongly connected components
+	var (
+		index      = make(map[string]int)
+		low        = make(map[string]int)
+		onStack    = make(map[string]bool)
+		stack      []string
+		components [][]string
+		visit      func(key string)
+	)
+	visit = func(key string) {
+		index[key] = len(index)
+		low[key] = index[key]
+		stack = append(stack, key)
+		onStack[key] = true
+
+		for _, child := range g.Edges[key] {
+			if _, ok := index[child]; !ok {
+				visit(child)
+				low[key] = min(low[key], low[child])
+			} else if onStack[child] {
+				low[key] = min(low[key], index[child])
+			}
+		}
+
+		if low[key] != index[key] {
+			return
+		}
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == key {
+				break
+			}
+		}
+		if len(component) > 1 || slices.Contains(g.Edges[key], key) {
+			components = append(components, component)
+		}
+	}
+	for _, key := range keys {
+		if _, ok := index[key]; !ok {
+			visit(key)
+		}
 	}
 
-	visited[current] = true
-	for _, next := range g.Edges[current] {
-		g.findPaths(next, target, newPath, visited, results)
+	var cycles [][]string
+	for _, component := range components {
+		slices.Sort(component)
+		cycles = append(cycles, g.shortestCycle(component[0], component))
 	}
-	visited[current] = false
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
 }
 
-// CalculateDepth returns the minimum depth of a dependency
-func (g *DependencyGraph) CalculateDepth(name string) int {
-	visited := make(map[string]bool)
-	return g.calculateMinDepth(name, visited)
+// shortestCycle returns the shortest path from start back to itself within
+// a strongly connected component
+func (g *DependencyGraph) shortestCycle(start string, component []string) []string {
+	prev := make(map[string]string)
+	queue := []string{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range g.Edges[current] {
+			if !slices.Contains(component, child) {
+				continue
+			}
+			if child == start {
+				cycle := []string{start}
+				for key := current; key != start; key = prev[key] {
+					cycle = append(cycle, key)
+				}
+				cycle = append(cycle, start)
+				slices.Reverse(cycle)
+				return cycle
+			}
+			if _, ok := prev[child]; !ok {
+				prev[child] = current
+				queue = append(queue, child)
+			}
+		}
+	}
+	return nil
 }
 
-func (g *DependencyGraph) calculateMinDepth(name string, visited map[string]bool) int {
-	if visited[name] {
-		return -1
+// Roots returns the keys of the project roots: keys with dependencies but no
+// dependents, such as "" for npm or the main module for Go
+func (g *DependencyGraph) Roots() []string {
+	children := make(map[string]bool)
+	for _, list := range g.Edges {
+		for _, child := range list {
+			children[child] = true
+		}
 	}
+	var roots []string
+	for parent := range g.Edges {
+		if !children[parent] {
+			roots = append(roots, parent)
+		}
+	}
+	sort.Strings(roots)
+	return roots
+}
 
-	visited[name] = true
-	minDepth := -1
+// Hub counts the packages depending on a package
+type Hub struct {
+	Key        string // Node key of the package
+	Direct     int    // Packages requiring it directly
+	Transitive int    // Packages depending on it directly or transitively
+}
 
+// Hubs returns every package other packages depend on, most transitive
+// dependents first. The project roots are not counted as dependents, so the
+// counts are the packages affected by removing or compromising the package.
+func (g *DependencyGraph) Hubs() []Hub {
+	roots := g.Roots()
+	direct := make(map[string]int)
 	for parent, children := range g.Edges {
+		if slices.Contains(roots, parent) {
+			continue
+		}
 		for _, child := range children {
-			if child == name {
-				parentDepth := g.calculateMinDepth(parent, visited)
-				if parentDepth != -1 {
-					depth := parentDepth + 1
-					if minDepth == -1 || depth < minDepth {
-						minDepth = depth
-					}
-				}
+			direct[child]++
+		}
+	}
+
+	var hubs []Hub
+	for key, count := range direct {
+		transitive := 0
+		for _, dependent := range g.Dependents(key) {
+			if !slices.Contains(roots, dependent.Key) {
+				transitive++
+			}
+		}
+		hubs = append(hubs, Hub{Key: key, Direct: count, Transitive: transitive})
+	}
+	sort.Slice(hubs, func(i, j int) bool {
+		if hubs[i].Transitive != hubs[j].Transitive {
+			return hubs[i].Transitive > hubs[j].Transitive
+		}
+		if hubs[i].Direct != hubs[j].Direct {
+			return hubs[i].Direct > hubs[j].Direct
+		}
+		return hubs[i].Key < hubs[j].Key
+	})
+	return hubs
+}
+
+// LongestChains returns the shortest paths from the project roots to the n
+// deepest packages, deepest first. Every package on such a chain must be
+// resolved before the package at its end, so they bound how far a fix has
+// to travel up the graph.
+func (g *DependencyGraph) LongestChains(n int) []DependencyPath {
+	depths, prev := g.levels()
+	keys := make([]string, 0, len(prev))
+	for key := range prev {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if depths[keys[i]] != depths[keys[j]] {
+			return depths[keys[i]] > depths[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	var chains []DependencyPath
+	for _, key := range keys[:min(n, len(keys))] {
+		path := []string{key}
+		for current := key; ; {
+			parent, ok := prev[current]
+			if !ok {
+				break
 			}
+			path = append(path, parent)
+			current = parent
 		}
+		slices.Reverse(path)
+		chains = append(chains, DependencyPath{Path: path, Depth: depths[key]})
 	}
+	return chains
+}
 
-	visited[name] = false
-	return minDepth
+// levels finds the minimum depth of every node reachable from the project
+// roots with a breadth-first search from all of them, and the node through
+// which each non-root node was first reached
+func (g *DependencyGraph) levels() (map[string]int, map[string]string) {
+	prev := make(map[string]string)
+	depths := make(map[string]int)
+	queue := g.Roots()
+	for _, root := range queue {
+		depths[root] = 0
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range g.Edges[current] {
+			if _, ok := depths[child]; !ok {
+				depths[child] = depths[current] + 1
+				prev[child] = current
+				queue = append(queue, child)
+			}
+		}
+	}
+	return depths, prev
+}
+
+// Depths returns the minimum number of edges from the project roots to every
+// node reachable from them, 0 for the roots themselves, in a single walk of
+// the graph
+func (g *DependencyGraph) Depths() map[string]int {
+	depths, _ := g.levels()
+	return depths
+}
+
+// CountByDepth returns the number of packages at each minimum depth, 1 for
+// direct dependencies. The project roots are not counted.
+func (g *DependencyGraph) CountByDepth() map[int]int {
+	counts := make(map[int]int)
+	for _, depth := range g.Depths() {
+		if depth > 0 {
+			counts[depth]++
+		}
+	}
+	return counts
+}
+
+// MaxDepth returns the minimum depth of the deepest package, 0 for a graph
+// without dependencies
+func (g *DependencyGraph) MaxDepth() int {
+	deepest := 0
+	for _, depth := range g.Depths() {
+		deepest = max(deepest, depth)
+	}
+	return deepest
+}
+
+// TopologicalOrder returns the keys of every node in the graph with each
+// package after the packages it depends on, the order to build or install
+// them in. Packages in a cycle cannot all come after each other, so the
+// cycle is broken at the edge leading back to where it was entered. The
+// order is deterministic: nodes and their children are visited by key.
+func (g *DependencyGraph) TopologicalOrder() []string {
+	var keys []string
+	for parent, children := range g.Edges {
+		keys = append(keys, parent)
+		keys = append(keys, children...)
+	}
+	for key := range g.Nodes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	keys = slices.Compact(keys)
+
+	order := make([]string, 0, len(keys))
+	visited := make(map[string]bool, len(keys))
+	var visit func(key string)
+	visit = func(key string) {
+		visited[key] = true
+		children := slices.Clone(g.Edges[key])
+		slices.Sort(children)
+		for _, child := range children {
+			if !visited[child] {
+				visit(child)
+			}
+		}
+		order = append(order, key)
+	}
+	for _, key := range keys {
+		if !visited[key] {
+			visit(key)
+		}
+	}
+	return order
+}
+
+// CalculateDepth returns the minimum depth of a node, as Depths does, or -1
+// when no project root reaches it. Callers needing the depth of many nodes
+// should call Depths once instead.
+func (g *DependencyGraph) CalculateDepth(key string) int {
+	if depth, ok := g.Depths()[key]; ok {
+		return depth
+	}
+	return -1
 }
@@ -2,6 +2,7 @@ package scanners
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -28,7 +29,7 @@ func (m *MockScanner) DetectProject(ctx context.Context, dir string) bool {
 	return m.detectResult
 }
 
-func (m *MockScanner) ScanDependencies(ctx context.Context, dir string) (*ScanResult, error) {
+func (m *MockScanner) ScanDependencies(ctx context.Context, dir string, opts ScanOptions) (*ScanResult, error) {
 	if m.scanError != nil {
 		return nil, m.scanError
 	}
@@ -169,3 +170,285 @@ func validateScanResult(t *testing.T, result ScanResult) {
 		validateDependency(t, dep)
 	}
 }
+
+func TestScanOptions_WithinDepth(t *testing.T) {
+	opts := DefaultScanOptions()
+	assert.True(t, opts.WithinDepth(10), "unlimited depth by default")
+
+	opts.MaxDepth = 2
+	assert.True(t, opts.WithinDepth(1))
+	assert.True(t, opts.WithinDepth(2))
+	assert.False(t, opts.WithinDepth(3))
+}
+
+func TestDependencyGraph_Dependents(t *testing.T) {
+	graph := &DependencyGraph{Edges: map[string][]string{
+		"":            {"express", "koa"},
+		"express":     {"body-parser", "accepts"},
+		"koa":         {"accepts"},
+		"body-parser": {"qs"},
+		"qs":          {"side-channel"},
+		"accepts":     {"mime-types"},
+		"mime-types":  {"accepts"},
+	}}
+
+	// Cycles end at the package itself
+	assert.Equal(t, []Dependent{
+		{Key: "accepts", Depth: 1, Via: "mime-types"},
+		{Key: "express", Depth: 2, Via: "accepts"},
+		{Key: "koa", Depth: 2, Via: "accepts"},
+	}, graph.Dependents("mime-types"))
+	assert.Equal(t, []Dependent{
+		{Key: "qs", Depth: 1, Via: "side-channel"},
+		{Key: "body-parser", Depth: 2, Via: "qs"},
+		{Key: "express", Depth: 3, Via: "body-parser"},
+	}, graph.Dependents("side-channel"))
+	assert.Empty(t, graph.Dependents("express"))
+	assert.Empty(t, graph.Dependents("unknown"))
+}
+
+func TestDependencyGraph_Root(t *testing.T) {
+	// Go graphs are rooted at the main module rather than ""
+	graph := &DependencyGraph{Root: "example.com/app", Edges: map[string][]string{
+		"example.com/app":  {"example.com/a@v1"},
+		"example.com/a@v1": {"example.com/b@v1"},
+	}}
+	assert.Equal(t, []Dependent{{Key: "example.com/a@v1", Depth: 1, Via: "example.com/b@v1"}}, graph.Dependents("example.com/b@v1"))
+
+	result := &ScanResult{Graph: graph, Project: &Project{Name: "example.com/app"}}
+	assert.Equal(t, []string{"example.com/app", "example.com/a@v1"}, result.DisplayPath([]string{"example.com/app", "example.com/a@v1"}))
+
+	result = &ScanResult{Graph: &DependencyGraph{}, Project: &Project{Name: "my-app", Version: "1.0.0"}}
+	assert.Equal(t, []string{"my-app", "express@4.18.2"}, result.DisplayPath([]string{"", "express@4.18.2"}))
+	assert.Equal(t, []string{"express@4.18.2"}, result.DisplayPath([]string{"express@4.18.2"}))
+	assert.Equal(t, []string{RootLabel, "express@4.18.2"}, (&ScanResult{}).DisplayPath([]string{"", "express@4.18.2"}))
+	assert.Empty(t, (&ScanResult{}).DisplayPath(nil))
+}
+
+func TestDependencyGraph_Node(t *testing.T) {
+	debug := &Dependency{Name: "debug", Version: "2.6.9"}
+	graph := &DependencyGraph{Nodes: map[string]*Dependency{
+		"debug@2.6.9": debug,
+		"debug@4.3.4": {Name: "debug", Version: "4.3.4"},
+	}}
+
+	assert.Equal(t, "debug@2.6.9", NodeKey("debug", "2.6.9"))
+	assert.Equal(t, "example.com/app", NodeKey("example.com/app", ""))
+
+	node, ok := graph.Node("debug", "2.6.9")
+	assert.True(t, ok)
+	assert.Same(t, debug, node)
+	_, ok = graph.Node("debug", "3.0.0")
+	assert.False(t, ok)
+}
+
+func TestScanResult_SyncGraph(t *testing.T) {
+	lodash := &Dependency{Name: "lodash", Version: "4.17.15"}
+	result := &ScanResult{
+		Dependencies: []Dependency{
+			{Name: "lodash", Version: "4.17.15", License: "MIT", Properties: map[string]string{"latest": "4.17.21"}, Vulnerabilities: []Vulnerability{{ID: "GHSA-1"}}},
+			{Name: "left-pad", Version: "1.3.0", License: "WTFPL"},
+		},
+		Graph: &DependencyGraph{Nodes: map[string]*Dependency{"lodash@4.17.15": lodash}},
+	}
+
+	result.SyncGraph()
+	assert.Equal(t, "MIT", lodash.License)
+	assert.Equal(t, map[string]string{"latest": "4.17.21"}, lodash.Properties)
+	assert.Equal(t, []Vulnerability{{ID: "GHSA-1"}}, lodash.Vulnerabilities)
+	assert.Len(t, result.Graph.Nodes, 1)
+
+	(&ScanResult{Dependencies: result.Dependencies}).SyncGraph()
+}
+
+func TestDependencyGraph_Cycles(t *testing.T) {
+	graph := &DependencyGraph{Edges: map[string][]string{
+		"":      {"a@1", "d@1", "s@1"},
+		"a@1":   {"b@1"},
+		"b@1":   {"c@1", "x@1"},
+		"c@1":   {"a@1", "b@1"},
+		"d@1":   {"e@1"},
+		"e@1":   {"d@1"},
+		"s@1":   {"s@1"},
+		"x@1":   {"y@1"},
+		"y@1":   {},
+		"z@1":   {"a@1"},
+		"end@1": nil,
+	}}
+
+	assert.Equal(t, [][]string{
+		{"a@1", "b@1", "c@1", "a@1"},
+		{"d@1", "e@1", "d@1"},
+		{"s@1", "s@1"},
+	}, graph.Cycles())
+	assert.Empty(t, (&DependencyGraph{Edges: map[string][]string{"": {"a@1"}}}).Cycles())
+}
+
+func TestDependencyGraph_Hubs(t *testing.T) {
+	graph := &DependencyGraph{Edges: map[string][]string{
+		"example.com/app":  {"example.com/a@v1", "example.com/b@v1"},
+		"example.com/a@v1": {"example.com/c@v1"},
+		"example.com/b@v1": {"example.com/c@v1", "example.com/d@v1"},
+		"example.com/c@v1": {"example.com/e@v1"},
+		"example.com/d@v1": {"example.com/e@v1"},
+		"example.com/e@v1": {},
+	}}
+
+	assert.Equal(t, []string{"example.com/app"}, graph.Roots())
+	assert.Equal(t, []Hub{
+		{Key: "example.com/e@v1", Direct: 2, Transitive: 4},
+		{Key: "example.com/c@v1", Direct: 2, Transitive: 2},
+		{Key: "example.com/d@v1", Direct: 1, Transitive: 1},
+	}, graph.Hubs())
+
+	assert.Equal(t, []DependencyPath{
+		{Path: []string{"example.com/app", "example.com/a@v1", "example.com/c@v1", "example.com/e@v1"}, Depth: 3},
+		{Path: []string{"example.com/app", "example.com/a@v1", "example.com/c@v1"}, Depth: 2},
+	}, graph.LongestChains(2))
+	assert.Len(t, graph.LongestChains(100), 5)
+}
+
+func TestDependencyGraph_Statistics(t *testing.T) {
+	graph := &DependencyGraph{Edges: map[string][]string{
+		"example.com/app":  {"example.com/a@v1", "example.com/b@v1"},
+		"example.com/a@v1": {"example.com/c@v1"},
+		"example.com/b@v1": {"example.com/c@v1", "example.com/d@v1"},
+		"example.com/c@v1": {"example.com/e@v1"},
+		"example.com/d@v1": {"example.com/e@v1"},
+		"example.com/e@v1": {},
+	}}
+
+	assert.Equal(t, map[string]int{
+		"example.com/app":  0,
+		"example.com/a@v1": 1,
+		"example.com/b@v1": 1,
+		"example.com/c@v1": 2,
+		"example.com/d@v1": 2,
+		"example.com/e@v1": 3,
+	}, graph.Depths())
+	assert.Equal(t, 3, graph.CalculateDepth("example.com/e@v1"))
+	assert.Equal(t, -1, graph.CalculateDepth("example.com/unknown@v1"))
+	assert.Equal(t, map[int]int{1: 2, 2: 2, 3: 1}, graph.CountByDepth())
+	assert.Equal(t, 3, graph.MaxDepth())
+	assert.Equal(t, []string{
+		"example.com/e@v1",
+		"example.com/c@v1",
+		"example.com/a@v1",
+		"example.com/d@v1",
+		"example.com/b@v1",
+		"example.com/app",
+	}, graph.TopologicalOrder())
+
+	// Cycles are broken where they were entered
+	cyclic := &DependencyGraph{Edges: map[string][]string{"": {"x@1"}, "x@1": {"y@1"}, "y@1": {"x@1"}}}
+	assert.Equal(t, []string{"y@1", "x@1", ""}, cyclic.TopologicalOrder())
+	assert.Equal(t, 2, cyclic.CalculateDepth("y@1"))
+	assert.Equal(t, 0, (&DependencyGraph{}).MaxDepth())
+}
+
+func TestDependencyGraph_Paths(t *testing.T) {
+	graph := &DependencyGraph{Edges: map[string][]string{
+		"":         {"a@1", "b@1"},
+		"a@1":      {"c@1"},
+		"b@1":      {"c@1", "d@1"},
+		"c@1":      {"e@1", "a@1"},
+		"d@1":      {"e@1"},
+		"orphan@1": {"e@1"},
+	}}
+
+	shortest := graph.ShortestPaths("")
+	assert.Equal(t, DependencyPath{Path: []string{"", "a@1", "c@1", "e@1"}, Depth: 3}, shortest["e@1"])
+	assert.Equal(t, DependencyPath{Path: []string{""}}, shortest[""])
+	assert.NotContains(t, shortest, "orphan@1")
+
+	all := []DependencyPath{
+		{Path: []string{"", "a@1", "c@1", "e@1"}, Depth: 3},
+		{Path: []string{"", "b@1", "c@1", "e@1"}, Depth: 3},
+		{Path: []string{"", "b@1", "d@1", "e@1"}, Depth: 3},
+		{Path: []string{"", "b@1", "c@1", "a@1"}, Depth: 3},
+	}
+	assert.Equal(t, all[:3], graph.FindAllPaths("", "e@1"))
+	assert.Equal(t, all[:2], graph.FindPaths("", "e@1", 2))
+	assert.Equal(t, []DependencyPath{{Path: []string{"", "a@1"}, Depth: 1}, all[3]}, graph.FindAllPaths("", "a@1"))
+	assert.Empty(t, graph.FindPaths("", "orphan@1", 5))
+
+	paths, depth := Paths(graph, shortest, "", "e@1", DefaultScanOptions())
+	assert.Equal(t, all[:1], paths)
+	assert.Equal(t, 3, depth)
+	paths, depth = Paths(graph, shortest, "", "e@1", ScanOptions{MaxPaths: 10})
+	assert.Equal(t, all[:3], paths)
+	assert.Equal(t, 3, depth)
+	paths, _ = Paths(graph, shortest, "", "e@1", ScanOptions{Paths: PathsAll})
+	assert.Equal(t, all[:3], paths)
+	paths, _ = Paths(graph, shortest, "", "e@1", ScanOptions{Paths: PathsAll, MaxPaths: 2})
+	assert.Equal(t, all[:2], paths)
+	paths, _ = Paths(graph, shortest, "", "e@1", ScanOptions{Paths: PathsNone})
+	assert.Equal(t, all[:1], paths)
+	paths, depth = Paths(graph, shortest, "", "orphan@1", DefaultScanOptions())
+	assert.Empty(t, paths)
+	assert.Equal(t, -1, depth)
+}
+
+func TestDependencyGraph_FindPathsScales(t *testing.T) {
+	// Each layer doubles the number of paths, 2^40 in all
+	graph := &DependencyGraph{Edges: map[string][]string{}}
+	previous := ""
+	for i := 0; i < 40; i++ {
+		left, right, join := fmt.Sprintf("l%d", i), fmt.Sprintf("r%d", i), fmt.Sprintf("j%d", i)
+		graph.Edges[previous] = []string{left, right}
+		graph.Edges[left] = []string{join}
+		graph.Edges[right] = []string{join}
+		previous = join
+	}
+
+	paths := graph.FindPaths("", previous, 3)
+	assert.Len(t, paths, 3)
+	assert.Equal(t, 80, paths[0].Depth)
+	assert.Equal(t, 80, graph.ShortestPaths("")[previous].Depth)
+}
+
+func TestDependencyGraph_ParentsAndChildren(t *testing.T) {
+	graph := &DependencyGraph{Edges: map[string][]string{
+		"":    {"b@1", "a@1"},
+		"b@1": {"c@1"},
+	}}
+	graph.AddEdge("a@1", "c@1")
+
+	assert.Equal(t, []string{"a@1", "b@1"}, graph.Parents("c@1"))
+	assert.Equal(t, []string{""}, graph.Parents("a@1"))
+	assert.Empty(t, graph.Parents(""))
+
+	// Edges added after indexing are kept in order, duplicates ignored
+	graph.AddEdge("", "c@1")
+	graph.AddEdge("b@1", "c@1")
+	assert.Equal(t, []string{"", "a@1", "b@1"}, graph.Parents("c@1"))
+	assert.Equal(t, []string{"b@1", "a@1", "c@1"}, graph.Children(""))
+	assert.Equal(t, []string{"c@1"}, graph.Children("b@1"))
+
+	empty := &DependencyGraph{}
+	empty.AddEdge("x@1", "y@1")
+	assert.Equal(t, []string{"y@1"}, empty.Children("x@1"))
+	assert.Equal(t, []string{"x@1"}, empty.Parents("y@1"))
+}
+
+func TestStream(t *testing.T) {
+	scanner := NewMockScanner("mock")
+	scanner.scanResult.Dependencies = []Dependency{{Name: "a", Version: "1.0.0"}, {Name: "b", Version: "2.0.0"}}
+
+	var names []string
+	err := Stream(context.Background(), scanner, ".", DefaultScanOptions(), func(dep Dependency) error {
+		names = append(names, dep.Name)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, names)
+
+	err = Stream(context.Background(), scanner, ".", DefaultScanOptions(), func(dep Dependency) error {
+		return ErrScanFailed
+	})
+	assert.ErrorIs(t, err, ErrScanFailed)
+
+	scanner.scanError = ErrInvalidProject
+	err = Stream(context.Background(), scanner, ".", DefaultScanOptions(), func(dep Dependency) error { return nil })
+	assert.ErrorIs(t, err, ErrInvalidProject)
+}
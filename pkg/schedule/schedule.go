@@ -0,0 +1,179 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidSchedule is returned for specs that do not parse
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule tells when something runs next
+type Schedule interface {
+	// Next returns the first time after t it runs at, or the zero time
+	// when it never runs again
+	Next(t time.Time) time.Time
+}
+
+// macros are the shorthands of cron specs
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a five field cron spec (minute, hour, day of month, month,
+// day of week) with lists, ranges, steps and month and weekday names, one of
+// the @yearly, @monthly, @weekly, @daily and @hourly macros, or
+// "@every <duration>" for a fixed interval
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || interval < time.Second {
+			return nil, fmt.Errorf("%w: %q needs a duration of at least 1s", ErrInvalidSchedule, spec)
+		}
+		return every(interval), nil
+	}
+	if expanded, ok := macros[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q has %d fields, expected minute hour day-of-month month day-of-week", ErrInvalidSchedule, spec, len(fields))
+	}
+	var c cron
+	var err error
+	if c.minute, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("%w: minute %w", ErrInvalidSchedule, err)
+	}
+	if c.hour, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("%w: hour %w", ErrInvalidSchedule, err)
+	}
+	if c.dom, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("%w: day of month %w", ErrInvalidSchedule, err)
+	}
+	if c.month, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("%w: month %w", ErrInvalidSchedule, err)
+	}
+	if c.dow, err = parseField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("%w: day of week %w", ErrInvalidSchedule, err)
+	}
+	// 7 is another name of Sunday
+	c.dow |= c.dow >> 7 & 1
+	c.domAny, c.dowAny = fields[2] == "*", fields[4] == "*"
+	return c, nil
+}
+
+var monthNames = []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+
+var dayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// parseField parses a comma separated list of *, values and ranges, each
+// with an optional /step, into a bit set of the values
+func parseField(field string, low, high int, names []string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		expr, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("step %q is not a positive number", stepText)
+			}
+			step = n
+		}
+
+		first, last := low, high
+		if expr != "*" {
+			start, end, isRange := strings.Cut(expr, "-")
+			var err error
+			if first, err = parseValue(start, low, high, names); err != nil {
+				return 0, err
+			}
+			last = first
+			if isRange {
+				if last, err = parseValue(end, low, high, names); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				last = high
+			}
+			if last < first {
+				return 0, fmt.Errorf("range %q is reversed", expr)
+			}
+		}
+		for v := first; v <= last; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(text string, low, high int, names []string) (int, error) {
+	for i, name := range names {
+		if name != "" && strings.EqualFold(text, name) {
+			return i, nil
+		}
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil || n < low || n > high {
+		return 0, fmt.Errorf("value %q is not between %d and %d", text, low, high)
+	}
+	return n, nil
+}
+
+// cron is a parsed cron spec, each field a bit set of its values
+type cron struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+// Next returns the first minute after t matching the spec, in t's location.
+// When both the day of month and the day of week are restricted, a day
+// matching either runs, as in cron.
+func (c cron) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	end := t.AddDate(5, 0, 0)
+	for t.Before(end) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.day(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c cron) day(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.domAny || c.dowAny:
+		return dom && dow
+	default:
+		return dom || dow
+	}
+}
+
+// every runs at a fixed interval
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
@@ -0,0 +1,51 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_Next(t *testing.T) {
+	// A Wednesday
+	start := time.Date(2024, 5, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 5, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 5, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 */6 * * *", time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2024, 5, 16, 2, 30, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2024, 5, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 5, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan,jul *", time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * fri", time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"5-10/5 10 * * *", time.Date(2024, 5, 15, 10, 10, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 5, 19, 0, 0, 0, 0, time.UTC)},
+		{"@every 90m", start.Add(90 * time.Minute)},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		if assert.NoError(t, err, tt.spec) {
+			assert.Equal(t, tt.want, s.Next(start), tt.spec)
+		}
+	}
+}
+
+func TestParse_Never(t *testing.T) {
+	s, err := Parse("0 0 31 2 *")
+	assert.NoError(t, err)
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "10-5 * * * *", "@every soon", "@every 10ms", "@fortnightly"} {
+		_, err := Parse(spec)
+		assert.ErrorIs(t, err, ErrInvalidSchedule, spec)
+	}
+}
@@ -0,0 +1,182 @@
+package scorecard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/httpclient"
+)
+
+// DefaultAPIURL is the deps.dev API
+const DefaultAPIURL = "https://api.deps.dev"
+
+// Common errors
+var (
+	ErrNotFound      = errors.New("package not found on deps.dev")
+	ErrRequestFailed = errors.New("deps.dev request failed")
+)
+
+// Project is the source repository of a package with its health signals
+type Project struct {
+	ID         string // Repository, such as "github.com/expressjs/express"
+	Stars      int
+	Forks      int
+	OpenIssues int
+	Scorecard  *Scorecard // Nil when the repository has no OpenSSF Scorecard
+}
+
+// Scorecard is an OpenSSF Scorecard result
+type Scorecard struct {
+	Date    time.Time
+	Score   float64           // Overall score from 0 to 10
+	Checks  map[string]int    // Score of each check from 0 to 10, -1 when inconclusive
+	Reasons map[string]string // Reason given for each check
+}
+
+// Source looks up the source project of package versions
+type Source interface {
+	// Project returns the source project of a package version, or
+	// ErrNotFound when it is unknown
+	Project(ctx context.Context, depType, name, version string) (*Project, error)
+}
+
+// Client queries the deps.dev API
+type Client struct {
+	APIURL     string
+	HTTPClient *http.Client
+
+	mu       sync.Mutex
+	projects map[string]*Project // Projects by ID, shared by packages of a repository
+}
+
+// NewClient creates a client for deps.dev
+func NewClient() *Client {
+	return &Client{
+		APIURL:     DefaultAPIURL,
+		HTTPClient: httpclient.Default,
+	}
+}
+
+// systems maps dependency types to deps.dev package systems
+var systems = map[string]string{
+	"npm":  "NPM",
+	"go":   "GO",
+	"pypi": "PYPI",
+}
+
+type versionResponse struct {
+	RelatedProjects []struct {
+		ProjectKey struct {
+			ID string `json:"id"`
+		} `json:"projectKey"`
+		RelationType string `json:"relationType"`
+	} `json:"relatedProjects"`
+}
+
+type projectResponse struct {
+	OpenIssuesCount int `json:"openIssuesCount"`
+	StarsCount      int `json:"starsCount"`
+	ForksCount      int `json:"forksCount"`
+	Scorecard       *struct {
+		Date         time.Time `json:"date"`
+		OverallScore float64   `json:"overallScore"`
+		Checks       []struct {
+			Name   string `json:"name"`
+			Score  int    `json:"score"`
+			Reason string `json:"reason"`
+		} `json:"checks"`
+	} `json:"scorecard"`
+}
+
+// Project returns the source repository deps.dev associates with a package
+// version and its health signals
+func (c *Client) Project(ctx context.Context, depType, name, version string) (*Project, error) {
+	system, ok := systems[depType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s", ErrNotFound, depType, name)
+	}
+
+	var v versionResponse
+	path := fmt.Sprintf("/v3/systems/%s/packages/%s/versions/%s", system, url.PathEscape(name), url.PathEscape(version))
+	if err := c.get(ctx, path, &v); err != nil {
+		return nil, err
+	}
+
+	for _, related := range v.RelatedProjects {
+		if related.RelationType == "SOURCE_REPO" {
+			return c.project(ctx, related.ProjectKey.ID)
+		}
+	}
+	return nil, fmt.Errorf("%w: no source repository for %s@%s", ErrNotFound, name, version)
+}
+
+// project fetches a project once per client
+func (c *Client) project(ctx context.Context, id string) (*Project, error) {
+	c.mu.Lock()
+	if project, ok := c.projects[id]; ok {
+		c.mu.Unlock()
+		return project, nil
+	}
+	c.mu.Unlock()
+
+	var resp projectResponse
+	if err := c.get(ctx, "/v3/projects/"+url.PathEscape(id), &resp); err != nil {
+		return nil, err
+	}
+
+	project := &Project{
+		ID:         id,
+		Stars:      resp.StarsCount,
+		Forks:      resp.ForksCount,
+		OpenIssues: resp.OpenIssuesCount,
+	}
+	if resp.Scorecard != nil {
+		project.Scorecard = &Scorecard{
+			Date:    resp.Scorecard.Date,
+			Score:   resp.Scorecard.OverallScore,
+			Checks:  make(map[string]int),
+			Reasons: make(map[string]string),
+		}
+		for _, check := range resp.Scorecard.Checks {
+			project.Scorecard.Checks[check.Name] = check.Score
+			project.Scorecard.Reasons[check.Name] = check.Reason
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.projects == nil {
+		c.projects = make(map[string]*Project)
+	}
+	c.projects[id] = project
+	return project, nil
+}
+
+// get fetches a JSON document. Rate limited and failed requests are retried
+// by the HTTP client.
+func (c *Client) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(c.APIURL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRequestFailed, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, path)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%w: GET %s: %s", ErrRequestFailed, path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
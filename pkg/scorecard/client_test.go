@@ -0,0 +1,67 @@
+package scorecard
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Project(t *testing.T) {
+	var projectRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/v3/systems/NPM/packages/express/versions/4.18.2", "/v3/systems/NPM/packages/@express%2Frouter/versions/1.0.0":
+			fmt.Fprint(w, `{"relatedProjects": [
+				{"projectKey": {"id": "github.com/expressjs/express.js"}, "relationType": "ISSUE_TRACKER"},
+				{"projectKey": {"id": "github.com/expressjs/express"}, "relationType": "SOURCE_REPO"}
+			]}`)
+		case "/v3/systems/GO/packages/example.com%2Fnorepo/versions/v1.0.0":
+			fmt.Fprint(w, `{"relatedProjects": []}`)
+		case "/v3/projects/github.com%2Fexpressjs%2Fexpress":
+			projectRequests.Add(1)
+			fmt.Fprint(w, `{"openIssuesCount": 150, "starsCount": 64000, "forksCount": 15000, "scorecard": {
+				"date": "2024-06-03T00:00:00Z", "overallScore": 8.2,
+				"checks": [{"name": "Maintained", "score": 10, "reason": "30 commit(s) and 12 issue activity found in the last 90 days -- score normalized to 10"}]
+			}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.APIURL = server.URL
+	ctx := context.Background()
+
+	project, err := client.Project(ctx, "npm", "express", "4.18.2")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "github.com/expressjs/express", project.ID)
+	assert.Equal(t, 64000, project.Stars)
+	assert.Equal(t, 15000, project.Forks)
+	assert.Equal(t, 150, project.OpenIssues)
+	if assert.NotNil(t, project.Scorecard) {
+		assert.Equal(t, 8.2, project.Scorecard.Score)
+		assert.Equal(t, 10, project.Scorecard.Checks[Maintained])
+		assert.Equal(t, time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), project.Scorecard.Date)
+	}
+
+	cached, err := client.Project(ctx, "npm", "@express/router", "1.0.0")
+	assert.NoError(t, err)
+	assert.Same(t, project, cached)
+	assert.Equal(t, int32(1), projectRequests.Load())
+
+	_, err = client.Project(ctx, "go", "example.com/norepo", "v1.0.0")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = client.Project(ctx, "npm", "missing", "1.0.0")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = client.Project(ctx, "maven", "junit", "4.13")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,86 @@
+package scorecard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Enrichment is the name of the scorecard enrichment in ScanOptions.Enrich
+const Enrichment = "scorecard"
+
+// Rule is the rule name of unmaintained package findings
+const Rule = "unmaintained"
+
+// Maintained is the Scorecard check measuring recent project activity
+const Maintained = "Maintained"
+
+// concurrency is the number of packages looked up in parallel
+const concurrency = 8
+
+// Enrich annotates every dependency with the health signals of its source
+// repository: "sourceRepo", "stars", "forks" and "openIssues", and when the
+// repository has an OpenSSF Scorecard, its overall "scorecard" score and
+// the "scorecard.maintained" check score. Dependencies whose repository
+// scores 0 on the Maintained check, because it is archived or had no
+// activity in the last 90 days, are reported as warnings. Packages unknown
+// to deps.dev are left untouched.
+func Enrich(ctx context.Context, source Source, result *scanners.ScanResult) error {
+	projects := make([]*Project, len(result.Dependencies))
+	errs := make([]error, len(result.Dependencies))
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, concurrency)
+	for i, dep := range result.Dependencies {
+		if dep.Version == "" {
+			continue
+		}
+		wg.Add(1)
+		limit <- struct{}{}
+		go func() {
+			defer func() { <-limit; wg.Done() }()
+			project, err := source.Project(ctx, dep.Type, dep.Name, dep.Version)
+			if !errors.Is(err, ErrNotFound) {
+				projects[i], errs[i] = project, err
+			}
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	for i, project := range projects {
+		if project == nil {
+			continue
+		}
+		dep := &result.Dependencies[i]
+		if dep.Properties == nil {
+			dep.Properties = make(map[string]string)
+		}
+		dep.Properties["sourceRepo"] = project.ID
+		dep.Properties["stars"] = strconv.Itoa(project.Stars)
+		dep.Properties["forks"] = strconv.Itoa(project.Forks)
+		dep.Properties["openIssues"] = strconv.Itoa(project.OpenIssues)
+
+		if card := project.Scorecard; card != nil {
+			dep.Properties["scorecard"] = strconv.FormatFloat(card.Score, 'f', 1, 64)
+			if score, ok := card.Checks[Maintained]; ok {
+				dep.Properties["scorecard.maintained"] = strconv.Itoa(score)
+				if score == 0 {
+					result.Findings = append(result.Findings, scanners.Finding{
+						Rule:       Rule,
+						Severity:   scanners.SeverityWarning,
+						Dependency: dep.Name,
+						Version:    dep.Version,
+						Message:    fmt.Sprintf("%s looks unmaintained: %s", project.ID, card.Reasons[Maintained]),
+					})
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package scorecard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+type fakeSource map[string]*Project
+
+func (s fakeSource) Project(ctx context.Context, depType, name, version string) (*Project, error) {
+	if project, ok := s[name]; ok {
+		return project, nil
+	}
+	return nil, ErrNotFound
+}
+
+func TestEnrich(t *testing.T) {
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "express", Version: "4.18.2", Type: "npm"},
+			{Name: "left-pad", Version: "1.3.0", Type: "npm"},
+			{Name: "example.com/noscore", Version: "v1.0.0", Type: "go"},
+			{Name: "private", Version: "1.0.0", Type: "npm"},
+		},
+	}
+	source := fakeSource{
+		"express": {ID: "github.com/expressjs/express", Stars: 64000, Forks: 15000, OpenIssues: 150, Scorecard: &Scorecard{
+			Score: 8.24, Checks: map[string]int{Maintained: 10},
+		}},
+		"left-pad": {ID: "github.com/left-pad/left-pad", Stars: 1200, Scorecard: &Scorecard{
+			Score:   3.1,
+			Checks:  map[string]int{Maintained: 0},
+			Reasons: map[string]string{Maintained: "project is archived"},
+		}},
+		"example.com/noscore": {ID: "github.com/example/noscore", Stars: 3},
+	}
+
+	if !assert.NoError(t, Enrich(context.Background(), source, result)) {
+		return
+	}
+
+	assert.Equal(t, map[string]string{
+		"sourceRepo":           "github.com/expressjs/express",
+		"stars":                "64000",
+		"forks":                "15000",
+		"openIssues":           "150",
+		"scorecard":            "8.2",
+		"scorecard.maintained": "10",
+	}, result.Dependencies[0].Properties)
+
+	assert.Equal(t, "3", result.Dependencies[2].Properties["stars"])
+	assert.NotContains(t, result.Dependencies[2].Properties, "scorecard")
+	assert.Nil(t, result.Dependencies[3].Properties)
+
+	assert.Equal(t, []scanners.Finding{{
+		Rule:       Rule,
+		Severity:   scanners.SeverityWarning,
+		Dependency: "left-pad",
+		Version:    "1.3.0",
+		Message:    "github.com/left-pad/left-pad looks unmaintained: project is archived",
+	}}, result.Findings)
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	deplisterv1 "github.com/santoshdahal12/deplister/pkg/api/deplisterv1"
+	"github.com/santoshdahal12/deplister/pkg/archive"
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// GRPCServer implements the Deplister gRPC service
+type GRPCServer struct {
+	deplisterv1.UnimplementedDeplisterServer
+	config Config
+}
+
+// NewGRPCServer creates the gRPC service with the given configuration
+func NewGRPCServer(config Config) *GRPCServer {
+	return &GRPCServer{config: config}
+}
+
+// NewGRPC creates a grpc.Server with the Deplister service registered
+func NewGRPC(config Config, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	deplisterv1.RegisterDeplisterServer(s, NewGRPCServer(config))
+	return s
+}
+
+// Scan sends the project type first and then streams every dependency, so
+// clients never have to hold a large graph in a single message
+func (s *GRPCServer) Scan(req *deplisterv1.ScanRequest, stream grpc.ServerStreamingServer[deplisterv1.ScanResponse]) error {
+	target, err := s.target(req.GetTarget())
+	if err != nil {
+		return err
+	}
+
+	report, err := runScan(stream.Context(), s.config, target, fromProtoOptions(req.GetOptions()))
+	if err != nil {
+		return scanStatus(err)
+	}
+
+	err = stream.Send(&deplisterv1.ScanResponse{
+		Result: &deplisterv1.ScanResponse_Project{
+			Project: &deplisterv1.Project{ProjectType: report.ProjectType},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, dep := range report.Result.Dependencies {
+		err := stream.Send(&deplisterv1.ScanResponse{
+			Result: &deplisterv1.ScanResponse_Dependency{Dependency: toProtoDependency(report.Result, dep)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *GRPCServer) DetectProject(ctx context.Context, req *deplisterv1.DetectProjectRequest) (*deplisterv1.DetectProjectResponse, error) {
+	target, err := s.target(req.GetTarget())
+	if err != nil {
+		return nil, err
+	}
+
+	projectType, err := engine.Detect(ctx, target, scanners.ScanOptions{Offline: s.config.Offline})
+	if errors.Is(err, engine.ErrNoProject) {
+		return &deplisterv1.DetectProjectResponse{}, nil
+	}
+	if err != nil {
+		return nil, scanStatus(err)
+	}
+
+	return &deplisterv1.DetectProjectResponse{Detected: true, ProjectType: projectType}, nil
+}
+
+func (s *GRPCServer) ListScanners(ctx context.Context, req *deplisterv1.ListScannersRequest) (*deplisterv1.ListScannersResponse, error) {
+	return &deplisterv1.ListScannersResponse{Types: scanners.Types()}, nil
+}
+
+// target converts and authorizes a requested scan target
+func (s *GRPCServer) target(t *deplisterv1.Target) (engine.Target, error) {
+	switch source := t.GetSource().(type) {
+	case *deplisterv1.Target_Path:
+		if !s.config.AllowPaths {
+			return engine.Target{}, status.Error(codes.PermissionDenied, "scanning paths is disabled")
+		}
+		return engine.Target{Path: source.Path}, nil
+
+	case *deplisterv1.Target_Repo:
+		if !s.config.AllowRepos {
+			return engine.Target{}, status.Error(codes.PermissionDenied, "scanning repositories is disabled")
+		}
+		return engine.Target{Repo: source.Repo}, nil
+
+	case *deplisterv1.Target_Archive:
+		fsys, err := archive.OpenReader(bytes.NewReader(source.Archive), int64(len(source.Archive)), t.GetArchiveName())
+		if err != nil {
+			return engine.Target{}, status.Errorf(codes.InvalidArgument, "opening archive: %v", err)
+		}
+		return engine.Target{FS: fsys}, nil
+	}
+
+	return engine.Target{}, status.Error(codes.InvalidArgument, "path, repo or archive is required")
+}
+
+func scanStatus(err error) error {
+	switch {
+	case errors.Is(err, engine.ErrNoProject):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, engine.ErrInvalidTarget):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, engine.ErrOffline):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func fromProtoOptions(o *deplisterv1.ScanOptions) scanners.ScanOptions {
+	if o == nil {
+		return scanners.DefaultScanOptions()
+	}
+	return scanners.ScanOptions{
+		IncludeDev:       o.GetIncludeDev(),
+		FollowWorkspaces: o.GetFollowWorkspaces(),
+		Offline:          o.GetOffline(),
+		MaxDepth:         int(o.GetMaxDepth()),
+		Enrich:           o.GetEnrich(),
+	}
+}
+
+func toProtoDependency(result *scanners.ScanResult, dep scanners.Dependency) *deplisterv1.Dependency {
+	paths := make([]*deplisterv1.DependencyPath, len(dep.Paths))
+	for i, path := range dep.Paths {
+		paths[i] = &deplisterv1.DependencyPath{Path: result.DisplayPath(path.Path), Depth: int32(path.Depth)}
+	}
+
+	vulns := make([]*deplisterv1.Vulnerability, len(dep.Vulnerabilities))
+	for i, vuln := range dep.Vulnerabilities {
+		vulns[i] = &deplisterv1.Vulnerability{
+			Id:            vuln.ID,
+			Aliases:       vuln.Aliases,
+			Summary:       vuln.Summary,
+			Severity:      vuln.Severity,
+			Cvss:          vuln.CVSS,
+			FixedVersions: vuln.FixedVersions,
+		}
+	}
+
+	return &deplisterv1.Dependency{
+		Name:               dep.Name,
+		Version:            dep.Version,
+		Type:               dep.Type,
+		IsDirectDependency: dep.IsDirectDep,
+		Parent:             dep.Parent,
+		Parents:            dep.Parents,
+		Paths:              paths,
+		Properties:         dep.Properties,
+		Depth:              int32(dep.Depth),
+		Vulnerabilities:    vulns,
+		License:            dep.License,
+	}
+}
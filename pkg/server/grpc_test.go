@@ -0,0 +1,127 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	deplisterv1 "github.com/santoshdahal12/deplister/pkg/api/deplisterv1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T, config Config) deplisterv1.DeplisterClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	srv := NewGRPC(config)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return deplisterv1.NewDeplisterClient(conn)
+}
+
+func testArchive(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range testProject {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestGRPC_Scan(t *testing.T) {
+	client := newTestClient(t, Config{})
+
+	stream, err := client.Scan(context.Background(), &deplisterv1.ScanRequest{
+		Target: &deplisterv1.Target{
+			Source:      &deplisterv1.Target_Archive{Archive: testArchive(t)},
+			ArchiveName: "project.zip",
+		},
+	})
+	assert.NoError(t, err)
+
+	var responses []*deplisterv1.ScanResponse
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		if err != nil {
+			return
+		}
+		responses = append(responses, resp)
+	}
+
+	assert.Len(t, responses, 2)
+	assert.Equal(t, "npm", responses[0].GetProject().GetProjectType())
+	assert.Equal(t, "lodash", responses[1].GetDependency().GetName())
+	assert.Equal(t, "4.17.21", responses[1].GetDependency().GetVersion())
+}
+
+func TestGRPC_DetectAndList(t *testing.T) {
+	client := newTestClient(t, Config{AllowPaths: true})
+	ctx := context.Background()
+
+	detected, err := client.DetectProject(ctx, &deplisterv1.DetectProjectRequest{
+		Target: &deplisterv1.Target{
+			Source:      &deplisterv1.Target_Archive{Archive: testArchive(t)},
+			ArchiveName: "project.zip",
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, detected.GetDetected())
+	assert.Equal(t, "npm", detected.GetProjectType())
+
+	detected, err = client.DetectProject(ctx, &deplisterv1.DetectProjectRequest{
+		Target: &deplisterv1.Target{Source: &deplisterv1.Target_Path{Path: t.TempDir()}},
+	})
+	assert.NoError(t, err)
+	assert.False(t, detected.GetDetected())
+
+	list, err := client.ListScanners(ctx, &deplisterv1.ListScannersRequest{})
+	assert.NoError(t, err)
+	assert.Contains(t, list.GetTypes(), "npm")
+}
+
+func TestGRPC_PermissionDenied(t *testing.T) {
+	client := newTestClient(t, Config{})
+
+	_, err := client.DetectProject(context.Background(), &deplisterv1.DetectProjectRequest{
+		Target: &deplisterv1.Target{Source: &deplisterv1.Target_Repo{Repo: "https://example.com/repo"}},
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestGRPC_DetectOffline(t *testing.T) {
+	client := newTestClient(t, Config{AllowRepos: true, Offline: true})
+
+	_, err := client.DetectProject(context.Background(), &deplisterv1.DetectProjectRequest{
+		Target: &deplisterv1.Target{Source: &deplisterv1.Target_Repo{Repo: "https://example.com/repo"}},
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.ErrorContains(t, err, "cloning https://example.com/repo")
+}
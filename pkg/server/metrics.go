@@ -0,0 +1,78 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+)
+
+// Metrics collects Prometheus metrics about the scans a server performs
+type Metrics struct {
+	registry     *prometheus.Registry
+	scans        *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	dependencies *prometheus.CounterVec
+}
+
+// NewMetrics creates the scan metrics on a dedicated registry
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		scans: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "deplister_scans_total",
+			Help: "Number of scans by scanner and status.",
+		}, []string{"scanner", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "deplister_scan_duration_seconds",
+			Help:    "Duration of scans by scanner.",
+			Buckets: prometheus.ExponentialBuckets(0.05, 2, 12),
+		}, []string{"scanner"}),
+		dependencies: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "deplister_dependencies_found_total",
+			Help: "Number of dependencies reported by scanner.",
+		}, []string{"scanner"}),
+	}
+
+	m.registry.MustRegister(
+		m.scans,
+		m.duration,
+		m.dependencies,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// observe records the outcome and duration of a single scan. Failed scans
+// are attributed to the scanner of the project they detected, or to the
+// "unknown" scanner when detection failed.
+func (m *Metrics) observe(report *engine.Report, err error, elapsed time.Duration) {
+	scanner := "unknown"
+	var projectErr *engine.ProjectError
+	switch {
+	case report != nil:
+		scanner = report.ProjectType
+	case errors.As(err, &projectErr):
+		scanner = projectErr.ProjectType
+	}
+	m.duration.WithLabelValues(scanner).Observe(elapsed.Seconds())
+
+	if err != nil {
+		m.scans.WithLabelValues(scanner, "failure").Inc()
+		return
+	}
+
+	m.scans.WithLabelValues(scanner, "success").Inc()
+	m.dependencies.WithLabelValues(scanner).Add(float64(len(report.Result.Dependencies)))
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range testProject {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		assert.NoError(t, err)
+	}
+
+	// An npm project whose lockfile fails to parse after detection
+	broken := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(broken, "package.json"), []byte(testProject["package.json"]), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(broken, "package-lock.json"), []byte("{"), 0644))
+
+	srv := New(Config{AllowPaths: true, Metrics: NewMetrics()})
+
+	for _, path := range []string{dir, t.TempDir(), broken} {
+		body := `{"path": "` + path + `"}`
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/scan", strings.NewReader(body)))
+	}
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	metrics := rec.Body.String()
+	assert.Contains(t, metrics, `deplister_scans_total{scanner="npm",status="success"} 1`)
+	assert.Contains(t, metrics, `deplister_scans_total{scanner="npm",status="failure"} 1`)
+	assert.Contains(t, metrics, `deplister_scans_total{scanner="unknown",status="failure"} 1`)
+	assert.Contains(t, metrics, `deplister_dependencies_found_total{scanner="npm"} 1`)
+	assert.Contains(t, metrics, `deplister_scan_duration_seconds_count{scanner="npm"} 2`)
+	assert.Contains(t, metrics, `deplister_scan_duration_seconds_count{scanner="unknown"} 1`)
+}
+
+func TestMetrics_Disabled(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(Config{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
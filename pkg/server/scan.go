@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/webhook"
+)
+
+// Scan scans the target with the client and offline mode of the
+// configuration and records metrics when they are enabled. It is how scans
+// the server does not receive through its API, such as those of the daemon,
+// are counted alongside them; they deliver their own webhooks.
+func Scan(ctx context.Context, config Config, target engine.Target, opts scanners.ScanOptions) (*engine.Report, error) {
+	opts.HTTPClient = config.HTTPClient
+	opts.Offline = opts.Offline || config.Offline
+	start := time.Now()
+	report, err := engine.Scan(ctx, target, opts)
+	if config.Metrics != nil {
+		config.Metrics.observe(report, err, time.Since(start))
+	}
+	return report, err
+}
+
+// runScan scans a target of the API and hands completed scans to the webhook
+// in the background
+func runScan(ctx context.Context, config Config, target engine.Target, opts scanners.ScanOptions) (*engine.Report, error) {
+	report, err := Scan(ctx, config, target, opts)
+	if err == nil && config.Webhook != nil {
+		go deliver(context.WithoutCancel(ctx), config, describe(target), report)
+	}
+	return report, err
+}
+
+func deliver(ctx context.Context, config Config, target string, report *engine.Report) {
+	ctx, cancel := context.WithTimeout(ctx, webhook.Timeout)
+	defer cancel()
+	if err := config.Webhook.Deliver(ctx, target, report); err != nil && config.WebhookError != nil {
+		config.WebhookError(target, err)
+	}
+}
+
+// describe names a target in webhook deliveries: its path or repository, or
+// "upload" for uploaded archives
+func describe(target engine.Target) string {
+	switch {
+	case target.Path != "":
+		return target.Path
+	case target.Repo != "":
+		return target.Repo
+	}
+	return "upload"
+}
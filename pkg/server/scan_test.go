@@ -0,0 +1,44 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range testProject {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		assert.NoError(t, err)
+	}
+	var deliveries atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deliveries.Add(1)
+	}))
+	defer receiver.Close()
+	notifier, err := webhook.New(webhook.Config{URL: receiver.URL})
+	assert.NoError(t, err)
+
+	config := Config{Metrics: NewMetrics(), Webhook: notifier}
+	report, err := Scan(context.Background(), config, engine.Target{Path: dir}, scanners.DefaultScanOptions())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "npm", report.ProjectType)
+
+	rec := httptest.NewRecorder()
+	config.Metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), `deplister_scans_total{scanner="npm",status="success"} 1`)
+	assert.Zero(t, deliveries.Load(), "callers of Scan deliver their own webhooks")
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/archive"
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/output"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/tracing"
+	"github.com/santoshdahal12/deplister/pkg/webhook"
+)
+
+// MaxUploadSize limits the size of uploaded archives
+const MaxUploadSize = 256 << 20
+
+// Config controls which scan targets the server accepts
+type Config struct {
+	AllowPaths bool     // Allow scanning paths on the server's file system
+	AllowRepos bool     // Allow cloning and scanning git repositories
+	Metrics    *Metrics // Scan metrics, exposed on /metrics when set
+	Offline    bool     // Scan every request offline, whatever its options
+
+	// Webhook receives every completed scan when set. Deliveries run in the
+	// background; WebhookError, when set, is called with those that fail.
+	Webhook      *webhook.Notifier
+	WebhookError func(target string, err error)
+
+	// HTTPClient is the client of enrichments reaching the network, nil for
+	// httpclient.Default
+	HTTPClient *http.Client
+}
+
+// Server exposes deplister scans over HTTP
+type Server struct {
+	config Config
+	mux    *http.ServeMux
+}
+
+// ScanRequest is the JSON body accepted by POST /scan
+type ScanRequest struct {
+	Path    string          `json:"path,omitempty"`
+	Repo    string          `json:"repo,omitempty"`
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+// ErrorResponse is returned for failed requests
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// New creates a server with the given configuration
+func New(config Config) *Server {
+	s := &Server{
+		config: config,
+		mux:    http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+	s.mux.HandleFunc("POST /scan", s.handleScan)
+	if config.Metrics != nil {
+		s.mux.Handle("GET /metrics", config.Metrics.Handler())
+	}
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r.WithContext(tracing.Extract(r.Context(), r.Header)))
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleScan accepts either a JSON ScanRequest or a multipart form with the
+// archive in the "archive" field and optional JSON scan options in "options"
+func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		s.handleUpload(w, r)
+		return
+	}
+
+	var req ScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	opts, err := parseOptions(req.Options)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var target engine.Target
+	switch {
+	case req.Path != "" && req.Repo != "":
+		writeError(w, http.StatusBadRequest, "only one of path or repo may be set")
+		return
+	case req.Path != "":
+		if !s.config.AllowPaths {
+			writeError(w, http.StatusForbidden, "scanning paths is disabled")
+			return
+		}
+		target.Path = req.Path
+	case req.Repo != "":
+		if !s.config.AllowRepos {
+			writeError(w, http.StatusForbidden, "scanning repositories is disabled")
+			return
+		}
+		target.Repo = req.Repo
+	default:
+		writeError(w, http.StatusBadRequest, "path, repo or an uploaded archive is required")
+		return
+	}
+
+	s.scan(w, r, target, opts)
+}
+
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxUploadSize)
+	file, header, err := r.FormFile("archive")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "archive upload required: "+err.Error())
+		return
+	}
+	defer file.Close()
+
+	if !archive.IsArchive(header.Filename) {
+		writeError(w, http.StatusBadRequest, archive.ErrUnsupportedArchive.Error())
+		return
+	}
+
+	opts, err := parseOptions(json.RawMessage(r.FormValue("options")))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	fsys, err := archive.OpenReader(file, header.Size, header.Filename)
+	if errors.Is(err, archive.ErrTooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "opening archive: "+err.Error())
+		return
+	}
+
+	s.scan(w, r, engine.Target{FS: fsys}, opts)
+}
+
+func (s *Server) scan(w http.ResponseWriter, r *http.Request, target engine.Target, opts scanners.ScanOptions) {
+	report, err := runScan(r.Context(), s.config, target, opts)
+	if errors.Is(err, engine.ErrNoProject) {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, output.NewOutputFormat(report.Result, report.ProjectType))
+}
+
+// parseOptions applies JSON encoded options on top of the defaults
+func parseOptions(raw json.RawMessage) (scanners.ScanOptions, error) {
+	opts := scanners.DefaultScanOptions()
+	if len(strings.TrimSpace(string(raw))) == 0 {
+		return opts, nil
+	}
+	if err := json.Unmarshal(raw, &opts); err != nil {
+		return opts, errors.New("invalid options: " + err.Error())
+	}
+	return opts, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Error: message})
+}
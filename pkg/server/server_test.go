@@ -0,0 +1,158 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/output"
+	_ "github.com/santoshdahal12/deplister/pkg/scanners/npm"
+	"github.com/santoshdahal12/deplister/pkg/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testProject = map[string]string{
+	"package.json": `{"name": "test-project", "dependencies": {"lodash": "^4.17.21"}}`,
+	"package-lock.json": `{
+		"name": "test-project",
+		"packages": {
+			"": {"name": "test-project"},
+			"node_modules/lodash": {"version": "4.17.21"}
+		}
+	}`,
+}
+
+func TestServer_Health(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(Config{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status": "ok"}`, rec.Body.String())
+}
+
+func TestServer_ScanPath(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range testProject {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		assert.NoError(t, err)
+	}
+	body := `{"path": "` + dir + `", "options": {"includeDev": false}}`
+
+	rec := httptest.NewRecorder()
+	New(Config{AllowPaths: true}).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/scan", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var out output.OutputFormat
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	assert.Equal(t, "npm", out.ProjectType)
+	assert.Len(t, out.Dependencies, 1)
+
+	rec = httptest.NewRecorder()
+	New(Config{}).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/scan", strings.NewReader(body)))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestServer_Webhook(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range testProject {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		assert.NoError(t, err)
+	}
+	delivered := make(chan webhook.Payload, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhook.Payload
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		delivered <- payload
+	}))
+	defer receiver.Close()
+	notifier, err := webhook.New(webhook.Config{URL: receiver.URL, Mode: webhook.Diff})
+	assert.NoError(t, err)
+
+	body := `{"path": "` + dir + `"}`
+	rec := httptest.NewRecorder()
+	New(Config{AllowPaths: true, Webhook: notifier}).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/scan", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	select {
+	case payload := <-delivered:
+		assert.Equal(t, dir, payload.Target)
+		assert.Equal(t, []webhook.Dependency{{Name: "lodash", Version: "4.17.21"}}, payload.Changes.Added)
+	case <-time.After(10 * time.Second):
+		t.Fatal("scan was not delivered to the webhook")
+	}
+}
+
+func TestServer_ScanUpload(t *testing.T) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, content := range testProject {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("archive", "project.zip")
+	assert.NoError(t, err)
+	_, err = part.Write(archive.Bytes())
+	assert.NoError(t, err)
+	assert.NoError(t, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/scan", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	New(Config{}).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var out output.OutputFormat
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	assert.Equal(t, "lodash", out.Dependencies[0].Name)
+}
+
+func TestServer_Offline(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range testProject {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		assert.NoError(t, err)
+	}
+	body := `{"path": "` + dir + `", "options": {"offline": false, "enrich": {"outdated": true}}}`
+
+	rec := httptest.NewRecorder()
+	New(Config{AllowPaths: true, Offline: true}).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/scan", strings.NewReader(body)))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "offline")
+}
+
+func TestServer_BadRequests(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"invalid_json", `{`, http.StatusBadRequest},
+		{"no_target", `{}`, http.StatusBadRequest},
+		{"both_targets", `{"path": ".", "repo": "https://example.com/repo"}`, http.StatusBadRequest},
+		{"invalid_options", `{"path": ".", "options": {"maxDepth": "x"}}`, http.StatusBadRequest},
+		{"no_project", `{"path": "EMPTY_DIR"}`, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := strings.ReplaceAll(tt.body, "EMPTY_DIR", t.TempDir())
+			rec := httptest.NewRecorder()
+			New(Config{AllowPaths: true}).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/scan", strings.NewReader(body)))
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package signing
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Keyless is the key name selecting keyless signing with a Sigstore identity
+const Keyless = "keyless"
+
+// PredicateType identifies deplister reports used as in-toto predicates
+const PredicateType = "https://github.com/santoshdahal12/deplister/report/v1"
+
+// In-toto and DSSE types
+const (
+	StatementType = "https://in-toto.io/Statement/v1"
+	PayloadType   = "application/vnd.in-toto+json"
+)
+
+// Common errors
+var (
+	ErrInvalidKey       = errors.New("invalid signing key")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrCosignFailed     = errors.New("cosign failed")
+)
+
+// cosign is the command used for keyless signing and encrypted keys,
+// replaced by tests
+var cosign = "cosign"
+
+// Options select how a file is signed
+type Options struct {
+	Key    string // Private key file, or Keyless
+	Attest bool   // Wrap the file as an in-toto attestation instead of signing it directly
+}
+
+// Subject is the artifact an in-toto statement is about
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Statement is an in-toto statement
+type Statement struct {
+	Type          string          `json:"_type"`
+	Subject       []Subject       `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// Envelope is a DSSE envelope holding a signed payload
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a signature of a DSSE envelope
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// SignFile signs a file and returns the path of the signature, attestation
+// or Sigstore bundle written next to it. Unencrypted PEM keys are used
+// directly, while keyless signing and encrypted cosign keys run cosign.
+func SignFile(ctx context.Context, path string, opts Options) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	var key crypto.Signer
+	if opts.Key != Keyless {
+		pemData, err := os.ReadFile(opts.Key)
+		if err != nil {
+			return "", err
+		}
+		if !encrypted(pemData) {
+			if key, err = ParseKey(pemData); err != nil {
+				return "", err
+			}
+		}
+	}
+	if key == nil {
+		return runCosign(ctx, path, opts)
+	}
+
+	if !opts.Attest {
+		sig, err := SignBlob(key, data)
+		if err != nil {
+			return "", err
+		}
+		return path + ".sig", os.WriteFile(path+".sig", []byte(sig), 0o644)
+	}
+
+	envelope, err := Attest(key, filepath.Base(path), data)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(envelope)
+	if err != nil {
+		return "", err
+	}
+	return path + ".intoto.json", os.WriteFile(path+".intoto.json", append(encoded, '\n'), 0o644)
+}
+
+// encrypted reports whether a PEM file holds a cosign encrypted key
+func encrypted(data []byte) bool {
+	block, _ := pem.Decode(data)
+	return block != nil && strings.HasPrefix(block.Type, "ENCRYPTED ") && strings.HasSuffix(block.Type, " PRIVATE KEY")
+}
+
+// ParseKey reads an unencrypted PKCS#8, EC or PKCS#1 private key in PEM form
+func ParseKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block", ErrInvalidKey)
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: unsupported PEM type %q", ErrInvalidKey, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported key type %T", ErrInvalidKey, key)
+	}
+	return signer, nil
+}
+
+// SignBlob signs data the way "cosign sign-blob --key" does, so the base64
+// signature can be checked with "cosign verify-blob --key"
+func SignBlob(key crypto.Signer, data []byte) (string, error) {
+	sig, err := sign(key, data)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// VerifyBlob checks a signature made by SignBlob
+func VerifyBlob(key crypto.PublicKey, data []byte, signature string) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return verify(key, data, sig)
+}
+
+// Attest signs an in-toto statement about a report, with the report as the
+// predicate, and returns it as a DSSE envelope
+func Attest(key crypto.Signer, name string, report []byte) (*Envelope, error) {
+	digest := sha256.Sum256(report)
+	statement, err := json.Marshal(Statement{
+		Type:          StatementType,
+		Subject:       []Subject{{Name: name, Digest: map[string]string{"sha256": hex.EncodeToString(digest[:])}}},
+		PredicateType: PredicateType,
+		Predicate:     json.RawMessage(report),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := sign(key, pae(PayloadType, statement))
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(statement),
+		Signatures:  []Signature{{Sig: base64.StdEncoding.EncodeToString(sig)}},
+	}, nil
+}
+
+// VerifyEnvelope checks that any signature of a DSSE envelope was made by
+// the key, and returns the statement it holds
+func VerifyEnvelope(key crypto.PublicKey, envelope *Envelope) (*Statement, error) {
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	err = fmt.Errorf("%w: no signatures", ErrInvalidSignature)
+	for _, signature := range envelope.Signatures {
+		var sig []byte
+		if sig, err = base64.StdEncoding.DecodeString(signature.Sig); err != nil {
+			err = fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+			continue
+		}
+		if err = verify(key, pae(envelope.PayloadType, payload), sig); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var statement Statement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return &statement, nil
+}
+
+// pae is the DSSE pre-authentication encoding of a payload
+func pae(payloadType string, payload []byte) []byte {
+	return []byte("DSSEv1 " + strconv.Itoa(len(payloadType)) + " " + payloadType + " " + strconv.Itoa(len(payload)) + " " + string(payload))
+}
+
+// sign signs the SHA-256 digest of data, or data itself for Ed25519 keys
+func sign(key crypto.Signer, data []byte) ([]byte, error) {
+	if _, ok := key.Public().(ed25519.PublicKey); ok {
+		return key.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(data)
+	return key.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+func verify(key crypto.PublicKey, data, sig []byte) error {
+	digest := sha256.Sum256(data)
+	var ok bool
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(key, digest[:], sig)
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(key, data, sig)
+	case *rsa.PublicKey:
+		ok = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil
+	default:
+		return fmt.Errorf("%w: unsupported key type %T", ErrInvalidKey, key)
+	}
+	if !ok {
+		return fmt.Errorf("%w: verification failed", ErrInvalidSignature)
+	}
+	return nil
+}
+
+// runCosign signs a file with cosign, writing a Sigstore bundle
+func runCosign(ctx context.Context, path string, opts Options) (string, error) {
+	bundle := path + ".sigstore.json"
+	cmd := exec.CommandContext(ctx, cosign, cosignArgs(path, bundle, opts)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrCosignFailed, err)
+	}
+	return bundle, nil
+}
+
+func cosignArgs(path, bundle string, opts Options) []string {
+	args := []string{"sign-blob"}
+	if opts.Attest {
+		args = []string{"attest-blob", "--predicate", path, "--type", PredicateType}
+	}
+	args = append(args, "--yes", "--bundle", bundle)
+	if opts.Key != Keyless {
+		args = append(args, "--key", opts.Key)
+	}
+	return append(args, path)
+}
@@ -0,0 +1,116 @@
+package signing
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeKey(t *testing.T, dir string, key crypto.Signer) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	path := filepath.Join(dir, "key.pem")
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	return path
+}
+
+func TestSignFile(t *testing.T) {
+	dir := t.TempDir()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	report := filepath.Join(dir, "report.json")
+	assert.NoError(t, os.WriteFile(report, []byte(`{"projectType":"npm"}`), 0o644))
+
+	opts := Options{Key: writeKey(t, dir, key)}
+	path, err := SignFile(context.Background(), report, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, report+".sig", path)
+	sig, _ := os.ReadFile(path)
+	assert.NoError(t, VerifyBlob(&key.PublicKey, []byte(`{"projectType":"npm"}`), string(sig)))
+	assert.True(t, errors.Is(VerifyBlob(&key.PublicKey, []byte(`{}`), string(sig)), ErrInvalidSignature))
+
+	opts.Attest = true
+	path, err = SignFile(context.Background(), report, opts)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, report+".intoto.json", path)
+	data, _ := os.ReadFile(path)
+	var envelope Envelope
+	if !assert.NoError(t, json.Unmarshal(data, &envelope)) {
+		return
+	}
+	statement, err := VerifyEnvelope(&key.PublicKey, &envelope)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, StatementType, statement.Type)
+	assert.Equal(t, PredicateType, statement.PredicateType)
+	assert.JSONEq(t, `{"projectType":"npm"}`, string(statement.Predicate))
+	assert.Equal(t, []Subject{{Name: "report.json", Digest: map[string]string{"sha256": "8c2c830ba36f4f4f39f089c3107ec19d1416aa072db1bb3053f4adfe73be7526"}}}, statement.Subject)
+}
+
+func TestVerifyEnvelope(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	envelope, err := Attest(key, "report.json", []byte(`{}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = VerifyEnvelope(key.Public(), envelope)
+	assert.NoError(t, err)
+
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	_, err = VerifyEnvelope(other, envelope)
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+
+	envelope.PayloadType = "text/plain"
+	_, err = VerifyEnvelope(key.Public(), envelope)
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+}
+
+func TestParseKey(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalECPrivateKey(key)
+	signer, err := ParseKey(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+	assert.NoError(t, err)
+	assert.IsType(t, &ecdsa.PrivateKey{}, signer)
+
+	_, err = ParseKey([]byte("not a key"))
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	_, err = ParseKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+}
+
+func TestCosign(t *testing.T) {
+	assert.Equal(t, []string{"sign-blob", "--yes", "--bundle", "r.json.sigstore.json", "r.json"},
+		cosignArgs("r.json", "r.json.sigstore.json", Options{Key: Keyless}))
+	assert.Equal(t, []string{"attest-blob", "--predicate", "r.json", "--type", PredicateType, "--yes", "--bundle", "r.json.sigstore.json", "--key", "cosign.key", "r.json"},
+		cosignArgs("r.json", "r.json.sigstore.json", Options{Key: "cosign.key", Attest: true}))
+
+	// Encrypted cosign keys are passed to cosign
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.json")
+	keyFile := filepath.Join(dir, "cosign.key")
+	assert.NoError(t, os.WriteFile(report, []byte(`{}`), 0o644))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED SIGSTORE PRIVATE KEY", Bytes: []byte("x")}), 0o600))
+
+	cosign = filepath.Join(dir, "missing-cosign")
+	defer func() { cosign = "cosign" }()
+	_, err := SignFile(context.Background(), report, Options{Key: keyFile})
+	assert.True(t, errors.Is(err, ErrCosignFailed))
+}
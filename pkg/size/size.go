@@ -0,0 +1,149 @@
+package size
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Enrichment is the name of the size estimation in ScanOptions.Enrich
+const Enrichment = "size"
+
+// Property is the dependency property holding the estimated size in bytes
+const Property = "size"
+
+// Top is the number of largest dependencies listed in a footprint
+const Top = 10
+
+// concurrency is the number of Go module sizes looked up in parallel
+const concurrency = 8
+
+// Source provides npm registry metadata and Go module zip sizes
+type Source interface {
+	registry.Source
+	ModuleSize(ctx context.Context, path, version string) (int64, error)
+}
+
+// Enrich records the estimated size in bytes of every dependency as the
+// "size" property: the unpacked size published in the npm registry, or the
+// size of the module zip on the Go proxy. Dependencies whose size is unknown,
+// such as private packages or local replacements, are left untouched.
+func Enrich(ctx context.Context, source Source, result *scanners.ScanResult) error {
+	packages, err := registry.Lookup(ctx, source, npmDependencies(result.Dependencies))
+	if err != nil {
+		return err
+	}
+
+	sizes := make([]int64, len(result.Dependencies))
+	errs := make([]error, len(result.Dependencies))
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, concurrency)
+	for i, dep := range result.Dependencies {
+		switch dep.Type {
+		case "npm":
+			if pkg, ok := packages[registry.Key{Type: dep.Type, Name: dep.Name}]; ok {
+				sizes[i] = pkg.Dist[dep.Version].UnpackedSize
+			}
+		case "go":
+			path, version := dep.Name, dep.Version
+			if replaced, ok := dep.Properties["replaced_by"]; ok {
+				path, version = replaced, dep.Properties["replaced_version"]
+			}
+			if version == "" {
+				continue
+			}
+			wg.Add(1)
+			limit <- struct{}{}
+			go func() {
+				defer func() { <-limit; wg.Done() }()
+				sizes[i], errs[i] = source.ModuleSize(ctx, path, version)
+				if errors.Is(errs[i], registry.ErrNotFound) {
+					errs[i] = nil
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	for i := range result.Dependencies {
+		if sizes[i] <= 0 {
+			continue
+		}
+		dep := &result.Dependencies[i]
+		if dep.Properties == nil {
+			dep.Properties = make(map[string]string)
+		}
+		dep.Properties[Property] = strconv.FormatInt(sizes[i], 10)
+	}
+	return nil
+}
+
+func npmDependencies(deps []scanners.Dependency) []scanners.Dependency {
+	var npm []scanners.Dependency
+	for _, dep := range deps {
+		if dep.Type == "npm" {
+			npm = append(npm, dep)
+		}
+	}
+	return npm
+}
+
+// Footprint summarizes the sizes recorded by Enrich
+type Footprint struct {
+	Total   int64        // Bytes of every dependency with a known size
+	Sized   int          // Number of dependencies with a known size
+	Largest []Dependency // Largest dependencies, biggest first
+}
+
+// Dependency is the size of a single dependency
+type Dependency struct {
+	Name    string
+	Version string
+	Bytes   int64
+}
+
+// Summarize totals the sizes of the dependencies and lists the n largest.
+// It reports false when no dependency has a size.
+func Summarize(deps []scanners.Dependency, n int) (Footprint, bool) {
+	var footprint Footprint
+	var sized []Dependency
+	for _, dep := range deps {
+		bytes, err := strconv.ParseInt(dep.Properties[Property], 10, 64)
+		if err != nil {
+			continue
+		}
+		footprint.Total += bytes
+		sized = append(sized, Dependency{Name: dep.Name, Version: dep.Version, Bytes: bytes})
+	}
+	if len(sized) == 0 {
+		return footprint, false
+	}
+
+	sort.SliceStable(sized, func(i, j int) bool { return sized[i].Bytes > sized[j].Bytes })
+	footprint.Sized = len(sized)
+	footprint.Largest = sized[:min(n, len(sized))]
+	return footprint, true
+}
+
+// Format renders a size in bytes with a binary unit
+func Format(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
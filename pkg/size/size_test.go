@@ -0,0 +1,84 @@
+package size
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/registry"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+type fakeSource struct {
+	packages map[string]*registry.Package
+	modules  map[string]int64
+}
+
+func (f fakeSource) Package(ctx context.Context, depType, name string) (*registry.Package, error) {
+	if pkg, ok := f.packages[name]; ok {
+		return pkg, nil
+	}
+	return nil, registry.ErrNotFound
+}
+
+func (f fakeSource) ModuleSize(ctx context.Context, path, version string) (int64, error) {
+	if size, ok := f.modules[path+"@"+version]; ok {
+		return size, nil
+	}
+	return 0, registry.ErrNotFound
+}
+
+func TestEnrich(t *testing.T) {
+	source := fakeSource{
+		packages: map[string]*registry.Package{
+			"typescript": {Name: "typescript", Dist: map[string]registry.Dist{"5.4.5": {UnpackedSize: 31470860}}},
+			"left-pad":   {Name: "left-pad", Dist: map[string]registry.Dist{"1.3.0": {}}},
+		},
+		modules: map[string]int64{
+			"github.com/spf13/cobra@v1.8.0": 193254,
+			"example.com/fork@v1.0.1":       2048,
+		},
+	}
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "typescript", Version: "5.4.5", Type: "npm"},
+			{Name: "left-pad", Version: "1.3.0", Type: "npm"},
+			{Name: "private", Version: "1.0.0", Type: "npm"},
+			{Name: "github.com/spf13/cobra", Version: "v1.8.0", Type: "go"},
+			{Name: "example.com/original", Version: "v1.0.0", Type: "go", Properties: map[string]string{"replaced_by": "example.com/fork", "replaced_version": "v1.0.1"}},
+			{Name: "example.com/local", Version: "v1.0.0", Type: "go", Properties: map[string]string{"replaced_by": "../local", "replaced_version": ""}},
+		},
+	}
+
+	if !assert.NoError(t, Enrich(context.Background(), source, result)) {
+		return
+	}
+	assert.Equal(t, "31470860", result.Dependencies[0].Properties[Property])
+	assert.Nil(t, result.Dependencies[1].Properties)
+	assert.Nil(t, result.Dependencies[2].Properties)
+	assert.Equal(t, "193254", result.Dependencies[3].Properties[Property])
+	assert.Equal(t, "2048", result.Dependencies[4].Properties[Property])
+	assert.NotContains(t, result.Dependencies[5].Properties, Property)
+
+	footprint, ok := Summarize(result.Dependencies, 2)
+	assert.True(t, ok)
+	assert.Equal(t, Footprint{
+		Total: 31470860 + 193254 + 2048,
+		Sized: 3,
+		Largest: []Dependency{
+			{Name: "typescript", Version: "5.4.5", Bytes: 31470860},
+			{Name: "github.com/spf13/cobra", Version: "v1.8.0", Bytes: 193254},
+		},
+	}, footprint)
+
+	_, ok = Summarize(result.Dependencies[1:3], Top)
+	assert.False(t, ok)
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "512 B", Format(512))
+	assert.Equal(t, "1.5 KiB", Format(1536))
+	assert.Equal(t, "30.0 MiB", Format(30<<20))
+	assert.Equal(t, "2.0 GiB", Format(2<<30))
+}
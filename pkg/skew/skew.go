@@ -0,0 +1,137 @@
+package skew
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/scanners/npm"
+	"github.com/santoshdahal12/deplister/pkg/version"
+)
+
+// Enrichment is the name of the workspace version skew check in ScanOptions.Enrich
+const Enrichment = "skew"
+
+// Rule is the rule name of version skew findings
+const Rule = "version-skew"
+
+// Skew is a dependency the workspaces of a monorepo resolve to different versions
+type Skew struct {
+	Name         string
+	Requirements []Requirement // Ordered by workspace
+}
+
+// Versions returns the distinct resolved versions, lowest first
+func (s Skew) Versions() []string {
+	var versions []string
+	for _, req := range s.Requirements {
+		if !slices.Contains(versions, req.Version) {
+			versions = append(versions, req.Version)
+		}
+	}
+	slices.SortFunc(versions, version.Compare)
+	return versions
+}
+
+// Requirement is the dependency of one workspace on a package
+type Requirement struct {
+	Workspace string // Package name of the workspace, or its path when unnamed
+	Specifier string // Range declared in the workspace's package.json
+	Version   string // Version installed for the workspace
+}
+
+// Find returns the dependencies that the root package and the workspace
+// members of the npm monorepo in fsys resolve to more than one version,
+// sorted by name. Dependencies of every kind are compared, as diverging
+// tooling versions fragment a monorepo as much as runtime ones. Projects
+// without workspaces have no skew.
+func Find(fsys fs.FS) ([]Skew, error) {
+	lock, err := npm.ReadLockfile(fsys)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Workspace members are the packages installed outside node_modules
+	var workspaces []string
+	for pkgPath, pkg := range lock.Packages {
+		if pkgPath == "" || (!strings.Contains(pkgPath, "node_modules/") && !pkg.Link) {
+			workspaces = append(workspaces, pkgPath)
+		}
+	}
+	if len(workspaces) < 2 {
+		return nil, nil
+	}
+	sort.Strings(workspaces)
+
+	requirements := make(map[string][]Requirement)
+	for _, dir := range workspaces {
+		member := lock.Packages[dir]
+		workspace := member.Name
+		if workspace == "" {
+			workspace = dir
+		}
+		for _, deps := range []map[string]string{member.Dependencies, member.DevDependencies, member.OptionalDependencies, member.PeerDependencies} {
+			for name, specifier := range deps {
+				installed, ok := npm.ResolvePackage(lock.Packages, dir, name)
+				// Workspaces depending on each other are linked, not installed
+				if !ok || lock.Packages[installed].Link || lock.Packages[installed].Version == "" {
+					continue
+				}
+				if slices.ContainsFunc(requirements[name], func(req Requirement) bool { return req.Workspace == workspace }) {
+					continue
+				}
+				requirements[name] = append(requirements[name], Requirement{
+					Workspace: workspace,
+					Specifier: specifier,
+					Version:   lock.Packages[installed].Version,
+				})
+			}
+		}
+	}
+
+	var skews []Skew
+	for name, reqs := range requirements {
+		skew := Skew{Name: name, Requirements: reqs}
+		if len(skew.Versions()) < 2 {
+			continue
+		}
+		sort.Slice(skew.Requirements, func(i, j int) bool { return skew.Requirements[i].Workspace < skew.Requirements[j].Workspace })
+		skews = append(skews, skew)
+	}
+	sort.Slice(skews, func(i, j int) bool { return skews[i].Name < skews[j].Name })
+	return skews, nil
+}
+
+// Enrich adds a warning finding for every dependency the workspaces of an npm
+// monorepo in fsys resolve to different versions. Other project types are
+// left untouched.
+func Enrich(fsys fs.FS, projectType string, result *scanners.ScanResult) error {
+	if projectType != "npm" {
+		return nil
+	}
+	skews, err := Find(fsys)
+	if err != nil {
+		return err
+	}
+
+	for _, skew := range skews {
+		var uses []string
+		for _, req := range skew.Requirements {
+			uses = append(uses, fmt.Sprintf("%s uses %s (%s)", req.Workspace, req.Version, req.Specifier))
+		}
+		result.Findings = append(result.Findings, scanners.Finding{
+			Rule:       Rule,
+			Severity:   scanners.SeverityWarning,
+			Dependency: skew.Name,
+			Message:    fmt.Sprintf("%s is resolved to %d versions across workspaces: %s", skew.Name, len(skew.Versions()), strings.Join(uses, ", ")),
+		})
+	}
+	return nil
+}
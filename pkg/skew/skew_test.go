@@ -0,0 +1,76 @@
+package skew
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+const monorepoLock = `{
+  "name": "mono",
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "mono", "workspaces": ["packages/*"], "dependencies": {"lodash": "^4.17.21"}, "devDependencies": {"typescript": "^5.4.0"}},
+    "node_modules/@mono/a": {"resolved": "packages/a", "link": true},
+    "node_modules/@mono/b": {"resolved": "packages/b", "link": true},
+    "node_modules/lodash": {"version": "4.17.21"},
+    "node_modules/ms": {"version": "2.1.3"},
+    "node_modules/typescript": {"version": "5.4.5"},
+    "packages/a": {"name": "@mono/a", "version": "1.0.0", "dependencies": {"lodash": "^4.17.0", "ms": "^2.1.0"}},
+    "packages/b": {"name": "@mono/b", "version": "1.0.0", "dependencies": {"lodash": "3.10.1", "ms": "^2.1.0", "@mono/a": "*"}, "devDependencies": {"typescript": "~4.9.0"}},
+    "packages/b/node_modules/lodash": {"version": "3.10.1"},
+    "packages/b/node_modules/typescript": {"version": "4.9.5"}
+  }
+}`
+
+func TestFind(t *testing.T) {
+	skews, err := Find(fstest.MapFS{"package-lock.json": {Data: []byte(monorepoLock)}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []Skew{
+		{Name: "lodash", Requirements: []Requirement{
+			{Workspace: "@mono/a", Specifier: "^4.17.0", Version: "4.17.21"},
+			{Workspace: "@mono/b", Specifier: "3.10.1", Version: "3.10.1"},
+			{Workspace: "mono", Specifier: "^4.17.21", Version: "4.17.21"},
+		}},
+		{Name: "typescript", Requirements: []Requirement{
+			{Workspace: "@mono/b", Specifier: "~4.9.0", Version: "4.9.5"},
+			{Workspace: "mono", Specifier: "^5.4.0", Version: "5.4.5"},
+		}},
+	}, skews)
+	assert.Equal(t, []string{"3.10.1", "4.17.21"}, skews[0].Versions())
+
+	// A single package has nothing to diverge from
+	skews, err = Find(fstest.MapFS{"package-lock.json": {Data: []byte(`{"packages": {"": {"dependencies": {"ms": "^2.0.0"}}, "node_modules/ms": {"version": "2.1.3"}}}`)}})
+	assert.NoError(t, err)
+	assert.Empty(t, skews)
+
+	skews, err = Find(fstest.MapFS{})
+	assert.NoError(t, err)
+	assert.Empty(t, skews)
+}
+
+func TestEnrich(t *testing.T) {
+	fsys := fstest.MapFS{"package-lock.json": {Data: []byte(monorepoLock)}}
+
+	result := &scanners.ScanResult{}
+	if !assert.NoError(t, Enrich(fsys, "npm", result)) {
+		return
+	}
+	if assert.Len(t, result.Findings, 2) {
+		assert.Equal(t, scanners.Finding{
+			Rule:       Rule,
+			Severity:   scanners.SeverityWarning,
+			Dependency: "lodash",
+			Message:    "lodash is resolved to 2 versions across workspaces: @mono/a uses 4.17.21 (^4.17.0), @mono/b uses 3.10.1 (3.10.1), mono uses 4.17.21 (^4.17.21)",
+		}, result.Findings[0])
+	}
+
+	result = &scanners.ScanResult{}
+	assert.NoError(t, Enrich(fsys, "go", result))
+	assert.Empty(t, result.Findings)
+}
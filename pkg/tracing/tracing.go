@@ -0,0 +1,123 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName identifies deplister's spans
+const InstrumentationName = "github.com/santoshdahal12/deplister"
+
+// Start starts a span using the globally configured tracer provider. Without
+// Setup the provider is a no-op, so instrumented code costs next to nothing.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(InstrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on the span, if any, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Run runs an external command inside a span named after it
+func Run(ctx context.Context, cmd *exec.Cmd) error {
+	span := startCommand(ctx, cmd)
+	err := cmd.Run()
+	End(span, err)
+	return err
+}
+
+// Output is like Run but returns the command's standard output
+func Output(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	span := startCommand(ctx, cmd)
+	out, err := cmd.Output()
+	End(span, err)
+	return out, err
+}
+
+func startCommand(ctx context.Context, cmd *exec.Cmd) trace.Span {
+	_, span := Start(ctx, "exec "+cmd.Args[0],
+		attribute.String("process.command", cmd.Args[0]),
+		attribute.String("process.command_line", strings.Join(cmd.Args, " ")),
+		attribute.String("process.working_directory", cmd.Dir))
+	return span
+}
+
+// Extract returns ctx carrying the remote span context propagated in the
+// request headers, so server side spans join the caller's trace
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Enabled reports whether the standard OTEL_ environment variables ask for
+// traces to be exported
+func Enabled() bool {
+	if strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+	switch strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")) {
+	case "none":
+		return false
+	case "otlp":
+		return true
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup installs an OTLP/HTTP exporting tracer provider when tracing is
+// enabled. The exporter, sampler and resource are configured through the
+// standard OTEL_ environment variables. The returned function flushes and
+// shuts down the provider.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	if !Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewSchemaless(semconv.ServiceName(serviceName())),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// serviceName honors OTEL_SERVICE_NAME, which resource.Default also reads,
+// while defaulting to "deplister" instead of "unknown_service"
+func serviceName() string {
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		return name
+	}
+	return "deplister"
+}
@@ -0,0 +1,197 @@
+package typosquat
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Enrichment is the name of the typosquatting check in ScanOptions.Enrich
+const Enrichment = "typosquat"
+
+// Rule is the rule name of typosquatting findings
+const Rule = "typosquat"
+
+//go:embed corpus/*.txt
+var corpus embed.FS
+
+// Match describes why a package name looks like a typosquat
+type Match struct {
+	Target string // Popular package the name imitates
+	Reason string
+}
+
+// Checker compares package names against popular packages and known
+// typosquats
+type Checker struct {
+	popular map[string][]string          // Popular names by dependency type
+	known   map[string]map[string]string // Known squats by type, mapped to their target
+}
+
+// NewChecker creates a checker from popular package names and known squats,
+// both keyed by dependency type
+func NewChecker(popular map[string][]string, known map[string]map[string]string) *Checker {
+	return &Checker{popular: popular, known: known}
+}
+
+// Default returns a checker for the built-in corpus of popular npm packages
+// and Go modules
+var Default = sync.OnceValue(func() *Checker {
+	popular := map[string][]string{
+		"npm": readLines("corpus/npm.txt"),
+		"go":  readLines("corpus/go.txt"),
+	}
+
+	known := make(map[string]map[string]string)
+	for _, line := range readLines("corpus/squats.txt") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		if known[fields[0]] == nil {
+			known[fields[0]] = make(map[string]string)
+		}
+		known[fields[0]][fields[1]] = fields[2]
+	}
+	return NewChecker(popular, known)
+})
+
+func readLines(name string) []string {
+	file, err := corpus.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Check reports whether a package name imitates a popular package: it is a
+// known typosquat, it only differs from a popular name in case or
+// separators, or it is within a small edit distance of one. Popular packages
+// themselves are never reported.
+func (c *Checker) Check(depType, name string) (Match, bool) {
+	if target, ok := c.known[depType][name]; ok {
+		return Match{Target: target, Reason: "known typosquat"}, true
+	}
+
+	popular := c.popular[depType]
+	for _, target := range popular {
+		if name == target {
+			return Match{}, false
+		}
+	}
+
+	normalized := normalize(depType, name)
+	for _, target := range popular {
+		normalizedTarget := normalize(depType, target)
+		if normalized == normalizedTarget {
+			// Other major versions of a Go module are not squats
+			if depType == "go" && !strings.EqualFold(name, target) {
+				continue
+			}
+			return Match{Target: target, Reason: "differs only in case or separators"}, true
+		}
+
+		limit := maxDistance(depType, target)
+		if limit == 0 || abs(len(name)-len(target)) > limit {
+			continue
+		}
+		if distance(name, target) <= limit {
+			return Match{Target: target, Reason: "differs by a typo"}, true
+		}
+	}
+	return Match{}, false
+}
+
+var (
+	separators   = strings.NewReplacer("-", "", "_", "", ".", "")
+	majorVersion = regexp.MustCompile(`([/.]v\d+)$`)
+)
+
+// normalize folds case and the separators of npm names, and strips the
+// major version suffix of Go module paths
+func normalize(depType, name string) string {
+	name = strings.ToLower(name)
+	if depType == "go" {
+		return majorVersion.ReplaceAllString(name, "")
+	}
+	return separators.Replace(name)
+}
+
+// maxDistance is the number of edits from a popular name considered
+// suspicious. Short names are too close to each other to compare and Go
+// module paths share long prefixes, so they allow fewer edits.
+func maxDistance(depType, target string) int {
+	switch {
+	case len(target) < 5:
+		return 0
+	case depType == "go" || len(target) < 12:
+		return 1
+	}
+	return 2
+}
+
+// distance is the optimal string alignment distance: the number of
+// insertions, deletions, substitutions and adjacent transpositions
+func distance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Enrich adds a warning finding for every dependency whose name looks like a
+// typosquat of a popular package
+func Enrich(checker *Checker, result *scanners.ScanResult) {
+	for _, dep := range result.Dependencies {
+		match, ok := checker.Check(dep.Type, dep.Name)
+		if !ok {
+			continue
+		}
+		result.Findings = append(result.Findings, scanners.Finding{
+			Rule:       Rule,
+			Severity:   scanners.SeverityWarning,
+			Dependency: dep.Name,
+			Version:    dep.Version,
+			Message:    fmt.Sprintf("%s resembles the popular package %s (%s)", dep.Name, match.Target, match.Reason),
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package typosquat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func TestChecker_Check(t *testing.T) {
+	tests := []struct {
+		depType string
+		name    string
+		target  string
+	}{
+		{"npm", "lodahs", "lodash"},
+		{"npm", "expres", "express"},
+		{"npm", "crossenv", "cross-env"},
+		{"npm", "cross_env", "cross-env"},
+		{"npm", "Lodash", "lodash"},
+		{"npm", "styled-componets", "styled-components"},
+		{"npm", "react-doom", "react-dom"},
+		{"go", "github.com/Sirupsen/logrus", "github.com/sirupsen/logrus"},
+		{"go", "github.com/gorila/mux", "github.com/gorilla/mux"},
+		{"go", "github.com/boltdb-go/bolt", "github.com/boltdb/bolt"},
+
+		// Not squats
+		{"npm", "lodash", ""},
+		{"npm", "lodash-es", ""},
+		{"npm", "mysql2", ""},
+		{"npm", "qs", ""},
+		{"npm", "pm", ""},
+		{"npm", "my-internal-lib", ""},
+		{"go", "github.com/go-redis/redis/v7", ""},
+		{"go", "github.com/go-chi/chi", ""},
+		{"go", "golang.org/x/term", ""},
+		{"go", "lodahs", ""},
+	}
+
+	for _, tt := range tests {
+		match, ok := Default().Check(tt.depType, tt.name)
+		assert.Equal(t, tt.target != "", ok, "%s %s", tt.depType, tt.name)
+		assert.Equal(t, tt.target, match.Target, "%s %s", tt.depType, tt.name)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	assert.Equal(t, 0, distance("lodash", "lodash"))
+	assert.Equal(t, 1, distance("lodahs", "lodash"))
+	assert.Equal(t, 1, distance("expres", "express"))
+	assert.Equal(t, 1, distance("reakt", "react"))
+	assert.Equal(t, 2, distance("term", "text"))
+	assert.Equal(t, 3, distance("", "abc"))
+}
+
+func TestEnrich(t *testing.T) {
+	checker := NewChecker(map[string][]string{"npm": {"lodash"}}, nil)
+	result := &scanners.ScanResult{Dependencies: []scanners.Dependency{
+		{Name: "lodash", Version: "4.17.21", Type: "npm"},
+		{Name: "lodahs", Version: "1.0.0", Type: "npm"},
+		{Name: "lodahs", Version: "1.0.0", Type: "go"},
+	}}
+
+	Enrich(checker, result)
+	assert.Equal(t, []scanners.Finding{{
+		Rule:       Rule,
+		Severity:   scanners.SeverityWarning,
+		Dependency: "lodahs",
+		Version:    "1.0.0",
+		Message:    "lodahs resembles the popular package lodash (differs by a typo)",
+	}}, result.Findings)
+}
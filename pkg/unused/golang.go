@@ -0,0 +1,79 @@
+package unused
+
+import (
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// goImports returns the import paths of every Go file of the module in fsys,
+// test files included. Build constraints are not evaluated, so imports only
+// used on some platforms still count. Vendored code, testdata, hidden
+// directories and nested modules are skipped.
+func goImports(fsys fs.FS) (map[string]bool, error) {
+	imports := make(map[string]bool)
+	fset := token.NewFileSet()
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name != "." && skipGoDir(fsys, name) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") {
+			return nil
+		}
+
+		src, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		// Files that do not parse are skipped, as the go tool would fail on them
+		file, err := parser.ParseFile(fset, name, src, parser.ImportsOnly)
+		if err != nil {
+			return nil
+		}
+		for _, spec := range file.Imports {
+			if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+				imports[importPath] = true
+			}
+		}
+		return nil
+	})
+	return imports, err
+}
+
+// skipGoDir reports whether the go tool ignores a directory when matching
+// ./... or it belongs to another module
+func skipGoDir(fsys fs.FS, dir string) bool {
+	base := path.Base(dir)
+	if base == "vendor" || base == "testdata" || strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_") {
+		return true
+	}
+	_, err := fs.Stat(fsys, path.Join(dir, "go.mod"))
+	return err == nil
+}
+
+// importedModules maps import paths to the modules providing them, picking
+// the longest matching module path as the go tool does
+func importedModules(imports map[string]bool, modules []string) map[string]bool {
+	imported := make(map[string]bool)
+	for importPath := range imports {
+		best := ""
+		for _, module := range modules {
+			if (importPath == module || strings.HasPrefix(importPath, module+"/")) && len(module) > len(best) {
+				best = module
+			}
+		}
+		if best != "" {
+			imported[best] = true
+		}
+	}
+	return imported
+}
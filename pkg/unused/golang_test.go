@@ -0,0 +1,39 @@
+package unused
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoImports(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod":                   {Data: []byte("module example.com/app\n")},
+		"a.go":                     {Data: []byte("package a\n\nimport \"github.com/a/a\"\n")},
+		"broken.go":                {Data: []byte("package a\n\nimport (\n")},
+		"vendor/github.com/v/v.go": {Data: []byte("package v\n\nimport \"github.com/vendored/v\"\n")},
+		"testdata/t.go":            {Data: []byte("package t\n\nimport \"github.com/testdata/t\"\n")},
+		".hidden/h.go":             {Data: []byte("package h\n\nimport \"github.com/hidden/h\"\n")},
+		"tools/go.mod":             {Data: []byte("module example.com/app/tools\n")},
+		"tools/tools.go":           {Data: []byte("package tools\n\nimport _ \"github.com/nested/n\"\n")},
+		"sub/b_windows.go":         {Data: []byte("package sub\n\nimport \"github.com/b/b/v2/pkg\"\n")},
+	}
+
+	imports, err := goImports(fsys)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]bool{"github.com/a/a": true, "github.com/b/b/v2/pkg": true}, imports)
+}
+
+func TestImportedModules(t *testing.T) {
+	imports := map[string]bool{
+		"github.com/b/b/v2/pkg":                true,
+		"cloud.google.com/go/storage/internal": true,
+		"fmt":                                  true,
+	}
+	modules := []string{"github.com/b/b", "github.com/b/b/v2", "cloud.google.com/go", "cloud.google.com/go/storage"}
+
+	assert.Equal(t, map[string]bool{"github.com/b/b/v2": true, "cloud.google.com/go/storage": true}, importedModules(imports, modules))
+}
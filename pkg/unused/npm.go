@@ -0,0 +1,127 @@
+package unused
+
+import (
+	"encoding/json"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// manifest holds the dependency fields of package.json
+type manifest struct {
+	Name                 string            `json:"name"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// declares reports whether package.json lists a package in any dependency field
+func (m manifest) declares(name string) bool {
+	for _, deps := range []map[string]string{m.Dependencies, m.DevDependencies, m.PeerDependencies, m.OptionalDependencies} {
+		if _, ok := deps[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func readManifest(fsys fs.FS) (manifest, error) {
+	var m manifest
+	content, err := fs.ReadFile(fsys, "package.json")
+	if err != nil {
+		return m, err
+	}
+	err = json.Unmarshal(content, &m)
+	return m, err
+}
+
+// sourceExtensions are the extensions of the files searched for imports
+var sourceExtensions = []string{".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".mts", ".cts"}
+
+// specifiers match the module specifiers of require calls, dynamic imports,
+// import and export declarations, and side effect imports
+var specifiers = []*regexp.Regexp{
+	regexp.MustCompile(`\brequire\s*\(\s*['"]([^'"]+)['"]\s*\)`),
+	regexp.MustCompile(`\bimport\s*\(\s*['"]([^'"]+)['"]`),
+	regexp.MustCompile(`\b(?:import|export)\s[^'";]*?\bfrom\s*['"]([^'"]+)['"]`),
+	regexp.MustCompile(`\bimport\s*['"]([^'"]+)['"]`),
+}
+
+// builtins are the modules shipped with Node.js
+var builtins = []string{
+	"assert", "async_hooks", "buffer", "child_process", "cluster", "console", "constants", "crypto",
+	"dgram", "diagnostics_channel", "dns", "domain", "events", "fs", "http", "http2", "https",
+	"inspector", "module", "net", "os", "path", "perf_hooks", "process", "punycode", "querystring",
+	"readline", "repl", "stream", "string_decoder", "sys", "timers", "tls", "trace_events", "tty",
+	"url", "util", "v8", "vm", "wasi", "worker_threads", "zlib",
+}
+
+// npmImports returns the names of the packages imported by the JavaScript and
+// TypeScript sources of the package in fsys. Installed packages, hidden
+// directories and nested packages such as workspaces are skipped.
+func npmImports(fsys fs.FS) (map[string]bool, error) {
+	imports := make(map[string]bool)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name != "." && skipNPMDir(fsys, name) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !slices.Contains(sourceExtensions, path.Ext(name)) || strings.HasSuffix(name, ".d.ts") {
+			return nil
+		}
+
+		src, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		for _, re := range specifiers {
+			for _, match := range re.FindAllSubmatch(src, -1) {
+				if pkg, ok := importedPackage(string(match[1])); ok {
+					imports[pkg] = true
+				}
+			}
+		}
+		return nil
+	})
+	return imports, err
+}
+
+func skipNPMDir(fsys fs.FS, dir string) bool {
+	base := path.Base(dir)
+	if base == "node_modules" || strings.HasPrefix(base, ".") {
+		return true
+	}
+	_, err := fs.Stat(fsys, path.Join(dir, "package.json"))
+	return err == nil
+}
+
+// importedPackage returns the package a module specifier resolves to, if it
+// names an installable package rather than a file, a builtin, a URL or a
+// subpath import
+func importedPackage(specifier string) (string, bool) {
+	if specifier == "" || strings.ContainsAny(specifier[:1], "./#~") || strings.Contains(specifier, ":") {
+		return "", false
+	}
+
+	parts := strings.Split(specifier, "/")
+	name := parts[0]
+	if strings.HasPrefix(name, "@") {
+		// "@/" is a common alias for the source directory, not a scope
+		if len(name) == 1 || len(parts) < 2 {
+			return "", false
+		}
+		name += "/" + parts[1]
+	}
+	if slices.Contains(builtins, parts[0]) {
+		return "", false
+	}
+	return name, true
+}
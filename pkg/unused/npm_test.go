@@ -0,0 +1,68 @@
+package unused
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportedPackage(t *testing.T) {
+	for specifier, want := range map[string]string{
+		"lodash":             "lodash",
+		"lodash/fp":          "lodash",
+		"@babel/core":        "@babel/core",
+		"@babel/core/lib/x":  "@babel/core",
+		"./util":             "",
+		"../util":            "",
+		"/abs/path":          "",
+		"#internal":          "",
+		"~/components":       "",
+		"@/components":       "",
+		"@scope":             "",
+		"fs":                 "",
+		"fs/promises":        "",
+		"node:test":          "",
+		"https://esm.sh/x":   "",
+		"string_decoder/abc": "",
+	} {
+		name, ok := importedPackage(specifier)
+		assert.Equal(t, want, name, specifier)
+		assert.Equal(t, want != "", ok, specifier)
+	}
+}
+
+func TestNPMImports(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json": {Data: []byte(`{"name": "app"}`)},
+		"index.js": {Data: []byte(`const express = require('express')
+const { join } = require("path")
+import("chalk").then(() => {})
+`)},
+		"src/app.tsx": {Data: []byte(`import React, {
+  useState,
+} from 'react'
+import type { Config } from "@acme/config/types"
+export * from './local'
+import 'reflect-metadata'
+`)},
+		"src/types.d.ts":                {Data: []byte(`import "declared-only"`)},
+		"node_modules/express/index.js": {Data: []byte(`require("body-parser")`)},
+		".storybook/main.js":            {Data: []byte(`require("storybook")`)},
+		"packages/ui/package.json":      {Data: []byte(`{"name": "ui"}`)},
+		"packages/ui/index.js":          {Data: []byte(`require("styled-components")`)},
+		"README.md":                     {Data: []byte(`require("markdown")`)},
+	}
+
+	imports, err := npmImports(fsys)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]bool{
+		"express":          true,
+		"chalk":            true,
+		"react":            true,
+		"@acme/config":     true,
+		"reflect-metadata": true,
+	}, imports)
+}
@@ -0,0 +1,111 @@
+package unused
+
+import (
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Enrichment is the name of the unused dependency check in ScanOptions.Enrich
+const Enrichment = "unused"
+
+// Rule is the rule name of findings about declared dependencies that no
+// source file imports
+const Rule = "unused"
+
+// PhantomRule is the rule name of findings about imported packages that the
+// project does not declare
+const PhantomRule = "phantom"
+
+// Enrich adds a warning finding for every direct dependency of the project in
+// fsys that none of its source files imports. For npm projects it also adds
+// one for every imported package missing from package.json, which only
+// resolves while another dependency happens to install it. Only Go and npm
+// projects are analyzed; other project types are left untouched.
+func Enrich(fsys fs.FS, projectType string, result *scanners.ScanResult) error {
+	switch projectType {
+	case "go":
+		imports, err := goImports(fsys)
+		if err != nil {
+			return err
+		}
+		var modules []string
+		for _, dep := range result.Dependencies {
+			modules = append(modules, dep.Name)
+		}
+		imported := importedModules(imports, modules)
+
+		for _, dep := range result.Dependencies {
+			if !dep.IsDirectDep || dep.Type != "go" || imported[dep.Name] {
+				continue
+			}
+			result.Findings = append(result.Findings, scanners.Finding{
+				Rule:       Rule,
+				Severity:   scanners.SeverityWarning,
+				Dependency: dep.Name,
+				Version:    dep.Version,
+				Message:    fmt.Sprintf("%s is required in go.mod but no package imports it", dep.Name),
+			})
+		}
+
+	case "npm":
+		m, err := readManifest(fsys)
+		if err != nil {
+			return err
+		}
+		imported, err := npmImports(fsys)
+		if err != nil {
+			return err
+		}
+		versions := make(map[string]string)
+		for _, dep := range result.Dependencies {
+			if _, ok := versions[dep.Name]; !ok || dep.IsDirectDep {
+				versions[dep.Name] = dep.Version
+			}
+		}
+
+		// Type definitions are used by the compiler, not imported
+		for _, name := range sortedKeys(m.Dependencies) {
+			if imported[name] || strings.HasPrefix(name, "@types/") {
+				continue
+			}
+			result.Findings = append(result.Findings, scanners.Finding{
+				Rule:       Rule,
+				Severity:   scanners.SeverityWarning,
+				Dependency: name,
+				Version:    versions[name],
+				Message:    fmt.Sprintf("%s is declared in package.json dependencies but no source file imports it", name),
+			})
+		}
+
+		for _, name := range sortedKeys(imported) {
+			if name == m.Name || m.declares(name) {
+				continue
+			}
+			message := fmt.Sprintf("%s is imported but not declared in package.json", name)
+			if _, ok := versions[name]; ok {
+				message += "; it only resolves because another dependency installs it"
+			}
+			result.Findings = append(result.Findings, scanners.Finding{
+				Rule:       PhantomRule,
+				Severity:   scanners.SeverityWarning,
+				Dependency: name,
+				Version:    versions[name],
+				Message:    message,
+			})
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,69 @@
+package unused
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func TestEnrich(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod":             {Data: []byte("module example.com/app\n")},
+		"main.go":            {Data: []byte("package main\n\nimport (\n\t\"fmt\"\n\n\t\"github.com/spf13/cobra\"\n\t\"example.com/app/internal\"\n)\n")},
+		"internal/x_test.go": {Data: []byte("package internal\n\nimport \"github.com/stretchr/testify/assert\"\n")},
+		"tools.go":           {Data: []byte("//go:build tools\n\npackage main\n\nimport _ \"golang.org/x/tools/cmd/stringer\"\n")},
+	}
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "github.com/spf13/cobra", Version: "v1.8.0", Type: "go", IsDirectDep: true},
+			{Name: "github.com/stretchr/testify", Version: "v1.9.0", Type: "go", IsDirectDep: true},
+			{Name: "golang.org/x/tools", Version: "v0.20.0", Type: "go", IsDirectDep: true},
+			{Name: "github.com/pkg/errors", Version: "v0.9.1", Type: "go", IsDirectDep: true},
+			{Name: "github.com/spf13/pflag", Version: "v1.0.5", Type: "go"},
+		},
+	}
+
+	assert.NoError(t, Enrich(fsys, "go", result))
+	assert.Equal(t, []scanners.Finding{{
+		Rule:       Rule,
+		Severity:   scanners.SeverityWarning,
+		Dependency: "github.com/pkg/errors",
+		Version:    "v0.9.1",
+		Message:    "github.com/pkg/errors is required in go.mod but no package imports it",
+	}}, result.Findings)
+}
+
+func TestEnrichNPM(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.json": {Data: []byte(`{
+			"name": "app",
+			"dependencies": {"express": "^4.18.0", "lodash": "^4.17.21", "@types/node": "^20.0.0"},
+			"devDependencies": {"jest": "^29.0.0"}
+		}`)},
+		"index.js":      {Data: []byte(`require("express"); require("debug"); require("left-pad"); require("app/lib")`)},
+		"index.test.js": {Data: []byte(`const { test } = require("jest")`)},
+	}
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "express", Version: "4.18.2", Type: "npm", IsDirectDep: true},
+			{Name: "lodash", Version: "4.17.21", Type: "npm", IsDirectDep: true},
+			{Name: "debug", Version: "2.6.9", Type: "npm"},
+		},
+	}
+
+	assert.NoError(t, Enrich(fsys, "npm", result))
+	assert.Equal(t, []scanners.Finding{
+		{Rule: Rule, Severity: scanners.SeverityWarning, Dependency: "lodash", Version: "4.17.21", Message: "lodash is declared in package.json dependencies but no source file imports it"},
+		{Rule: PhantomRule, Severity: scanners.SeverityWarning, Dependency: "debug", Version: "2.6.9", Message: "debug is imported but not declared in package.json; it only resolves because another dependency installs it"},
+		{Rule: PhantomRule, Severity: scanners.SeverityWarning, Dependency: "left-pad", Message: "left-pad is imported but not declared in package.json"},
+	}, result.Findings)
+}
+
+func TestEnrichOtherProjects(t *testing.T) {
+	result := &scanners.ScanResult{Dependencies: []scanners.Dependency{{Name: "left-pad", Version: "1.3.0", Type: "npm", IsDirectDep: true}}}
+	assert.NoError(t, Enrich(fstest.MapFS{}, "pip", result))
+	assert.Empty(t, result.Findings)
+}
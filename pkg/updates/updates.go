@@ -0,0 +1,233 @@
+package updates
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/santoshdahal12/deplister/pkg/outdated"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Config formats
+const (
+	Dependabot = "dependabot" // .github/dependabot.yml
+	Renovate   = "renovate"   // renovate.json
+)
+
+// Formats lists the config formats
+var Formats = []string{Dependabot, Renovate}
+
+// Schedule intervals, shared by both formats
+const (
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+)
+
+// Intervals lists the schedule intervals
+var Intervals = []string{Daily, Weekly, Monthly}
+
+// DefaultLimit is the number of pull requests Dependabot keeps open per
+// project by default
+const DefaultLimit = 5
+
+// ecosystem names a scanner type in the update tools
+type ecosystem struct {
+	dependabot string // package-ecosystem
+	renovate   string // Manager
+	manifest   string // File both tools read the dependencies from
+}
+
+// ecosystems maps the scanner types the tools update to their names
+var ecosystems = map[string]ecosystem{
+	"npm": {dependabot: "npm", renovate: "npm", manifest: "package.json"},
+	"go":  {dependabot: "gomod", renovate: "gomod", manifest: "go.mod"},
+}
+
+// Manifest returns the file the update tools read the dependencies of a
+// project of the scanner type from, or "" when they do not support it
+func Manifest(projectType string) string {
+	return ecosystems[projectType].manifest
+}
+
+// Project is a project found in the repository
+type Project struct {
+	Dir    string               // Slash separated path relative to the repository root, "." for the root
+	Type   string               // Scanner type
+	Result *scanners.ScanResult // Scan with outdated data, nil when the scan failed
+}
+
+// Plan is how updates of a project are configured
+type Plan struct {
+	Dir     string
+	Type    string
+	Major   []string // Outdated direct dependencies by update, sorted
+	Minor   []string
+	Patch   []string
+	Grouped bool // Minor and patch updates share one pull request
+	Limit   int  // Open pull requests needed to update everything at once
+}
+
+// Manifest returns the slash separated path of the project's manifest
+func (p Plan) Manifest() string {
+	return path.Join(p.Dir, Manifest(p.Type))
+}
+
+// Suggest plans the updates of the projects the tools support, in order of
+// directory, and returns the projects they do not support. Each outdated
+// direct dependency with a major update gets a pull request of its own,
+// while minor and patch updates are grouped when there are several.
+func Suggest(projects []Project) (plans []Plan, unsupported []Project) {
+	for _, project := range projects {
+		if Manifest(project.Type) == "" {
+			unsupported = append(unsupported, project)
+			continue
+		}
+		plan := Plan{Dir: project.Dir, Type: project.Type}
+		if project.Result != nil {
+			for _, dep := range project.Result.Dependencies {
+				if !dep.IsDirectDep {
+					continue
+				}
+				switch dep.Properties["update"] {
+				case outdated.Major:
+					plan.Major = append(plan.Major, dep.Name)
+				case outdated.Minor:
+					plan.Minor = append(plan.Minor, dep.Name)
+				case outdated.Patch:
+					plan.Patch = append(plan.Patch, dep.Name)
+				}
+			}
+		}
+		sort.Strings(plan.Major)
+		sort.Strings(plan.Minor)
+		sort.Strings(plan.Patch)
+		plan.Grouped = len(plan.Minor)+len(plan.Patch) > 1
+		plan.Limit = len(plan.Major) + len(plan.Minor) + len(plan.Patch)
+		if plan.Grouped {
+			plan.Limit = len(plan.Major) + 1
+		}
+		plans = append(plans, plan)
+	}
+	sort.SliceStable(plans, func(i, j int) bool {
+		if plans[i].Dir != plans[j].Dir {
+			return plans[i].Dir < plans[j].Dir
+		}
+		return plans[i].Type < plans[j].Type
+	})
+	return plans, unsupported
+}
+
+// Write writes the plans as a config of the format, updating on the
+// schedule interval
+func Write(writer io.Writer, format, interval string, plans []Plan) error {
+	switch format {
+	case Dependabot:
+		return writeDependabot(writer, interval, plans)
+	case Renovate:
+		return writeRenovate(writer, interval, plans)
+	}
+	return fmt.Errorf("unknown config format %q", format)
+}
+
+type dependabotConfig struct {
+	Version int                `yaml:"version"`
+	Updates []dependabotUpdate `yaml:"updates"`
+}
+
+type dependabotUpdate struct {
+	Ecosystem string                     `yaml:"package-ecosystem"`
+	Directory string                     `yaml:"directory"`
+	Schedule  dependabotSchedule         `yaml:"schedule"`
+	Limit     int                        `yaml:"open-pull-requests-limit,omitempty"`
+	Groups    map[string]dependabotGroup `yaml:"groups,omitempty"`
+}
+
+type dependabotSchedule struct {
+	Interval string `yaml:"interval"`
+}
+
+type dependabotGroup struct {
+	UpdateTypes []string `yaml:"update-types"`
+}
+
+// writeDependabot writes a dependabot.yml with an update per plan. The
+// limit of open pull requests is only raised above DefaultLimit.
+func writeDependabot(writer io.Writer, interval string, plans []Plan) error {
+	config := dependabotConfig{Version: 2, Updates: []dependabotUpdate{}}
+	for _, plan := range plans {
+		update := dependabotUpdate{
+			Ecosystem: ecosystems[plan.Type].dependabot,
+			Directory: path.Join("/", plan.Dir),
+			Schedule:  dependabotSchedule{Interval: interval},
+		}
+		if plan.Limit > DefaultLimit {
+			update.Limit = plan.Limit
+		}
+		if plan.Grouped {
+			update.Groups = map[string]dependabotGroup{
+				"minor-and-patch": {UpdateTypes: []string{"minor", "patch"}},
+			}
+		}
+		config.Updates = append(config.Updates, update)
+	}
+
+	encoder := yaml.NewEncoder(writer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+type renovateConfig struct {
+	Schema          string         `json:"$schema"`
+	Extends         []string       `json:"extends"`
+	EnabledManagers []string       `json:"enabledManagers"`
+	IncludePaths    []string       `json:"includePaths"`
+	PackageRules    []renovateRule `json:"packageRules,omitempty"`
+}
+
+type renovateRule struct {
+	MatchFileNames   []string `json:"matchFileNames"`
+	MatchUpdateTypes []string `json:"matchUpdateTypes"`
+	GroupName        string   `json:"groupName"`
+}
+
+// writeRenovate writes a renovate.json limited to the managers and
+// manifests of the plans, with a rule grouping the minor and patch updates
+// of each grouped plan
+func writeRenovate(writer io.Writer, interval string, plans []Plan) error {
+	config := renovateConfig{
+		Schema:          "https://docs.renovatebot.com/renovate-schema.json",
+		Extends:         []string{"config:recommended", "schedule:" + interval},
+		EnabledManagers: []string{},
+		IncludePaths:    []string{},
+	}
+	managers := make(map[string]bool)
+	for _, plan := range plans {
+		if manager := ecosystems[plan.Type].renovate; !managers[manager] {
+			managers[manager] = true
+			config.EnabledManagers = append(config.EnabledManagers, manager)
+		}
+		config.IncludePaths = append(config.IncludePaths, plan.Manifest())
+		if plan.Grouped {
+			config.PackageRules = append(config.PackageRules, renovateRule{
+				MatchFileNames:   []string{plan.Manifest()},
+				MatchUpdateTypes: []string{"minor", "patch"},
+				GroupName:        fmt.Sprintf("%s minor and patch updates", plan.Manifest()),
+			})
+		}
+	}
+	sort.Strings(config.EnabledManagers)
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(config)
+}
@@ -0,0 +1,85 @@
+package updates
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func outdatedDep(name, update string, direct bool) scanners.Dependency {
+	return scanners.Dependency{Name: name, IsDirectDep: direct, Properties: map[string]string{"update": update}}
+}
+
+func testProjects() []Project {
+	return []Project{
+		{Dir: "web", Type: "npm", Result: &scanners.ScanResult{Dependencies: []scanners.Dependency{
+			outdatedDep("react", "major", true),
+			outdatedDep("lodash", "minor", true),
+			outdatedDep("express", "patch", true),
+			outdatedDep("ms", "major", false),
+			{Name: "chalk", IsDirectDep: true},
+		}}},
+		{Dir: ".", Type: "go", Result: &scanners.ScanResult{Dependencies: []scanners.Dependency{
+			outdatedDep("golang.org/x/mod", "minor", true),
+		}}},
+		{Dir: "tools", Type: "cargo"},
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	plans, unsupported := Suggest(testProjects())
+
+	assert.Equal(t, []Plan{
+		{Dir: ".", Type: "go", Minor: []string{"golang.org/x/mod"}, Limit: 1},
+		{Dir: "web", Type: "npm", Major: []string{"react"}, Minor: []string{"lodash"}, Patch: []string{"express"}, Grouped: true, Limit: 2},
+	}, plans)
+	assert.Equal(t, []Project{{Dir: "tools", Type: "cargo"}}, unsupported)
+	assert.Equal(t, "go.mod", plans[0].Manifest())
+	assert.Equal(t, "web/package.json", plans[1].Manifest())
+}
+
+func TestWrite_Dependabot(t *testing.T) {
+	plans, _ := Suggest(testProjects())
+	plans[1].Limit = 8
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, Dependabot, Weekly, plans))
+	assert.Equal(t, `version: 2
+updates:
+  - package-ecosystem: gomod
+    directory: /
+    schedule:
+      interval: weekly
+  - package-ecosystem: npm
+    directory: /web
+    schedule:
+      interval: weekly
+    open-pull-requests-limit: 8
+    groups:
+      minor-and-patch:
+        update-types:
+          - minor
+          - patch
+`, buf.String())
+}
+
+func TestWrite_Renovate(t *testing.T) {
+	plans, _ := Suggest(testProjects())
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, Renovate, Monthly, plans))
+	assert.JSONEq(t, `{
+		"$schema": "https://docs.renovatebot.com/renovate-schema.json",
+		"extends": ["config:recommended", "schedule:monthly"],
+		"enabledManagers": ["gomod", "npm"],
+		"includePaths": ["go.mod", "web/package.json"],
+		"packageRules": [
+			{"matchFileNames": ["web/package.json"], "matchUpdateTypes": ["minor", "patch"], "groupName": "web/package.json minor and patch updates"}
+		]
+	}`, buf.String())
+
+	assert.Error(t, Write(&buf, "greenkeeper", Weekly, plans))
+}
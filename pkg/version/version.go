@@ -0,0 +1,352 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Common errors
+var (
+	ErrInvalidConstraint = errors.New("invalid version constraint")
+)
+
+// Compare compares semantic versions, ignoring a "v" prefix and build
+// metadata. "0" sorts before every other version.
+func Compare(a, b string) int {
+	if a == b {
+		return 0
+	}
+	if a == "0" {
+		return -1
+	}
+	if b == "0" {
+		return 1
+	}
+
+	aCore, aPre := split(a)
+	bCore, bPre := split(b)
+
+	if c := compareIdentifiers(strings.Split(aCore, "."), strings.Split(bCore, "."), true); c != 0 {
+		return c
+	}
+
+	// A version without a prerelease sorts after its prereleases
+	switch {
+	case aPre == "" && bPre == "":
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareIdentifiers(strings.Split(aPre, "."), strings.Split(bPre, "."), false)
+}
+
+// Prerelease reports whether the version has a prerelease part
+func Prerelease(v string) bool {
+	_, pre := split(v)
+	return pre != ""
+}
+
+// Parts returns the numeric major, minor and patch components of a version,
+// treating missing or non-numeric components as zero
+func Parts(v string) [3]uint64 {
+	core, _ := split(v)
+	var parts [3]uint64
+	for i, field := range strings.SplitN(core, ".", 3) {
+		parts[i], _ = strconv.ParseUint(field, 10, 64)
+	}
+	return parts
+}
+
+func split(v string) (core, prerelease string) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+	core, prerelease, _ = strings.Cut(v, "-")
+	return core, prerelease
+}
+
+// compareIdentifiers compares dot separated identifiers, numerically where
+// both are numbers. Missing core components count as zero.
+func compareIdentifiers(a, b []string, padZero bool) int {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var x, y string
+		switch {
+		case i < len(a) && i < len(b):
+			x, y = a[i], b[i]
+		case padZero && i < len(a):
+			x, y = a[i], "0"
+		case padZero:
+			x, y = "0", b[i]
+		case i < len(a):
+			return 1
+		default:
+			return -1
+		}
+
+		xn, xErr := strconv.ParseUint(x, 10, 64)
+		yn, yErr := strconv.ParseUint(y, 10, 64)
+		switch {
+		case xErr == nil && yErr == nil:
+			if xn != yn {
+				if xn < yn {
+					return -1
+				}
+				return 1
+			}
+		case xErr == nil:
+			return -1
+		case yErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// comparator is a single operator and version, e.g. ">=1.2.0"
+type comparator struct {
+	op      string
+	version string
+}
+
+func (c comparator) check(v string) bool {
+	cmp := Compare(v, c.version)
+	switch c.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "!=":
+		return cmp != 0
+	}
+	return cmp == 0
+}
+
+// Constraint is a set of version ranges. Comparators separated by spaces must
+// all match and ranges separated by "||" are alternatives, as in npm.
+type Constraint struct {
+	raw    string
+	ranges [][]comparator
+}
+
+// ParseConstraint parses constraints such as "<4.17.21", ">=1.0.0 <2.0.0",
+// "^1.2.3", "~1.2", "1.x" or "1.0.0 || 2.0.0". "*" and "" match anything.
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{raw: strings.TrimSpace(s)}
+	for _, alternative := range strings.Split(s, "||") {
+		var comparators []comparator
+		fields := strings.Fields(alternative)
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+
+			// Hyphen ranges: "1.2.3 - 2.3.4"
+			if i+2 < len(fields) && fields[i+1] == "-" {
+				comparators = append(comparators, comparator{">=", fields[i]})
+				comparators = append(comparators, upperBound(fields[i+2])...)
+				i += 2
+				continue
+			}
+
+			// Allow a space between operator and version: ">= 1.2.3"
+			if isOperator(field) && i+1 < len(fields) {
+				i++
+				field += fields[i]
+			}
+
+			parsed, err := parseComparator(field)
+			if err != nil {
+				return Constraint{}, fmt.Errorf("%w %q: %v", ErrInvalidConstraint, s, err)
+			}
+			comparators = append(comparators, parsed...)
+		}
+		c.ranges = append(c.ranges, comparators)
+	}
+	return c, nil
+}
+
+// MustParseConstraint is like ParseConstraint but panics on invalid input
+func MustParseConstraint(s string) Constraint {
+	c, err := ParseConstraint(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Check reports whether the version satisfies the constraint. As in npm, a
+// prerelease only matches a range naming a prerelease of the same version.
+func (c Constraint) Check(v string) bool {
+	for _, comparators := range c.ranges {
+		matched := true
+		for _, comp := range comparators {
+			if !comp.check(v) {
+				matched = false
+				break
+			}
+		}
+		if matched && (!Prerelease(v) || allowsPrerelease(comparators, v)) {
+			return true
+		}
+	}
+	return false
+}
+
+func allowsPrerelease(comparators []comparator, v string) bool {
+	if len(comparators) == 0 {
+		return false
+	}
+	for _, comp := range comparators {
+		if Prerelease(comp.version) && Parts(comp.version) == Parts(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Constraint) String() string {
+	return c.raw
+}
+
+func isOperator(s string) bool {
+	switch s {
+	case "<", "<=", ">", ">=", "=", "==", "!=", "^", "~":
+		return true
+	}
+	return false
+}
+
+// parseComparator expands a single term into plain comparators
+func parseComparator(term string) ([]comparator, error) {
+	op := ""
+	for _, candidate := range []string{"<=", ">=", "!=", "==", "<", ">", "=", "^", "~"} {
+		if strings.HasPrefix(term, candidate) {
+			op = candidate
+			break
+		}
+	}
+	v := strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(term, op)), "v")
+
+	if v == "" || v == "*" || v == "x" || v == "X" {
+		if op != "" && op != "=" && op != "==" {
+			return nil, errors.New("missing version")
+		}
+		return nil, nil
+	}
+
+	parts, wildcard, err := partial(v)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "^":
+		return caret(v, parts, wildcard), nil
+	case "~":
+		return tilde(parts, wildcard), nil
+	case "", "=", "==":
+		if wildcard < 3 {
+			return wildcardRange(parts, wildcard), nil
+		}
+		return []comparator{{"=", v}}, nil
+	case "!=":
+		return []comparator{{op, v}}, nil
+	}
+	if wildcard == 3 {
+		return []comparator{{op, v}}, nil
+	}
+	return []comparator{{op, fill(parts, wildcard)}}, nil
+}
+
+// partial parses a possibly incomplete version such as "1", "1.2" or "1.x".
+// wildcard is the index of the first missing component, or 3 when complete.
+func partial(v string) (parts [3]uint64, wildcard int, err error) {
+	core, _ := split(v)
+	fields := strings.Split(core, ".")
+	if len(fields) > 3 {
+		return parts, 0, fmt.Errorf("malformed version %q", v)
+	}
+
+	wildcard = 3
+	for i := 0; i < 3; i++ {
+		if i >= len(fields) || fields[i] == "x" || fields[i] == "X" || fields[i] == "*" {
+			wildcard = i
+			break
+		}
+		if parts[i], err = strconv.ParseUint(fields[i], 10, 64); err != nil {
+			return parts, 0, fmt.Errorf("malformed version %q", v)
+		}
+	}
+	return parts, wildcard, nil
+}
+
+func fill(parts [3]uint64, wildcard int) string {
+	for i := wildcard; i < 3; i++ {
+		parts[i] = 0
+	}
+	return fmt.Sprintf("%d.%d.%d", parts[0], parts[1], parts[2])
+}
+
+// wildcardRange matches every version with the given leading components
+func wildcardRange(parts [3]uint64, wildcard int) []comparator {
+	if wildcard == 0 {
+		return nil
+	}
+	upper := parts
+	upper[wildcard-1]++
+	return []comparator{{">=", fill(parts, wildcard)}, {"<", fill(upper, wildcard) + "-0"}}
+}
+
+// upperBound is the inclusive end of a hyphen range
+func upperBound(v string) []comparator {
+	parts, wildcard, err := partial(v)
+	if err != nil || wildcard == 0 {
+		return nil
+	}
+	if wildcard < 3 {
+		upper := parts
+		upper[wildcard-1]++
+		return []comparator{{"<", fill(upper, wildcard) + "-0"}}
+	}
+	return []comparator{{"<=", v}}
+}
+
+// caret allows changes that do not modify the left-most non-zero component
+func caret(v string, parts [3]uint64, wildcard int) []comparator {
+	lower := v
+	if wildcard < 3 {
+		lower = fill(parts, wildcard)
+	}
+
+	upper := parts
+	switch {
+	case parts[0] > 0 || wildcard == 1:
+		upper = [3]uint64{parts[0] + 1, 0, 0}
+	case parts[1] > 0 || wildcard == 2:
+		upper = [3]uint64{0, parts[1] + 1, 0}
+	default:
+		upper = [3]uint64{0, 0, parts[2] + 1}
+	}
+	return []comparator{{">=", lower}, {"<", fill(upper, 3) + "-0"}}
+}
+
+// tilde allows patch level changes, or minor changes when only the major
+// version is given
+func tilde(parts [3]uint64, wildcard int) []comparator {
+	upper := [3]uint64{parts[0], parts[1] + 1, 0}
+	if wildcard == 1 {
+		upper = [3]uint64{parts[0] + 1, 0, 0}
+	}
+	return []comparator{{">=", fill(parts, wildcard)}, {"<", fill(upper, 3) + "-0"}}
+}
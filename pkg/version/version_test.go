@@ -0,0 +1,97 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.0", "1.0.0", 0},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0-beta", 1},
+		{"1.0.0+build", "1.0.0", 0},
+		{"0", "0.0.1", -1},
+		{"0.0.0-20210101000000-abcdef", "0.0.1", -1},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Compare(tt.a, tt.b), "%s vs %s", tt.a, tt.b)
+		assert.Equal(t, -tt.want, Compare(tt.b, tt.a), "%s vs %s", tt.b, tt.a)
+	}
+}
+
+func TestParts(t *testing.T) {
+	assert.Equal(t, [3]uint64{1, 2, 3}, Parts("v1.2.3-rc.1"))
+	assert.Equal(t, [3]uint64{4, 0, 0}, Parts("4"))
+	assert.True(t, Prerelease("1.0.0-beta"))
+	assert.False(t, Prerelease("1.0.0+build"))
+}
+
+func TestConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		matches    []string
+		rejects    []string
+	}{
+		{"<4.17.21", []string{"4.17.20", "3.0.0"}, []string{"4.17.21", "5.0.0"}},
+		{">=1.0.0 <2.0.0", []string{"1.0.0", "1.9.9"}, []string{"0.9.0", "2.0.0", "2.0.0-alpha"}},
+		{">= 1.2.3", []string{"1.2.3", "v2.0.0"}, []string{"1.2.2"}},
+		{"1.2.3", []string{"1.2.3", "v1.2.3"}, []string{"1.2.4"}},
+		{"=1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{"!=1.2.3", []string{"1.2.4"}, []string{"1.2.3"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"1.2.2", "2.0.0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"^1.x", []string{"1.0.0", "1.5.0"}, []string{"2.0.0"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.3.0"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{"1.x", []string{"1.0.0", "1.99.0"}, []string{"2.0.0", "0.9.0"}},
+		{"1.2", []string{"1.2.0", "1.2.7"}, []string{"1.3.0"}},
+		{"1.2.3 - 2.3", []string{"1.2.3", "2.3.9"}, []string{"2.4.0", "1.2.2"}},
+		{"1.0.0 || >=3.0.0", []string{"1.0.0", "3.1.0"}, []string{"2.0.0"}},
+		{"*", []string{"0.0.1", "9.9.9"}, nil},
+		{"", []string{"1.0.0"}, nil},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if !assert.NoError(t, err, tt.constraint) {
+			continue
+		}
+		for _, v := range tt.matches {
+			assert.True(t, c.Check(v), "%s should match %s", tt.constraint, v)
+		}
+		for _, v := range tt.rejects {
+			assert.False(t, c.Check(v), "%s should reject %s", tt.constraint, v)
+		}
+	}
+}
+
+func TestParseConstraint_Invalid(t *testing.T) {
+	for _, s := range []string{">=", "^abc", "1.2.3.4", "<1.a"} {
+		_, err := ParseConstraint(s)
+		assert.ErrorIs(t, err, ErrInvalidConstraint, s)
+	}
+}
+
+func TestConstraint_Prerelease(t *testing.T) {
+	c := MustParseConstraint(">=1.2.3-beta.1 <2.0.0")
+	assert.True(t, c.Check("1.2.3-beta.2"))
+	assert.False(t, c.Check("1.2.4-beta.1"))
+	assert.True(t, c.Check("1.2.4"))
+
+	assert.False(t, MustParseConstraint("*").Check("1.0.0-rc.1"))
+	assert.True(t, MustParseConstraint("1.0.0-rc.1").Check("1.0.0-rc.1"))
+}
@@ -0,0 +1,120 @@
+package vex
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// csafStatuses maps CSAF product status groups to VEX statuses
+var csafStatuses = []struct{ group, status string }{
+	{"under_investigation", UnderInvestigation},
+	{"known_affected", Affected},
+	{"known_not_affected", NotAffected},
+	{"fixed", Fixed},
+}
+
+type csafProduct struct {
+	ProductID string `json:"product_id"`
+	Helper    struct {
+		PURL string `json:"purl"`
+	} `json:"product_identification_helper"`
+}
+
+type csafBranch struct {
+	Product  *csafProduct `json:"product"`
+	Branches []csafBranch `json:"branches"`
+}
+
+type csafDocument struct {
+	Document struct {
+		Category string `json:"category"`
+	} `json:"document"`
+	ProductTree struct {
+		Branches         []csafBranch  `json:"branches"`
+		FullProductNames []csafProduct `json:"full_product_names"`
+		Relationships    []struct {
+			FullProductName  csafProduct `json:"full_product_name"`
+			ProductReference string      `json:"product_reference"`
+		} `json:"relationships"`
+	} `json:"product_tree"`
+	Vulnerabilities []struct {
+		CVE string `json:"cve"`
+		IDs []struct {
+			Text string `json:"text"`
+		} `json:"ids"`
+		ProductStatus map[string][]string `json:"product_status"`
+		Flags         []struct {
+			Label      string   `json:"label"`
+			ProductIDs []string `json:"product_ids"`
+		} `json:"flags"`
+	} `json:"vulnerabilities"`
+}
+
+// parseCSAF reads a CSAF document of the csaf_vex category. Products are
+// identified by the package URLs of their identification helpers.
+func parseCSAF(data []byte) (*Document, error) {
+	var doc csafDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+	if doc.Document.Category != "csaf_vex" {
+		return nil, fmt.Errorf("%w: CSAF category %q is not csaf_vex", ErrInvalidDocument, doc.Document.Category)
+	}
+
+	purls := make(map[string]string)
+	var walk func([]csafBranch)
+	walk = func(branches []csafBranch) {
+		for _, branch := range branches {
+			if branch.Product != nil && branch.Product.Helper.PURL != "" {
+				purls[branch.Product.ProductID] = branch.Product.Helper.PURL
+			}
+			walk(branch.Branches)
+		}
+	}
+	walk(doc.ProductTree.Branches)
+	for _, product := range doc.ProductTree.FullProductNames {
+		if product.Helper.PURL != "" {
+			purls[product.ProductID] = product.Helper.PURL
+		}
+	}
+	// A component installed in a product is identified by the component
+	for _, relationship := range doc.ProductTree.Relationships {
+		id := relationship.FullProductName.ProductID
+		if purl := relationship.FullProductName.Helper.PURL; purl != "" {
+			purls[id] = purl
+		} else if purl, ok := purls[relationship.ProductReference]; ok {
+			purls[id] = purl
+		}
+	}
+
+	result := &Document{}
+	for _, vuln := range doc.Vulnerabilities {
+		var aliases []string
+		for _, id := range vuln.IDs {
+			aliases = append(aliases, id.Text)
+		}
+		justifications := make(map[string]string)
+		for _, flag := range vuln.Flags {
+			for _, id := range flag.ProductIDs {
+				justifications[id] = flag.Label
+			}
+		}
+
+		for _, status := range csafStatuses {
+			for _, id := range vuln.ProductStatus[status.group] {
+				purl, ok := purls[id]
+				if !ok {
+					continue
+				}
+				result.Statements = append(result.Statements, Statement{
+					Vulnerability: vuln.CVE,
+					Aliases:       aliases,
+					Products:      []string{purl},
+					Status:        status.status,
+					Justification: justifications[id],
+				})
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package vex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_CSAF(t *testing.T) {
+	doc, err := Parse([]byte(`{
+		"document": {"category": "csaf_vex", "csaf_version": "2.0", "title": "Example VEX"},
+		"product_tree": {
+			"branches": [{"category": "vendor", "name": "Example", "branches": [{
+				"category": "product_version", "name": "app 1.0",
+				"product": {"product_id": "APP-1", "name": "app 1.0", "product_identification_helper": {"purl": "pkg:github/example/app@1.0"}}
+			}]}],
+			"full_product_names": [
+				{"product_id": "LODASH", "name": "lodash 4.17.15", "product_identification_helper": {"purl": "pkg:npm/lodash@4.17.15"}},
+				{"product_id": "NOPURL", "name": "vendored thing"}
+			],
+			"relationships": [{
+				"category": "default_component_of",
+				"full_product_name": {"product_id": "APP-1:LODASH", "name": "lodash in app"},
+				"product_reference": "LODASH",
+				"relates_to_product_reference": "APP-1"
+			}]
+		},
+		"vulnerabilities": [{
+			"cve": "CVE-2024-0001",
+			"ids": [{"system_name": "GitHub", "text": "GHSA-xxxx-yyyy-zzzz"}],
+			"product_status": {"known_not_affected": ["APP-1:LODASH", "NOPURL"], "known_affected": ["APP-1"]},
+			"flags": [{"label": "vulnerable_code_not_in_execute_path", "product_ids": ["APP-1:LODASH"]}]
+		}]
+	}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []Statement{
+		{Vulnerability: "CVE-2024-0001", Aliases: []string{"GHSA-xxxx-yyyy-zzzz"}, Products: []string{"pkg:github/example/app@1.0"}, Status: Affected},
+		{
+			Vulnerability: "CVE-2024-0001",
+			Aliases:       []string{"GHSA-xxxx-yyyy-zzzz"},
+			Products:      []string{"pkg:npm/lodash@4.17.15"},
+			Status:        NotAffected,
+			Justification: "vulnerable_code_not_in_execute_path",
+		},
+	}, doc.Statements)
+}
@@ -0,0 +1,133 @@
+package vex
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/cpe"
+	"github.com/santoshdahal12/deplister/pkg/purl"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// OpenVEXContext is the OpenVEX version documents are written in
+const OpenVEXContext = "https://openvex.dev/ns/v0.2.0"
+
+// OpenVEX is an OpenVEX document
+type OpenVEX struct {
+	Context    string             `json:"@context"`
+	ID         string             `json:"@id"`
+	Author     string             `json:"author"`
+	Timestamp  time.Time          `json:"timestamp"`
+	Version    int                `json:"version"`
+	Tooling    string             `json:"tooling,omitempty"`
+	Statements []OpenVEXStatement `json:"statements"`
+}
+
+// OpenVEXStatement is a statement of an OpenVEX document
+type OpenVEXStatement struct {
+	Vulnerability   OpenVEXVulnerability `json:"vulnerability"`
+	Products        []OpenVEXProduct     `json:"products"`
+	Status          string               `json:"status"`
+	Justification   string               `json:"justification,omitempty"`
+	ImpactStatement string               `json:"impact_statement,omitempty"`
+	ActionStatement string               `json:"action_statement,omitempty"`
+}
+
+// OpenVEXVulnerability identifies a vulnerability. Documents before v0.2.0
+// give it as a plain string.
+type OpenVEXVulnerability struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+func (v *OpenVEXVulnerability) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		v.Name = name
+		return nil
+	}
+	type plain OpenVEXVulnerability
+	return json.Unmarshal(data, (*plain)(v))
+}
+
+// OpenVEXProduct identifies a product by package URL, optionally narrowed to
+// some of its components. Documents before v0.2.0 give it as a plain string.
+// Identifiers holds other names of it, such as its purl and cpe23.
+type OpenVEXProduct struct {
+	ID            string            `json:"@id"`
+	Identifiers   map[string]string `json:"identifiers,omitempty"`
+	Subcomponents []OpenVEXProduct  `json:"subcomponents,omitempty"`
+}
+
+func (p *OpenVEXProduct) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		p.ID = id
+		return nil
+	}
+	type plain OpenVEXProduct
+	return json.Unmarshal(data, (*plain)(p))
+}
+
+func parseOpenVEX(data []byte) (*Document, error) {
+	var doc OpenVEX
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+
+	result := &Document{}
+	for _, s := range doc.Statements {
+		statement := Statement{
+			Vulnerability: s.Vulnerability.Name,
+			Aliases:       s.Vulnerability.Aliases,
+			Status:        s.Status,
+			Justification: s.Justification,
+		}
+		// A statement about a product's components applies to them only
+		for _, product := range s.Products {
+			if len(product.Subcomponents) == 0 {
+				statement.Products = append(statement.Products, product.ID)
+			}
+			for _, component := range product.Subcomponents {
+				statement.Products = append(statement.Products, component.ID)
+			}
+		}
+		result.Statements = append(result.Statements, statement)
+	}
+	return result, nil
+}
+
+// Skeleton returns an OpenVEX document with an under_investigation
+// statement for every vulnerability found in the scan, ready to be triaged
+func Skeleton(result *scanners.ScanResult, author string, now time.Time) *OpenVEX {
+	doc := &OpenVEX{
+		Context:    OpenVEXContext,
+		ID:         "urn:uuid:" + uuid(),
+		Author:     author,
+		Timestamp:  now.UTC(),
+		Version:    1,
+		Tooling:    "deplister",
+		Statements: []OpenVEXStatement{},
+	}
+	for _, dep := range result.Dependencies {
+		for _, vuln := range dep.Vulnerabilities {
+			doc.Statements = append(doc.Statements, OpenVEXStatement{
+				Vulnerability: OpenVEXVulnerability{Name: vuln.ID, Aliases: vuln.Aliases},
+				Products:      []OpenVEXProduct{{ID: purl.For(dep), Identifiers: map[string]string{"purl": purl.For(dep), "cpe23": cpe.For(dep)}}},
+				Status:        UnderInvestigation,
+			})
+		}
+	}
+	return doc
+}
+
+// uuid returns a random version 4 UUID
+func uuid() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,161 @@
+package vex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/purl"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Common errors
+var (
+	ErrInvalidDocument = errors.New("invalid VEX document")
+)
+
+// Statuses of a vulnerability in a product, as named by OpenVEX
+const (
+	NotAffected        = "not_affected"
+	Affected           = "affected"
+	Fixed              = "fixed"
+	UnderInvestigation = "under_investigation"
+)
+
+// Statement declares the status of a vulnerability in a set of products
+type Statement struct {
+	Vulnerability string   // Vulnerability ID, such as a CVE or GHSA ID
+	Aliases       []string // Other IDs of the vulnerability
+	Products      []string // Package URLs of the products the statement covers
+	Status        string   // NotAffected, Affected, Fixed or UnderInvestigation
+	Justification string   // Why the products are not affected, when given
+}
+
+// Document is a VEX document normalized from OpenVEX or CSAF
+type Document struct {
+	Statements []Statement
+}
+
+// Load reads an OpenVEX or CSAF VEX document
+func Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse reads an OpenVEX or CSAF VEX document, telling them apart by their
+// top-level fields
+func Parse(data []byte) (*Document, error) {
+	var probe struct {
+		Context  string          `json:"@context"`
+		Document json.RawMessage `json:"document"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+
+	switch {
+	case strings.Contains(probe.Context, "openvex"):
+		return parseOpenVEX(data)
+	case probe.Document != nil:
+		return parseCSAF(data)
+	}
+	return nil, fmt.Errorf("%w: neither OpenVEX nor CSAF", ErrInvalidDocument)
+}
+
+// Status returns the status the document gives a vulnerability in a
+// dependency. When several statements apply, the last one wins.
+func (d *Document) Status(dep scanners.Dependency, vuln scanners.Vulnerability) (Statement, bool) {
+	ids := append([]string{vuln.ID}, vuln.Aliases...)
+	id := purl.For(dep)
+
+	var match Statement
+	var found bool
+	for _, statement := range d.Statements {
+		if !statement.covers(ids) {
+			continue
+		}
+		for _, product := range statement.Products {
+			if matchesPURL(product, id) {
+				match, found = statement, true
+				break
+			}
+		}
+	}
+	return match, found
+}
+
+// covers reports whether the statement is about any of the IDs
+func (s Statement) covers(ids []string) bool {
+	for _, id := range append([]string{s.Vulnerability}, s.Aliases...) {
+		if id != "" && slices.ContainsFunc(ids, func(other string) bool { return strings.EqualFold(id, other) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPURL reports whether a product package URL identifies the
+// dependency's package URL. Products without a version match every version,
+// and qualifiers and subpaths are ignored.
+func matchesPURL(product, dependency string) bool {
+	product, dependency = normalizePURL(product), normalizePURL(dependency)
+	return product == dependency || product == withoutVersion(dependency)
+}
+
+func withoutVersion(p string) string {
+	if at := strings.LastIndex(p, "@"); at > strings.LastIndex(p, "/") {
+		return p[:at]
+	}
+	return p
+}
+
+// normalizePURL drops qualifiers and subpaths and decodes percent-encoding,
+// which VEX producers apply inconsistently
+func normalizePURL(p string) string {
+	p, _, _ = strings.Cut(p, "#")
+	p, _, _ = strings.Cut(p, "?")
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		p = unescaped
+	}
+	// The type and, for npm and Go, the name are case insensitive
+	return strings.ToLower(p)
+}
+
+// Apply removes the vulnerabilities the document declares not_affected or
+// fixed from the dependencies, listing their IDs in the "vex.suppressed"
+// property, and returns the number suppressed
+func Apply(doc *Document, result *scanners.ScanResult) int {
+	suppressed := 0
+	for i := range result.Dependencies {
+		dep := &result.Dependencies[i]
+		var kept []scanners.Vulnerability
+		var ids []string
+		for _, vuln := range dep.Vulnerabilities {
+			statement, ok := doc.Status(*dep, vuln)
+			if ok && (statement.Status == NotAffected || statement.Status == Fixed) {
+				ids = append(ids, vuln.ID)
+				continue
+			}
+			kept = append(kept, vuln)
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		suppressed += len(ids)
+		dep.Vulnerabilities = kept
+		if dep.Properties == nil {
+			dep.Properties = make(map[string]string)
+		}
+		dep.Properties["vex.suppressed"] = strings.Join(ids, ",")
+	}
+	result.SyncGraph()
+	return suppressed
+}
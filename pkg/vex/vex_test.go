@@ -0,0 +1,56 @@
+package vex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	for _, data := range []string{
+		`not json`,
+		`{"bomFormat": "CycloneDX"}`,
+		`{"document": {"category": "csaf_security_advisory"}}`,
+	} {
+		_, err := Parse([]byte(data))
+		assert.ErrorIs(t, err, ErrInvalidDocument, data)
+	}
+}
+
+func TestMatchesPURL(t *testing.T) {
+	assert.True(t, matchesPURL("pkg:npm/%40babel/core@7.24.0", "pkg:npm/%40babel/core@7.24.0"))
+	assert.True(t, matchesPURL("pkg:npm/@babel/core@7.24.0", "pkg:npm/%40babel/core@7.24.0"))
+	assert.True(t, matchesPURL("pkg:npm/@babel/core", "pkg:npm/%40babel/core@7.24.0"))
+	assert.True(t, matchesPURL("pkg:golang/github.com/gin-gonic/gin@v1.9.0?type=module", "pkg:golang/github.com/gin-gonic/gin@v1.9.0"))
+	assert.False(t, matchesPURL("pkg:npm/@babel/core@7.23.0", "pkg:npm/%40babel/core@7.24.0"))
+	assert.False(t, matchesPURL("pkg:npm/@babel/cor", "pkg:npm/%40babel/core@7.24.0"))
+}
+
+func TestApply(t *testing.T) {
+	doc := &Document{Statements: []Statement{
+		{Vulnerability: "CVE-2024-0001", Products: []string{"pkg:npm/lodash@4.17.15"}, Status: NotAffected, Justification: "vulnerable_code_not_in_execute_path"},
+		{Vulnerability: "GHSA-fixed", Products: []string{"pkg:npm/lodash"}, Status: Fixed},
+		{Vulnerability: "GHSA-later", Products: []string{"pkg:npm/lodash"}, Status: NotAffected},
+		{Vulnerability: "GHSA-later", Products: []string{"pkg:npm/lodash@4.17.15"}, Status: Affected},
+		{Vulnerability: "GHSA-other", Products: []string{"pkg:npm/express"}, Status: NotAffected},
+	}}
+
+	lodash := scanners.Dependency{Name: "lodash", Version: "4.17.15", Type: "npm", Vulnerabilities: []scanners.Vulnerability{
+		{ID: "GHSA-alias", Aliases: []string{"CVE-2024-0001"}},
+		{ID: "GHSA-fixed"},
+		{ID: "GHSA-later"},
+		{ID: "GHSA-other"},
+	}}
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{lodash, {Name: "react", Version: "18.2.0", Type: "npm"}},
+		Graph:        &scanners.DependencyGraph{Nodes: map[string]*scanners.Dependency{"lodash@4.17.15": &lodash}},
+	}
+
+	assert.Equal(t, 2, Apply(doc, result))
+	assert.Equal(t, []scanners.Vulnerability{{ID: "GHSA-later"}, {ID: "GHSA-other"}}, result.Dependencies[0].Vulnerabilities)
+	assert.Equal(t, "GHSA-alias,GHSA-fixed", result.Dependencies[0].Properties["vex.suppressed"])
+	assert.Len(t, result.Graph.Nodes["lodash@4.17.15"].Vulnerabilities, 2)
+	assert.Nil(t, result.Dependencies[1].Properties)
+}
@@ -0,0 +1,161 @@
+package vulndb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	// Registers the pure Go "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+
+	"github.com/santoshdahal12/deplister/pkg/vulns"
+)
+
+// Common errors
+var (
+	ErrNoDatabase = errors.New("vulnerability database not found, run deplister db update")
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS advisories (
+	id   TEXT PRIMARY KEY,
+	data TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS affected (
+	ecosystem TEXT NOT NULL,
+	name      TEXT NOT NULL,
+	id        TEXT NOT NULL,
+	PRIMARY KEY (ecosystem, name, id)
+);
+CREATE TABLE IF NOT EXISTS sources (
+	ecosystem  TEXT PRIMARY KEY,
+	url        TEXT    NOT NULL,
+	updated_at INTEGER NOT NULL,
+	advisories INTEGER NOT NULL
+);
+`
+
+// DefaultPath returns the database location in the user's cache directory
+func DefaultPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "deplister", "vulns.db")
+}
+
+// DB is a local snapshot of OSV advisories. It implements vulns.Source.
+type DB struct {
+	db *sql.DB
+}
+
+// Open opens an existing database created by Update
+func Open(path string) (*DB, error) {
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrNoDatabase, path)
+		}
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	return &DB{db: db}, nil
+}
+
+// Close closes the database
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// Query returns the advisories affecting each package version
+func (d *DB) Query(ctx context.Context, pkgs []vulns.Package) ([][]vulns.OSV, error) {
+	type key struct{ ecosystem, name string }
+	cache := make(map[key][]vulns.OSV)
+
+	results := make([][]vulns.OSV, len(pkgs))
+	for i, pkg := range pkgs {
+		k := key{pkg.Ecosystem, pkg.Name}
+		advisories, ok := cache[k]
+		if !ok {
+			var err error
+			if advisories, err = d.advisories(ctx, pkg.Ecosystem, pkg.Name); err != nil {
+				return nil, err
+			}
+			cache[k] = advisories
+		}
+
+		for _, osv := range advisories {
+			if vulns.Affects(osv, pkg) {
+				results[i] = append(results[i], osv)
+			}
+		}
+	}
+	return results, nil
+}
+
+// advisories returns every advisory mentioning the package
+func (d *DB) advisories(ctx context.Context, ecosystem, name string) ([]vulns.OSV, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT a.data FROM affected f JOIN advisories a ON a.id = f.id
+		WHERE f.ecosystem = ? AND f.name = ?
+		ORDER BY a.id`, ecosystem, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var advisories []vulns.OSV
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var osv vulns.OSV
+		if err := json.Unmarshal(data, &osv); err != nil {
+			return nil, err
+		}
+		advisories = append(advisories, osv)
+	}
+	return advisories, rows.Err()
+}
+
+// Source describes an ecosystem snapshot loaded into the database
+type Source struct {
+	Ecosystem  string    `json:"ecosystem"`
+	URL        string    `json:"url"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+	Advisories int       `json:"advisories"`
+}
+
+// Sources returns the ecosystems in the database and when they were updated
+func (d *DB) Sources(ctx context.Context) ([]Source, error) {
+	rows, err := d.db.QueryContext(ctx, "SELECT ecosystem, url, updated_at, advisories FROM sources ORDER BY ecosystem")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sources []Source
+	for rows.Next() {
+		var source Source
+		var updatedAt int64
+		if err := rows.Scan(&source.Ecosystem, &source.URL, &updatedAt, &source.Advisories); err != nil {
+			return nil, err
+		}
+		source.UpdatedAt = time.Unix(updatedAt, 0).UTC()
+		sources = append(sources, source)
+	}
+	return sources, rows.Err()
+}
@@ -0,0 +1,213 @@
+package vulndb
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/vulns"
+)
+
+// DefaultSourceURL is the bucket publishing OSV snapshots per ecosystem,
+// including the GitHub Security Advisories of each ecosystem
+const DefaultSourceURL = "https://osv-vulnerabilities.storage.googleapis.com"
+
+// UpdateOptions controls where Update reads snapshots from
+type UpdateOptions struct {
+	// Source is a base URL or a local directory laid out like the OSV bucket,
+	// i.e. containing <ecosystem>/all.zip. Defaults to DefaultSourceURL.
+	Source string
+	// Ecosystems to download. Defaults to every supported ecosystem.
+	Ecosystems []string
+	HTTPClient *http.Client
+}
+
+// Update downloads the ecosystem snapshots and replaces the database at
+// path. The database is built next to path and renamed into place, so
+// scans never see a partially written database.
+func Update(ctx context.Context, path string, opts UpdateOptions) ([]Source, error) {
+	if opts.Source == "" {
+		opts.Source = DefaultSourceURL
+	}
+	if len(opts.Ecosystems) == 0 {
+		opts.Ecosystems = vulns.Ecosystems()
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	defer os.Remove(tmp)
+
+	db, err := sql.Open("sqlite", tmp)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	if _, err := db.Exec(schema); err != nil {
+		return nil, err
+	}
+
+	var sources []Source
+	for _, ecosystem := range opts.Ecosystems {
+		source, err := loadEcosystem(ctx, db, ecosystem, opts)
+		if err != nil {
+			return nil, fmt.Errorf("updating %s: %w", ecosystem, err)
+		}
+		sources = append(sources, source)
+	}
+
+	if err := db.Close(); err != nil {
+		return nil, err
+	}
+	return sources, os.Rename(tmp, path)
+}
+
+// loadEcosystem imports the advisories of one ecosystem snapshot
+func loadEcosystem(ctx context.Context, db *sql.DB, ecosystem string, opts UpdateOptions) (Source, error) {
+	location := strings.TrimSuffix(opts.Source, "/") + "/" + ecosystem + "/all.zip"
+	archive, err := openSnapshot(ctx, location, opts.HTTPClient)
+	if err != nil {
+		return Source{}, err
+	}
+	defer archive.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return Source{}, err
+	}
+	defer tx.Rollback()
+
+	insertAdvisory, err := tx.PrepareContext(ctx, "INSERT OR REPLACE INTO advisories (id, data) VALUES (?, ?)")
+	if err != nil {
+		return Source{}, err
+	}
+	defer insertAdvisory.Close()
+	insertAffected, err := tx.PrepareContext(ctx, "INSERT OR IGNORE INTO affected (ecosystem, name, id) VALUES (?, ?, ?)")
+	if err != nil {
+		return Source{}, err
+	}
+	defer insertAffected.Close()
+
+	source := Source{Ecosystem: ecosystem, URL: location, UpdatedAt: time.Now().UTC()}
+	for _, file := range archive.File {
+		if !strings.HasSuffix(file.Name, ".json") {
+			continue
+		}
+
+		data, err := readZipFile(file)
+		if err != nil {
+			return Source{}, err
+		}
+		var osv vulns.OSV
+		if err := json.Unmarshal(data, &osv); err != nil {
+			return Source{}, fmt.Errorf("%s: %w", file.Name, err)
+		}
+		if osv.ID == "" {
+			continue
+		}
+
+		if _, err := insertAdvisory.ExecContext(ctx, osv.ID, data); err != nil {
+			return Source{}, err
+		}
+		for _, affected := range osv.Affected {
+			if _, err := insertAffected.ExecContext(ctx, affected.Package.Ecosystem, affected.Package.Name, osv.ID); err != nil {
+				return Source{}, err
+			}
+		}
+		source.Advisories++
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT OR REPLACE INTO sources (ecosystem, url, updated_at, advisories) VALUES (?, ?, ?, ?)",
+		source.Ecosystem, source.URL, source.UpdatedAt.Unix(), source.Advisories)
+	if err != nil {
+		return Source{}, err
+	}
+	return source, tx.Commit()
+}
+
+// snapshot is an opened ecosystem archive
+type snapshot struct {
+	*zip.Reader
+	file *os.File
+	temp bool
+}
+
+func (s *snapshot) Close() error {
+	err := s.file.Close()
+	if s.temp {
+		os.Remove(s.file.Name())
+	}
+	return err
+}
+
+// openSnapshot opens a local archive or downloads it to a temporary file,
+// as zip archives need random access
+func openSnapshot(ctx context.Context, location string, client *http.Client) (*snapshot, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		file, err := os.Open(filepath.FromSlash(location))
+		if err != nil {
+			return nil, err
+		}
+		return newSnapshot(file, false)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading %s: %s", location, resp.Status)
+	}
+
+	file, err := os.CreateTemp("", "deplister-osv-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	return newSnapshot(file, true)
+}
+
+func newSnapshot(file *os.File, temp bool) (*snapshot, error) {
+	s := &snapshot{file: file, temp: temp}
+	info, err := file.Stat()
+	if err == nil {
+		s.Reader, err = zip.NewReader(file, info.Size())
+	}
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
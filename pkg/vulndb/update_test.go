@@ -0,0 +1,143 @@
+package vulndb
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santoshdahal12/deplister/pkg/vulns"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testAdvisories = map[string][]vulns.OSV{
+	"npm": {
+		{
+			ID:      "GHSA-p6mc-m468-83gw",
+			Summary: "Prototype Pollution in lodash",
+			Affected: []vulns.OSVAffected{{
+				Package: vulns.OSVPackage{Ecosystem: "npm", Name: "lodash"},
+				Ranges:  []vulns.OSVRange{{Type: "SEMVER", Events: []vulns.OSVEvent{{Introduced: "0"}, {Fixed: "4.17.19"}}}},
+			}},
+		},
+		{
+			ID: "GHSA-35jh-r3h4-6jhm",
+			Affected: []vulns.OSVAffected{{
+				Package: vulns.OSVPackage{Ecosystem: "npm", Name: "lodash"},
+				Ranges:  []vulns.OSVRange{{Type: "SEMVER", Events: []vulns.OSVEvent{{Introduced: "0"}, {Fixed: "4.17.21"}}}},
+			}},
+		},
+	},
+	"PyPI": {
+		{
+			ID: "GHSA-j8r2-6x86-q33q",
+			Affected: []vulns.OSVAffected{{
+				Package: vulns.OSVPackage{Ecosystem: "PyPI", Name: "requests"},
+				Ranges:  []vulns.OSVRange{{Type: "ECOSYSTEM", Events: []vulns.OSVEvent{{Introduced: "2.3.0"}, {Fixed: "2.31.0"}}}},
+			}},
+		},
+	},
+	"Go": {
+		{
+			ID: "GO-2023-2102",
+			Affected: []vulns.OSVAffected{{
+				Package: vulns.OSVPackage{Ecosystem: "Go", Name: "golang.org/x/net"},
+				Ranges:  []vulns.OSVRange{{Type: "SEMVER", Events: []vulns.OSVEvent{{Introduced: "0"}, {Fixed: "0.17.0"}}}},
+			}},
+		},
+	},
+}
+
+// writeSnapshots lays out the advisories like the OSV bucket
+func writeSnapshots(t *testing.T, dir string) {
+	for ecosystem, advisories := range testAdvisories {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, ecosystem), 0o755))
+		file, err := os.Create(filepath.Join(dir, ecosystem, "all.zip"))
+		assert.NoError(t, err)
+
+		w := zip.NewWriter(file)
+		for _, osv := range advisories {
+			f, err := w.Create(osv.ID + ".json")
+			assert.NoError(t, err)
+			assert.NoError(t, json.NewEncoder(f).Encode(osv))
+		}
+		assert.NoError(t, w.Close())
+		assert.NoError(t, file.Close())
+	}
+}
+
+func TestUpdate_Directory(t *testing.T) {
+	source := t.TempDir()
+	writeSnapshots(t, source)
+	path := filepath.Join(t.TempDir(), "cache", "vulns.db")
+
+	sources, err := Update(context.Background(), path, UpdateOptions{Source: source})
+	assert.NoError(t, err)
+	if assert.Len(t, sources, 3) {
+		assert.Equal(t, "Go", sources[0].Ecosystem)
+		assert.Equal(t, 1, sources[0].Advisories)
+		assert.Equal(t, "PyPI", sources[1].Ecosystem)
+		assert.Equal(t, 1, sources[1].Advisories)
+		assert.Equal(t, "npm", sources[2].Ecosystem)
+		assert.Equal(t, 2, sources[2].Advisories)
+	}
+
+	db, err := Open(path)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	results, err := db.Query(context.Background(), []vulns.Package{
+		{Ecosystem: "npm", Name: "lodash", Version: "4.17.15"},
+		{Ecosystem: "npm", Name: "lodash", Version: "4.17.20"},
+		{Ecosystem: "npm", Name: "lodash", Version: "4.17.21"},
+		{Ecosystem: "Go", Name: "golang.org/x/net", Version: "0.16.0"},
+		{Ecosystem: "npm", Name: "express", Version: "4.18.2"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results[0], 2)
+	if assert.Len(t, results[1], 1) {
+		assert.Equal(t, "GHSA-35jh-r3h4-6jhm", results[1][0].ID)
+	}
+	assert.Empty(t, results[2])
+	assert.Len(t, results[3], 1)
+	assert.Empty(t, results[4])
+
+	stored, err := db.Sources(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, stored, 3)
+}
+
+func TestUpdate_HTTP(t *testing.T) {
+	source := t.TempDir()
+	writeSnapshots(t, source)
+	server := httptest.NewServer(http.FileServer(http.Dir(source)))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "vulns.db")
+	sources, err := Update(context.Background(), path, UpdateOptions{Source: server.URL, Ecosystems: []string{"npm"}})
+	assert.NoError(t, err)
+	if assert.Len(t, sources, 1) {
+		assert.Equal(t, server.URL+"/npm/all.zip", sources[0].URL)
+	}
+
+	_, err = Update(context.Background(), path, UpdateOptions{Source: server.URL, Ecosystems: []string{"crates.io"}})
+	assert.Error(t, err)
+
+	// A failed update keeps the previous database
+	db, err := Open(path)
+	assert.NoError(t, err)
+	defer db.Close()
+	stored, err := db.Sources(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, stored, 1)
+}
+
+func TestOpen_Missing(t *testing.T) {
+	_, err := Open(filepath.Join(t.TempDir(), "missing.db"))
+	assert.ErrorIs(t, err, ErrNoDatabase)
+}
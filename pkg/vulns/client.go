@@ -0,0 +1,215 @@
+package vulns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/santoshdahal12/deplister/pkg/httpclient"
+)
+
+// DefaultAPIURL is the OSV.dev API
+const DefaultAPIURL = "https://api.osv.dev"
+
+// maxBatchSize is the number of queries OSV.dev accepts per batch request
+const maxBatchSize = 1000
+
+// Common errors
+var (
+	ErrRequestFailed = errors.New("vulnerability lookup failed")
+)
+
+// Source looks up the advisories affecting packages
+type Source interface {
+	// Query returns the advisories affecting each package, in order
+	Query(ctx context.Context, pkgs []Package) ([][]OSV, error)
+}
+
+// Client queries the OSV.dev API
+type Client struct {
+	APIURL      string
+	HTTPClient  *http.Client
+	Concurrency int // Parallel advisory downloads
+}
+
+// NewClient creates a client for OSV.dev
+func NewClient() *Client {
+	return &Client{
+		APIURL:      DefaultAPIURL,
+		HTTPClient:  httpclient.Default,
+		Concurrency: 8,
+	}
+}
+
+type batchQuery struct {
+	Package   osvQueryPackage `json:"package"`
+	Version   string          `json:"version"`
+	PageToken string          `json:"page_token,omitempty"`
+}
+
+type osvQueryPackage struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+}
+
+type batchResponse struct {
+	Results []struct {
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+		NextPageToken string `json:"next_page_token"`
+	} `json:"results"`
+}
+
+// Query looks up the packages in batches and downloads every matching
+// advisory once, as the batch API only returns advisory IDs
+func (c *Client) Query(ctx context.Context, pkgs []Package) ([][]OSV, error) {
+	ids := make([][]string, len(pkgs))
+	for start := 0; start < len(pkgs); start += maxBatchSize {
+		end := min(start+maxBatchSize, len(pkgs))
+		if err := c.queryBatch(ctx, pkgs[start:end], ids[start:end]); err != nil {
+			return nil, err
+		}
+	}
+
+	advisories, err := c.fetchAll(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([][]OSV, len(pkgs))
+	for i, pkgIDs := range ids {
+		for _, id := range pkgIDs {
+			results[i] = append(results[i], advisories[id])
+		}
+	}
+	return results, nil
+}
+
+// queryBatch fills ids with the advisory IDs of each package, following
+// pagination until every package is complete
+func (c *Client) queryBatch(ctx context.Context, pkgs []Package, ids [][]string) error {
+	pending := make([]int, len(pkgs))
+	tokens := make([]string, len(pkgs))
+	for i := range pkgs {
+		pending[i] = i
+	}
+
+	for len(pending) > 0 {
+		queries := make([]batchQuery, len(pending))
+		for i, idx := range pending {
+			queries[i] = batchQuery{
+				Package:   osvQueryPackage{Ecosystem: pkgs[idx].Ecosystem, Name: pkgs[idx].Name},
+				Version:   pkgs[idx].Version,
+				PageToken: tokens[idx],
+			}
+		}
+
+		body, err := json.Marshal(map[string]any{"queries": queries})
+		if err != nil {
+			return err
+		}
+
+		var resp batchResponse
+		if err := c.do(ctx, http.MethodPost, "/v1/querybatch", body, &resp); err != nil {
+			return err
+		}
+		if len(resp.Results) != len(pending) {
+			return fmt.Errorf("%w: expected %d results, got %d", ErrRequestFailed, len(pending), len(resp.Results))
+		}
+
+		var next []int
+		for i, result := range resp.Results {
+			idx := pending[i]
+			for _, vuln := range result.Vulns {
+				ids[idx] = append(ids[idx], vuln.ID)
+			}
+			if result.NextPageToken != "" {
+				tokens[idx] = result.NextPageToken
+				next = append(next, idx)
+			}
+		}
+		pending = next
+	}
+
+	return nil
+}
+
+// fetchAll downloads the referenced advisories with limited concurrency
+func (c *Client) fetchAll(ctx context.Context, ids [][]string) (map[string]OSV, error) {
+	unique := make(map[string]bool)
+	for _, pkgIDs := range ids {
+		for _, id := range pkgIDs {
+			unique[id] = true
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu         sync.Mutex
+		wg         sync.WaitGroup
+		firstErr   error
+		advisories = make(map[string]OSV, len(unique))
+		queue      = make(chan string)
+	)
+
+	for range max(c.Concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range queue {
+				var osv OSV
+				err := c.do(ctx, http.MethodGet, "/v1/vulns/"+url.PathEscape(id), nil, &osv)
+
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				advisories[id] = osv
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for id := range unique {
+		select {
+		case queue <- id:
+		case <-ctx.Done():
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	return advisories, firstErr
+}
+
+// do sends a request and decodes the JSON response into out. Rate limited
+// and failed requests are retried by the HTTP client.
+func (c *Client) do(ctx context.Context, method, path string, body []byte, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.APIURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrRequestFailed, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s %s: %s", ErrRequestFailed, method, path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
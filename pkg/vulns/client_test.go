@@ -0,0 +1,99 @@
+package vulns
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/httpclient"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(url string) *Client {
+	client := NewClient()
+	client.APIURL = url
+	return client
+}
+
+func TestClient_Query(t *testing.T) {
+	var batches, rateLimited atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/querybatch":
+			batches.Add(1)
+			var req struct {
+				Queries []batchQuery `json:"queries"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+			results := make([]map[string]any, len(req.Queries))
+			for i, q := range req.Queries {
+				switch {
+				case q.Package.Name == "lodash" && q.PageToken == "":
+					results[i] = map[string]any{"vulns": []map[string]string{{"id": "GHSA-1"}}, "next_page_token": "page2"}
+				case q.Package.Name == "lodash":
+					results[i] = map[string]any{"vulns": []map[string]string{{"id": "GHSA-2"}}}
+				case q.Package.Name == "minimist":
+					results[i] = map[string]any{"vulns": []map[string]string{{"id": "GHSA-1"}}}
+				default:
+					results[i] = map[string]any{}
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]any{"results": results})
+
+		case strings.HasPrefix(r.URL.Path, "/v1/vulns/"):
+			if rateLimited.Add(1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			json.NewEncoder(w).Encode(OSV{ID: strings.TrimPrefix(r.URL.Path, "/v1/vulns/")})
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	results, err := newTestClient(server.URL).Query(context.Background(), []Package{
+		{Ecosystem: "npm", Name: "lodash", Version: "4.17.20"},
+		{Ecosystem: "npm", Name: "express", Version: "4.18.2"},
+		{Ecosystem: "npm", Name: "minimist", Version: "1.2.5"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]OSV{
+		{{ID: "GHSA-1"}, {ID: "GHSA-2"}},
+		nil,
+		{{ID: "GHSA-1"}},
+	}, results)
+	assert.Equal(t, int32(2), batches.Load())
+}
+
+func TestClient_QueryErrors(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	defer func(delay time.Duration) { httpclient.RetryDelay = delay }(httpclient.RetryDelay)
+	httpclient.RetryDelay = time.Millisecond
+
+	// The HTTP client is the only one retrying
+	for retries, expected := range map[int]int32{0: 1, 2: 3} {
+		requests.Store(0)
+		client := newTestClient(server.URL)
+		client.HTTPClient, _ = httpclient.New(httpclient.Config{Retries: retries})
+
+		_, err := client.Query(context.Background(), []Package{{Ecosystem: "npm", Name: "lodash", Version: "4.17.20"}})
+		assert.ErrorIs(t, err, ErrRequestFailed)
+		assert.Equal(t, expected, requests.Load(), "requests with %d retries", retries)
+	}
+}
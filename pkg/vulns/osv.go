@@ -0,0 +1,153 @@
+package vulns
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// OSV is the subset of the OSV schema (https://ossf.github.io/osv-schema/)
+// deplister uses
+type OSV struct {
+	ID               string         `json:"id"`
+	Summary          string         `json:"summary,omitempty"`
+	Aliases          []string       `json:"aliases,omitempty"`
+	Modified         string         `json:"modified,omitempty"`
+	Withdrawn        string         `json:"withdrawn,omitempty"`
+	Severity         []OSVSeverity  `json:"severity,omitempty"`
+	Affected         []OSVAffected  `json:"affected,omitempty"`
+	DatabaseSpecific map[string]any `json:"database_specific,omitempty"`
+}
+
+// OSVSeverity is a severity score such as a CVSS vector
+type OSVSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// OSVAffected lists the affected versions of a package
+type OSVAffected struct {
+	Package           OSVPackage     `json:"package"`
+	Ranges            []OSVRange     `json:"ranges,omitempty"`
+	Versions          []string       `json:"versions,omitempty"`
+	EcosystemSpecific map[string]any `json:"ecosystem_specific,omitempty"`
+	DatabaseSpecific  map[string]any `json:"database_specific,omitempty"`
+}
+
+// OSVPackage identifies a package within an ecosystem
+type OSVPackage struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	PURL      string `json:"purl,omitempty"`
+}
+
+// OSVRange is a range of affected versions described by events
+type OSVRange struct {
+	Type   string     `json:"type"`
+	Events []OSVEvent `json:"events"`
+}
+
+// OSVEvent introduces or fixes a vulnerability at a version
+type OSVEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// Package is a package version to look up vulnerabilities for
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+}
+
+// ecosystems maps dependency types to OSV ecosystems
+var ecosystems = map[string]string{
+	"go":   "Go",
+	"npm":  "npm",
+	"pypi": "PyPI",
+}
+
+// Ecosystems returns the OSV ecosystems of the supported dependency types
+func Ecosystems() []string {
+	result := make([]string, 0, len(ecosystems))
+	for _, ecosystem := range ecosystems {
+		result = append(result, ecosystem)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// PackageFor returns the OSV package of a dependency. It reports false for
+// dependency types without an OSV ecosystem.
+func PackageFor(dep scanners.Dependency) (Package, bool) {
+	ecosystem, ok := ecosystems[dep.Type]
+	if !ok || dep.Version == "" {
+		return Package{}, false
+	}
+
+	version := dep.Version
+	if ecosystem == "Go" {
+		// OSV records Go versions without the "v" prefix
+		version = strings.TrimPrefix(version, "v")
+	}
+	return Package{Ecosystem: ecosystem, Name: dep.Name, Version: version}, true
+}
+
+// toVulnerability converts an advisory into the vulnerability reported for
+// the package
+func toVulnerability(osv OSV, pkg Package) scanners.Vulnerability {
+	vuln := scanners.Vulnerability{
+		ID:       osv.ID,
+		Aliases:  osv.Aliases,
+		Summary:  osv.Summary,
+		Severity: severity(osv),
+	}
+
+	for _, s := range osv.Severity {
+		if strings.HasPrefix(s.Type, "CVSS") {
+			vuln.CVSS = s.Score
+			break
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, affected := range osv.Affected {
+		if affected.Package.Ecosystem != pkg.Ecosystem || affected.Package.Name != pkg.Name {
+			continue
+		}
+		for _, r := range affected.Ranges {
+			for _, event := range r.Events {
+				if event.Fixed != "" && !seen[event.Fixed] {
+					seen[event.Fixed] = true
+					vuln.FixedVersions = append(vuln.FixedVersions, event.Fixed)
+				}
+			}
+		}
+	}
+
+	return vuln
+}
+
+// severity returns the qualitative severity published by the advisory
+// database, normalized to LOW, MEDIUM, HIGH or CRITICAL
+func severity(osv OSV) string {
+	value, _ := osv.DatabaseSpecific["severity"].(string)
+	if value == "" {
+		for _, affected := range osv.Affected {
+			if value, _ = affected.EcosystemSpecific["severity"].(string); value != "" {
+				break
+			}
+		}
+	}
+
+	switch value = strings.ToUpper(value); value {
+	case "MODERATE":
+		return "MEDIUM"
+	case "LOW", "MEDIUM", "HIGH", "CRITICAL":
+		return value
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package vulns
+
+import (
+	"testing"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageFor(t *testing.T) {
+	pkg, ok := PackageFor(scanners.Dependency{Name: "golang.org/x/net", Version: "v0.17.0", Type: "go"})
+	assert.True(t, ok)
+	assert.Equal(t, Package{Ecosystem: "Go", Name: "golang.org/x/net", Version: "0.17.0"}, pkg)
+
+	pkg, ok = PackageFor(scanners.Dependency{Name: "@babel/core", Version: "7.24.0", Type: "npm"})
+	assert.True(t, ok)
+	assert.Equal(t, Package{Ecosystem: "npm", Name: "@babel/core", Version: "7.24.0"}, pkg)
+
+	_, ok = PackageFor(scanners.Dependency{Name: "serde", Version: "1.0.0", Type: "cargo-plugin"})
+	assert.False(t, ok)
+
+	_, ok = PackageFor(scanners.Dependency{Name: "lodash", Type: "npm"})
+	assert.False(t, ok)
+}
+
+func TestToVulnerability(t *testing.T) {
+	osv := OSV{
+		ID:       "GHSA-p6mc-m468-83gw",
+		Summary:  "Prototype Pollution in lodash",
+		Aliases:  []string{"CVE-2020-8203"},
+		Severity: []OSVSeverity{{Type: "CVSS_V3", Score: "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:H/A:H"}},
+		Affected: []OSVAffected{
+			{
+				Package: OSVPackage{Ecosystem: "npm", Name: "lodash"},
+				Ranges:  []OSVRange{{Type: "SEMVER", Events: []OSVEvent{{Introduced: "3.7.0"}, {Fixed: "4.17.19"}}}},
+			},
+			{
+				Package: OSVPackage{Ecosystem: "npm", Name: "lodash-es"},
+				Ranges:  []OSVRange{{Type: "SEMVER", Events: []OSVEvent{{Introduced: "0"}, {Fixed: "4.17.20"}}}},
+			},
+		},
+		DatabaseSpecific: map[string]any{"severity": "MODERATE"},
+	}
+
+	vuln := toVulnerability(osv, Package{Ecosystem: "npm", Name: "lodash", Version: "4.17.15"})
+	assert.Equal(t, scanners.Vulnerability{
+		ID:            "GHSA-p6mc-m468-83gw",
+		Aliases:       []string{"CVE-2020-8203"},
+		Summary:       "Prototype Pollution in lodash",
+		Severity:      "MEDIUM",
+		CVSS:          "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:H/A:H",
+		FixedVersions: []string{"4.17.19"},
+	}, vuln)
+}
+
+func TestSeverity(t *testing.T) {
+	assert.Equal(t, "HIGH", severity(OSV{DatabaseSpecific: map[string]any{"severity": "high"}}))
+	assert.Equal(t, "CRITICAL", severity(OSV{Affected: []OSVAffected{{EcosystemSpecific: map[string]any{"severity": "CRITICAL"}}}}))
+	assert.Equal(t, "", severity(OSV{DatabaseSpecific: map[string]any{"severity": "unknown"}}))
+	assert.Equal(t, "", severity(OSV{}))
+}
@@ -0,0 +1,73 @@
+package vulns
+
+import (
+	"sort"
+
+	"github.com/santoshdahal12/deplister/pkg/version"
+)
+
+// Affects reports whether the advisory affects the package version. Versions
+// are matched against the explicit version list and against SEMVER and
+// ECOSYSTEM ranges, which are semantic versions for all supported
+// ecosystems. GIT ranges cannot be evaluated without the repository and are
+// ignored.
+func Affects(osv OSV, pkg Package) bool {
+	for _, affected := range osv.Affected {
+		if affected.Package.Ecosystem != pkg.Ecosystem || affected.Package.Name != pkg.Name {
+			continue
+		}
+		for _, v := range affected.Versions {
+			if v == pkg.Version {
+				return true
+			}
+		}
+		for _, r := range affected.Ranges {
+			if r.Type != "GIT" && inRange(pkg.Version, r.Events) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inRange evaluates range events as described by the OSV schema: a version
+// is affected after an introduced event until a fixed or last_affected
+// event says otherwise
+func inRange(v string, events []OSVEvent) bool {
+	sorted := make([]OSVEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return version.Compare(eventVersion(sorted[i]), eventVersion(sorted[j])) < 0
+	})
+
+	affected := false
+	for _, event := range sorted {
+		switch {
+		case event.Introduced != "":
+			if event.Introduced == "0" || version.Compare(v, event.Introduced) >= 0 {
+				affected = true
+			}
+		case event.Fixed != "":
+			if version.Compare(v, event.Fixed) >= 0 {
+				affected = false
+			}
+		case event.LastAffected != "":
+			if version.Compare(v, event.LastAffected) > 0 {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
+
+func eventVersion(event OSVEvent) string {
+	switch {
+	case event.Introduced != "":
+		return event.Introduced
+	case event.Fixed != "":
+		return event.Fixed
+	case event.LastAffected != "":
+		return event.LastAffected
+	}
+	return event.Limit
+}
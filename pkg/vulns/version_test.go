@@ -0,0 +1,49 @@
+package vulns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAffects(t *testing.T) {
+	osv := OSV{
+		ID: "GHSA-test",
+		Affected: []OSVAffected{
+			{
+				Package: OSVPackage{Ecosystem: "npm", Name: "lodash"},
+				Ranges: []OSVRange{
+					{Type: "SEMVER", Events: []OSVEvent{{Fixed: "4.17.19"}, {Introduced: "0"}}},
+					{Type: "SEMVER", Events: []OSVEvent{{Introduced: "5.0.0"}, {LastAffected: "5.0.2"}}},
+				},
+			},
+			{
+				Package:  OSVPackage{Ecosystem: "npm", Name: "lodash-es"},
+				Versions: []string{"4.17.10"},
+			},
+			{
+				Package: OSVPackage{Ecosystem: "npm", Name: "gitonly"},
+				Ranges:  []OSVRange{{Type: "GIT", Events: []OSVEvent{{Introduced: "0"}}}},
+			},
+		},
+	}
+
+	tests := []struct {
+		pkg  Package
+		want bool
+	}{
+		{Package{Ecosystem: "npm", Name: "lodash", Version: "4.17.15"}, true},
+		{Package{Ecosystem: "npm", Name: "lodash", Version: "4.17.19"}, false},
+		{Package{Ecosystem: "npm", Name: "lodash", Version: "4.17.21"}, false},
+		{Package{Ecosystem: "npm", Name: "lodash", Version: "5.0.2"}, true},
+		{Package{Ecosystem: "npm", Name: "lodash", Version: "5.0.3"}, false},
+		{Package{Ecosystem: "npm", Name: "lodash-es", Version: "4.17.10"}, true},
+		{Package{Ecosystem: "npm", Name: "lodash-es", Version: "4.17.11"}, false},
+		{Package{Ecosystem: "npm", Name: "gitonly", Version: "1.0.0"}, false},
+		{Package{Ecosystem: "Go", Name: "lodash", Version: "4.17.15"}, false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Affects(osv, tt.pkg), "%s@%s", tt.pkg.Name, tt.pkg.Version)
+	}
+}
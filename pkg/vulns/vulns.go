@@ -0,0 +1,53 @@
+package vulns
+
+import (
+	"context"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Enrichment is the name of the vulnerability enrichment in ScanOptions.Enrich
+const Enrichment = "vulns"
+
+// Enrich looks up every dependency in the source and attaches the advisories
+// affecting it. Dependencies without an OSV ecosystem are left untouched.
+func Enrich(ctx context.Context, source Source, result *scanners.ScanResult) error {
+	var (
+		pkgs    []Package
+		indexes []int
+	)
+	for i, dep := range result.Dependencies {
+		if pkg, ok := PackageFor(dep); ok {
+			pkgs = append(pkgs, pkg)
+			indexes = append(indexes, i)
+		}
+	}
+	if len(pkgs) == 0 {
+		return nil
+	}
+
+	advisories, err := source.Query(ctx, pkgs)
+	if err != nil {
+		return err
+	}
+
+	for i, osvs := range advisories {
+		dep := &result.Dependencies[indexes[i]]
+		dep.Vulnerabilities = Vulnerabilities(osvs, pkgs[i])
+	}
+
+	return nil
+}
+
+// Vulnerabilities converts the advisories returned for a package into the
+// vulnerabilities reported for it, leaving out withdrawn advisories
+func Vulnerabilities(osvs []OSV, pkg Package) []scanners.Vulnerability {
+	var vulnerabilities []scanners.Vulnerability
+	for _, osv := range osvs {
+		if osv.Withdrawn != "" {
+			continue
+		}
+		vulnerabilities = append(vulnerabilities, toVulnerability(osv, pkg))
+	}
+	return vulnerabilities
+}
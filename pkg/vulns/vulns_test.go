@@ -0,0 +1,69 @@
+package vulns
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSource struct {
+	advisories map[Package][]OSV
+	queried    []Package
+	err        error
+}
+
+func (s *fakeSource) Query(ctx context.Context, pkgs []Package) ([][]OSV, error) {
+	s.queried = pkgs
+	if s.err != nil {
+		return nil, s.err
+	}
+	results := make([][]OSV, len(pkgs))
+	for i, pkg := range pkgs {
+		results[i] = s.advisories[pkg]
+	}
+	return results, nil
+}
+
+func TestEnrich(t *testing.T) {
+	result := &scanners.ScanResult{
+		Dependencies: []scanners.Dependency{
+			{Name: "lodash", Version: "4.17.15", Type: "npm"},
+			{Name: "express", Version: "4.18.2", Type: "npm"},
+			{Name: "internal", Version: "1.0.0", Type: "custom"},
+		},
+	}
+
+	source := &fakeSource{advisories: map[Package][]OSV{
+		{Ecosystem: "npm", Name: "lodash", Version: "4.17.15"}: {
+			{ID: "GHSA-1", Affected: []OSVAffected{{
+				Package: OSVPackage{Ecosystem: "npm", Name: "lodash"},
+				Ranges:  []OSVRange{{Type: "SEMVER", Events: []OSVEvent{{Introduced: "0"}, {Fixed: "4.17.19"}}}},
+			}}},
+			{ID: "GHSA-withdrawn", Withdrawn: "2021-01-01T00:00:00Z"},
+		},
+	}}
+
+	err := Enrich(context.Background(), source, result)
+	assert.NoError(t, err)
+	assert.Len(t, source.queried, 2)
+
+	expected := []scanners.Vulnerability{{ID: "GHSA-1", FixedVersions: []string{"4.17.19"}}}
+	assert.Equal(t, expected, result.Dependencies[0].Vulnerabilities)
+	assert.Empty(t, result.Dependencies[1].Vulnerabilities)
+	assert.Empty(t, result.Dependencies[2].Vulnerabilities)
+}
+
+func TestEnrich_Errors(t *testing.T) {
+	result := &scanners.ScanResult{Dependencies: []scanners.Dependency{{Name: "lodash", Version: "4.17.15", Type: "npm"}}}
+	err := Enrich(context.Background(), &fakeSource{err: errors.New("unavailable")}, result)
+	assert.Error(t, err)
+
+	// Nothing to look up does not query the source
+	source := &fakeSource{err: errors.New("unavailable")}
+	assert.NoError(t, Enrich(context.Background(), source, &scanners.ScanResult{}))
+	assert.Nil(t, source.queried)
+}
@@ -0,0 +1,206 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/history"
+	"github.com/santoshdahal12/deplister/pkg/httpclient"
+	"github.com/santoshdahal12/deplister/pkg/output"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// Payload modes
+const (
+	Full     = "full"     // The whole scan output
+	Diff     = "diff"     // Dependencies added, removed and changed since the previous scan of the target
+	Findings = "findings" // Findings and vulnerabilities only
+)
+
+// Modes lists the payload modes
+var Modes = []string{Full, Diff, Findings}
+
+// Event is the type of the events delivered
+const Event = "scan.completed"
+
+// Headers of deliveries
+const (
+	EventHeader     = "X-Deplister-Event"
+	SignatureHeader = "X-Deplister-Signature" // sha256=<hex HMAC-SHA256 of the body>
+)
+
+// Timeout bounds a delivery, the retries of the HTTP client included
+const Timeout = time.Minute
+
+// ErrDelivery is returned for deliveries the receiver did not accept
+var ErrDelivery = errors.New("webhook delivery failed")
+
+// Config configures the delivery of completed scans
+type Config struct {
+	URL    string // Receiver of the POST requests
+	Secret string // Key signing each body, no signature when empty
+	Mode   string // Payload mode, Full when empty
+
+	// HTTPClient sends the requests and retries those failing with a network
+	// error or a 5xx response, nil for httpclient.Default
+	HTTPClient *http.Client
+}
+
+// Payload is the JSON body of a delivery. Which of Result, Changes and the
+// findings are set depends on the mode.
+type Payload struct {
+	Event       string                    `json:"event"`
+	Target      string                    `json:"target"`
+	ProjectType string                    `json:"projectType"`
+	Timestamp   time.Time                 `json:"timestamp"`
+	Result      *output.OutputFormat      `json:"result,omitempty"`
+	Changes     *Changes                  `json:"changes,omitempty"`
+	Findings    []output.FindingOutput    `json:"findings,omitempty"`
+	Vulnerable  []output.DependencyOutput `json:"vulnerable,omitempty"` // Dependencies with vulnerabilities
+}
+
+// Changes are the dependencies that changed since the previous scan of a
+// target. Everything is added on the first scan.
+type Changes struct {
+	Initial bool                    `json:"initial,omitempty"` // First scan of the target
+	Added   []Dependency            `json:"added,omitempty"`
+	Removed []Dependency            `json:"removed,omitempty"`
+	Changed []history.VersionChange `json:"changed,omitempty"`
+}
+
+// Dependency identifies a dependency in Changes
+type Dependency struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Notifier delivers completed scans to a webhook. It is safe for
+// concurrent use.
+type Notifier struct {
+	config Config
+
+	mu       sync.Mutex
+	previous map[string][]history.Dependency // Dependencies of the last scan of each target, for Diff
+}
+
+// New creates a notifier, checking the configuration
+func New(config Config) (*Notifier, error) {
+	if config.URL == "" {
+		return nil, errors.New("webhook URL is required")
+	}
+	if config.Mode == "" {
+		config.Mode = Full
+	}
+	switch config.Mode {
+	case Full, Diff, Findings:
+	default:
+		return nil, fmt.Errorf("unknown webhook mode %q", config.Mode)
+	}
+	return &Notifier{config: config, previous: make(map[string][]history.Dependency)}, nil
+}
+
+// Deliver posts the report of a completed scan of the target once, leaving
+// retries to the HTTP client. In Diff mode the report becomes the baseline of
+// the next scan of the same target.
+func (n *Notifier) Deliver(ctx context.Context, target string, report *engine.Report) error {
+	body, err := json.Marshal(n.payload(target, report))
+	if err != nil {
+		return err
+	}
+
+	client := n.config.HTTPClient
+	if client == nil {
+		client = httpclient.Default
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, Event)
+	if n.config.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(n.config.Secret, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrDelivery, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: %s responded %s", ErrDelivery, n.config.URL, resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature header value of a body: sha256= followed by
+// the hex encoded HMAC-SHA256 of the body keyed with the secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of the body, in
+// constant time
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+func (n *Notifier) payload(target string, report *engine.Report) Payload {
+	payload := Payload{Event: Event, Target: target, ProjectType: report.ProjectType, Timestamp: time.Now().UTC()}
+	switch n.config.Mode {
+	case Full:
+		doc := output.NewOutputFormat(report.Result, report.ProjectType)
+		payload.Result = &doc
+	case Diff:
+		payload.Changes = n.changes(target, report.Result)
+	case Findings:
+		doc := output.NewOutputFormat(report.Result, report.ProjectType)
+		payload.Findings = doc.Findings
+		for _, dep := range doc.Dependencies {
+			if len(dep.Vulnerabilities) > 0 {
+				payload.Vulnerable = append(payload.Vulnerable, dep)
+			}
+		}
+	}
+	return payload
+}
+
+// changes diffs the result against the previous scan of the target and
+// makes it the new baseline
+func (n *Notifier) changes(target string, result *scanners.ScanResult) *Changes {
+	current := make([]history.Dependency, 0, len(result.Dependencies))
+	for _, dep := range result.Dependencies {
+		current = append(current, history.Dependency{Name: dep.Name, Version: dep.Version})
+	}
+
+	n.mu.Lock()
+	before, seen := n.previous[target]
+	n.previous[target] = current
+	n.mu.Unlock()
+
+	added, removed, changed := history.Diff(before, current)
+	return &Changes{Initial: !seen, Added: dependencies(added), Removed: dependencies(removed), Changed: changed}
+}
+
+func dependencies(deps []history.Dependency) []Dependency {
+	var result []Dependency
+	for _, dep := range deps {
+		result = append(result, Dependency{Name: dep.Name, Version: dep.Version})
+	}
+	return result
+}
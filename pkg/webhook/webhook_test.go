@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/history"
+	"github.com/santoshdahal12/deplister/pkg/httpclient"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testReport(deps ...scanners.Dependency) *engine.Report {
+	return &engine.Report{ProjectType: "npm", Result: &scanners.ScanResult{Dependencies: deps}}
+}
+
+// receiver records the bodies posted to it after answering with the
+// statuses in order, then 200
+func receiver(t *testing.T, secret string, statuses ...int) (*httptest.Server, *[]Payload) {
+	var payloads []Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, Event, r.Header.Get(EventHeader))
+		if secret != "" {
+			assert.True(t, Verify(secret, body, r.Header.Get(SignatureHeader)))
+		} else {
+			assert.Empty(t, r.Header.Get(SignatureHeader))
+		}
+		if len(statuses) > 0 {
+			status := statuses[0]
+			statuses = statuses[1:]
+			w.WriteHeader(status)
+			return
+		}
+		var payload Payload
+		assert.NoError(t, json.Unmarshal(body, &payload))
+		payloads = append(payloads, payload)
+	}))
+	t.Cleanup(server.Close)
+	return server, &payloads
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{})
+	assert.Error(t, err)
+	_, err = New(Config{URL: "http://example.com", Mode: "everything"})
+	assert.Error(t, err)
+	n, err := New(Config{URL: "http://example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, Full, n.config.Mode)
+}
+
+func TestDeliver_Full(t *testing.T) {
+	server, payloads := receiver(t, "s3cret")
+	n, err := New(Config{URL: server.URL, Secret: "s3cret"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, n.Deliver(context.Background(), "/srv/app", testReport(scanners.Dependency{Name: "lodash", Version: "4.17.21"})))
+	assert.Len(t, *payloads, 1)
+	payload := (*payloads)[0]
+	assert.Equal(t, Event, payload.Event)
+	assert.Equal(t, "/srv/app", payload.Target)
+	assert.Equal(t, "npm", payload.ProjectType)
+	assert.Len(t, payload.Result.Dependencies, 1)
+	assert.Nil(t, payload.Changes)
+}
+
+func TestDeliver_Diff(t *testing.T) {
+	server, payloads := receiver(t, "")
+	n, err := New(Config{URL: server.URL, Mode: Diff})
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	assert.NoError(t, n.Deliver(ctx, "app", testReport(
+		scanners.Dependency{Name: "lodash", Version: "4.17.20"},
+		scanners.Dependency{Name: "ms", Version: "2.1.3"},
+	)))
+	assert.NoError(t, n.Deliver(ctx, "app", testReport(
+		scanners.Dependency{Name: "lodash", Version: "4.17.21"},
+		scanners.Dependency{Name: "debug", Version: "4.3.4"},
+	)))
+	assert.NoError(t, n.Deliver(ctx, "other", testReport()))
+
+	assert.Len(t, *payloads, 3)
+	assert.Equal(t, &Changes{Initial: true, Added: []Dependency{{"lodash", "4.17.20"}, {"ms", "2.1.3"}}}, (*payloads)[0].Changes)
+	assert.Equal(t, &Changes{
+		Added:   []Dependency{{"debug", "4.3.4"}},
+		Removed: []Dependency{{"ms", "2.1.3"}},
+		Changed: []history.VersionChange{{Name: "lodash", From: "4.17.20", To: "4.17.21"}},
+	}, (*payloads)[1].Changes)
+	assert.Equal(t, &Changes{Initial: true}, (*payloads)[2].Changes)
+	assert.Nil(t, (*payloads)[1].Result)
+}
+
+func TestDeliver_Findings(t *testing.T) {
+	server, payloads := receiver(t, "")
+	n, err := New(Config{URL: server.URL, Mode: Findings})
+	assert.NoError(t, err)
+
+	report := testReport(
+		scanners.Dependency{Name: "lodash", Version: "4.17.20", Vulnerabilities: []scanners.Vulnerability{{ID: "GHSA-35jh-r3h4-6jhm"}}},
+		scanners.Dependency{Name: "ms", Version: "2.1.3"},
+	)
+	report.Result.Findings = []scanners.Finding{{Rule: "deny license GPL-*", Severity: scanners.SeverityError, Message: "denied"}}
+	assert.NoError(t, n.Deliver(context.Background(), "app", report))
+
+	payload := (*payloads)[0]
+	assert.Len(t, payload.Findings, 1)
+	assert.Equal(t, "deny license GPL-*", payload.Findings[0].Rule)
+	assert.Len(t, payload.Vulnerable, 1)
+	assert.Equal(t, "lodash", payload.Vulnerable[0].Name)
+	assert.Nil(t, payload.Result)
+}
+
+func TestDeliver_Retries(t *testing.T) {
+	defer func(delay time.Duration) { httpclient.RetryDelay = delay }(httpclient.RetryDelay)
+	httpclient.RetryDelay = time.Millisecond
+
+	// The HTTP client retries 5xx responses
+	server, payloads := receiver(t, "", http.StatusBadGateway, http.StatusServiceUnavailable)
+	client, _ := httpclient.New(httpclient.Config{Retries: 2})
+	n, err := New(Config{URL: server.URL, HTTPClient: client})
+	assert.NoError(t, err)
+	assert.NoError(t, n.Deliver(context.Background(), "app", testReport()))
+	assert.Len(t, *payloads, 1)
+
+	// Deliver itself sends the body once
+	var requests atomic.Int32
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	client, _ = httpclient.New(httpclient.Config{})
+	n, _ = New(Config{URL: failing.URL, HTTPClient: client})
+	err = n.Deliver(context.Background(), "app", testReport())
+	assert.True(t, errors.Is(err, ErrDelivery))
+	assert.Equal(t, int32(1), requests.Load())
+
+	server, _ = receiver(t, "", http.StatusUnauthorized, http.StatusUnauthorized)
+	n, _ = New(Config{URL: server.URL})
+	assert.True(t, errors.Is(n.Deliver(context.Background(), "app", testReport()), ErrDelivery))
+}
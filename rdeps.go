@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// reverseDependencies is the JSON output of the rdeps command
+type reverseDependencies struct {
+	Name       string             `json:"name"`
+	Version    string             `json:"version"`
+	Direct     bool               `json:"isDirectDependency"`
+	Dependents []reverseDependent `json:"dependents"`
+}
+
+type reverseDependent struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Depth   int    `json:"depth"`
+	Via     string `json:"via"`
+}
+
+func runRdeps(args []string) {
+	var (
+		projectPath string
+		repoSpec    string
+		scanFile    string
+		jsonOutput  bool
+		disabled    string
+		opts        = scanners.DefaultScanOptions()
+	)
+
+	flags := flag.NewFlagSet("rdeps", flag.ExitOnError)
+	flags.StringVar(&projectPath, "path", ".", "Path to the project directory or archive")
+	flags.StringVar(&repoSpec, "repo", "", "Git repository to clone and scan, as url[@ref]")
+	flags.BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	flags.StringVar(&scanFile, "scan", "", "JSON output of scan -graph to read instead of scanning")
+	flags.StringVar(&disabled, "disable", "", "Comma separated list of scanner types to disable")
+	flags.BoolVar(&opts.IncludeDev, "include-dev", opts.IncludeDev, "Include development dependencies")
+	flags.BoolVar(&opts.Offline, "offline", opts.Offline, "Never access the network while scanning")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: deplister rdeps [flags] <package>[@version]\n\nLists the packages that depend on a package, directly or transitively.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	opts.Offline = opts.Offline || offline
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		exit(2)
+	}
+	name := flags.Arg(0)
+
+	setupScanners(disabled)
+	target := engine.Target{Path: projectPath}
+	if repoSpec != "" {
+		target = engine.Target{Repo: repoSpec}
+	}
+
+	report := scanOrLoad(target, opts, scanFile)
+
+	graph := report.Result.Graph
+	keys := nodeKeys(graph, name)
+	switch {
+	case len(keys) == 0:
+		fmt.Fprintf(os.Stderr, "%s is not a dependency of %s\n", name, describeTarget(target))
+		exit(1)
+	case len(keys) > 1:
+		fmt.Fprintf(os.Stderr, "%s is present at several versions, pick one of: %s\n", name, strings.Join(keys, ", "))
+		exit(1)
+	}
+
+	node := graph.Nodes[keys[0]]
+	rdeps := reverseDependencies{Name: node.Name, Version: node.Version, Direct: node.IsDirectDep, Dependents: []reverseDependent{}}
+	for _, dependent := range graph.Dependents(keys[0]) {
+		entry := reverseDependent{Name: dependent.Key, Depth: dependent.Depth, Via: dependent.Via}
+		if dep, ok := graph.Nodes[dependent.Key]; ok {
+			entry.Name, entry.Version = dep.Name, dep.Version
+		}
+		rdeps.Dependents = append(rdeps.Dependents, entry)
+	}
+
+	var err error
+	if jsonOutput {
+		err = writeJSONValue(os.Stdout, rdeps)
+	} else {
+		err = writeRdepsText(os.Stdout, rdeps)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		exit(1)
+	}
+}
+
+// nodeKeys returns the keys of the graph nodes a package argument refers to,
+// given either as name@version or as a bare name matching every version
+func nodeKeys(graph *scanners.DependencyGraph, arg string) []string {
+	if _, ok := graph.Nodes[arg]; ok {
+		return []string{arg}
+	}
+	var keys []string
+	for key, node := range graph.Nodes {
+		if node.Name == arg {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func writeRdepsText(w io.Writer, rdeps reverseDependencies) error {
+	fmt.Fprintf(w, "Packages depending on %s@%s: %d\n", rdeps.Name, rdeps.Version, len(rdeps.Dependents))
+	if rdeps.Direct {
+		fmt.Fprintf(w, "%s is a direct dependency of the project\n", rdeps.Name)
+	}
+	if len(rdeps.Dependents) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DEPTH\tPACKAGE\tVIA")
+	for _, dependent := range rdeps.Dependents {
+		// The project itself has no version
+		name := dependent.Name
+		if dependent.Version != "" {
+			name += "@" + dependent.Version
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", dependent.Depth, name, dependent.Via)
+	}
+	return tw.Flush()
+}
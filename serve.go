@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/daemon"
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/history"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/server"
+	"github.com/santoshdahal12/deplister/pkg/webhook"
+)
+
+func runServe(args []string) {
+	var (
+		addr     string
+		grpcAddr string
+		disabled string
+		metrics  bool
+		watch    string
+		store    string
+		workers  int
+		hook     webhook.Config
+		config   server.Config
+	)
+
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	flags.StringVar(&addr, "addr", "127.0.0.1:8080", "Address to listen on")
+	flags.StringVar(&grpcAddr, "grpc-addr", "", "Address to serve the gRPC API on (disabled when empty)")
+	flags.BoolVar(&config.AllowPaths, "allow-paths", false, "Allow requests to scan paths on the server's file system")
+	flags.BoolVar(&config.AllowRepos, "allow-repos", false, "Allow requests to clone and scan git repositories")
+	flags.BoolVar(&metrics, "metrics", true, "Expose Prometheus metrics on /metrics")
+	flags.StringVar(&disabled, "disable", "", "Comma separated list of scanner types to disable")
+	flags.BoolVar(&config.Offline, "offline", offline, "Scan every request offline, failing enrichments and repositories that need the network")
+	flags.StringVar(&hook.URL, "webhook", "", "URL to POST every completed scan to")
+	flags.StringVar(&hook.Secret, "webhook-secret", os.Getenv("DEPLISTER_WEBHOOK_SECRET"), "Key signing webhook bodies with HMAC-SHA256 in the "+webhook.SignatureHeader+" header")
+	flags.StringVar(&hook.Mode, "webhook-mode", webhook.Full, "What webhook bodies hold: "+strings.Join(webhook.Modes, ", "))
+	flags.StringVar(&watch, "daemon", "", "YAML file of paths and repositories to rescan on cron schedules, exposing their latest results on /targets")
+	flags.StringVar(&store, "store", "", "SQLite database recording every scheduled scan, as scan -store does")
+	flags.IntVar(&workers, "concurrency", 0, "Number of scheduled scans to run at once (default: one per CPU)")
+	network := networkFlags(flags)
+	flags.Parse(args)
+	config.Offline = config.Offline || offline
+	network.Offline = config.Offline
+	config.HTTPClient = httpClient(network)
+
+	if hook.URL != "" {
+		if config.Offline {
+			fmt.Fprintf(os.Stderr, "-webhook needs network access and cannot be combined with -offline\n")
+			exit(2)
+		}
+		hook.HTTPClient = httpClient(network)
+		notifier, err := webhook.New(hook)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid webhook: %v\n", err)
+			exit(2)
+		}
+		config.Webhook = notifier
+		config.WebhookError = func(target string, err error) {
+			fmt.Fprintf(os.Stderr, "Error delivering scan of %s to webhook: %v\n", target, err)
+		}
+	}
+
+	setupScanners(disabled)
+
+	if metrics {
+		config.Metrics = server.NewMetrics()
+	}
+
+	var handler http.Handler = server.New(config)
+	if watch != "" {
+		handler = startDaemon(watch, store, workers, config, handler)
+	} else if store != "" || workers != 0 {
+		fmt.Fprintf(os.Stderr, "-store and -concurrency require -daemon\n")
+		exit(2)
+	}
+
+	errs := make(chan error, 2)
+
+	if grpcAddr != "" {
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listening on %s: %v\n", grpcAddr, err)
+			exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Serving gRPC on %s\n", grpcAddr)
+		go func() {
+			errs <- server.NewGRPC(config).Serve(listener)
+		}()
+	}
+
+	fmt.Fprintf(os.Stderr, "Listening on %s\n", addr)
+	go func() {
+		errs <- http.ListenAndServe(addr, handler)
+	}()
+
+	if err := <-errs; err != nil {
+		fmt.Fprintf(os.Stderr, "Error serving: %v\n", err)
+		exit(1)
+	}
+}
+
+// startDaemon starts rescanning the targets of the daemon configuration in
+// the background and returns the handler serving their results next to the
+// server's API
+func startDaemon(configPath, storePath string, workers int, config server.Config, api http.Handler) http.Handler {
+	daemonConfig, err := daemon.LoadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading daemon configuration: %v\n", err)
+		exit(1)
+	}
+
+	opts := daemon.Options{
+		Scan: func(ctx context.Context, target engine.Target, opts scanners.ScanOptions) (*engine.Report, error) {
+			return server.Scan(ctx, config, target, opts)
+		},
+		Webhook:     config.Webhook,
+		Concurrency: workers,
+		Error: func(name string, err error) {
+			fmt.Fprintf(os.Stderr, "Error in scheduled scan of %s: %v\n", name, err)
+		},
+	}
+	if storePath != "" {
+		if opts.Store, err = history.Open(storePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening history: %v\n", err)
+			exit(1)
+		}
+	}
+
+	d, err := daemon.New(daemonConfig, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading daemon configuration: %v\n", err)
+		exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Rescanning %d targets on schedule\n", len(daemonConfig.Targets))
+	go d.Run(context.Background())
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /targets", d.Handler())
+	mux.Handle("GET /targets/", d.Handler())
+	mux.Handle("/", api)
+	return mux
+}
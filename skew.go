@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/skew"
+)
+
+// skewEntry is a dependency in the JSON output of the skew command
+type skewEntry struct {
+	Name       string                   `json:"name"`
+	Versions   []string                 `json:"versions"`
+	Workspaces map[string]skewWorkspace `json:"workspaces"`
+}
+
+type skewWorkspace struct {
+	Specifier string `json:"specifier"`
+	Version   string `json:"version"`
+}
+
+func runSkew(args []string) {
+	var (
+		projectPath string
+		repoSpec    string
+		jsonOutput  bool
+	)
+
+	flags := flag.NewFlagSet("skew", flag.ExitOnError)
+	flags.StringVar(&projectPath, "path", ".", "Path to the project directory or archive")
+	flags.StringVar(&repoSpec, "repo", "", "Git repository to clone and scan, as url[@ref]")
+	flags.BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: deplister skew [flags]\n\nReports the dependencies the workspaces of an npm monorepo resolve to different versions.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	target := engine.Target{Path: projectPath}
+	if repoSpec != "" {
+		target = engine.Target{Repo: repoSpec}
+	}
+
+	fsys, cleanup, err := engine.Files(context.Background(), target, scanners.ScanOptions{Offline: offline})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", describeTarget(target), err)
+		exit(1)
+	}
+	skews, err := skew.Find(fsys)
+	cleanup()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading lockfile: %v\n", err)
+		exit(1)
+	}
+
+	if jsonOutput {
+		entries := []skewEntry{}
+		for _, s := range skews {
+			entry := skewEntry{Name: s.Name, Versions: s.Versions(), Workspaces: make(map[string]skewWorkspace)}
+			for _, req := range s.Requirements {
+				entry.Workspaces[req.Workspace] = skewWorkspace{Specifier: req.Specifier, Version: req.Version}
+			}
+			entries = append(entries, entry)
+		}
+		err = writeJSONValue(os.Stdout, entries)
+	} else {
+		err = writeSkewText(os.Stdout, skews)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		exit(1)
+	}
+}
+
+// writeSkewText writes the skew matrix, a row per dependency and a column per
+// workspace requiring one of them
+func writeSkewText(w io.Writer, skews []skew.Skew) error {
+	if len(skews) == 0 {
+		_, err := fmt.Fprintln(w, "No version skew across workspaces")
+		return err
+	}
+
+	var workspaces []string
+	for _, s := range skews {
+		for _, req := range s.Requirements {
+			if !slices.Contains(workspaces, req.Workspace) {
+				workspaces = append(workspaces, req.Workspace)
+			}
+		}
+	}
+	slices.Sort(workspaces)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "DEPENDENCY\t%s\n", strings.Join(workspaces, "\t"))
+	for _, s := range skews {
+		cells := make([]string, len(workspaces))
+		for i, workspace := range workspaces {
+			cells[i] = "-"
+			for _, req := range s.Requirements {
+				if req.Workspace == workspace {
+					cells[i] = req.Version
+				}
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", s.Name, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/github"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+func runSubmit(args []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprintln(os.Stderr, "Usage: deplister submit github [options]")
+		exit(2)
+	}
+
+	switch args[0] {
+	case "github":
+		runSubmitGitHub(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown submission target %q\n", args[0])
+		fmt.Fprintln(os.Stderr, "Available targets: github")
+		exit(2)
+	}
+}
+
+func runSubmitGitHub(args []string) {
+	var (
+		projectPath string
+		repository  string
+		apiURL      string
+		token       string
+		disabled    string
+		snapshot    github.SnapshotOptions
+		opts        = scanners.DefaultScanOptions()
+	)
+
+	flags := flag.NewFlagSet("submit github", flag.ExitOnError)
+	flags.StringVar(&projectPath, "path", ".", "Path to the project directory within the repository")
+	flags.StringVar(&repository, "repository", os.Getenv("GITHUB_REPOSITORY"), "Repository to submit to, as owner/name")
+	flags.StringVar(&snapshot.SHA, "sha", os.Getenv("GITHUB_SHA"), "Commit the dependencies were scanned at")
+	flags.StringVar(&snapshot.Ref, "ref", os.Getenv("GITHUB_REF"), "Git ref the commit belongs to, e.g. refs/heads/main")
+	flags.StringVar(&snapshot.Correlator, "correlator", "", "Identifier of this submission; later submissions with the same one replace it (default: workflow, job and manifest)")
+	flags.StringVar(&snapshot.Manifest, "manifest", "", "Manifest path in the repository (default: the project's lockfile)")
+	flags.StringVar(&apiURL, "api-url", envOr("GITHUB_API_URL", github.DefaultAPIURL), "GitHub API URL")
+	flags.StringVar(&token, "token", os.Getenv("GITHUB_TOKEN"), "GitHub token with contents: write permission")
+	flags.StringVar(&disabled, "disable", "", "Comma separated list of scanner types to disable")
+	flags.BoolVar(&opts.IncludeDev, "include-dev", opts.IncludeDev, "Include development dependencies")
+	flags.BoolVar(&opts.Offline, "offline", opts.Offline, "Never access the network while scanning")
+	network := networkFlags(flags)
+	flags.Parse(args)
+	opts.Offline = opts.Offline || offline
+
+	if repository == "" || snapshot.SHA == "" || snapshot.Ref == "" {
+		fmt.Fprintln(os.Stderr, "-repository, -sha and -ref are required outside of GitHub Actions")
+		exit(2)
+	}
+
+	if offline {
+		fmt.Fprintln(os.Stderr, "Submitting to GitHub needs network access and cannot be used in offline mode")
+		exit(2)
+	}
+	opts.Env = network.Env()
+	opts.HTTPClient = httpClient(network)
+	setupScanners(disabled)
+
+	report, err := engine.Scan(context.Background(), engine.Target{Path: projectPath}, opts)
+	if errors.Is(err, engine.ErrNoProject) {
+		fmt.Fprintf(os.Stderr, "No supported project found at %s\n", describeTarget(engine.Target{Path: projectPath}))
+		exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error scanning dependencies: %v\n", err)
+		exit(1)
+	}
+
+	if snapshot.Manifest == "" {
+		snapshot.Manifest = manifestPath(projectPath, github.ManifestName(report.ProjectType))
+	}
+	if snapshot.Correlator == "" {
+		snapshot.Correlator = defaultCorrelator(snapshot.Manifest)
+	}
+	snapshot.JobID = envOr("GITHUB_RUN_ID", strconv.FormatInt(time.Now().Unix(), 10))
+	if server, runID := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_RUN_ID"); server != "" && runID != "" {
+		snapshot.JobURL = fmt.Sprintf("%s/%s/actions/runs/%s", server, repository, runID)
+	}
+	snapshot.DetectorVersion = detectorVersion()
+	snapshot.Scanned = time.Now()
+
+	client := github.NewClient(token)
+	client.APIURL = apiURL
+	client.HTTPClient = opts.HTTPClient
+
+	result, err := client.Submit(context.Background(), repository, github.NewSnapshot(report, snapshot))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error submitting dependencies: %v\n", err)
+		exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "Submitted %d dependencies from %s as snapshot %d: %s\n",
+		len(report.Result.Dependencies), snapshot.Manifest, result.ID, result.Message)
+}
+
+// manifestPath returns the manifest's path relative to the working
+// directory, which is the repository root in CI
+func manifestPath(projectPath, manifest string) string {
+	rel := projectPath
+	if filepath.IsAbs(projectPath) {
+		wd, err := os.Getwd()
+		if err != nil {
+			return manifest
+		}
+		if rel, err = filepath.Rel(wd, projectPath); err != nil {
+			return manifest
+		}
+	}
+
+	rel = filepath.ToSlash(filepath.Join(rel, manifest))
+	if strings.HasPrefix(rel, "../") {
+		return manifest
+	}
+	return rel
+}
+
+// defaultCorrelator keeps snapshots of different workflows, jobs and
+// manifests from replacing each other
+func defaultCorrelator(manifest string) string {
+	parts := []string{"deplister"}
+	for _, env := range []string{"GITHUB_WORKFLOW", "GITHUB_JOB"} {
+		if value := os.Getenv(env); value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(append(parts, manifest), "_")
+}
+
+func detectorVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "devel"
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
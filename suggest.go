@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/discover"
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/outdated"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/updates"
+)
+
+func runSuggestUpdates(args []string) {
+	var (
+		projectPath string
+		format      string
+		interval    string
+		outputFile  string
+		disabled    string
+		concurrency int
+		discovery   discover.Options
+		opts        = scanners.DefaultScanOptions()
+	)
+
+	flags := flag.NewFlagSet("suggest-updates", flag.ExitOnError)
+	flags.StringVar(&projectPath, "path", ".", "Path to the repository")
+	flags.StringVar(&format, "format", updates.Dependabot, "Config to generate: "+strings.Join(updates.Formats, ", "))
+	flags.StringVar(&interval, "interval", updates.Weekly, "How often to check for updates: "+strings.Join(updates.Intervals, ", "))
+	flags.StringVar(&outputFile, "out", "", "Output file path (default: stdout)")
+	flags.StringVar(&disabled, "disable", "", "Comma separated list of scanner types to disable")
+	flags.IntVar(&concurrency, "concurrency", 0, "Number of projects to scan at once (default: one per CPU)")
+	flags.Var((*listFlag)(&discovery.Exclude), "exclude-dir", "Directory name or slash separated path, relative to the repository, to skip (repeatable)")
+	flags.BoolVar(&discovery.NoGitignore, "no-gitignore", false, "Also look for projects in directories .gitignore files exclude")
+	flags.BoolVar(&opts.IncludeDev, "include-dev", opts.IncludeDev, "Include development dependencies")
+	flags.BoolVar(&opts.Offline, "offline", opts.Offline, "Never access the network; the config is generated without outdated dependency data")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: deplister suggest-updates [flags]\n\nGenerates a Dependabot or Renovate config covering the projects found in a repository, grouping the outdated dependencies of each into as few pull requests as practical.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	opts.Offline = opts.Offline || offline
+
+	if !slices.Contains(updates.Formats, format) {
+		fmt.Fprintf(os.Stderr, "Invalid -format %q, expected one of %s\n", format, strings.Join(updates.Formats, ", "))
+		exit(2)
+	}
+	if !slices.Contains(updates.Intervals, interval) {
+		fmt.Fprintf(os.Stderr, "Invalid -interval %q, expected one of %s\n", interval, strings.Join(updates.Intervals, ", "))
+		exit(2)
+	}
+
+	setupScanners(disabled)
+	dirs, err := engine.Discover(context.Background(), projectPath, discovery)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error discovering projects in %s: %v\n", projectPath, err)
+		exit(1)
+	}
+	if len(dirs) == 0 {
+		fmt.Fprintf(os.Stderr, "No supported project found in %s\n", projectPath)
+		exit(1)
+	}
+
+	targets := make([]engine.Target, len(dirs))
+	for i, dir := range dirs {
+		targets[i] = engine.Target{Path: dir}
+	}
+	opts.Enrich = map[string]bool{outdated.Enrichment: !opts.Offline}
+
+	var projects []updates.Project
+	for _, outcome := range engine.ScanAll(context.Background(), targets, opts, concurrency) {
+		rel, _ := filepath.Rel(projectPath, outcome.Target.Path)
+		project := updates.Project{Dir: filepath.ToSlash(rel)}
+		if outcome.Err != nil {
+			fmt.Fprintf(os.Stderr, "Error scanning %s, configuring it without outdated dependencies: %v\n", outcome.Target.Path, outcome.Err)
+			if project.Type, err = engine.Detect(context.Background(), outcome.Target, opts); err != nil {
+				continue
+			}
+		} else {
+			project.Type, project.Result = outcome.Report.ProjectType, outcome.Report.Result
+		}
+		// Both tools read Go dependencies from go.mod only
+		if manifest := updates.Manifest(project.Type); manifest != "" {
+			if _, err := os.Stat(filepath.Join(outcome.Target.Path, manifest)); errors.Is(err, os.ErrNotExist) {
+				fmt.Fprintf(os.Stderr, "Skipping %s, which has no %s\n", project.Dir, manifest)
+				continue
+			}
+		}
+		projects = append(projects, project)
+	}
+
+	plans, unsupported := updates.Suggest(projects)
+	for _, project := range unsupported {
+		fmt.Fprintf(os.Stderr, "Skipping %s, %s projects are not supported by %s\n", project.Dir, project.Type, format)
+	}
+	for _, plan := range plans {
+		fmt.Fprintf(os.Stderr, "%s: %d major, %d minor and %d patch updates of direct dependencies\n", plan.Manifest(), len(plan.Major), len(plan.Minor), len(plan.Patch))
+	}
+
+	writer := os.Stdout
+	if outputFile != "" {
+		if writer, err = os.Create(outputFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
+			exit(1)
+		}
+	}
+	err = updates.Write(writer, format, interval, plans)
+	if outputFile != "" {
+		err = errors.Join(err, writer.Close())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		exit(1)
+	}
+}
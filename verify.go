@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/output"
+	"github.com/santoshdahal12/deplister/pkg/sbom"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// verification is the JSON output of the verify command
+type verification struct {
+	Baseline string `json:"baseline"`
+	Passed   bool   `json:"passed"`
+	*sbom.Drift
+}
+
+func runVerify(args []string) {
+	var (
+		baselineFile  string
+		projectPath   string
+		repoSpec      string
+		scanFile      string
+		jsonOutput    bool
+		failOnRemoved bool
+		disabled      string
+		opts          = scanners.DefaultScanOptions()
+	)
+
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	flags.StringVar(&baselineFile, "baseline", "", "Approved CycloneDX or SPDX JSON document to compare with (required)")
+	flags.StringVar(&projectPath, "path", ".", "Path to the project directory or archive")
+	flags.StringVar(&repoSpec, "repo", "", "Git repository to clone and scan, as url[@ref]")
+	flags.StringVar(&scanFile, "scan", "", "JSON output of scan to read instead of scanning")
+	flags.BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	flags.BoolVar(&failOnRemoved, "fail-on-removed", false, "Also fail when packages of the baseline are gone")
+	flags.StringVar(&disabled, "disable", "", "Comma separated list of scanner types to disable")
+	flags.BoolVar(&opts.IncludeDev, "include-dev", opts.IncludeDev, "Include development dependencies")
+	flags.BoolVar(&opts.Offline, "offline", opts.Offline, "Never access the network while scanning")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: deplister verify -baseline <sbom.json> [flags]\n\nChecks that the dependencies of a project match an approved SBOM, exiting with status 1 on unexpected additions or version changes.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	opts.Offline = opts.Offline || offline
+
+	if baselineFile == "" || flags.NArg() != 0 {
+		flags.Usage()
+		exit(2)
+	}
+
+	_, baseline, err := sbom.Load(baselineFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", baselineFile, err)
+		exit(1)
+	}
+
+	var current *scanners.ScanResult
+	if scanFile != "" {
+		// The drift only needs the dependencies, not the graph scanOrLoad asks for
+		if current, _, err = output.LoadFile(scanFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading scan: %v\n", err)
+			exit(1)
+		}
+	} else {
+		setupScanners(disabled)
+		target := engine.Target{Path: projectPath}
+		if repoSpec != "" {
+			target = engine.Target{Repo: repoSpec}
+		}
+		current = scanOrLoad(target, opts, "").Result
+	}
+
+	drift := sbom.Verify(baseline, current)
+	result := verification{Baseline: baselineFile, Passed: !drift.Unexpected(failOnRemoved), Drift: drift}
+	if jsonOutput {
+		err = writeJSONValue(os.Stdout, result)
+	} else {
+		err = writeVerifyText(os.Stdout, result)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		exit(1)
+	}
+	if !result.Passed {
+		exit(1)
+	}
+}
+
+func writeVerifyText(w io.Writer, result verification) error {
+	for _, dep := range result.Added {
+		fmt.Fprintf(w, "+ %s@%s\n", dep.Name, dep.Version)
+	}
+	for _, dep := range result.Removed {
+		fmt.Fprintf(w, "- %s@%s\n", dep.Name, dep.Version)
+	}
+	for _, change := range result.Changed {
+		fmt.Fprintf(w, "~ %s %s -> %s\n", change.Name, change.From, change.To)
+	}
+	status := "matches"
+	if !result.Passed {
+		status = "does not match"
+	}
+	_, err := fmt.Fprintf(w, "The project %s %s: %d added, %d changed, %d removed\n", status, result.Baseline, len(result.Added), len(result.Changed), len(result.Removed))
+	return err
+}
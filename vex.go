@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+	"github.com/santoshdahal12/deplister/pkg/vex"
+	"github.com/santoshdahal12/deplister/pkg/vulns"
+)
+
+func runVEX(args []string) {
+	var (
+		projectPath string
+		repoSpec    string
+		outputFile  string
+		author      string
+		disabled    string
+		opts        = scanners.DefaultScanOptions()
+	)
+
+	flags := flag.NewFlagSet("vex", flag.ExitOnError)
+	flags.StringVar(&projectPath, "path", ".", "Path to the project directory or archive")
+	flags.StringVar(&repoSpec, "repo", "", "Git repository to clone and scan, as url[@ref]")
+	flags.StringVar(&outputFile, "out", "", "Output file path (default: stdout)")
+	flags.StringVar(&author, "author", "Unknown Author", "Author of the VEX statements")
+	flags.StringVar(&disabled, "disable", "", "Comma separated list of scanner types to disable")
+	flags.BoolVar(&opts.IncludeDev, "include-dev", opts.IncludeDev, "Include development dependencies")
+	flags.BoolVar(&opts.Offline, "offline", opts.Offline, "Never access the network while scanning")
+	flags.StringVar(&opts.VulnDB, "vulndb", "", "Local vulnerability database from 'deplister db update' (default with -offline)")
+	flags.Parse(args)
+	opts.Offline = opts.Offline || offline
+
+	opts.Enrich = map[string]bool{vulns.Enrichment: true}
+	setupScanners(disabled)
+
+	target := engine.Target{Path: projectPath}
+	if repoSpec != "" {
+		target = engine.Target{Repo: repoSpec}
+	}
+
+	report, err := engine.Scan(context.Background(), target, opts)
+	if errors.Is(err, engine.ErrNoProject) {
+		fmt.Fprintf(os.Stderr, "No supported project found at %s\n", describeTarget(target))
+		exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error scanning dependencies: %v\n", err)
+		exit(1)
+	}
+
+	var writer io.Writer = os.Stdout
+	if outputFile != "" {
+		file, err := os.Create(outputFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
+			exit(1)
+		}
+		defer file.Close()
+		writer = file
+	}
+
+	doc := vex.Skeleton(report.Result, author, time.Now())
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing VEX document: %v\n", err)
+		exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d under_investigation statements\n", len(doc.Statements))
+}
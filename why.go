@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/santoshdahal12/deplister/pkg/engine"
+	"github.com/santoshdahal12/deplister/pkg/scanners"
+)
+
+// dependencyReasons is the JSON output of the why command, one per version
+// of the package
+type dependencyReasons struct {
+	Name    string     `json:"name"`
+	Version string     `json:"version"`
+	Direct  bool       `json:"isDirectDependency"`
+	Paths   [][]string `json:"paths"`
+}
+
+func runWhy(args []string) {
+	var (
+		projectPath string
+		repoSpec    string
+		scanFile    string
+		jsonOutput  bool
+		limit       int
+		disabled    string
+		opts        = scanners.DefaultScanOptions()
+	)
+
+	flags := flag.NewFlagSet("why", flag.ExitOnError)
+	flags.StringVar(&projectPath, "path", ".", "Path to the project directory or archive")
+	flags.StringVar(&repoSpec, "repo", "", "Git repository to clone and scan, as url[@ref]")
+	flags.BoolVar(&jsonOutput, "json", false, "Output as JSON")
+	flags.StringVar(&scanFile, "scan", "", "JSON output of scan -graph to read instead of scanning")
+	flags.IntVar(&limit, "limit", 0, "Paths to list per version at most, shortest first (default: all)")
+	flags.StringVar(&disabled, "disable", "", "Comma separated list of scanner types to disable")
+	flags.BoolVar(&opts.IncludeDev, "include-dev", opts.IncludeDev, "Include development dependencies")
+	flags.BoolVar(&opts.Offline, "offline", opts.Offline, "Never access the network while scanning")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: deplister why [flags] <package>[@version]\n\nLists every path through which the project depends on a package.\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	opts.Offline = opts.Offline || offline
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		exit(2)
+	}
+	if limit < 0 {
+		fmt.Fprintf(os.Stderr, "-limit must not be negative\n")
+		exit(2)
+	}
+	name := flags.Arg(0)
+
+	setupScanners(disabled)
+	target := engine.Target{Path: projectPath}
+	if repoSpec != "" {
+		target = engine.Target{Repo: repoSpec}
+	}
+
+	report := scanOrLoad(target, opts, scanFile)
+
+	result := report.Result
+	keys := nodeKeys(result.Graph, name)
+	if len(keys) == 0 {
+		fmt.Fprintf(os.Stderr, "%s is not a dependency of %s\n", name, describeTarget(target))
+		exit(1)
+	}
+
+	reasons := make([]dependencyReasons, 0, len(keys))
+	for _, key := range keys {
+		node := result.Graph.Nodes[key]
+		entry := dependencyReasons{Name: node.Name, Version: node.Version, Direct: node.IsDirectDep, Paths: [][]string{}}
+		for _, root := range result.Graph.Roots() {
+			for _, path := range result.Graph.FindPaths(root, key, limit) {
+				if limit > 0 && len(entry.Paths) == limit {
+					break
+				}
+				entry.Paths = append(entry.Paths, result.DisplayPath(path.Path))
+			}
+		}
+		reasons = append(reasons, entry)
+	}
+
+	var err error
+	if jsonOutput {
+		err = writeJSONValue(os.Stdout, reasons)
+	} else {
+		err = writeWhyText(os.Stdout, reasons)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		exit(1)
+	}
+}
+
+func writeWhyText(w io.Writer, reasons []dependencyReasons) error {
+	for i, entry := range reasons {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Paths to %s@%s: %d\n", entry.Name, entry.Version, len(entry.Paths))
+		for _, path := range entry.Paths {
+			if _, err := fmt.Fprintf(w, "  %s\n", strings.Join(path, " > ")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}